@@ -17,6 +17,7 @@ package main
 import (
 	"github.com/uber/peloton/pkg/apiserver"
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/middleware/inbound"
@@ -29,4 +30,9 @@ type Config struct {
 	Auth      auth.Config             `yaml:"auth"`
 	RateLimit inbound.RateLimitConfig `yaml:"rate_limit"`
 	Election  leader.ElectionConfig   `yaml:"election"`
+	// Audit is the audit log of the calls forwarded by the API server,
+	// which records the callers before they are replaced by the internal
+	// user. Only the file and stream sinks are supported, as the API
+	// server has no store.
+	Audit audit.Config `yaml:"audit"`
 }
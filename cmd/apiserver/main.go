@@ -17,10 +17,14 @@ package main
 import (
 	"os"
 
+	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
 	"github.com/uber/peloton/pkg/apiserver"
+	"github.com/uber/peloton/pkg/apiserver/forward"
 	"github.com/uber/peloton/pkg/auth"
 	authimpl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -32,6 +36,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/middleware"
 	"go.uber.org/yarpc/api/transport"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -79,7 +84,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -246,10 +251,32 @@ func main() {
 		log.WithError(err).
 			Fatal("Could not create rate limit middleware")
 	}
+	// The rate limits of the methods are checked before auth, so that
+	// unauthenticated calls are rate limited, and the rate limits of
+	// the callers after auth, so that callers are identified by the
+	// authenticated users.
+	callerRateLimitMiddleware := rateLimitMiddleware.CallerMiddleware()
 
 	// Setup inbound authentication middleware.
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
+	// Setup inbound audit middleware, which records the callers of the
+	// mutating calls before they are forwarded with the internal token.
+	var auditSink audit.Sink
+	if cfg.Audit.Enabled {
+		auditSink, err = audit.NewSink(&cfg.Audit, nil, rootScope)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Could not create audit sink")
+		}
+	}
+	auditInboundMiddleware, err := inbound.NewAuditInboundMiddleware(
+		cfg.Audit, common.PelotonAPIServer, auditSink)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create audit middleware")
+	}
+
 	// Create security client for outbound authentication middleware.
 	securityClient, err := authimpl.CreateNewSecurityClient(&cfg.Auth)
 	if err != nil {
@@ -260,6 +287,25 @@ func main() {
 	// Setup outbound authentication middleware.
 	authOutboundMiddleware := outbound.NewAuthOutboundMiddleware(securityClient)
 
+	// Setup outbound middleware which forwards the authenticated
+	// callers, so that the calls forwarded with the internal token
+	// are authorized for the callers by the other components.
+	forwardedUserMiddleware := outbound.NewForwardedUserOutboundMiddleware()
+	forwardOutbounds := func(name string) transport.Outbounds {
+		return transport.Outbounds{
+			Unary: middleware.ApplyUnaryOutbound(
+				middleware.ApplyUnaryOutbound(
+					outbounds[name].Unary, authOutboundMiddleware),
+				forwardedUserMiddleware,
+			),
+			Stream: middleware.ApplyStreamOutbound(
+				middleware.ApplyStreamOutbound(
+					outbounds[name].Stream, authOutboundMiddleware),
+				forwardedUserMiddleware,
+			),
+		}
+	}
+
 	// Create YARPC dispatcher.
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonAPIServer,
@@ -269,9 +315,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  yarpc.UnaryInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
-			Stream: yarpc.StreamInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
-			Oneway: yarpc.OnewayInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
+			Unary:  yarpc.UnaryInboundMiddleware(rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
+			Stream: yarpc.StreamInboundMiddleware(rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
+			Oneway: yarpc.OnewayInboundMiddleware(rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -280,24 +326,55 @@ func main() {
 		},
 	})
 
+	// Setup the retries of idempotent calls during leader failover.
+	var forwardOptions []forward.UnaryOption
+	if failoverPolicy := forward.NewFailoverPolicy(
+		cfg.APIServer.Failover,
+		rootScope,
+	); failoverPolicy != nil {
+		forwardOptions = append(
+			forwardOptions, forward.WithFailoverPolicy(failoverPolicy))
+	}
+
+	// Setup the response cache of Job Manager read only procedures,
+	// which is invalidated by watching the changes to jobs and pods.
+	jobmgrForwardOptions := forwardOptions
+	var cacheInvalidator *forward.CacheInvalidator
+	if cfg.APIServer.Cache.Enabled {
+		responseCache := forward.NewResponseCache(cfg.APIServer.Cache, rootScope)
+		cacheInvalidator = forward.NewCacheInvalidator(
+			responseCache,
+			watchsvc.NewWatchServiceYARPCClient(
+				dispatcher.ClientConfig(common.PelotonJobManager),
+			),
+		)
+		jobmgrForwardOptions = append(
+			[]forward.UnaryOption{forward.WithResponseCache(responseCache)},
+			forwardOptions...,
+		)
+	}
+
 	// Register service procedures in dispatcher.
 	var procedures []transport.Procedure
 	procedures = append(
 		procedures,
 		apiserver.BuildHostManagerProcedures(
-			outbounds[common.PelotonHostManager],
+			forwardOutbounds(common.PelotonHostManager),
+			forwardOptions...,
 		)...,
 	)
 	procedures = append(
 		procedures,
 		apiserver.BuildJobManagerProcedures(
-			outbounds[common.PelotonJobManager],
+			forwardOutbounds(common.PelotonJobManager),
+			jobmgrForwardOptions...,
 		)...,
 	)
 	procedures = append(
 		procedures,
 		apiserver.BuildResourceManagerProcedures(
-			outbounds[common.PelotonResourceManager],
+			forwardOutbounds(common.PelotonResourceManager),
+			forwardOptions...,
 		)...,
 	)
 	dispatcher.Register(procedures)
@@ -312,6 +389,13 @@ func main() {
 	}
 	defer dispatcher.Stop()
 
+	// Start invalidating the response cache, the cache is
+	// only used while the changes to jobs and pods are watched.
+	if cacheInvalidator != nil {
+		cacheInvalidator.Start()
+		defer cacheInvalidator.Stop()
+	}
+
 	// Start collecting runtime metrics。
 	defer metrics.StartCollectingRuntimeMetrics(
 		rootScope,
@@ -114,6 +114,14 @@ var (
 		Envar("ARCHIVE_STEP_SIZE").
 		String()
 
+	retentionDryRun = app.Flag(
+		"retention-dry-run",
+		"Report the jobs and pod events to be deleted by the retention "+
+			"policies without deleting them (archiver.retention.dry_run override)").
+		Default("false").
+		Envar("RETENTION_DRY_RUN").
+		Bool()
+
 	kafkaTopic = app.Flag(
 		"kafka-topic",
 		"kafka topic used by archiver to stream completed jobs").
@@ -125,7 +133,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -218,6 +226,10 @@ func main() {
 		}
 	}
 
+	if *retentionDryRun {
+		cfg.Archiver.Retention.DryRun = *retentionDryRun
+	}
+
 	if *kafkaTopic != "" {
 		cfg.Archiver.KafkaTopic = *kafkaTopic
 	}
@@ -257,11 +269,10 @@ func main() {
 		mux,
 	)
 
-	discovery, err := leader.NewZkServiceDiscovery(
-		cfg.Election.ZKServers, cfg.Election.Root)
+	discovery, err := leader.NewServiceDiscovery(cfg.Election)
 	if err != nil {
 		log.WithError(err).
-			Fatal("Could not create zk service discovery")
+			Fatal("Could not create service discovery")
 	}
 
 	archiverEngine, err := engine.New(
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -109,7 +110,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -205,11 +206,10 @@ func main() {
 		cfg.GRPCPort, // dummy grpc port for aurora bridge
 		mux)
 
-	discovery, err := leader.NewZkServiceDiscovery(
-		cfg.Election.ZKServers, cfg.Election.Root)
+	discovery, err := leader.NewServiceDiscovery(cfg.Election)
 	if err != nil {
 		log.WithError(err).
-			Fatal("Could not create zk service discovery")
+			Fatal("Could not create service discovery")
 	}
 
 	clientRecvOption := grpc.ClientMaxRecvMsgSize(cfg.EventPublisher.GRPCMsgSize)
@@ -247,6 +247,11 @@ func main() {
 		log.WithError(err).
 			Fatal("Could not create rate limit middleware")
 	}
+	// The rate limits of the methods are checked before auth, so that
+	// unauthenticated calls are rate limited, and the rate limits of
+	// the callers after auth, so that callers are identified by the
+	// authenticated users.
+	callerRateLimitMiddleware := rateLimitMiddleware.CallerMiddleware()
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
@@ -266,9 +271,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  yarpc.UnaryInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
-			Stream: yarpc.StreamInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
-			Oneway: yarpc.OnewayInboundMiddleware(rateLimitMiddleware, authInboundMiddleware),
+			Unary:  yarpc.UnaryInboundMiddleware(rateLimitMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
+			Stream: yarpc.StreamInboundMiddleware(rateLimitMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
+			Oneway: yarpc.OnewayInboundMiddleware(rateLimitMiddleware, authInboundMiddleware, callerRateLimitMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -286,6 +291,9 @@ func main() {
 	podClient := podsvc.NewPodServiceYARPCClient(
 		dispatcher.ClientConfig(common.PelotonJobManager))
 
+	cronClient := cronsvc.NewCronJobServiceYARPCClient(
+		dispatcher.ClientConfig(common.PelotonJobManager))
+
 	respoolClient := respool.NewResourceManagerYARPCClient(
 		dispatcher.ClientConfig(common.PelotonResourceManager))
 
@@ -335,6 +343,7 @@ func main() {
 		jobClient,
 		jobmgrClient,
 		podClient,
+		cronClient,
 		respoolLoader,
 		bridgecommon.RandomImpl{},
 		cache.NewJobIDCache(),
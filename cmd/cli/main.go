@@ -16,12 +16,14 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math"
 	"os"
 	"strconv"
 	"strings"
 
 	pt "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbstateless "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 
 	pc "github.com/uber/peloton/pkg/cli"
 	"github.com/uber/peloton/pkg/cli/config"
@@ -94,9 +96,18 @@ var (
 		Envar("ZK_SERVERS").
 		Strings()
 
+	etcdEndpoints = app.Flag(
+		"etcdendpoints",
+		"etcd endpoints used for peloton service discovery when the "+
+			"leaders are elected on etcd. Specify multiple times for "+
+			"multiple endpoints"+
+			"(set $ETCD_ENDPOINTS to override with '\n' as delimiter)").
+		Envar("ETCD_ENDPOINTS").
+		Strings()
+
 	zkRoot = app.Flag(
 		"zkroot",
-		"zookeeper root path for peloton service discovery(set $ZK_ROOT to override)").
+		"zookeeper or etcd root path for peloton service discovery(set $ZK_ROOT to override)").
 		Default(common.DefaultLeaderElectionRoot).
 		Envar("ZK_ROOT").
 		String()
@@ -107,6 +118,20 @@ var (
 		Envar("BASIC_AUTH_CONFIG").
 		String()
 
+	authToken = app.Flag(
+		"authToken",
+		"bearer token issued by the oidc provider for jwt auth feature "+
+			"(set $PELOTON_AUTH_TOKEN to override)").
+		Envar("PELOTON_AUTH_TOKEN").
+		String()
+
+	authTokenFile = app.Flag(
+		"authTokenFile",
+		"file containing the bearer token issued by the oidc provider "+
+			"for jwt auth feature (set $PELOTON_AUTH_TOKEN_FILE to override)").
+		Envar("PELOTON_AUTH_TOKEN_FILE").
+		ExistingFile()
+
 	timeout = app.Flag(
 		"timeout",
 		"default RPC timeout (set $TIMEOUT to override)").
@@ -212,14 +237,16 @@ var (
 
 	watch = app.Command("watch", "watch job / pod runtime changes")
 
-	watchJob       = watch.Command("job", "watch job runtime changes")
-	watchJobIDList = watchJob.Arg("job", "job identifier").Strings()
-	watchJobLabels = watchJob.Flag("labels", "filter on labels (key:value pairs)").Strings()
+	watchJob              = watch.Command("job", "watch job runtime changes")
+	watchJobIDList        = watchJob.Arg("job", "job identifier").Strings()
+	watchJobLabels        = watchJob.Flag("labels", "filter on labels (key:value pairs)").Strings()
+	watchJobStartRevision = watchJob.Flag("start-revision", "resume the watch from the revision").Uint64()
 
-	watchPod         = watch.Command("pod", "watch pod runtime changes")
-	watchPodJobID    = watchPod.Arg("job", "job identifier").String()
-	watchPodPodNames = watchPod.Arg("pod", "pod name").Strings()
-	watchPodLabels   = watchPod.Flag("labels", "filter on labels (key:value pairs)").Strings()
+	watchPod              = watch.Command("pod", "watch pod runtime changes")
+	watchPodJobID         = watchPod.Arg("job", "job identifier").String()
+	watchPodPodNames      = watchPod.Arg("pod", "pod name").Strings()
+	watchPodLabels        = watchPod.Flag("labels", "filter on labels (key:value pairs)").Strings()
+	watchPodStartRevision = watchPod.Flag("start-revision", "resume the watch from the revision").Uint64()
 
 	watchCancel        = watch.Command("cancel", "cancel watch")
 	watchCancelWatchID = watchCancel.Arg("id", "watch id").Required().String()
@@ -300,6 +327,18 @@ var (
 		"start the update with best effort in-place update").Default("false").Bool()
 	statelessStartPods = statelessReplace.Flag("start-pods",
 		"start pods affected by the update if they are not running").Default("false").Bool()
+	statelessReplaceCanaryInstances = statelessReplace.Flag("canary-instances",
+		"number of instances to update in the canary stage. "+
+			"If the value is 0, the update has no canary stage.").Default("0").Uint32()
+	statelessReplaceCanaryBakeTime = statelessReplace.Flag("canary-bake-time",
+		"time to hold the update after the canary instances are updated "+
+			"before promoting it").Default("0s").Duration()
+	statelessReplaceCanaryMaxFailurePercent = statelessReplace.Flag("canary-max-failure-percent",
+		"maximum percentage of canary instances which may fail").Default("0").Float64()
+	statelessReplaceCanaryMaxHealthCheckFlaps = statelessReplace.Flag("canary-max-health-check-flaps",
+		"maximum number of times a canary instance may become unhealthy").Default("0").Uint32()
+	statelessReplaceCanaryMaxRestarts = statelessReplace.Flag("canary-max-restarts",
+		"maximum number of times a canary instance may be restarted").Default("0").Uint32()
 
 	statelessRollback              = stateless.Command("rollback", "rollback the job to a previous configuration")
 	statelessRollbackJobID         = statelessRollback.Arg("job", "job identifier").Required().String()
@@ -394,6 +433,52 @@ var (
 		"and the job cannot be re-created (with same uuid) till the delete is complete. "+
 		"USE WITH CAUTION!").Default("false").Short('f').Bool()
 
+	// Top level job command for batch jobs
+	batchJob = job.Command("batch", "manage batch jobs")
+
+	batchJobCreate            = batchJob.Command("create", "create batch job")
+	batchJobCreateResPoolPath = batchJobCreate.Arg("respool", "complete path of the "+
+		"resource pool starting from the root").Required().String()
+	batchJobCreateSpec = batchJobCreate.Arg("spec", "YAML job specification").Required().ExistingFile()
+	batchJobCreateID   = batchJobCreate.Flag("jobID", "optional job identifier, must be UUID format").Short('i').String()
+
+	batchJobGet            = batchJob.Command("get", "get batch job")
+	batchJobGetJobID       = batchJobGet.Arg("job", "job identifier").Required().String()
+	batchJobGetSummaryOnly = batchJobGet.Flag("summaryonly", "only return the job summary").Default("false").Bool()
+
+	batchJobQuery            = batchJob.Command("query", "query batch jobs by mesos label / respool")
+	batchJobQueryLabels      = batchJobQuery.Flag("labels", "labels").Default("").Short('l').String()
+	batchJobQueryRespoolPath = batchJobQuery.Flag("respool", "respool path").Default("").Short('r').String()
+	batchJobQueryKeywords    = batchJobQuery.Flag("keywords", "keywords").Default("").Short('k').String()
+	batchJobQueryStates      = batchJobQuery.Flag("states", "job states").Default("").Short('s').String()
+	batchJobQueryOwner       = batchJobQuery.Flag("owner", "job owner").Default("").String()
+	batchJobQueryName        = batchJobQuery.Flag("name", "job name").Default("").String()
+	batchJobQueryTimeRange   = batchJobQuery.Flag("timerange", "query jobs created within last d days").Short('d').Default("0").Uint32()
+	batchJobQueryLimit       = batchJobQuery.Flag("limit", "maximum number of jobs to return").Default("100").Short('n').Uint32()
+	batchJobQueryMaxLimit    = batchJobQuery.Flag("total", "total number of jobs to query").Default("100").Short('q').Uint32()
+	batchJobQueryOffset      = batchJobQuery.Flag("offset", "offset").Default("0").Short('o').Uint32()
+	batchJobQuerySortBy      = batchJobQuery.Flag("sort", "sort by property").Default("creation_time").Short('p').String()
+	batchJobQuerySortOrder   = batchJobQuery.Flag("sortorder", "sort order (ASC or DESC)").Default("DESC").Short('a').String()
+
+	batchJobStop              = batchJob.Command("stop", "stop all pods in a batch job")
+	batchJobStopJobID         = batchJobStop.Arg("job", "job identifier").Required().String()
+	batchJobStopEntityVersion = batchJobStop.Arg("entityVersion",
+		"entity version for concurrency control").Required().String()
+
+	batchJobDelete              = batchJob.Command("delete", "delete a batch job")
+	batchJobDeleteJobID         = batchJobDelete.Arg("job", "job identifier").Required().String()
+	batchJobDeleteEntityVersion = batchJobDelete.Arg("entityVersion",
+		"entity version for concurrency control").Required().String()
+	batchJobDeleteForce = batchJobDelete.Flag("force", "force delete the job even if it is running. "+
+		"USE WITH CAUTION!").Default("false").Short('f').Bool()
+
+	batchJobListPods              = batchJob.Command("list-pods", "list all pods in a batch job")
+	batchJobListPodsJobID         = batchJobListPods.Arg("job", "job identifier").Required().String()
+	batchJobListPodsInstanceRange = taskRangeFlag(batchJobListPods.Flag("range", "show range of instances (from:to syntax)").Default(":").Short('r'))
+
+	batchJobGetCache     = batchJob.Command("cache", "get a batch job cache")
+	batchJobGetCacheName = batchJobGetCache.Arg("job", "job identifier").Required().String()
+
 	// Top level pod command
 	pod = app.Command("pod", "CLI reflects pod(s) actions, such as get pod details, create/restart/update a pod...")
 
@@ -540,6 +625,10 @@ var (
 	resMgrOrphanTasks          = resMgrTasks.Command("orphan", "fetch orphan tasks in resource manager")
 	resMgrOrphanTasksRespoolID = resMgrOrphanTasks.Flag("respool", "resource pool identifier").Default("").String()
 
+	resMgrSLAViolations          = resMgr.Command("sla-violations", "fetch state transition SLA violations of active tasks in resource manager")
+	resMgrSLAViolationsJobID     = resMgrSLAViolations.Flag("job", "job identifier").Default("").String()
+	resMgrSLAViolationsRespoolID = resMgrSLAViolations.Flag("respool", "resource pool identifier").Default("").String()
+
 	// Top level resource pool command
 	resPool = app.Command("respool", "manage resource pools")
 
@@ -577,6 +666,19 @@ var (
 	hostMaintenanceComplete         = hostMaintenance.Command("complete", "complete maintenance on a host")
 	hostMaintenanceCompleteHostname = hostMaintenanceComplete.Arg("hostname", "hostname").Required().String()
 
+	hostMaintenanceWindow = hostMaintenance.Command("window", "scheduled maintenance windows")
+
+	hostMaintenanceWindowCreate         = hostMaintenanceWindow.Command("create", "schedule maintenance of hosts")
+	hostMaintenanceWindowCreateHosts    = hostMaintenanceWindowCreate.Flag("hosts", "comma separated hostnames").Default("").String()
+	hostMaintenanceWindowCreatePool     = hostMaintenanceWindowCreate.Flag("pool", "host pool whose hosts are put into maintenance").Default("").String()
+	hostMaintenanceWindowCreateStart    = hostMaintenanceWindowCreate.Flag("start", "start time in RFC3339 format, now if not set").Default("").String()
+	hostMaintenanceWindowCreateDuration = hostMaintenanceWindowCreate.Flag("duration", "duration of the window (e.g. 2h)").Required().Duration()
+
+	hostMaintenanceWindowList = hostMaintenanceWindow.Command("list", "list maintenance windows")
+
+	hostMaintenanceWindowDelete   = hostMaintenanceWindow.Command("delete", "delete a maintenance window")
+	hostMaintenanceWindowDeleteID = hostMaintenanceWindowDelete.Arg("id", "maintenance window id").Required().String()
+
 	hostQuery       = host.Command("query", "query hosts by state(s)")
 	hostQueryStates = hostQuery.Flag("states", "host state(s) to filter").Default("").Short('s').String()
 
@@ -589,6 +691,71 @@ var (
 	volumeDelete         = volume.Command("delete", "delete a volume")
 	volumeDeleteVolumeID = volumeDelete.Arg("volume", "volume identifier").Required().String()
 
+	// Top level cron job command
+	cronJob = app.Command("cron", "manage cron scheduled batch jobs")
+
+	cronJobCreate            = cronJob.Command("create", "create a cron job")
+	cronJobCreateName        = cronJobCreate.Arg("name", "cron job name").Required().String()
+	cronJobCreateResPoolPath = cronJobCreate.Arg("respool", "complete path of the "+
+		"resource pool starting from the root").Required().String()
+	cronJobCreateSchedule = cronJobCreate.Arg("schedule", "cron schedule, "+
+		"e.g. \"*/5 * * * *\" or @hourly").Required().String()
+	cronJobCreateConfig       = cronJobCreate.Arg("config", "YAML batch job template").Required().ExistingFile()
+	cronJobCreatePolicy       = cronJobCreate.Flag("policy", "concurrency policy").Default("skip").Enum("skip", "replace", "allow")
+	cronJobCreateHistoryLimit = cronJobCreate.Flag("history-limit", "number of finished jobs to keep, 0 for server default").Default("0").Uint32()
+	cronJobCreatePaused       = cronJobCreate.Flag("paused", "create the cron job paused").Default("false").Bool()
+
+	cronJobReplace            = cronJob.Command("replace", "replace the configuration of a cron job")
+	cronJobReplaceName        = cronJobReplace.Arg("name", "cron job name").Required().String()
+	cronJobReplaceResPoolPath = cronJobReplace.Arg("respool", "complete path of the "+
+		"resource pool starting from the root").Required().String()
+	cronJobReplaceSchedule = cronJobReplace.Arg("schedule", "cron schedule, "+
+		"e.g. \"*/5 * * * *\" or @hourly").Required().String()
+	cronJobReplaceConfig       = cronJobReplace.Arg("config", "YAML batch job template").Required().ExistingFile()
+	cronJobReplacePolicy       = cronJobReplace.Flag("policy", "concurrency policy").Default("skip").Enum("skip", "replace", "allow")
+	cronJobReplaceHistoryLimit = cronJobReplace.Flag("history-limit", "number of finished jobs to keep, 0 for server default").Default("0").Uint32()
+	cronJobReplacePaused       = cronJobReplace.Flag("paused", "pause the cron job").Default("false").Bool()
+
+	cronJobGet     = cronJob.Command("get", "get a cron job")
+	cronJobGetName = cronJobGet.Arg("name", "cron job name").Required().String()
+
+	cronJobList = cronJob.Command("list", "list all cron jobs")
+
+	cronJobDelete     = cronJob.Command("delete", "delete a cron job, jobs already created are not affected")
+	cronJobDeleteName = cronJobDelete.Arg("name", "cron job name").Required().String()
+
+	cronJobRun     = cronJob.Command("run", "run a cron job immediately")
+	cronJobRunName = cronJobRun.Arg("name", "cron job name").Required().String()
+
+	// Top level DAG command
+	dagCmd = app.Command("dag", "manage DAGs of dependent batch jobs")
+
+	dagCreate            = dagCmd.Command("create", "create a DAG")
+	dagCreateResPoolPath = dagCreate.Arg("respool", "complete path of the "+
+		"resource pool starting from the root of the jobs of the DAG").Required().String()
+	dagCreateConfig = dagCreate.Arg("config", "YAML DAG config, the job configs of the "+
+		"nodes are paths relative to it").Required().ExistingFile()
+
+	dagGet   = dagCmd.Command("get", "get a DAG")
+	dagGetID = dagGet.Arg("id", "DAG identifier").Required().String()
+
+	dagList = dagCmd.Command("list", "list all DAGs")
+
+	dagCancel   = dagCmd.Command("cancel", "cancel a DAG, the jobs of its running nodes are killed")
+	dagCancelID = dagCancel.Arg("id", "DAG identifier").Required().String()
+
+	// Top level audit command
+	audit = app.Command("audit", "query the audit log of the mutating API calls")
+
+	auditQuery          = audit.Command("query", "query the audit events, most recent first")
+	auditQueryStart     = auditQuery.Flag("start", "start of the time range in RFC3339 format, defaults to one day before the end").Default("").String()
+	auditQueryEnd       = auditQuery.Flag("end", "end of the time range in RFC3339 format, defaults to now").Default("").String()
+	auditQueryUser      = auditQuery.Flag("user", "only the calls made by the user").Default("").String()
+	auditQueryProcedure = auditQuery.Flag("procedure", "only the calls to procedures containing the string, e.g. StopJob").Default("").String()
+	auditQueryResource  = auditQuery.Flag("resource", "only the calls targeting the job, pod, resource pool or host").Default("").String()
+	auditQueryOutcome   = auditQuery.Flag("outcome", "only the calls with the outcome").Default("").Enum("", "succeeded", "failed")
+	auditQueryLimit     = auditQuery.Flag("limit", "maximum number of events to return, 0 for server default").Default("0").Uint32()
+
 	// Top level job update command
 	update = app.Command("update", "manage job updates")
 
@@ -891,6 +1058,12 @@ func main() {
 	var discovery leader.Discovery
 	if len(*zkServers) > 0 {
 		discovery, err = leader.NewZkServiceDiscovery(*zkServers, *zkRoot)
+	} else if len(*etcdEndpoints) > 0 {
+		discovery, err = leader.NewServiceDiscovery(leader.ElectionConfig{
+			Backend:       leader.EtcdBackend,
+			EtcdEndpoints: *etcdEndpoints,
+			Root:          *zkRoot,
+		})
 	} else {
 		discovery, err = leader.NewStaticServiceDiscovery(*jobMgrURL, *resMgrURL, *hostMgrURL)
 	}
@@ -898,16 +1071,29 @@ func main() {
 		app.FatalIfError(err, "Fail to initialize service discovery")
 	}
 
-	var basicAuthConfigPtr *middleware.BasicAuthConfig
-	if len(*basicAuthConfigFile) != 0 {
-		var basicAuthConfig middleware.BasicAuthConfig
-		if err := common_config.Parse(&basicAuthConfig, *basicAuthConfigFile); err != nil {
-			app.FatalIfError(err, "Fail to load auth config file")
+	var authMiddleware middleware.OutboundMiddleware
+	switch {
+	case len(*authToken) != 0:
+		authMiddleware = middleware.NewBearerTokenOutboundMiddleware(*authToken)
+	case len(*authTokenFile) != 0:
+		token, err := ioutil.ReadFile(*authTokenFile)
+		if err != nil {
+			app.FatalIfError(err, "Fail to read auth token file")
 		}
-		basicAuthConfigPtr = &basicAuthConfig
+		authMiddleware = middleware.NewBearerTokenOutboundMiddleware(string(token))
+	default:
+		var basicAuthConfigPtr *middleware.BasicAuthConfig
+		if len(*basicAuthConfigFile) != 0 {
+			var basicAuthConfig middleware.BasicAuthConfig
+			if err := common_config.Parse(&basicAuthConfig, *basicAuthConfigFile); err != nil {
+				app.FatalIfError(err, "Fail to load auth config file")
+			}
+			basicAuthConfigPtr = &basicAuthConfig
+		}
+		authMiddleware = middleware.NewBasicAuthOutboundMiddleware(basicAuthConfigPtr)
 	}
 
-	client, err := pc.New(discovery, *timeout, basicAuthConfigPtr, *jsonFormat)
+	client, err := pc.New(discovery, *timeout, authMiddleware, *jsonFormat)
 	if err != nil {
 		app.FatalIfError(err, "Fail to initialize client")
 	}
@@ -977,6 +1163,17 @@ func main() {
 		err = client.HostMaintenanceStartAction(*hostMaintenanceStartHostname)
 	case hostMaintenanceComplete.FullCommand():
 		err = client.HostMaintenanceCompleteAction(*hostMaintenanceCompleteHostname)
+	case hostMaintenanceWindowCreate.FullCommand():
+		err = client.HostMaintenanceWindowCreateAction(
+			*hostMaintenanceWindowCreateHosts,
+			*hostMaintenanceWindowCreatePool,
+			*hostMaintenanceWindowCreateStart,
+			*hostMaintenanceWindowCreateDuration,
+		)
+	case hostMaintenanceWindowList.FullCommand():
+		err = client.HostMaintenanceWindowListAction()
+	case hostMaintenanceWindowDelete.FullCommand():
+		err = client.HostMaintenanceWindowDeleteAction(*hostMaintenanceWindowDeleteID)
 	case hostQuery.FullCommand():
 		err = client.HostQueryAction(*hostQueryStates)
 	case hostcacheDump.FullCommand():
@@ -992,6 +1189,8 @@ func main() {
 			uint32(*resMgrPendingTasksGetLimit))
 	case resMgrOrphanTasks.FullCommand():
 		err = client.ResMgrGetOrphanTasks(*resMgrOrphanTasksRespoolID)
+	case resMgrSLAViolations.FullCommand():
+		err = client.ResMgrGetSLAViolations(*resMgrSLAViolationsJobID, *resMgrSLAViolationsRespoolID)
 	case resPoolCreate.FullCommand():
 		err = client.ResPoolCreateAction(*resPoolCreatePath, *resPoolCreateConfig)
 	case respoolUpdate.FullCommand():
@@ -1004,6 +1203,52 @@ func main() {
 		err = client.VolumeListAction(*volumeListJobName)
 	case volumeDelete.FullCommand():
 		err = client.VolumeDeleteAction(*volumeDeleteVolumeID)
+	case cronJobCreate.FullCommand():
+		err = client.CronJobCreateAction(
+			*cronJobCreateName,
+			*cronJobCreateResPoolPath,
+			*cronJobCreateSchedule,
+			*cronJobCreatePolicy,
+			*cronJobCreateHistoryLimit,
+			*cronJobCreatePaused,
+			*cronJobCreateConfig,
+		)
+	case cronJobReplace.FullCommand():
+		err = client.CronJobReplaceAction(
+			*cronJobReplaceName,
+			*cronJobReplaceResPoolPath,
+			*cronJobReplaceSchedule,
+			*cronJobReplacePolicy,
+			*cronJobReplaceHistoryLimit,
+			*cronJobReplacePaused,
+			*cronJobReplaceConfig,
+		)
+	case cronJobGet.FullCommand():
+		err = client.CronJobGetAction(*cronJobGetName)
+	case cronJobList.FullCommand():
+		err = client.CronJobListAction()
+	case cronJobDelete.FullCommand():
+		err = client.CronJobDeleteAction(*cronJobDeleteName)
+	case cronJobRun.FullCommand():
+		err = client.CronJobRunAction(*cronJobRunName)
+	case dagCreate.FullCommand():
+		err = client.DAGCreateAction(*dagCreateResPoolPath, *dagCreateConfig)
+	case dagGet.FullCommand():
+		err = client.DAGGetAction(*dagGetID)
+	case dagList.FullCommand():
+		err = client.DAGListAction()
+	case dagCancel.FullCommand():
+		err = client.DAGCancelAction(*dagCancelID)
+	case auditQuery.FullCommand():
+		err = client.AuditQueryAction(
+			*auditQueryStart,
+			*auditQueryEnd,
+			*auditQueryUser,
+			*auditQueryProcedure,
+			*auditQueryResource,
+			*auditQueryOutcome,
+			*auditQueryLimit,
+		)
 	case updateCreate.FullCommand():
 		err = client.UpdateCreateAction(
 			*updateJobID,
@@ -1088,6 +1333,16 @@ func main() {
 	case statelessQuery.FullCommand():
 		err = client.StatelessQueryAction(*statelessQueryLabels, *statelessQueryRespoolPath, *statelessQueryKeywords, *statelessQueryStates, *statelessQueryOwner, *statelessQueryName, *statelessQueryTimeRange, *statelessQueryLimit, *statelessQueryMaxLimit, *statelessQueryOffset, *statelessQuerySortBy, *statelessQuerySortOrder)
 	case statelessReplace.FullCommand():
+		var canary *pbstateless.CanarySpec
+		if *statelessReplaceCanaryInstances > 0 {
+			canary = &pbstateless.CanarySpec{
+				InstanceCount:             *statelessReplaceCanaryInstances,
+				BakeTimeSeconds:           uint32(statelessReplaceCanaryBakeTime.Seconds()),
+				MaxInstanceFailurePercent: *statelessReplaceCanaryMaxFailurePercent,
+				MaxHealthCheckFlaps:       *statelessReplaceCanaryMaxHealthCheckFlaps,
+				MaxRestarts:               *statelessReplaceCanaryMaxRestarts,
+			}
+		}
 		err = client.StatelessReplaceJobAction(
 			*statelessReplaceJobID,
 			*statelessReplaceSpec,
@@ -1102,6 +1357,7 @@ func main() {
 			*statelessReplaceOpaqueData,
 			*statelessReplaceInPlace,
 			*statelessStartPods,
+			canary,
 		)
 	case statelessRollback.FullCommand():
 		err = client.StatelessRollbackJobAction(
@@ -1186,10 +1442,37 @@ func main() {
 			*statelessDeleteEntityVersion,
 			*statelessDeleteForce,
 		)
+	case batchJobCreate.FullCommand():
+		err = client.BatchJobCreateAction(
+			*batchJobCreateID,
+			*batchJobCreateResPoolPath,
+			*batchJobCreateSpec,
+		)
+	case batchJobGet.FullCommand():
+		err = client.BatchJobGetAction(*batchJobGetJobID, *batchJobGetSummaryOnly)
+	case batchJobQuery.FullCommand():
+		err = client.BatchJobQueryAction(*batchJobQueryLabels, *batchJobQueryRespoolPath, *batchJobQueryKeywords, *batchJobQueryStates, *batchJobQueryOwner, *batchJobQueryName, *batchJobQueryTimeRange, *batchJobQueryLimit, *batchJobQueryMaxLimit, *batchJobQueryOffset, *batchJobQuerySortBy, *batchJobQuerySortOrder)
+	case batchJobStop.FullCommand():
+		err = client.BatchJobStopAction(*batchJobStopJobID, *batchJobStopEntityVersion)
+	case batchJobDelete.FullCommand():
+		err = client.BatchJobDeleteAction(
+			*batchJobDeleteJobID,
+			*batchJobDeleteEntityVersion,
+			*batchJobDeleteForce,
+		)
+	case batchJobListPods.FullCommand():
+		err = client.BatchJobListPodsAction(*batchJobListPodsJobID, batchJobListPodsInstanceRange)
+	case batchJobGetCache.FullCommand():
+		err = client.BatchJobGetCacheAction(*batchJobGetCacheName)
 	case watchJob.FullCommand():
-		err = client.WatchJob(*watchJobIDList, *watchJobLabels)
+		err = client.WatchJob(*watchJobIDList, *watchJobLabels, *watchJobStartRevision)
 	case watchPod.FullCommand():
-		err = client.WatchPod(*watchPodJobID, *watchPodPodNames, *watchPodLabels)
+		err = client.WatchPod(
+			*watchPodJobID,
+			*watchPodPodNames,
+			*watchPodLabels,
+			*watchPodStartRevision,
+		)
 	case watchCancel.FullCommand():
 		err = client.CancelWatch(*watchCancelWatchID)
 	case lock.FullCommand():
@@ -16,6 +16,7 @@ package main
 
 import (
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -36,5 +37,6 @@ type Config struct {
 	Health       health.Config         `yaml:"health"`
 	SentryConfig logging.SentryConfig  `yaml:"sentry"`
 	Auth         auth.Config           `yaml:"auth"`
+	Audit        audit.Config          `yaml:"audit"`
 	K8s          p2kconfig.K8sConfig   `yaml:"k8s"`
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/uber/peloton/pkg/auth"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/backoff"
 	"github.com/uber/peloton/pkg/common/buildversion"
@@ -207,7 +208,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -500,6 +501,21 @@ func main() {
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
+	var auditSink audit.Sink
+	if cfg.Audit.Enabled {
+		auditSink, err = audit.NewSink(&cfg.Audit, ormStore, rootScope)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Could not create audit sink")
+		}
+	}
+	auditInboundMiddleware, err := inbound.NewAuditInboundMiddleware(
+		cfg.Audit, common.PelotonHostManager, auditSink)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create audit middleware")
+	}
+
 	securityClient, err := auth_impl.CreateNewSecurityClient(&cfg.Auth)
 	if err != nil {
 		log.WithError(err).
@@ -517,9 +533,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  yarpc.UnaryInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware),
-			Oneway: yarpc.OnewayInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware),
-			Stream: yarpc.StreamInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware),
+			Unary:  yarpc.UnaryInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware),
+			Oneway: yarpc.OnewayInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware),
+			Stream: yarpc.StreamInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -769,10 +785,12 @@ func main() {
 
 	hostDrainer := drainer.NewDrainer(
 		cfg.HostManager.HostDrainerPeriod,
+		cfg.HostManager.MaintenanceWindowPeriod,
 		cfg.Mesos.Framework.Role,
 		masterOperatorClient,
 		goalStateDriver,
 		ormobjects.GetHostInfoOps(),
+		ormobjects.NewMaintenanceWindowOps(ormStore),
 		taskEvictionQueue,
 	)
 
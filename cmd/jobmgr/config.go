@@ -16,6 +16,7 @@ package main
 
 import (
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -34,6 +35,7 @@ type Config struct {
 	Health       health.Config           `yaml:"health"`
 	SentryConfig logging.SentryConfig    `yaml:"sentry"`
 	Auth         auth.Config             `yaml:"auth"`
+	Audit        audit.Config            `yaml:"audit"`
 	RateLimit    inbound.RateLimitConfig `yaml:"rate_limit"`
 	// APILock defines which APIs are read/write APIs,
 	// so when lockdown is requested, the correct APIs are locked.
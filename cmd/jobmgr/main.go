@@ -15,17 +15,21 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"time"
 
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 
 	"github.com/uber/peloton/pkg/auth"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
@@ -37,15 +41,23 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
 	"github.com/uber/peloton/pkg/jobmgr"
 	"github.com/uber/peloton/pkg/jobmgr/adminsvc"
+	"github.com/uber/peloton/pkg/jobmgr/auditsvc"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/cron"
+	"github.com/uber/peloton/pkg/jobmgr/cronsvc"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
+	"github.com/uber/peloton/pkg/jobmgr/dag"
+	"github.com/uber/peloton/pkg/jobmgr/dagsvc"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc/batch"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/private"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/stateless"
 	"github.com/uber/peloton/pkg/jobmgr/logmanager"
 	"github.com/uber/peloton/pkg/jobmgr/podsvc"
 	"github.com/uber/peloton/pkg/jobmgr/task/activermtask"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
+	"github.com/uber/peloton/pkg/jobmgr/task/descheduler"
 	"github.com/uber/peloton/pkg/jobmgr/task/event"
 	"github.com/uber/peloton/pkg/jobmgr/task/evictor"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
@@ -57,6 +69,7 @@ import (
 	"github.com/uber/peloton/pkg/middleware/inbound"
 	"github.com/uber/peloton/pkg/middleware/outbound"
 	"github.com/uber/peloton/pkg/storage/cassandra"
+	"github.com/uber/peloton/pkg/storage/encryption"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
@@ -197,7 +210,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -438,6 +451,11 @@ func main() {
 	if ormErr != nil {
 		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
 	}
+	secretEncrypter, encErr := encryption.New(&cfg.Storage.SecretEncryption)
+	if encErr != nil {
+		log.WithError(encErr).Fatal("Failed to create secret encrypter")
+	}
+	ormStore.SetSecretEncrypter(secretEncrypter)
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -502,7 +520,27 @@ func main() {
 		log.WithError(err).
 			Fatal("Could not create rate limit middleware")
 	}
+	// The rate limits of the methods are checked before auth, so that
+	// unauthenticated calls are rate limited, and the rate limits of
+	// the callers after auth, so that callers are identified by the
+	// authenticated users.
+	callerRateLimitMiddleware := rateLimitMiddleware.CallerMiddleware()
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
+
+	var auditSink audit.Sink
+	if cfg.Audit.Enabled {
+		auditSink, err = audit.NewSink(&cfg.Audit, ormStore, rootScope)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Could not create audit sink")
+		}
+	}
+	auditInboundMiddleware, err := inbound.NewAuditInboundMiddleware(
+		cfg.Audit, common.PelotonJobManager, auditSink)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create audit middleware")
+	}
 	apiLockInboundMiddleware := inbound.NewAPILockInboundMiddleware(&cfg.APILock)
 
 	yarpcMetricsMiddleware := &inbound.YAPRCMetricsInboundMiddleware{Scope: rootScope.SubScope("yarpc")}
@@ -523,9 +561,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  yarpc.UnaryInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, authInboundMiddleware, yarpcMetricsMiddleware),
-			Stream: yarpc.StreamInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, authInboundMiddleware, yarpcMetricsMiddleware),
-			Oneway: yarpc.OnewayInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, authInboundMiddleware, yarpcMetricsMiddleware),
+			Unary:  yarpc.UnaryInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware, yarpcMetricsMiddleware),
+			Stream: yarpc.StreamInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware, yarpcMetricsMiddleware),
+			Oneway: yarpc.OnewayInboundMiddleware(apiLockInboundMiddleware, rateLimitMiddleware, auditInboundMiddleware, authInboundMiddleware, callerRateLimitMiddleware, yarpcMetricsMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -549,6 +587,31 @@ func main() {
 			Period: time.Duration(cfg.JobManager.ActiveTaskUpdatePeriod),
 		},
 	)
+
+	// Register the re-encryption of the secrets not encrypted
+	// with the current key
+	rotationPeriod := cfg.Storage.SecretEncryption.RotationPeriod
+	if secretEncrypter != nil && rotationPeriod > 0 {
+		secretInfoOps := ormobjects.NewSecretInfoOps(ormStore)
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "SecretKeyRotation",
+				Func: func(_ *atomic.Bool) {
+					ctx, cancel := context.WithTimeout(
+						context.Background(), rotationPeriod)
+					defer cancel()
+					count, err := secretInfoOps.ReencryptSecrets(ctx)
+					if err != nil {
+						log.WithError(err).Warn("Failed to re-encrypt secrets")
+					}
+					if count > 0 {
+						log.WithField("count", count).Info("Re-encrypted secrets")
+					}
+				},
+				Period: rotationPeriod,
+			},
+		)
+	}
 	watchProcessor := watchsvc.InitV1AlphaWatchServiceHandler(
 		dispatcher,
 		rootScope,
@@ -625,6 +688,17 @@ func main() {
 		&cfg.JobManager.Deadline,
 	)
 
+	// Create a new descheduler to relocate stateless pods
+	deschedulerInstance := descheduler.New(
+		dispatcher,
+		common.PelotonHostManager,
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		rootScope,
+		&cfg.JobManager.Descheduler,
+	)
+
 	// Create the Task status update which pulls task update events
 	// from HM once started after gaining leadership
 	statusUpdate := event.NewTaskStatusUpdate(
@@ -639,6 +713,21 @@ func main() {
 		cfg.JobManager.HostManagerAPIVersion,
 	)
 
+	// Create the daemon job manager, which keeps one instance of each
+	// daemon job on every eligible host and only runs on the leader
+	daemonManager := daemon.New(
+		ormStore,
+		store, // store implements UpdateStore
+		jobFactory,
+		goalStateDriver,
+		host_svc.NewHostServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonHostManager)),
+		hostsvc.NewInternalHostServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonHostManager)),
+		rootScope,
+		&cfg.JobManager.Daemon,
+	)
+
 	server := jobmgr.NewServer(
 		cfg.JobManager.HTTPPort,
 		cfg.JobManager.GRPCPort,
@@ -646,10 +735,12 @@ func main() {
 		goalStateDriver,
 		taskEvictor,
 		deadlineTracker,
+		deschedulerInstance,
 		placementProcessor,
 		statusUpdate,
 		backgroundManager,
 		watchProcessor,
+		daemonManager,
 	)
 
 	candidate, err := leader.NewCandidate(
@@ -662,7 +753,7 @@ func main() {
 		log.Fatalf("Unable to create leader candidate: %v", err)
 	}
 
-	jobsvc.InitServiceHandler(
+	jobSvcHandler := jobsvc.InitServiceHandler(
 		dispatcher,
 		rootScope,
 		store, // store implements JobStore
@@ -675,6 +766,66 @@ func main() {
 		cfg.JobManager.JobSvcCfg,
 	)
 
+	// Create the cron scheduler, which creates jobs through the job
+	// service and only runs on the leader as a background work. The
+	// runs are authorized for the owners of the cron jobs if the
+	// security manager can look them up.
+	cronUsers, _ := securityManager.(auth.UserLookup)
+	cronScheduler := cron.New(
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		jobSvcHandler,
+		respool.NewResourceManagerYARPCClient(
+			dispatcher.ClientConfig(common.PelotonResourceManager)),
+		cronUsers,
+		rootScope,
+		&cfg.JobManager.Cron,
+	)
+	if err := cronScheduler.Register(backgroundManager); err != nil {
+		log.WithError(err).
+			Fatal("fail to register cronScheduler in backgroundManager")
+	}
+
+	cronsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+		cronScheduler,
+		candidate,
+		common.PelotonResourceManager,
+	)
+
+	// Create the DAG manager, which creates the jobs of the nodes of
+	// DAGs through the job service and only evaluates DAGs on the leader
+	dagManager := dag.New(
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		jobSvcHandler,
+		rootScope,
+		&cfg.JobManager.DAG,
+	)
+	if err := dagManager.Register(backgroundManager); err != nil {
+		log.WithError(err).
+			Fatal("fail to register dagManager in backgroundManager")
+	}
+
+	dagsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+		dagManager,
+		candidate,
+		common.PelotonResourceManager,
+	)
+
+	auditsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+	)
+
 	private.InitPrivateJobServiceHandler(
 		dispatcher,
 		store,
@@ -699,6 +850,17 @@ func main() {
 		activeJobCache,
 	)
 
+	batch.InitV1AlphaBatchJobServiceHandler(
+		dispatcher,
+		store,
+		store,
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		candidate,
+		cfg.JobManager.JobSvcCfg,
+	)
+
 	tasksvc.InitServiceHandler(
 		dispatcher,
 		rootScope,
@@ -15,14 +15,18 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/storage/cassandra"
+	"github.com/uber/peloton/pkg/storage/encryption"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -64,6 +68,10 @@ var (
 	// Top level migrate DB commands
 	upCmd      = app.Command("up", "Apply all DB migrations. Will create keyspace if not exists")
 	versionCmd = app.Command("version", "Get the current schema version.")
+	encryptCmd = app.Command(
+		"encrypt-secrets",
+		"Encrypt the secrets stored in plaintext or with an old key "+
+			"using the configured secret encryption.")
 )
 
 func main() {
@@ -126,6 +134,34 @@ func main() {
 			log.Fatalf("Could not get schema version: %v", err)
 		}
 		log.WithField("version", version).Info("Database schema version")
+	case encryptCmd.FullCommand():
+		encryptSecrets(&cfg)
+	}
+}
+
+// encryptSecrets encrypts the existing secrets with the current key of
+// the configured secret encryption
+func encryptSecrets(cfg *Config) {
+	encrypter, err := encryption.New(&cfg.Storage.SecretEncryption)
+	if err != nil {
+		log.Fatalf("Could not create secret encrypter: %v", err)
+	}
+	if encrypter == nil {
+		log.Fatal("Secret encryption is not configured")
 	}
 
+	ormStore, err := ormobjects.NewCassandraStore(
+		cassandra.ToOrmConfig(&cfg.Storage.Cassandra),
+		tally.NoopScope)
+	if err != nil {
+		log.Fatalf("Could not create ORM store: %v", err)
+	}
+	ormStore.SetSecretEncrypter(encrypter)
+
+	count, err := ormobjects.NewSecretInfoOps(ormStore).
+		ReencryptSecrets(context.Background())
+	if err != nil {
+		log.Fatalf("Could not encrypt secrets: %v", err)
+	}
+	log.WithField("count", count).Info("Encrypted secrets")
 }
@@ -159,7 +159,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -493,8 +493,10 @@ func overridePlacementStrategy(taskType string, cfg *config.Config) {
 
 	cfg.Placement.TaskType = resmgr.TaskType(tt)
 	switch cfg.Placement.TaskType {
-	case resmgr.TaskType_STATEFUL, resmgr.TaskType_STATELESS:
-		// Use mimir strategy for stateful and stateless task placement.
+	case resmgr.TaskType_STATEFUL, resmgr.TaskType_STATELESS,
+		resmgr.TaskType_DAEMON:
+		// Use mimir strategy for stateful, stateless and daemon task
+		// placement.
 		cfg.Placement.Strategy = config.Mimir
 		cfg.Placement.FetchOfferTasks = true
 	default:
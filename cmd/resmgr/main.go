@@ -24,6 +24,7 @@ import (
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/audit"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/health"
@@ -155,7 +156,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "RBAC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -352,6 +353,21 @@ func main() {
 	}
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
+
+	var auditSink audit.Sink
+	if cfg.Audit.Enabled {
+		auditSink, err = audit.NewSink(&cfg.Audit, ormStore, rootScope)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Could not create audit sink")
+		}
+	}
+	auditInboundMiddleware, err := inbound.NewAuditInboundMiddleware(
+		cfg.Audit, common.PelotonResourceManager, auditSink)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create audit middleware")
+	}
 	yarpcMetricsMiddleware := &inbound.YAPRCMetricsInboundMiddleware{Scope: rootScope.SubScope("yarpc")}
 
 	securityClient, err := auth_impl.CreateNewSecurityClient(&cfg.Auth)
@@ -371,9 +387,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  yarpc.UnaryInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
-			Oneway: yarpc.OnewayInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
-			Stream: yarpc.StreamInboundMiddleware(authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
+			Unary:  yarpc.UnaryInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
+			Oneway: yarpc.OnewayInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
+			Stream: yarpc.StreamInboundMiddleware(auditInboundMiddleware, authInboundMiddleware, leaderCheckMiddleware, yarpcMetricsMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -441,6 +457,12 @@ func main() {
 	)
 
 	// Initializing the task preemptor
+	if err := preemption.ValidateRanker(
+		cfg.ResManager.PreemptionConfig.Ranker); err != nil {
+		log.WithField("ranker_name", cfg.ResManager.PreemptionConfig.Ranker).
+			WithError(err).
+			Fatal("Ranker not found")
+	}
 	preemptor := preemption.NewPreemptor(
 		rootScope,
 		cfg.ResManager.PreemptionConfig,
@@ -462,6 +484,10 @@ func main() {
 		hostServiceClient)
 
 	// Initialize resource manager service handlers
+	if err := resmgr.ValidateGangPlacementTimeout(
+		cfg.ResManager); err != nil {
+		log.WithError(err).Fatal("Invalid gang placement timeout")
+	}
 	serviceHandler := resmgr.NewServiceHandler(
 		dispatcher,
 		rootScope,
@@ -473,6 +499,19 @@ func main() {
 		cfg.ResManager,
 	)
 
+	// Initializing the SLA checker, the members of a gang being placed are
+	// requeued with their gang by the service handler
+	if err := task.ValidateSLARules(
+		cfg.ResManager.RmTaskConfig.SLARules); err != nil {
+		log.WithError(err).Fatal("Invalid task SLA rules")
+	}
+	slaChecker := task.NewSLAChecker(
+		task.GetTracker(),
+		serviceHandler,
+		rootScope,
+		cfg.ResManager.RmTaskConfig.SLACheckPeriod,
+	)
+
 	// Initialize recovery
 	recoveryHandler := resmgr.NewRecovery(
 		rootScope,
@@ -497,6 +536,7 @@ func main() {
 		preemptor,
 		drainer,
 		batchScorer,
+		slaChecker,
 	)
 	// Set nomination for leader check middleware
 	leaderCheckMiddleware.SetNomination(server)
@@ -14,6 +14,10 @@
 
 package apiserver
 
+import (
+	"github.com/uber/peloton/pkg/apiserver/forward"
+)
+
 // Config contains APIServer specific configuration
 type Config struct {
 	// HTTP port which API Server is listening on
@@ -21,4 +25,12 @@ type Config struct {
 
 	// gRPC port which API Server is listening on
 	GRPCPort int `yaml:"grpc_port"`
+
+	// Cache is the config of the cache of the responses of the read
+	// only procedures of Job Manager
+	Cache forward.CacheConfig `yaml:"cache"`
+
+	// Failover is the config of the retries of the idempotent
+	// procedures during the leader failover of a component
+	Failover forward.FailoverConfig `yaml:"failover"`
 }
@@ -0,0 +1,312 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/procedure"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+)
+
+const (
+	// _cacheableLabel is the label of the procedures
+	// whose responses are cached
+	_cacheableLabel = "cacheable"
+
+	// _defaultCacheTTL is the default time a response is cached for
+	_defaultCacheTTL = 2 * time.Second
+
+	// _defaultCacheMaxEntries is the default
+	// maximum number of cached responses
+	_defaultCacheMaxEntries = 10000
+
+	// _maxTrackedInvalidations is the maximum number of jobs whose last
+	// invalidation is tracked, beyond which all invalidations are
+	// treated as global.
+	_maxTrackedInvalidations = 100000
+)
+
+// default procedures whose responses are cached
+var _defaultCachedAPIs = []string{
+	"peloton.api.v0.job.JobManager:Get",
+	"peloton.api.v0.job.svc.JobService:GetJob",
+	"peloton.api.v1alpha.job.stateless.svc.JobService:GetJob",
+	"peloton.api.v1alpha.job.stateless.svc.JobService:QueryPods",
+	"peloton.api.v1alpha.job.stateless.svc.JobService:QueryJobs",
+	"peloton.api.v1alpha.pod.svc.PodService:GetPod",
+}
+
+// CacheConfig is the config of the cache of the responses
+// of read only procedures.
+type CacheConfig struct {
+	// Enabled enables the response cache
+	Enabled bool `yaml:"enabled"`
+
+	// TTL is the time a response is cached for. Responses are also
+	// invalidated earlier by the changes to the jobs and pods they
+	// refer to, as reported by the watch API.
+	TTL time.Duration `yaml:"ttl"`
+
+	// MaxEntries is the maximum number of cached responses
+	MaxEntries int `yaml:"max_entries"`
+
+	// Procedures are the unary procedures whose responses are cached,
+	// e.g. peloton.api.v1alpha.pod.svc.PodService:GetPod. If not set,
+	// the procedures getting a job or a pod, and querying the pods of
+	// a job are cached.
+	Procedures []string `yaml:"procedures"`
+}
+
+// cachedResponse is a response of a procedure in the cache
+type cachedResponse struct {
+	headers transport.Headers
+	body    []byte
+	// jobIDs are the jobs the response refers to
+	jobIDs   []string
+	expireAt time.Time
+}
+
+// ResponseCache caches the responses of read only procedures for a short
+// time. Cached responses are invalidated by the changes to the jobs they
+// refer to. The cache is only used while it is active, i.e. while the
+// changes to the jobs are being watched.
+type ResponseCache struct {
+	sync.Mutex
+
+	ttl          time.Duration
+	maxEntries   int
+	labelManager *procedure.LabelManager
+
+	active    bool
+	responses map[string]*cachedResponse
+	// keys of the responses which refer to each job
+	jobKeys map[string]map[string]struct{}
+	// keys of the responses which refer to no job in particular,
+	// e.g. queries, they are invalidated by the change to any job
+	unscopedKeys map[string]struct{}
+
+	// sequence number of the last invalidation
+	seq uint64
+	// sequence number of the last invalidation of all responses
+	globalSeq uint64
+	// sequence number of the last invalidation of each job
+	jobSeqs map[string]uint64
+
+	metrics *cacheMetrics
+	now     func() time.Time
+}
+
+// NewResponseCache returns a new response cache. The cache is
+// inactive until it is activated by the cache invalidator.
+func NewResponseCache(cfg CacheConfig, scope tally.Scope) *ResponseCache {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = _defaultCacheTTL
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = _defaultCacheMaxEntries
+	}
+	procedures := cfg.Procedures
+	if len(procedures) == 0 {
+		procedures = _defaultCachedAPIs
+	}
+
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		labelManager: procedure.NewLabelManager(&procedure.LabelManagerConfig{
+			Entries: []*procedure.LabelManagerConfigEntry{
+				{Procedures: procedures, Labels: []string{_cacheableLabel}},
+			},
+		}),
+		responses:    make(map[string]*cachedResponse),
+		jobKeys:      make(map[string]map[string]struct{}),
+		unscopedKeys: make(map[string]struct{}),
+		jobSeqs:      make(map[string]uint64),
+		metrics:      newCacheMetrics(scope),
+		now:          time.Now,
+	}
+}
+
+// Cacheable returns true if the responses of the procedure are cached
+func (c *ResponseCache) Cacheable(procedure string) bool {
+	return c.labelManager.HasLabel(procedure, _cacheableLabel)
+}
+
+// SetActive activates or deactivates the cache. All cached responses
+// are dropped when the cache is deactivated, since changes to the jobs
+// are not watched anymore.
+func (c *ResponseCache) SetActive(active bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.active = active
+	if !active {
+		c.invalidateAllLocked()
+	}
+}
+
+// Get returns the cached response for a request key. It also returns
+// the sequence number of the last invalidation, which must be passed
+// to Put, so that responses computed before an invalidation are
+// not cached.
+func (c *ResponseCache) Get(key string) (*cachedResponse, uint64, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.active {
+		return nil, c.seq, false
+	}
+
+	resp, ok := c.responses[key]
+	if !ok {
+		c.metrics.miss.Inc(1)
+		return nil, c.seq, false
+	}
+	if !c.now().Before(resp.expireAt) {
+		c.removeLocked(key)
+		c.metrics.miss.Inc(1)
+		return nil, c.seq, false
+	}
+
+	c.metrics.hit.Inc(1)
+	return resp, c.seq, true
+}
+
+// Put caches the response for a request key, unless the jobs the
+// response refers to have been invalidated since seq.
+func (c *ResponseCache) Put(
+	key string,
+	seq uint64,
+	jobIDs []string,
+	headers transport.Headers,
+	body []byte,
+) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.active || c.globalSeq > seq {
+		return
+	}
+	if len(jobIDs) == 0 && c.seq > seq {
+		return
+	}
+	for _, jobID := range jobIDs {
+		if c.jobSeqs[jobID] > seq {
+			return
+		}
+	}
+
+	if _, ok := c.responses[key]; !ok && len(c.responses) >= c.maxEntries {
+		c.removeExpiredLocked()
+		if len(c.responses) >= c.maxEntries {
+			c.metrics.full.Inc(1)
+			return
+		}
+	}
+
+	c.removeLocked(key)
+	c.responses[key] = &cachedResponse{
+		headers:  headers,
+		body:     body,
+		jobIDs:   jobIDs,
+		expireAt: c.now().Add(c.ttl),
+	}
+	if len(jobIDs) == 0 {
+		c.unscopedKeys[key] = struct{}{}
+	}
+	for _, jobID := range jobIDs {
+		keys, ok := c.jobKeys[jobID]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.jobKeys[jobID] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	c.metrics.put.Inc(1)
+}
+
+// InvalidateJob drops the cached responses which refer to the job,
+// and the ones which refer to no job in particular.
+func (c *ResponseCache) InvalidateJob(jobID string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.seq++
+	if len(c.jobSeqs) >= _maxTrackedInvalidations {
+		c.invalidateAllLocked()
+		return
+	}
+	c.jobSeqs[jobID] = c.seq
+
+	for key := range c.jobKeys[jobID] {
+		c.removeLocked(key)
+	}
+	for key := range c.unscopedKeys {
+		c.removeLocked(key)
+	}
+	c.metrics.invalidateJob.Inc(1)
+}
+
+// InvalidateAll drops all cached responses
+func (c *ResponseCache) InvalidateAll() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.invalidateAllLocked()
+}
+
+func (c *ResponseCache) invalidateAllLocked() {
+	c.seq++
+	c.globalSeq = c.seq
+	c.responses = make(map[string]*cachedResponse)
+	c.jobKeys = make(map[string]map[string]struct{})
+	c.unscopedKeys = make(map[string]struct{})
+	c.jobSeqs = make(map[string]uint64)
+	c.metrics.invalidateAll.Inc(1)
+}
+
+// removeExpiredLocked drops the expired responses
+func (c *ResponseCache) removeExpiredLocked() {
+	now := c.now()
+	for key, resp := range c.responses {
+		if !now.Before(resp.expireAt) {
+			c.removeLocked(key)
+		}
+	}
+}
+
+// removeLocked drops the response of a request key
+func (c *ResponseCache) removeLocked(key string) {
+	resp, ok := c.responses[key]
+	if !ok {
+		return
+	}
+
+	delete(c.responses, key)
+	delete(c.unscopedKeys, key)
+	for _, jobID := range resp.jobIDs {
+		keys := c.jobKeys[jobID]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.jobKeys, jobID)
+		}
+	}
+}
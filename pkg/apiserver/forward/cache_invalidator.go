@@ -0,0 +1,202 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// _defaultRewatchInterval is the interval before the watches
+// are created again after they failed
+const _defaultRewatchInterval = 5 * time.Second
+
+// CacheInvalidator watches the changes to the stateless and batch jobs
+// and pods with the watch API of Job Manager, and invalidates the cached
+// responses which refer to the changed jobs. The cache is only active
+// while all of them are being watched.
+type CacheInvalidator struct {
+	cache  *ResponseCache
+	client watchsvc.WatchServiceYARPCClient
+
+	rewatchInterval time.Duration
+	lifeCycle       lifecycle.LifeCycle
+}
+
+// NewCacheInvalidator returns a new cache invalidator, which
+// watches the changes to the jobs and pods with the client
+func NewCacheInvalidator(
+	cache *ResponseCache,
+	client watchsvc.WatchServiceYARPCClient,
+) *CacheInvalidator {
+	return &CacheInvalidator{
+		cache:           cache,
+		client:          client,
+		rewatchInterval: _defaultRewatchInterval,
+		lifeCycle:       lifecycle.NewLifeCycle(),
+	}
+}
+
+// Start starts watching the changes to the stateless and batch jobs and pods
+func (i *CacheInvalidator) Start() {
+	if !i.lifeCycle.Start() {
+		return
+	}
+	go i.run()
+	log.Info("Response cache invalidator started")
+}
+
+// Stop stops watching the changes to the stateless and batch jobs and pods,
+// and deactivates the cache
+func (i *CacheInvalidator) Stop() {
+	if !i.lifeCycle.Stop() {
+		return
+	}
+	i.lifeCycle.Wait()
+	i.cache.SetActive(false)
+	log.Info("Response cache invalidator stopped")
+}
+
+// run watches the changes until the invalidator is stopped,
+// and watches them again whenever the watches fail
+func (i *CacheInvalidator) run() {
+	defer i.lifeCycle.StopComplete()
+
+	for {
+		err := i.watch()
+		i.cache.SetActive(false)
+
+		select {
+		case <-i.lifeCycle.StopCh():
+			return
+		default:
+		}
+
+		log.WithError(err).
+			Warn("Watch for response cache invalidation failed, " +
+				"response cache is inactive")
+
+		select {
+		case <-i.lifeCycle.StopCh():
+			return
+		case <-time.After(i.rewatchInterval):
+		}
+	}
+}
+
+// watch creates the watches of the stateless and batch jobs and pods,
+// activates the cache, and invalidates the cached responses until one
+// of the watches fails
+func (i *CacheInvalidator) watch() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-i.lifeCycle.StopCh():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	jobStream, err := i.client.Watch(ctx, &watchsvc.WatchRequest{
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+	})
+	if err != nil {
+		return err
+	}
+	batchJobStream, err := i.client.Watch(ctx, &watchsvc.WatchRequest{
+		BatchJobFilter: &watch.BatchJobFilter{},
+	})
+	if err != nil {
+		return err
+	}
+	podStream, err := i.client.Watch(ctx, &watchsvc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the first response of a watch only carries the watch id
+	if _, err := jobStream.Recv(); err != nil {
+		return err
+	}
+	if _, err := batchJobStream.Recv(); err != nil {
+		return err
+	}
+	if _, err := podStream.Recv(); err != nil {
+		return err
+	}
+
+	// the cache was cleared when it was deactivated,
+	// so it holds no response from before the watches
+	i.cache.SetActive(true)
+	log.Info("Watching jobs and pods, response cache is active")
+
+	errs := make(chan error, 3)
+	go func() { errs <- i.receive(jobStream) }()
+	go func() { errs <- i.receive(batchJobStream) }()
+	go func() { errs <- i.receive(podStream) }()
+	return <-errs
+}
+
+// receive invalidates the cached responses of the jobs
+// which are changed, until the watch fails
+func (i *CacheInvalidator) receive(
+	stream watchsvc.WatchServiceServiceWatchYARPCClient,
+) error {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		for _, job := range resp.GetStatelessJobs() {
+			i.cache.InvalidateJob(job.GetJobId().GetValue())
+		}
+		for _, jobID := range resp.GetStatelessJobsNotFound() {
+			i.cache.InvalidateJob(jobID.GetValue())
+		}
+		for _, job := range resp.GetBatchJobs() {
+			i.cache.InvalidateJob(job.GetJobId().GetValue())
+		}
+		for _, pod := range resp.GetPods() {
+			i.invalidatePod(pod.GetPodName().GetValue())
+		}
+		for _, podName := range resp.GetPodsNotFound() {
+			i.invalidatePod(podName.GetValue())
+		}
+	}
+}
+
+// invalidatePod invalidates the cached responses of the job of a pod
+func (i *CacheInvalidator) invalidatePod(podName string) {
+	jobID, _, err := util.ParseTaskID(podName)
+	if err != nil {
+		// the job of the pod is unknown
+		i.cache.InvalidateAll()
+		return
+	}
+	i.cache.InvalidateJob(jobID)
+}
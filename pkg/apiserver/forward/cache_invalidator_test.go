@@ -0,0 +1,228 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+	watchmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+)
+
+// CacheInvalidatorTestSuite is test suite for the cache invalidator.
+type CacheInvalidatorTestSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	watchClient    *watchmocks.MockWatchServiceYARPCClient
+	jobStream      *watchmocks.MockWatchServiceServiceWatchYARPCClient
+	batchJobStream *watchmocks.MockWatchServiceServiceWatchYARPCClient
+	podStream      *watchmocks.MockWatchServiceServiceWatchYARPCClient
+
+	cache       *ResponseCache
+	invalidator *CacheInvalidator
+}
+
+// SetupTest is setup function for each test in this suite.
+func (suite *CacheInvalidatorTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.watchClient = watchmocks.NewMockWatchServiceYARPCClient(suite.ctrl)
+	suite.jobStream = watchmocks.NewMockWatchServiceServiceWatchYARPCClient(suite.ctrl)
+	suite.batchJobStream = watchmocks.NewMockWatchServiceServiceWatchYARPCClient(suite.ctrl)
+	suite.podStream = watchmocks.NewMockWatchServiceServiceWatchYARPCClient(suite.ctrl)
+
+	suite.cache = NewResponseCache(CacheConfig{}, tally.NoopScope)
+	suite.invalidator = NewCacheInvalidator(suite.cache, suite.watchClient)
+	suite.invalidator.rewatchInterval = time.Hour
+}
+
+// TearDownTest is teardown function for each test in this suite.
+func (suite *CacheInvalidatorTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// TestCacheInvalidatorTestSuite runs CacheInvalidatorTestSuite.
+func TestCacheInvalidatorTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheInvalidatorTestSuite))
+}
+
+// expectWatch sets up the watches of the stateless and batch jobs and pods
+func (suite *CacheInvalidatorTestSuite) expectWatch() {
+	suite.watchClient.EXPECT().
+		Watch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *watchsvc.WatchRequest,
+			opts ...yarpc.CallOption,
+		) (watchsvc.WatchServiceServiceWatchYARPCClient, error) {
+			if req.GetStatelessJobFilter() != nil {
+				return suite.jobStream, nil
+			}
+			if req.GetBatchJobFilter() != nil {
+				return suite.batchJobStream, nil
+			}
+			suite.NotNil(req.GetPodFilter())
+			return suite.podStream, nil
+		}).
+		Times(3)
+}
+
+// TestInvalidate tests invalidating the cached responses
+// of the jobs which are changed.
+func (suite *CacheInvalidatorTestSuite) TestInvalidate() {
+	invalidated := make(chan struct{})
+	batchInvalidated := make(chan struct{})
+	podWatched := make(chan struct{})
+	done := make(chan struct{})
+
+	suite.expectWatch()
+	gomock.InOrder(
+		suite.jobStream.EXPECT().
+			Recv().
+			Return(&watchsvc.WatchResponse{WatchId: "job-watch"}, nil),
+		suite.jobStream.EXPECT().
+			Recv().
+			DoAndReturn(func() (*watchsvc.WatchResponse, error) {
+				// the cache is active once the watches are created
+				_, seq, ok := suite.cache.Get("job")
+				suite.False(ok)
+				suite.cache.Put("job", seq, []string{_testJobID}, nil, nil)
+				_, _, ok = suite.cache.Get("job")
+				suite.True(ok)
+
+				return &watchsvc.WatchResponse{
+					WatchId: "job-watch",
+					StatelessJobs: []*stateless.JobSummary{
+						{JobId: &peloton.JobID{Value: _testJobID}},
+					},
+				}, nil
+			}),
+		suite.jobStream.EXPECT().
+			Recv().
+			DoAndReturn(func() (*watchsvc.WatchResponse, error) {
+				close(invalidated)
+				<-done
+				return nil, errors.New("watch stopped")
+			}),
+	)
+	gomock.InOrder(
+		suite.batchJobStream.EXPECT().
+			Recv().
+			Return(&watchsvc.WatchResponse{WatchId: "batch-job-watch"}, nil),
+		suite.batchJobStream.EXPECT().
+			Recv().
+			DoAndReturn(func() (*watchsvc.WatchResponse, error) {
+				<-invalidated
+				_, seq, _ := suite.cache.Get("batch-job")
+				suite.cache.Put("batch-job", seq, []string{"batch-job"}, nil, nil)
+
+				return &watchsvc.WatchResponse{
+					WatchId: "batch-job-watch",
+					BatchJobs: []*stateless.JobSummary{
+						{JobId: &peloton.JobID{Value: "batch-job"}},
+					},
+				}, nil
+			}),
+		suite.batchJobStream.EXPECT().
+			Recv().
+			DoAndReturn(func() (*watchsvc.WatchResponse, error) {
+				close(batchInvalidated)
+				<-done
+				return nil, errors.New("watch stopped")
+			}),
+	)
+	gomock.InOrder(
+		suite.podStream.EXPECT().
+			Recv().
+			Return(&watchsvc.WatchResponse{WatchId: "pod-watch"}, nil),
+		suite.podStream.EXPECT().
+			Recv().
+			DoAndReturn(func() (*watchsvc.WatchResponse, error) {
+				close(podWatched)
+				<-done
+				return nil, errors.New("watch stopped")
+			}),
+	)
+
+	suite.invalidator.Start()
+	<-invalidated
+	<-batchInvalidated
+	<-podWatched
+
+	// the responses of the changed stateless and batch jobs are invalidated
+	_, _, ok := suite.cache.Get("job")
+	suite.False(ok)
+	_, _, ok = suite.cache.Get("batch-job")
+	suite.False(ok)
+
+	close(done)
+	suite.invalidator.Stop()
+	suite.False(suite.cache.active)
+}
+
+// TestInvalidatePod tests invalidating the cached responses
+// of the jobs of the pods which are changed.
+func (suite *CacheInvalidatorTestSuite) TestInvalidatePod() {
+	suite.cache.SetActive(true)
+	_, seq, _ := suite.cache.Get("job")
+	suite.cache.Put("job", seq, []string{_testJobID}, nil, nil)
+	_, seq, _ = suite.cache.Get("other")
+	suite.cache.Put("other", seq, []string{"other-job"}, nil, nil)
+
+	suite.invalidator.invalidatePod(_testJobID + "-0")
+	_, _, ok := suite.cache.Get("job")
+	suite.False(ok)
+	_, _, ok = suite.cache.Get("other")
+	suite.True(ok)
+
+	// all responses are invalidated if the job of the pod is unknown
+	suite.invalidator.invalidatePod("invalid")
+	_, _, ok = suite.cache.Get("other")
+	suite.False(ok)
+}
+
+// TestWatchFailure tests that the cache stays
+// inactive when the watches cannot be created.
+func (suite *CacheInvalidatorTestSuite) TestWatchFailure() {
+	called := make(chan struct{})
+	suite.watchClient.EXPECT().
+		Watch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *watchsvc.WatchRequest,
+			opts ...yarpc.CallOption,
+		) (watchsvc.WatchServiceServiceWatchYARPCClient, error) {
+			close(called)
+			return nil, errors.New("jobmgr unavailable")
+		})
+
+	suite.invalidator.Start()
+	<-called
+	suite.invalidator.Stop()
+
+	_, _, ok := suite.cache.Get("job")
+	suite.False(ok)
+	suite.False(suite.cache.active)
+}
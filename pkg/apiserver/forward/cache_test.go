@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+)
+
+const (
+	_testGetJobProcedure = "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob"
+	_testJobID           = "d6b3f4c0-5b8a-4f6e-9a3c-1f2e3d4c5b6a"
+)
+
+// ResponseCacheTestSuite is test suite for the response cache.
+type ResponseCacheTestSuite struct {
+	suite.Suite
+
+	now   time.Time
+	cache *ResponseCache
+}
+
+// SetupTest is setup function for each test in this suite.
+func (suite *ResponseCacheTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.cache = NewResponseCache(CacheConfig{
+		TTL:        time.Second,
+		MaxEntries: 2,
+	}, tally.NoopScope)
+	suite.cache.now = func() time.Time { return suite.now }
+	suite.cache.SetActive(true)
+}
+
+// TestResponseCacheTestSuite runs ResponseCacheTestSuite.
+func TestResponseCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ResponseCacheTestSuite))
+}
+
+// put caches a response for key, which refers to the jobs
+func (suite *ResponseCacheTestSuite) put(key string, jobIDs ...string) {
+	_, seq, _ := suite.cache.Get(key)
+	suite.cache.Put(key, seq, jobIDs, transport.NewHeaders(), []byte(key))
+}
+
+// TestCacheable tests which procedures are cacheable by default.
+func (suite *ResponseCacheTestSuite) TestCacheable() {
+	suite.True(suite.cache.Cacheable(_testGetJobProcedure))
+	suite.True(suite.cache.Cacheable(
+		"peloton.api.v1alpha.pod.svc.PodService::GetPod"))
+	suite.False(suite.cache.Cacheable(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob"))
+}
+
+// TestGetPut tests caching a response until it expires.
+func (suite *ResponseCacheTestSuite) TestGetPut() {
+	suite.put("key1", _testJobID)
+
+	resp, _, ok := suite.cache.Get("key1")
+	suite.True(ok)
+	suite.Equal([]byte("key1"), resp.body)
+
+	suite.now = suite.now.Add(time.Second)
+	_, _, ok = suite.cache.Get("key1")
+	suite.False(ok)
+}
+
+// TestInactive tests that the cache is not used while it is inactive.
+func (suite *ResponseCacheTestSuite) TestInactive() {
+	suite.put("key1", _testJobID)
+	suite.cache.SetActive(false)
+
+	_, _, ok := suite.cache.Get("key1")
+	suite.False(ok)
+
+	suite.put("key1", _testJobID)
+	suite.cache.SetActive(true)
+	_, _, ok = suite.cache.Get("key1")
+	suite.False(ok)
+}
+
+// TestInvalidateJob tests invalidating the responses of a job.
+func (suite *ResponseCacheTestSuite) TestInvalidateJob() {
+	suite.put("job", _testJobID)
+	suite.put("query")
+
+	suite.cache.InvalidateJob("other-job")
+	_, _, ok := suite.cache.Get("job")
+	suite.True(ok)
+	// responses which refer to no job are invalidated by any change
+	_, _, ok = suite.cache.Get("query")
+	suite.False(ok)
+
+	suite.cache.InvalidateJob(_testJobID)
+	_, _, ok = suite.cache.Get("job")
+	suite.False(ok)
+}
+
+// TestPutAfterInvalidation tests that a response computed
+// before its job is invalidated is not cached.
+func (suite *ResponseCacheTestSuite) TestPutAfterInvalidation() {
+	_, seq, ok := suite.cache.Get("job")
+	suite.False(ok)
+	_, querySeq, ok := suite.cache.Get("query")
+	suite.False(ok)
+
+	suite.cache.InvalidateJob(_testJobID)
+
+	suite.cache.Put("job", seq, []string{_testJobID}, nil, nil)
+	_, _, ok = suite.cache.Get("job")
+	suite.False(ok)
+
+	suite.cache.Put("query", querySeq, nil, nil, nil)
+	_, _, ok = suite.cache.Get("query")
+	suite.False(ok)
+
+	// responses of other jobs are still cached
+	suite.cache.Put("other", seq, []string{"other-job"}, nil, nil)
+	_, _, ok = suite.cache.Get("other")
+	suite.True(ok)
+}
+
+// TestMaxEntries tests that no response is cached beyond the
+// maximum number of entries, until responses expire.
+func (suite *ResponseCacheTestSuite) TestMaxEntries() {
+	suite.put("key1", _testJobID)
+	suite.put("key2", _testJobID)
+	suite.put("key3", _testJobID)
+
+	_, _, ok := suite.cache.Get("key3")
+	suite.False(ok)
+
+	suite.now = suite.now.Add(time.Second)
+	suite.put("key3", _testJobID)
+	_, _, ok = suite.cache.Get("key3")
+	suite.True(ok)
+	suite.Len(suite.cache.responses, 1)
+}
+
+// TestInvalidateAll tests invalidating all responses.
+func (suite *ResponseCacheTestSuite) TestInvalidateAll() {
+	suite.put("key1", _testJobID)
+	suite.put("key2")
+
+	suite.cache.InvalidateAll()
+	suite.Empty(suite.cache.responses)
+	suite.Empty(suite.cache.jobKeys)
+	suite.Empty(suite.cache.unscopedKeys)
+}
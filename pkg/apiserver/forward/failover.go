@@ -0,0 +1,120 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/common/procedure"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _idempotentLabel is the label of the idempotent procedures,
+	// which are retried during leader failover
+	_idempotentLabel = "idempotent"
+
+	// _defaultFailoverMaxAttempts is the default maximum number
+	// of attempts of a call during leader failover
+	_defaultFailoverMaxAttempts = 30
+
+	// _defaultFailoverRetryInterval is the default interval
+	// between the attempts of a call during leader failover
+	_defaultFailoverRetryInterval = time.Second
+)
+
+// default idempotent procedures, aurora bridge methods start
+// with a lower case letter
+var _defaultIdempotentAPIs = []string{
+	"*:Get*",
+	"*:get*",
+	"*:Query*",
+	"*:List*",
+	"*:Browse*",
+	"*:Lookup*",
+}
+
+// FailoverConfig is the config of the retries of idempotent
+// calls while the leader of a component fails over.
+type FailoverConfig struct {
+	// Enabled enables the retries of idempotent calls
+	Enabled bool `yaml:"enabled"`
+
+	// IdempotentAPIs are the procedures which are retried, e.g.
+	// *:Get*. If not set, the Get, Query, List, Browse and Lookup
+	// methods are retried.
+	IdempotentAPIs []string `yaml:"idempotent_apis"`
+
+	// MaxAttempts is the maximum number of attempts of a call.
+	// Calls are also not retried beyond their deadline.
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// RetryInterval is the interval between the attempts of a call,
+	// during which the new leader can be discovered
+	RetryInterval time.Duration `yaml:"retry_interval"`
+}
+
+// FailoverPolicy decides which calls are retried during leader failover
+type FailoverPolicy struct {
+	maxAttempts   int
+	retryInterval time.Duration
+	labelManager  *procedure.LabelManager
+	metrics       *failoverMetrics
+}
+
+// NewFailoverPolicy returns a new failover policy, or nil
+// if the retries are not enabled
+func NewFailoverPolicy(cfg FailoverConfig, scope tally.Scope) *FailoverPolicy {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = _defaultFailoverMaxAttempts
+	}
+	retryInterval := cfg.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = _defaultFailoverRetryInterval
+	}
+	idempotentAPIs := cfg.IdempotentAPIs
+	if len(idempotentAPIs) == 0 {
+		idempotentAPIs = _defaultIdempotentAPIs
+	}
+
+	return &FailoverPolicy{
+		maxAttempts:   maxAttempts,
+		retryInterval: retryInterval,
+		labelManager: procedure.NewLabelManager(&procedure.LabelManagerConfig{
+			Entries: []*procedure.LabelManagerConfigEntry{
+				{Procedures: idempotentAPIs, Labels: []string{_idempotentLabel}},
+			},
+		}),
+		metrics: newFailoverMetrics(scope),
+	}
+}
+
+// Retryable returns true if the calls to the procedure are retried
+func (p *FailoverPolicy) Retryable(procedure string) bool {
+	return p != nil && p.labelManager.HasLabel(procedure, _idempotentLabel)
+}
+
+// isFailoverError returns true if the call failed because the leader
+// is unreachable, or the component called is not the leader anymore
+func isFailoverError(err error) bool {
+	return yarpcerrors.IsUnavailable(err)
+}
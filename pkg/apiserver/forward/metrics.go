@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forward
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// cacheMetrics track the response cache
+type cacheMetrics struct {
+	hit           tally.Counter
+	miss          tally.Counter
+	put           tally.Counter
+	full          tally.Counter
+	invalidateJob tally.Counter
+	invalidateAll tally.Counter
+}
+
+func newCacheMetrics(scope tally.Scope) *cacheMetrics {
+	cacheScope := scope.SubScope("response_cache")
+	return &cacheMetrics{
+		hit:           cacheScope.Counter("hit"),
+		miss:          cacheScope.Counter("miss"),
+		put:           cacheScope.Counter("put"),
+		full:          cacheScope.Counter("full"),
+		invalidateJob: cacheScope.Counter("invalidate_job"),
+		invalidateAll: cacheScope.Counter("invalidate_all"),
+	}
+}
+
+// failoverMetrics track the retries of the calls during leader failover
+type failoverMetrics struct {
+	retry     tally.Counter
+	recovered tally.Counter
+	exhausted tally.Counter
+}
+
+func newFailoverMetrics(scope tally.Scope) *failoverMetrics {
+	failoverScope := scope.SubScope("failover")
+	return &failoverMetrics{
+		retry:     failoverScope.Counter("retry"),
+		recovered: failoverScope.Counter("recovered"),
+		exhausted: failoverScope.Counter("exhausted"),
+	}
+}
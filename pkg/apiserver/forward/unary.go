@@ -15,8 +15,18 @@
 package forward
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"time"
 
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/audit"
+	"github.com/uber/peloton/pkg/common/util"
+
+	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc/api/transport"
 )
 
@@ -29,36 +39,165 @@ type unaryForward struct {
 
 	// overrideService is the service name to override the original service name.
 	overrideService string
+
+	// cache caches the responses of read only procedures, nil if
+	// responses are not cached.
+	cache *ResponseCache
+
+	// failover decides which calls are retried during leader failover,
+	// nil if calls are not retried.
+	failover *FailoverPolicy
+}
+
+// UnaryOption is an option of the unary forwarding handler.
+type UnaryOption func(*unaryForward)
+
+// WithResponseCache caches the responses of the procedures
+// which are cacheable in the given cache.
+func WithResponseCache(cache *ResponseCache) UnaryOption {
+	return func(uf *unaryForward) {
+		uf.cache = cache
+	}
 }
 
+// WithFailoverPolicy retries the calls to idempotent procedures
+// during leader failover, following the given policy.
+func WithFailoverPolicy(policy *FailoverPolicy) UnaryOption {
+	return func(uf *unaryForward) {
+		uf.failover = policy
+	}
+}
+
+// NewUnaryForward returns a new unary forwarding handler.
 func NewUnaryForward(
 	outbound transport.UnaryOutbound,
 	overrideService string,
+	opts ...UnaryOption,
 ) *unaryForward {
-	return &unaryForward{
+	uf := &unaryForward{
 		outbound:        outbound,
 		overrideService: overrideService,
 	}
+	for _, opt := range opts {
+		opt(uf)
+	}
+	return uf
 }
 
 // Handle implements Handle function of UnaryHandler to forward request to given
-// outbound and copy the response.
+// outbound and copy the response. The response is served from the cache
+// if the procedure is cacheable, and the call is retried during leader
+// failover if the procedure is idempotent.
 func (uf *unaryForward) Handle(
 	ctx context.Context,
 	req *transport.Request,
 	w transport.ResponseWriter,
 ) error {
-	resp, err := uf.outbound.Call(ctx, uf.preprocess(req))
+	cacheable := uf.cache != nil && uf.cache.Cacheable(req.Procedure)
+	var caller string
+	if cacheable {
+		caller, cacheable = callerName(ctx)
+	}
+	retryable := uf.failover.Retryable(req.Procedure)
+	if !cacheable && !retryable {
+		resp, err := uf.outbound.Call(ctx, uf.preprocess(req))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return writeResponse(w, resp)
+	}
+
+	// the request body is read, so that it can be
+	// used as the cache key and sent again on retries
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+
+	var key string
+	var seq uint64
+	if cacheable {
+		key = cacheKey(req, caller, body)
+		cached, lastSeq, ok := uf.cache.Get(key)
+		if ok {
+			w.AddHeaders(cached.headers)
+			_, err := w.Write(cached.body)
+			return err
+		}
+		seq = lastSeq
+	}
+
+	resp, err := uf.call(ctx, req, body, retryable)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	// copy the response
+
+	if !cacheable || resp.ApplicationError {
+		return writeResponse(w, resp)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	uf.cache.Put(
+		key, seq, referredJobIDs(req, body), resp.Headers, respBody)
+
+	w.AddHeaders(resp.Headers)
+	_, err = w.Write(respBody)
+	return err
+}
+
+// call forwards the request to the outbound, and retries the call
+// while the leader fails over if the procedure is retryable
+func (uf *unaryForward) call(
+	ctx context.Context,
+	req *transport.Request,
+	body []byte,
+	retryable bool,
+) (*transport.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req.Body = bytes.NewReader(body)
+		resp, err := uf.outbound.Call(ctx, uf.preprocess(req))
+		if err == nil {
+			if attempt > 1 {
+				uf.failover.metrics.recovered.Inc(1)
+			}
+			return resp, nil
+		}
+
+		if !retryable || !isFailoverError(err) {
+			return nil, err
+		}
+		if attempt >= uf.failover.maxAttempts {
+			uf.failover.metrics.exhausted.Inc(1)
+			return nil, err
+		}
+
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			WithField("attempt", attempt).
+			Debug("leader unavailable, retrying call")
+
+		select {
+		case <-ctx.Done():
+			uf.failover.metrics.exhausted.Inc(1)
+			return nil, err
+		case <-time.After(uf.failover.retryInterval):
+		}
+		uf.failover.metrics.retry.Inc(1)
+	}
+}
+
+// writeResponse copies the response to the response writer
+func writeResponse(w transport.ResponseWriter, resp *transport.Response) error {
 	if resp.ApplicationError {
 		w.SetApplicationError()
 	}
 	w.AddHeaders(resp.Headers)
-	_, err = copy(w, resp.Body)
+	_, err := copy(w, resp.Body)
 	return err
 }
 
@@ -69,3 +208,68 @@ func (uf *unaryForward) preprocess(req *transport.Request) *transport.Request {
 	}
 	return req
 }
+
+// callerName returns the name of the authenticated user in ctx, which
+// is empty if auth is disabled. It returns false if the user has no
+// name, in which case the responses to the user cannot be cached.
+func callerName(ctx context.Context) (string, bool) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return "", true
+	}
+	namedUser, ok := user.(auth.NamedUser)
+	if !ok {
+		return "", false
+	}
+	return namedUser.Name(), true
+}
+
+// cacheKey returns the cache key of a request from a caller. The caller
+// is part of the key, so that a response is only served to the caller
+// it was returned to, as the permissions of the callers may differ.
+func cacheKey(req *transport.Request, caller string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Procedure))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Encoding))
+	h.Write([]byte{0})
+	h.Write([]byte(caller))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// referredJobIDs returns the jobs a request refers to, including the
+// jobs of the pods it refers to. It returns no job if the jobs cannot
+// be extracted from the request, so that the response is invalidated
+// by the change to any job.
+func referredJobIDs(req *transport.Request, body []byte) []string {
+	resources, err := audit.ExtractResources(
+		req.Procedure, string(req.Encoding), body)
+	if err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Debug("failed to extract jobs from request")
+		return nil
+	}
+
+	jobIDs := resources.JobIDs
+	for _, podName := range resources.PodNames {
+		jobID, _, err := util.ParseTaskID(podName)
+		if err != nil {
+			continue
+		}
+		jobIDs = appendJobID(jobIDs, jobID)
+	}
+	return jobIDs
+}
+
+// appendJobID appends a job which is not in jobIDs yet
+func appendJobID(jobIDs []string, jobID string) []string {
+	for _, id := range jobIDs {
+		if id == jobID {
+			return jobIDs
+		}
+	}
+	return append(jobIDs, jobID)
+}
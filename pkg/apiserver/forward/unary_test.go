@@ -15,14 +15,23 @@
 package forward
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
 
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -115,3 +124,256 @@ func (suite *UnaryHandlerTestSuite) TestHandle() {
 		cancel()
 	}
 }
+
+// newGetJobRequest returns the request of a GetJob call.
+func (suite *UnaryHandlerTestSuite) newGetJobRequest() *transport.Request {
+	body, err := proto.Marshal(&statelesssvc.GetJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: _testJobID},
+	})
+	suite.NoError(err)
+
+	return &transport.Request{
+		Procedure: _testGetJobProcedure,
+		Encoding:  "proto",
+		Body:      bytes.NewReader(body),
+	}
+}
+
+// newResponse returns an outbound call response with the body.
+func newResponse(body string) *transport.Response {
+	return &transport.Response{
+		Body: ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// TestHandleCached tests serving responses from the cache.
+func (suite *UnaryHandlerTestSuite) TestHandleCached() {
+	cache := NewResponseCache(CacheConfig{}, tally.NoopScope)
+	cache.SetActive(true)
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithResponseCache(cache),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Return(newResponse(_bodyStr), nil)
+
+	for i := 0; i < 2; i++ {
+		w := &transporttest.FakeResponseWriter{}
+		suite.NoError(handler.Handle(
+			context.Background(), suite.newGetJobRequest(), w))
+		suite.Equal(_bodyStr, w.Body.String())
+	}
+
+	// the response is invalidated by the change to the job
+	cache.InvalidateJob(_testJobID)
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Return(newResponse(_bodyStr), nil)
+
+	w := &transporttest.FakeResponseWriter{}
+	suite.NoError(handler.Handle(
+		context.Background(), suite.newGetJobRequest(), w))
+	suite.Equal(_bodyStr, w.Body.String())
+}
+
+// testUser is an authenticated user with a name.
+type testUser struct {
+	name string
+}
+
+func (u *testUser) IsPermitted(procedure string) bool { return true }
+
+func (u *testUser) Name() string { return u.name }
+
+// TestHandleCachedPerCaller tests that a cached response is only
+// served to the caller it was returned to.
+func (suite *UnaryHandlerTestSuite) TestHandleCachedPerCaller() {
+	cache := NewResponseCache(CacheConfig{}, tally.NoopScope)
+	cache.SetActive(true)
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithResponseCache(cache),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *transport.Request,
+		) (*transport.Response, error) {
+			return newResponse(_bodyStr), nil
+		}).
+		Times(2)
+
+	// the response cached for alice is not served to bob
+	for _, name := range []string{"alice", "bob", "alice"} {
+		ctx := auth.ContextWithUser(context.Background(), &testUser{name: name})
+		w := &transporttest.FakeResponseWriter{}
+		suite.NoError(handler.Handle(ctx, suite.newGetJobRequest(), w))
+		suite.Equal(_bodyStr, w.Body.String())
+	}
+}
+
+// TestHandleUnnamedCallerNotCached tests that the responses to
+// a user which cannot be identified are not cached.
+func (suite *UnaryHandlerTestSuite) TestHandleUnnamedCallerNotCached() {
+	cache := NewResponseCache(CacheConfig{}, tally.NoopScope)
+	cache.SetActive(true)
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithResponseCache(cache),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *transport.Request,
+		) (*transport.Response, error) {
+			return newResponse(_bodyStr), nil
+		}).
+		Times(2)
+
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.ContextWithUser(context.Background(), user)
+	for i := 0; i < 2; i++ {
+		w := &transporttest.FakeResponseWriter{}
+		suite.NoError(handler.Handle(ctx, suite.newGetJobRequest(), w))
+		suite.Equal(_bodyStr, w.Body.String())
+	}
+}
+
+// TestHandleApplicationErrorNotCached tests that
+// application errors are not cached.
+func (suite *UnaryHandlerTestSuite) TestHandleApplicationErrorNotCached() {
+	cache := NewResponseCache(CacheConfig{}, tally.NoopScope)
+	cache.SetActive(true)
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithResponseCache(cache),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *transport.Request,
+		) (*transport.Response, error) {
+			resp := newResponse(_bodyStr)
+			resp.ApplicationError = true
+			return resp, nil
+		}).
+		Times(2)
+
+	for i := 0; i < 2; i++ {
+		w := &transporttest.FakeResponseWriter{}
+		suite.NoError(handler.Handle(
+			context.Background(), suite.newGetJobRequest(), w))
+		suite.True(w.IsApplicationError)
+	}
+}
+
+// TestHandleFailoverRetry tests retrying idempotent
+// calls while the leader is unavailable.
+func (suite *UnaryHandlerTestSuite) TestHandleFailoverRetry() {
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithFailoverPolicy(NewFailoverPolicy(FailoverConfig{
+			Enabled:       true,
+			MaxAttempts:   3,
+			RetryInterval: time.Millisecond,
+		}, tally.NoopScope)),
+	)
+
+	gomock.InOrder(
+		suite.mockUnaryOutbound.EXPECT().
+			Call(gomock.Any(), gomock.Any()).
+			Return(nil, yarpcerrors.UnavailableErrorf("no leader")),
+		suite.mockUnaryOutbound.EXPECT().
+			Call(gomock.Any(), gomock.Any()).
+			Do(func(ctx context.Context, req *transport.Request) {
+				// the request body is sent again
+				body, err := ioutil.ReadAll(req.Body)
+				suite.NoError(err)
+				request := &statelesssvc.GetJobRequest{}
+				suite.NoError(proto.Unmarshal(body, request))
+				suite.Equal(_testJobID, request.GetJobId().GetValue())
+			}).
+			Return(newResponse(_bodyStr), nil),
+	)
+
+	w := &transporttest.FakeResponseWriter{}
+	suite.NoError(handler.Handle(
+		context.Background(), suite.newGetJobRequest(), w))
+	suite.Equal(_bodyStr, w.Body.String())
+}
+
+// TestHandleFailoverExhausted tests that calls are
+// not retried beyond the maximum number of attempts.
+func (suite *UnaryHandlerTestSuite) TestHandleFailoverExhausted() {
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithFailoverPolicy(NewFailoverPolicy(FailoverConfig{
+			Enabled:       true,
+			MaxAttempts:   2,
+			RetryInterval: time.Millisecond,
+		}, tally.NoopScope)),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("no leader")).
+		Times(2)
+
+	err := handler.Handle(
+		context.Background(),
+		suite.newGetJobRequest(),
+		&transporttest.FakeResponseWriter{},
+	)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestHandleFailoverNotRetried tests that calls to procedures which
+// are not idempotent, and calls which fail for other reasons than
+// the leader being unavailable are not retried.
+func (suite *UnaryHandlerTestSuite) TestHandleFailoverNotRetried() {
+	handler := NewUnaryForward(
+		suite.mockUnaryOutbound,
+		suite.overrideService,
+		WithFailoverPolicy(NewFailoverPolicy(FailoverConfig{
+			Enabled:       true,
+			RetryInterval: time.Millisecond,
+		}, tally.NoopScope)),
+	)
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("no leader"))
+	err := handler.Handle(
+		context.Background(),
+		&transport.Request{
+			Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			Body:      bytes.NewReader(nil),
+		},
+		&transporttest.FakeResponseWriter{},
+	)
+	suite.True(yarpcerrors.IsUnavailable(err))
+
+	suite.mockUnaryOutbound.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+	err = handler.Handle(
+		context.Background(),
+		suite.newGetJobRequest(),
+		&transporttest.FakeResponseWriter{},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
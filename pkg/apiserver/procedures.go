@@ -15,6 +15,8 @@
 package apiserver
 
 import (
+	pbv0cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	pbv0dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
 	pbv0hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	pbv0jobmgr "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	pbv0jobsvc "github.com/uber/peloton/.gen/peloton/api/v0/job/svc"
@@ -25,7 +27,10 @@ import (
 	pbv0updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	pbv0volumesvc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
 	pbv1alphaadminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	pbv1alphaauditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
 	pbv1alphahostsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+	pbv1alphajobbatchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	pbv1alphajobcronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	pbv1alphajobstatelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	pbv1alphapodsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	pbv1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
@@ -44,9 +49,11 @@ import (
 )
 
 // BuildJobManagerProcedures builds forwarding procedures for services that rely
-// on Job Manager. The outbounds must connect to the Job Manager leader.
+// on Job Manager. The outbounds must connect to the Job Manager leader. The
+// options, e.g. the response cache, apply to the unary procedures.
 func BuildJobManagerProcedures(
 	outbounds transport.Outbounds,
+	opts ...forward.UnaryOption,
 ) []transport.Procedure {
 	procedures :=
 		pbv0jobmgr.BuildJobManagerYARPCProcedures(nil)
@@ -58,6 +65,10 @@ func BuildJobManagerProcedures(
 		procedures,
 		pbv1alphajobstatelesssvc.BuildJobServiceYARPCProcedures(nil)...,
 	)
+	procedures = append(
+		procedures,
+		pbv1alphajobbatchsvc.BuildJobServiceYARPCProcedures(nil)...,
+	)
 	procedures = append(
 		procedures,
 		pbprivatejobmgrsvc.BuildJobManagerServiceYARPCProcedures(nil)...,
@@ -90,11 +101,28 @@ func BuildJobManagerProcedures(
 		procedures,
 		pbv1alphawatchsvc.BuildWatchServiceYARPCProcedures(nil)...,
 	)
+	procedures = append(
+		procedures,
+		pbv0cronsvc.BuildCronServiceYARPCProcedures(nil)...,
+	)
+	procedures = append(
+		procedures,
+		pbv0dagsvc.BuildDAGServiceYARPCProcedures(nil)...,
+	)
+	procedures = append(
+		procedures,
+		pbv1alphajobcronsvc.BuildCronJobServiceYARPCProcedures(nil)...,
+	)
+	procedures = append(
+		procedures,
+		pbv1alphaauditsvc.BuildAuditServiceYARPCProcedures(nil)...,
+	)
 
 	return convertProcedures(
 		procedures,
 		common.PelotonJobManager,
 		outbounds,
+		opts...,
 	)
 }
 
@@ -102,6 +130,7 @@ func BuildJobManagerProcedures(
 // by Host Manager. The outbounds must connect to the Host Manager leader.
 func BuildHostManagerProcedures(
 	outbounds transport.Outbounds,
+	opts ...forward.UnaryOption,
 ) []transport.Procedure {
 	procedures :=
 		pbv0hostsvc.BuildHostServiceYARPCProcedures(nil)
@@ -130,6 +159,7 @@ func BuildHostManagerProcedures(
 		procedures,
 		common.PelotonHostManager,
 		outbounds,
+		opts...,
 	)
 }
 
@@ -138,6 +168,7 @@ func BuildHostManagerProcedures(
 // Manager leader.
 func BuildResourceManagerProcedures(
 	outbounds transport.Outbounds,
+	opts ...forward.UnaryOption,
 ) []transport.Procedure {
 	procedures :=
 		pbv0resmgr.BuildResourceManagerYARPCProcedures(nil)
@@ -162,6 +193,7 @@ func BuildResourceManagerProcedures(
 		procedures,
 		common.PelotonResourceManager,
 		outbounds,
+		opts...,
 	)
 }
 
@@ -169,11 +201,12 @@ func convertProcedures(
 	procedures []transport.Procedure,
 	pelotonApplication string,
 	outbounds transport.Outbounds,
+	opts ...forward.UnaryOption,
 ) []transport.Procedure {
 	convertedProcedures := make([]transport.Procedure, 0, len(procedures))
 	for _, p := range procedures {
 		handlerSpec := transport.NewUnaryHandlerSpec(
-			forward.NewUnaryForward(outbounds.Unary, pelotonApplication),
+			forward.NewUnaryForward(outbounds.Unary, pelotonApplication, opts...),
 		)
 		if p.HandlerSpec.Type() == transport.Streaming {
 			handlerSpec = transport.NewStreamHandlerSpec(
@@ -17,6 +17,8 @@ package apiserver
 import (
 	"testing"
 
+	pbv0cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	pbv0dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
 	pbv0hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	pbv0jobmgr "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	pbv0jobsvc "github.com/uber/peloton/.gen/peloton/api/v0/job/svc"
@@ -27,7 +29,10 @@ import (
 	pbv0updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	pbv0volumesvc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
 	pbv1alphaadminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	pbv1alphaauditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
 	pbv1alphahostsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+	pbv1alphajobbatchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	pbv1alphajobcronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	pbv1alphajobstatelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	pbv1alphapodsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	pbv1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
@@ -103,6 +108,10 @@ func (suite *ProceduresTestSuite) TestBuildJobManagerProcedures() {
 		expectedProcedures,
 		pbv1alphajobstatelesssvc.BuildJobServiceYARPCProcedures(nil)...,
 	)
+	expectedProcedures = append(
+		expectedProcedures,
+		pbv1alphajobbatchsvc.BuildJobServiceYARPCProcedures(nil)...,
+	)
 	expectedProcedures = append(
 		expectedProcedures,
 		pbv1alphapodsvc.BuildPodServiceYARPCProcedures(nil)...,
@@ -111,6 +120,22 @@ func (suite *ProceduresTestSuite) TestBuildJobManagerProcedures() {
 		expectedProcedures,
 		pbv1alphawatchsvc.BuildWatchServiceYARPCProcedures(nil)...,
 	)
+	expectedProcedures = append(
+		expectedProcedures,
+		pbv0cronsvc.BuildCronServiceYARPCProcedures(nil)...,
+	)
+	expectedProcedures = append(
+		expectedProcedures,
+		pbv0dagsvc.BuildDAGServiceYARPCProcedures(nil)...,
+	)
+	expectedProcedures = append(
+		expectedProcedures,
+		pbv1alphajobcronsvc.BuildCronJobServiceYARPCProcedures(nil)...,
+	)
+	expectedProcedures = append(
+		expectedProcedures,
+		pbv1alphaauditsvc.BuildAuditServiceYARPCProcedures(nil)...,
+	)
 	expectedProcedures = append(
 		expectedProcedures,
 		pbprivatejobmgrsvc.BuildJobManagerServiceYARPCProcedures(nil)...,
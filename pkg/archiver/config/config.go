@@ -17,6 +17,8 @@ package config
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/archiver/retention"
+	"github.com/uber/peloton/pkg/archiver/sink"
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
@@ -105,6 +107,22 @@ type ArchiverConfig struct {
 
 	// Kafka topic used by archiver to stream jobs via filebeat
 	KafkaTopic string `yaml:"kafka_topic"`
+
+	// Sinks the completed jobs are archived to. Defaults to
+	// the filebeat sink streaming to KafkaTopic.
+	Sinks []sink.Config `yaml:"sinks"`
+
+	// Path of the file recording the completion time range to archive
+	// next, so that archival resumes after a restart. Archival starts
+	// from ArchiveAge on every restart if not set.
+	CheckpointPath string `yaml:"checkpoint_path"`
+
+	// Number of runs of pod events archived per instance by the sinks
+	// which archive pod events. Defaults to the job manager limit.
+	PodEventRuns uint64 `yaml:"pod_event_runs"`
+
+	// Retention policies per resource pool, job type and labels
+	Retention retention.Config `yaml:"retention"`
 }
 
 // Normalize configuration by setting unassigned fields to default values.
@@ -133,4 +151,14 @@ func (c *ArchiverConfig) Normalize() {
 	if c.BootstrapDelay == 0 {
 		c.BootstrapDelay = _defaultBootstrapDelay
 	}
+	c.Retention.Normalize()
+	if len(c.Sinks) == 0 {
+		c.Sinks = []sink.Config{{Type: sink.FilebeatSink}}
+	}
+	for i := range c.Sinks {
+		if c.Sinks[i].Type == sink.FilebeatSink &&
+			len(c.Sinks[i].Filebeat.Topic) == 0 {
+			c.Sinks[i].Filebeat.Topic = c.KafkaTopic
+		}
+	}
 }
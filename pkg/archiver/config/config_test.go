@@ -17,11 +17,13 @@ package config
 import (
 	"testing"
 
+	"github.com/uber/peloton/pkg/archiver/sink"
+
 	"github.com/stretchr/testify/assert"
 )
 
 func TestConfigNormalize(t *testing.T) {
-	c := ArchiverConfig{}
+	c := ArchiverConfig{KafkaTopic: "completed-jobs"}
 
 	c.Normalize()
 
@@ -33,4 +35,30 @@ func TestConfigNormalize(t *testing.T) {
 	assert.Equal(t, _defaultMaxRetryAttemptsJobQuery, c.MaxRetryAttemptsJobQuery)
 	assert.Equal(t, _defaultRetryIntervalJobQuery, c.RetryIntervalJobQuery)
 	assert.Equal(t, _defaultBootstrapDelay, c.BootstrapDelay)
+	assert.Equal(t, []sink.Config{
+		{
+			Type:     sink.FilebeatSink,
+			Filebeat: sink.FilebeatConfig{Topic: "completed-jobs"},
+		},
+	}, c.Sinks)
+}
+
+// TestConfigNormalizeSinks tests that configured sinks are kept
+func TestConfigNormalizeSinks(t *testing.T) {
+	c := ArchiverConfig{
+		KafkaTopic: "completed-jobs",
+		Sinks: []sink.Config{
+			{Type: sink.ParquetSink},
+			{
+				Type:     sink.FilebeatSink,
+				Filebeat: sink.FilebeatConfig{Topic: "other-topic"},
+			},
+		},
+	}
+
+	c.Normalize()
+
+	assert.Len(t, c.Sinks, 2)
+	assert.Equal(t, sink.ParquetSink, c.Sinks[0].Type)
+	assert.Equal(t, "other-topic", c.Sinks[1].Filebeat.Topic)
 }
@@ -28,10 +28,23 @@ By default, the archiver engine will function like this:
 	1. Archiver thread wakes up every 24 hours
 	2. Archiver thread uses peloton client to make JobQuery API request
 	   to jobmgr that queries for jobs that have been completed 30 days ago or earlier.
-	3. The job config for these jobs will be sent out as json data to Kafka upstream.
-	4. Once the jobconfig is sent to secondary storage via Kafka, the archiver will
+	3. The job summaries for these jobs will be logged for filebeat to send out
+	   as json data to Kafka upstream.
+	4. Once the jobs are sent to secondary storage, the archiver will
 	   call the JobDelete API for this job_id
 	Outside the scope of this code, the data streamed to kafka will be ingested by
 	secondary storage like ELK or query builder.
+
+The jobs can also be archived to other sinks (see package archiver/sink):
+rotating local JSON lines files, an S3 compatible object store, and Parquet
+files of the job configs, runtimes and pod events. Jobs are deleted only once
+every sink has archived them, so the sinks are written at least once. When
+checkpoint_path is set, the time range to archive next is saved after each
+run so that archival resumes after a restart.
+
+Retention policies (see package archiver/retention) override archive_age and
+the pod events limit for the jobs of a resource pool subtree, job type or
+labels. The archiver deletes the jobs and pod events expired by each policy
+through the job manager APIs, or only reports them in dry run mode.
 */
 package archiver
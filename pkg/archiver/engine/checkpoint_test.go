@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckpoint tests saving and loading the archiver checkpoint
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archiver-checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+
+	cp, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Nil(t, cp)
+
+	maxTime := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := &checkpoint{
+		MinTime: maxTime.Add(-24 * time.Hour),
+		MaxTime: maxTime,
+	}
+	assert.NoError(t, saveCheckpoint(path, expected))
+
+	cp, err = loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.True(t, expected.MinTime.Equal(cp.MinTime))
+	assert.True(t, expected.MaxTime.Equal(cp.MaxTime))
+
+	// the checkpoint is replaced
+	expected.MaxTime = expected.MinTime
+	expected.MinTime = expected.MinTime.Add(-24 * time.Hour)
+	assert.NoError(t, saveCheckpoint(path, expected))
+
+	cp, err = loadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.True(t, expected.MinTime.Equal(cp.MinTime))
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0640))
+	_, err = loadCheckpoint(path)
+	assert.Error(t, err)
+}
@@ -24,8 +24,11 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/pkg/archiver/config"
+	"github.com/uber/peloton/pkg/archiver/retention"
+	"github.com/uber/peloton/pkg/archiver/sink"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
@@ -52,15 +55,6 @@ const (
 	// Keep default max jitter to 100ms
 	jitterMax = 100
 
-	// The string "completed_job" will be used to tag the logs that contain
-	// job summary. This will be used by logstash and streamed using a heatpipe
-	// kafka topic to Hive table
-	completedJobTag = "completed_job"
-
-	// The key "filebeat_topic" will be used by filebeat to stream completed
-	// jobs to kafka topic specified
-	filebeatTopic = "filebeat_topic"
-
 	// archiver summary map keys
 	archiverSuccessKey = "SUCCESS"
 	archiverFailureKey = "FAILURE"
@@ -71,7 +65,7 @@ const (
 
 // Engine defines the interface used to query a peloton component
 // for data and then archive that data to secondary storage using
-// message queue
+// the configured sinks
 type Engine interface {
 	// Start starts the archiver goroutines
 	Start() error
@@ -93,6 +87,21 @@ type engine struct {
 	metrics *Metrics
 	// Archiver backoff/retry policy
 	retryPolicy backoff.RetryPolicy
+	// Sinks the completed jobs are archived to
+	sinks []sink.Sink
+	// Set if any sink archives the job configs, runtimes and pod events
+	detailed bool
+	// Resource Manager client to query the resource pool paths,
+	// only set if a retention policy matches jobs by resource pool
+	respoolClient respool.ResourceManagerYARPCClient
+	// Retention policies in order of precedence
+	policies []*retention.Policy
+	// Paths of the resource pools by ID, refreshed on every run
+	respoolPaths map[string]string
+	// Publishes the retention reports
+	reporter *retention.Reporter
+	// Retention report of the current run
+	report *retention.Report
 }
 
 // New creates a new Archiver Engine.
@@ -121,15 +130,33 @@ func New(
 	}
 	authOutboundMiddleware := outbound.NewAuthOutboundMiddleware(securityClient)
 
+	policies, err := retention.NewPolicies(cfg.Archiver.Retention.Policies)
+	if err != nil {
+		return nil, err
+	}
+
 	t := grpc.NewTransport()
-	dispatcher := yarpc.NewDispatcher(yarpc.Config{
-		Name:     config.PelotonArchiver,
-		Inbounds: inbounds,
-		Outbounds: yarpc.Outbounds{
-			common.PelotonJobManager: transport.Outbounds{
-				Unary: t.NewSingleOutbound(jobmgrURL.Host),
-			},
+	outbounds := yarpc.Outbounds{
+		common.PelotonJobManager: transport.Outbounds{
+			Unary: t.NewSingleOutbound(jobmgrURL.Host),
 		},
+	}
+	// Resource Manager is only needed to match the jobs
+	// with the resource pools of the retention policies
+	if retention.UsesRespools(policies) {
+		resmgrURL, err := discovery.GetAppURL(common.ResourceManagerRole)
+		if err != nil {
+			return nil, err
+		}
+		outbounds[common.PelotonResourceManager] = transport.Outbounds{
+			Unary: t.NewSingleOutbound(resmgrURL.Host),
+		}
+	}
+
+	dispatcher := yarpc.NewDispatcher(yarpc.Config{
+		Name:      config.PelotonArchiver,
+		Inbounds:  inbounds,
+		Outbounds: outbounds,
 		Metrics: yarpc.MetricsConfig{
 			Tally: scope,
 		},
@@ -145,10 +172,31 @@ func New(
 		},
 	})
 
+	var sinks []sink.Sink
+	var detailed bool
+	for i := range cfg.Archiver.Sinks {
+		s, err := sink.New(&cfg.Archiver.Sinks[i])
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+		detailed = detailed || s.Detailed()
+	}
+
 	if err := dispatcher.Start(); err != nil {
 		return nil, fmt.Errorf("Unable to start dispatcher: %v", err)
 	}
 
+	var respoolClient respool.ResourceManagerYARPCClient
+	if retention.UsesRespools(policies) {
+		respoolClient = respool.NewResourceManagerYARPCClient(
+			dispatcher.ClientConfig(common.PelotonResourceManager),
+		)
+	}
+
+	reporter := retention.NewReporter(cfg.Archiver.Retention.ReportPath)
+	mux.HandleFunc(RetentionReportPath, retentionReportHandler(reporter))
+
 	return &engine{
 		jobClient: job.NewJobManagerYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
@@ -162,6 +210,11 @@ func New(
 		retryPolicy: backoff.NewRetryPolicy(
 			cfg.Archiver.MaxRetryAttemptsJobQuery,
 			cfg.Archiver.RetryIntervalJobQuery),
+		sinks:         sinks,
+		detailed:      detailed,
+		respoolClient: respoolClient,
+		policies:      policies,
+		reporter:      reporter,
 	}, nil
 }
 
@@ -178,11 +231,34 @@ func (e *engine) Start() error {
 	e.metrics.ArchiverStart.Inc(1)
 	jitter := time.Duration(rand.Intn(jitterMax)) * time.Millisecond
 	time.Sleep(e.config.Archiver.BootstrapDelay + jitter)
-	// At first, the time range will be [(t-30d-1d), (t-30d))
+	// At first, the time range will be [(t-30d-1d), (t-30d)), unless
+	// a previous run checkpointed the time range to resume from
 	maxTime := time.Now().UTC().Add(-e.config.Archiver.ArchiveAge)
 	minTime := maxTime.Add(-e.config.Archiver.ArchiveStepSize)
+	if len(e.config.Archiver.CheckpointPath) > 0 {
+		cp, err := loadCheckpoint(e.config.Archiver.CheckpointPath)
+		if err != nil {
+			return err
+		}
+		if cp != nil {
+			log.WithFields(log.Fields{
+				"min_time": cp.MinTime,
+				"max_time": cp.MaxTime,
+			}).Info("Resuming archiver from checkpoint")
+			minTime, maxTime = cp.MinTime, cp.MaxTime
+		}
+	}
 
 	for {
+		if len(e.policies) > 0 {
+			e.report = retention.NewReport(
+				time.Now().UTC(),
+				e.config.Archiver.Retention.DryRun)
+			if err := e.runRetention(context.Background()); err != nil {
+				return err
+			}
+		}
+
 		if e.config.Archiver.Enable {
 			startTime := time.Now()
 			max, err := ptypes.TimestampProto(maxTime)
@@ -208,12 +284,15 @@ func (e *engine) Start() error {
 				},
 			}
 
+			batchMin, batchMax := minTime, maxTime
 			if err := e.runArchiver(
 				&job.QueryRequest{
 					Spec:        &spec,
 					SummaryOnly: true,
 				},
-				e.archiveJobs); err != nil {
+				func(ctx context.Context, results []*job.JobSummary) error {
+					return e.archiveJobs(ctx, batchMin, batchMax, results)
+				}); err != nil {
 				return err
 			}
 
@@ -221,6 +300,18 @@ func (e *engine) Start() error {
 			e.metrics.ArchiverRunDuration.Record(time.Since(startTime))
 			maxTime = minTime
 			minTime = minTime.Add(-e.config.Archiver.ArchiveStepSize)
+
+			if len(e.config.Archiver.CheckpointPath) > 0 {
+				if err := saveCheckpoint(
+					e.config.Archiver.CheckpointPath,
+					&checkpoint{MinTime: minTime, MaxTime: maxTime},
+				); err != nil {
+					log.WithError(err).
+						WithField("path", e.config.Archiver.CheckpointPath).
+						Error("failed to save archiver checkpoint")
+					e.metrics.ArchiverCheckpointFail.Inc(1)
+				}
+			}
 		}
 
 		if e.config.Archiver.PodEventsCleanup {
@@ -248,6 +339,8 @@ func (e *engine) Start() error {
 			e.metrics.PodDeleteEventsRunDuration.Record(time.Since(startTime))
 		}
 
+		e.publishReport()
+
 		jitter := time.Duration(rand.Intn(jitterMax)) * time.Millisecond
 		time.Sleep(e.config.Archiver.ArchiveInterval + jitter)
 	}
@@ -256,6 +349,13 @@ func (e *engine) Start() error {
 // Cleanup cleans the archiver engine before restarting
 func (e *engine) Cleanup() {
 	e.dispatcher.Stop()
+	for _, s := range e.sinks {
+		if err := s.Close(); err != nil {
+			log.WithError(err).
+				WithField("sink", s.Name()).
+				Warn("failed to close archiver sink")
+		}
+	}
 	return
 }
 
@@ -264,7 +364,7 @@ func (e *engine) runArchiver(
 	queryReq *job.QueryRequest,
 	action func(
 		ctx context.Context,
-		results []*job.JobSummary)) error {
+		results []*job.JobSummary) error) error {
 	p := backoff.NewRetrier(e.retryPolicy)
 	queryResp, err := e.queryJobs(
 		context.Background(),
@@ -275,37 +375,41 @@ func (e *engine) runArchiver(
 	}
 
 	results := queryResp.GetResults()
-	action(
+	return action(
 		context.Background(),
 		results)
-	return nil
 }
 
-// archiveJobs archives only batch jobs.
+// archiveJobs archives only batch jobs which are not governed by a
+// retention policy. The jobs completed between minTime and maxTime are
+// written to all the sinks, and are deleted only once every sink has
+// archived them.
 func (e *engine) archiveJobs(
 	ctx context.Context,
-	results []*job.JobSummary) {
+	minTime time.Time,
+	maxTime time.Time,
+	results []*job.JobSummary) error {
 	if len(results) > 0 {
-		archiveSummary := map[string]int{archiverFailureKey: 0, archiverSuccessKey: 0}
+		var summaries []*job.JobSummary
 		for _, summary := range results {
-			if summary.GetType() != job.JobType_BATCH {
-				continue
+			if summary.GetType() == job.JobType_BATCH &&
+				e.policyFor(summary) == nil {
+				summaries = append(summaries, summary)
 			}
-			// Sleep between consecutive Job Delete requests
-			time.Sleep(delayDelete)
+		}
 
-			// The log event for completedJobTag will be logged to Archiver stdout
-			// Filebeat configured on the Peloton host will ship this log out to
-			// logstash. Logstash will be configured to stream this specific log
-			// event to Hive via a heatpipe topic.
-			log.WithFields(log.Fields{
-				filebeatTopic:   e.config.Archiver.KafkaTopic,
-				completedJobTag: summary,
-			}).Info("completed job")
+		if err := e.writeSinks(ctx, minTime, maxTime, summaries); err != nil {
+			return err
+		}
 
-			if e.config.Archiver.StreamOnlyMode {
-				continue
-			}
+		if e.config.Archiver.StreamOnlyMode {
+			return nil
+		}
+
+		archiveSummary := map[string]int{archiverFailureKey: 0, archiverSuccessKey: 0}
+		for _, summary := range summaries {
+			// Sleep between consecutive Job Delete requests
+			time.Sleep(delayDelete)
 
 			log.WithFields(log.Fields{
 				"job_id": summary.GetId().GetValue(),
@@ -350,6 +454,92 @@ func (e *engine) archiveJobs(
 		// results, we should move the archive window back to now - 30days
 		e.metrics.ArchiverNoJobsInTimerange.Inc(1)
 	}
+	return nil
+}
+
+// writeSinks writes the completed jobs to all the sinks. The job
+// configs, runtimes and pod events are only fetched if a sink
+// archives them.
+func (e *engine) writeSinks(
+	ctx context.Context,
+	minTime time.Time,
+	maxTime time.Time,
+	summaries []*job.JobSummary) error {
+	if len(summaries) == 0 || len(e.sinks) == 0 {
+		return nil
+	}
+
+	batch := &sink.Batch{MinTime: minTime, MaxTime: maxTime}
+	for _, summary := range summaries {
+		record := &sink.Record{Summary: summary}
+		if e.detailed {
+			if err := e.fillRecord(ctx, record); err != nil {
+				e.metrics.ArchiverJobGetFail.Inc(1)
+				return err
+			}
+		}
+		batch.Records = append(batch.Records, record)
+	}
+
+	for _, s := range e.sinks {
+		if err := s.Write(ctx, batch); err != nil {
+			log.WithError(err).
+				WithField("sink", s.Name()).
+				WithField("jobs", len(batch.Records)).
+				Error("failed to write completed jobs to archiver sink")
+			e.metrics.ArchiverSinkWriteFail.Inc(1)
+			return fmt.Errorf("archiver sink %s: %v", s.Name(), err)
+		}
+		e.metrics.ArchiverSinkWriteSuccess.Inc(1)
+	}
+	e.metrics.ArchiverJobsStreamed.Inc(int64(len(batch.Records)))
+	return nil
+}
+
+// fillRecord fetches the job config, runtime and pod events of
+// the completed job in the record
+func (e *engine) fillRecord(ctx context.Context, record *sink.Record) error {
+	jobID := record.Summary.GetId()
+
+	getCtx, cancel := context.WithTimeout(
+		ctx,
+		e.config.Archiver.PelotonClientTimeout,
+	)
+	resp, err := e.jobClient.Get(getCtx, &job.GetRequest{Id: jobID})
+	cancel()
+	if err != nil {
+		return err
+	}
+	if resp.GetError() != nil {
+		return fmt.Errorf("failed to get job %s: %v",
+			jobID.GetValue(), resp.GetError())
+	}
+	record.Config = resp.GetJobInfo().GetConfig()
+	record.Runtime = resp.GetJobInfo().GetRuntime()
+
+	for i := uint32(0); i < record.Summary.GetInstanceCount(); i++ {
+		eventsCtx, cancel := context.WithTimeout(
+			ctx,
+			e.config.Archiver.PelotonClientTimeout,
+		)
+		eventsResp, err := e.taskClient.GetPodEvents(
+			eventsCtx,
+			&task.GetPodEventsRequest{
+				JobId:      jobID,
+				InstanceId: i,
+				Limit:      e.config.Archiver.PodEventRuns,
+			})
+		cancel()
+		if err != nil {
+			return err
+		}
+		if eventsResp.GetError() != nil {
+			return fmt.Errorf("failed to get pod events of %s-%d: %s",
+				jobID.GetValue(), i, eventsResp.GetError().GetMessage())
+		}
+		record.PodEvents = append(record.PodEvents, eventsResp.GetResult()...)
+	}
+	return nil
 }
 
 // deletePodEvents reads RUNNING service jobs and deletes,
@@ -357,13 +547,24 @@ func (e *engine) archiveJobs(
 // This action is to constraint #runs in DB, to prevent large partitions
 // 1) Get the most recent run_id from DB.
 // 2) If more than 100 runs exist, delete the delta.
+// Jobs of any type governed by a retention policy setting the number
+// of runs to keep are constrained to that number instead.
 func (e *engine) deletePodEvents(
 	ctx context.Context,
-	results []*job.JobSummary) {
+	results []*job.JobSummary) error {
 	var i uint32
 	for _, jobSummary := range results {
-		if jobSummary.GetType() != job.JobType_SERVICE {
-			continue
+		// jobs governed by a policy setting the number of runs keep
+		// that number, other service jobs keep the default number
+		runsToKeep := _defaultPodEventsToConstraint
+		policy := e.policyFor(jobSummary)
+		if policy != nil && policy.PodEventRuns > 0 {
+			runsToKeep = policy.PodEventRuns
+		} else {
+			policy = nil
+			if jobSummary.GetType() != job.JobType_SERVICE {
+				continue
+			}
 		}
 		log.WithFields(log.Fields{
 			"job_id": jobSummary.GetId().GetValue(),
@@ -409,11 +610,25 @@ func (e *engine) deletePodEvents(
 				continue
 			}
 
-			if runID > _defaultPodEventsToConstraint {
+			if runID > runsToKeep {
+				var action *retention.PodEventsAction
+				if policy != nil {
+					action = &retention.PodEventsAction{
+						Policy:     policy.Name,
+						JobID:      jobSummary.GetId().GetValue(),
+						InstanceID: i,
+						RunID:      runID - runsToKeep,
+					}
+					e.report.PodEvents = append(e.report.PodEvents, action)
+					if e.report.DryRun {
+						continue
+					}
+				}
+
 				log.WithFields(log.Fields{
 					"job_id":      jobSummary.GetId(),
 					"instance_id": i,
-					"run_id":      runID - _defaultPodEventsToConstraint,
+					"run_id":      runID - runsToKeep,
 					"task_id":     response.GetResult()[0].GetTaskId().GetValue(),
 				}).Info("Delete runs")
 				ctx, cancel := context.WithTimeout(
@@ -426,8 +641,11 @@ func (e *engine) deletePodEvents(
 					&task.DeletePodEventsRequest{
 						JobId:      jobSummary.GetId(),
 						InstanceId: i,
-						RunId:      runID - _defaultPodEventsToConstraint})
+						RunId:      runID - runsToKeep})
 				if err != nil {
+					if action != nil {
+						action.Error = err.Error()
+					}
 					log.WithFields(log.Fields{
 						"job_id":      jobSummary.GetId(),
 						"instance_id": i,
@@ -441,6 +659,7 @@ func (e *engine) deletePodEvents(
 			e.metrics.PodDeleteEventsSuccess.Inc(1)
 		}
 	}
+	return nil
 }
 
 func (e *engine) queryJobs(
@@ -17,8 +17,11 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	nethttp "net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -30,6 +33,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	task_mocks "github.com/uber/peloton/.gen/peloton/api/v0/task/mocks"
 	"github.com/uber/peloton/pkg/archiver/config"
+	"github.com/uber/peloton/pkg/archiver/sink"
+	sink_mocks "github.com/uber/peloton/pkg/archiver/sink/mocks"
 	"github.com/uber/peloton/pkg/common/backoff"
 	"github.com/uber/peloton/pkg/common/leader"
 	"go.uber.org/yarpc"
@@ -316,9 +321,11 @@ func (suite *archiverEngineTestSuite) TestArchiveJobs() {
 			Return(nil, fmt.Errorf("Job Delete failed")),
 	)
 
-	suite.e.archiveJobs(
+	suite.NoError(suite.e.archiveJobs(
 		context.Background(),
-		summaryList)
+		time.Time{},
+		time.Time{},
+		summaryList))
 }
 
 // TestArchiveJobsService tests that service jobs are not archived
@@ -330,9 +337,11 @@ func (suite *archiverEngineTestSuite) TestArchiveJobsService() {
 		},
 	}
 
-	suite.e.archiveJobs(
+	suite.NoError(suite.e.archiveJobs(
 		context.Background(),
-		summaryList)
+		time.Time{},
+		time.Time{},
+		summaryList))
 }
 
 // TestArchiveJobsStreamOnly tests that for stream only mode, jobs are not
@@ -345,7 +354,231 @@ func (suite *archiverEngineTestSuite) TestArchiveJobsStreamOnly() {
 			Id:   &peloton.JobID{Value: "my-job-0"},
 		},
 	}
-	suite.e.archiveJobs(
+	suite.NoError(suite.e.archiveJobs(
 		context.Background(),
-		summaryList)
+		time.Time{},
+		time.Time{},
+		summaryList))
+}
+
+// newSinkEngine returns an engine archiving to a mock sink
+func (suite *archiverEngineTestSuite) newSinkEngine(
+	detailed bool) (*engine, *sink_mocks.MockSink) {
+	mockSink := sink_mocks.NewMockSink(suite.mockCtrl)
+	mockSink.EXPECT().Name().Return("mock").AnyTimes()
+	return &engine{
+		jobClient:  suite.mockJobClient,
+		taskClient: suite.mockTaskClient,
+		config: config.Config{
+			Archiver: config.ArchiverConfig{
+				PelotonClientTimeout: time.Second,
+				PodEventRuns:         2,
+			},
+		},
+		metrics:  NewMetrics(tally.NoopScope),
+		sinks:    []sink.Sink{mockSink},
+		detailed: detailed,
+	}, mockSink
+}
+
+// TestArchiveJobsSinks tests that the job config, runtime and pod events
+// are written to a detailed sink before the job is deleted
+func (suite *archiverEngineTestSuite) TestArchiveJobsSinks() {
+	e, mockSink := suite.newSinkEngine(true)
+
+	jobID := &peloton.JobID{Value: "my-job-0"}
+	summaryList := []*job.JobSummary{
+		{
+			Type:          job.JobType_BATCH,
+			Id:            jobID,
+			InstanceCount: 2,
+		},
+		{
+			Type: job.JobType_SERVICE,
+			Id:   &peloton.JobID{Value: "my-service-0"},
+		},
+	}
+	jobInfo := &job.JobInfo{
+		Config:  &job.JobConfig{Name: "my-job"},
+		Runtime: &job.RuntimeInfo{State: job.JobState_SUCCEEDED},
+	}
+	taskID := "my-job-0-1-1"
+	events := []*task.PodEvent{
+		{TaskId: &mesos.TaskID{Value: &taskID}},
+	}
+	maxTime := time.Now().UTC()
+	minTime := maxTime.Add(-time.Hour)
+
+	gomock.InOrder(
+		suite.mockJobClient.EXPECT().
+			Get(gomock.Any(), &job.GetRequest{Id: jobID}).
+			Return(&job.GetResponse{JobInfo: jobInfo}, nil),
+		suite.mockTaskClient.EXPECT().
+			GetPodEvents(gomock.Any(), &task.GetPodEventsRequest{
+				JobId:      jobID,
+				InstanceId: 0,
+				Limit:      2,
+			}).
+			Return(&task.GetPodEventsResponse{}, nil),
+		suite.mockTaskClient.EXPECT().
+			GetPodEvents(gomock.Any(), &task.GetPodEventsRequest{
+				JobId:      jobID,
+				InstanceId: 1,
+				Limit:      2,
+			}).
+			Return(&task.GetPodEventsResponse{Result: events}, nil),
+		mockSink.EXPECT().
+			Write(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, batch *sink.Batch) {
+				suite.Equal(minTime, batch.MinTime)
+				suite.Equal(maxTime, batch.MaxTime)
+				suite.Len(batch.Records, 1)
+				suite.Equal(summaryList[0], batch.Records[0].Summary)
+				suite.Equal(jobInfo.GetConfig(), batch.Records[0].Config)
+				suite.Equal(jobInfo.GetRuntime(), batch.Records[0].Runtime)
+				suite.Equal(events, batch.Records[0].PodEvents)
+			}).
+			Return(nil),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: jobID}).
+			Return(&job.DeleteResponse{}, nil),
+	)
+
+	suite.NoError(e.archiveJobs(
+		context.Background(),
+		minTime,
+		maxTime,
+		summaryList))
+}
+
+// TestArchiveJobsSummarySink tests that only the job summaries are
+// fetched for sinks which are not detailed
+func (suite *archiverEngineTestSuite) TestArchiveJobsSummarySink() {
+	e, mockSink := suite.newSinkEngine(false)
+	e.config.Archiver.StreamOnlyMode = true
+
+	summaryList := []*job.JobSummary{
+		{
+			Type: job.JobType_BATCH,
+			Id:   &peloton.JobID{Value: "my-job-0"},
+		},
+	}
+
+	mockSink.EXPECT().
+		Write(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, batch *sink.Batch) {
+			suite.Len(batch.Records, 1)
+			suite.Nil(batch.Records[0].Config)
+		}).
+		Return(nil)
+
+	suite.NoError(e.archiveJobs(
+		context.Background(),
+		time.Time{},
+		time.Time{},
+		summaryList))
+}
+
+// TestArchiveJobsSinkFailure tests that jobs are not deleted
+// if a sink fails to archive them
+func (suite *archiverEngineTestSuite) TestArchiveJobsSinkFailure() {
+	e, mockSink := suite.newSinkEngine(false)
+
+	summaryList := []*job.JobSummary{
+		{
+			Type: job.JobType_BATCH,
+			Id:   &peloton.JobID{Value: "my-job-0"},
+		},
+	}
+
+	mockSink.EXPECT().
+		Write(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("sink unavailable"))
+
+	suite.Error(e.archiveJobs(
+		context.Background(),
+		time.Time{},
+		time.Time{},
+		summaryList))
+}
+
+// TestArchiveJobsGetFailure tests that jobs are not archived
+// if their details cannot be fetched
+func (suite *archiverEngineTestSuite) TestArchiveJobsGetFailure() {
+	e, _ := suite.newSinkEngine(true)
+
+	summaryList := []*job.JobSummary{
+		{
+			Type: job.JobType_BATCH,
+			Id:   &peloton.JobID{Value: "my-job-0"},
+		},
+	}
+
+	suite.mockJobClient.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("Job Get failed"))
+
+	suite.Error(e.archiveJobs(
+		context.Background(),
+		time.Time{},
+		time.Time{},
+		summaryList))
+}
+
+// TestEngineStartCheckpoint tests that the archiver resumes from
+// the checkpointed time range, and checkpoints the next one
+func (suite *archiverEngineTestSuite) TestEngineStartCheckpoint() {
+	dir, err := ioutil.TempDir("", "archiver-engine")
+	suite.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+	maxTime := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	minTime := maxTime.Add(-time.Hour)
+	suite.NoError(saveCheckpoint(path, &checkpoint{
+		MinTime: minTime,
+		MaxTime: maxTime,
+	}))
+
+	e := &engine{
+		jobClient: suite.mockJobClient,
+		config: config.Config{
+			Archiver: config.ArchiverConfig{
+				Enable:          true,
+				ArchiveInterval: 10 * time.Millisecond,
+				BootstrapDelay:  10 * time.Millisecond,
+				ArchiveStepSize: time.Hour,
+				CheckpointPath:  path,
+			},
+		},
+		metrics:     NewMetrics(tally.NoopScope),
+		dispatcher:  yarpc.NewDispatcher(yarpc.Config{Name: config.PelotonArchiver}),
+		retryPolicy: suite.retryPolicy,
+	}
+
+	gomock.InOrder(
+		suite.mockJobClient.EXPECT().Query(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *job.QueryRequest) {
+				timeRange := req.GetSpec().GetCompletionTimeRange()
+				min, err := ptypes.Timestamp(timeRange.GetMin())
+				suite.NoError(err)
+				max, err := ptypes.Timestamp(timeRange.GetMax())
+				suite.NoError(err)
+				suite.True(minTime.Equal(min))
+				suite.True(maxTime.Equal(max))
+			}).
+			Return(&job.QueryResponse{}, nil),
+
+		// Job Query fails on all three retries, which stops the archiver
+		suite.mockJobClient.EXPECT().Query(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("Job Query failed")).
+			Times(3),
+	)
+
+	suite.Error(e.Start())
+
+	cp, err := loadCheckpoint(path)
+	suite.NoError(err)
+	suite.True(minTime.Equal(cp.MaxTime))
+	suite.True(minTime.Add(-time.Hour).Equal(cp.MinTime))
 }
@@ -27,6 +27,18 @@ type Metrics struct {
 	ArchiverJobDeleteSuccess  tally.Counter
 	ArchiverJobDeleteFail     tally.Counter
 	ArchiverNoJobsInTimerange tally.Counter
+	ArchiverJobGetFail        tally.Counter
+	ArchiverJobsStreamed      tally.Counter
+	ArchiverSinkWriteSuccess  tally.Counter
+	ArchiverSinkWriteFail     tally.Counter
+	ArchiverCheckpointFail    tally.Counter
+
+	RetentionRun              tally.Counter
+	RetentionRunDuration      tally.Timer
+	RetentionRespoolQueryFail tally.Counter
+	RetentionJobDeleteSuccess tally.Counter
+	RetentionJobDeleteFail    tally.Counter
+	RetentionJobDeleteDryRun  tally.Counter
 
 	PodDeleteEventsFail    tally.Counter
 	PodDeleteEventsSuccess tally.Counter
@@ -47,6 +59,18 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ArchiverJobDeleteSuccess:  scope.Counter("archiver_job_delete_success"),
 		ArchiverJobDeleteFail:     scope.Counter("archiver_job_delete_fail"),
 		ArchiverNoJobsInTimerange: scope.Counter("archiver_no_jobs_in_timerange"),
+		ArchiverJobGetFail:        scope.Counter("archiver_job_get_fail"),
+		ArchiverJobsStreamed:      scope.Counter("archiver_jobs_streamed"),
+		ArchiverSinkWriteSuccess:  scope.Counter("archiver_sink_write_success"),
+		ArchiverSinkWriteFail:     scope.Counter("archiver_sink_write_fail"),
+		ArchiverCheckpointFail:    scope.Counter("archiver_checkpoint_fail"),
+
+		RetentionRun:              scope.Counter("retention_run"),
+		RetentionRunDuration:      scope.Timer("retention_run_duration"),
+		RetentionRespoolQueryFail: scope.Counter("retention_respool_query_fail"),
+		RetentionJobDeleteSuccess: scope.Counter("retention_job_delete_success"),
+		RetentionJobDeleteFail:    scope.Counter("retention_job_delete_fail"),
+		RetentionJobDeleteDryRun:  scope.Counter("retention_job_delete_dry_run"),
 		PodDeleteEventsSuccess:    scope.Counter("pod_delete_events_success"),
 		PodDeleteEventsFail:       scope.Counter("pod_delete_events_fail"),
 
@@ -0,0 +1,262 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"sort"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/pkg/archiver/retention"
+	"github.com/uber/peloton/pkg/common/backoff"
+
+	"github.com/golang/protobuf/ptypes"
+	log "github.com/sirupsen/logrus"
+)
+
+// RetentionReportPath is the HTTP path serving the retention
+// report of the last archiver run
+const RetentionReportPath = "/retention/report"
+
+// retentionReportHandler serves the last retention report
+func retentionReportHandler(reporter *retention.Reporter) nethttp.HandlerFunc {
+	return func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		report := reporter.Last()
+		if report == nil {
+			nethttp.Error(w, "no retention report yet", nethttp.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(report)
+	}
+}
+
+// refreshRespoolPaths reads the paths of the resource pools, used to
+// match the jobs with the resource pools of the retention policies
+func (e *engine) refreshRespoolPaths(ctx context.Context) error {
+	if e.respoolClient == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(
+		ctx,
+		e.config.Archiver.PelotonClientTimeout,
+	)
+	defer cancel()
+
+	resp, err := e.respoolClient.Query(ctx, &respool.QueryRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetError() != nil {
+		return fmt.Errorf("failed to query resource pools: %v", resp.GetError())
+	}
+
+	paths := make(map[string]string)
+	for _, info := range resp.GetResourcePools() {
+		paths[info.GetId().GetValue()] = info.GetPath().GetValue()
+	}
+	e.respoolPaths = paths
+	return nil
+}
+
+// policyFor returns the retention policy of the job, nil if the job
+// is retained according to the global archiver config
+func (e *engine) policyFor(summary *job.JobSummary) *retention.Policy {
+	return retention.Match(e.policies, summary, e.respoolPaths)
+}
+
+// runRetention deletes the terminal jobs which completed longer ago
+// than the retention of their policy. In dry run mode, the jobs are
+// only reported.
+func (e *engine) runRetention(ctx context.Context) error {
+	startTime := time.Now()
+	if err := e.refreshRespoolPaths(ctx); err != nil {
+		e.metrics.RetentionRespoolQueryFail.Inc(1)
+		return err
+	}
+
+	for _, p := range e.policies {
+		for _, state := range p.States() {
+			keep, _ := p.Retention(state)
+			maxTime := startTime.UTC().Add(-keep)
+
+			summaries, err := e.queryExpiredJobs(ctx, p, state, maxTime)
+			if err != nil {
+				return err
+			}
+			if err := e.applyRetention(ctx, p, maxTime, summaries); err != nil {
+				return err
+			}
+		}
+	}
+
+	e.metrics.RetentionRun.Inc(1)
+	e.metrics.RetentionRunDuration.Record(time.Since(startTime))
+	return nil
+}
+
+// queryExpiredJobs returns the jobs governed by the policy which
+// completed in the state before maxTime, up to the maximum number
+// of jobs archived per run
+func (e *engine) queryExpiredJobs(
+	ctx context.Context,
+	p *retention.Policy,
+	state job.JobState,
+	maxTime time.Time,
+) ([]*job.JobSummary, error) {
+	min, err := ptypes.TimestampProto(time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	max, err := ptypes.TimestampProto(maxTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// narrow the query with the policy labels, the policy
+	// is then matched with each job returned
+	var labels []*peloton.Label
+	for key, value := range p.Labels() {
+		labels = append(labels, &peloton.Label{Key: key, Value: value})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetKey() < labels[j].GetKey()
+	})
+
+	limit := uint32(e.config.Archiver.MaxArchiveEntries)
+	spec := &job.QuerySpec{
+		JobStates:           []job.JobState{state},
+		Labels:              labels,
+		CompletionTimeRange: &peloton.TimeRange{Min: min, Max: max},
+		Pagination: &query.PaginationSpec{
+			Offset:   0,
+			Limit:    limit,
+			MaxLimit: e.config.Archiver.Retention.MaxScanEntries,
+		},
+	}
+
+	var expired []*job.JobSummary
+	for {
+		resp, err := e.queryJobs(
+			ctx,
+			&job.QueryRequest{Spec: spec, SummaryOnly: true},
+			backoff.NewRetrier(e.retryPolicy))
+		if err != nil {
+			return nil, err
+		}
+
+		results := resp.GetResults()
+		for _, summary := range results {
+			// skip the jobs governed by a policy of higher precedence
+			if e.policyFor(summary) != p {
+				continue
+			}
+			expired = append(expired, summary)
+			if len(expired) >= int(limit) {
+				return expired, nil
+			}
+		}
+
+		spec.Pagination.Offset += uint32(len(results))
+		if len(results) < int(limit) ||
+			spec.Pagination.Offset >= spec.Pagination.MaxLimit {
+			return expired, nil
+		}
+	}
+}
+
+// applyRetention archives the expired jobs of the policy to the sinks
+// and deletes them, or only reports them in dry run mode
+func (e *engine) applyRetention(
+	ctx context.Context,
+	p *retention.Policy,
+	maxTime time.Time,
+	summaries []*job.JobSummary,
+) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	dryRun := e.config.Archiver.Retention.DryRun
+	if !dryRun {
+		if err := e.writeSinks(ctx, time.Unix(0, 0).UTC(), maxTime, summaries); err != nil {
+			return err
+		}
+	}
+
+	for _, summary := range summaries {
+		action := &retention.JobAction{
+			Policy:         p.Name,
+			JobID:          summary.GetId().GetValue(),
+			Name:           summary.GetName(),
+			Type:           summary.GetType().String(),
+			State:          summary.GetRuntime().GetState().String(),
+			CompletionTime: summary.GetRuntime().GetCompletionTime(),
+		}
+		e.report.Jobs = append(e.report.Jobs, action)
+
+		if dryRun {
+			e.metrics.RetentionJobDeleteDryRun.Inc(1)
+			continue
+		}
+
+		// Sleep between consecutive Job Delete requests
+		time.Sleep(delayDelete)
+
+		log.WithFields(log.Fields{
+			"job_id": summary.GetId().GetValue(),
+			"state":  summary.GetRuntime().GetState(),
+			"policy": p.Name}).
+			Info("Deleting job by retention policy")
+
+		deleteCtx, cancel := context.WithTimeout(
+			ctx,
+			e.config.Archiver.PelotonClientTimeout,
+		)
+		_, err := e.jobClient.Delete(deleteCtx, &job.DeleteRequest{Id: summary.GetId()})
+		cancel()
+		if err != nil {
+			log.WithError(err).
+				WithField("job_id", summary.GetId().GetValue()).
+				WithField("policy", p.Name).
+				Error("job delete failed")
+			action.Error = err.Error()
+			e.metrics.RetentionJobDeleteFail.Inc(1)
+			continue
+		}
+		e.metrics.RetentionJobDeleteSuccess.Inc(1)
+	}
+	return nil
+}
+
+// publishReport publishes the retention report of the run
+func (e *engine) publishReport() {
+	if e.report == nil {
+		return
+	}
+	if err := e.reporter.Publish(e.report); err != nil {
+		log.WithError(err).
+			WithField("path", e.config.Archiver.Retention.ReportPath).
+			Error("failed to write retention report")
+	}
+	e.report = nil
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respool_mocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/archiver/config"
+	"github.com/uber/peloton/pkg/archiver/retention"
+
+	"github.com/golang/mock/gomock"
+	"github.com/uber-go/tally"
+)
+
+// newRetentionEngine returns an engine with retention policies for
+// failed batch jobs of the /infra resource pool subtree, and for all
+// batch jobs
+func (suite *archiverEngineTestSuite) newRetentionEngine(
+	dryRun bool,
+) (*engine, *respool_mocks.MockResourceManagerYARPCClient) {
+	policies, err := retention.NewPolicies([]retention.PolicyConfig{
+		{
+			Name:         "infra",
+			RespoolPath:  "/infra",
+			JobType:      "BATCH",
+			KeepFailed:   720 * time.Hour,
+			PodEventRuns: 20,
+		},
+		{
+			Name:          "batch",
+			JobType:       "BATCH",
+			KeepSucceeded: 48 * time.Hour,
+			KeepFailed:    24 * time.Hour,
+		},
+	})
+	suite.NoError(err)
+
+	respoolClient := respool_mocks.NewMockResourceManagerYARPCClient(suite.mockCtrl)
+	return &engine{
+		jobClient:     suite.mockJobClient,
+		taskClient:    suite.mockTaskClient,
+		respoolClient: respoolClient,
+		config: config.Config{
+			Archiver: config.ArchiverConfig{
+				MaxArchiveEntries:    10,
+				PelotonClientTimeout: time.Second,
+				Retention: retention.Config{
+					DryRun:         dryRun,
+					MaxScanEntries: 100,
+				},
+			},
+		},
+		metrics:     NewMetrics(tally.NoopScope),
+		retryPolicy: suite.retryPolicy,
+		policies:    policies,
+		reporter:    retention.NewReporter(""),
+		report:      retention.NewReport(time.Now(), dryRun),
+	}, respoolClient
+}
+
+// retentionJobs returns failed jobs of the /infra and /other
+// resource pools, and a succeeded job of the /other resource pool
+func retentionJobs() (*job.JobSummary, *job.JobSummary, *job.JobSummary) {
+	infraFailed := &job.JobSummary{
+		Id:        &peloton.JobID{Value: "infra-failed"},
+		Type:      job.JobType_BATCH,
+		RespoolID: &peloton.ResourcePoolID{Value: "respool-infra"},
+		Runtime:   &job.RuntimeInfo{State: job.JobState_FAILED},
+	}
+	otherFailed := &job.JobSummary{
+		Id:        &peloton.JobID{Value: "other-failed"},
+		Type:      job.JobType_BATCH,
+		RespoolID: &peloton.ResourcePoolID{Value: "respool-other"},
+		Runtime:   &job.RuntimeInfo{State: job.JobState_FAILED},
+	}
+	otherSucceeded := &job.JobSummary{
+		Id:        &peloton.JobID{Value: "other-succeeded"},
+		Type:      job.JobType_BATCH,
+		RespoolID: &peloton.ResourcePoolID{Value: "respool-other"},
+		Runtime:   &job.RuntimeInfo{State: job.JobState_SUCCEEDED},
+	}
+	return infraFailed, otherFailed, otherSucceeded
+}
+
+// expectRespoolQuery expects the resource pool paths to be queried
+func expectRespoolQuery(client *respool_mocks.MockResourceManagerYARPCClient) {
+	client.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(&respool.QueryResponse{
+			ResourcePools: []*respool.ResourcePoolInfo{
+				{
+					Id:   &peloton.ResourcePoolID{Value: "respool-infra"},
+					Path: &respool.ResourcePoolPath{Value: "/infra"},
+				},
+				{
+					Id:   &peloton.ResourcePoolID{Value: "respool-other"},
+					Path: &respool.ResourcePoolPath{Value: "/other"},
+				},
+			},
+		}, nil)
+}
+
+// expectExpiredJobsQuery expects a query of the jobs in the state
+// which completed before the retention, and returns the results
+func (suite *archiverEngineTestSuite) expectExpiredJobsQuery(
+	state job.JobState,
+	keep time.Duration,
+	results ...*job.JobSummary) *gomock.Call {
+	return suite.mockJobClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *job.QueryRequest) {
+			suite.Equal([]job.JobState{state}, req.GetSpec().GetJobStates())
+			max := time.Unix(req.GetSpec().GetCompletionTimeRange().GetMax().GetSeconds(), 0)
+			suite.WithinDuration(time.Now().Add(-keep), max, time.Minute)
+		}).
+		Return(&job.QueryResponse{Results: results}, nil)
+}
+
+// TestRunRetention tests deleting the expired jobs of each
+// policy, by order of precedence of the policies
+func (suite *archiverEngineTestSuite) TestRunRetention() {
+	e, respoolClient := suite.newRetentionEngine(false)
+	infraFailed, otherFailed, otherSucceeded := retentionJobs()
+
+	gomock.InOrder(
+		expectRespoolQuery(respoolClient),
+		suite.expectExpiredJobsQuery(
+			job.JobState_FAILED, 720*time.Hour, infraFailed, otherFailed),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: infraFailed.GetId()}).
+			Return(&job.DeleteResponse{}, nil),
+		suite.expectExpiredJobsQuery(
+			job.JobState_SUCCEEDED, 48*time.Hour, otherSucceeded),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: otherSucceeded.GetId()}).
+			Return(nil, fmt.Errorf("Job Delete failed")),
+		suite.expectExpiredJobsQuery(
+			job.JobState_FAILED, 24*time.Hour, infraFailed, otherFailed),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: otherFailed.GetId()}).
+			Return(&job.DeleteResponse{}, nil),
+	)
+
+	suite.NoError(e.runRetention(context.Background()))
+
+	suite.Len(e.report.Jobs, 3)
+	suite.Equal("infra", e.report.Jobs[0].Policy)
+	suite.Equal("infra-failed", e.report.Jobs[0].JobID)
+	suite.Equal("batch", e.report.Jobs[1].Policy)
+	suite.Equal("Job Delete failed", e.report.Jobs[1].Error)
+	suite.Equal("other-failed", e.report.Jobs[2].JobID)
+
+	// jobs governed by a policy are not archived by the global config
+	suite.NoError(e.archiveJobs(
+		context.Background(),
+		time.Time{},
+		time.Time{},
+		[]*job.JobSummary{infraFailed, otherSucceeded}))
+
+	e.publishReport()
+	suite.Nil(e.report)
+	suite.NotNil(e.reporter.Last())
+}
+
+// TestRunRetentionDryRun tests that expired jobs are only
+// reported in dry run mode
+func (suite *archiverEngineTestSuite) TestRunRetentionDryRun() {
+	e, respoolClient := suite.newRetentionEngine(true)
+	infraFailed, otherFailed, otherSucceeded := retentionJobs()
+
+	gomock.InOrder(
+		expectRespoolQuery(respoolClient),
+		suite.expectExpiredJobsQuery(
+			job.JobState_FAILED, 720*time.Hour, infraFailed),
+		suite.expectExpiredJobsQuery(
+			job.JobState_SUCCEEDED, 48*time.Hour, otherSucceeded),
+		suite.expectExpiredJobsQuery(
+			job.JobState_FAILED, 24*time.Hour, otherFailed),
+	)
+
+	suite.NoError(e.runRetention(context.Background()))
+	suite.True(e.report.DryRun)
+	suite.Len(e.report.Jobs, 3)
+
+	e.publishReport()
+	recorder := httptest.NewRecorder()
+	retentionReportHandler(e.reporter)(
+		recorder,
+		httptest.NewRequest(nethttp.MethodGet, RetentionReportPath, nil))
+	suite.Equal(nethttp.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), "other-succeeded")
+}
+
+// TestRunRetentionRespoolQueryFailure tests that the run fails
+// if the resource pool paths cannot be read
+func (suite *archiverEngineTestSuite) TestRunRetentionRespoolQueryFailure() {
+	e, respoolClient := suite.newRetentionEngine(false)
+	respoolClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("resmgr unavailable"))
+	suite.Error(e.runRetention(context.Background()))
+}
+
+// TestDeletePodEventsRetentionPolicy tests constraining the pod events
+// of a batch job to the number of runs of its retention policy
+func (suite *archiverEngineTestSuite) TestDeletePodEventsRetentionPolicy() {
+	for _, dryRun := range []bool{false, true} {
+		e, _ := suite.newRetentionEngine(dryRun)
+		e.respoolPaths = map[string]string{"respool-infra": "/infra"}
+
+		jobID := &peloton.JobID{Value: "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06"}
+		taskID := "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-25"
+		summary := &job.JobSummary{
+			Id:            jobID,
+			Type:          job.JobType_BATCH,
+			InstanceCount: 1,
+			RespoolID:     &peloton.ResourcePoolID{Value: "respool-infra"},
+		}
+
+		suite.mockTaskClient.EXPECT().
+			GetPodEvents(gomock.Any(), gomock.Any()).
+			Return(&task.GetPodEventsResponse{
+				Result: []*task.PodEvent{
+					{TaskId: &mesos.TaskID{Value: &taskID}},
+				},
+			}, nil)
+		if !dryRun {
+			suite.mockTaskClient.EXPECT().
+				DeletePodEvents(gomock.Any(), &task.DeletePodEventsRequest{
+					JobId:      jobID,
+					InstanceId: 0,
+					RunId:      5,
+				}).
+				Return(&task.DeletePodEventsResponse{}, nil)
+		}
+
+		suite.NoError(e.deletePodEvents(
+			context.Background(),
+			[]*job.JobSummary{summary}))
+		suite.Equal([]*retention.PodEventsAction{
+			{
+				Policy:     "infra",
+				JobID:      jobID.GetValue(),
+				InstanceID: 0,
+				RunID:      5,
+			},
+		}, e.report.PodEvents)
+	}
+}
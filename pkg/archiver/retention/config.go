@@ -0,0 +1,78 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"time"
+)
+
+const (
+	// default number of jobs scanned by the job query of a policy
+	_defaultMaxScanEntries = 50000
+)
+
+// Config is the configuration of the archiver retention policies
+type Config struct {
+	// Policies in order of precedence. A job is retained according to
+	// the first policy it matches, and jobs which do not match any
+	// policy are retained according to the global archiver config.
+	Policies []PolicyConfig `yaml:"policies"`
+
+	// Report the jobs and pod events to be deleted by the policies
+	// without deleting them
+	DryRun bool `yaml:"dry_run"`
+
+	// Path of the file the report of the last run is written to.
+	// The report is only logged if not set.
+	ReportPath string `yaml:"report_path"`
+
+	// Maximum number of jobs scanned by the job query of a policy
+	MaxScanEntries uint32 `yaml:"max_scan_entries"`
+}
+
+// PolicyConfig is the configuration of a retention policy. A policy
+// matches the jobs in the resource pool subtree, of the job type and
+// with all the labels set in the policy.
+type PolicyConfig struct {
+	// Name of the policy, used in the reports
+	Name string `yaml:"name"`
+
+	// Path of the resource pool, the policy applies to the jobs
+	// of the resource pool and its descendants
+	RespoolPath string `yaml:"respool_path"`
+
+	// Job type, BATCH or SERVICE
+	JobType string `yaml:"job_type"`
+
+	// Labels of the jobs
+	Labels map[string]string `yaml:"labels"`
+
+	// Time to retain the jobs after they complete, by terminal state.
+	// Jobs in a terminal state without retention are never deleted.
+	KeepSucceeded time.Duration `yaml:"keep_succeeded"`
+	KeepFailed    time.Duration `yaml:"keep_failed"`
+	KeepKilled    time.Duration `yaml:"keep_killed"`
+
+	// Number of runs of pod events retained per instance of running
+	// jobs. Defaults to the global archiver limit.
+	PodEventRuns uint64 `yaml:"pod_event_runs"`
+}
+
+// Normalize configuration by setting unassigned fields to default values.
+func (c *Config) Normalize() {
+	if c.MaxScanEntries == 0 {
+		c.MaxScanEntries = _defaultMaxScanEntries
+	}
+}
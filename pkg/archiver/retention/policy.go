@@ -0,0 +1,174 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+)
+
+// Policy is a validated retention policy
+type Policy struct {
+	Name string
+
+	respoolPath string
+	jobType     *job.JobType
+	labels      map[string]string
+
+	// retention of the jobs by terminal state
+	keep map[job.JobState]time.Duration
+
+	// PodEventRuns is the number of runs of pod events retained
+	// per instance, 0 if the policy does not set it
+	PodEventRuns uint64
+}
+
+// NewPolicies validates the policy configs and returns the policies
+// in the same order
+func NewPolicies(cfgs []PolicyConfig) ([]*Policy, error) {
+	names := make(map[string]struct{})
+	var policies []*Policy
+	for _, cfg := range cfgs {
+		if len(cfg.Name) == 0 {
+			return nil, fmt.Errorf("retention policy without name")
+		}
+		if _, ok := names[cfg.Name]; ok {
+			return nil, fmt.Errorf("duplicate retention policy %s", cfg.Name)
+		}
+		names[cfg.Name] = struct{}{}
+
+		p := &Policy{
+			Name:         cfg.Name,
+			respoolPath:  strings.TrimSuffix(cfg.RespoolPath, "/"),
+			labels:       cfg.Labels,
+			keep:         make(map[job.JobState]time.Duration),
+			PodEventRuns: cfg.PodEventRuns,
+		}
+
+		if len(cfg.JobType) > 0 {
+			t, ok := job.JobType_value[strings.ToUpper(cfg.JobType)]
+			if !ok {
+				return nil, fmt.Errorf("retention policy %s has invalid job type %s",
+					cfg.Name, cfg.JobType)
+			}
+			jobType := job.JobType(t)
+			p.jobType = &jobType
+		}
+
+		for state, keep := range map[job.JobState]time.Duration{
+			job.JobState_SUCCEEDED: cfg.KeepSucceeded,
+			job.JobState_FAILED:    cfg.KeepFailed,
+			job.JobState_KILLED:    cfg.KeepKilled,
+		} {
+			if keep < 0 {
+				return nil, fmt.Errorf("retention policy %s has negative retention for %s",
+					cfg.Name, state)
+			}
+			if keep > 0 {
+				p.keep[state] = keep
+			}
+		}
+
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// UsesRespools returns true if any of the policies matches jobs
+// by resource pool
+func UsesRespools(policies []*Policy) bool {
+	for _, p := range policies {
+		if len(p.respoolPath) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the first policy matching the job, nil if none. The
+// respool paths map the resource pool IDs to their paths.
+func Match(
+	policies []*Policy,
+	summary *job.JobSummary,
+	respoolPaths map[string]string,
+) *Policy {
+	for _, p := range policies {
+		if p.matches(summary, respoolPaths) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Retention returns the time jobs completed in the state are retained
+// for, and false if the policy does not delete jobs in the state
+func (p *Policy) Retention(state job.JobState) (time.Duration, bool) {
+	keep, ok := p.keep[state]
+	return keep, ok
+}
+
+// States returns the terminal states of the jobs deleted by the policy
+func (p *Policy) States() []job.JobState {
+	var states []job.JobState
+	for state := range p.keep {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+	return states
+}
+
+// Labels returns the labels of the jobs matched by the policy
+func (p *Policy) Labels() map[string]string {
+	return p.labels
+}
+
+// matches returns true if the job matches all the selectors of the policy
+func (p *Policy) matches(
+	summary *job.JobSummary,
+	respoolPaths map[string]string,
+) bool {
+	if p.jobType != nil && summary.GetType() != *p.jobType {
+		return false
+	}
+
+	if len(p.respoolPath) > 0 {
+		path, ok := respoolPaths[summary.GetRespoolID().GetValue()]
+		if !ok {
+			return false
+		}
+		if path != p.respoolPath &&
+			!strings.HasPrefix(path, p.respoolPath+"/") {
+			return false
+		}
+	}
+
+	for key, value := range p.labels {
+		found := false
+		for _, l := range summary.GetLabels() {
+			if l.GetKey() == key && l.GetValue() == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
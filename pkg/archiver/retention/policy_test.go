@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type policyTestSuite struct {
+	suite.Suite
+
+	policies     []*Policy
+	respoolPaths map[string]string
+}
+
+func (suite *policyTestSuite) SetupTest() {
+	policies, err := NewPolicies([]PolicyConfig{
+		{
+			Name:        "infra-batch",
+			RespoolPath: "/infra/",
+			JobType:     "batch",
+			KeepFailed:  720 * time.Hour,
+		},
+		{
+			Name:          "spark",
+			Labels:        map[string]string{"framework": "spark"},
+			KeepSucceeded: 48 * time.Hour,
+			PodEventRuns:  20,
+		},
+		{
+			Name:          "batch",
+			JobType:       "BATCH",
+			KeepSucceeded: 48 * time.Hour,
+			KeepFailed:    720 * time.Hour,
+			KeepKilled:    24 * time.Hour,
+		},
+	})
+	suite.NoError(err)
+	suite.policies = policies
+	suite.respoolPaths = map[string]string{
+		"respool-infra":         "/infra",
+		"respool-infra-compute": "/infra/compute",
+		"respool-infrastaging":  "/infrastaging",
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	suite.Run(t, new(policyTestSuite))
+}
+
+func (suite *policyTestSuite) match(summary *job.JobSummary) string {
+	p := Match(suite.policies, summary, suite.respoolPaths)
+	if p == nil {
+		return ""
+	}
+	return p.Name
+}
+
+// TestMatch tests matching jobs with the first matching policy
+func (suite *policyTestSuite) TestMatch() {
+	sparkLabels := []*peloton.Label{{Key: "framework", Value: "spark"}}
+
+	testCases := map[string]struct {
+		summary *job.JobSummary
+		policy  string
+	}{
+		"respool": {
+			summary: &job.JobSummary{
+				Type:      job.JobType_BATCH,
+				RespoolID: &peloton.ResourcePoolID{Value: "respool-infra"},
+			},
+			policy: "infra-batch",
+		},
+		"respool-descendant": {
+			summary: &job.JobSummary{
+				Type:      job.JobType_BATCH,
+				RespoolID: &peloton.ResourcePoolID{Value: "respool-infra-compute"},
+				Labels:    sparkLabels,
+			},
+			policy: "infra-batch",
+		},
+		"respool-sibling-prefix": {
+			summary: &job.JobSummary{
+				Type:      job.JobType_BATCH,
+				RespoolID: &peloton.ResourcePoolID{Value: "respool-infrastaging"},
+			},
+			policy: "batch",
+		},
+		"labels": {
+			summary: &job.JobSummary{
+				Type:   job.JobType_SERVICE,
+				Labels: sparkLabels,
+			},
+			policy: "spark",
+		},
+		"labels-mismatch": {
+			summary: &job.JobSummary{
+				Type: job.JobType_SERVICE,
+				Labels: []*peloton.Label{
+					{Key: "framework", Value: "flink"},
+				},
+			},
+			policy: "",
+		},
+		"unknown-respool": {
+			summary: &job.JobSummary{
+				Type:      job.JobType_BATCH,
+				RespoolID: &peloton.ResourcePoolID{Value: "respool-unknown"},
+			},
+			policy: "batch",
+		},
+	}
+
+	for name, tc := range testCases {
+		suite.Equal(tc.policy, suite.match(tc.summary), name)
+	}
+}
+
+// TestRetention tests the retention of the jobs by state
+func (suite *policyTestSuite) TestRetention() {
+	p := suite.policies[0]
+	suite.Equal([]job.JobState{job.JobState_FAILED}, p.States())
+
+	keep, ok := p.Retention(job.JobState_FAILED)
+	suite.True(ok)
+	suite.Equal(720*time.Hour, keep)
+
+	_, ok = p.Retention(job.JobState_SUCCEEDED)
+	suite.False(ok)
+
+	suite.Equal([]job.JobState{
+		job.JobState_SUCCEEDED,
+		job.JobState_FAILED,
+		job.JobState_KILLED,
+	}, suite.policies[2].States())
+
+	suite.True(UsesRespools(suite.policies))
+	suite.False(UsesRespools(suite.policies[1:]))
+}
+
+// TestNewPoliciesInvalid tests validating the policy configs
+func (suite *policyTestSuite) TestNewPoliciesInvalid() {
+	for name, cfgs := range map[string][]PolicyConfig{
+		"no-name": {
+			{KeepFailed: time.Hour},
+		},
+		"duplicate-name": {
+			{Name: "batch", KeepFailed: time.Hour},
+			{Name: "batch", KeepKilled: time.Hour},
+		},
+		"invalid-job-type": {
+			{Name: "batch", JobType: "CRON"},
+		},
+		"negative-retention": {
+			{Name: "batch", KeepSucceeded: -time.Hour},
+		},
+	} {
+		_, err := NewPolicies(cfgs)
+		suite.Error(err, name)
+	}
+}
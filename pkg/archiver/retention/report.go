@@ -0,0 +1,133 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// JobAction is a job deleted, or to be deleted in dry run, by a policy
+type JobAction struct {
+	Policy         string `json:"policy"`
+	JobID          string `json:"job_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	State          string `json:"state"`
+	CompletionTime string `json:"completion_time"`
+	Error          string `json:"error,omitempty"`
+}
+
+// PodEventsAction is a deletion of the runs of pod events of an
+// instance, up to and including RunID, by a policy
+type PodEventsAction struct {
+	Policy     string `json:"policy"`
+	JobID      string `json:"job_id"`
+	InstanceID uint32 `json:"instance_id"`
+	RunID      uint64 `json:"run_id"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report lists the actions of the retention policies in an archiver run
+type Report struct {
+	Time      time.Time          `json:"time"`
+	DryRun    bool               `json:"dry_run"`
+	Jobs      []*JobAction       `json:"jobs"`
+	PodEvents []*PodEventsAction `json:"pod_events"`
+}
+
+// NewReport returns an empty report
+func NewReport(now time.Time, dryRun bool) *Report {
+	return &Report{
+		Time:      now,
+		DryRun:    dryRun,
+		Jobs:      []*JobAction{},
+		PodEvents: []*PodEventsAction{},
+	}
+}
+
+// Reporter publishes the report of the last archiver run
+type Reporter struct {
+	sync.RWMutex
+
+	path string
+	last []byte
+}
+
+// NewReporter returns a reporter writing the reports to path,
+// if not empty
+func NewReporter(path string) *Reporter {
+	return &Reporter{path: path}
+}
+
+// Publish logs the summary of the report, writes it to the report
+// file and keeps it as the last report
+func (r *Reporter) Publish(report *Report) error {
+	buf, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, a := range report.Jobs {
+		counts[a.Policy]++
+	}
+	log.WithFields(log.Fields{
+		"dry_run":        report.DryRun,
+		"jobs":           len(report.Jobs),
+		"pod_events":     len(report.PodEvents),
+		"jobs_by_policy": counts,
+	}).Info("Retention report")
+
+	r.Lock()
+	r.last = buf
+	r.Unlock()
+
+	if len(r.path) == 0 {
+		return nil
+	}
+	return writeFile(r.path, buf)
+}
+
+// Last returns the last published report as JSON, nil if none
+func (r *Reporter) Last() []byte {
+	r.RLock()
+	defer r.RUnlock()
+	return r.last
+}
+
+// writeFile atomically replaces the file at path
+func writeFile(path string, buf []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
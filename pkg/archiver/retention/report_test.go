@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReporterPublish tests publishing a retention report
+func TestReporterPublish(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention-report")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "report.json")
+	reporter := NewReporter(path)
+	assert.Nil(t, reporter.Last())
+
+	report := NewReport(time.Now().UTC(), true)
+	report.Jobs = append(report.Jobs, &JobAction{
+		Policy: "batch",
+		JobID:  "job-0",
+		State:  "FAILED",
+	})
+	report.PodEvents = append(report.PodEvents, &PodEventsAction{
+		Policy:     "batch",
+		JobID:      "job-1",
+		InstanceID: 2,
+		RunID:      5,
+	})
+	assert.NoError(t, reporter.Publish(report))
+
+	buf, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, reporter.Last(), buf)
+
+	published := &Report{}
+	assert.NoError(t, json.Unmarshal(buf, published))
+	assert.True(t, published.DryRun)
+	assert.Equal(t, report.Jobs, published.Jobs)
+	assert.Equal(t, report.PodEvents, published.PodEvents)
+}
+
+// TestReporterPublishWithoutPath tests that the last report is kept
+// when no report path is configured
+func TestReporterPublishWithoutPath(t *testing.T) {
+	reporter := NewReporter("")
+	assert.NoError(t, reporter.Publish(NewReport(time.Now(), false)))
+	assert.NotNil(t, reporter.Last())
+}
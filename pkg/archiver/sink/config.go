@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"time"
+)
+
+// Type is the type of an archiver sink
+type Type string
+
+const (
+	// FilebeatSink logs the job summaries to be shipped to Kafka by filebeat
+	FilebeatSink Type = "filebeat"
+	// FileSink writes the jobs to rotating local JSON lines files
+	FileSink Type = "file"
+	// ObjectStoreSink uploads the jobs as JSON lines objects
+	// to an S3 compatible object store
+	ObjectStoreSink Type = "object_store"
+	// ParquetSink writes the job configs, runtimes and pod
+	// events to Parquet files
+	ParquetSink Type = "parquet"
+)
+
+const (
+	// _timeFormat is the format of the time range in the batch names
+	_timeFormat = "20060102T150405Z"
+	// default maximum size of a local JSON lines file
+	_defaultMaxFileSize = 100 * 1024 * 1024
+	// default region of the object store
+	_defaultRegion = "us-east-1"
+	// default timeout to upload a batch to the object store
+	_defaultUploadTimeout = 60 * time.Second
+)
+
+// Config is the configuration of an archiver sink
+type Config struct {
+	// Type of the sink
+	Type Type `yaml:"type"`
+
+	Filebeat    FilebeatConfig    `yaml:"filebeat"`
+	File        FileConfig        `yaml:"file"`
+	ObjectStore ObjectStoreConfig `yaml:"object_store"`
+	Parquet     ParquetConfig     `yaml:"parquet"`
+}
+
+// FilebeatConfig is the configuration of the filebeat sink
+type FilebeatConfig struct {
+	// Kafka topic that filebeat streams the completed jobs to
+	Topic string `yaml:"topic"`
+}
+
+// FileConfig is the configuration of the local file sink
+type FileConfig struct {
+	// Directory of the JSON lines files
+	Directory string `yaml:"directory"`
+
+	// Size in bytes after which the sink rotates to a new file
+	MaxFileSize int64 `yaml:"max_file_size"`
+
+	// Maximum number of files to keep, the oldest files are removed
+	// after rotation. All files are kept if not set.
+	MaxFiles int `yaml:"max_files"`
+}
+
+// ObjectStoreConfig is the configuration of the object store sink
+type ObjectStoreConfig struct {
+	// Endpoint of the S3 compatible API, ex: https://s3.us-west-2.amazonaws.com
+	Endpoint string `yaml:"endpoint"`
+
+	// Bucket to upload the objects to
+	Bucket string `yaml:"bucket"`
+
+	// Prefix of the object keys
+	Prefix string `yaml:"prefix"`
+
+	// Region used to sign the requests
+	Region string `yaml:"region"`
+
+	// Credentials used to sign the requests
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+
+	// Timeout to upload a batch
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ParquetConfig is the configuration of the Parquet sink
+type ParquetConfig struct {
+	// Directory of the Parquet files
+	Directory string `yaml:"directory"`
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	_filePrefix = "archive-"
+	_fileSuffix = ".jsonl"
+)
+
+// fileSink appends the jobs to JSON lines files in a local directory,
+// rotating to a new file once the current one is larger than the
+// maximum file size
+type fileSink struct {
+	sync.Mutex
+
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	file      *os.File
+	size      int64
+	marshaler *jsonpb.Marshaler
+}
+
+// NewFileSink returns a sink writing the jobs to rotating local
+// JSON lines files
+func NewFileSink(cfg *FileConfig) (Sink, error) {
+	if len(cfg.Directory) == 0 {
+		return nil, errors.New("file archiver sink requires a directory")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0750); err != nil {
+		return nil, err
+	}
+
+	maxFileSize := cfg.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = _defaultMaxFileSize
+	}
+	return &fileSink{
+		dir:         cfg.Directory,
+		maxFileSize: maxFileSize,
+		maxFiles:    cfg.MaxFiles,
+		marshaler:   &jsonpb.Marshaler{OrigName: true},
+	}, nil
+}
+
+// Name returns the name of the sink
+func (s *fileSink) Name() string {
+	return string(FileSink)
+}
+
+// Detailed returns true as the file sink archives all the job data
+func (s *fileSink) Detailed() bool {
+	return true
+}
+
+// Write appends the jobs of the batch to the current file, and
+// syncs it to disk
+func (s *fileSink) Write(ctx context.Context, batch *Batch) error {
+	if len(batch.Records) == 0 {
+		return nil
+	}
+
+	buf, err := marshalBatch(s.marshaler, batch)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil || s.size >= s.maxFileSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(buf)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the current file
+func (s *fileSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// rotate closes the current file, opens a new one and
+// removes the oldest files beyond the maximum file count
+func (s *fileSink) rotate() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.file = nil
+	}
+
+	name := fmt.Sprintf("%s%d%s", _filePrefix, time.Now().UnixNano(), _fileSuffix)
+	file, err := os.OpenFile(
+		filepath.Join(s.dir, name),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0640)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.size = 0
+
+	if s.maxFiles > 0 {
+		s.removeOldFiles()
+	}
+	return nil
+}
+
+// removeOldFiles removes the oldest files beyond the maximum file count
+func (s *fileSink) removeOldFiles() {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		log.WithError(err).
+			WithField("directory", s.dir).
+			Warn("failed to list archive files")
+		return
+	}
+
+	var names []string
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name(), _filePrefix) &&
+			strings.HasSuffix(info.Name(), _fileSuffix) {
+			names = append(names, info.Name())
+		}
+	}
+	if len(names) <= s.maxFiles {
+		return
+	}
+
+	// file names embed the creation time with a fixed number of
+	// digits, so the oldest files sort first
+	sort.Strings(names)
+	for _, name := range names[:len(names)-s.maxFiles] {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+			log.WithError(err).
+				WithField("file", name).
+				Warn("failed to remove archive file")
+		}
+	}
+}
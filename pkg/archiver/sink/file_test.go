@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fileSinkTestSuite struct {
+	suite.Suite
+
+	dir string
+}
+
+func (suite *fileSinkTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "archiver-file-sink")
+	suite.NoError(err)
+	suite.dir = dir
+}
+
+func (suite *fileSinkTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestFileSink(t *testing.T) {
+	suite.Run(t, new(fileSinkTestSuite))
+}
+
+// TestNewSink tests creating the configured sinks
+func (suite *fileSinkTestSuite) TestNewSink() {
+	s, err := New(&Config{Type: FilebeatSink})
+	suite.NoError(err)
+	suite.False(s.Detailed())
+
+	s, err = New(&Config{
+		Type: FileSink,
+		File: FileConfig{Directory: suite.dir},
+	})
+	suite.NoError(err)
+	suite.True(s.Detailed())
+	suite.Equal(string(FileSink), s.Name())
+
+	_, err = New(&Config{Type: FileSink})
+	suite.Error(err)
+
+	_, err = New(&Config{Type: "kafka"})
+	suite.Error(err)
+}
+
+// TestFileSinkWrite tests writing jobs as JSON lines
+func (suite *fileSinkTestSuite) TestFileSinkWrite() {
+	s, err := NewFileSink(&FileConfig{Directory: suite.dir})
+	suite.NoError(err)
+	defer s.Close()
+
+	batch := testBatch(2)
+	suite.NoError(s.Write(context.Background(), batch))
+	suite.NoError(s.Write(context.Background(), &Batch{}))
+
+	files := suite.archiveFiles()
+	suite.Len(files, 1)
+
+	records := readRecords(suite.T(), files[0])
+	suite.Len(records, 2)
+	suite.Equal("job-0", records[0].JobID)
+	suite.Equal("job-1", records[1].JobID)
+	suite.NotEmpty(records[0].Config)
+	suite.NotEmpty(records[0].Runtime)
+	suite.Len(records[0].PodEvents, 1)
+}
+
+// TestFileSinkRotate tests rotating and removing old files
+func (suite *fileSinkTestSuite) TestFileSinkRotate() {
+	s, err := NewFileSink(&FileConfig{
+		Directory:   suite.dir,
+		MaxFileSize: 1,
+		MaxFiles:    2,
+	})
+	suite.NoError(err)
+	defer s.Close()
+
+	for i := 0; i < 4; i++ {
+		suite.NoError(s.Write(context.Background(), testBatch(1)))
+	}
+
+	// every write rotates as the files are larger than the maximum size
+	files := suite.archiveFiles()
+	suite.Len(files, 2)
+	for _, f := range files {
+		suite.Len(readRecords(suite.T(), f), 1)
+	}
+}
+
+func (suite *fileSinkTestSuite) archiveFiles() []string {
+	files, err := filepath.Glob(
+		filepath.Join(suite.dir, _filePrefix+"*"+_fileSuffix))
+	suite.NoError(err)
+	return files
+}
+
+// testBatch returns a batch of count jobs
+func testBatch(count int) *Batch {
+	maxTime := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	batch := &Batch{
+		MinTime: maxTime.Add(-24 * time.Hour),
+		MaxTime: maxTime,
+	}
+
+	for i := 0; i < count; i++ {
+		jobID := &peloton.JobID{Value: fmt.Sprintf("job-%d", i)}
+		taskID := jobID.GetValue() + "-0-1"
+		batch.Records = append(batch.Records, &Record{
+			Summary: &job.JobSummary{
+				Id:            jobID,
+				Name:          "test-job",
+				InstanceCount: 1,
+			},
+			Config: &job.JobConfig{
+				Name:          "test-job",
+				OwningTeam:    "compute",
+				InstanceCount: 1,
+				Labels: []*peloton.Label{
+					{Key: "env", Value: "prod"},
+				},
+			},
+			Runtime: &job.RuntimeInfo{
+				State:          job.JobState_SUCCEEDED,
+				CompletionTime: maxTime.Format(time.RFC3339Nano),
+				TaskStats:      map[string]uint32{"SUCCEEDED": 1},
+			},
+			PodEvents: []*task.PodEvent{
+				{
+					TaskId:      &mesos.TaskID{Value: &taskID},
+					ActualState: task.TaskState_SUCCEEDED.String(),
+					Hostname:    "host-0",
+				},
+			},
+		})
+	}
+	return batch
+}
+
+// readRecords reads the JSON lines records of a file
+func readRecords(t *testing.T, path string) []*jsonRecord {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseRecords(t, buf)
+}
+
+// parseRecords parses JSON lines records
+func parseRecords(t *testing.T, buf []byte) []*jsonRecord {
+	var records []*jsonRecord
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		r := &jsonRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The string "completed_job" will be used to tag the logs that contain
+	// job summary. This will be used by logstash and streamed using a heatpipe
+	// kafka topic to Hive table
+	completedJobTag = "completed_job"
+
+	// The key "filebeat_topic" will be used by filebeat to stream completed
+	// jobs to kafka topic specified
+	filebeatTopic = "filebeat_topic"
+)
+
+// filebeatSink logs the job summaries to archiver stdout
+type filebeatSink struct {
+	topic string
+}
+
+// NewFilebeatSink returns a sink which logs the job summaries, tagged
+// with the Kafka topic. Filebeat configured on the Peloton host ships
+// these logs out to logstash, which streams them to Hive via a heatpipe
+// topic.
+func NewFilebeatSink(topic string) Sink {
+	return &filebeatSink{topic: topic}
+}
+
+// Name returns the name of the sink
+func (s *filebeatSink) Name() string {
+	return string(FilebeatSink)
+}
+
+// Detailed returns false as only the job summaries are logged
+func (s *filebeatSink) Detailed() bool {
+	return false
+}
+
+// Write logs the job summaries of the batch
+func (s *filebeatSink) Write(ctx context.Context, batch *Batch) error {
+	for _, r := range batch.Records {
+		log.WithFields(log.Fields{
+			filebeatTopic:   s.topic,
+			completedJobTag: r.Summary,
+		}).Info("completed job")
+	}
+	return nil
+}
+
+// Close is a no-op for the filebeat sink
+func (s *filebeatSink) Close() error {
+	return nil
+}
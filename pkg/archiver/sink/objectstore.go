@@ -0,0 +1,212 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+)
+
+const (
+	_signAlgorithm  = "AWS4-HMAC-SHA256"
+	_signService    = "s3"
+	_signDateFormat = "20060102"
+	_signTimeFormat = "20060102T150405Z"
+
+	_amzDateHeader          = "X-Amz-Date"
+	_amzContentSHA256Header = "X-Amz-Content-Sha256"
+)
+
+// objectStoreSink uploads each batch as a JSON lines object to
+// a bucket of an S3 compatible object store
+type objectStoreSink struct {
+	cfg       ObjectStoreConfig
+	endpoint  *url.URL
+	client    *http.Client
+	marshaler *jsonpb.Marshaler
+	now       func() time.Time
+}
+
+// NewObjectStoreSink returns a sink uploading the jobs to an
+// S3 compatible object store
+func NewObjectStoreSink(cfg *ObjectStoreConfig) (Sink, error) {
+	if len(cfg.Endpoint) == 0 || len(cfg.Bucket) == 0 {
+		return nil, errors.New(
+			"object store archiver sink requires an endpoint and a bucket")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *cfg
+	if len(c.Region) == 0 {
+		c.Region = _defaultRegion
+	}
+	if c.Timeout == 0 {
+		c.Timeout = _defaultUploadTimeout
+	}
+	return &objectStoreSink{
+		cfg:       c,
+		endpoint:  endpoint,
+		client:    &http.Client{Timeout: c.Timeout},
+		marshaler: &jsonpb.Marshaler{OrigName: true},
+		now:       time.Now,
+	}, nil
+}
+
+// Name returns the name of the sink
+func (s *objectStoreSink) Name() string {
+	return string(ObjectStoreSink)
+}
+
+// Detailed returns true as the object store sink archives all the job data
+func (s *objectStoreSink) Detailed() bool {
+	return true
+}
+
+// Write uploads the jobs of the batch as a single object
+func (s *objectStoreSink) Write(ctx context.Context, batch *Batch) error {
+	if len(batch.Records) == 0 {
+		return nil
+	}
+
+	body, err := marshalBatch(s.marshaler, batch)
+	if err != nil {
+		return err
+	}
+	return s.put(ctx, s.objectKey(batch), body)
+}
+
+// Close is a no-op for the object store sink
+func (s *objectStoreSink) Close() error {
+	return nil
+}
+
+// objectKey returns the key of the object for the batch, partitioned
+// by the completion date of the jobs
+func (s *objectStoreSink) objectKey(batch *Batch) string {
+	return path.Join(
+		s.cfg.Prefix,
+		batch.MaxTime.UTC().Format("2006/01/02"),
+		batchName(batch)+_fileSuffix)
+}
+
+// put uploads an object with a request signed with AWS signature v4
+func (s *objectStoreSink) put(ctx context.Context, key string, body []byte) error {
+	u := *s.endpoint
+	u.Path = path.Join("/", u.Path, s.cfg.Bucket, key)
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	signRequest(req, body, &s.cfg, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to upload object %s: %s %s",
+			key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// signRequest signs the request with AWS signature version 4, using
+// the host, content hash and date as signed headers
+func signRequest(
+	req *http.Request,
+	body []byte,
+	cfg *ObjectStoreConfig,
+	now time.Time,
+) {
+	amzDate := now.Format(_signTimeFormat)
+	payloadHash := hashHex(body)
+	req.Header.Set(_amzDateHeader, amzDate)
+	req.Header.Set(_amzContentSHA256Header, payloadHash)
+
+	if len(cfg.AccessKeyID) == 0 {
+		// anonymous access
+		return
+	}
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := now.Format(_signDateFormat)
+	scope := strings.Join(
+		[]string{date, cfg.Region, _signService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		_signAlgorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := signingKey(cfg.SecretAccessKey, date, cfg.Region, _signService)
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		_signAlgorithm, cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// signingKey derives the AWS signature v4 signing key
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
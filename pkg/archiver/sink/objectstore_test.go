@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeObjectStore is a local stand-in for an S3 compatible object store
+type fakeObjectStore struct {
+	sync.Mutex
+
+	objects map[string][]byte
+	headers map[string]http.Header
+	fail    bool
+}
+
+func (f *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.fail {
+		http.Error(w, "SlowDown", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodPut {
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.objects[r.URL.Path] = body
+	f.headers[r.URL.Path] = r.Header
+}
+
+type objectStoreSinkTestSuite struct {
+	suite.Suite
+
+	store  *fakeObjectStore
+	server *httptest.Server
+}
+
+func (suite *objectStoreSinkTestSuite) SetupTest() {
+	suite.store = &fakeObjectStore{
+		objects: make(map[string][]byte),
+		headers: make(map[string]http.Header),
+	}
+	suite.server = httptest.NewServer(suite.store)
+}
+
+func (suite *objectStoreSinkTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func TestObjectStoreSink(t *testing.T) {
+	suite.Run(t, new(objectStoreSinkTestSuite))
+}
+
+func (suite *objectStoreSinkTestSuite) newSink() Sink {
+	s, err := NewObjectStoreSink(&ObjectStoreConfig{
+		Endpoint:        suite.server.URL,
+		Bucket:          "peloton",
+		Prefix:          "archive/jobs",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	})
+	suite.NoError(err)
+	return s
+}
+
+// TestObjectStoreSinkWrite tests uploading a batch as a single object
+func (suite *objectStoreSinkTestSuite) TestObjectStoreSinkWrite() {
+	s := suite.newSink()
+	suite.NoError(s.Write(context.Background(), testBatch(3)))
+	suite.NoError(s.Write(context.Background(), &Batch{}))
+
+	suite.Len(suite.store.objects, 1)
+	for key, body := range suite.store.objects {
+		suite.True(strings.HasPrefix(key, "/peloton/archive/jobs/2019/03/01/"))
+		suite.True(strings.HasSuffix(key, _fileSuffix))
+		suite.Len(parseRecords(suite.T(), body), 3)
+
+		auth := suite.store.headers[key].Get("Authorization")
+		suite.True(strings.HasPrefix(auth,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		suite.Equal(hashHex(body),
+			suite.store.headers[key].Get(_amzContentSHA256Header))
+	}
+}
+
+// TestObjectStoreSinkWriteFailure tests that upload failures are returned
+func (suite *objectStoreSinkTestSuite) TestObjectStoreSinkWriteFailure() {
+	suite.store.fail = true
+	s := suite.newSink()
+	suite.Error(s.Write(context.Background(), testBatch(1)))
+}
+
+// TestNewObjectStoreSinkInvalidConfig tests that the endpoint
+// and bucket are required
+func (suite *objectStoreSinkTestSuite) TestNewObjectStoreSinkInvalidConfig() {
+	_, err := NewObjectStoreSink(&ObjectStoreConfig{Bucket: "peloton"})
+	suite.Error(err)
+	_, err = NewObjectStoreSink(&ObjectStoreConfig{Endpoint: suite.server.URL})
+	suite.Error(err)
+}
+
+// TestSignRequest tests signing a request with AWS signature version 4
+func TestSignRequest(t *testing.T) {
+	body := []byte("hello\n")
+	req, err := http.NewRequest(
+		http.MethodPut,
+		"http://localhost:9000/archive/jobs/x.jsonl",
+		nil)
+	assert.NoError(t, err)
+
+	signRequest(req, body, &ObjectStoreConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, "20190301T000000Z", req.Header.Get(_amzDateHeader))
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 "+
+			"Credential=AKIDEXAMPLE/20190301/us-east-1/s3/aws4_request, "+
+			"SignedHeaders=host;x-amz-content-sha256;x-amz-date, "+
+			"Signature=42ddf74ed9ab74b345f90bc6c94c8a836e841eb3a7589a78c78019c0ce5454b8",
+		req.Header.Get("Authorization"))
+}
+
+// TestSigningKey tests deriving the signing key with the
+// example from the AWS documentation
+func TestSigningKey(t *testing.T) {
+	key := signingKey(
+		"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"20120215",
+		"us-east-1",
+		"iam")
+	assert.Equal(t,
+		"f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d",
+		hex.EncodeToString(key))
+}
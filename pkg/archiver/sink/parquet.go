@@ -0,0 +1,295 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/common/util"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	_parquetSuffix = ".parquet"
+
+	// number of goroutines used to encode the columns of a row group
+	_parquetParallelism = 4
+
+	// tables of the Parquet sink, each written to its own sub-directory
+	_jobConfigsTable  = "job_configs"
+	_jobRuntimesTable = "job_runtimes"
+	_podEventsTable   = "pod_events"
+)
+
+// jobConfigRow is a row of the job configs table
+type jobConfigRow struct {
+	JobID         string `parquet:"name=job_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name          string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Type          string `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OwningTeam    string `parquet:"name=owning_team, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	InstanceCount int32  `parquet:"name=instance_count, type=INT32"`
+	RespoolID     string `parquet:"name=respool_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SLAPriority   int32  `parquet:"name=sla_priority, type=INT32"`
+	Labels        string `parquet:"name=labels, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Config        string `parquet:"name=config, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// jobRuntimeRow is a row of the job runtimes table
+type jobRuntimeRow struct {
+	JobID          string `parquet:"name=job_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	State          string `parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	GoalState      string `parquet:"name=goal_state, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CreationTime   string `parquet:"name=creation_time, type=BYTE_ARRAY, convertedtype=UTF8"`
+	StartTime      string `parquet:"name=start_time, type=BYTE_ARRAY, convertedtype=UTF8"`
+	CompletionTime string `parquet:"name=completion_time, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ConfigVersion  int64  `parquet:"name=config_version, type=INT64"`
+	TaskStats      string `parquet:"name=task_stats, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// podEventRow is a row of the pod events table
+type podEventRow struct {
+	JobID         string `parquet:"name=job_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	InstanceID    int32  `parquet:"name=instance_id, type=INT32"`
+	TaskID        string `parquet:"name=task_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PrevTaskID    string `parquet:"name=prev_task_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ActualState   string `parquet:"name=actual_state, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	GoalState     string `parquet:"name=goal_state, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Timestamp     string `parquet:"name=timestamp, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ConfigVersion int64  `parquet:"name=config_version, type=INT64"`
+	Hostname      string `parquet:"name=hostname, type=BYTE_ARRAY, convertedtype=UTF8"`
+	AgentID       string `parquet:"name=agent_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Message       string `parquet:"name=message, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Reason        string `parquet:"name=reason, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Healthy       string `parquet:"name=healthy, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// parquetSink writes the job configs, runtimes and pod events of each
+// batch to Parquet files, one file per table and batch
+type parquetSink struct {
+	dir       string
+	marshaler *jsonpb.Marshaler
+}
+
+// NewParquetSink returns a sink writing the jobs to Parquet files
+func NewParquetSink(cfg *ParquetConfig) (Sink, error) {
+	if len(cfg.Directory) == 0 {
+		return nil, errors.New("parquet archiver sink requires a directory")
+	}
+	for _, table := range []string{
+		_jobConfigsTable,
+		_jobRuntimesTable,
+		_podEventsTable,
+	} {
+		if err := os.MkdirAll(filepath.Join(cfg.Directory, table), 0750); err != nil {
+			return nil, err
+		}
+	}
+	return &parquetSink{
+		dir:       cfg.Directory,
+		marshaler: &jsonpb.Marshaler{OrigName: true},
+	}, nil
+}
+
+// Name returns the name of the sink
+func (s *parquetSink) Name() string {
+	return string(ParquetSink)
+}
+
+// Detailed returns true as the Parquet sink archives all the job data
+func (s *parquetSink) Detailed() bool {
+	return true
+}
+
+// Write writes the job configs, runtimes and pod events of the batch
+func (s *parquetSink) Write(ctx context.Context, batch *Batch) error {
+	if len(batch.Records) == 0 {
+		return nil
+	}
+
+	var configs, runtimes, events []interface{}
+	for _, r := range batch.Records {
+		jobID := r.Summary.GetId().GetValue()
+
+		if r.Config != nil {
+			row, err := s.jobConfigRow(jobID, r)
+			if err != nil {
+				return err
+			}
+			configs = append(configs, *row)
+		}
+		if r.Runtime != nil {
+			row, err := s.jobRuntimeRow(jobID, r)
+			if err != nil {
+				return err
+			}
+			runtimes = append(runtimes, *row)
+		}
+		for _, event := range r.PodEvents {
+			events = append(events, newPodEventRow(jobID, event))
+		}
+	}
+
+	name := batchName(batch) + _parquetSuffix
+	if err := s.writeTable(_jobConfigsTable, name, new(jobConfigRow), configs); err != nil {
+		return err
+	}
+	if err := s.writeTable(_jobRuntimesTable, name, new(jobRuntimeRow), runtimes); err != nil {
+		return err
+	}
+	return s.writeTable(_podEventsTable, name, new(podEventRow), events)
+}
+
+// Close is a no-op for the Parquet sink, the files are
+// closed at the end of each write
+func (s *parquetSink) Close() error {
+	return nil
+}
+
+// writeTable writes the rows to a Parquet file of the table. The file is
+// written under a temporary name and renamed once complete, so that
+// readers never see a partial file.
+func (s *parquetSink) writeTable(
+	table string,
+	name string,
+	schema interface{},
+	rows []interface{},
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(s.dir, table, name)
+	tmpPath := path + ".tmp"
+
+	fw, err := local.NewLocalFileWriter(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	pw, err := writer.NewParquetWriter(fw, schema, _parquetParallelism)
+	if err != nil {
+		fw.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, row := range rows {
+		if err = pw.Write(row); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = pw.WriteStop()
+	}
+	if closeErr := fw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// jobConfigRow converts the job config of the record to a row
+func (s *parquetSink) jobConfigRow(jobID string, r *Record) (*jobConfigRow, error) {
+	config, err := s.marshaler.MarshalToString(r.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := "{}"
+	if len(r.Config.GetLabels()) > 0 {
+		m := make(map[string]string)
+		for _, l := range r.Config.GetLabels() {
+			m[l.GetKey()] = l.GetValue()
+		}
+		if labels, err = marshalJSONString(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return &jobConfigRow{
+		JobID:         jobID,
+		Name:          r.Config.GetName(),
+		Type:          r.Config.GetType().String(),
+		OwningTeam:    r.Config.GetOwningTeam(),
+		InstanceCount: int32(r.Config.GetInstanceCount()),
+		RespoolID:     r.Config.GetRespoolID().GetValue(),
+		SLAPriority:   int32(r.Config.GetSLA().GetPriority()),
+		Labels:        labels,
+		Config:        config,
+	}, nil
+}
+
+// jobRuntimeRow converts the job runtime of the record to a row
+func (s *parquetSink) jobRuntimeRow(jobID string, r *Record) (*jobRuntimeRow, error) {
+	taskStats, err := marshalJSONString(r.Runtime.GetTaskStats())
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobRuntimeRow{
+		JobID:          jobID,
+		State:          r.Runtime.GetState().String(),
+		GoalState:      r.Runtime.GetGoalState().String(),
+		CreationTime:   r.Runtime.GetCreationTime(),
+		StartTime:      r.Runtime.GetStartTime(),
+		CompletionTime: r.Runtime.GetCompletionTime(),
+		ConfigVersion:  int64(r.Runtime.GetConfigurationVersion()),
+		TaskStats:      taskStats,
+	}, nil
+}
+
+// newPodEventRow converts a pod event of the job to a row
+func newPodEventRow(jobID string, event *task.PodEvent) podEventRow {
+	taskID := event.GetTaskId().GetValue()
+	_, instanceID, _ := util.ParseJobAndInstanceID(taskID)
+
+	return podEventRow{
+		JobID:         jobID,
+		InstanceID:    int32(instanceID),
+		TaskID:        taskID,
+		PrevTaskID:    event.GetPrevTaskId().GetValue(),
+		ActualState:   event.GetActualState(),
+		GoalState:     event.GetGoalState(),
+		Timestamp:     event.GetTimestamp(),
+		ConfigVersion: int64(event.GetConfigVersion()),
+		Hostname:      event.GetHostname(),
+		AgentID:       event.GetAgentID(),
+		Message:       event.GetMessage(),
+		Reason:        event.GetReason(),
+		Healthy:       event.GetHealthy(),
+	}
+}
+
+// marshalJSONString encodes a value as a JSON string
+func marshalJSONString(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+type parquetSinkTestSuite struct {
+	suite.Suite
+
+	dir string
+}
+
+func (suite *parquetSinkTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "archiver-parquet-sink")
+	suite.NoError(err)
+	suite.dir = dir
+}
+
+func (suite *parquetSinkTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestParquetSink(t *testing.T) {
+	suite.Run(t, new(parquetSinkTestSuite))
+}
+
+// TestParquetSinkWrite tests writing the job configs, runtimes and
+// pod events of a batch to Parquet files
+func (suite *parquetSinkTestSuite) TestParquetSinkWrite() {
+	s, err := NewParquetSink(&ParquetConfig{Directory: suite.dir})
+	suite.NoError(err)
+	defer s.Close()
+
+	suite.NoError(s.Write(context.Background(), testBatch(2)))
+
+	var configs []jobConfigRow
+	suite.readTable(_jobConfigsTable, new(jobConfigRow), &configs)
+	suite.Len(configs, 2)
+	suite.Equal("job-0", configs[0].JobID)
+	suite.Equal("compute", configs[0].OwningTeam)
+	suite.Equal(`{"env":"prod"}`, configs[0].Labels)
+
+	var runtimes []jobRuntimeRow
+	suite.readTable(_jobRuntimesTable, new(jobRuntimeRow), &runtimes)
+	suite.Len(runtimes, 2)
+	suite.Equal("SUCCEEDED", runtimes[1].State)
+
+	var events []podEventRow
+	suite.readTable(_podEventsTable, new(podEventRow), &events)
+	suite.Len(events, 2)
+	suite.Equal("job-1-0-1", events[1].TaskID)
+	suite.Equal("host-0", events[1].Hostname)
+}
+
+// TestParquetSinkWriteWithoutDetails tests that no files are
+// written for tables without rows
+func (suite *parquetSinkTestSuite) TestParquetSinkWriteWithoutDetails() {
+	s, err := NewParquetSink(&ParquetConfig{Directory: suite.dir})
+	suite.NoError(err)
+
+	batch := testBatch(1)
+	batch.Records[0].PodEvents = nil
+	suite.NoError(s.Write(context.Background(), batch))
+
+	files, err := filepath.Glob(
+		filepath.Join(suite.dir, _podEventsTable, "*"))
+	suite.NoError(err)
+	suite.Empty(files)
+}
+
+// readTable reads the rows of the only Parquet file of the table
+func (suite *parquetSinkTestSuite) readTable(
+	table string,
+	schema interface{},
+	rows interface{},
+) {
+	files, err := filepath.Glob(
+		filepath.Join(suite.dir, table, "*"+_parquetSuffix))
+	suite.NoError(err)
+	suite.Len(files, 1)
+
+	fr, err := local.NewLocalFileReader(files[0])
+	suite.NoError(err)
+	defer fr.Close()
+
+	pr, err := reader.NewParquetReader(fr, schema, 1)
+	suite.NoError(err)
+	defer pr.ReadStop()
+
+	// the reader reads as many rows as the length of the slice
+	n := int(pr.GetNumRows())
+	v := reflect.ValueOf(rows).Elem()
+	v.Set(reflect.MakeSlice(v.Type(), n, n))
+	suite.NoError(pr.Read(rows))
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink contains the sinks the archiver writes completed jobs to.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// Sink archives the completed jobs to secondary storage
+type Sink interface {
+	// Name returns the name of the sink
+	Name() string
+	// Detailed returns true if the sink archives the job config,
+	// runtime and pod events of the jobs, in addition to the job summary
+	Detailed() bool
+	// Write archives a batch of completed jobs. The batch must be
+	// durably archived when Write returns without an error.
+	Write(ctx context.Context, batch *Batch) error
+	// Close flushes and releases the resources held by the sink
+	Close() error
+}
+
+// Record contains the archived data of a completed job. The config,
+// runtime and pod events are only set for detailed sinks.
+type Record struct {
+	Summary   *job.JobSummary
+	Config    *job.JobConfig
+	Runtime   *job.RuntimeInfo
+	PodEvents []*task.PodEvent
+}
+
+// Batch is a set of completed jobs archived together
+type Batch struct {
+	// MinTime and MaxTime bound the completion time of the jobs
+	MinTime time.Time
+	MaxTime time.Time
+	Records []*Record
+}
+
+// New creates the sink configured in cfg
+func New(cfg *Config) (Sink, error) {
+	switch cfg.Type {
+	case FilebeatSink:
+		return NewFilebeatSink(cfg.Filebeat.Topic), nil
+	case FileSink:
+		return NewFileSink(&cfg.File)
+	case ObjectStoreSink:
+		return NewObjectStoreSink(&cfg.ObjectStore)
+	case ParquetSink:
+		return NewParquetSink(&cfg.Parquet)
+	default:
+		return nil, fmt.Errorf("unknown archiver sink %s", cfg.Type)
+	}
+}
+
+// jsonRecord is the JSON representation of a Record
+type jsonRecord struct {
+	JobID     string            `json:"job_id"`
+	Summary   json.RawMessage   `json:"summary,omitempty"`
+	Config    json.RawMessage   `json:"config,omitempty"`
+	Runtime   json.RawMessage   `json:"runtime,omitempty"`
+	PodEvents []json.RawMessage `json:"pod_events,omitempty"`
+}
+
+// marshalRecord encodes the record as a single line of JSON
+func marshalRecord(m *jsonpb.Marshaler, r *Record) ([]byte, error) {
+	var err error
+	jr := &jsonRecord{JobID: r.Summary.GetId().GetValue()}
+
+	if r.Summary != nil {
+		if jr.Summary, err = marshalProto(m, r.Summary); err != nil {
+			return nil, err
+		}
+	}
+	if r.Config != nil {
+		if jr.Config, err = marshalProto(m, r.Config); err != nil {
+			return nil, err
+		}
+	}
+	if r.Runtime != nil {
+		if jr.Runtime, err = marshalProto(m, r.Runtime); err != nil {
+			return nil, err
+		}
+	}
+	for _, event := range r.PodEvents {
+		raw, err := marshalProto(m, event)
+		if err != nil {
+			return nil, err
+		}
+		jr.PodEvents = append(jr.PodEvents, raw)
+	}
+	return json.Marshal(jr)
+}
+
+// marshalProto encodes a protobuf message as JSON
+func marshalProto(m *jsonpb.Marshaler, msg proto.Message) (json.RawMessage, error) {
+	s, err := m.MarshalToString(msg)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(s), nil
+}
+
+// marshalBatch encodes the records of the batch as JSON lines
+func marshalBatch(m *jsonpb.Marshaler, batch *Batch) ([]byte, error) {
+	var buf []byte
+	for _, r := range batch.Records {
+		line, err := marshalRecord(m, r)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return buf, nil
+}
+
+// batchName returns a name for the batch which is unique across
+// archiver runs, including runs which archive the same time range
+// again after a restart
+func batchName(batch *Batch) string {
+	return fmt.Sprintf("%s-%s-%d",
+		batch.MinTime.UTC().Format(_timeFormat),
+		batch.MaxTime.UTC().Format(_timeFormat),
+		time.Now().UnixNano())
+}
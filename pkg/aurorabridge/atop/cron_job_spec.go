@@ -0,0 +1,77 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/common/config"
+)
+
+// NewCronJobSpec creates a new CronJobSpec from an Aurora cron job
+// configuration. The cron job is named after the Aurora job key, and
+// each run creates a batch job from the converted task config.
+func NewCronJobSpec(
+	c *api.JobConfiguration,
+	respoolID *peloton.ResourcePoolID,
+	tc config.ThermosExecutorConfig,
+) (*cron.CronJobSpec, error) {
+
+	if !c.IsSetTaskConfig() {
+		return nil, fmt.Errorf("task config is not set in job configuration")
+	}
+	if c.GetCronSchedule() == "" {
+		return nil, fmt.Errorf("cron schedule is not set in job configuration")
+	}
+
+	p, err := NewPodSpec(c.GetTaskConfig(), tc)
+	if err != nil {
+		return nil, fmt.Errorf("new pod spec: %s", err)
+	}
+
+	name := NewJobName(c.GetKey())
+
+	return &cron.CronJobSpec{
+		Name:              name,
+		Schedule:          c.GetCronSchedule(),
+		ConcurrencyPolicy: NewConcurrencyPolicy(c.GetCronCollisionPolicy()),
+		Template: &stateless.JobSpec{
+			Name:          name,
+			Owner:         c.GetTaskConfig().GetOwner().GetUser(),
+			OwningTeam:    c.GetTaskConfig().GetOwner().GetUser(),
+			InstanceCount: uint32(c.GetInstanceCount()),
+			Sla:           newSLASpec(c.GetTaskConfig(), 0),
+			DefaultSpec:   p,
+			RespoolId:     respoolID,
+		},
+	}, nil
+}
+
+// NewConcurrencyPolicy converts an Aurora cron collision policy to a
+// cron job concurrency policy. RUN_OVERLAP is deprecated in Aurora and
+// treated the same as CANCEL_NEW.
+func NewConcurrencyPolicy(p api.CronCollisionPolicy) cron.ConcurrencyPolicy {
+	switch p {
+	case api.CronCollisionPolicyKillExisting:
+		return cron.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE
+	default:
+		return cron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP
+	}
+}
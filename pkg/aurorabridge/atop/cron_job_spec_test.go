@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/common/config"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+func TestNewCronJobSpec(t *testing.T) {
+	respoolID := &peloton.ResourcePoolID{Value: "respool"}
+	c := &api.JobConfiguration{
+		Key: &api.JobKey{
+			Role:        ptr.String("role"),
+			Environment: ptr.String("prod"),
+			Name:        ptr.String("nightly"),
+		},
+		CronSchedule:        ptr.String("0 2 * * *"),
+		CronCollisionPolicy: api.CronCollisionPolicyCancelNew.Ptr(),
+		TaskConfig: &api.TaskConfig{
+			Owner: &api.Identity{User: ptr.String("owner")},
+		},
+		InstanceCount: ptr.Int32(3),
+	}
+
+	spec, err := NewCronJobSpec(c, respoolID, config.ThermosExecutorConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, "role/prod/nightly", spec.GetName())
+	assert.Equal(t, "0 2 * * *", spec.GetSchedule())
+	assert.Equal(t, cron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP,
+		spec.GetConcurrencyPolicy())
+	assert.Equal(t, "role/prod/nightly", spec.GetTemplate().GetName())
+	assert.Equal(t, uint32(3), spec.GetTemplate().GetInstanceCount())
+	assert.Equal(t, "owner", spec.GetTemplate().GetOwner())
+	assert.Equal(t, respoolID, spec.GetTemplate().GetRespoolId())
+
+	c.CronSchedule = nil
+	_, err = NewCronJobSpec(c, respoolID, config.ThermosExecutorConfig{})
+	assert.Error(t, err)
+
+	c.TaskConfig = nil
+	_, err = NewCronJobSpec(c, respoolID, config.ThermosExecutorConfig{})
+	assert.Error(t, err)
+}
+
+func TestNewConcurrencyPolicy(t *testing.T) {
+	assert.Equal(t, cron.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE,
+		NewConcurrencyPolicy(api.CronCollisionPolicyKillExisting))
+	assert.Equal(t, cron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP,
+		NewConcurrencyPolicy(api.CronCollisionPolicyCancelNew))
+	assert.Equal(t, cron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP,
+		NewConcurrencyPolicy(api.CronCollisionPolicyRunOverlap))
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
@@ -73,6 +74,7 @@ type ServiceHandler struct {
 	jobClient     statelesssvc.JobServiceYARPCClient
 	jobmgrClient  jobmgrsvc.JobManagerServiceYARPCClient
 	podClient     podsvc.PodServiceYARPCClient
+	cronClient    cronsvc.CronJobServiceYARPCClient
 	respoolLoader RespoolLoader
 	random        common.Random
 	jobIdCache    cache.JobIDCache
@@ -85,6 +87,7 @@ func NewServiceHandler(
 	jobClient statelesssvc.JobServiceYARPCClient,
 	jobmgrClient jobmgrsvc.JobManagerServiceYARPCClient,
 	podClient podsvc.PodServiceYARPCClient,
+	cronClient cronsvc.CronJobServiceYARPCClient,
 	respoolLoader RespoolLoader,
 	random common.Random,
 	jobIdCache cache.JobIDCache,
@@ -101,6 +104,7 @@ func NewServiceHandler(
 		jobClient:     jobClient,
 		jobmgrClient:  jobmgrClient,
 		podClient:     podClient,
+		cronClient:    cronClient,
 		respoolLoader: respoolLoader,
 		random:        random,
 		jobIdCache:    jobIdCache,
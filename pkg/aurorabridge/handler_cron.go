@@ -0,0 +1,313 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"context"
+	"time"
+
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/label"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ScheduleCronJob creates a cron job, or replaces the template and
+// schedule of the cron job if it already exists.
+func (h *ServiceHandler) ScheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Response, error) {
+
+	startTime := time.Now()
+	result, err := h.scheduleCronJob(ctx, description)
+	resp := newResponse(result, err, "scheduleCronJob")
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureScheduleCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureScheduleCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"description": description,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ScheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job":      description.GetKey(),
+				"schedule": description.GetCronSchedule(),
+			},
+		}).Info("ScheduleCronJob success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) scheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	req, aerr := h.newCronJobRequest(ctx, description)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	_, err := h.cronClient.CreateCronJob(ctx, &cronsvc.CreateCronJobRequest{
+		Spec: req.GetSpec(),
+	})
+	if err == nil {
+		return dummyResult(), nil
+	}
+	if !yarpcerrors.IsAlreadyExists(err) {
+		return nil, auroraErrorf("create cron job: %s", err)
+	}
+
+	if _, err := h.cronClient.ReplaceCronJob(ctx, req); err != nil {
+		return nil, auroraErrorf("replace cron job: %s", err)
+	}
+	return dummyResult(), nil
+}
+
+// DescheduleCronJob removes the cron schedule of a job. Jobs already
+// started by the cron schedule are not affected.
+func (h *ServiceHandler) DescheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	startTime := time.Now()
+	result, err := h.descheduleCronJob(ctx, job)
+	resp := newResponse(result, err, "descheduleCronJob")
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureDescheduleCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureDescheduleCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("DescheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+		}).Info("DescheduleCronJob success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) descheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	_, err := h.cronClient.DeleteCronJob(ctx, &cronsvc.DeleteCronJobRequest{
+		Name: atop.NewJobName(job),
+	})
+	if err != nil {
+		return nil, newCronJobError("delete cron job", job, err)
+	}
+	return dummyResult(), nil
+}
+
+// StartCronJob starts a run of a cron job immediately.
+func (h *ServiceHandler) StartCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	startTime := time.Now()
+	result, err := h.startCronJob(ctx, job)
+	resp := newResponse(result, err, "startCronJob")
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureStartCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureStartCronJob].
+			ResponseCodes[resp.GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("StartCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+		}).Info("StartCronJob success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) startCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	_, err := h.cronClient.RunCronJob(ctx, &cronsvc.RunCronJobRequest{
+		Name: atop.NewJobName(job),
+	})
+	if err != nil {
+		return nil, newCronJobError("run cron job", job, err)
+	}
+	return dummyResult(), nil
+}
+
+// ReplaceCronTemplate replaces the template and schedule of an
+// existing cron job.
+func (h *ServiceHandler) ReplaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Response, error) {
+
+	startTime := time.Now()
+	result, err := h.replaceCronTemplate(ctx, config)
+	resp := newResponse(result, err, "replaceCronTemplate")
+
+	defer func() {
+		h.metrics.
+			Procedures[ProcedureReplaceCronTemplate].
+			ResponseCodes[resp.GetResponseCode()].
+			Calls.Inc(1)
+
+		h.metrics.
+			Procedures[ProcedureReplaceCronTemplate].
+			ResponseCodes[resp.GetResponseCode()].
+			CallLatency.Record(time.Since(startTime))
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"config": config,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ReplaceCronTemplate error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job":      config.GetKey(),
+				"schedule": config.GetCronSchedule(),
+			},
+		}).Info("ReplaceCronTemplate success")
+	}()
+
+	return resp, nil
+}
+
+func (h *ServiceHandler) replaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	req, aerr := h.newCronJobRequest(ctx, config)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	if _, err := h.cronClient.ReplaceCronJob(ctx, req); err != nil {
+		return nil, newCronJobError("replace cron job", config.GetKey(), err)
+	}
+	return dummyResult(), nil
+}
+
+// newCronJobRequest converts an Aurora cron job configuration to a
+// request replacing the cron job spec.
+func (h *ServiceHandler) newCronJobRequest(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*cronsvc.ReplaceCronJobRequest, *auroraError) {
+
+	respoolID, err := h.respoolLoader.Load(
+		ctx,
+		label.IsGpuConfig(
+			config.GetTaskConfig().GetMetadata(),
+			config.GetTaskConfig().GetResources(),
+		),
+	)
+	if err != nil {
+		return nil, auroraErrorf("load respool: %s", err)
+	}
+
+	spec, err := atop.NewCronJobSpec(config, respoolID, h.config.ThermosExecutor)
+	if err != nil {
+		return nil, auroraErrorf("new cron job spec: %s", err).
+			code(api.ResponseCodeInvalidRequest)
+	}
+	return &cronsvc.ReplaceCronJobRequest{Spec: spec}, nil
+}
+
+// newCronJobError converts an error returned by the cron job service,
+// reporting unknown cron jobs as invalid requests like Aurora does.
+func newCronJobError(
+	action string,
+	job *api.JobKey,
+	err error,
+) *auroraError {
+	if yarpcerrors.IsNotFound(err) {
+		return auroraErrorf("job %s is not scheduled with cron",
+			atop.NewJobName(job)).
+			code(api.ResponseCodeInvalidRequest)
+	}
+	return auroraErrorf("%s: %s", action, err)
+}
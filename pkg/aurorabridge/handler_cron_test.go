@@ -0,0 +1,190 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"errors"
+
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/fixture"
+
+	"github.com/golang/mock/gomock"
+	"go.uber.org/thriftrw/ptr"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func newAuroraCronJobConfiguration() *api.JobConfiguration {
+	taskConfig := fixture.AuroraTaskConfig()
+	return &api.JobConfiguration{
+		Key:                 taskConfig.GetJob(),
+		CronSchedule:        ptr.String("0 * * * *"),
+		CronCollisionPolicy: api.CronCollisionPolicyKillExisting.Ptr(),
+		TaskConfig:          taskConfig,
+		InstanceCount:       ptr.Int32(2),
+	}
+}
+
+// Ensures that ScheduleCronJob creates a new cron job.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_Create() {
+	config := newAuroraCronJobConfiguration()
+	respoolID := fixture.PelotonResourcePoolID()
+
+	suite.respoolLoader.EXPECT().Load(gomock.Any(), false).Return(respoolID, nil)
+	suite.cronClient.EXPECT().
+		CreateCronJob(gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, req *cronsvc.CreateCronJobRequest) {
+			suite.Equal(atop.NewJobName(config.GetKey()), req.GetSpec().GetName())
+			suite.Equal("0 * * * *", req.GetSpec().GetSchedule())
+			suite.Equal(respoolID, req.GetSpec().GetTemplate().GetRespoolId())
+		}).
+		Return(&cronsvc.CreateCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob replaces an existing cron job.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_Replace() {
+	config := newAuroraCronJobConfiguration()
+
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(fixture.PelotonResourcePoolID(), nil)
+	suite.cronClient.EXPECT().
+		CreateCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.AlreadyExistsErrorf("exists"))
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(gomock.Any(), gomock.Any()).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob rejects configurations without schedule.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_NoSchedule() {
+	config := newAuroraCronJobConfiguration()
+	config.CronSchedule = nil
+
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(fixture.PelotonResourcePoolID(), nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob returns error if the respool cannot be loaded.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_RespoolError() {
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(nil, errors.New("respool error"))
+
+	resp, err := suite.handler.ScheduleCronJob(
+		suite.ctx, newAuroraCronJobConfiguration())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that DescheduleCronJob deletes the cron job.
+func (suite *ServiceHandlerTestSuite) TestDescheduleCronJob() {
+	k := fixture.AuroraJobKey()
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(gomock.Any(), &cronsvc.DeleteCronJobRequest{
+			Name: atop.NewJobName(k),
+		}).
+		Return(&cronsvc.DeleteCronJobResponse{}, nil)
+
+	resp, err := suite.handler.DescheduleCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that DescheduleCronJob of an unknown cron job is an
+// invalid request.
+func (suite *ServiceHandlerTestSuite) TestDescheduleCronJob_NotFound() {
+	suite.cronClient.EXPECT().
+		DeleteCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+
+	resp, err := suite.handler.DescheduleCronJob(
+		suite.ctx, fixture.AuroraJobKey())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that StartCronJob runs the cron job.
+func (suite *ServiceHandlerTestSuite) TestStartCronJob() {
+	k := fixture.AuroraJobKey()
+
+	suite.cronClient.EXPECT().
+		RunCronJob(gomock.Any(), &cronsvc.RunCronJobRequest{
+			Name: atop.NewJobName(k),
+		}).
+		Return(&cronsvc.RunCronJobResponse{}, nil)
+
+	resp, err := suite.handler.StartCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that StartCronJob returns error if the run fails.
+func (suite *ServiceHandlerTestSuite) TestStartCronJob_Error() {
+	suite.cronClient.EXPECT().
+		RunCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("not leader"))
+
+	resp, err := suite.handler.StartCronJob(suite.ctx, fixture.AuroraJobKey())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that ReplaceCronTemplate replaces the cron job.
+func (suite *ServiceHandlerTestSuite) TestReplaceCronTemplate() {
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(fixture.PelotonResourcePoolID(), nil)
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(gomock.Any(), gomock.Any()).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ReplaceCronTemplate(
+		suite.ctx, newAuroraCronJobConfiguration())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ReplaceCronTemplate of an unknown cron job is an
+// invalid request.
+func (suite *ServiceHandlerTestSuite) TestReplaceCronTemplate_NotFound() {
+	suite.respoolLoader.EXPECT().
+		Load(gomock.Any(), false).
+		Return(fixture.PelotonResourcePoolID(), nil)
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+
+	resp, err := suite.handler.ReplaceCronTemplate(
+		suite.ctx, newAuroraCronJobConfiguration())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
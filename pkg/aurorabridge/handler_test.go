@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"testing"
 
+	cronmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc/mocks"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	jobmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc/mocks"
@@ -67,6 +68,7 @@ type ServiceHandlerTestSuite struct {
 	jobmgrClient   *jobmgrmocks.MockJobManagerServiceYARPCClient
 	listPodsStream *jobmocks.MockJobServiceServiceListPodsYARPCClient
 	podClient      *podmocks.MockPodServiceYARPCClient
+	cronClient     *cronmocks.MockCronJobServiceYARPCClient
 	respoolLoader  *aurorabridgemocks.MockRespoolLoader
 	random         *commonmocks.MockRandom
 	jobIdCache     *cachemocks.MockJobIDCache
@@ -85,6 +87,7 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 	suite.jobmgrClient = jobmgrmocks.NewMockJobManagerServiceYARPCClient(suite.ctrl)
 	suite.listPodsStream = jobmocks.NewMockJobServiceServiceListPodsYARPCClient(suite.ctrl)
 	suite.podClient = podmocks.NewMockPodServiceYARPCClient(suite.ctrl)
+	suite.cronClient = cronmocks.NewMockCronJobServiceYARPCClient(suite.ctrl)
 	suite.respoolLoader = aurorabridgemocks.NewMockRespoolLoader(suite.ctrl)
 	suite.random = commonmocks.NewMockRandom(suite.ctrl)
 	suite.jobIdCache = cachemocks.NewMockJobIDCache(suite.ctrl)
@@ -111,6 +114,7 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 		suite.jobClient,
 		suite.jobmgrClient,
 		suite.podClient,
+		suite.cronClient,
 		suite.respoolLoader,
 		suite.random,
 		suite.jobIdCache,
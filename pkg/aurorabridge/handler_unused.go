@@ -66,27 +66,6 @@ func (h *ServiceHandler) CreateJob(
 	return nil, errUnimplemented
 }
 
-// ScheduleCronJob will remain unimplemented.
-func (h *ServiceHandler) ScheduleCronJob(
-	ctx context.Context,
-	description *api.JobConfiguration) (*api.Response, error) {
-	return nil, errUnimplemented
-}
-
-// DescheduleCronJob will remain unimplemented.
-func (h *ServiceHandler) DescheduleCronJob(
-	ctx context.Context,
-	job *api.JobKey) (*api.Response, error) {
-	return nil, errUnimplemented
-}
-
-// StartCronJob will remain unimplemented.
-func (h *ServiceHandler) StartCronJob(
-	ctx context.Context,
-	job *api.JobKey) (*api.Response, error) {
-	return nil, errUnimplemented
-}
-
 // RestartShards will remain unimplemented.
 func (h *ServiceHandler) RestartShards(
 	ctx context.Context,
@@ -103,10 +82,3 @@ func (h *ServiceHandler) AddInstances(
 	count *int32) (*api.Response, error) {
 	return nil, errUnimplemented
 }
-
-// ReplaceCronTemplate will remain unimplemented.
-func (h *ServiceHandler) ReplaceCronTemplate(
-	ctx context.Context,
-	config *api.JobConfiguration) (*api.Response, error) {
-	return nil, errUnimplemented
-}
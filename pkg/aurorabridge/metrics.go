@@ -21,6 +21,7 @@ import (
 
 const (
 	ProcedureAbortJobUpdate         = "auroraschedulermanager__abortjobupdate"
+	ProcedureDescheduleCronJob      = "auroraschedulermanager__deschedulecronjob"
 	ProcedureGetConfigSummary       = "readonlyscheduler__getconfigsummary"
 	ProcedureGetJobSummary          = "readonlyscheduler__getjobsummary"
 	ProcedureGetJobUpdateDetails    = "readonlyscheduler__getjobupdatedetails"
@@ -32,8 +33,11 @@ const (
 	ProcedureKillTasks              = "auroraschedulermanager__killtasks"
 	ProcedurePauseJobUpdate         = "auroraschedulermanager__pausejobupdate"
 	ProcedurePulseJobUpdate         = "auroraschedulermanager__pulsejobupdate"
+	ProcedureReplaceCronTemplate    = "auroraschedulermanager__replacecrontemplate"
 	ProcedureResumeJobUpdate        = "auroraschedulermanager__resumejobupdate"
 	ProcedureRollbackJobUpdate      = "auroraschedulermanager__rollbackjobupdate"
+	ProcedureScheduleCronJob        = "auroraschedulermanager__schedulecronjob"
+	ProcedureStartCronJob           = "auroraschedulermanager__startcronjob"
 	ProcedureStartJobUpdate         = "auroraschedulermanager__startjobupdate"
 
 	// Metric tag names
@@ -48,6 +52,7 @@ const (
 
 var _procedures = []string{
 	ProcedureAbortJobUpdate,
+	ProcedureDescheduleCronJob,
 	ProcedureGetConfigSummary,
 	ProcedureGetJobSummary,
 	ProcedureGetJobUpdateDetails,
@@ -59,8 +64,11 @@ var _procedures = []string{
 	ProcedureKillTasks,
 	ProcedurePauseJobUpdate,
 	ProcedurePulseJobUpdate,
+	ProcedureReplaceCronTemplate,
 	ProcedureResumeJobUpdate,
 	ProcedureRollbackJobUpdate,
+	ProcedureScheduleCronJob,
+	ProcedureStartCronJob,
 	ProcedureStartJobUpdate,
 }
 
@@ -14,10 +14,29 @@
 
 package auth
 
+import "time"
+
 // Config is auth specific configuration
 type Config struct {
 	// AuthType is the type of auth
 	AuthType Type `yaml:"auth_type"`
 	// Path is the path to the config file for auth
 	Path string `yaml:"path"`
+	// ReloadInterval is the interval to check the config file
+	// for changes, it defaults to DefaultReloadInterval and the
+	// config is not reloaded if it is negative
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// DefaultReloadInterval is the interval to check the config file
+// for changes if ReloadInterval is not set
+const DefaultReloadInterval = time.Minute
+
+// GetReloadInterval returns the interval to check the config file
+// for changes, the config is not reloaded if it is not positive
+func (c *Config) GetReloadInterval() time.Duration {
+	if c.ReloadInterval == 0 {
+		return DefaultReloadInterval
+	}
+	return c.ReloadInterval
 }
@@ -0,0 +1,59 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import "context"
+
+type userContextKey struct{}
+
+// ContextWithUser returns a copy of the context which
+// carries the authenticated user
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user carried
+// by the context, if any
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey{}).(User)
+	return user, ok
+}
+
+// ResourceUserFromContext returns the authenticated user carried by
+// the context if its permissions can be scoped to resources
+func ResourceUserFromContext(ctx context.Context) (ResourceUser, bool) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	resourceUser, ok := user.(ResourceUser)
+	return resourceUser, ok
+}
+
+// IsPermittedOnResource returns whether the user carried by the context
+// can access the procedure on the resource. Requests which do not carry
+// a user, e.g. when auth is disabled, and users whose permissions are not
+// scoped to resources have already been authorized by procedure, so they
+// are permitted.
+func IsPermittedOnResource(
+	ctx context.Context,
+	procedure string,
+	resource *Resource) bool {
+	user, ok := ResourceUserFromContext(ctx)
+	if !ok {
+		return true
+	}
+	return user.IsPermittedOnResource(procedure, resource)
+}
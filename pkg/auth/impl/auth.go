@@ -17,7 +17,9 @@ package impl
 import (
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/auth/impl/basic"
+	"github.com/uber/peloton/pkg/auth/impl/jwt"
 	"github.com/uber/peloton/pkg/auth/impl/noop"
+	"github.com/uber/peloton/pkg/auth/impl/rbac"
 
 	"go.uber.org/yarpc/yarpcerrors"
 )
@@ -29,6 +31,10 @@ func CreateNewSecurityManager(config *auth.Config) (auth.SecurityManager, error)
 		return noop.NewNoopSecurityManager(), nil
 	case auth.BASIC:
 		return basic.NewBasicSecurityManager(config.Path)
+	case auth.RBAC:
+		return rbac.NewRBACSecurityManager(config.Path, config.GetReloadInterval())
+	case auth.JWT:
+		return jwt.NewJWTSecurityManager(config.Path)
 	default:
 		return nil,
 			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", config.AuthType)
@@ -42,6 +48,10 @@ func CreateNewSecurityClient(config *auth.Config) (auth.SecurityClient, error) {
 		return noop.NewNoopSecurityClient(), nil
 	case auth.BASIC:
 		return basic.NewBasicSecurityClient(config.Path)
+	case auth.RBAC:
+		return rbac.NewRBACSecurityClient(config.Path)
+	case auth.JWT:
+		return jwt.NewJWTSecurityClient(config.Path)
 	default:
 		return nil,
 			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", config.AuthType)
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/rule"
 	"github.com/uber/peloton/pkg/common/config"
 
 	"go.uber.org/yarpc/yarpcerrors"
@@ -64,6 +65,7 @@ type role struct {
 }
 
 var _ auth.SecurityManager = &SecurityManager{}
+var _ auth.NamedUser = &user{}
 
 // Authenticate authenticates a user,
 // it expects to Accept UsernamePasswordToken
@@ -103,6 +105,11 @@ func (m *SecurityManager) RedactToken(token auth.Token) {
 	token.Del(_passwordHeaderKey)
 }
 
+// Name returns the username of user
+func (u *user) Name() string {
+	return u.username
+}
+
 // IsPermitted returns if a procedure is permitted for user
 func (u *user) IsPermitted(procedure string) bool {
 	// procedure is permitted if it is accepted by
@@ -287,67 +294,9 @@ func isRootRole(config *roleConfig) bool {
 }
 
 // check if the rule is valid,
-func validateRule(rule string) error {
-	error := yarpcerrors.InvalidArgumentErrorf(
-		"rule: %s has unexpected format",
-		rule,
-	)
-
-	if rule == _matchAllRule {
-		return nil
-	}
-
-	results := strings.Split(rule, _ruleSeparator)
-	if len(results) != 2 {
-		return error
-	}
-
-	if len(results[0]) == 0 || len(results[1]) == 0 {
-		return error
-	}
-
-	if !isValidServiceName(results[0]) || !isValidMethod(results[1]) {
-		return error
-	}
-
-	return nil
-}
-
-func isValidServiceName(service string) bool {
-	// service name in a rule can have only [a-zA-Z0-9] and '.'
-	for _, r := range service {
-		if (r >= 'a' && r <= 'z') ||
-			(r >= 'A' && r <= 'Z') ||
-			(r >= '0' && r <= '9') ||
-			r == '.' {
-			continue
-		}
-		return false
-	}
-
-	return true
-}
-
-func isValidMethod(method string) bool {
-	// method part in a rule can be '*', method_name_prefix + '*' or method name
-	if method == _matchAllRule {
-		return true
-	}
-
-	c := strings.Count(method, _matchAllRule)
-	if c > 1 {
-		return false
-	} else if c == 1 {
-		return method[len(method)-1:] == _matchAllRule
-	}
-
-	for _, r := range method {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-			continue
-		}
-		return false
-	}
-	return true
+func validateRule(r string) error {
+	_, err := rule.Parse(r)
+	return err
 }
 
 func constructRoles(mConfig *authConfig) map[string]*role {
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// interval to check the internal token file for changes
+const _tokenCheckInterval = 10 * time.Second
+
+// SecurityClient returns token which authenticates internal
+// communication when jwt auth is enabled. The token is read from
+// a file, which is refreshed by the provider before it expires.
+type SecurityClient struct {
+	sync.Mutex
+
+	tokenPath string
+	// modification time of the file the token was read from
+	modTime time.Time
+	// last time the token file was checked for changes
+	checkTime time.Time
+	token     *jwtToken
+
+	now func() time.Time
+}
+
+// GetToken returns a token for jwt auth
+func (c *SecurityClient) GetToken() auth.Token {
+	c.Lock()
+	defer c.Unlock()
+
+	if now := c.now(); now.Sub(c.checkTime) >= _tokenCheckInterval {
+		c.checkTime = now
+		if err := c.reload(); err != nil {
+			log.WithError(err).
+				WithField("path", c.tokenPath).
+				Error("failed to reload internal token, keep using the previous token")
+		}
+	}
+	return c.token
+}
+
+// reload reads the token from the token file
+// if it has changed since the token was last read
+func (c *SecurityClient) reload() error {
+	info, err := os.Stat(c.tokenPath)
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(c.modTime) {
+		return nil
+	}
+
+	token, err := readToken(c.tokenPath)
+	if err != nil {
+		return err
+	}
+
+	c.token = token
+	c.modTime = info.ModTime()
+	return nil
+}
+
+// jwtToken is immutable after init,
+// a new token is created when the token file is reloaded
+type jwtToken struct {
+	items map[string]string
+}
+
+func (t *jwtToken) Get(k string) (string, bool) {
+	result, ok := t.items[k]
+	return result, ok
+}
+
+func (t *jwtToken) Items() map[string]string {
+	return t.items
+}
+
+func (t *jwtToken) Del(k string) {
+	delete(t.items, k)
+}
+
+func readToken(tokenPath string) (*jwtToken, error) {
+	data, err := ioutil.ReadFile(tokenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if len(token) == 0 {
+		return nil, errors.New("token file is empty")
+	}
+
+	return &jwtToken{
+		items: map[string]string{
+			_authorizationHeaderKey: "Bearer " + token,
+		},
+	}, nil
+}
+
+// NewJWTSecurityClient returns SecurityClient
+func NewJWTSecurityClient(configPath string) (*SecurityClient, error) {
+	cConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newJWTSecurityClient(cConfig)
+}
+
+// helper method to create SecurityClient which makes test easier
+func newJWTSecurityClient(cConfig *authConfig) (*SecurityClient, error) {
+	if len(cConfig.InternalTokenPath) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("no internal token path specified")
+	}
+
+	c := &SecurityClient{
+		tokenPath: cConfig.InternalTokenPath,
+		now:       time.Now,
+	}
+	if err := c.reload(); err != nil {
+		return nil, errors.Wrap(err, "failed to read internal token")
+	}
+	c.checkTime = c.now()
+	return c, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SecurityClientTestSuite struct {
+	suite.Suite
+
+	tempDir   string
+	tokenPath string
+	now       time.Time
+}
+
+func (suite *SecurityClientTestSuite) SetupTest() {
+	var err error
+	suite.tempDir, err = ioutil.TempDir("", "jwt")
+	suite.NoError(err)
+	suite.tokenPath = filepath.Join(suite.tempDir, "token")
+	suite.now = time.Now()
+}
+
+func (suite *SecurityClientTestSuite) TearDownTest() {
+	os.RemoveAll(suite.tempDir)
+}
+
+func (suite *SecurityClientTestSuite) writeToken(token string, modTime time.Time) {
+	suite.NoError(ioutil.WriteFile(suite.tokenPath, []byte(token), 0600))
+	suite.NoError(os.Chtimes(suite.tokenPath, modTime, modTime))
+}
+
+func (suite *SecurityClientTestSuite) newClient() *SecurityClient {
+	c, err := newJWTSecurityClient(&authConfig{InternalTokenPath: suite.tokenPath})
+	suite.NoError(err)
+	c.now = func() time.Time { return suite.now }
+	c.checkTime = suite.now
+	return c
+}
+
+func (suite *SecurityClientTestSuite) getAuthorization(c *SecurityClient) string {
+	header, ok := c.GetToken().Get(_authorizationHeaderKey)
+	suite.True(ok)
+	return header
+}
+
+// TestGetToken tests that the token is read from the token file
+func (suite *SecurityClientTestSuite) TestGetToken() {
+	suite.writeToken("token1\n", suite.now.Add(-time.Hour))
+	c := suite.newClient()
+
+	suite.Equal("Bearer token1", suite.getAuthorization(c))
+	suite.Len(c.GetToken().Items(), 1)
+}
+
+// TestGetTokenReload tests that the token is reloaded
+// when the token file changes
+func (suite *SecurityClientTestSuite) TestGetTokenReload() {
+	suite.writeToken("token1", suite.now.Add(-time.Hour))
+	c := suite.newClient()
+
+	suite.writeToken("token2", suite.now)
+
+	// the token file is not checked again within the check interval
+	suite.Equal("Bearer token1", suite.getAuthorization(c))
+
+	suite.now = suite.now.Add(_tokenCheckInterval)
+	suite.Equal("Bearer token2", suite.getAuthorization(c))
+
+	// previous token is kept if the token file cannot be read
+	suite.NoError(os.Remove(suite.tokenPath))
+	suite.now = suite.now.Add(_tokenCheckInterval)
+	suite.Equal("Bearer token2", suite.getAuthorization(c))
+}
+
+// TestNewClientFailure tests that a client cannot be created
+// without a valid token file
+func (suite *SecurityClientTestSuite) TestNewClientFailure() {
+	_, err := newJWTSecurityClient(&authConfig{})
+	suite.Error(err)
+
+	_, err = newJWTSecurityClient(&authConfig{InternalTokenPath: suite.tokenPath})
+	suite.Error(err)
+
+	suite.writeToken(" \n", suite.now)
+	_, err = newJWTSecurityClient(&authConfig{InternalTokenPath: suite.tokenPath})
+	suite.Error(err)
+}
+
+func TestSecurityClient(t *testing.T) {
+	suite.Run(t, new(SecurityClientTestSuite))
+}
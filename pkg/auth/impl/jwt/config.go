@@ -0,0 +1,51 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import "time"
+
+type authConfig struct {
+	// Issuer is the expected iss claim of the tokens
+	Issuer string
+	// Audience is the expected aud claim of the tokens
+	Audience string
+	// JWKS is the path or the http(s) URL of the JSON Web Key Set
+	// used to verify the signature of the tokens
+	JWKS string `yaml:"jwks"`
+	// JWKSRefreshInterval is the interval to reload the key set,
+	// the key set is not reloaded if it is 0
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+	// ClockSkew is the leeway allowed when checking exp and nbf claims
+	ClockSkew time.Duration `yaml:"clock_skew"`
+	// UsernameClaim is the claim used as the username, default to sub
+	UsernameClaim string `yaml:"username_claim"`
+	// RolesClaim is the claim which lists the roles of the user,
+	// default to groups. Nested claims are separated by '.'
+	RolesClaim string `yaml:"roles_claim"`
+	// DefaultRole is the role of the requests without a token,
+	// requests without a token are rejected if it is not set
+	DefaultRole string `yaml:"default_role"`
+	Roles       []*roleConfig
+	// InternalTokenPath is the path to the file which contains the
+	// token used for communication between peloton components. The
+	// file is expected to be refreshed before the token expires.
+	InternalTokenPath string `yaml:"internal_token_path"`
+}
+
+type roleConfig struct {
+	Role   string
+	Accept []string
+	Reject []string
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const _keyUseSignature = "sig"
+
+// errUnsupportedKey is returned for keys whose type, curve or algorithm
+// cannot be used to verify tokens, they are skipped when loading a key set
+var errUnsupportedKey = errors.New("unsupported key")
+
+// jsonWebKey is a public key in a JSON Web Key Set, see RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA public key parameters
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC public key parameters
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+// verificationKey is a public key used to verify signatures
+type verificationKey struct {
+	key crypto.PublicKey
+	// alg is the algorithm the key is declared for in the key set,
+	// tokens signed with other algorithms are rejected. It is empty
+	// if the key set does not restrict the algorithm of the key.
+	alg string
+}
+
+// keySet maps key id to the key used to verify signatures
+type keySet map[string]*verificationKey
+
+// get returns the key for the key id. A token without
+// key id can only be verified by a key set with one key.
+func (s keySet) get(kid string) (*verificationKey, bool) {
+	if len(kid) == 0 && len(s) == 1 {
+		for _, key := range s {
+			return key, true
+		}
+	}
+	key, ok := s[kid]
+	return key, ok
+}
+
+// loadKeySet loads the key set from a http(s) URL or a file
+func loadKeySet(source string, client *http.Client) (keySet, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://") {
+		data, err = fetchKeySet(source, client)
+	} else {
+		data, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load jwks from %s", source)
+	}
+
+	return parseKeySet(data)
+}
+
+func fetchKeySet(url string, client *http.Client) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseKeySet parses the signature keys in a JSON Web Key Set
+func parseKeySet(data []byte) (keySet, error) {
+	var jwks jsonWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Wrap(err, "failed to parse jwks")
+	}
+
+	keys := make(keySet)
+	for _, jwk := range jwks.Keys {
+		if len(jwk.Use) != 0 && jwk.Use != _keyUseSignature {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if errors.Cause(err) == errUnsupportedKey {
+			log.WithError(err).
+				WithField("kid", jwk.Kid).
+				Info("skip unsupported key in jwks")
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %s in jwks", jwk.Kid)
+		}
+
+		if _, ok := keys[jwk.Kid]; ok {
+			return nil, fmt.Errorf("key %s defined more than once in jwks", jwk.Kid)
+		}
+		keys[jwk.Kid] = &verificationKey{key: key, alg: jwk.Alg}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signature key found in jwks")
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	if len(k.Alg) != 0 && !k.supportsAlgorithm() {
+		return nil, errors.Wrapf(errUnsupportedKey,
+			"algorithm %s for key type %s", k.Alg, k.Kty)
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("invalid rsa public exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(errUnsupportedKey, "curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, errors.Wrapf(errUnsupportedKey, "key type %s", k.Kty)
+	}
+}
+
+// supportsAlgorithm returns true if the algorithm of the key is
+// supported to verify tokens and matches the key type
+func (k *jsonWebKey) supportsAlgorithm() bool {
+	if _, ok := _algorithms[k.Alg]; !ok {
+		return false
+	}
+
+	switch k.Kty {
+	case "RSA":
+		return strings.HasPrefix(k.Alg, "RS") || strings.HasPrefix(k.Alg, "PS")
+	case "EC":
+		// the algorithm is bound to the curve of the key
+		return _ecdsaCurves[k.Alg] == k.Crv
+	}
+	return false
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
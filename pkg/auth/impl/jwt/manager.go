@@ -0,0 +1,328 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/rule"
+	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// expected field passed by token
+	_authorizationHeaderKey = "authorization"
+	_bearerPrefix           = "bearer "
+
+	_defaultUsernameClaim = "sub"
+	_defaultRolesClaim    = "groups"
+
+	_jwksFetchTimeout = 10 * time.Second
+)
+
+// SecurityManager authenticates users with signed bearer tokens
+// issued by an OIDC provider, and authorizes them with the roles
+// listed in the claims of the token.
+type SecurityManager struct {
+	sync.RWMutex
+
+	// keys used to verify tokens, reloaded from jwksSource
+	keys       keySet
+	jwksSource string
+	httpClient *http.Client
+
+	verifier      *verifier
+	usernameClaim string
+	rolesClaim    string
+	roles         map[string]*role
+	defaultUser   *user
+
+	lifeCycle lifecycle.LifeCycle
+}
+
+// all fields are immutable after init
+type user struct {
+	username string
+	roles    []*role
+}
+
+// all fields are immutable after init
+type role struct {
+	role    string
+	accepts []*rule.Rule
+	rejects []*rule.Rule
+}
+
+var _ auth.SecurityManager = &SecurityManager{}
+var _ auth.NamedUser = &user{}
+
+// Authenticate authenticates a user,
+// it expects to Accept a bearer token in the authorization header
+func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
+	header, _ := token.Get(_authorizationHeaderKey)
+
+	// no token provided, return default user
+	if len(header) == 0 {
+		if m.defaultUser == nil {
+			return nil, yarpcerrors.UnauthenticatedErrorf("no bearer token provided")
+		}
+		return m.defaultUser, nil
+	}
+
+	if len(header) <= len(_bearerPrefix) ||
+		!strings.EqualFold(header[:len(_bearerPrefix)], _bearerPrefix) {
+		return nil, yarpcerrors.UnauthenticatedErrorf("invalid authorization header")
+	}
+
+	c, err := m.verifier.verify(
+		strings.TrimSpace(header[len(_bearerPrefix):]),
+		m.getKeys(),
+	)
+	if err != nil {
+		return nil, yarpcerrors.UnauthenticatedErrorf("invalid bearer token: %v", err)
+	}
+
+	return m.newUser(c)
+}
+
+// RedactToken removes the bearer token from the token
+func (m *SecurityManager) RedactToken(token auth.Token) {
+	token.Del(_authorizationHeaderKey)
+}
+
+// Stop stops reloading the key set
+func (m *SecurityManager) Stop() {
+	if !m.lifeCycle.Stop() {
+		return
+	}
+	m.lifeCycle.Wait()
+}
+
+func (m *SecurityManager) getKeys() keySet {
+	m.RLock()
+	defer m.RUnlock()
+	return m.keys
+}
+
+// newUser maps the claims of a verified token to a user
+func (m *SecurityManager) newUser(c claims) (*user, error) {
+	usernames := c.strings(m.usernameClaim)
+	if len(usernames) != 1 || len(usernames[0]) == 0 {
+		return nil, yarpcerrors.UnauthenticatedErrorf(
+			"invalid bearer token: no %s claim", m.usernameClaim)
+	}
+
+	u := &user{username: usernames[0]}
+	for _, name := range c.strings(m.rolesClaim) {
+		// roles which are not defined in the config are ignored,
+		// since the provider may list roles of other applications
+		if r, ok := m.roles[name]; ok {
+			u.roles = append(u.roles, r)
+		}
+	}
+	return u, nil
+}
+
+// startRefresh reloads the key set every interval, so keys rotated
+// by the provider are picked up
+func (m *SecurityManager) startRefresh(interval time.Duration) {
+	if !m.lifeCycle.Start() {
+		return
+	}
+
+	go func() {
+		defer m.lifeCycle.StopComplete()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.lifeCycle.StopCh():
+				return
+			case <-ticker.C:
+				keys, err := loadKeySet(m.jwksSource, m.httpClient)
+				if err != nil {
+					log.WithError(err).
+						WithField("jwks", m.jwksSource).
+						Error("failed to refresh jwks, keep using the previous keys")
+					continue
+				}
+
+				m.Lock()
+				m.keys = keys
+				m.Unlock()
+			}
+		}
+	}()
+}
+
+// Name returns the username of user
+func (u *user) Name() string {
+	return u.username
+}
+
+// IsPermitted returns if a procedure is permitted for user,
+// a procedure is permitted if any of the roles of the user
+// accepts it and does not reject it
+func (u *user) IsPermitted(procedure string) bool {
+	for _, r := range u.roles {
+		if rule.MatchAny(procedure, r.accepts) &&
+			!rule.MatchAny(procedure, r.rejects) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewJWTSecurityManager returns SecurityManager
+func NewJWTSecurityManager(configPath string) (*SecurityManager, error) {
+	mConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newJWTSecurityManager(mConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if mConfig.JWKSRefreshInterval > 0 {
+		m.startRefresh(mConfig.JWKSRefreshInterval)
+	}
+	return m, nil
+}
+
+// helper method to create SecurityManager which makes test easier
+func newJWTSecurityManager(mConfig *authConfig) (*SecurityManager, error) {
+	if err := validateConfig(mConfig); err != nil {
+		return nil, err
+	}
+
+	roles, err := constructRoles(mConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultUser *user
+	if len(mConfig.DefaultRole) != 0 {
+		defaultUser = &user{roles: []*role{roles[mConfig.DefaultRole]}}
+	}
+
+	httpClient := &http.Client{Timeout: _jwksFetchTimeout}
+	keys, err := loadKeySet(mConfig.JWKS, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	usernameClaim := mConfig.UsernameClaim
+	if len(usernameClaim) == 0 {
+		usernameClaim = _defaultUsernameClaim
+	}
+
+	rolesClaim := mConfig.RolesClaim
+	if len(rolesClaim) == 0 {
+		rolesClaim = _defaultRolesClaim
+	}
+
+	return &SecurityManager{
+		keys:       keys,
+		jwksSource: mConfig.JWKS,
+		httpClient: httpClient,
+		verifier: &verifier{
+			issuer:    mConfig.Issuer,
+			audience:  mConfig.Audience,
+			clockSkew: mConfig.ClockSkew,
+			now:       time.Now,
+		},
+		usernameClaim: usernameClaim,
+		rolesClaim:    rolesClaim,
+		roles:         roles,
+		defaultUser:   defaultUser,
+		lifeCycle:     lifecycle.NewLifeCycle(),
+	}, nil
+}
+
+func parseConfig(configPath string) (*authConfig, error) {
+	mConfig := &authConfig{}
+	if err := config.Parse(mConfig, configPath); err != nil {
+		return nil, err
+	}
+	return mConfig, nil
+}
+
+func validateConfig(mConfig *authConfig) error {
+	if len(mConfig.Issuer) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("no issuer specified")
+	}
+
+	if len(mConfig.Audience) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("no audience specified")
+	}
+
+	if len(mConfig.JWKS) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("no jwks specified")
+	}
+
+	if len(mConfig.DefaultRole) == 0 {
+		return nil
+	}
+
+	for _, rConfig := range mConfig.Roles {
+		if rConfig.Role == mConfig.DefaultRole {
+			return nil
+		}
+	}
+	return yarpcerrors.InvalidArgumentErrorf(
+		"undefined default role: %s", mConfig.DefaultRole)
+}
+
+func constructRoles(mConfig *authConfig) (map[string]*role, error) {
+	roles := make(map[string]*role)
+	for _, rConfig := range mConfig.Roles {
+		if _, ok := roles[rConfig.Role]; ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"same Role defined more than once. Role:%s",
+				rConfig.Role,
+			)
+		}
+
+		r := &role{role: rConfig.Role}
+		for _, accept := range rConfig.Accept {
+			parsed, err := rule.Parse(accept)
+			if err != nil {
+				return nil, err
+			}
+			r.accepts = append(r.accepts, parsed)
+		}
+		for _, reject := range rConfig.Reject {
+			parsed, err := rule.Parse(reject)
+			if err != nil {
+				return nil, err
+			}
+			r.rejects = append(r.rejects, parsed)
+		}
+		roles[rConfig.Role] = r
+	}
+	return roles, nil
+}
@@ -0,0 +1,543 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	_testIssuer   = "https://issuer.example.com"
+	_testAudience = "peloton"
+
+	_jobService = "peloton.api.v1alpha.job.stateless.svc.JobService"
+)
+
+type SecurityManagerTestSuite struct {
+	suite.Suite
+
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	tempDir  string
+	jwksPath string
+	config   *authConfig
+	now      time.Time
+}
+
+func (suite *SecurityManagerTestSuite) SetupSuite() {
+	var err error
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.NoError(err)
+	suite.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.NoError(err)
+}
+
+func (suite *SecurityManagerTestSuite) SetupTest() {
+	var err error
+	suite.tempDir, err = ioutil.TempDir("", "jwt")
+	suite.NoError(err)
+
+	suite.jwksPath = filepath.Join(suite.tempDir, "jwks.json")
+	suite.writeJWKS(suite.jwksPath, map[string]crypto.PublicKey{
+		"rsa": &suite.rsaKey.PublicKey,
+		"ec":  &suite.ecKey.PublicKey,
+	})
+
+	suite.now = time.Now()
+	suite.config = &authConfig{
+		Issuer:      _testIssuer,
+		Audience:    _testAudience,
+		JWKS:        suite.jwksPath,
+		DefaultRole: "reader",
+		RolesClaim:  "realm_access.roles",
+		Roles: []*roleConfig{
+			{Role: "admin", Accept: []string{"*"}},
+			{
+				Role:   "reader",
+				Accept: []string{_jobService + ":Get*"},
+				Reject: []string{_jobService + ":GetJobCache"},
+			},
+		},
+	}
+}
+
+func (suite *SecurityManagerTestSuite) TearDownTest() {
+	os.RemoveAll(suite.tempDir)
+}
+
+func (suite *SecurityManagerTestSuite) writeJWKS(
+	path string, keys map[string]crypto.PublicKey) {
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+
+	var jwks jsonWebKeySet
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, &jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: _keyUseSignature,
+				N:   encode(k.N),
+				E:   encode(big.NewInt(int64(k.E))),
+			})
+		case *ecdsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, &jsonWebKey{
+				Kty: "EC",
+				Kid: kid,
+				Crv: "P-256",
+				X:   encode(k.X),
+				Y:   encode(k.Y),
+			})
+		}
+	}
+
+	data, err := json.Marshal(jwks)
+	suite.NoError(err)
+	suite.NoError(ioutil.WriteFile(path, data, 0644))
+}
+
+// sign returns a compact serialized token signed with the algorithm
+func (suite *SecurityManagerTestSuite) sign(
+	alg string, kid string, c map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		suite.NoError(err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) +
+		"." + encode(c)
+
+	hash := _algorithms[alg]
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	var err error
+	switch alg[:2] {
+	case "RS":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, suite.rsaKey, hash, digest)
+	case "PS":
+		signature, err = rsa.SignPSS(rand.Reader, suite.rsaKey, hash, digest, nil)
+	case "ES":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, suite.ecKey, digest)
+		// r and s are left padded to the size of the curve
+		signature = make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(signature[32-len(rb):32], rb)
+		copy(signature[64-len(sb):], sb)
+	}
+	suite.NoError(err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (suite *SecurityManagerTestSuite) claims(roles ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss": _testIssuer,
+		"aud": []string{"other", _testAudience},
+		"sub": "alice",
+		"exp": suite.now.Add(time.Hour).Unix(),
+		"nbf": suite.now.Add(-time.Minute).Unix(),
+		"realm_access": map[string]interface{}{
+			"roles": roles,
+		},
+	}
+}
+
+func (suite *SecurityManagerTestSuite) newManager() *SecurityManager {
+	m, err := newJWTSecurityManager(suite.config)
+	suite.NoError(err)
+	m.verifier.now = func() time.Time { return suite.now }
+	return m
+}
+
+func (suite *SecurityManagerTestSuite) authenticate(
+	m *SecurityManager, raw string) (*user, error) {
+	u, err := m.Authenticate(&jwtToken{items: map[string]string{
+		_authorizationHeaderKey: "Bearer " + raw,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return u.(*user), nil
+}
+
+// TestAuthenticate tests tokens signed with the supported algorithms
+func (suite *SecurityManagerTestSuite) TestAuthenticate() {
+	m := suite.newManager()
+
+	tests := []struct {
+		alg string
+		kid string
+	}{
+		{alg: "RS256", kid: "rsa"},
+		{alg: "RS512", kid: "rsa"},
+		{alg: "PS256", kid: "rsa"},
+		{alg: "ES256", kid: "ec"},
+	}
+
+	for _, test := range tests {
+		u, err := suite.authenticate(
+			m, suite.sign(test.alg, test.kid, suite.claims("admin")))
+		suite.NoError(err, test.alg)
+		suite.Equal("alice", u.username)
+		suite.True(u.IsPermitted(_jobService + "::DeleteJob"))
+	}
+}
+
+// TestAuthenticateFailure tests tokens which fail verification
+func (suite *SecurityManagerTestSuite) TestAuthenticateFailure() {
+	m := suite.newManager()
+
+	expired := suite.claims("admin")
+	expired["exp"] = suite.now.Add(-time.Hour).Unix()
+
+	noExpiry := suite.claims("admin")
+	delete(noExpiry, "exp")
+
+	notYetValid := suite.claims("admin")
+	notYetValid["nbf"] = suite.now.Add(time.Hour).Unix()
+
+	wrongIssuer := suite.claims("admin")
+	wrongIssuer["iss"] = "https://other.example.com"
+
+	wrongAudience := suite.claims("admin")
+	wrongAudience["aud"] = "other"
+
+	noSubject := suite.claims("admin")
+	delete(noSubject, "sub")
+
+	valid := suite.sign("RS256", "rsa", suite.claims("admin"))
+	tampered := suite.sign("RS256", "rsa", suite.claims("reader"))
+	tampered = tampered[:len(tampered)-4] + valid[len(valid)-4:]
+
+	tests := []string{
+		suite.sign("RS256", "rsa", expired),
+		suite.sign("RS256", "rsa", noExpiry),
+		suite.sign("RS256", "rsa", notYetValid),
+		suite.sign("RS256", "rsa", wrongIssuer),
+		suite.sign("RS256", "rsa", wrongAudience),
+		suite.sign("RS256", "rsa", noSubject),
+		suite.sign("RS256", "unknown", suite.claims("admin")),
+		// key of a different type than the algorithm
+		suite.sign("ES256", "rsa", suite.claims("admin")),
+		tampered,
+		"not.a.token",
+		"invalid",
+	}
+
+	for _, raw := range tests {
+		u, err := suite.authenticate(m, raw)
+		suite.Nil(u)
+		suite.Error(err)
+	}
+}
+
+// TestAuthenticateAlgorithmMismatch tests that tokens signed with
+// another algorithm than the one declared for the key are rejected
+func (suite *SecurityManagerTestSuite) TestAuthenticateAlgorithmMismatch() {
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	data, err := json.Marshal(&jsonWebKeySet{Keys: []*jsonWebKey{{
+		Kty: "RSA",
+		Kid: "rsa",
+		Alg: "RS256",
+		N:   encode(suite.rsaKey.PublicKey.N),
+		E:   encode(big.NewInt(int64(suite.rsaKey.PublicKey.E))),
+	}}})
+	suite.NoError(err)
+	suite.NoError(ioutil.WriteFile(suite.jwksPath, data, 0644))
+	m := suite.newManager()
+
+	_, err = suite.authenticate(
+		m, suite.sign("RS256", "rsa", suite.claims("admin")))
+	suite.NoError(err)
+
+	for _, alg := range []string{"RS512", "PS256"} {
+		u, err := suite.authenticate(
+			m, suite.sign(alg, "rsa", suite.claims("admin")))
+		suite.Nil(u, alg)
+		suite.Error(err, alg)
+	}
+}
+
+// TestVerifySignatureCurve tests that the ECDSA algorithms
+// are only accepted with the curve of the matching size
+func (suite *SecurityManagerTestSuite) TestVerifySignatureCurve() {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	suite.NoError(err)
+
+	signed := []byte("header.claims")
+	sign := func(hash crypto.Hash) []byte {
+		h := hash.New()
+		h.Write(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+		suite.NoError(err)
+		// r and s are left padded to the size of the curve
+		signature := make([]byte, 96)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(signature[48-len(rb):48], rb)
+		copy(signature[96-len(sb):], sb)
+		return signature
+	}
+
+	suite.NoError(verifySignature(
+		"ES384", crypto.SHA384, &key.PublicKey, signed, sign(crypto.SHA384)))
+	suite.Error(verifySignature(
+		"ES256", crypto.SHA256, &key.PublicKey, signed, sign(crypto.SHA256)))
+}
+
+// TestAuthenticateUnsignedToken tests that unsigned tokens
+// and tokens signed with a shared secret are rejected
+func (suite *SecurityManagerTestSuite) TestAuthenticateUnsignedToken() {
+	m := suite.newManager()
+
+	parts := strings.Split(suite.sign("RS256", "rsa", suite.claims("admin")), ".")
+	for _, alg := range []string{"none", "HS256"} {
+		header, err := json.Marshal(map[string]string{"alg": alg, "kid": "rsa"})
+		suite.NoError(err)
+		encoded := base64.RawURLEncoding.EncodeToString(header)
+
+		for _, raw := range []string{
+			encoded + "." + parts[1] + "." + parts[2],
+			encoded + "." + parts[1] + ".",
+		} {
+			u, err := suite.authenticate(m, raw)
+			suite.Nil(u)
+			suite.Error(err)
+		}
+	}
+}
+
+// TestClockSkew tests that tokens expired within the clock skew are accepted
+func (suite *SecurityManagerTestSuite) TestClockSkew() {
+	suite.config.ClockSkew = time.Minute
+	m := suite.newManager()
+
+	c := suite.claims("admin")
+	c["exp"] = suite.now.Add(-30 * time.Second).Unix()
+
+	_, err := suite.authenticate(m, suite.sign("RS256", "rsa", c))
+	suite.NoError(err)
+}
+
+// TestAuthenticateDefaultUser tests requests without a token
+func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUser() {
+	m := suite.newManager()
+
+	u, err := m.Authenticate(&jwtToken{items: map[string]string{}})
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_jobService + "::GetJob"))
+	suite.False(u.IsPermitted(_jobService + "::GetJobCache"))
+	suite.False(u.IsPermitted(_jobService + "::DeleteJob"))
+
+	suite.config.DefaultRole = ""
+	m = suite.newManager()
+	u, err = m.Authenticate(&jwtToken{items: map[string]string{}})
+	suite.Nil(u)
+	suite.Error(err)
+
+	u, err = m.Authenticate(&jwtToken{items: map[string]string{
+		_authorizationHeaderKey: "Basic dXNlcjpwYXNzd29yZA==",
+	}})
+	suite.Nil(u)
+	suite.Error(err)
+}
+
+// TestIsPermitted tests that the roles in the claims are mapped to the user
+func (suite *SecurityManagerTestSuite) TestIsPermitted() {
+	m := suite.newManager()
+
+	u, err := suite.authenticate(
+		m, suite.sign("RS256", "rsa", suite.claims("reader", "undefined")))
+	suite.NoError(err)
+	suite.Len(u.roles, 1)
+	suite.True(u.IsPermitted(_jobService + "::GetJob"))
+	suite.False(u.IsPermitted(_jobService + "::GetJobCache"))
+	suite.False(u.IsPermitted(_jobService + "::ReplaceJob"))
+	suite.False(u.IsPermitted("invalid"))
+
+	u, err = suite.authenticate(
+		m, suite.sign("RS256", "rsa", suite.claims("reader", "admin")))
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_jobService + "::GetJobCache"))
+
+	// user without any known role is authenticated but not permitted
+	u, err = suite.authenticate(m, suite.sign("RS256", "rsa", suite.claims()))
+	suite.NoError(err)
+	suite.False(u.IsPermitted(_jobService + "::GetJob"))
+}
+
+// TestRedactToken tests that the bearer token is removed
+func (suite *SecurityManagerTestSuite) TestRedactToken() {
+	m := suite.newManager()
+
+	token := &jwtToken{items: map[string]string{
+		_authorizationHeaderKey: "Bearer token",
+		"other":                 "value",
+	}}
+	m.RedactToken(token)
+
+	_, ok := token.Get(_authorizationHeaderKey)
+	suite.False(ok)
+	_, ok = token.Get("other")
+	suite.True(ok)
+}
+
+// TestJWKSFromURL tests loading the key set from a URL
+func (suite *SecurityManagerTestSuite) TestJWKSFromURL() {
+	data, err := ioutil.ReadFile(suite.jwksPath)
+	suite.NoError(err)
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/jwks" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}))
+	defer server.Close()
+
+	suite.config.JWKS = server.URL + "/jwks"
+	m := suite.newManager()
+
+	_, err = suite.authenticate(m, suite.sign("ES256", "ec", suite.claims("admin")))
+	suite.NoError(err)
+
+	suite.config.JWKS = server.URL + "/missing"
+	_, err = newJWTSecurityManager(suite.config)
+	suite.Error(err)
+}
+
+// TestRefreshKeys tests that rotated keys are picked up
+func (suite *SecurityManagerTestSuite) TestRefreshKeys() {
+	m := suite.newManager()
+	m.startRefresh(10 * time.Millisecond)
+	defer m.Stop()
+
+	raw := suite.sign("RS256", "rotated", suite.claims("admin"))
+	_, err := suite.authenticate(m, raw)
+	suite.Error(err)
+
+	suite.writeJWKS(suite.jwksPath, map[string]crypto.PublicKey{
+		"rotated": &suite.rsaKey.PublicKey,
+	})
+
+	for i := 0; i < 100; i++ {
+		if _, err = suite.authenticate(m, raw); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	suite.NoError(err)
+
+	// invalid key set does not replace the keys
+	suite.NoError(ioutil.WriteFile(suite.jwksPath, []byte("invalid"), 0644))
+	time.Sleep(50 * time.Millisecond)
+	_, err = suite.authenticate(m, raw)
+	suite.NoError(err)
+}
+
+// TestInvalidConfig tests that invalid configs are rejected
+func (suite *SecurityManagerTestSuite) TestInvalidConfig() {
+	tests := []func(c *authConfig){
+		func(c *authConfig) { c.Issuer = "" },
+		func(c *authConfig) { c.Audience = "" },
+		func(c *authConfig) { c.JWKS = "" },
+		func(c *authConfig) { c.JWKS = filepath.Join(suite.tempDir, "missing") },
+		func(c *authConfig) { c.DefaultRole = "undefined" },
+		func(c *authConfig) { c.Roles = append(c.Roles, &roleConfig{Role: "admin"}) },
+		func(c *authConfig) {
+			c.Roles = append(c.Roles, &roleConfig{
+				Role:   "invalid",
+				Accept: []string{"service:method:invalid"},
+			})
+		},
+	}
+
+	for _, test := range tests {
+		c := *suite.config
+		c.Roles = append([]*roleConfig{}, suite.config.Roles...)
+		test(&c)
+		_, err := newJWTSecurityManager(&c)
+		suite.Error(err)
+	}
+}
+
+// TestParseKeySet tests parsing invalid key sets
+func (suite *SecurityManagerTestSuite) TestParseKeySet() {
+	tests := []string{
+		`invalid`,
+		`{"keys": []}`,
+		`{"keys": [{"kty": "oct", "kid": "a"}]}`,
+		`{"keys": [{"kty": "RSA", "kid": "a", "n": "", "e": "AQAB"}]}`,
+		`{"keys": [{"kty": "EC", "kid": "a", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`,
+		`{"keys": [{"kty": "EC", "kid": "a", "crv": "unknown", "x": "AQ", "y": "AQ"}]}`,
+		`{"keys": [{"kty": "RSA", "kid": "a", "alg": "HS256", "n": "AQ", "e": "AQAB"}]}`,
+		// encryption keys are ignored
+		`{"keys": [{"kty": "RSA", "kid": "a", "use": "enc", "n": "AQ", "e": "AQAB"}]}`,
+	}
+
+	for _, test := range tests {
+		_, err := parseKeySet([]byte(test))
+		suite.Error(err, test)
+	}
+}
+
+// TestParseKeySetSkipUnsupportedKeys tests that keys with an unsupported
+// type, curve or algorithm are skipped instead of failing the key set
+func (suite *SecurityManagerTestSuite) TestParseKeySetSkipUnsupportedKeys() {
+	keys, err := parseKeySet([]byte(`{"keys": [
+		{"kty": "oct", "kid": "a", "k": "AQ"},
+		{"kty": "OKP", "kid": "b", "crv": "Ed25519", "x": "AQ"},
+		{"kty": "EC", "kid": "c", "crv": "secp256k1", "x": "AQ", "y": "AQ"},
+		{"kty": "RSA", "kid": "d", "alg": "RSA-OAEP", "n": "AQ", "e": "AQAB"},
+		{"kty": "RSA", "kid": "e", "alg": "RS256", "n": "AQ", "e": "AQAB"},
+		{"kty": "EC", "kid": "f", "alg": "ES256", "crv": "P-384", "x": "AQ", "y": "AQ"}
+	]}`))
+	suite.NoError(err)
+	suite.Len(keys, 1)
+	_, ok := keys.get("e")
+	suite.True(ok)
+}
+
+func TestSecurityManager(t *testing.T) {
+	suite.Run(t, new(SecurityManagerTestSuite))
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	_claimIssuer    = "iss"
+	_claimAudience  = "aud"
+	_claimExpiry    = "exp"
+	_claimNotBefore = "nbf"
+
+	_claimSeparator = "."
+)
+
+// signing algorithms supported to verify tokens, symmetric
+// algorithms and "none" are rejected on purpose
+var _algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// curves of the ECDSA algorithms, the hash of each algorithm is
+// only used with the curve of the matching size, see RFC 7518 §3.4
+var _ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// claims are the claims carried by a verified token
+type claims map[string]interface{}
+
+// verifier verifies the signature and the registered claims of tokens
+type verifier struct {
+	issuer    string
+	audience  string
+	clockSkew time.Duration
+	now       func() time.Time
+}
+
+// verify verifies the compact serialized token with the key set,
+// and returns the claims of the token
+func (v *verifier) verify(raw string, keys keySet) (claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+
+	hash, ok := _algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	key, ok := keys.get(header.Kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+
+	// the algorithm of the token header is not trusted, it must be
+	// the one the key is declared for, see RFC 8725 §3.1
+	if len(key.alg) != 0 && key.alg != header.Alg {
+		return nil, fmt.Errorf(
+			"signing algorithm %q does not match the key %q",
+			header.Alg, header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token signature")
+	}
+
+	if err := verifySignature(
+		header.Alg,
+		hash,
+		key.key,
+		[]byte(parts[0]+"."+parts[1]),
+		signature,
+	); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+
+	if err := v.verifyClaims(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func verifySignature(
+	alg string,
+	hash crypto.Hash,
+	key crypto.PublicKey,
+	signed []byte,
+	signature []byte,
+) error {
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	invalidErr := errors.New("invalid token signature")
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("signing key is not valid for %s", alg)
+		}
+		var err error
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+		}
+		if err != nil {
+			return invalidErr
+		}
+		return nil
+
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().Name != _ecdsaCurves[alg] {
+			return fmt.Errorf("signing key is not valid for %s", alg)
+		}
+		// signature is the concatenation of fixed size r and s
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return invalidErr
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return invalidErr
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported signing algorithm %q", alg)
+}
+
+// verifyClaims checks the issuer, audience and validity period of
+// the token. Tokens without expiry are rejected.
+func (v *verifier) verifyClaims(c claims) error {
+	if iss, _ := c[_claimIssuer].(string); iss != v.issuer {
+		return fmt.Errorf("unexpected token issuer %q", iss)
+	}
+
+	if !containsString(c.strings(_claimAudience), v.audience) {
+		return errors.New("token is not issued for the audience")
+	}
+
+	now := v.now()
+
+	exp, ok := c.time(_claimExpiry)
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.After(exp.Add(v.clockSkew)) {
+		return errors.New("token is expired")
+	}
+
+	if nbf, ok := c.time(_claimNotBefore); ok &&
+		now.Add(v.clockSkew).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	return nil
+}
+
+// get returns the claim with the name, nested claims
+// are separated by _claimSeparator
+func (c claims) get(name string) (interface{}, bool) {
+	var value interface{} = map[string]interface{}(c)
+	for _, key := range strings.Split(name, _claimSeparator) {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// strings returns the claim as a list of strings, the claim can
+// either be a string or an array of strings
+func (c claims) strings(name string) []string {
+	value, ok := c.get(name)
+	if !ok {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// time returns the claim of NumericDate type as time
+func (c claims) time(name string) (time.Time, bool) {
+	value, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := value.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -38,6 +38,12 @@ func (u *noopUser) IsPermitted(procedure string) bool {
 	return true
 }
 
+// Name returns the empty name of the default user,
+// since the users are not identified
+func (u *noopUser) Name() string {
+	return ""
+}
+
 // NewNoopSecurityManager returns SecurityManager
 func NewNoopSecurityManager() *SecurityManager {
 	return &SecurityManager{}
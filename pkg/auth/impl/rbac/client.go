@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/uber/peloton/pkg/auth"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// SecurityClient returns token which authenticates internal
+// communication when rbac auth is enabled
+type SecurityClient struct {
+	token *rbacToken
+}
+
+// GetToken returns a token for rbac auth
+func (c *SecurityClient) GetToken() auth.Token {
+	return c.token
+}
+
+type rbacToken struct {
+	items map[string]string
+}
+
+func (t *rbacToken) Get(k string) (string, bool) {
+	result, ok := t.items[k]
+	return result, ok
+}
+
+func (t *rbacToken) Items() map[string]string {
+	return t.items
+}
+
+func (t *rbacToken) Del(k string) {
+	delete(t.items, k)
+}
+
+// NewRBACSecurityClient returns SecurityClient
+func NewRBACSecurityClient(configPath string) (*SecurityClient, error) {
+	pConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newRBACSecurityClient(pConfig)
+}
+
+// helper method to create SecurityClient which makes test easier
+func newRBACSecurityClient(pConfig *policyConfig) (*SecurityClient, error) {
+	if _, err := newPolicy(pConfig); err != nil {
+		return nil, err
+	}
+
+	for _, uConfig := range pConfig.Users {
+		if uConfig.Username == pConfig.InternalUser {
+			return &SecurityClient{
+				token: &rbacToken{
+					items: map[string]string{
+						_usernameHeaderKey: uConfig.Username,
+						_passwordHeaderKey: uConfig.Password,
+					},
+				},
+			}, nil
+		}
+	}
+
+	return nil, yarpcerrors.InvalidArgumentErrorf("no password found for internal user")
+}
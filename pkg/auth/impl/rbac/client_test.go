@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SecurityClientTestSuite struct {
+	suite.Suite
+
+	c *SecurityClient
+}
+
+func (suite *SecurityClientTestSuite) SetupTest() {
+	c, err := NewRBACSecurityClient(_testConfigPath)
+	suite.NoError(err)
+	suite.c = c
+}
+
+func (suite *SecurityClientTestSuite) TestRBACSecurityClientGetToken() {
+	t := suite.c.GetToken()
+
+	password, ok := t.Get(_passwordHeaderKey)
+	suite.True(ok)
+	suite.Equal("admin-password", password)
+
+	username, ok := t.Get(_usernameHeaderKey)
+	suite.True(ok)
+	suite.Equal("admin", username)
+
+	suite.Len(t.Items(), 2)
+}
+
+func (suite *SecurityClientTestSuite) TestCreateRBACSecurityClientInvalidUserFailure() {
+	config, err := parseConfig(_testConfigPath)
+	suite.NoError(err)
+
+	// change user to one which has no root privilege
+	config.InternalUser = "alice"
+
+	c, err := newRBACSecurityClient(config)
+	suite.Nil(c)
+	suite.Error(err)
+}
+
+func TestSecurityClientTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityClientTestSuite))
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+type policyConfig struct {
+	Users        []*userConfig
+	Roles        []*roleConfig
+	InternalUser string `yaml:"internal_user"`
+}
+
+type userConfig struct {
+	Username string
+	Password string
+	Roles    []string
+}
+
+type roleConfig struct {
+	Role   string
+	Grants []*grantConfig
+	Reject []string
+}
+
+// grantConfig grants the procedures on the resources in any of the
+// resource pools, and owned by any of the owners. A grant without
+// resource pools or owners is not scoped by them.
+type grantConfig struct {
+	Procedures    []string
+	ResourcePools []string `yaml:"resource_pools"`
+	Owners        []string
+}
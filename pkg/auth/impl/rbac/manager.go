@@ -0,0 +1,560 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/rule"
+	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// expected fields passed by token
+	_usernameHeaderKey = "username"
+	_passwordHeaderKey = "password"
+
+	_resourcePoolSeparator = "/"
+)
+
+// SecurityManager uses Username and Password for authentication, and
+// roles which grant procedures on a subset of the resource pools and
+// job owners for authorization. The policy is reloaded from the policy
+// file when the file changes.
+type SecurityManager struct {
+	sync.RWMutex
+
+	configPath string
+	// modification time of the policy file the policy was loaded from
+	modTime time.Time
+	policy  *policy
+
+	lifeCycle lifecycle.LifeCycle
+}
+
+// policy is immutable after init, a new policy
+// is created when the policy file is reloaded
+type policy struct {
+	defaultUser *user
+	users       map[string]*user
+	// name of the user which authenticates internal communication
+	internalUser string
+}
+
+type user struct {
+	username string
+	roles    []*role
+	// store the Password in hashed way,
+	// so it is not exposed by mem dump.
+	hashedPassword []byte
+}
+
+type role struct {
+	role    string
+	grants  []*grant
+	rejects []*rule.Rule
+}
+
+type grant struct {
+	procedures    []*rule.Rule
+	resourcePools []string
+	owners        []string
+}
+
+var _ auth.SecurityManager = &SecurityManager{}
+var _ auth.NamedUser = &user{}
+var _ auth.ResourceUser = &user{}
+
+// Authenticate authenticates a user,
+// it expects to Accept UsernamePasswordToken. If the token authenticates
+// the internal user and carries a forwarded user, the forwarded user
+// is returned, so that calls forwarded by the API server are authorized
+// for the caller instead of the internal user.
+func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
+	authErr := yarpcerrors.UnauthenticatedErrorf("invalid Username/Password combination")
+
+	username, _ := token.Get(_usernameHeaderKey)
+	password, _ := token.Get(_passwordHeaderKey)
+
+	p := m.getPolicy()
+
+	// no Username & Password provided, return default user
+	if len(username) == 0 && len(password) == 0 {
+		if p.defaultUser == nil {
+			return nil, authErr
+		}
+		return p.defaultUser, nil
+	}
+
+	// invalid token format, expect both Username and Password to be provided
+	if len(username) == 0 || len(password) == 0 {
+		return nil, authErr
+	}
+
+	user, ok := p.users[username]
+	if !ok {
+		return nil, authErr
+	}
+
+	if !compareHashedPassword(user.hashedPassword, password) {
+		return nil, authErr
+	}
+
+	if username == p.internalUser {
+		if forwarded, ok := token.Get(auth.ForwardedUserHeaderKey); ok {
+			user, ok := p.lookupUser(forwarded)
+			if !ok {
+				return nil, yarpcerrors.UnauthenticatedErrorf(
+					"unknown forwarded user %q", forwarded)
+			}
+			return user, nil
+		}
+	}
+
+	return user, nil
+}
+
+// LookupUser returns the user with the name, the default user if
+// the name is empty
+func (m *SecurityManager) LookupUser(username string) (auth.User, error) {
+	user, ok := m.getPolicy().lookupUser(username)
+	if !ok {
+		return nil, yarpcerrors.NotFoundErrorf("unknown user %q", username)
+	}
+	return user, nil
+}
+
+// lookupUser returns the user with the name, the default user if
+// the name is empty
+func (p *policy) lookupUser(username string) (auth.User, bool) {
+	if len(username) == 0 {
+		if p.defaultUser == nil {
+			return nil, false
+		}
+		return p.defaultUser, true
+	}
+
+	user, ok := p.users[username]
+	if !ok {
+		return nil, false
+	}
+	return user, true
+}
+
+// RedactToken removes password info from the token
+func (m *SecurityManager) RedactToken(token auth.Token) {
+	token.Del(_passwordHeaderKey)
+}
+
+// Stop stops reloading the policy file
+func (m *SecurityManager) Stop() {
+	if !m.lifeCycle.Stop() {
+		return
+	}
+	m.lifeCycle.Wait()
+}
+
+func (m *SecurityManager) getPolicy() *policy {
+	m.RLock()
+	defer m.RUnlock()
+	return m.policy
+}
+
+// startReload checks the policy file for changes every interval
+func (m *SecurityManager) startReload(interval time.Duration) {
+	if !m.lifeCycle.Start() {
+		return
+	}
+
+	go func() {
+		defer m.lifeCycle.StopComplete()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.lifeCycle.StopCh():
+				return
+			case <-ticker.C:
+				if err := m.reload(); err != nil {
+					log.WithError(err).
+						WithField("path", m.configPath).
+						Error("failed to reload rbac policy, keep using the previous policy")
+				}
+			}
+		}
+	}()
+}
+
+// reload loads the policy from the policy file if it has
+// changed since the policy was last loaded
+func (m *SecurityManager) reload() error {
+	info, err := os.Stat(m.configPath)
+	if err != nil {
+		return err
+	}
+
+	m.RLock()
+	modTime := m.modTime
+	m.RUnlock()
+
+	if info.ModTime().Equal(modTime) {
+		return nil
+	}
+
+	p, err := loadPolicy(m.configPath)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	m.policy = p
+	m.modTime = info.ModTime()
+	m.Unlock()
+
+	log.WithField("path", m.configPath).Info("rbac policy reloaded")
+	return nil
+}
+
+// Name returns the username of user
+func (u *user) Name() string {
+	return u.username
+}
+
+// IsPermitted returns if a procedure is permitted for user on any
+// of the resources. Handlers of procedures which are called on a
+// resource further check if the procedure is permitted on it.
+func (u *user) IsPermitted(procedure string) bool {
+	for _, r := range u.roles {
+		if r.isPermitted(procedure, nil, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermittedOnResource returns if a procedure is permitted for
+// user on the resource
+func (u *user) IsPermittedOnResource(
+	procedure string,
+	resource *auth.Resource) bool {
+	for _, r := range u.roles {
+		if r.isPermitted(procedure, resource, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPermitted returns if the procedure is granted by the role and is
+// not rejected. If scoped is false, grants on any resource are considered.
+func (r *role) isPermitted(
+	procedure string,
+	resource *auth.Resource,
+	scoped bool) bool {
+	if rule.MatchAny(procedure, r.rejects) {
+		return false
+	}
+
+	for _, g := range r.grants {
+		if !rule.MatchAny(procedure, g.procedures) {
+			continue
+		}
+		if !scoped || g.matches(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the resource is in any of the resource
+// pools and owned by any of the owners of the grant
+func (g *grant) matches(resource *auth.Resource) bool {
+	if len(g.resourcePools) > 0 {
+		if resource == nil {
+			return false
+		}
+		var inResourcePool bool
+		for _, resourcePool := range g.resourcePools {
+			if isInResourcePool(resource.ResourcePoolPath, resourcePool) {
+				inResourcePool = true
+				break
+			}
+		}
+		if !inResourcePool {
+			return false
+		}
+	}
+
+	if len(g.owners) > 0 {
+		if resource == nil {
+			return false
+		}
+		var owned bool
+		for _, owner := range g.owners {
+			if owner == resource.Owner {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			return false
+		}
+	}
+	return true
+}
+
+// isInResourcePool returns true if the path is the resource pool
+// or one of its descendants
+func isInResourcePool(path string, resourcePool string) bool {
+	resourcePool = strings.TrimSuffix(resourcePool, _resourcePoolSeparator)
+	if len(resourcePool) == 0 {
+		// root resource pool
+		return true
+	}
+	return path == resourcePool ||
+		strings.HasPrefix(path, resourcePool+_resourcePoolSeparator)
+}
+
+// NewRBACSecurityManager returns SecurityManager. If the reload
+// interval is positive, the policy file is checked for changes
+// every interval.
+func NewRBACSecurityManager(
+	configPath string,
+	reloadInterval time.Duration,
+) (*SecurityManager, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := loadPolicy(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &SecurityManager{
+		configPath: configPath,
+		modTime:    info.ModTime(),
+		policy:     p,
+		lifeCycle:  lifecycle.NewLifeCycle(),
+	}
+
+	if reloadInterval > 0 {
+		m.startReload(reloadInterval)
+	}
+	return m, nil
+}
+
+// helper method to create SecurityManager which makes test easier
+func newRBACSecurityManager(pConfig *policyConfig) (*SecurityManager, error) {
+	p, err := newPolicy(pConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecurityManager{
+		policy:    p,
+		lifeCycle: lifecycle.NewLifeCycle(),
+	}, nil
+}
+
+func loadPolicy(configPath string) (*policy, error) {
+	pConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newPolicy(pConfig)
+}
+
+func parseConfig(configPath string) (*policyConfig, error) {
+	pConfig := &policyConfig{}
+	if err := config.Parse(pConfig, configPath); err != nil {
+		return nil, err
+	}
+	return pConfig, nil
+}
+
+func newPolicy(pConfig *policyConfig) (*policy, error) {
+	roles, err := constructRoles(pConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUsers(pConfig, roles); err != nil {
+		return nil, err
+	}
+
+	p := &policy{
+		users:        make(map[string]*user),
+		internalUser: pConfig.InternalUser,
+	}
+	for _, userConfig := range pConfig.Users {
+		u := &user{
+			username:       userConfig.Username,
+			hashedPassword: generateHashByte(userConfig.Password),
+		}
+		for _, roleName := range userConfig.Roles {
+			u.roles = append(u.roles, roles[roleName])
+		}
+
+		if len(userConfig.Username) == 0 {
+			p.defaultUser = u
+			continue
+		}
+		p.users[userConfig.Username] = u
+	}
+	return p, nil
+}
+
+func constructRoles(pConfig *policyConfig) (map[string]*role, error) {
+	roles := make(map[string]*role)
+	for _, roleConfig := range pConfig.Roles {
+		if _, ok := roles[roleConfig.Role]; ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"same Role defined more than once. Role:%s",
+				roleConfig.Role,
+			)
+		}
+
+		r := &role{role: roleConfig.Role}
+		for _, grantConfig := range roleConfig.Grants {
+			g := &grant{
+				resourcePools: grantConfig.ResourcePools,
+				owners:        grantConfig.Owners,
+			}
+			for _, procedure := range grantConfig.Procedures {
+				parsed, err := rule.Parse(procedure)
+				if err != nil {
+					return nil, err
+				}
+				g.procedures = append(g.procedures, parsed)
+			}
+			r.grants = append(r.grants, g)
+		}
+
+		for _, reject := range roleConfig.Reject {
+			parsed, err := rule.Parse(reject)
+			if err != nil {
+				return nil, err
+			}
+			r.rejects = append(r.rejects, parsed)
+		}
+		roles[roleConfig.Role] = r
+	}
+	return roles, nil
+}
+
+func validateUsers(pConfig *policyConfig, roles map[string]*role) error {
+	var defaultUserCount int
+	userConfigs := make(map[string]*userConfig)
+	for _, userConfig := range pConfig.Users {
+		if len(userConfig.Roles) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf("no Role specified for user")
+		}
+
+		// check if user has roles that are defined in Roles
+		for _, roleName := range userConfig.Roles {
+			if _, ok := roles[roleName]; !ok {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"user: %s has undefined Role: %s",
+					userConfig.Username,
+					roleName,
+				)
+			}
+		}
+
+		if len(userConfig.Password) != 0 && len(userConfig.Username) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf("no Username specified for user")
+		}
+
+		if len(userConfig.Password) == 0 && len(userConfig.Username) != 0 {
+			return yarpcerrors.InvalidArgumentErrorf("no Password specified for user")
+		}
+
+		if len(userConfig.Password) == 0 && len(userConfig.Username) == 0 {
+			defaultUserCount++
+		}
+
+		// only one user can be default user
+		if defaultUserCount > 1 {
+			return yarpcerrors.InvalidArgumentErrorf("more than one default user specified")
+		}
+
+		if _, ok := userConfigs[userConfig.Username]; ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"same user defined more than once. user:%s",
+				userConfig.Username,
+			)
+		}
+		userConfigs[userConfig.Username] = userConfig
+	}
+
+	// validate internal user configs
+	internalUserConfig, ok := userConfigs[pConfig.InternalUser]
+	if !ok || len(pConfig.InternalUser) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("undefined internal user")
+	}
+
+	for _, roleName := range internalUserConfig.Roles {
+		if isRootRole(roles[roleName]) {
+			return nil
+		}
+	}
+	return yarpcerrors.InvalidArgumentErrorf(
+		"role for internal user must grant * on all resources and reject no method")
+}
+
+// isRootRole returns true if the role grants all the
+// procedures on all the resources
+func isRootRole(r *role) bool {
+	if len(r.rejects) != 0 {
+		return false
+	}
+
+	for _, g := range r.grants {
+		if len(g.resourcePools) != 0 || len(g.owners) != 0 {
+			continue
+		}
+		for _, procedure := range g.procedures {
+			if procedure.MatchesAll() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func generateHashByte(password string) []byte {
+	h := sha256.New()
+	io.WriteString(h, password)
+	return h.Sum(nil)
+}
+
+func compareHashedPassword(hashedPassword []byte, password string) bool {
+	return subtle.ConstantTimeCompare(hashedPassword, generateHashByte(password)) == 1
+}
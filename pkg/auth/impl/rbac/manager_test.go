@@ -0,0 +1,373 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_testConfigPath = "testdata/test_rbac_config.yaml"
+
+	_jobService = "peloton.api.v1alpha.job.stateless.svc.JobService"
+	_podService = "peloton.api.v1alpha.pod.svc.PodService"
+)
+
+type SecurityManagerTestSuite struct {
+	suite.Suite
+
+	m *SecurityManager
+}
+
+func (suite *SecurityManagerTestSuite) SetupTest() {
+	m, err := NewRBACSecurityManager(_testConfigPath, 0)
+	suite.NoError(err)
+	suite.m = m
+}
+
+func (suite *SecurityManagerTestSuite) authenticate(
+	username, password string) auth.ResourceUser {
+	items := make(map[string]string)
+	if len(username) != 0 {
+		items[_usernameHeaderKey] = username
+		items[_passwordHeaderKey] = password
+	}
+	user, err := suite.m.Authenticate(&rbacToken{items: items})
+	suite.NoError(err)
+	return user.(auth.ResourceUser)
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateFailure() {
+	tests := []map[string]string{
+		{_usernameHeaderKey: "alice", _passwordHeaderKey: "wrong"},
+		{_usernameHeaderKey: "unknown", _passwordHeaderKey: "password"},
+		{_usernameHeaderKey: "alice"},
+		{_passwordHeaderKey: "alice-password"},
+	}
+
+	for _, items := range tests {
+		user, err := suite.m.Authenticate(&rbacToken{items: items})
+		suite.Nil(user)
+		suite.Error(err)
+	}
+}
+
+func (suite *SecurityManagerTestSuite) TestRedactToken() {
+	token := &rbacToken{items: map[string]string{
+		_usernameHeaderKey: "alice",
+		_passwordHeaderKey: "alice-password",
+	}}
+	suite.m.RedactToken(token)
+	_, ok := token.Get(_passwordHeaderKey)
+	suite.False(ok)
+}
+
+func (suite *SecurityManagerTestSuite) TestRootUser() {
+	user := suite.authenticate("admin", "admin-password")
+	suite.True(user.IsPermitted(_jobService + "::StopJob"))
+	suite.True(user.IsPermitted(_podService + "::StopPod"))
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestDefaultUser() {
+	user := suite.authenticate("", "")
+	suite.True(user.IsPermitted(_jobService + "::GetJob"))
+	suite.True(user.IsPermitted(_jobService + "::ListPods"))
+	suite.False(user.IsPermitted(_jobService + "::GetJobCache"))
+	suite.False(user.IsPermitted(_jobService + "::StopJob"))
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::GetJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestResourcePoolScopedUser() {
+	user := suite.authenticate("alice", "alice-password")
+
+	// the procedure is granted on some of the resource pools
+	suite.True(user.IsPermitted(_jobService + "::StopJob"))
+	suite.False(user.IsPermitted(_podService + "::StopPod"))
+
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA"}))
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA/batch"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamAB"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+	suite.False(user.IsPermittedOnResource(_jobService+"::StopJob", nil))
+
+	// rejected by the reader role but granted by the team role
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::GetJobCache",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::GetJobCache",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+
+	// granted by the reader role on all resources
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::GetJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestOwnerScopedUser() {
+	user := suite.authenticate("bob", "bob-password")
+
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA", Owner: "team-b"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA", Owner: "team-a"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/batch", Owner: "team-b"}))
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::DeleteJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamA", Owner: "team-b"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestForwardedUser() {
+	// the internal user authenticates calls forwarded for the caller
+	user, err := suite.m.Authenticate(&rbacToken{items: map[string]string{
+		_usernameHeaderKey:          "admin",
+		_passwordHeaderKey:          "admin-password",
+		auth.ForwardedUserHeaderKey: "alice",
+	}})
+	suite.NoError(err)
+	suite.Equal("alice", user.(auth.NamedUser).Name())
+	suite.False(user.(auth.ResourceUser).IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+
+	// an empty forwarded user is the default user
+	user, err = suite.m.Authenticate(&rbacToken{items: map[string]string{
+		_usernameHeaderKey:          "admin",
+		_passwordHeaderKey:          "admin-password",
+		auth.ForwardedUserHeaderKey: "",
+	}})
+	suite.NoError(err)
+	suite.False(user.IsPermitted(_jobService + "::StopJob"))
+
+	// unknown forwarded users are not authenticated
+	user, err = suite.m.Authenticate(&rbacToken{items: map[string]string{
+		_usernameHeaderKey:          "admin",
+		_passwordHeaderKey:          "admin-password",
+		auth.ForwardedUserHeaderKey: "unknown",
+	}})
+	suite.Nil(user)
+	suite.Error(err)
+
+	// the forwarded user is only trusted from the internal user
+	user, err = suite.m.Authenticate(&rbacToken{items: map[string]string{
+		_usernameHeaderKey:          "alice",
+		_passwordHeaderKey:          "alice-password",
+		auth.ForwardedUserHeaderKey: "admin",
+	}})
+	suite.NoError(err)
+	suite.Equal("alice", user.(auth.NamedUser).Name())
+}
+
+func (suite *SecurityManagerTestSuite) TestLookupUser() {
+	user, err := suite.m.LookupUser("alice")
+	suite.NoError(err)
+	suite.Equal("alice", user.(auth.NamedUser).Name())
+
+	// an empty name is the default user
+	user, err = suite.m.LookupUser("")
+	suite.NoError(err)
+	suite.False(user.IsPermitted(_jobService + "::StopJob"))
+
+	_, err = suite.m.LookupUser("unknown")
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+func (suite *SecurityManagerTestSuite) TestCreateRBACSecurityManagerErrors() {
+	root := &roleConfig{
+		Role:   "root",
+		Grants: []*grantConfig{{Procedures: []string{"*"}}},
+	}
+	admin := &userConfig{
+		Username: "admin",
+		Password: "password",
+		Roles:    []string{"root"},
+	}
+
+	tests := []struct {
+		msg    string
+		config *policyConfig
+	}{
+		{
+			msg: "invalid rule",
+			config: &policyConfig{
+				Users: []*userConfig{admin},
+				Roles: []*roleConfig{root, {
+					Role: "invalid",
+					Grants: []*grantConfig{
+						{Procedures: []string{"JobService::Stop"}},
+					},
+				}},
+				InternalUser: "admin",
+			},
+		},
+		{
+			msg: "duplicate role",
+			config: &policyConfig{
+				Users:        []*userConfig{admin},
+				Roles:        []*roleConfig{root, root},
+				InternalUser: "admin",
+			},
+		},
+		{
+			msg: "undefined role",
+			config: &policyConfig{
+				Users: []*userConfig{admin, {
+					Username: "user",
+					Password: "password",
+					Roles:    []string{"undefined"},
+				}},
+				Roles:        []*roleConfig{root},
+				InternalUser: "admin",
+			},
+		},
+		{
+			msg: "multiple default users",
+			config: &policyConfig{
+				Users: []*userConfig{
+					admin,
+					{Roles: []string{"root"}},
+					{Roles: []string{"root"}},
+				},
+				Roles:        []*roleConfig{root},
+				InternalUser: "admin",
+			},
+		},
+		{
+			msg: "internal user scoped to a resource pool",
+			config: &policyConfig{
+				Users: []*userConfig{admin},
+				Roles: []*roleConfig{{
+					Role: "root",
+					Grants: []*grantConfig{{
+						Procedures:    []string{"*"},
+						ResourcePools: []string{"/infra"},
+					}},
+				}},
+				InternalUser: "admin",
+			},
+		},
+		{
+			msg: "undefined internal user",
+			config: &policyConfig{
+				Users:        []*userConfig{admin},
+				Roles:        []*roleConfig{root},
+				InternalUser: "undefined",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		m, err := newRBACSecurityManager(test.config)
+		suite.Nil(m, test.msg)
+		suite.Error(err, test.msg)
+	}
+}
+
+func (suite *SecurityManagerTestSuite) TestReload() {
+	dir, err := ioutil.TempDir("", "rbac")
+	suite.NoError(err)
+	defer os.RemoveAll(dir)
+
+	content, err := ioutil.ReadFile(_testConfigPath)
+	suite.NoError(err)
+	path := filepath.Join(dir, "policy.yaml")
+	suite.NoError(ioutil.WriteFile(path, content, 0644))
+
+	m, err := NewRBACSecurityManager(path, 0)
+	suite.NoError(err)
+	suite.m = m
+
+	// the policy is not reloaded if the file has not changed
+	suite.NoError(m.reload())
+	user := suite.authenticate("alice", "alice-password")
+	suite.False(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+
+	// grant team-a the procedure on the resource pool of team b
+	policy := []byte(`
+users:
+- username: admin
+  password: admin-password
+  roles: [root]
+- username: alice
+  password: alice-password
+  roles: [team-a]
+roles:
+- role: root
+  grants:
+  - procedures: ['*']
+- role: team-a
+  grants:
+  - procedures: ['peloton.api.v1alpha.job.stateless.svc.JobService:*']
+    resource_pools: ['/infra/teamA', '/infra/teamB']
+internal_user: admin
+`)
+	suite.NoError(ioutil.WriteFile(path, policy, 0644))
+	suite.NoError(os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	suite.NoError(m.reload())
+	user = suite.authenticate("alice", "alice-password")
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+
+	// an invalid policy is not loaded
+	suite.NoError(ioutil.WriteFile(path, []byte("internal_user: unknown"), 0644))
+	suite.NoError(os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	suite.Error(m.reload())
+	user = suite.authenticate("alice", "alice-password")
+	suite.True(user.IsPermittedOnResource(
+		_jobService+"::StopJob",
+		&auth.Resource{ResourcePoolPath: "/infra/teamB"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestStartStopReload() {
+	m, err := NewRBACSecurityManager(_testConfigPath, time.Millisecond)
+	suite.NoError(err)
+	m.Stop()
+	// stopping twice is a noop
+	m.Stop()
+}
+
+func TestSecurityManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityManagerTestSuite))
+}
@@ -24,8 +24,20 @@ const (
 	NOOP = Type("NOOP")
 	// BASIC would use username and password for auth
 	BASIC = Type("BASIC")
+	// RBAC would use username and password for authentication, and
+	// roles scoped to resource pools and job owners for authorization
+	RBAC = Type("RBAC")
+	// JWT would use bearer tokens signed by an OIDC provider for auth
+	JWT = Type("JWT")
 )
 
+// ForwardedUserHeaderKey is the header in which the API server forwards
+// the name of the authenticated caller along with the internal token, so
+// that the other components authorize the call for the caller. It is
+// empty for the default user, and only trusted on calls authenticated
+// as the internal user.
+const ForwardedUserHeaderKey = "x-peloton-forwarded-user"
+
 // Token is used by SecurityManager to authenticate a user
 type Token interface {
 	Get(k string) (string, bool)
@@ -57,6 +69,40 @@ type User interface {
 	IsPermitted(procedure string) bool
 }
 
+// NamedUser is a User which can be identified by name
+type NamedUser interface {
+	User
+	// Name returns the name of the user, it is empty
+	// for the default user
+	Name() string
+}
+
+// Resource is the entity a procedure is called on, e.g. a job
+type Resource struct {
+	// ResourcePoolPath is the path of the resource pool of the entity
+	ResourcePoolPath string
+	// Owner is the owning team of the entity
+	Owner string
+}
+
+// ResourceUser is a User whose permissions can be
+// scoped to a subset of the resources
+type ResourceUser interface {
+	User
+	// IsPermittedOnResource returns whether user can
+	// access the specified procedure on the resource
+	IsPermittedOnResource(procedure string, resource *Resource) bool
+}
+
+// UserLookup is implemented by the SecurityManagers which can look up
+// users by name, so that work done in the background on behalf of a
+// user, e.g. the runs of cron jobs, is authorized for the user
+type UserLookup interface {
+	// LookupUser returns the user with the name, the default
+	// user if the name is empty
+	LookupUser(username string) (User, error)
+}
+
 // SecurityClient is the internal client used by each of
 // the peloton components to talk to each other.
 // For each SecurityManager there should be a corresponding
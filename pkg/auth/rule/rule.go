@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rule
+
+import (
+	"strings"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_ruleSeparator = ":"
+	// rule that matches all methods under all services
+	_matchAllRule       = "*"
+	_procedureSeparator = "::"
+)
+
+// Rule matches the procedures of a service, a method ending with
+// '*' matches all the methods with the prefix. Rules are immutable
+// after they are parsed.
+type Rule struct {
+	service string
+	method  string
+}
+
+// Parse parses a rule of the format service:method, or '*' which
+// matches all the methods under all the services
+func Parse(r string) (*Rule, error) {
+	if r == _matchAllRule {
+		return &Rule{service: _matchAllRule, method: _matchAllRule}, nil
+	}
+
+	results := strings.Split(r, _ruleSeparator)
+	if len(results) != 2 ||
+		!isValidServiceName(results[0]) ||
+		!isValidMethod(results[1]) {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"rule: %s has unexpected format",
+			r,
+		)
+	}
+	return &Rule{service: results[0], method: results[1]}, nil
+}
+
+// MatchesAll returns true if the rule matches all the methods
+// under all the services
+func (r *Rule) MatchesAll() bool {
+	return r.service == _matchAllRule
+}
+
+// Matches returns true if the rule matches the service and method
+func (r *Rule) Matches(service, method string) bool {
+	if r.service == _matchAllRule {
+		return true
+	}
+
+	if r.service != service {
+		return false
+	}
+
+	if strings.HasSuffix(r.method, _matchAllRule) {
+		return strings.HasPrefix(method, strings.TrimSuffix(r.method, _matchAllRule))
+	}
+	return r.method == method
+}
+
+// MatchAny returns true if any of the rules matches the procedure,
+// which is of the format service::method
+func MatchAny(procedure string, rules []*Rule) bool {
+	results := strings.SplitN(procedure, _procedureSeparator, 2)
+	if len(results) != 2 {
+		return false
+	}
+
+	for _, r := range rules {
+		if r.Matches(results[0], results[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidServiceName(service string) bool {
+	if len(service) == 0 {
+		return false
+	}
+
+	// service name in a rule can have only [a-zA-Z0-9] and '.'
+	for _, r := range service {
+		if (r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') ||
+			r == '.' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func isValidMethod(method string) bool {
+	if len(method) == 0 {
+		return false
+	}
+
+	// method part in a rule can be '*', method_name_prefix + '*' or method name
+	prefix := strings.TrimSuffix(method, _matchAllRule)
+	for _, r := range prefix {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RuleTestSuite struct {
+	suite.Suite
+}
+
+func TestRule(t *testing.T) {
+	suite.Run(t, new(RuleTestSuite))
+}
+
+// TestParse tests parsing valid and invalid rules
+func (suite *RuleTestSuite) TestParse() {
+	tests := []struct {
+		rule      string
+		expectErr bool
+	}{
+		{rule: "peloton.api.v1alpha.job.stateless.svc.JobService:Get*"},
+		{rule: "peloton.api.v1alpha.job.stateless.svc.JobService:*"},
+		{rule: "peloton.api.v1alpha.job.stateless.svc.JobService:GetJob"},
+		{rule: "*"},
+		{
+			rule:      "peloton.api.v1alpha.job.stateless.svc.JobService:Get*Job",
+			expectErr: true,
+		},
+		{rule: "peloton*Service:Get*Job", expectErr: true},
+		{rule: "pelotonService", expectErr: true},
+		{rule: "pelotonService:GetJob:CreateJob", expectErr: true},
+		{rule: ":GetJob", expectErr: true},
+		{rule: "pelotonService:", expectErr: true},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.rule)
+		if test.expectErr {
+			suite.Error(err, test.rule)
+		} else {
+			suite.NoError(err, test.rule)
+		}
+	}
+}
+
+// TestMatchAny tests matching procedures against rules
+func (suite *RuleTestSuite) TestMatchAny() {
+	parse := func(rules ...string) []*Rule {
+		var result []*Rule
+		for _, r := range rules {
+			parsed, err := Parse(r)
+			suite.NoError(err)
+			result = append(result, parsed)
+		}
+		return result
+	}
+
+	tests := []struct {
+		procedure string
+		rules     []*Rule
+		match     bool
+	}{
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob",
+			rules:     parse("*"),
+			match:     true,
+		},
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob",
+			rules:     parse("peloton.api.v1alpha.job.stateless.svc.JobService:Get*"),
+			match:     true,
+		},
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			rules:     parse("peloton.api.v1alpha.job.stateless.svc.JobService:Get*"),
+			match:     false,
+		},
+		{
+			procedure: "peloton.api.v1alpha.pod.svc.PodService::GetPod",
+			rules: parse(
+				"peloton.api.v1alpha.job.stateless.svc.JobService:*",
+				"peloton.api.v1alpha.pod.svc.PodService:GetPod",
+			),
+			match: true,
+		},
+		{
+			procedure: "GetJob",
+			rules:     parse("*"),
+			match:     false,
+		},
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob",
+			match:     false,
+		},
+	}
+
+	for _, test := range tests {
+		suite.Equal(test.match, MatchAny(test.procedure, test.rules), test.procedure)
+	}
+}
+
+// TestMatchesAll tests that only the '*' rule matches all the procedures
+func (suite *RuleTestSuite) TestMatchesAll() {
+	r, err := Parse("*")
+	suite.NoError(err)
+	suite.True(r.MatchesAll())
+
+	r, err = Parse("peloton.api.v1alpha.job.stateless.svc.JobService:*")
+	suite.NoError(err)
+	suite.False(r.MatchesAll())
+}
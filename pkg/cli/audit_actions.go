@@ -0,0 +1,103 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+)
+
+const (
+	auditEventListFormatHeader = "Time\tUser\tCaller\tProcedure\tComponent\t" +
+		"Resources\tOutcome\tError\tLatency (ms)\t\n"
+	auditEventListFormatBody = "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t\n"
+)
+
+// AuditQueryAction is the action to query the audit events of
+// the mutating API calls
+func (c *Client) AuditQueryAction(
+	startTime string,
+	endTime string,
+	user string,
+	procedure string,
+	resource string,
+	outcome string,
+	limit uint32,
+) error {
+	request := &auditsvc.QueryAuditEventsRequest{
+		StartTime: startTime,
+		EndTime:   endTime,
+		User:      user,
+		Procedure: procedure,
+		Resource:  resource,
+		Limit:     limit,
+	}
+
+	if len(outcome) != 0 {
+		value, ok := audit.Outcome_value["OUTCOME_"+strings.ToUpper(outcome)]
+		if !ok {
+			return fmt.Errorf("invalid outcome %s", outcome)
+		}
+		request.Outcome = audit.Outcome(value)
+	}
+
+	response, err := c.auditClient.QueryAuditEvents(c.ctx, request)
+	if err != nil {
+		return err
+	}
+	printAuditQueryResponse(response, c.Debug)
+	return nil
+}
+
+func printAuditQueryResponse(
+	r *auditsvc.QueryAuditEventsResponse,
+	debug bool,
+) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	if len(r.GetEvents()) == 0 {
+		fmt.Fprintf(tabWriter, "No audit event was found\n")
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, auditEventListFormatHeader)
+	for _, event := range r.GetEvents() {
+		var resources []string
+		resources = append(resources, event.GetJobIds()...)
+		resources = append(resources, event.GetPodNames()...)
+		resources = append(resources, event.GetResourcePools()...)
+		resources = append(resources, event.GetHostnames()...)
+
+		fmt.Fprintf(
+			tabWriter,
+			auditEventListFormatBody,
+			event.GetTimestamp(),
+			event.GetUser(),
+			event.GetCaller(),
+			event.GetProcedure(),
+			event.GetComponent(),
+			strings.Join(resources, ","),
+			strings.TrimPrefix(event.GetOutcome().String(), "OUTCOME_"),
+			event.GetErrorCode(),
+			event.GetLatencyMs(),
+		)
+	}
+	tabWriter.Flush()
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	auditmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type auditActionsTestSuite struct {
+	suite.Suite
+	mockCtrl  *gomock.Controller
+	mockAudit *auditmocks.MockAuditServiceYARPCClient
+	ctx       context.Context
+	client    Client
+}
+
+func (suite *auditActionsTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockAudit = auditmocks.NewMockAuditServiceYARPCClient(suite.mockCtrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:       false,
+		auditClient: suite.mockAudit,
+		dispatcher:  nil,
+		ctx:         suite.ctx,
+	}
+}
+
+func (suite *auditActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestAuditActions(t *testing.T) {
+	suite.Run(t, new(auditActionsTestSuite))
+}
+
+// TestAuditQueryAction tests querying the audit events
+func (suite *auditActionsTestSuite) TestAuditQueryAction() {
+	suite.mockAudit.EXPECT().
+		QueryAuditEvents(suite.ctx, &auditsvc.QueryAuditEventsRequest{
+			StartTime: "2019-03-01T00:00:00Z",
+			User:      "alice",
+			Procedure: "StopJob",
+			Resource:  "job-1",
+			Outcome:   audit.Outcome_OUTCOME_FAILED,
+			Limit:     10,
+		}).
+		Return(&auditsvc.QueryAuditEventsResponse{
+			Events: []*audit.AuditEvent{
+				{
+					EventId:   "event-1",
+					Timestamp: "2019-03-01T10:00:00Z",
+					User:      "alice",
+					Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+					JobIds:    []string{"job-1"},
+					Outcome:   audit.Outcome_OUTCOME_FAILED,
+					ErrorCode: "not-found",
+				},
+			},
+		}, nil)
+
+	suite.NoError(suite.client.AuditQueryAction(
+		"2019-03-01T00:00:00Z", "", "alice", "StopJob", "job-1", "failed", 10))
+}
+
+// TestAuditQueryActionNoEvent tests querying the audit
+// events when no event is found
+func (suite *auditActionsTestSuite) TestAuditQueryActionNoEvent() {
+	suite.mockAudit.EXPECT().
+		QueryAuditEvents(suite.ctx, gomock.Any()).
+		Return(&auditsvc.QueryAuditEventsResponse{}, nil)
+
+	suite.NoError(suite.client.AuditQueryAction("", "", "", "", "", "", 0))
+}
+
+// TestAuditQueryActionInvalidOutcome tests querying
+// the audit events with an unknown outcome
+func (suite *auditActionsTestSuite) TestAuditQueryActionInvalidOutcome() {
+	suite.Error(suite.client.AuditQueryAction("", "", "", "", "", "maybe", 0))
+}
+
+// TestAuditQueryActionError tests the failure to query the audit events
+func (suite *auditActionsTestSuite) TestAuditQueryActionError() {
+	suite.mockAudit.EXPECT().
+		QueryAuditEvents(suite.ctx, gomock.Any()).
+		Return(nil, errors.New("query error"))
+
+	suite.Error(suite.client.AuditQueryAction("", "", "", "", "", "", 0))
+}
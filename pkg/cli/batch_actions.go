@@ -0,0 +1,299 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch"
+	batchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	v1alphapod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	v1alphaquery "github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
+	v1alpharespool "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/yarpc/yarpcerrors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BatchJobCreateAction is the action for creating a batch job
+func (c *Client) BatchJobCreateAction(
+	jobID string,
+	respoolPath string,
+	cfg string,
+) error {
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return err
+	}
+	if respoolID == nil {
+		return fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	var jobSpec batch.JobSpec
+	buffer, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to open file %s: %v", cfg, err)
+	}
+	if err := yaml.Unmarshal(buffer, &jobSpec); err != nil {
+		return fmt.Errorf("unable to parse file %s: %v", cfg, err)
+	}
+
+	jobSpec.RespoolId = &v1alphapeloton.ResourcePoolID{Value: respoolID.GetValue()}
+
+	request := &batchsvc.CreateJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: jobID},
+		Spec:  &jobSpec,
+	}
+	response, err := c.batchClient.CreateJob(c.ctx, request)
+
+	printBatchJobCreateResponse(request, response, err, c.Debug)
+
+	return err
+}
+
+// BatchJobGetAction is the action for getting status
+// and spec (or only summary) of a batch job
+func (c *Client) BatchJobGetAction(jobID string, summaryOnly bool) error {
+	resp, err := c.batchClient.GetJob(
+		c.ctx,
+		&batchsvc.GetJobRequest{
+			JobId:       &v1alphapeloton.JobID{Value: jobID},
+			SummaryOnly: summaryOnly,
+		})
+	if err != nil {
+		return err
+	}
+
+	out, err := marshallResponse(defaultResponseFormat, resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v\n", string(out))
+
+	return nil
+}
+
+// BatchJobQueryAction queries batch jobs given the spec
+func (c *Client) BatchJobQueryAction(
+	labels string,
+	respoolPath string,
+	keywords string,
+	states string,
+	owner string,
+	name string,
+	days uint32,
+	limit uint32,
+	maxLimit uint32,
+	offset uint32,
+	sortBy string,
+	sortOrder string) error {
+	pelotonLabels, err := parseLabels(labels)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrderBy(sortBy, sortOrder)
+	if err != nil {
+		return err
+	}
+
+	spec := &stateless.QuerySpec{
+		Pagination: &v1alphaquery.PaginationSpec{
+			Offset:   offset,
+			Limit:    limit,
+			OrderBy:  orderBy,
+			MaxLimit: maxLimit,
+		},
+		Labels:    pelotonLabels,
+		Keywords:  parseKeyWords(keywords),
+		JobStates: parseJobStates(states),
+		Owner:     owner,
+		Name:      name,
+	}
+
+	if len(respoolPath) > 0 {
+		spec.Respool = &v1alpharespool.ResourcePoolPath{
+			Value: respoolPath,
+		}
+	}
+
+	if days > 0 {
+		now := time.Now().UTC()
+		max, err := ptypes.TimestampProto(now)
+		if err != nil {
+			return err
+		}
+		min, err := ptypes.TimestampProto(now.AddDate(0, 0, -int(days)))
+		if err != nil {
+			return err
+		}
+		spec.CreationTimeRange = &v1alphapeloton.TimeRange{Min: min, Max: max}
+	}
+
+	resp, err := c.batchClient.QueryJobs(c.ctx, &batchsvc.QueryJobsRequest{
+		Spec: spec,
+	})
+	if err != nil {
+		return err
+	}
+
+	defer tabWriter.Flush()
+	if len(resp.GetRecords()) == 0 {
+		fmt.Fprintf(tabWriter, "No results found\n")
+		return nil
+	}
+	fmt.Fprint(tabWriter, statelessJobSummaryFormatHeader)
+	for _, r := range resp.GetRecords() {
+		printStatelessQueryResult(r)
+	}
+	return nil
+}
+
+// BatchJobStopAction stops a batch job
+func (c *Client) BatchJobStopAction(jobID string, entityVersion string) error {
+	resp, err := c.batchClient.StopJob(
+		c.ctx,
+		&batchsvc.StopJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: jobID},
+			Version: &v1alphapeloton.EntityVersion{Value: entityVersion},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Job stopped. New EntityVersion: %s\n", resp.GetVersion().GetValue())
+
+	return nil
+}
+
+// BatchJobDeleteAction is the action for deleting a batch job
+func (c *Client) BatchJobDeleteAction(
+	jobID string,
+	version string,
+	forceDelete bool,
+) error {
+	_, err := c.batchClient.DeleteJob(
+		c.ctx,
+		&batchsvc.DeleteJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: jobID},
+			Version: &v1alphapeloton.EntityVersion{Value: version},
+			Force:   forceDelete,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Job deleted\n")
+	return nil
+}
+
+// BatchJobListPodsAction is the action to list pods in a batch job
+func (c *Client) BatchJobListPodsAction(
+	jobID string,
+	instanceRange *task.InstanceRange,
+) error {
+	defer tabWriter.Flush()
+
+	request := &batchsvc.ListPodsRequest{
+		JobId: &v1alphapeloton.JobID{Value: jobID},
+	}
+	if instanceRange != nil {
+		request.Range = &v1alphapod.InstanceIDRange{
+			From: instanceRange.GetFrom(),
+			To:   instanceRange.GetTo(),
+		}
+	}
+
+	stream, err := c.batchClient.ListPods(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(tabWriter, queryPodsFormatHeader)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, pod := range resp.GetPods() {
+			printPod(pod.GetStatus(), pod.GetPodName())
+		}
+	}
+}
+
+// BatchJobGetCacheAction gets the cache of a batch job
+func (c *Client) BatchJobGetCacheAction(jobID string) error {
+	resp, err := c.batchClient.GetJobCache(
+		c.ctx,
+		&batchsvc.GetJobCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: jobID},
+		})
+	if err != nil {
+		return err
+	}
+
+	out, err := marshallResponse(defaultResponseFormat, resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v\n", string(out))
+
+	return nil
+}
+
+func printBatchJobCreateResponse(
+	req *batchsvc.CreateJobRequest,
+	resp *batchsvc.CreateJobResponse,
+	err error,
+	jsonFormat bool,
+) {
+	defer tabWriter.Flush()
+	if jsonFormat {
+		printResponseJSON(resp)
+		return
+	}
+
+	if err != nil {
+		if yarpcerrors.IsAlreadyExists(err) {
+			fmt.Fprintf(tabWriter, "Job %s already exists: %s\n",
+				req.GetJobId().GetValue(), err.Error())
+		} else if yarpcerrors.IsInvalidArgument(err) {
+			fmt.Fprintf(tabWriter, "Invalid job spec: %s\n",
+				err.Error())
+		}
+	} else if resp.GetJobId() != nil {
+		fmt.Fprintf(
+			tabWriter,
+			"Job %s created. Entity Version: %s\n",
+			resp.GetJobId().GetValue(),
+			resp.GetVersion().GetValue(),
+		)
+	} else {
+		fmt.Fprint(tabWriter, "Missing job ID in job create response\n")
+	}
+}
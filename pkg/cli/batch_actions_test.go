@@ -0,0 +1,216 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	batchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	batchmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	v1alphapod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const testBatchSpecConfig = "../../example/batch/testspec.yaml"
+
+type batchActionsTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	client      Client
+	ctrl        *gomock.Controller
+	batchClient *batchmocks.MockJobServiceYARPCClient
+	resClient   *respoolmocks.MockResourceManagerYARPCClient
+}
+
+func (suite *batchActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.batchClient = batchmocks.NewMockJobServiceYARPCClient(suite.ctrl)
+	suite.resClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:       false,
+		batchClient: suite.batchClient,
+		resClient:   suite.resClient,
+		dispatcher:  nil,
+		ctx:         suite.ctx,
+	}
+}
+
+func (suite *batchActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestBatchActions(t *testing.T) {
+	suite.Run(t, new(batchActionsTestSuite))
+}
+
+// TestBatchJobCreateActionSuccess tests creating a batch job from a spec file
+func (suite *batchActionsTestSuite) TestBatchJobCreateActionSuccess() {
+	respoolID := uuid.New()
+
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testRespoolPath},
+		}).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: respoolID},
+		}, nil)
+
+	suite.batchClient.EXPECT().
+		CreateJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *batchsvc.CreateJobRequest) {
+			suite.Equal(testJobID, req.GetJobId().GetValue())
+			suite.Equal(respoolID, req.GetSpec().GetRespoolId().GetValue())
+			suite.Equal(uint32(3), req.GetSpec().GetInstanceCount())
+			suite.Equal(uint32(2), req.GetSpec().GetSla().GetMaximumRunningInstances())
+		}).
+		Return(&batchsvc.CreateJobResponse{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		}, nil)
+
+	suite.NoError(suite.client.BatchJobCreateAction(
+		testJobID,
+		testRespoolPath,
+		testBatchSpecConfig,
+	))
+}
+
+// TestBatchJobCreateActionRespoolNotFound tests creating a batch job
+// in a resource pool which does not exist
+func (suite *batchActionsTestSuite) TestBatchJobCreateActionRespoolNotFound() {
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{}, nil)
+
+	suite.Error(suite.client.BatchJobCreateAction(
+		testJobID,
+		testRespoolPath,
+		testBatchSpecConfig,
+	))
+}
+
+// TestBatchJobGetAction tests getting a batch job
+func (suite *batchActionsTestSuite) TestBatchJobGetAction() {
+	suite.batchClient.EXPECT().
+		GetJob(gomock.Any(), &batchsvc.GetJobRequest{
+			JobId:       &v1alphapeloton.JobID{Value: testJobID},
+			SummaryOnly: true,
+		}).
+		Return(&batchsvc.GetJobResponse{}, nil)
+
+	suite.NoError(suite.client.BatchJobGetAction(testJobID, true))
+}
+
+// TestBatchJobQueryAction tests querying batch jobs
+func (suite *batchActionsTestSuite) TestBatchJobQueryAction() {
+	suite.batchClient.EXPECT().
+		QueryJobs(gomock.Any(), gomock.Any()).
+		Return(&batchsvc.QueryJobsResponse{
+			Records: []*stateless.JobSummary{
+				{
+					JobId: &v1alphapeloton.JobID{Value: testJobID},
+					Name:  "test",
+				},
+			},
+		}, nil)
+
+	suite.NoError(suite.client.BatchJobQueryAction(
+		"", "", "", "", "", "", 1, 10, 100, 0, "creation_time", "DESC"))
+}
+
+// TestBatchJobStopActionError tests stopping a batch job when
+// the service returns an error
+func (suite *batchActionsTestSuite) TestBatchJobStopActionError() {
+	suite.batchClient.EXPECT().
+		StopJob(gomock.Any(), &batchsvc.StopJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		}).
+		Return(nil, yarpcerrors.AbortedErrorf("unexpected entity version"))
+
+	suite.Error(suite.client.BatchJobStopAction(testJobID, testEntityVersion))
+}
+
+// TestBatchJobDeleteAction tests deleting a batch job
+func (suite *batchActionsTestSuite) TestBatchJobDeleteAction() {
+	suite.batchClient.EXPECT().
+		DeleteJob(gomock.Any(), &batchsvc.DeleteJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			Force:   true,
+		}).
+		Return(&batchsvc.DeleteJobResponse{}, nil)
+
+	suite.NoError(suite.client.BatchJobDeleteAction(testJobID, testEntityVersion, true))
+}
+
+// TestBatchJobListPodsAction tests listing the pods of a batch job
+func (suite *batchActionsTestSuite) TestBatchJobListPodsAction() {
+	stream := batchmocks.NewMockJobServiceServiceListPodsYARPCClient(suite.ctrl)
+
+	suite.batchClient.EXPECT().
+		ListPods(gomock.Any(), &batchsvc.ListPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+			Range: &v1alphapod.InstanceIDRange{From: 0, To: 5},
+		}).
+		Return(stream, nil)
+
+	gomock.InOrder(
+		stream.EXPECT().
+			Recv().
+			Return(&batchsvc.ListPodsResponse{
+				Pods: []*v1alphapod.PodSummary{
+					{
+						PodName: &v1alphapeloton.PodName{Value: testJobID + "-0"},
+						Status: &v1alphapod.PodStatus{
+							State: v1alphapod.PodState_POD_STATE_RUNNING,
+						},
+					},
+				},
+			}, nil),
+		stream.EXPECT().
+			Recv().
+			Return(nil, io.EOF),
+	)
+
+	suite.NoError(suite.client.BatchJobListPodsAction(
+		testJobID,
+		&task.InstanceRange{From: 0, To: 5},
+	))
+}
+
+// TestBatchJobGetCacheAction tests getting the cache of a batch job
+func (suite *batchActionsTestSuite) TestBatchJobGetCacheAction() {
+	suite.batchClient.EXPECT().
+		GetJobCache(gomock.Any(), &batchsvc.GetJobCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		}).
+		Return(&batchsvc.GetJobCacheResponse{}, nil)
+
+	suite.NoError(suite.client.BatchJobGetCacheAction(testJobID))
+}
@@ -23,6 +23,8 @@ import (
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/transport/grpc"
 
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
 	hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -30,6 +32,8 @@ import (
 	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	volume_svc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
 	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	batchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -49,6 +53,7 @@ type Client struct {
 	taskClient      task.TaskManagerYARPCClient
 	podClient       podsvc.PodServiceYARPCClient
 	statelessClient statelesssvc.JobServiceYARPCClient
+	batchClient     batchsvc.JobServiceYARPCClient
 	watchClient     watchsvc.WatchServiceYARPCClient
 	resClient       respool.ResourceManagerYARPCClient
 	resMgrClient    resmgrsvc.ResourceManagerServiceYARPCClient
@@ -59,6 +64,9 @@ type Client struct {
 	hostClient      hostsvc.HostServiceYARPCClient
 	jobmgrClient    jobmgrsvc.JobManagerServiceYARPCClient
 	adminClient     adminsvc.AdminServiceYARPCClient
+	auditClient     auditsvc.AuditServiceYARPCClient
+	cronClient      cronsvc.CronServiceYARPCClient
+	dagClient       dagsvc.DAGServiceYARPCClient
 	dispatcher      *yarpc.Dispatcher
 	ctx             context.Context
 	cancelFunc      context.CancelFunc
@@ -70,7 +78,7 @@ type Client struct {
 func New(
 	discovery leader.Discovery,
 	timeout time.Duration,
-	authConfig *middleware.BasicAuthConfig,
+	authMiddleware middleware.OutboundMiddleware,
 	debug bool) (*Client, error) {
 
 	jobmgrURL, err := discovery.GetAppURL(common.JobManagerRole)
@@ -90,8 +98,6 @@ func New(
 
 	t := grpc.NewTransport()
 
-	authMiddleware := middleware.NewBasicAuthOutboundMiddleware(authConfig)
-
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name: common.PelotonCLI,
 		Outbounds: yarpc.Outbounds{
@@ -153,6 +159,9 @@ func New(
 		statelessClient: statelesssvc.NewJobServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
+		batchClient: batchsvc.NewJobServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
 		watchClient: watchsvc.NewWatchServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
@@ -162,6 +171,15 @@ func New(
 		adminClient: adminsvc.NewAdminServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
+		auditClient: auditsvc.NewAuditServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		cronClient: cronsvc.NewCronServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		dagClient: dagsvc.NewDAGServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
 		dispatcher: dispatcher,
 		ctx:        ctx,
 		cancelFunc: cancelFunc,
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	cronListFormatHeader = "Name\tSchedule\tPolicy\tPaused\tLast Run\tNext Run\t" +
+		"Active Jobs\tRuns\tSkips\t\n"
+	cronListFormatBody = "%s\t%s\t%s\t%t\t%s\t%s\t%d\t%d\t%d\t\n"
+)
+
+// CronJobCreateAction is the action to create a cron job
+func (c *Client) CronJobCreateAction(
+	name, respoolPath, schedule, policy string,
+	historyLimit uint32,
+	paused bool,
+	cfg string,
+) error {
+	config, err := c.buildCronJobConfig(
+		name, respoolPath, schedule, policy, historyLimit, paused, cfg)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.cronClient.CreateCronJob(
+		c.ctx,
+		&cronsvc.CreateCronJobRequest{Config: config},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// CronJobReplaceAction is the action to replace the configuration
+// of a cron job
+func (c *Client) CronJobReplaceAction(
+	name, respoolPath, schedule, policy string,
+	historyLimit uint32,
+	paused bool,
+	cfg string,
+) error {
+	config, err := c.buildCronJobConfig(
+		name, respoolPath, schedule, policy, historyLimit, paused, cfg)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.cronClient.ReplaceCronJob(
+		c.ctx,
+		&cronsvc.ReplaceCronJobRequest{Config: config},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// CronJobGetAction is the action to get a cron job
+func (c *Client) CronJobGetAction(name string) error {
+	response, err := c.cronClient.GetCronJob(
+		c.ctx,
+		&cronsvc.GetCronJobRequest{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// CronJobListAction is the action to list all cron jobs
+func (c *Client) CronJobListAction() error {
+	response, err := c.cronClient.ListCronJobs(
+		c.ctx,
+		&cronsvc.ListCronJobsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+	printCronJobListResponse(response, c.Debug)
+	return nil
+}
+
+// CronJobDeleteAction is the action to delete a cron job
+func (c *Client) CronJobDeleteAction(name string) error {
+	response, err := c.cronClient.DeleteCronJob(
+		c.ctx,
+		&cronsvc.DeleteCronJobRequest{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// CronJobRunAction is the action to run a cron job immediately
+func (c *Client) CronJobRunAction(name string) error {
+	response, err := c.cronClient.RunCronJob(
+		c.ctx,
+		&cronsvc.RunCronJobRequest{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// buildCronJobConfig reads the job template from the given file and
+// builds the cron job configuration.
+func (c *Client) buildCronJobConfig(
+	name, respoolPath, schedule, policy string,
+	historyLimit uint32,
+	paused bool,
+	cfg string,
+) (*cron.CronJobConfig, error) {
+	concurrencyPolicy, ok := cron.ConcurrencyPolicy_value[strings.ToUpper(policy)]
+	if !ok {
+		return nil, fmt.Errorf("invalid concurrency policy: %s", policy)
+	}
+
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return nil, err
+	}
+	if respoolID == nil {
+		return nil, fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	var jobConfig job.JobConfig
+	buffer, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %s: %v", cfg, err)
+	}
+	if err := yaml.Unmarshal(buffer, &jobConfig); err != nil {
+		return nil, fmt.Errorf("unable to parse file %s: %v", cfg, err)
+	}
+	jobConfig.RespoolID = respoolID
+
+	return &cron.CronJobConfig{
+		Name:              name,
+		Schedule:          schedule,
+		ConcurrencyPolicy: cron.ConcurrencyPolicy(concurrencyPolicy),
+		HistoryLimit:      historyLimit,
+		Paused:            paused,
+		Template:          &jobConfig,
+	}, nil
+}
+
+func printCronJobListResponse(r *cronsvc.ListCronJobsResponse, debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	if len(r.GetCronJobs()) == 0 {
+		fmt.Fprintf(tabWriter, "No cron job was found\n")
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, cronListFormatHeader)
+	for _, cronJob := range r.GetCronJobs() {
+		fmt.Fprintf(
+			tabWriter,
+			cronListFormatBody,
+			cronJob.GetConfig().GetName(),
+			cronJob.GetConfig().GetSchedule(),
+			cronJob.GetConfig().GetConcurrencyPolicy().String(),
+			cronJob.GetConfig().GetPaused(),
+			cronJob.GetRuntime().GetLastScheduleTime(),
+			cronJob.GetRuntime().GetNextScheduleTime(),
+			len(cronJob.GetRuntime().GetActiveJobs()),
+			cronJob.GetRuntime().GetRunCount(),
+			cronJob.GetRuntime().GetSkipCount(),
+		)
+	}
+	tabWriter.Flush()
+}
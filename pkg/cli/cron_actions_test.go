@@ -0,0 +1,211 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	cronmocks "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testCronJobName     = "test-cron"
+	testCronJobSchedule = "*/5 * * * *"
+	testCronRespoolPath = "/testrespool"
+)
+
+type cronActionsTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockCron    *cronmocks.MockCronServiceYARPCClient
+	mockRespool *respoolmocks.MockResourceManagerYARPCClient
+	ctx         context.Context
+	client      Client
+}
+
+func (suite *cronActionsTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockCron = cronmocks.NewMockCronServiceYARPCClient(suite.mockCtrl)
+	suite.mockRespool = respoolmocks.NewMockResourceManagerYARPCClient(
+		suite.mockCtrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:      false,
+		resClient:  suite.mockRespool,
+		cronClient: suite.mockCron,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+}
+
+func (suite *cronActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestCronActions(t *testing.T) {
+	suite.Run(t, new(cronActionsTestSuite))
+}
+
+func (suite *cronActionsTestSuite) withMockResourcePoolLookup() {
+	suite.mockRespool.EXPECT().
+		LookupResourcePoolID(suite.ctx, &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testCronRespoolPath},
+		}).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: "respool-id"},
+		}, nil)
+}
+
+// TestCronJobCreateAction tests creating a cron job
+func (suite *cronActionsTestSuite) TestCronJobCreateAction() {
+	suite.withMockResourcePoolLookup()
+	suite.mockCron.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Do(func(_ context.Context, req *cronsvc.CreateCronJobRequest) {
+			suite.Equal(testCronJobName, req.GetConfig().GetName())
+			suite.Equal(testCronJobSchedule, req.GetConfig().GetSchedule())
+			suite.Equal(cron.ConcurrencyPolicy_REPLACE,
+				req.GetConfig().GetConcurrencyPolicy())
+			suite.Equal(uint32(5), req.GetConfig().GetHistoryLimit())
+			suite.Equal("respool-id",
+				req.GetConfig().GetTemplate().GetRespoolID().GetValue())
+		}).
+		Return(&cronsvc.CreateCronJobResponse{}, nil)
+
+	suite.NoError(suite.client.CronJobCreateAction(
+		testCronJobName, testCronRespoolPath, testCronJobSchedule,
+		"replace", 5, false, testJobConfig,
+	))
+}
+
+// TestCronJobCreateActionInvalidPolicy tests creating a cron job
+// with an unknown concurrency policy
+func (suite *cronActionsTestSuite) TestCronJobCreateActionInvalidPolicy() {
+	suite.Error(suite.client.CronJobCreateAction(
+		testCronJobName, testCronRespoolPath, testCronJobSchedule,
+		"sometimes", 5, false, testJobConfig,
+	))
+}
+
+// TestCronJobCreateActionError tests failure to create a cron job
+func (suite *cronActionsTestSuite) TestCronJobCreateActionError() {
+	suite.withMockResourcePoolLookup()
+	suite.mockCron.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Return(nil, errors.New("already exists"))
+
+	suite.Error(suite.client.CronJobCreateAction(
+		testCronJobName, testCronRespoolPath, testCronJobSchedule,
+		"skip", 0, false, testJobConfig,
+	))
+}
+
+// TestCronJobReplaceAction tests replacing a cron job
+func (suite *cronActionsTestSuite) TestCronJobReplaceAction() {
+	suite.withMockResourcePoolLookup()
+	suite.mockCron.EXPECT().
+		ReplaceCronJob(suite.ctx, gomock.Any()).
+		Do(func(_ context.Context, req *cronsvc.ReplaceCronJobRequest) {
+			suite.True(req.GetConfig().GetPaused())
+		}).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	suite.NoError(suite.client.CronJobReplaceAction(
+		testCronJobName, testCronRespoolPath, testCronJobSchedule,
+		"allow", 0, true, testJobConfig,
+	))
+}
+
+// TestCronJobGetAction tests getting a cron job
+func (suite *cronActionsTestSuite) TestCronJobGetAction() {
+	suite.mockCron.EXPECT().
+		GetCronJob(suite.ctx, &cronsvc.GetCronJobRequest{Name: testCronJobName}).
+		Return(&cronsvc.GetCronJobResponse{}, nil)
+	suite.NoError(suite.client.CronJobGetAction(testCronJobName))
+
+	suite.mockCron.EXPECT().
+		GetCronJob(suite.ctx, &cronsvc.GetCronJobRequest{Name: testCronJobName}).
+		Return(nil, errors.New("not found"))
+	suite.Error(suite.client.CronJobGetAction(testCronJobName))
+}
+
+// TestCronJobListAction tests listing cron jobs
+func (suite *cronActionsTestSuite) TestCronJobListAction() {
+	resp := &cronsvc.ListCronJobsResponse{
+		CronJobs: []*cron.CronJobInfo{
+			{
+				Config: &cron.CronJobConfig{
+					Name:     testCronJobName,
+					Schedule: testCronJobSchedule,
+				},
+				Runtime: &cron.CronJobRuntime{
+					ActiveJobs: []*peloton.JobID{{Value: testJobID}},
+					RunCount:   1,
+				},
+			},
+		},
+	}
+
+	for _, debug := range []bool{false, true} {
+		suite.client.Debug = debug
+		suite.mockCron.EXPECT().
+			ListCronJobs(suite.ctx, &cronsvc.ListCronJobsRequest{}).
+			Return(resp, nil)
+		suite.NoError(suite.client.CronJobListAction())
+	}
+
+	suite.mockCron.EXPECT().
+		ListCronJobs(suite.ctx, &cronsvc.ListCronJobsRequest{}).
+		Return(&cronsvc.ListCronJobsResponse{}, nil)
+	suite.NoError(suite.client.CronJobListAction())
+
+	suite.mockCron.EXPECT().
+		ListCronJobs(suite.ctx, &cronsvc.ListCronJobsRequest{}).
+		Return(nil, errors.New("unavailable"))
+	suite.Error(suite.client.CronJobListAction())
+}
+
+// TestCronJobDeleteAction tests deleting a cron job
+func (suite *cronActionsTestSuite) TestCronJobDeleteAction() {
+	suite.mockCron.EXPECT().
+		DeleteCronJob(suite.ctx, &cronsvc.DeleteCronJobRequest{Name: testCronJobName}).
+		Return(&cronsvc.DeleteCronJobResponse{}, nil)
+	suite.NoError(suite.client.CronJobDeleteAction(testCronJobName))
+}
+
+// TestCronJobRunAction tests running a cron job immediately
+func (suite *cronActionsTestSuite) TestCronJobRunAction() {
+	suite.mockCron.EXPECT().
+		RunCronJob(suite.ctx, &cronsvc.RunCronJobRequest{Name: testCronJobName}).
+		Return(&cronsvc.RunCronJobResponse{
+			JobId: &peloton.JobID{Value: testJobID},
+		}, nil)
+	suite.NoError(suite.client.CronJobRunAction(testCronJobName))
+
+	suite.mockCron.EXPECT().
+		RunCronJob(suite.ctx, &cronsvc.RunCronJobRequest{Name: testCronJobName}).
+		Return(nil, errors.New("leader unavailable"))
+	suite.Error(suite.client.CronJobRunAction(testCronJobName))
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	dagListFormatHeader = "ID\tName\tState\tGoal State\tNodes\tCreation Time\t" +
+		"Completion Time\t\n"
+	dagListFormatBody = "%s\t%s\t%s\t%s\t%d\t%s\t%s\t\n"
+)
+
+// dagFile is the YAML format of a DAG read by the CLI. The job configs
+// of the nodes are paths relative to the DAG file.
+type dagFile struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Nodes       []struct {
+		Name    string `yaml:"name"`
+		Config  string `yaml:"config"`
+		Parents []struct {
+			Parent    string `yaml:"parent"`
+			Condition string `yaml:"condition"`
+		} `yaml:"parents"`
+	} `yaml:"nodes"`
+}
+
+// DAGCreateAction is the action to create a DAG
+func (c *Client) DAGCreateAction(respoolPath, cfg string) error {
+	config, err := c.buildDAGConfig(respoolPath, cfg)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.dagClient.CreateDAG(
+		c.ctx,
+		&dagsvc.CreateDAGRequest{Config: config},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// DAGGetAction is the action to get a DAG
+func (c *Client) DAGGetAction(id string) error {
+	response, err := c.dagClient.GetDAG(
+		c.ctx,
+		&dagsvc.GetDAGRequest{Id: &dag.DAGID{Value: id}},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// DAGListAction is the action to list all DAGs
+func (c *Client) DAGListAction() error {
+	response, err := c.dagClient.ListDAGs(
+		c.ctx,
+		&dagsvc.ListDAGsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+	printDAGListResponse(response, c.Debug)
+	return nil
+}
+
+// DAGCancelAction is the action to cancel a DAG
+func (c *Client) DAGCancelAction(id string) error {
+	response, err := c.dagClient.CancelDAG(
+		c.ctx,
+		&dagsvc.CancelDAGRequest{Id: &dag.DAGID{Value: id}},
+	)
+	if err != nil {
+		return err
+	}
+	printResponseJSON(response)
+	return nil
+}
+
+// buildDAGConfig reads the DAG and the job configs of its nodes from
+// the given file and builds the DAG configuration. All the jobs are
+// created in the given resource pool.
+func (c *Client) buildDAGConfig(
+	respoolPath, cfg string,
+) (*dag.DAGConfig, error) {
+	var file dagFile
+	buffer, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %s: %v", cfg, err)
+	}
+	if err := yaml.Unmarshal(buffer, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse file %s: %v", cfg, err)
+	}
+
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return nil, err
+	}
+	if respoolID == nil {
+		return nil, fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	config := &dag.DAGConfig{
+		Name:        file.Name,
+		Description: file.Description,
+	}
+	for _, n := range file.Nodes {
+		jobFile := n.Config
+		if !filepath.IsAbs(jobFile) {
+			jobFile = filepath.Join(filepath.Dir(cfg), jobFile)
+		}
+
+		var jobConfig job.JobConfig
+		buffer, err := ioutil.ReadFile(jobFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open file %s: %v", jobFile, err)
+		}
+		if err := yaml.Unmarshal(buffer, &jobConfig); err != nil {
+			return nil, fmt.Errorf("unable to parse file %s: %v", jobFile, err)
+		}
+		jobConfig.RespoolID = respoolID
+
+		node := &dag.Node{
+			Name:   n.Name,
+			Config: &jobConfig,
+		}
+		for _, p := range n.Parents {
+			condition := dag.EdgeCondition_ON_SUCCESS
+			if len(p.Condition) > 0 {
+				value, ok := dag.EdgeCondition_value[strings.ToUpper(p.Condition)]
+				if !ok {
+					return nil, fmt.Errorf("invalid condition %s of node %s",
+						p.Condition, n.Name)
+				}
+				condition = dag.EdgeCondition(value)
+			}
+			node.Parents = append(node.Parents, &dag.Edge{
+				Parent:    p.Parent,
+				Condition: condition,
+			})
+		}
+		config.Nodes = append(config.Nodes, node)
+	}
+	return config, nil
+}
+
+func printDAGListResponse(r *dagsvc.ListDAGsResponse, debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	if len(r.GetDags()) == 0 {
+		fmt.Fprintf(tabWriter, "No DAG was found\n")
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, dagListFormatHeader)
+	for _, d := range r.GetDags() {
+		fmt.Fprintf(
+			tabWriter,
+			dagListFormatBody,
+			d.GetId().GetValue(),
+			d.GetConfig().GetName(),
+			strings.TrimPrefix(d.GetRuntime().GetState().String(), "DAG_STATE_"),
+			strings.TrimPrefix(d.GetRuntime().GetGoalState().String(), "DAG_STATE_"),
+			len(d.GetConfig().GetNodes()),
+			d.GetRuntime().GetCreationTime(),
+			d.GetRuntime().GetCompletionTime(),
+		)
+	}
+	tabWriter.Flush()
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	dagmocks "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testDAGConfig      = "../../example/testdag.yaml"
+	testDAGID          = "dag-id"
+	testDAGRespoolPath = "/testrespool"
+)
+
+type dagActionsTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockDAG     *dagmocks.MockDAGServiceYARPCClient
+	mockRespool *respoolmocks.MockResourceManagerYARPCClient
+	ctx         context.Context
+	client      Client
+}
+
+func (suite *dagActionsTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockDAG = dagmocks.NewMockDAGServiceYARPCClient(suite.mockCtrl)
+	suite.mockRespool = respoolmocks.NewMockResourceManagerYARPCClient(
+		suite.mockCtrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:      false,
+		resClient:  suite.mockRespool,
+		dagClient:  suite.mockDAG,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+}
+
+func (suite *dagActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestDAGActions(t *testing.T) {
+	suite.Run(t, new(dagActionsTestSuite))
+}
+
+func (suite *dagActionsTestSuite) withMockResourcePoolLookup() {
+	suite.mockRespool.EXPECT().
+		LookupResourcePoolID(suite.ctx, &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testDAGRespoolPath},
+		}).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: "respool-id"},
+		}, nil)
+}
+
+// TestDAGCreateAction tests creating a DAG
+func (suite *dagActionsTestSuite) TestDAGCreateAction() {
+	suite.withMockResourcePoolLookup()
+	suite.mockDAG.EXPECT().
+		CreateDAG(suite.ctx, gomock.Any()).
+		Do(func(_ context.Context, req *dagsvc.CreateDAGRequest) {
+			config := req.GetConfig()
+			suite.Equal("TestPelotonDAG", config.GetName())
+			suite.Len(config.GetNodes(), 4)
+			for _, node := range config.GetNodes() {
+				suite.Equal("TestPelotonJob_123", node.GetConfig().GetName())
+				suite.Equal("respool-id",
+					node.GetConfig().GetRespoolID().GetValue())
+			}
+			suite.Equal([]*dag.Edge{
+				{Parent: "extract", Condition: dag.EdgeCondition_ON_FAILURE},
+			}, config.GetNodes()[2].GetParents())
+			suite.Equal([]*dag.Edge{
+				{Parent: "transform", Condition: dag.EdgeCondition_ALWAYS},
+				{Parent: "alert", Condition: dag.EdgeCondition_ALWAYS},
+			}, config.GetNodes()[3].GetParents())
+		}).
+		Return(&dagsvc.CreateDAGResponse{
+			Id: &dag.DAGID{Value: testDAGID},
+		}, nil)
+
+	suite.NoError(suite.client.DAGCreateAction(
+		testDAGRespoolPath, testDAGConfig))
+}
+
+// TestDAGCreateActionInvalidCondition tests creating a DAG with an
+// unknown edge condition
+func (suite *dagActionsTestSuite) TestDAGCreateActionInvalidCondition() {
+	dir, err := ioutil.TempDir("", "dag")
+	suite.NoError(err)
+	defer os.RemoveAll(dir)
+
+	jobConfig, err := filepath.Abs(testJobConfig)
+	suite.NoError(err)
+	cfg := filepath.Join(dir, "dag.yaml")
+	suite.NoError(ioutil.WriteFile(cfg, []byte(`
+name: dag
+nodes:
+- name: a
+  config: `+jobConfig+`
+- name: b
+  config: `+jobConfig+`
+  parents:
+  - parent: a
+    condition: sometimes
+`), 0644))
+
+	suite.withMockResourcePoolLookup()
+	suite.Error(suite.client.DAGCreateAction(testDAGRespoolPath, cfg))
+}
+
+// TestDAGCreateActionError tests failure to create a DAG
+func (suite *dagActionsTestSuite) TestDAGCreateActionError() {
+	suite.withMockResourcePoolLookup()
+	suite.mockDAG.EXPECT().
+		CreateDAG(suite.ctx, gomock.Any()).
+		Return(nil, errors.New("DAG has a cycle"))
+
+	suite.Error(suite.client.DAGCreateAction(
+		testDAGRespoolPath, testDAGConfig))
+}
+
+// TestDAGGetAction tests getting a DAG
+func (suite *dagActionsTestSuite) TestDAGGetAction() {
+	suite.mockDAG.EXPECT().
+		GetDAG(suite.ctx, &dagsvc.GetDAGRequest{
+			Id: &dag.DAGID{Value: testDAGID},
+		}).
+		Return(&dagsvc.GetDAGResponse{}, nil)
+
+	suite.NoError(suite.client.DAGGetAction(testDAGID))
+}
+
+// TestDAGListAction tests listing DAGs
+func (suite *dagActionsTestSuite) TestDAGListAction() {
+	suite.mockDAG.EXPECT().
+		ListDAGs(suite.ctx, gomock.Any()).
+		Return(&dagsvc.ListDAGsResponse{
+			Dags: []*dag.DAGInfo{
+				{
+					Id:     &dag.DAGID{Value: testDAGID},
+					Config: &dag.DAGConfig{Name: "dag"},
+					Runtime: &dag.DAGRuntime{
+						State:     dag.DAGState_DAG_STATE_RUNNING,
+						GoalState: dag.DAGState_DAG_STATE_RUNNING,
+					},
+				},
+			},
+		}, nil)
+	suite.NoError(suite.client.DAGListAction())
+
+	suite.mockDAG.EXPECT().
+		ListDAGs(suite.ctx, gomock.Any()).
+		Return(&dagsvc.ListDAGsResponse{}, nil)
+	suite.NoError(suite.client.DAGListAction())
+}
+
+// TestDAGCancelAction tests cancelling a DAG
+func (suite *dagActionsTestSuite) TestDAGCancelAction() {
+	suite.mockDAG.EXPECT().
+		CancelDAG(suite.ctx, &dagsvc.CancelDAGRequest{
+			Id: &dag.DAGID{Value: testDAGID},
+		}).
+		Return(nil, errors.New("not found"))
+
+	suite.Error(suite.client.DAGCancelAction(testDAGID))
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
@@ -36,6 +37,9 @@ const (
 	getHostsFormatBody    = "%s\t%.2f\t%.2f\t%.2f MB\t%.2f MB\t%s\t%s\t%s\n"
 	hostCacheFormatHeader = "Hostname\tCPU\tGPU\tMEM\tDisk\tStatus\n"
 	hostCacheFormatBody   = "%s\t%.2f/%.2f\t%.2f/%.2f\t%.2f/%.2f MB\t%.2f/%.2f MB\t%s\n"
+
+	maintenanceWindowFormatHeader = "ID\tState\tStart Time\tDuration\tHostPool\tHosts\n"
+	maintenanceWindowFormatBody   = "%s\t%s\t%s\t%s\t%s\t%s\n"
 )
 
 // HostCacheDump dumps the contents of the host cache.
@@ -114,6 +118,102 @@ func (c *Client) HostMaintenanceCompleteAction(hostname string) error {
 	return nil
 }
 
+// HostMaintenanceWindowCreateAction is the action for scheduling maintenance
+// of a list of hosts and/or of the hosts of a host pool. Maintenance is
+// started at the start time, immediately if not set, and the hosts are
+// brought back up once the duration has elapsed.
+func (c *Client) HostMaintenanceWindowCreateAction(
+	hosts string,
+	pool string,
+	startTime string,
+	duration time.Duration,
+) error {
+	var hostnames []string
+	if len(hosts) > 0 {
+		var err error
+		hostnames, err = c.ExtractHostnames(hosts, hostSeparator)
+		if err != nil {
+			return err
+		}
+	}
+	if len(hostnames) == 0 && len(pool) == 0 {
+		return fmt.Errorf("hosts or host pool must be specified")
+	}
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	request := &host_svc.CreateMaintenanceWindowRequest{
+		Hostnames:       hostnames,
+		HostPool:        pool,
+		StartTime:       startTime,
+		DurationSeconds: uint32(duration.Seconds()),
+	}
+	resp, err := c.hostClient.CreateMaintenanceWindow(c.ctx, request)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tabWriter, "Maintenance window created: %s\n",
+		resp.GetWindow().GetId())
+	tabWriter.Flush()
+	return nil
+}
+
+// HostMaintenanceWindowListAction is the action for listing
+// maintenance windows.
+func (c *Client) HostMaintenanceWindowListAction() error {
+	resp, err := c.hostClient.ListMaintenanceWindows(
+		c.ctx,
+		&host_svc.ListMaintenanceWindowsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(resp)
+		return nil
+	}
+	if len(resp.GetWindows()) == 0 {
+		fmt.Fprintf(tabWriter, "No maintenance windows found\n")
+		tabWriter.Flush()
+		return nil
+	}
+	fmt.Fprintf(tabWriter, maintenanceWindowFormatHeader)
+	for _, w := range resp.GetWindows() {
+		fmt.Fprintf(
+			tabWriter,
+			maintenanceWindowFormatBody,
+			w.GetId(),
+			w.GetState(),
+			w.GetStartTime(),
+			time.Duration(w.GetDurationSeconds())*time.Second,
+			w.GetHostPool(),
+			strings.Join(w.GetHostnames(), hostSeparator),
+		)
+	}
+	tabWriter.Flush()
+	return nil
+}
+
+// HostMaintenanceWindowDeleteAction is the action for deleting
+// a maintenance window.
+func (c *Client) HostMaintenanceWindowDeleteAction(id string) error {
+	if len(id) == 0 {
+		return fmt.Errorf("Missing maintenance window id")
+	}
+	_, err := c.hostClient.DeleteMaintenanceWindow(
+		c.ctx,
+		&host_svc.DeleteMaintenanceWindowRequest{Id: id},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tabWriter, "Maintenance window deleted: %s\n", id)
+	tabWriter.Flush()
+	return nil
+}
+
 // HostQueryAction is the action for querying hosts by states. This can be to used to monitor the state of the host(s)
 // Eg. When a list of hosts are put into maintenance (`host maintenance start`).
 // A host, at any given time, will be in one of the following states
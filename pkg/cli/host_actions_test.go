@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
@@ -114,6 +115,114 @@ func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCompleteAction()
 	suite.Error(err)
 }
 
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceWindowCreateAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		CreateMaintenanceWindow(gomock.Any(), &hostsvc.CreateMaintenanceWindowRequest{
+			Hostnames:       []string{"host1", "host2"},
+			HostPool:        "pool1",
+			StartTime:       "2019-01-01T00:00:00Z",
+			DurationSeconds: 7200,
+		}).
+		Return(&hostsvc.CreateMaintenanceWindowResponse{
+			Window: &host.MaintenanceWindow{Id: "w1"},
+		}, nil)
+	err := c.HostMaintenanceWindowCreateAction(
+		"host2,host1", "pool1", "2019-01-01T00:00:00Z", 2*time.Hour)
+	suite.NoError(err)
+
+	// Test CreateMaintenanceWindow error
+	suite.mockHostmgr.EXPECT().
+		CreateMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake CreateMaintenanceWindow error"))
+	err = c.HostMaintenanceWindowCreateAction("", "pool1", "", time.Hour)
+	suite.Error(err)
+
+	// Test missing hosts and pool error
+	err = c.HostMaintenanceWindowCreateAction("", "", "", time.Hour)
+	suite.Error(err)
+
+	// Test missing duration error
+	err = c.HostMaintenanceWindowCreateAction("host1", "", "", 0)
+	suite.Error(err)
+
+	// Test invalid hosts error
+	err = c.HostMaintenanceWindowCreateAction("host1,,host2", "", "", time.Hour)
+	suite.Error(err)
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceWindowListAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	resp := &hostsvc.ListMaintenanceWindowsResponse{
+		Windows: []*host.MaintenanceWindow{
+			{
+				Id:              "w1",
+				Hostnames:       []string{"host1"},
+				StartTime:       "2019-01-01T00:00:00Z",
+				DurationSeconds: 3600,
+				State:           host.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE,
+			},
+		},
+	}
+	for _, debug := range []bool{false, true} {
+		c.Debug = debug
+		suite.mockHostmgr.EXPECT().
+			ListMaintenanceWindows(gomock.Any(), gomock.Any()).
+			Return(resp, nil)
+		suite.NoError(c.HostMaintenanceWindowListAction())
+	}
+
+	// Test empty list
+	c.Debug = false
+	suite.mockHostmgr.EXPECT().
+		ListMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.ListMaintenanceWindowsResponse{}, nil)
+	suite.NoError(c.HostMaintenanceWindowListAction())
+
+	// Test ListMaintenanceWindows error
+	suite.mockHostmgr.EXPECT().
+		ListMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake ListMaintenanceWindows error"))
+	suite.Error(c.HostMaintenanceWindowListAction())
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceWindowDeleteAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		DeleteMaintenanceWindow(gomock.Any(), &hostsvc.DeleteMaintenanceWindowRequest{
+			Id: "w1",
+		}).
+		Return(&hostsvc.DeleteMaintenanceWindowResponse{}, nil)
+	suite.NoError(c.HostMaintenanceWindowDeleteAction("w1"))
+
+	// Test DeleteMaintenanceWindow error
+	suite.mockHostmgr.EXPECT().
+		DeleteMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake DeleteMaintenanceWindow error"))
+	suite.Error(c.HostMaintenanceWindowDeleteAction("w1"))
+
+	// Test empty id error
+	suite.Error(c.HostMaintenanceWindowDeleteAction(""))
+}
+
 func (suite *hostmgrActionsTestSuite) TestClientHostQueryAction() {
 	c := Client{
 		Debug:      false,
@@ -16,23 +16,27 @@ package middleware
 
 import (
 	"context"
+	"strings"
 
 	"go.uber.org/yarpc/api/middleware"
 	"go.uber.org/yarpc/api/transport"
 )
 
 const (
-	_usernameHeader = "username"
-	_passwordHeader = "password"
+	_usernameHeader      = "username"
+	_passwordHeader      = "password"
+	_authorizationHeader = "authorization"
 )
 
-type outboundMiddleware interface {
+// OutboundMiddleware adds auth info to all outbound requests
+type OutboundMiddleware interface {
 	middleware.UnaryOutbound
 	middleware.OnewayOutbound
 	middleware.StreamOutbound
 }
 
-var _ outboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &BearerTokenOutboundMiddleware{}
 
 // BasicAuthConfig is the config for basic auth
 type BasicAuthConfig struct {
@@ -85,3 +89,42 @@ func (m *BasicAuthOutboundMiddleware) addAuthToHeader(headers transport.Headers)
 
 	return headers
 }
+
+// BearerTokenOutboundMiddleware provides bearer token auth
+// support for all outbound requests
+type BearerTokenOutboundMiddleware struct {
+	token string
+}
+
+// NewBearerTokenOutboundMiddleware creates BearerTokenOutboundMiddleware
+func NewBearerTokenOutboundMiddleware(token string) *BearerTokenOutboundMiddleware {
+	return &BearerTokenOutboundMiddleware{
+		token: strings.TrimSpace(token),
+	}
+}
+
+// Call adds auth info to yarpc request header and relay the request
+func (m *BearerTokenOutboundMiddleware) Call(ctx context.Context, request *transport.Request, out transport.UnaryOutbound) (*transport.Response, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.Call(ctx, request)
+}
+
+// CallOneway adds auth info to yarpc request header and relay the request
+func (m *BearerTokenOutboundMiddleware) CallOneway(ctx context.Context, request *transport.Request, out transport.OnewayOutbound) (transport.Ack, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.CallOneway(ctx, request)
+}
+
+// CallStream adds auth info to yarpc request header and relay the request
+func (m *BearerTokenOutboundMiddleware) CallStream(ctx context.Context, request *transport.StreamRequest, out transport.StreamOutbound) (*transport.ClientStream, error) {
+	request.Meta.Headers = m.addAuthToHeader(request.Meta.Headers)
+	return out.CallStream(ctx, request)
+}
+
+func (m *BearerTokenOutboundMiddleware) addAuthToHeader(headers transport.Headers) transport.Headers {
+	if len(m.token) == 0 {
+		return headers
+	}
+
+	return headers.With(_authorizationHeader, "Bearer "+m.token)
+}
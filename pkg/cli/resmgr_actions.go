@@ -17,6 +17,7 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -27,6 +28,8 @@ const (
 	activeTaskListFormatBody   = "%s\t%s\t%s\t%s\t%s\n"
 	orphanTasksFormatHeader    = "TaskID\tHostname\tCPU\tGPU\tMemoryMB\tDiskMB\tFD\t\n"
 	orphanTasksFormatBody      = "%s\t%s\t%v\t%v\t%v\t%v\t%v\t\n"
+	slaViolationsFormatHeader  = "TaskID\tRespool\tFrom\tTo\tSLA\tElapsed\tIn Progress\tDetection Time\tActions\t\n"
+	slaViolationsFormatBody    = "%s\t%s\t%s\t%s\t%v\t%v\t%t\t%s\t%s\t\n"
 )
 
 // ResMgrGetActiveTasks fetches the active tasks from resource manager.
@@ -82,6 +85,23 @@ func (c *Client) ResMgrGetOrphanTasks(respoolID string) error {
 	return nil
 }
 
+// ResMgrGetSLAViolations fetches the state transition SLA violations of
+// the active tasks from resource manager.
+func (c *Client) ResMgrGetSLAViolations(jobID string, respoolID string) error {
+	request := &resmgrsvc.GetSLAViolationsRequest{
+		JobID:     jobID,
+		RespoolID: respoolID,
+	}
+
+	resp, err := c.resMgrClient.GetSLAViolations(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	printSLAViolationsResponse(resp, c.Debug)
+	return nil
+}
+
 func printActiveTasksResponse(r *resmgrsvc.GetActiveTasksResponse, debug bool) {
 	if debug {
 		printResponseJSON(r)
@@ -168,3 +188,31 @@ func printOrphanTasksResponse(r *resmgrsvc.GetOrphanTasksResponse, debug bool) {
 	}
 	tabWriter.Flush()
 }
+
+func printSLAViolationsResponse(
+	r *resmgrsvc.GetSLAViolationsResponse,
+	debug bool,
+) {
+	if debug {
+		printResponseJSON(r)
+	} else {
+		fmt.Fprint(tabWriter, slaViolationsFormatHeader)
+		for _, v := range r.GetViolations() {
+			fmt.Fprintf(
+				tabWriter,
+				slaViolationsFormatBody,
+				v.GetId().GetValue(),
+				v.GetRespoolPath(),
+				v.GetFromState().String(),
+				v.GetToState().String(),
+				time.Duration(v.GetSlaSeconds()*float64(time.Second)),
+				time.Duration(v.GetElapsedSeconds()*float64(time.Second)).
+					Round(time.Second),
+				v.GetInProgress(),
+				v.GetDetectionTime(),
+				strings.Join(v.GetActions(), ","),
+			)
+		}
+	}
+	tabWriter.Flush()
+}
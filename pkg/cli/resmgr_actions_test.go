@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -245,3 +246,67 @@ func (suite *resmgrActionsTestSuite) TestClientGetOrphanTasks() {
 		}
 	}
 }
+
+// TestClientGetSLAViolations tests getting the SLA violations of the
+// active tasks
+func (suite *resmgrActionsTestSuite) TestClientGetSLAViolations() {
+	c := Client{
+		Debug:        false,
+		resMgrClient: suite.mockRes,
+		dispatcher:   nil,
+		ctx:          suite.ctx,
+	}
+
+	violations := []*resmgr.SLAViolation{
+		{
+			Id:             &peloton.TaskID{Value: "job-1-0"},
+			JobId:          &peloton.JobID{Value: "job-1"},
+			RespoolPath:    "/infra",
+			FromState:      task.TaskState_READY,
+			ToState:        task.TaskState_PLACED,
+			SlaSeconds:     300,
+			ElapsedSeconds: 420.5,
+			InProgress:     true,
+			DetectionTime:  "2019-01-01T00:00:00Z",
+			Actions:        []string{"requeue", "alert"},
+		},
+	}
+	tt := []struct {
+		debug bool
+		resp  *resmgrsvc.GetSLAViolationsResponse
+		err   error
+	}{
+		{
+			resp: &resmgrsvc.GetSLAViolationsResponse{
+				Violations: violations,
+			},
+			err: nil,
+		},
+		{
+			resp: nil,
+			err:  fmt.Errorf("fake res error"),
+		},
+		{
+			debug: true,
+			resp: &resmgrsvc.GetSLAViolationsResponse{
+				Violations: violations,
+			},
+			err: nil,
+		},
+	}
+
+	for _, t := range tt {
+		c.Debug = t.debug
+		suite.mockRes.EXPECT().
+			GetSLAViolations(gomock.Any(), &resmgrsvc.GetSLAViolationsRequest{
+				JobID:     "job-1",
+				RespoolID: "respool-1",
+			}).
+			Return(t.resp, t.err)
+		if t.err != nil {
+			suite.Error(c.ResMgrGetSLAViolations("job-1", "respool-1"))
+		} else {
+			suite.NoError(c.ResMgrGetSLAViolations("job-1", "respool-1"))
+		}
+	}
+}
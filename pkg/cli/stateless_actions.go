@@ -285,6 +285,7 @@ func (c *Client) StatelessReplaceJobAction(
 	opaqueData string,
 	inPlace bool,
 	startPods bool,
+	canary *stateless.CanarySpec,
 ) error {
 	var jobSpec stateless.JobSpec
 
@@ -350,6 +351,7 @@ func (c *Client) StatelessReplaceJobAction(
 			StartPaused:                  startPaused,
 			InPlace:                      inPlace,
 			StartPods:                    startPods,
+			Canary:                       canary,
 		},
 		OpaqueData: opaque,
 	}
@@ -332,6 +332,11 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 	inPlace := false
 	startPods := false
 	opaque := "test"
+	canary := &stateless.CanarySpec{
+		InstanceCount:   1,
+		BakeTimeSeconds: 300,
+		MaxRestarts:     1,
+	}
 
 	suite.resClient.EXPECT().
 		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
@@ -355,6 +360,9 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 
 	suite.statelessClient.EXPECT().
 		ReplaceJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *svc.ReplaceJobRequest) {
+			suite.Equal(canary, req.GetUpdateSpec().GetCanary())
+		}).
 		Return(&svc.ReplaceJobResponse{
 			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
 		}, nil)
@@ -373,6 +381,7 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 		opaque,
 		inPlace,
 		startPods,
+		canary,
 	))
 }
 
@@ -410,6 +419,7 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionLookupResou
 		"",
 		inPlace,
 		startPods,
+		nil,
 	))
 }
 
@@ -455,6 +465,7 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionGetJobFail(
 		opaque,
 		inPlace,
 		startPods,
+		nil,
 	))
 }
 
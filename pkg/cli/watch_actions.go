@@ -128,8 +128,12 @@ func (c *Client) watchStream(stream watchsvc.WatchServiceServiceWatchYARPCClient
 }
 
 // WatchJob is the action for starting a watch stream for job, specified
-// by job ids.
-func (c *Client) WatchJob(jobIDs []string, labels []string) error {
+// by job ids. If startRevision is set, the watch resumes from the revision.
+func (c *Client) WatchJob(
+	jobIDs []string,
+	labels []string,
+	startRevision uint64,
+) error {
 	var js []*peloton.JobID
 
 	for _, j := range jobIDs {
@@ -146,6 +150,7 @@ func (c *Client) WatchJob(jobIDs []string, labels []string) error {
 	stream, err := c.watchClient.Watch(
 		c.ctx,
 		&watchsvc.WatchRequest{
+			StartRevision: startRevision,
 			StatelessJobFilter: &watch.StatelessJobFilter{
 				JobIds: js,
 				Labels: labelFilter,
@@ -160,8 +165,14 @@ func (c *Client) WatchJob(jobIDs []string, labels []string) error {
 }
 
 // WatchPod is the action for starting a watch stream for pod, specified
-// by job id and pod names.
-func (c *Client) WatchPod(jobID string, podNames []string, labels []string) error {
+// by job id and pod names. If startRevision is set, the watch resumes
+// from the revision.
+func (c *Client) WatchPod(
+	jobID string,
+	podNames []string,
+	labels []string,
+	startRevision uint64,
+) error {
 	var j *peloton.JobID
 	if jobID != "" {
 		j = &peloton.JobID{
@@ -184,6 +195,7 @@ func (c *Client) WatchPod(jobID string, podNames []string, labels []string) erro
 	stream, err := c.watchClient.Watch(
 		c.ctx,
 		&watchsvc.WatchRequest{
+			StartRevision: startRevision,
 			PodFilter: &watch.PodFilter{
 				JobId:    j,
 				PodNames: ps,
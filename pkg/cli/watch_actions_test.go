@@ -28,6 +28,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc"
 )
 
 type watchActionsTestSuite struct {
@@ -69,9 +70,10 @@ func (suite *watchActionsTestSuite) TestWatchPod() {
 		{WatchId: watchID},
 	}
 
-	for _, podName := range podNames {
+	for i, podName := range podNames {
 		resps = append(resps, &watchsvc.WatchResponse{
-			WatchId: watchID,
+			WatchId:  watchID,
+			Revision: uint64(10 + i),
 			Pods: []*pod.PodSummary{
 				{
 					PodName: &peloton.PodName{Value: podName},
@@ -82,6 +84,13 @@ func (suite *watchActionsTestSuite) TestWatchPod() {
 
 	suite.watchClient.EXPECT().
 		Watch(gomock.Any(), gomock.Any()).
+		Do(func(
+			ctx context.Context,
+			req *watchsvc.WatchRequest,
+			opts ...yarpc.CallOption,
+		) {
+			suite.Equal(uint64(10), req.GetStartRevision())
+		}).
 		Return(stream, nil)
 
 	var calls []*gomock.Call
@@ -92,7 +101,7 @@ func (suite *watchActionsTestSuite) TestWatchPod() {
 
 	gomock.InOrder(calls...)
 
-	suite.NoError(suite.client.WatchPod(jobID, podNames, labels))
+	suite.NoError(suite.client.WatchPod(jobID, podNames, labels, 10))
 }
 
 func (suite *watchActionsTestSuite) TestWatchPodLabelError() {
@@ -103,7 +112,7 @@ func (suite *watchActionsTestSuite) TestWatchPodLabelError() {
 	label1 := "key1:value1:value2"
 	labels = append(labels, label1)
 
-	suite.Error(suite.client.WatchPod(jobID, podNames, labels))
+	suite.Error(suite.client.WatchPod(jobID, podNames, labels, 0))
 }
 
 func (suite *watchActionsTestSuite) TestWatchJob() {
@@ -142,7 +151,7 @@ func (suite *watchActionsTestSuite) TestWatchJob() {
 
 	gomock.InOrder(calls...)
 
-	suite.NoError(suite.client.WatchJob(jobIDs, labels))
+	suite.NoError(suite.client.WatchJob(jobIDs, labels, 0))
 }
 
 func (suite *watchActionsTestSuite) TestWatchJobLabelError() {
@@ -152,7 +161,7 @@ func (suite *watchActionsTestSuite) TestWatchJobLabelError() {
 	label1 := "key1:value1:value2"
 	labels = append(labels, label1)
 
-	suite.Error(suite.client.WatchJob(jobIDs, labels))
+	suite.Error(suite.client.WatchJob(jobIDs, labels, 0))
 }
 
 func (suite *watchActionsTestSuite) TestCancelWatch() {
@@ -24,6 +24,7 @@ import (
 	pelotonv0respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
@@ -182,10 +183,11 @@ func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
 			UpdatedAt: runtime.GetRevision().GetUpdatedAt(),
 			UpdatedBy: runtime.GetRevision().GetUpdatedBy(),
 		},
-		PrevPodId:     &v1alphapeloton.PodID{Value: runtime.GetPrevMesosTaskId().GetValue()},
-		ResourceUsage: runtime.GetResourceUsage(),
-		DesiredPodId:  &v1alphapeloton.PodID{Value: runtime.GetDesiredMesosTaskId().GetValue()},
-		DesiredHost:   runtime.GetDesiredHost(),
+		PrevPodId:      &v1alphapeloton.PodID{Value: runtime.GetPrevMesosTaskId().GetValue()},
+		ResourceUsage:  runtime.GetResourceUsage(),
+		DesiredPodId:   &v1alphapeloton.PodID{Value: runtime.GetDesiredMesosTaskId().GetValue()},
+		DesiredHost:    runtime.GetDesiredHost(),
+		CrashLoopCount: runtime.GetCrashLoopCount(),
 	}
 }
 
@@ -226,7 +228,13 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 
 	if taskConfig.GetRestartPolicy() != nil {
 		result.RestartPolicy = &pod.RestartPolicy{
-			MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:           taskConfig.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSecs:    taskConfig.GetRestartPolicy().GetInitialBackoffSecs(),
+			BackoffMultiplier:     taskConfig.GetRestartPolicy().GetBackoffMultiplier(),
+			MaxBackoffSecs:        taskConfig.GetRestartPolicy().GetMaxBackoffSecs(),
+			Jitter:                taskConfig.GetRestartPolicy().GetJitter(),
+			ResetAfterHealthySecs: taskConfig.GetRestartPolicy().GetResetAfterHealthySecs(),
+			CrashLoopThreshold:    taskConfig.GetRestartPolicy().GetCrashLoopThreshold(),
 		}
 	}
 
@@ -571,6 +579,8 @@ func ConvertUpdateModelToWorkflowInfo(
 			MaxTolerableInstanceFailures: updateInfo.GetUpdateConfig().GetMaxFailureInstances(),
 			StartPaused:                  updateInfo.GetUpdateConfig().GetStartPaused(),
 			InPlace:                      updateInfo.GetUpdateConfig().GetInPlace(),
+			Canary: ConvertCanaryConfigToCanarySpec(
+				updateInfo.GetUpdateConfig().GetCanary()),
 		}
 	} else if updateInfo.GetType() == models.WorkflowType_RESTART {
 		result.RestartSpec = &stateless.RestartSpec{
@@ -719,6 +729,76 @@ func ConvertJobSpecToJobConfig(spec *stateless.JobSpec) (*job.JobConfig, error)
 	return result, nil
 }
 
+// ConvertBatchJobSpecToJobConfig converts batch job spec to job config
+func ConvertBatchJobSpecToJobConfig(spec *batch.JobSpec) (*job.JobConfig, error) {
+	var sla *stateless.SlaSpec
+	if spec.GetSla() != nil {
+		sla = &stateless.SlaSpec{
+			Priority:    spec.GetSla().GetPriority(),
+			Preemptible: spec.GetSla().GetPreemptible(),
+			Revocable:   spec.GetSla().GetRevocable(),
+		}
+	}
+
+	result, err := ConvertJobSpecToJobConfig(&stateless.JobSpec{
+		Revision:      spec.GetRevision(),
+		Name:          spec.GetName(),
+		Owner:         spec.GetOwner(),
+		OwningTeam:    spec.GetOwningTeam(),
+		LdapGroups:    spec.GetLdapGroups(),
+		Description:   spec.GetDescription(),
+		Labels:        spec.GetLabels(),
+		InstanceCount: spec.GetInstanceCount(),
+		Sla:           sla,
+		DefaultSpec:   spec.GetDefaultSpec(),
+		InstanceSpec:  spec.GetInstanceSpec(),
+		RespoolId:     spec.GetRespoolId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Type = job.JobType_BATCH
+	if spec.GetSla() != nil {
+		result.SLA = &job.SlaConfig{
+			Priority:                spec.GetSla().GetPriority(),
+			Preemptible:             spec.GetSla().GetPreemptible(),
+			Revocable:               spec.GetSla().GetRevocable(),
+			MaximumRunningInstances: spec.GetSla().GetMaximumRunningInstances(),
+			MinimumRunningInstances: spec.GetSla().GetMinimumRunningInstances(),
+			MaxRunningTime:          spec.GetSla().GetMaxRunningTime(),
+		}
+	}
+	return result, nil
+}
+
+// ConvertJobConfigToBatchJobSpec converts v0 job.JobConfig to
+// v1alpha batch.JobSpec
+func ConvertJobConfigToBatchJobSpec(config *job.JobConfig) *batch.JobSpec {
+	spec := ConvertJobConfigToJobSpec(config)
+	return &batch.JobSpec{
+		Revision:      spec.GetRevision(),
+		Name:          spec.GetName(),
+		Owner:         spec.GetOwner(),
+		OwningTeam:    spec.GetOwningTeam(),
+		LdapGroups:    spec.GetLdapGroups(),
+		Description:   spec.GetDescription(),
+		Labels:        spec.GetLabels(),
+		InstanceCount: spec.GetInstanceCount(),
+		Sla: &batch.SlaSpec{
+			Priority:                config.GetSLA().GetPriority(),
+			Preemptible:             config.GetSLA().GetPreemptible(),
+			Revocable:               config.GetSLA().GetRevocable(),
+			MaximumRunningInstances: config.GetSLA().GetMaximumRunningInstances(),
+			MinimumRunningInstances: config.GetSLA().GetMinimumRunningInstances(),
+			MaxRunningTime:          config.GetSLA().GetMaxRunningTime(),
+		},
+		DefaultSpec:  spec.GetDefaultSpec(),
+		InstanceSpec: spec.GetInstanceSpec(),
+		RespoolId:    spec.GetRespoolId(),
+	}
+}
+
 // FindVolumeInPodSpec finds a volume of given name in the
 // volume spec present in the pod spec
 func FindVolumeInPodSpec(spec *pod.PodSpec, name string) *volume.VolumeSpec {
@@ -1325,7 +1405,13 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 
 	if spec.GetRestartPolicy() != nil {
 		result.RestartPolicy = &task.RestartPolicy{
-			MaxFailures: spec.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:           spec.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSecs:    spec.GetRestartPolicy().GetInitialBackoffSecs(),
+			BackoffMultiplier:     spec.GetRestartPolicy().GetBackoffMultiplier(),
+			MaxBackoffSecs:        spec.GetRestartPolicy().GetMaxBackoffSecs(),
+			Jitter:                spec.GetRestartPolicy().GetJitter(),
+			ResetAfterHealthySecs: spec.GetRestartPolicy().GetResetAfterHealthySecs(),
+			CrashLoopThreshold:    spec.GetRestartPolicy().GetCrashLoopThreshold(),
 		}
 	}
 
@@ -1409,6 +1495,39 @@ func ConvertUpdateSpecToUpdateConfig(spec *stateless.UpdateSpec) *update.UpdateC
 		StartPaused:         spec.GetStartPaused(),
 		InPlace:             spec.GetInPlace(),
 		StartTasks:          spec.GetStartPods(),
+		Canary:              ConvertCanarySpecToCanaryConfig(spec.GetCanary()),
+	}
+}
+
+// ConvertCanarySpecToCanaryConfig converts v1alpha canary spec
+// to v0 canary config
+func ConvertCanarySpecToCanaryConfig(spec *stateless.CanarySpec) *update.CanaryConfig {
+	if spec == nil {
+		return nil
+	}
+
+	return &update.CanaryConfig{
+		InstanceCount:       spec.GetInstanceCount(),
+		BakeTimeSeconds:     spec.GetBakeTimeSeconds(),
+		MaxFailurePercent:   spec.GetMaxInstanceFailurePercent(),
+		MaxHealthCheckFlaps: spec.GetMaxHealthCheckFlaps(),
+		MaxRestarts:         spec.GetMaxRestarts(),
+	}
+}
+
+// ConvertCanaryConfigToCanarySpec converts v0 canary config
+// to v1alpha canary spec
+func ConvertCanaryConfigToCanarySpec(config *update.CanaryConfig) *stateless.CanarySpec {
+	if config == nil {
+		return nil
+	}
+
+	return &stateless.CanarySpec{
+		InstanceCount:             config.GetInstanceCount(),
+		BakeTimeSeconds:           config.GetBakeTimeSeconds(),
+		MaxInstanceFailurePercent: config.GetMaxFailurePercent(),
+		MaxHealthCheckFlaps:       config.GetMaxHealthCheckFlaps(),
+		MaxRestarts:               config.GetMaxRestarts(),
 	}
 }
 
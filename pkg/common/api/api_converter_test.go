@@ -25,6 +25,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
@@ -133,13 +134,14 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 		MesosTaskId: &mesos.TaskID{
 			Value: &testMesosTaskID,
 		},
-		StartTime:    startTime,
-		Host:         host,
-		Ports:        ports,
-		GoalState:    task.TaskState_SUCCEEDED,
-		Message:      message,
-		Reason:       reason,
-		FailureCount: failureCount,
+		StartTime:      startTime,
+		Host:           host,
+		Ports:          ports,
+		GoalState:      task.TaskState_SUCCEEDED,
+		Message:        message,
+		Reason:         reason,
+		FailureCount:   failureCount,
+		CrashLoopCount: failureCount,
 		VolumeID: &peloton.VolumeID{
 			Value: volume,
 		},
@@ -192,10 +194,11 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 				},
 			},
 		},
-		DesiredState: pod.PodState_POD_STATE_SUCCEEDED,
-		Message:      message,
-		Reason:       reason,
-		FailureCount: failureCount,
+		DesiredState:   pod.PodState_POD_STATE_SUCCEEDED,
+		Message:        message,
+		Reason:         reason,
+		FailureCount:   failureCount,
+		CrashLoopCount: failureCount,
 		VolumeId: &v1alphapeloton.VolumeID{
 			Value: volume,
 		},
@@ -476,7 +479,13 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				OrConstraint:  &task.OrConstraint{},
 			},
 			RestartPolicy: &task.RestartPolicy{
-				MaxFailures: 5,
+				MaxFailures:           5,
+				InitialBackoffSecs:    10,
+				BackoffMultiplier:     1.5,
+				MaxBackoffSecs:        300,
+				Jitter:                0.1,
+				ResetAfterHealthySecs: 600,
+				CrashLoopThreshold:    4,
 			},
 			Volume: &task.PersistentVolumeConfig{
 				ContainerPath: "test/container/path",
@@ -595,7 +604,13 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				OrConstraint:  &pod.OrConstraint{},
 			},
 			RestartPolicy: &pod.RestartPolicy{
-				MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+				MaxFailures:           taskConfig.GetRestartPolicy().GetMaxFailures(),
+				InitialBackoffSecs:    taskConfig.GetRestartPolicy().GetInitialBackoffSecs(),
+				BackoffMultiplier:     taskConfig.GetRestartPolicy().GetBackoffMultiplier(),
+				MaxBackoffSecs:        taskConfig.GetRestartPolicy().GetMaxBackoffSecs(),
+				Jitter:                taskConfig.GetRestartPolicy().GetJitter(),
+				ResetAfterHealthySecs: taskConfig.GetRestartPolicy().GetResetAfterHealthySecs(),
+				CrashLoopThreshold:    taskConfig.GetRestartPolicy().GetCrashLoopThreshold(),
 			},
 			Volume: &pod.PersistentVolumeSpec{
 				ContainerPath: taskConfig.GetVolume().GetContainerPath(),
@@ -1119,6 +1134,77 @@ func (suite *apiConverterTestSuite) TestConvertJobConfigToJobSpec() {
 	suite.Equal(jobConfig.GetRespoolID().GetValue(), jobSpec.GetRespoolId().GetValue())
 }
 
+// TestConvertBatchJobSpecToJobConfigAndViceVersa tests conversion
+// from v1alpha batch JobSpec to v0 JobConfig and vice versa
+func (suite *apiConverterTestSuite) TestConvertBatchJobSpecToJobConfigAndViceVersa() {
+	command := "echo hello"
+	jobSpec := &batch.JobSpec{
+		Revision: &v1alphapeloton.Revision{
+			Version:   1,
+			CreatedAt: 2,
+			UpdatedAt: 3,
+			UpdatedBy: "peloton",
+		},
+		Name:        "test-name",
+		Owner:       "test-owner",
+		OwningTeam:  "team123",
+		LdapGroups:  []string{"peloton"},
+		Description: "test description",
+		Labels: []*v1alphapeloton.Label{
+			{
+				Key:   "test-key",
+				Value: "test-value",
+			},
+		},
+		InstanceCount: 10,
+		Sla: &batch.SlaSpec{
+			Priority:                2,
+			Revocable:               true,
+			MaximumRunningInstances: 5,
+			MinimumRunningInstances: 2,
+			MaxRunningTime:          3600,
+		},
+		DefaultSpec: &pod.PodSpec{
+			Containers: []*pod.ContainerSpec{
+				{
+					Name: "instance",
+					Command: &mesos.CommandInfo{
+						Value: &command,
+					},
+				},
+			},
+		},
+		RespoolId: &v1alphapeloton.ResourcePoolID{
+			Value: "/test/respool",
+		},
+	}
+
+	jobConfig, err := ConvertBatchJobSpecToJobConfig(jobSpec)
+	suite.NoError(err)
+
+	suite.Equal(job.JobType_BATCH, jobConfig.GetType())
+	suite.Equal(jobSpec.GetName(), jobConfig.GetName())
+	suite.Equal(jobSpec.GetOwningTeam(), jobConfig.GetOwningTeam())
+	suite.Equal(jobSpec.GetInstanceCount(), jobConfig.GetInstanceCount())
+	suite.Equal(jobSpec.GetRevision().GetVersion(), jobConfig.GetChangeLog().GetVersion())
+	suite.Equal(jobSpec.GetSla().GetPriority(), jobConfig.GetSLA().GetPriority())
+	suite.Equal(jobSpec.GetSla().GetRevocable(), jobConfig.GetSLA().GetRevocable())
+	suite.Equal(jobSpec.GetSla().GetRevocable(), jobConfig.GetDefaultConfig().GetRevocable())
+	suite.Equal(uint32(5), jobConfig.GetSLA().GetMaximumRunningInstances())
+	suite.Equal(uint32(2), jobConfig.GetSLA().GetMinimumRunningInstances())
+	suite.Equal(uint32(3600), jobConfig.GetSLA().GetMaxRunningTime())
+	suite.Equal(command, jobConfig.GetDefaultConfig().GetCommand().GetValue())
+	suite.Equal(jobSpec.GetRespoolId().GetValue(), jobConfig.GetRespoolID().GetValue())
+
+	convertedSpec := ConvertJobConfigToBatchJobSpec(jobConfig)
+	suite.Equal(jobSpec.GetName(), convertedSpec.GetName())
+	suite.Equal(jobSpec.GetLabels(), convertedSpec.GetLabels())
+	suite.Equal(jobSpec.GetSla(), convertedSpec.GetSla())
+	suite.Equal(jobSpec.GetRespoolId(), convertedSpec.GetRespoolId())
+	suite.Equal(command,
+		convertedSpec.GetDefaultSpec().GetContainers()[0].GetCommand().GetValue())
+}
+
 // TestConvertJobSpecToJobConfig tests conversion
 // from v1alpha JobSpec to v0 JobConfig
 func (suite *apiConverterTestSuite) TestConvertJobSpecToJobConfig() {
@@ -1629,6 +1715,33 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 	suite.Equal(spec.GetMaxInstanceRetries(), config.GetMaxInstanceAttempts())
 	suite.Equal(spec.GetMaxTolerableInstanceFailures(), config.GetMaxFailureInstances())
 	suite.Equal(spec.GetStartPaused(), config.GetStartPaused())
+	suite.Nil(config.GetCanary())
+}
+
+// TestConvertCanarySpecToCanaryConfigAndViceVersa tests the conversion
+// between v1alpha canary spec and v0 canary config
+func (suite *apiConverterTestSuite) TestConvertCanarySpecToCanaryConfigAndViceVersa() {
+	spec := &stateless.UpdateSpec{
+		BatchSize: 10,
+		Canary: &stateless.CanarySpec{
+			InstanceCount:             2,
+			BakeTimeSeconds:           600,
+			MaxInstanceFailurePercent: 50,
+			MaxHealthCheckFlaps:       1,
+			MaxRestarts:               3,
+		},
+	}
+
+	config := ConvertUpdateSpecToUpdateConfig(spec)
+	suite.Equal(&update.CanaryConfig{
+		InstanceCount:       2,
+		BakeTimeSeconds:     600,
+		MaxFailurePercent:   50,
+		MaxHealthCheckFlaps: 1,
+		MaxRestarts:         3,
+	}, config.GetCanary())
+
+	suite.Equal(spec.GetCanary(), ConvertCanaryConfigToCanarySpec(config.GetCanary()))
 }
 
 // TestConvertInstanceIDListToInstanceRange tests conversion from
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+const (
+	// CassandraSink stores the audit events in Cassandra
+	CassandraSink = "cassandra"
+
+	// FileSink appends the audit events to a local file
+	FileSink = "file"
+
+	// StreamSink publishes the audit events to a Kafka REST proxy
+	StreamSink = "stream"
+
+	// _defaultBufferSize is the default number of audit events buffered
+	// while they are being written to the sink
+	_defaultBufferSize = 10000
+)
+
+// Config is the config of the audit log of the mutating API calls
+type Config struct {
+	// Enabled enables the audit log
+	Enabled bool `yaml:"enabled"`
+
+	// Sink is the sink which records the audit events,
+	// one of cassandra, file or stream
+	Sink string `yaml:"sink"`
+
+	// FilePath is the file which the file sink appends
+	// the audit events to, one JSON object per line
+	FilePath string `yaml:"file_path"`
+
+	// StreamURL is the URL of the Kafka REST proxy topic
+	// which the stream sink publishes the audit events to
+	StreamURL string `yaml:"stream_url"`
+
+	// BufferSize is the number of audit events buffered while they
+	// are being written to the sink. Events are dropped when the
+	// buffer is full.
+	BufferSize int `yaml:"buffer_size"`
+
+	// ReadAPIs are the read only procedures, e.g. *:Get*, which
+	// are not audited. If not set, the Get, Query, List, Browse
+	// and Lookup methods and the watch service are not audited.
+	ReadAPIs []string `yaml:"read_apis"`
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track
+// the audit log
+type Metrics struct {
+	// number of audit events written to the sink
+	Written tally.Counter
+	// number of audit events which failed to be written to the sink
+	WriteFail tally.Counter
+	// number of audit events dropped because the buffer was full
+	Dropped tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	auditScope := scope.SubScope("audit")
+	return &Metrics{
+		Written:   auditScope.Counter("written"),
+		WriteFail: auditScope.Counter("write_fail"),
+		Dropped:   auditScope.Counter("dropped"),
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	v1alpharespool "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// _procedureSeparator separates the service and
+	// the method in a procedure name
+	_procedureSeparator = "::"
+
+	// _maxDepth is the maximum depth of nested messages
+	// searched for the targeted resources
+	_maxDepth = 4
+
+	// suffixes of the names of the request and the
+	// response messages of the procedures
+	_requestSuffix  = "Request"
+	_responseSuffix = "Response"
+
+	// _errorField is the field of the response messages
+	// of the v0 APIs which reports the failure of the call
+	_errorField = "Error"
+)
+
+// Resources are the jobs, pods, resource pools and hosts targeted
+// by an API call
+type Resources struct {
+	JobIDs        []string
+	PodNames      []string
+	ResourcePools []string
+	Hostnames     []string
+}
+
+// ExtractResources decodes the request body of a procedure call and
+// returns the resources targeted by the call. The request message is
+// looked up following the convention of the Peloton APIs that the
+// request of Service::Method is the message MethodRequest in the
+// package of the service.
+func ExtractResources(
+	procedure string,
+	encoding string,
+	body []byte,
+) (*Resources, error) {
+	msg, err := newMessage(procedure, _requestSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unmarshal(encoding, body, msg); err != nil {
+		return nil, err
+	}
+
+	resources := &Resources{}
+	resources.collect(reflect.ValueOf(msg), 0)
+	return resources, nil
+}
+
+// ExtractResponseError decodes the response body of a procedure call and
+// returns the error reported in the response message, which is how the
+// v0 Peloton APIs report failures. The code is the name of the field of
+// the error which is set, e.g. NotFound, and the message is the text of
+// the error. The code is empty if the response reports no error.
+func ExtractResponseError(
+	procedure string,
+	encoding string,
+	body []byte,
+) (code string, message string, err error) {
+	msg, err := newMessage(procedure, _responseSuffix)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := unmarshal(encoding, body, msg); err != nil {
+		return "", "", err
+	}
+
+	errField := reflect.ValueOf(msg).Elem().FieldByName(_errorField)
+	if !errField.IsValid() ||
+		errField.Kind() != reflect.Ptr ||
+		errField.IsNil() {
+		return "", "", nil
+	}
+
+	code = _errorField
+	errValue := errField.Elem()
+	if errValue.Kind() == reflect.Struct {
+		for i := 0; i < errValue.NumField(); i++ {
+			field := errValue.Type().Field(i)
+			value := errValue.Field(i)
+			if len(field.PkgPath) != 0 ||
+				strings.HasPrefix(field.Name, "XXX_") ||
+				value.Kind() != reflect.Ptr ||
+				value.IsNil() {
+				continue
+			}
+			code = field.Name
+			break
+		}
+	}
+
+	if errMsg, ok := errField.Interface().(proto.Message); ok {
+		message = proto.CompactTextString(errMsg)
+	}
+	return code, message, nil
+}
+
+// unmarshal decodes the body of a request
+// or a response in the encoding into msg
+func unmarshal(encoding string, body []byte, msg proto.Message) error {
+	switch encoding {
+	case "proto":
+		return proto.Unmarshal(body, msg)
+	case "json":
+		unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: true}
+		return unmarshaler.Unmarshal(bytes.NewReader(body), msg)
+	default:
+		return fmt.Errorf("unsupported encoding %s", encoding)
+	}
+}
+
+// newMessage returns an empty request or response message
+// of a procedure, depending on the suffix of the message name
+func newMessage(procedure string, suffix string) (proto.Message, error) {
+	i := strings.Index(procedure, _procedureSeparator)
+	if i < 0 {
+		return nil, fmt.Errorf("invalid procedure %s", procedure)
+	}
+	service := procedure[:i]
+	method := procedure[i+len(_procedureSeparator):]
+
+	j := strings.LastIndex(service, ".")
+	if j < 0 {
+		return nil, fmt.Errorf("invalid procedure %s", procedure)
+	}
+
+	name := service[:j+1] + method + suffix
+	t := proto.MessageType(name)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("unknown message %s", name)
+	}
+	return reflect.New(t.Elem()).Interface().(proto.Message), nil
+}
+
+// collect searches a value for the resources it refers to
+func (r *Resources) collect(v reflect.Value, depth int) {
+	if depth > _maxDepth {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if r.collectID(v.Interface()) {
+			return
+		}
+		r.collect(v.Elem(), depth)
+	case reflect.Interface:
+		if !v.IsNil() {
+			r.collect(v.Elem(), depth)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			r.collect(v.Index(i), depth)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			// skip unexported fields and the XXX_ bookkeeping
+			// fields of the message
+			if len(field.PkgPath) != 0 ||
+				strings.HasPrefix(field.Name, "XXX_") {
+				continue
+			}
+
+			value := v.Field(i)
+			switch {
+			case field.Name == "Hostname" &&
+				value.Kind() == reflect.String:
+				r.Hostnames = appendUnique(r.Hostnames, value.String())
+			case field.Name == "Hostnames" &&
+				value.Type() == reflect.TypeOf([]string{}):
+				for _, hostname := range value.Interface().([]string) {
+					r.Hostnames = appendUnique(r.Hostnames, hostname)
+				}
+			default:
+				r.collect(value, depth+1)
+			}
+		}
+	}
+}
+
+// collectID records the resource if the value is the identifier of a
+// resource, and returns true if it is.
+func (r *Resources) collectID(value interface{}) bool {
+	switch id := value.(type) {
+	case *peloton.JobID:
+		r.JobIDs = appendUnique(r.JobIDs, id.GetValue())
+	case *v1alphapeloton.JobID:
+		r.JobIDs = appendUnique(r.JobIDs, id.GetValue())
+	case *v1alphapeloton.PodName:
+		r.PodNames = appendUnique(r.PodNames, id.GetValue())
+	case *peloton.ResourcePoolID:
+		r.ResourcePools = appendUnique(r.ResourcePools, id.GetValue())
+	case *v1alphapeloton.ResourcePoolID:
+		r.ResourcePools = appendUnique(r.ResourcePools, id.GetValue())
+	case *respool.ResourcePoolPath:
+		r.ResourcePools = appendUnique(r.ResourcePools, id.GetValue())
+	case *v1alpharespool.ResourcePoolPath:
+		r.ResourcePools = appendUnique(r.ResourcePools, id.GetValue())
+	default:
+		return false
+	}
+	return true
+}
+
+// appendUnique appends a non empty value which is not in values yet
+func appendUnique(values []string, value string) []string {
+	if len(value) == 0 {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"testing"
+
+	pberrors "github.com/uber/peloton/.gen/peloton/api/v0/errors"
+	"github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	respoolsvc "github.com/uber/peloton/.gen/peloton/api/v0/respool/svc"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResourcesTestSuite struct {
+	suite.Suite
+}
+
+// TestExtractResourcesProto tests extracting the
+// resources of proto encoded requests
+func (suite *ResourcesTestSuite) TestExtractResourcesProto() {
+	tests := []struct {
+		procedure string
+		request   proto.Message
+		expected  *Resources
+	}{
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			request: &statelesssvc.StopJobRequest{
+				JobId: &v1alphapeloton.JobID{Value: "job-1"},
+			},
+			expected: &Resources{JobIDs: []string{"job-1"}},
+		},
+		{
+			procedure: "peloton.api.v1alpha.pod.svc.PodService::RestartPod",
+			request: &podsvc.RestartPodRequest{
+				PodName: &v1alphapeloton.PodName{Value: "job-1-0"},
+			},
+			expected: &Resources{PodNames: []string{"job-1-0"}},
+		},
+		{
+			procedure: "peloton.api.v0.respool.ResourcePoolService::DeleteResourcePool",
+			request: &respoolsvc.DeleteResourcePoolRequest{
+				Id: &peloton.ResourcePoolID{Value: "respool-1"},
+			},
+			expected: &Resources{ResourcePools: []string{"respool-1"}},
+		},
+		{
+			procedure: "peloton.api.v0.host.svc.HostService::StartMaintenance",
+			request: &svc.StartMaintenanceRequest{
+				Hostname: "host-1",
+			},
+			expected: &Resources{Hostnames: []string{"host-1"}},
+		},
+	}
+
+	for _, test := range tests {
+		body, err := proto.Marshal(test.request)
+		suite.NoError(err)
+
+		resources, err := ExtractResources(test.procedure, "proto", body)
+		suite.NoError(err, test.procedure)
+		suite.Equal(test.expected, resources, test.procedure)
+	}
+}
+
+// TestExtractResourcesJSON tests extracting the
+// resources of json encoded requests
+func (suite *ResourcesTestSuite) TestExtractResourcesJSON() {
+	marshaler := &jsonpb.Marshaler{}
+	body, err := marshaler.MarshalToString(&statelesssvc.StopJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: "job-1"},
+	})
+	suite.NoError(err)
+
+	resources, err := ExtractResources(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		"json",
+		[]byte(body),
+	)
+	suite.NoError(err)
+	suite.Equal([]string{"job-1"}, resources.JobIDs)
+}
+
+// TestExtractResourcesErrors tests the errors
+// when extracting the resources of a request
+func (suite *ResourcesTestSuite) TestExtractResourcesErrors() {
+	tests := []struct {
+		procedure string
+		encoding  string
+		body      []byte
+	}{
+		{procedure: "invalid", encoding: "proto"},
+		{procedure: "invalid::StopJob", encoding: "proto"},
+		{procedure: "peloton.api.v0.job.JobManager::Unknown", encoding: "proto"},
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			encoding:  "thrift",
+		},
+		{
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			encoding:  "json",
+			body:      []byte("not json"),
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ExtractResources(test.procedure, test.encoding, test.body)
+		suite.Error(err, test.procedure)
+	}
+}
+
+// TestExtractResponseError tests extracting the errors
+// reported in the response messages
+func (suite *ResourcesTestSuite) TestExtractResponseError() {
+	tests := []struct {
+		procedure string
+		response  proto.Message
+		code      string
+	}{
+		{
+			procedure: "peloton.api.v0.job.JobManager::Delete",
+			response: &job.DeleteResponse{
+				Error: &job.DeleteResponse_Error{
+					NotFound: &pberrors.JobNotFound{
+						Id:      &peloton.JobID{Value: "job-1"},
+						Message: "job not found",
+					},
+				},
+			},
+			code: "NotFound",
+		},
+		{
+			procedure: "peloton.api.v0.job.JobManager::Delete",
+			response:  &job.DeleteResponse{},
+		},
+		{
+			// responses without error field report no error
+			procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			response:  &statelesssvc.StopJobResponse{},
+		},
+	}
+
+	for _, test := range tests {
+		body, err := proto.Marshal(test.response)
+		suite.NoError(err)
+
+		code, message, err := ExtractResponseError(
+			test.procedure, "proto", body)
+		suite.NoError(err, test.procedure)
+		suite.Equal(test.code, code, test.procedure)
+		if len(test.code) != 0 {
+			suite.Contains(message, "job not found")
+		} else {
+			suite.Empty(message)
+		}
+	}
+
+	_, _, err := ExtractResponseError(
+		"peloton.api.v0.job.JobManager::Unknown", "proto", nil)
+	suite.Error(err)
+}
+
+// TestAppendUnique tests that empty and duplicate values are not appended
+func (suite *ResourcesTestSuite) TestAppendUnique() {
+	var values []string
+	values = appendUnique(values, "a")
+	values = appendUnique(values, "")
+	values = appendUnique(values, "b")
+	values = appendUnique(values, "a")
+	suite.Equal([]string{"a", "b"}, values)
+}
+
+func TestResources(t *testing.T) {
+	suite.Run(t, new(ResourcesTestSuite))
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/golang/protobuf/jsonpb"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// _writeTimeout is the timeout to write an audit event to the sink
+	_writeTimeout = 10 * time.Second
+)
+
+var errBufferFull = errors.New("audit event buffer is full")
+
+// Sink records audit events
+type Sink interface {
+	// Write records an audit event
+	Write(ctx context.Context, event *audit.AuditEvent) error
+}
+
+// NewSink creates the sink configured in cfg, which writes the audit
+// events in the background. The ORM store is only required by the
+// cassandra sink.
+func NewSink(
+	cfg *Config,
+	ormStore *ormobjects.Store,
+	scope tally.Scope,
+) (Sink, error) {
+	var sink Sink
+	switch cfg.Sink {
+	case CassandraSink:
+		if ormStore == nil {
+			return nil, errors.New("cassandra audit sink requires a store")
+		}
+		sink = NewStoreSink(ormobjects.NewAuditEventOps(ormStore))
+	case FileSink:
+		fileSink, err := NewFileSink(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case StreamSink:
+		if len(cfg.StreamURL) == 0 {
+			return nil, errors.New("stream audit sink requires a stream url")
+		}
+		sink = NewStreamSink(cfg.StreamURL, &http.Client{Timeout: _writeTimeout})
+	default:
+		return nil, fmt.Errorf("unknown audit sink %s", cfg.Sink)
+	}
+
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = _defaultBufferSize
+	}
+	return NewAsyncSink(sink, bufferSize, scope), nil
+}
+
+// storeSink records the audit events in the audit_events table
+type storeSink struct {
+	auditEventOps ormobjects.AuditEventOps
+}
+
+// NewStoreSink returns a sink recording the audit events in Cassandra
+func NewStoreSink(auditEventOps ormobjects.AuditEventOps) Sink {
+	return &storeSink{auditEventOps: auditEventOps}
+}
+
+// Write adds the audit event to the audit_events table
+func (s *storeSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	eventTime, err := time.Parse(time.RFC3339Nano, event.GetTimestamp())
+	if err != nil {
+		eventTime = time.Now()
+	}
+	return s.auditEventOps.Add(ctx, eventTime, event)
+}
+
+// fileSink appends the audit events to a file, one JSON object per line
+type fileSink struct {
+	sync.Mutex
+	file      *os.File
+	marshaler *jsonpb.Marshaler
+}
+
+// NewFileSink returns a sink appending the audit events to a file
+func NewFileSink(path string) (Sink, error) {
+	if len(path) == 0 {
+		return nil, errors.New("file audit sink requires a file path")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{
+		file:      file,
+		marshaler: &jsonpb.Marshaler{OrigName: true},
+	}, nil
+}
+
+// Write appends the audit event to the file
+func (s *fileSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	line, err := s.marshaler.MarshalToString(event)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.file.WriteString(line + "\n")
+	return err
+}
+
+// streamSink publishes the audit events to a Kafka REST proxy
+type streamSink struct {
+	url       string
+	client    *http.Client
+	marshaler *jsonpb.Marshaler
+}
+
+// NewStreamSink returns a sink publishing the audit events to
+// the Kafka REST proxy topic at url
+func NewStreamSink(url string, client *http.Client) Sink {
+	return &streamSink{
+		url:       url,
+		client:    client,
+		marshaler: &jsonpb.Marshaler{OrigName: true},
+	}
+}
+
+// Write posts the audit event to the Kafka REST proxy
+func (s *streamSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	message, err := s.marshaler.MarshalToString(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewBufferString(message))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Producer-Type", "reliable")
+	req.Header.Set("Content-Type", "application/vnd.kafka.binary.v1")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to publish audit event: %s", resp.Status)
+	}
+	return nil
+}
+
+// asyncSink writes the audit events to a sink in the background, so
+// that API calls are not slowed down by the sink
+type asyncSink struct {
+	sink    Sink
+	events  chan *audit.AuditEvent
+	metrics *Metrics
+}
+
+// NewAsyncSink returns a sink which buffers up to bufferSize audit
+// events, and writes them to sink in the background. Events are
+// dropped when the buffer is full.
+func NewAsyncSink(sink Sink, bufferSize int, scope tally.Scope) Sink {
+	s := &asyncSink{
+		sink:    sink,
+		events:  make(chan *audit.AuditEvent, bufferSize),
+		metrics: NewMetrics(scope),
+	}
+	go s.run()
+	return s
+}
+
+// Write buffers the audit event
+func (s *asyncSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	default:
+		s.metrics.Dropped.Inc(1)
+		return errBufferFull
+	}
+}
+
+// run writes the buffered audit events to the sink
+func (s *asyncSink) run() {
+	for event := range s.events {
+		ctx, cancel := context.WithTimeout(context.Background(), _writeTimeout)
+		err := s.sink.Write(ctx, event)
+		cancel()
+
+		if err != nil {
+			log.WithError(err).
+				WithField("event_id", event.GetEventId()).
+				WithField("procedure", event.GetProcedure()).
+				Warn("failed to write audit event")
+			s.metrics.WriteFail.Inc(1)
+			continue
+		}
+		s.metrics.Written.Inc(1)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+// blockingSink notifies each audit event written to it, and
+// blocks the write until it is released
+type blockingSink struct {
+	started chan *audit.AuditEvent
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	s.started <- event
+	<-s.release
+	return errors.New("sink error")
+}
+
+type SinkTestSuite struct {
+	suite.Suite
+
+	ctrl  *gomock.Controller
+	event *audit.AuditEvent
+}
+
+func (suite *SinkTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.event = &audit.AuditEvent{
+		EventId:   "event-1",
+		Timestamp: "2019-03-01T10:00:00Z",
+		User:      "alice",
+		Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		JobIds:    []string{"job-1"},
+		Outcome:   audit.Outcome_OUTCOME_SUCCEEDED,
+	}
+}
+
+func (suite *SinkTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// TestNewSinkErrors tests creating sinks with invalid configs
+func (suite *SinkTestSuite) TestNewSinkErrors() {
+	configs := []*Config{
+		{Sink: CassandraSink},
+		{Sink: FileSink},
+		{Sink: StreamSink},
+		{Sink: "unknown"},
+	}
+
+	for _, cfg := range configs {
+		_, err := NewSink(cfg, nil, tally.NoopScope)
+		suite.Error(err, cfg.Sink)
+	}
+}
+
+// TestStoreSink tests recording audit events in the store
+func (suite *SinkTestSuite) TestStoreSink() {
+	auditEventOps := objectmocks.NewMockAuditEventOps(suite.ctrl)
+	sink := NewStoreSink(auditEventOps)
+
+	eventTime, err := time.Parse(time.RFC3339Nano, suite.event.GetTimestamp())
+	suite.NoError(err)
+	auditEventOps.EXPECT().
+		Add(gomock.Any(), eventTime, suite.event).
+		Return(nil)
+	suite.NoError(sink.Write(context.Background(), suite.event))
+
+	auditEventOps.EXPECT().
+		Add(gomock.Any(), eventTime, suite.event).
+		Return(errors.New("store error"))
+	suite.Error(sink.Write(context.Background(), suite.event))
+}
+
+// TestFileSink tests appending audit events to a file
+func (suite *SinkTestSuite) TestFileSink() {
+	dir, err := ioutil.TempDir("", "audit")
+	suite.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileSink(path)
+	suite.NoError(err)
+
+	suite.NoError(sink.Write(context.Background(), suite.event))
+	suite.NoError(sink.Write(context.Background(), suite.event))
+
+	file, err := os.Open(path)
+	suite.NoError(err)
+	defer file.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := &audit.AuditEvent{}
+		suite.NoError(jsonpb.UnmarshalString(scanner.Text(), event))
+		suite.Equal(suite.event.GetEventId(), event.GetEventId())
+		suite.Equal(suite.event.GetJobIds(), event.GetJobIds())
+		lines++
+	}
+	suite.Equal(2, lines)
+}
+
+// TestStreamSink tests publishing audit events to a Kafka REST proxy
+func (suite *SinkTestSuite) TestStreamSink() {
+	var received *audit.AuditEvent
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			suite.Equal("reliable", r.Header.Get("Producer-Type"))
+			received = &audit.AuditEvent{}
+			suite.NoError(jsonpb.Unmarshal(r.Body, received))
+			w.WriteHeader(status)
+		}))
+	defer server.Close()
+
+	sink := NewStreamSink(server.URL, server.Client())
+	suite.NoError(sink.Write(context.Background(), suite.event))
+	suite.Equal(suite.event.GetEventId(), received.GetEventId())
+
+	status = http.StatusInternalServerError
+	suite.Error(sink.Write(context.Background(), suite.event))
+}
+
+// TestAsyncSink tests writing audit events in the background
+func (suite *SinkTestSuite) TestAsyncSink() {
+	sink := &blockingSink{
+		started: make(chan *audit.AuditEvent),
+		release: make(chan struct{}),
+	}
+	scope := tally.NewTestScope("", nil)
+	asyncSink := NewAsyncSink(sink, 1, scope)
+
+	// the first event is being written by the background writer, the
+	// second one is buffered, and the third one is dropped
+	suite.NoError(asyncSink.Write(context.Background(), suite.event))
+	suite.Equal(suite.event, <-sink.started)
+	suite.NoError(asyncSink.Write(context.Background(), suite.event))
+	suite.Error(asyncSink.Write(context.Background(), suite.event))
+
+	// the second event is written once the first one failed
+	close(sink.release)
+	suite.Equal(suite.event, <-sink.started)
+
+	counters := scope.Snapshot().Counters()
+	suite.Equal(int64(1), counters["audit.dropped+"].Value())
+	suite.Equal(int64(1), counters["audit.write_fail+"].Value())
+}
+
+func TestSink(t *testing.T) {
+	suite.Run(t, new(SinkTestSuite))
+}
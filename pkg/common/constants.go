@@ -151,6 +151,10 @@ const (
 	// TaskThrottleMessage indicates that the task is throttled due to repeated failures.
 	TaskThrottleMessage = "Task throttled due to failure"
 
+	// TaskCrashLoopBackoffReason indicates that the restart of a throttled
+	// task is delayed because the task keeps failing.
+	TaskCrashLoopBackoffReason = "CRASH_LOOP_BACKOFF"
+
 	// DefaultHostPoolID is the ID of default host pool.
 	DefaultHostPoolID = "default"
 
@@ -0,0 +1,165 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/docker/leadership"
+	"github.com/docker/libkv/store"
+	"github.com/docker/libkv/store/zookeeper"
+)
+
+const (
+	// ZooKeeperBackend runs leader election on ZooKeeper.
+	ZooKeeperBackend = "zookeeper"
+
+	// EtcdBackend runs leader election on etcd using leases.
+	EtcdBackend = "etcd"
+
+	// LocalBackend runs leader election within the process, which is
+	// useful when all the Peloton components run in a single binary.
+	LocalBackend = "local"
+)
+
+// Backend is a coordination service on which candidates campaign for
+// leadership and observers follow the elected leaders.
+type Backend interface {
+	// NewCampaigner creates a campaigner which runs for the election at
+	// key, and publishes value once elected.
+	NewCampaigner(key string, value string) (Campaigner, error)
+
+	// NewFollower creates a follower of the election at key.
+	NewFollower(key string) (Follower, error)
+
+	// GetLeader returns the value published by the leader elected at key.
+	GetLeader(key string) (string, error)
+
+	// Close releases the clients of the backend. The campaigners and
+	// followers created by the backend must be stopped first.
+	Close() error
+}
+
+// Campaigner runs for an election on a backend.
+type Campaigner interface {
+	// RunForElection starts campaigning and returns a channel on which
+	// the election status is published, and a channel for errors.
+	RunForElection() (<-chan bool, <-chan error)
+
+	// IsLeader returns whether the campaigner is elected.
+	IsLeader() bool
+
+	// Resign gives up leadership and campaigns again.
+	Resign()
+
+	// Stop stops campaigning.
+	Stop()
+}
+
+// Follower follows an election on a backend.
+type Follower interface {
+	// FollowElection starts following the election and returns a channel
+	// on which the elected leaders are published, and a channel for errors.
+	FollowElection() (<-chan string, <-chan error)
+
+	// Stop stops following the election.
+	Stop()
+}
+
+// NewBackend creates the leader election backend configured in cfg.
+func NewBackend(cfg ElectionConfig) (Backend, error) {
+	switch cfg.Backend {
+	case "", ZooKeeperBackend:
+		return &zkBackend{servers: cfg.ZKServers}, nil
+	case EtcdBackend:
+		return newEtcdBackend(cfg.EtcdEndpoints)
+	case LocalBackend:
+		return _localBackend, nil
+	default:
+		return nil, fmt.Errorf("unknown leader election backend %s", cfg.Backend)
+	}
+}
+
+// zkBackend is the ZooKeeper based implementation of Backend
+type zkBackend struct {
+	sync.Mutex
+	servers []string
+
+	// client used to look up leaders, created on first use
+	client store.Store
+}
+
+// NewCampaigner creates a campaigner holding a ZooKeeper lock at key
+func (b *zkBackend) NewCampaigner(key string, value string) (Campaigner, error) {
+	client, err := zookeeper.New(
+		b.servers,
+		&store.Config{ConnectionTimeout: znodeEphemeralTimeout},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return leadership.NewCandidate(client, key, value, ttl), nil
+}
+
+// NewFollower creates a follower watching the ZooKeeper lock at key
+func (b *zkBackend) NewFollower(key string) (Follower, error) {
+	client, err := zookeeper.New(
+		b.servers,
+		&store.Config{ConnectionTimeout: zkConnErrRetry},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return leadership.NewFollower(client, key), nil
+}
+
+// GetLeader reads the value of the ZooKeeper lock at key
+func (b *zkBackend) GetLeader(key string) (string, error) {
+	b.Lock()
+	if b.client == nil {
+		client, err := zookeeper.New(
+			b.servers,
+			&store.Config{ConnectionTimeout: zkConnErrRetry},
+		)
+		if err != nil {
+			b.Unlock()
+			return "", err
+		}
+		b.client = client
+	}
+	client := b.client
+	b.Unlock()
+
+	kv, err := client.Get(key)
+	if err != nil {
+		return "", err
+	}
+	return string(kv.Value), nil
+}
+
+// Close closes the client used to look up leaders. The clients of the
+// campaigners and followers are owned by them, since they release their
+// ZooKeeper locks and watches asynchronously after being stopped.
+func (b *zkBackend) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.client != nil {
+		b.client.Close()
+		b.client = nil
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBackend(t *testing.T) {
+	backend, err := NewBackend(ElectionConfig{
+		ZKServers: []string{"1.1.1.1:2181"},
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &zkBackend{}, backend)
+
+	backend, err = NewBackend(ElectionConfig{Backend: LocalBackend})
+	assert.NoError(t, err)
+	assert.Equal(t, _localBackend, backend)
+
+	_, err = NewBackend(ElectionConfig{Backend: EtcdBackend})
+	assert.Error(t, err)
+
+	_, err = NewBackend(ElectionConfig{Backend: "consul"})
+	assert.Error(t, err)
+}
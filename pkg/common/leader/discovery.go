@@ -21,8 +21,6 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 
-	"github.com/docker/libkv/store"
-	"github.com/docker/libkv/store/zookeeper"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -72,43 +70,50 @@ func (s *staticDiscovery) GetAppURL(role string) (*url.URL, error) {
 	}
 }
 
-// NewZkServiceDiscovery creates a zkDiscovery object
+// NewZkServiceDiscovery creates a Discovery reading the app URLs of the
+// Peloton leaders from Zookeeper
 func NewZkServiceDiscovery(
 	zkServers []string,
 	zkRoot string) (Discovery, error) {
+	return NewServiceDiscovery(ElectionConfig{
+		Backend:   ZooKeeperBackend,
+		ZKServers: zkServers,
+		Root:      zkRoot,
+	})
+}
 
-	zkClient, err := zookeeper.New(
-		zkServers,
-		&store.Config{ConnectionTimeout: zkConnErrRetry},
-	)
+// NewServiceDiscovery creates a Discovery reading the app URLs of the
+// Peloton leaders from the leader election backend in cfg
+func NewServiceDiscovery(cfg ElectionConfig) (Discovery, error) {
+	backend, err := NewBackend(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	discovery := &zkDiscovery{
-		zkClient: zkClient,
-		zkRoot:   zkRoot,
+	discovery := &leaderDiscovery{
+		backend: backend,
+		root:    cfg.Root,
 	}
 	return discovery, nil
 }
 
-// zkDiscovery is the zk based implementation of Discovery
-type zkDiscovery struct {
-	zkClient store.Store
-	zkRoot   string
+// leaderDiscovery is the leader election backend based
+// implementation of Discovery
+type leaderDiscovery struct {
+	backend Backend
+	root    string
 }
 
-// GetAppURL reads app URL from Zookeeper for a given Peloton role
-func (s *zkDiscovery) GetAppURL(role string) (*url.URL, error) {
-	zkPath := leaderZkPath(s.zkRoot, role)
-	leader, err := s.zkClient.Get(zkPath)
+// GetAppURL reads app URL from the elected leader of a given Peloton role
+func (s *leaderDiscovery) GetAppURL(role string) (*url.URL, error) {
+	leader, err := s.backend.GetLeader(leaderZkPath(s.root, role))
 	if err != nil {
 		return nil, err
 	}
 
 	id := ID{}
-	if err := json.Unmarshal([]byte(leader.Value), &id); err != nil {
-		log.WithField("leader", leader.Value).Error("Failed to parse leader json")
+	if err := json.Unmarshal([]byte(leader), &id); err != nil {
+		log.WithField("leader", leader).Error("Failed to parse leader json")
 		return nil, err
 	}
 	return &url.URL{
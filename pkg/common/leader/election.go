@@ -22,9 +22,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/leadership"
-	"github.com/docker/libkv/store"
-	"github.com/docker/libkv/store/zookeeper"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 	"github.com/uber/peloton/pkg/common"
@@ -51,9 +48,16 @@ const (
 
 // ElectionConfig is config related to leader election of this service.
 type ElectionConfig struct {
+	// The backend to use for leader election, which is one of
+	// zookeeper, etcd or local. Defaults to zookeeper.
+	Backend string `yaml:"backend"`
+
 	// A comma separated list of ZK servers to use for leader election.
 	ZKServers []string `yaml:"zk_servers"`
 
+	// A list of etcd endpoints to use for leader election.
+	EtcdEndpoints []string `yaml:"etcd_endpoints"`
+
 	// The root path in ZK to use for role leader election.
 	// This will be something like /peloton/YOURCLUSTERHERE.
 	Root string `yaml:"root"`
@@ -66,7 +70,8 @@ type election struct {
 	running    bool
 	leader     string
 	role       string
-	candidate  *leadership.Candidate
+	backend    Backend
+	candidate  Campaigner
 	nomination Nomination
 	stopChan   chan struct{}
 }
@@ -84,10 +89,7 @@ func NewCandidate(
 			"for that isnt the empty string")
 	}
 
-	client, err := zookeeper.New(
-		cfg.ZKServers,
-		&store.Config{ConnectionTimeout: znodeEphemeralTimeout},
-	)
+	backend, err := NewBackend(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -103,12 +105,11 @@ func NewCandidate(
 		"leader_path": leaderPath,
 	}).Debug("Creating new Candidate")
 
-	candidate := leadership.NewCandidate(
-		client,
-		leaderPath,
-		nomination.GetID(),
-		ttl,
-	)
+	candidate, err := backend.NewCampaigner(leaderPath, nomination.GetID())
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
 	scope := parent.SubScope("election")
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -119,6 +120,7 @@ func NewCandidate(
 		metrics:    newElectionMetrics(scope, hostname),
 		role:       role,
 		nomination: nomination,
+		backend:    backend,
 		candidate:  candidate,
 		stopChan:   make(chan struct{}),
 	}
@@ -248,7 +250,7 @@ func (el *election) waitForEvent() error {
 				el.metrics.Error.Inc(1)
 				return err
 			}
-			// Just a shutdown signal from the docker/leadership lib, we can
+			// Just a shutdown signal from the election backend, we can
 			// propogate this and let the caller decide if we should continue to
 			// run, or terminate.
 			return nil
@@ -266,6 +268,11 @@ func (el *election) Stop() error {
 		el.running = false
 		close(el.stopChan)
 		el.candidate.Stop()
+		if err := el.backend.Close(); err != nil {
+			log.WithError(err).
+				WithField("role", el.role).
+				Warn("Failed to close leader election backend")
+		}
 		el.metrics.Stop.Inc(1)
 		el.metrics.Running.Update(0)
 		el.metrics.Resigned.Inc(1)
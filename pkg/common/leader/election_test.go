@@ -121,6 +121,7 @@ func TestLeaderElection(t *testing.T) {
 	el := election{
 		role:       role,
 		metrics:    newElectionMetrics(tally.NoopScope, "hostname"),
+		backend:    &zkBackend{},
 		candidate:  leadership.NewCandidate(mockStore, key, "testhost:666", ttl),
 		nomination: nomination,
 		stopChan:   make(chan struct{}, 1),
@@ -201,6 +202,7 @@ func TestLeaderElectionIfGainedLeadershipCallbackFails(t *testing.T) {
 	el := election{
 		role:       role,
 		metrics:    newElectionMetrics(tally.NoopScope, "hostname"),
+		backend:    &zkBackend{},
 		candidate:  leadership.NewCandidate(mockStore, key, "testhost:666", ttl),
 		nomination: nomination,
 		stopChan:   make(chan struct{}, 1),
@@ -252,6 +254,7 @@ func TestElectionStop(t *testing.T) {
 	el := election{
 		role:       role,
 		metrics:    newElectionMetrics(tally.NoopScope, "hostname"),
+		backend:    &zkBackend{},
 		candidate:  leadership.NewCandidate(mockStore, key, "testhost:666", ttl),
 		nomination: nomination,
 		stopChan:   make(chan struct{}, 1),
@@ -0,0 +1,346 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/concurrency"
+)
+
+const (
+	// etcdLeaseTTL is the TTL of the lease held by a candidate, after
+	// which its candidacy is dropped if it stops renewing the lease.
+	etcdLeaseTTL = 5 * time.Second
+
+	// etcdDialTimeout is the timeout to connect to the etcd endpoints.
+	etcdDialTimeout = 5 * time.Second
+)
+
+var errNoLeader = errors.New("no leader elected")
+
+// etcdBackend is the etcd based implementation of Backend. Candidates
+// campaign by creating a key attached to their lease under the election
+// prefix, and the candidate with the oldest key is the leader.
+type etcdBackend struct {
+	client *clientv3.Client
+}
+
+func newEtcdBackend(endpoints []string) (Backend, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no etcd endpoints specified")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdBackend{client: client}, nil
+}
+
+// NewCampaigner creates a campaigner for the election at key
+func (b *etcdBackend) NewCampaigner(key string, value string) (Campaigner, error) {
+	return &etcdCampaigner{
+		client:   b.client,
+		key:      key,
+		value:    value,
+		resignCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// NewFollower creates a follower of the election at key
+func (b *etcdBackend) NewFollower(key string) (Follower, error) {
+	return &etcdFollower{
+		client: b.client,
+		key:    key,
+		stopCh: make(chan struct{}),
+	}, nil
+}
+
+// GetLeader returns the value of the oldest candidate key at key
+func (b *etcdBackend) GetLeader(key string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdDialTimeout)
+	defer cancel()
+
+	leader, _, err := getEtcdLeader(ctx, b.client, key)
+	if err != nil {
+		return "", err
+	}
+	if len(leader) == 0 {
+		return "", errNoLeader
+	}
+	return leader, nil
+}
+
+// Close closes the etcd client shared by the campaigners and followers
+func (b *etcdBackend) Close() error {
+	return b.client.Close()
+}
+
+// getEtcdLeader returns the value of the leader elected at key, if any,
+// and the revision it was read at.
+func getEtcdLeader(
+	ctx context.Context,
+	client *clientv3.Client,
+	key string,
+) (string, int64, error) {
+	resp, err := client.Get(ctx, key+"/", clientv3.WithFirstCreate()...)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", resp.Header.Revision, nil
+	}
+	return string(resp.Kvs[0].Value), resp.Header.Revision, nil
+}
+
+// etcdCampaigner runs for an etcd election with a lease which is kept
+// alive for as long as the campaigner is running.
+type etcdCampaigner struct {
+	sync.Mutex
+	client   *clientv3.Client
+	key      string
+	value    string
+	leader   bool
+	resignCh chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// doneCh is closed once the running campaign returns
+	doneCh chan struct{}
+}
+
+// RunForElection starts campaigning in the background
+func (c *etcdCampaigner) RunForElection() (<-chan bool, <-chan error) {
+	electedCh := make(chan bool)
+	errCh := make(chan error, 1)
+	doneCh := make(chan struct{})
+
+	c.Lock()
+	c.doneCh = doneCh
+	c.Unlock()
+
+	go func() {
+		defer close(doneCh)
+		c.campaign(electedCh, errCh)
+	}()
+	return electedCh, errCh
+}
+
+// campaign campaigns for leadership until stopped or an error occurs.
+// Leadership is given up and campaigned for again on resignation, or
+// when the lease of the campaigner expires.
+func (c *etcdCampaigner) campaign(electedCh chan<- bool, errCh chan<- error) {
+	defer close(electedCh)
+
+	for {
+		// start as a follower
+		c.update(electedCh, false)
+
+		session, err := concurrency.NewSession(
+			c.client,
+			concurrency.WithTTL(int(etcdLeaseTTL/time.Second)),
+		)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-c.stopCh:
+			case <-session.Done():
+			case <-ctx.Done():
+			}
+			cancel()
+		}()
+
+		election := concurrency.NewElection(session, c.key)
+		if err := election.Campaign(ctx, c.value); err != nil {
+			cancel()
+			session.Close()
+			select {
+			case <-c.stopCh:
+			default:
+				errCh <- err
+			}
+			return
+		}
+
+		c.update(electedCh, true)
+
+		select {
+		case <-c.resignCh:
+			election.Resign(context.Background())
+			cancel()
+			session.Close()
+		case <-session.Done():
+			// the lease expired, campaign with a new one
+			cancel()
+		case <-c.stopCh:
+			election.Resign(context.Background())
+			cancel()
+			session.Close()
+			c.Lock()
+			c.leader = false
+			c.Unlock()
+			return
+		}
+	}
+}
+
+// update sets the election status and publishes it
+func (c *etcdCampaigner) update(electedCh chan<- bool, leader bool) {
+	c.Lock()
+	c.leader = leader
+	c.Unlock()
+	electedCh <- leader
+}
+
+// IsLeader returns whether the campaigner is elected
+func (c *etcdCampaigner) IsLeader() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.leader
+}
+
+// Resign gives up leadership if elected
+func (c *etcdCampaigner) Resign() {
+	if !c.IsLeader() {
+		return
+	}
+	select {
+	case c.resignCh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops campaigning. It waits for the campaign to resign, so that
+// the client can be closed afterwards and another candidate is elected
+// without waiting for the lease to expire.
+func (c *etcdCampaigner) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+
+	c.Lock()
+	doneCh := c.doneCh
+	c.Unlock()
+	if doneCh == nil {
+		return
+	}
+
+	select {
+	case <-doneCh:
+	case <-time.After(etcdDialTimeout):
+	}
+}
+
+// etcdFollower watches an etcd election for leadership changes
+type etcdFollower struct {
+	client   *clientv3.Client
+	key      string
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// FollowElection starts following the election in the background
+func (f *etcdFollower) FollowElection() (<-chan string, <-chan error) {
+	leaderCh := make(chan string)
+	errCh := make(chan error, 1)
+	go f.follow(leaderCh, errCh)
+	return leaderCh, errCh
+}
+
+// follow publishes the elected leader whenever it changes, until
+// stopped or an error occurs.
+func (f *etcdFollower) follow(leaderCh chan<- string, errCh chan<- error) {
+	defer close(leaderCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-f.stopCh:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	var current string
+	for {
+		leader, rev, err := getEtcdLeader(ctx, f.client, f.key)
+		if err != nil {
+			if !f.stopped() {
+				errCh <- err
+			}
+			return
+		}
+
+		if len(leader) != 0 && leader != current {
+			current = leader
+			select {
+			case leaderCh <- leader:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// wait for the candidates to change before looking up the
+		// leader again
+		watchCh := f.client.Watch(
+			ctx,
+			f.key+"/",
+			clientv3.WithPrefix(),
+			clientv3.WithRev(rev+1),
+		)
+		resp, ok := <-watchCh
+		if !ok || f.stopped() {
+			return
+		}
+		if err := resp.Err(); err != nil {
+			errCh <- err
+			return
+		}
+	}
+}
+
+// stopped returns whether the follower is stopped. The client may be
+// closed right after the follower is stopped, so the errors which
+// follow are not reported.
+func (f *etcdFollower) stopped() bool {
+	select {
+	case <-f.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops following the election
+func (f *etcdFollower) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stopCh)
+	})
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/embed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+// localURL returns a URL on a free local port
+func localURL(t *testing.T) url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+// startEtcd starts a single member etcd server in a temporary directory,
+// and returns its client endpoints along with a function to stop it.
+func startEtcd(t *testing.T) ([]string, func()) {
+	dir, err := ioutil.TempDir("", "peloton-etcd")
+	require.NoError(t, err)
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	clientURL := localURL(t)
+	peerURL := localURL(t)
+	cfg.LCUrls, cfg.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		os.RemoveAll(dir)
+		t.Fatal("etcd server took too long to start")
+	}
+
+	return []string{clientURL.Host}, func() {
+		e.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNewEtcdBackendNoEndpoints(t *testing.T) {
+	_, err := newEtcdBackend(nil)
+	assert.Error(t, err)
+}
+
+func TestEtcdLeaderElection(t *testing.T) {
+	endpoints, stop := startEtcd(t)
+	defer stop()
+
+	config := ElectionConfig{
+		Backend:       EtcdBackend,
+		EtcdEndpoints: endpoints,
+		Root:          "/peloton/etcdtest",
+	}
+	role := "testrole"
+
+	nomination1 := &testComponent{
+		host:   "testhost1",
+		port:   "666",
+		events: make(chan string, 100),
+	}
+	nomination2 := &testComponent{
+		host:   "testhost2",
+		port:   "666",
+		events: make(chan string, 100),
+	}
+
+	leaders := make(chan string, 100)
+	o, err := NewObserver(config, tally.NoopScope, role, func(leader string) error {
+		leaders <- leader
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NoError(t, o.Start())
+
+	el1, err := NewCandidate(config, tally.NoopScope, role, nomination1)
+	require.NoError(t, err)
+	assert.NoError(t, el1.Start())
+
+	// the first candidate gets elected
+	assert.Equal(t, "leadership_lost", <-nomination1.events)
+	assert.Equal(t, "leadership_gained", <-nomination1.events)
+	assert.True(t, el1.IsLeader())
+	assert.Equal(t, "testhost1:666", <-leaders)
+
+	el2, err := NewCandidate(config, tally.NoopScope, role, nomination2)
+	require.NoError(t, err)
+	assert.NoError(t, el2.Start())
+	assert.Equal(t, "leadership_lost", <-nomination2.events)
+	assert.False(t, el2.IsLeader())
+
+	// the second candidate takes over once the leader stops, without
+	// waiting for the lease of the leader to expire
+	stopTime := time.Now()
+	assert.NoError(t, el1.Stop())
+	assert.Equal(t, "shutdown", <-nomination1.events)
+	assert.False(t, el1.IsLeader())
+	assert.Equal(t, "leadership_gained", <-nomination2.events)
+	assert.True(t, el2.IsLeader())
+	assert.True(t, time.Since(stopTime) < etcdLeaseTTL)
+	assert.Equal(t, "testhost2:666", <-leaders)
+
+	// the only candidate is elected again after resigning
+	el2.Resign()
+	assert.Equal(t, "leadership_lost", <-nomination2.events)
+	assert.Equal(t, "leadership_gained", <-nomination2.events)
+	assert.True(t, el2.IsLeader())
+
+	assert.NoError(t, el2.Stop())
+	assert.Equal(t, "shutdown", <-nomination2.events)
+	o.Stop()
+}
+
+func TestEtcdServiceDiscovery(t *testing.T) {
+	endpoints, stop := startEtcd(t)
+	defer stop()
+
+	backend, err := newEtcdBackend(endpoints)
+	require.NoError(t, err)
+	defer backend.Close()
+
+	discovery := &leaderDiscovery{
+		backend: backend,
+		root:    "/peloton",
+	}
+
+	_, err = discovery.GetAppURL("testrole")
+	assert.Error(t, err)
+
+	campaigner, err := backend.NewCampaigner(
+		leaderZkPath("/peloton", "testrole"),
+		`{"hostname":"testhost","ip":"10.0.0.1","http":5291,"grpc":5391}`,
+	)
+	require.NoError(t, err)
+
+	electedCh, _ := campaigner.RunForElection()
+	assert.False(t, <-electedCh)
+	assert.True(t, <-electedCh)
+	assert.True(t, campaigner.IsLeader())
+
+	url, err := discovery.GetAppURL("testrole")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:5391", url.Host)
+
+	// the campaign has resigned once stopped
+	campaigner.Stop()
+	for range electedCh {
+	}
+	assert.False(t, campaigner.IsLeader())
+
+	_, err = discovery.GetAppURL("testrole")
+	assert.Error(t, err)
+}
+
+func TestEtcdFollowerStop(t *testing.T) {
+	endpoints, stop := startEtcd(t)
+	defer stop()
+
+	backend, err := newEtcdBackend(endpoints)
+	require.NoError(t, err)
+
+	follower, err := backend.NewFollower("peloton/testrole/leader")
+	require.NoError(t, err)
+
+	leaderCh, errCh := follower.FollowElection()
+	follower.Stop()
+	follower.Stop()
+	assert.NoError(t, backend.Close())
+
+	// the follower stops without reporting the errors of the
+	// closed client
+	for range leaderCh {
+	}
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	default:
+	}
+}
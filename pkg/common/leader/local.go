@@ -0,0 +1,274 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"sync"
+)
+
+// _localBackend is shared by all the candidates and observers of the
+// process using the local backend.
+var _localBackend = newLocalBackend()
+
+// localBackend is the in-process implementation of Backend
+type localBackend struct {
+	sync.Mutex
+	elections map[string]*localElection
+}
+
+func newLocalBackend() *localBackend {
+	return &localBackend{
+		elections: make(map[string]*localElection),
+	}
+}
+
+// election returns the election at key, creating it if needed
+func (b *localBackend) election(key string) *localElection {
+	b.Lock()
+	defer b.Unlock()
+
+	e, ok := b.elections[key]
+	if !ok {
+		e = &localElection{changed: make(chan struct{})}
+		b.elections[key] = e
+	}
+	return e
+}
+
+// NewCampaigner creates a campaigner for the election at key
+func (b *localBackend) NewCampaigner(key string, value string) (Campaigner, error) {
+	return &localCampaigner{
+		election: b.election(key),
+		value:    value,
+		resignCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// NewFollower creates a follower of the election at key
+func (b *localBackend) NewFollower(key string) (Follower, error) {
+	return &localFollower{
+		election: b.election(key),
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// GetLeader returns the value of the leader elected at key
+func (b *localBackend) GetLeader(key string) (string, error) {
+	leader, _ := b.election(key).state()
+	if leader == nil {
+		return "", errNoLeader
+	}
+	return leader.value, nil
+}
+
+// Close does nothing since the local backend is shared by the process
+func (b *localBackend) Close() error {
+	return nil
+}
+
+// localElection holds the campaigners of an election in the order they
+// joined it. The first campaigner is the leader.
+type localElection struct {
+	sync.Mutex
+	campaigners []*localCampaigner
+
+	// changed is closed whenever the campaigners change
+	changed chan struct{}
+}
+
+// join adds a campaigner to the election
+func (e *localElection) join(c *localCampaigner) {
+	e.Lock()
+	defer e.Unlock()
+	e.campaigners = append(e.campaigners, c)
+	e.notify()
+}
+
+// leave removes a campaigner from the election
+func (e *localElection) leave(c *localCampaigner) {
+	e.Lock()
+	defer e.Unlock()
+	for i, campaigner := range e.campaigners {
+		if campaigner == c {
+			e.campaigners = append(e.campaigners[:i], e.campaigners[i+1:]...)
+			e.notify()
+			return
+		}
+	}
+}
+
+// state returns the leader of the election, and a channel which is
+// closed when the campaigners change.
+func (e *localElection) state() (*localCampaigner, <-chan struct{}) {
+	e.Lock()
+	defer e.Unlock()
+	if len(e.campaigners) == 0 {
+		return nil, e.changed
+	}
+	return e.campaigners[0], e.changed
+}
+
+// notify wakes up everyone waiting on a change, the caller
+// must hold the lock.
+func (e *localElection) notify() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// localCampaigner runs for an in-process election
+type localCampaigner struct {
+	sync.Mutex
+	election *localElection
+	value    string
+	leader   bool
+	resignCh chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// RunForElection starts campaigning in the background
+func (c *localCampaigner) RunForElection() (<-chan bool, <-chan error) {
+	electedCh := make(chan bool)
+	errCh := make(chan error, 1)
+	go c.campaign(electedCh)
+	return electedCh, errCh
+}
+
+// campaign campaigns for leadership until stopped, and campaigns
+// again after resigning.
+func (c *localCampaigner) campaign(electedCh chan<- bool) {
+	defer close(electedCh)
+
+	for {
+		// start as a follower
+		c.update(electedCh, false)
+
+		c.election.join(c)
+		if !c.waitForLeadership() {
+			c.election.leave(c)
+			return
+		}
+
+		c.update(electedCh, true)
+
+		select {
+		case <-c.resignCh:
+			c.election.leave(c)
+		case <-c.stopCh:
+			c.election.leave(c)
+			c.Lock()
+			c.leader = false
+			c.Unlock()
+			return
+		}
+	}
+}
+
+// waitForLeadership blocks until the campaigner is elected and returns
+// true, or returns false if the campaigner is stopped first.
+func (c *localCampaigner) waitForLeadership() bool {
+	for {
+		leader, changed := c.election.state()
+		if leader == c {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-c.stopCh:
+			return false
+		}
+	}
+}
+
+// update sets the election status and publishes it
+func (c *localCampaigner) update(electedCh chan<- bool, leader bool) {
+	c.Lock()
+	c.leader = leader
+	c.Unlock()
+	electedCh <- leader
+}
+
+// IsLeader returns whether the campaigner is elected
+func (c *localCampaigner) IsLeader() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.leader
+}
+
+// Resign gives up leadership if elected
+func (c *localCampaigner) Resign() {
+	if !c.IsLeader() {
+		return
+	}
+	select {
+	case c.resignCh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops campaigning
+func (c *localCampaigner) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+}
+
+// localFollower follows an in-process election
+type localFollower struct {
+	election *localElection
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// FollowElection starts following the election in the background
+func (f *localFollower) FollowElection() (<-chan string, <-chan error) {
+	leaderCh := make(chan string)
+	errCh := make(chan error, 1)
+	go f.follow(leaderCh)
+	return leaderCh, errCh
+}
+
+// follow publishes the elected leader whenever it changes, until stopped
+func (f *localFollower) follow(leaderCh chan<- string) {
+	defer close(leaderCh)
+
+	var current string
+	for {
+		leader, changed := f.election.state()
+		if leader != nil && leader.value != current {
+			current = leader.value
+			select {
+			case leaderCh <- current:
+			case <-f.stopCh:
+				return
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-f.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops following the election
+func (f *localFollower) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stopCh)
+	})
+}
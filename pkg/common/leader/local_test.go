@@ -0,0 +1,115 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+func TestLocalLeaderElection(t *testing.T) {
+	config := ElectionConfig{
+		Backend: LocalBackend,
+		Root:    "/peloton/localtest",
+	}
+	role := "testrole"
+
+	nomination1 := &testComponent{
+		host:   "testhost1",
+		port:   "666",
+		events: make(chan string, 100),
+	}
+	nomination2 := &testComponent{
+		host:   "testhost2",
+		port:   "666",
+		events: make(chan string, 100),
+	}
+
+	leaders := make(chan string, 100)
+	o, err := NewObserver(config, tally.NoopScope, role, func(leader string) error {
+		leaders <- leader
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, o.Start())
+
+	el1, err := NewCandidate(config, tally.NoopScope, role, nomination1)
+	assert.NoError(t, err)
+	assert.NoError(t, el1.Start())
+
+	// the first candidate gets elected
+	assert.Equal(t, "leadership_lost", <-nomination1.events)
+	assert.Equal(t, "leadership_gained", <-nomination1.events)
+	assert.True(t, el1.IsLeader())
+	assert.Equal(t, "testhost1:666", <-leaders)
+
+	el2, err := NewCandidate(config, tally.NoopScope, role, nomination2)
+	assert.NoError(t, err)
+	assert.NoError(t, el2.Start())
+	assert.Equal(t, "leadership_lost", <-nomination2.events)
+	assert.False(t, el2.IsLeader())
+
+	// the second candidate takes over once the leader stops
+	assert.NoError(t, el1.Stop())
+	assert.Equal(t, "shutdown", <-nomination1.events)
+	assert.False(t, el1.IsLeader())
+	assert.Equal(t, "leadership_gained", <-nomination2.events)
+	assert.True(t, el2.IsLeader())
+	assert.Equal(t, "testhost2:666", <-leaders)
+
+	// the only candidate is elected again after resigning
+	el2.Resign()
+	assert.Equal(t, "leadership_lost", <-nomination2.events)
+	assert.Equal(t, "leadership_gained", <-nomination2.events)
+	assert.True(t, el2.IsLeader())
+
+	assert.NoError(t, el2.Stop())
+	assert.Equal(t, "shutdown", <-nomination2.events)
+	o.Stop()
+}
+
+func TestLocalServiceDiscovery(t *testing.T) {
+	backend := newLocalBackend()
+	discovery := &leaderDiscovery{
+		backend: backend,
+		root:    "/peloton",
+	}
+
+	_, err := discovery.GetAppURL("testrole")
+	assert.Error(t, err)
+
+	campaigner, err := backend.NewCampaigner(
+		leaderZkPath("/peloton", "testrole"),
+		`{"hostname":"testhost","ip":"10.0.0.1","http":5291,"grpc":5391}`,
+	)
+	assert.NoError(t, err)
+
+	electedCh, _ := campaigner.RunForElection()
+	assert.False(t, <-electedCh)
+	assert.True(t, <-electedCh)
+
+	url, err := discovery.GetAppURL("testrole")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:5391", url.Host)
+
+	campaigner.Stop()
+	for range electedCh {
+	}
+
+	_, err = discovery.GetAppURL("testrole")
+	assert.Error(t, err)
+}
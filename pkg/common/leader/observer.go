@@ -19,9 +19,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/leadership"
-	"github.com/docker/libkv/store"
-	"github.com/docker/libkv/store/zookeeper"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 )
@@ -37,7 +34,8 @@ type Observer interface {
 type observer struct {
 	sync.Mutex
 	metrics  observerMetrics
-	follower *leadership.Follower
+	backend  Backend
+	follower Follower
 	role     string
 	callback func(string) error
 	leader   string
@@ -49,15 +47,21 @@ type observer struct {
 // a given `role`, and will call newLeaderCallback whenever leadership changes
 func NewObserver(cfg ElectionConfig, scope tally.Scope, role string, newLeaderCallback func(string) error) (Observer, error) {
 	log.WithFields(log.Fields{"role": role}).Debug("Creating new observer of election")
-	client, err := zookeeper.New(cfg.ZKServers, &store.Config{ConnectionTimeout: zkConnErrRetry})
+	backend, err := NewBackend(cfg)
 	if err != nil {
 		return nil, err
 	}
+	follower, err := backend.NewFollower(leaderZkPath(cfg.Root, role))
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
 	obs := observer{
 		role:     role,
 		metrics:  newObserverMetrics(scope, role),
 		callback: newLeaderCallback,
-		follower: leadership.NewFollower(client, leaderZkPath(cfg.Root, role)),
+		backend:  backend,
+		follower: follower,
 		stopChan: make(chan struct{}),
 	}
 	return &obs, nil
@@ -89,6 +93,10 @@ func (o *observer) Stop() {
 		o.running = false
 		close(o.stopChan)
 		o.follower.Stop()
+		if err := o.backend.Close(); err != nil {
+			log.WithFields(log.Fields{"role": o.role, "error": err}).
+				Warn("Failed to close leader election backend")
+		}
 		o.metrics.Stop.Inc(1)
 		o.metrics.Running.Update(0)
 	}
@@ -131,7 +139,7 @@ func (o *observer) waitForEvent() error {
 				o.metrics.Error.Inc(1)
 				return err
 			}
-			// just a shutdown signal from the docker/leadership lib,
+			// just a shutdown signal from the election backend,
 			// we can propogate this and let the caller decide if we
 			// should continue to run, or terminate
 			return nil
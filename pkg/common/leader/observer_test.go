@@ -46,6 +46,7 @@ func TestObserver(t *testing.T) {
 	mockStore.On("Watch", key, mock.Anything).Return(mockKVCh, nil)
 
 	o := observer{
+		backend:  &zkBackend{},
 		follower: leadership.NewFollower(mockStore, key),
 		role:     role,
 		metrics:  newObserverMetrics(tally.NoopScope, "testobserverrole"),
@@ -112,6 +113,7 @@ func TestObserverStop(t *testing.T) {
 
 	o := observer{
 		role:     role,
+		backend:  &zkBackend{},
 		follower: leadership.NewFollower(kv, key),
 		metrics:  newObserverMetrics(tally.NoopScope, "testobserverrole"),
 		stopChan: make(chan struct{}),
@@ -16,6 +16,7 @@ package task
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -30,9 +31,12 @@ import (
 // ConvertToResMgrGangs converts the taskinfo for the tasks comprising
 // the config job to resmgr tasks and organizes them into gangs, each
 // of which is a set of 1+ tasks to be admitted and placed as a group.
+// The deadline of the tasks is computed from the submission time in
+// jobRuntime, which can be nil for the jobs without a max running time.
 func ConvertToResMgrGangs(
 	tasks []*task.TaskInfo,
-	jobConfig jobmgrcommon.JobConfig) []*resmgrsvc.Gang {
+	jobConfig jobmgrcommon.JobConfig,
+	jobRuntime *job.RuntimeInfo) []*resmgrsvc.Gang {
 	var gangs []*resmgrsvc.Gang
 
 	// Gangs of multiple tasks are placed at the front of the returned list for
@@ -41,12 +45,12 @@ func ConvertToResMgrGangs(
 	var multiTaskGangs []*resmgrsvc.Gang
 
 	for _, t := range tasks {
-		resmgrtask := ConvertTaskToResMgrTask(t, jobConfig)
+		resmgrtask := ConvertTaskToResMgrTask(t, jobConfig, jobRuntime)
 		// Currently a job has at most 1 gang comprising multiple tasks;
 		// those tasks have their MinInstances field set > 1.
 		if resmgrtask.MinInstances > 1 &&
 			!resmgrtask.GetRevocable() &&
-			jobConfig.GetType() != job.JobType_SERVICE {
+			!util.IsLongRunningJobType(jobConfig.GetType()) {
 			if len(multiTaskGangs) == 0 {
 				var multiTaskGang resmgrsvc.Gang
 				multiTaskGangs = append(multiTaskGangs, &multiTaskGang)
@@ -65,10 +69,12 @@ func ConvertToResMgrGangs(
 	return gangs
 }
 
-// ConvertTaskToResMgrTask converts taskinfo to resmgr task.
+// ConvertTaskToResMgrTask converts taskinfo to resmgr task. jobRuntime
+// can be nil for the jobs without a max running time.
 func ConvertTaskToResMgrTask(
 	taskInfo *task.TaskInfo,
-	jobConfig jobmgrcommon.JobConfig) *resmgr.Task {
+	jobConfig jobmgrcommon.JobConfig,
+	jobRuntime *job.RuntimeInfo) *resmgr.Task {
 	instanceID := taskInfo.GetInstanceId()
 	taskID := &peloton.TaskID{
 		Value: fmt.Sprintf(
@@ -106,22 +112,28 @@ func ConvertTaskToResMgrTask(
 	}
 
 	resmgrTask := &resmgr.Task{
-		Id:                taskID,
-		JobId:             taskInfo.GetJobId(),
-		TaskId:            taskInfo.GetRuntime().GetMesosTaskId(),
-		Name:              taskInfo.GetConfig().GetName(),
-		Preemptible:       preemptible,
-		Priority:          slaConfig.GetPriority(),
-		MinInstances:      minInstances,
-		Resource:          taskInfo.GetConfig().GetResource(),
-		Constraint:        taskInfo.GetConfig().GetConstraint(),
-		NumPorts:          uint32(numPorts),
-		Type:              getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
-		Labels:            util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
-		Controller:        taskInfo.GetConfig().GetController(),
-		Revocable:         taskInfo.GetConfig().GetRevocable(),
-		DesiredHost:       taskInfo.GetRuntime().GetDesiredHost(),
-		PlacementStrategy: jobConfig.GetPlacementStrategy(),
+		Id:                             taskID,
+		JobId:                          taskInfo.GetJobId(),
+		TaskId:                         taskInfo.GetRuntime().GetMesosTaskId(),
+		Name:                           taskInfo.GetConfig().GetName(),
+		Preemptible:                    preemptible,
+		Priority:                       slaConfig.GetPriority(),
+		MinInstances:                   minInstances,
+		Resource:                       taskInfo.GetConfig().GetResource(),
+		Constraint:                     taskInfo.GetConfig().GetConstraint(),
+		NumPorts:                       uint32(numPorts),
+		Type:                           getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
+		Labels:                         util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
+		Controller:                     taskInfo.GetConfig().GetController(),
+		Revocable:                      taskInfo.GetConfig().GetRevocable(),
+		DesiredHost:                    taskInfo.GetRuntime().GetDesiredHost(),
+		PlacementStrategy:              jobConfig.GetPlacementStrategy(),
+		Deadline:                       getDeadline(jobConfig, jobRuntime),
+		FailureCount:                   taskInfo.GetRuntime().GetFailureCount(),
+		JobInstanceCount:               jobConfig.GetInstanceCount(),
+		JobMinimumRunningInstances:     slaConfig.GetMinimumRunningInstances(),
+		JobMaximumUnavailableInstances: slaConfig.GetMaximumUnavailableInstances(),
+		JobLabels:                      jobConfig.GetLabels(),
 	}
 
 	taskState := taskInfo.GetRuntime().GetState()
@@ -139,6 +151,23 @@ func ConvertTaskToResMgrTask(
 	return resmgrTask
 }
 
+// returns the deadline of the tasks of the job in nanoseconds since epoch,
+// computed from the job submission time and the SLA max running time.
+// The submission time is the creation time of the job runtime, which
+// unlike the creation time of the job config is kept by updates.
+// Returns zero if the job does not have a max running time.
+func getDeadline(
+	jobConfig jobmgrcommon.JobConfig,
+	jobRuntime *job.RuntimeInfo) uint64 {
+	maxRunningTime := jobConfig.GetSLA().GetMaxRunningTime()
+	submittedAt := jobRuntime.GetRevision().GetCreatedAt()
+	if maxRunningTime == 0 || submittedAt == 0 {
+		return 0
+	}
+	return submittedAt +
+		uint64(time.Duration(maxRunningTime)*time.Second)
+}
+
 // returns the task type
 func getTaskType(cfg *task.TaskConfig, jobType job.JobType) resmgr.TaskType {
 	if cfg.GetVolume() != nil {
@@ -148,6 +177,10 @@ func getTaskType(cfg *task.TaskConfig, jobType job.JobType) resmgr.TaskType {
 	if jobType == job.JobType_SERVICE {
 		return resmgr.TaskType_STATELESS
 	}
+
+	if jobType == job.JobType_DAEMON {
+		return resmgr.TaskType_DAEMON
+	}
 	// By default task type is batch.
 	return resmgr.TaskType_BATCH
 }
@@ -16,6 +16,7 @@ package task
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,11 @@ func TestGetTaskType(t *testing.T) {
 			jobType:  job.JobType_SERVICE,
 			taskType: resmgr.TaskType_STATELESS,
 		},
+		{
+			cfg:      &task.TaskConfig{},
+			jobType:  job.JobType_DAEMON,
+			taskType: resmgr.TaskType_DAEMON,
+		},
 	}
 
 	for _, test := range tt {
@@ -56,6 +62,29 @@ func TestGetTaskType(t *testing.T) {
 	}
 }
 
+func TestGetDeadline(t *testing.T) {
+	jobRuntime := &job.RuntimeInfo{
+		Revision: &peloton.ChangeLog{CreatedAt: 1000},
+	}
+
+	assert.Equal(t, uint64(0), getDeadline(&job.JobConfig{
+		SLA:       &job.SlaConfig{},
+		ChangeLog: &peloton.ChangeLog{CreatedAt: 1000},
+	}, jobRuntime))
+
+	// the deadline is computed from the job submission time, and not
+	// from the creation time of the current config version
+	assert.Equal(t, uint64(1000+60*time.Second), getDeadline(&job.JobConfig{
+		SLA:       &job.SlaConfig{MaxRunningTime: 60},
+		ChangeLog: &peloton.ChangeLog{CreatedAt: 5000, Version: 2},
+	}, jobRuntime))
+
+	// no deadline without the job submission time
+	assert.Equal(t, uint64(0), getDeadline(&job.JobConfig{
+		SLA: &job.SlaConfig{MaxRunningTime: 60},
+	}, nil))
+}
+
 func TestConvertTaskToResMgrTask(t *testing.T) {
 	jobID := peloton.JobID{Value: uuid.New()}
 	taskInfos := []*task.TaskInfo{
@@ -127,9 +156,10 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 	jobConfig := &job.JobConfig{
 		SLA:               &job.SlaConfig{},
 		PlacementStrategy: job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
+		Labels:            []*peloton.Label{{Key: "team", Value: "infra"}},
 	}
 	for _, taskInfo := range taskInfos {
-		rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig)
+		rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig, nil)
 		assert.Equal(t, taskInfo.JobId.Value, rmTask.JobId.Value)
 		assert.Equal(t, uint32(len(taskInfo.Config.Ports)), rmTask.NumPorts)
 		taskState := taskInfo.Runtime.GetState()
@@ -144,6 +174,7 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 			t,
 			job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
 			rmTask.GetPlacementStrategy())
+		assert.Equal(t, jobConfig.GetLabels(), rmTask.GetJobLabels())
 	}
 }
 
@@ -168,7 +199,8 @@ func TestConvertToResMgrGangs(t *testing.T) {
 			{
 				InstanceId: 3,
 			}},
-		jobConfig)
+		jobConfig,
+		nil)
 
 	assert.Len(t, gangs, 3)
 }
@@ -267,7 +299,7 @@ func TestConvertTaskToResMgrTaskPreemptible(t *testing.T) {
 	}
 
 	for _, test := range tt {
-		r := ConvertTaskToResMgrTask(test.taskInfo, test.jobConfig)
+		r := ConvertTaskToResMgrTask(test.taskInfo, test.jobConfig, nil)
 		assert.Equal(t, test.preemptible, r.Preemptible, test.name)
 	}
 }
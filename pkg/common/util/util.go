@@ -350,6 +350,13 @@ func IsPelotonJobStateTerminal(state job.JobState) bool {
 	}
 }
 
+// IsLongRunningJobType returns true if the instances of jobs of the given
+// type are expected to keep running until they are stopped, i.e. service
+// and daemon jobs.
+func IsLongRunningJobType(jobType job.JobType) bool {
+	return jobType == job.JobType_SERVICE || jobType == job.JobType_DAEMON
+}
+
 // IsTaskHasValidVolume returns true if a task is stateful and has a valid volume
 func IsTaskHasValidVolume(taskInfo *task.TaskInfo) bool {
 	if taskInfo.GetConfig().GetVolume() != nil &&
@@ -506,6 +506,13 @@ func TestJobTerminalState(t *testing.T) {
 	}
 }
 
+// Test check for long running job types
+func TestIsLongRunningJobType(t *testing.T) {
+	assert.True(t, IsLongRunningJobType(job.JobType_SERVICE))
+	assert.True(t, IsLongRunningJobType(job.JobType_DAEMON))
+	assert.False(t, IsLongRunningJobType(job.JobType_BATCH))
+}
+
 // Test check for task state being terminal
 func TestTaskTerminalState(t *testing.T) {
 	taskTerminalStates := map[task.TaskState]bool{
@@ -70,6 +70,9 @@ type Config struct {
 	// Host Drainer Period
 	HostDrainerPeriod time.Duration `yaml:"host_drainer_period"`
 
+	// Period to start and complete maintenance windows
+	MaintenanceWindowPeriod time.Duration `yaml:"maintenance_window_period"`
+
 	// Represents scarce resource types such as GPU.
 	ScarceResourceTypes []string `yaml:"scarce_resource_types"`
 
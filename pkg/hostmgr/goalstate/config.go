@@ -20,6 +20,7 @@ const (
 	_defaultMaxRetryDelay     = 60 * time.Minute
 	_defaultFailureRetryDelay = 10 * time.Second
 	_defaultHostWorkerThreads = 10
+	_defaultDrainBudgetDelay  = 30 * time.Second
 )
 
 // Config for the goalstate engine.
@@ -32,6 +33,17 @@ type Config struct {
 	FailureRetryDelay time.Duration `yaml:"failure_retry_delay"`
 
 	NumWorkerHostThreads int `yaml:"host_worker_thread_count"`
+
+	// MaxDrainingHosts is the maximum number of hosts which can be
+	// draining at the same time across the cluster. 0 means no limit.
+	MaxDrainingHosts int `yaml:"max_draining_hosts"`
+	// MaxDrainingHostsPerPool is the maximum number of hosts of a host pool
+	// which can be draining at the same time. 0 means no limit.
+	MaxDrainingHostsPerPool int `yaml:"max_draining_hosts_per_pool"`
+	// DrainBudgetRetryDelay is the delay after which a host which could not
+	// start draining, because the drain budget was exhausted, is evaluated
+	// again.
+	DrainBudgetRetryDelay time.Duration `yaml:"drain_budget_retry_delay"`
 }
 
 // normalize configuration by setting unassigned fields to default values.
@@ -45,4 +57,7 @@ func (c *Config) normalize() {
 	if c.NumWorkerHostThreads == 0 {
 		c.NumWorkerHostThreads = _defaultHostWorkerThreads
 	}
+	if c.DrainBudgetRetryDelay == 0 {
+		c.DrainBudgetRetryDelay = _defaultDrainBudgetDelay
+	}
 }
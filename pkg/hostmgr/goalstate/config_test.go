@@ -28,4 +28,7 @@ func TestConfigNormalize(t *testing.T) {
 	assert.Equal(t, _defaultMaxRetryDelay, c.MaxRetryDelay)
 	assert.Equal(t, _defaultFailureRetryDelay, c.FailureRetryDelay)
 	assert.Equal(t, _defaultHostWorkerThreads, c.NumWorkerHostThreads)
+	assert.Equal(t, _defaultDrainBudgetDelay, c.DrainBudgetRetryDelay)
+	assert.Zero(t, c.MaxDrainingHosts)
+	assert.Zero(t, c.MaxDrainingHostsPerPool)
 }
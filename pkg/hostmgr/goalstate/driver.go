@@ -20,6 +20,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+
 	"github.com/uber/peloton/pkg/common/goalstate"
 	"github.com/uber/peloton/pkg/hostmgr/hostpool/manager"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
@@ -206,6 +208,42 @@ func (d *driver) DeleteHost(hostname string) {
 	d.hostEngine.Delete(hostEntity)
 }
 
+// hasDrainBudget returns true if one more host of the given host pool can
+// start draining without exceeding the cluster-wide and per-pool drain
+// budgets. Hosts which are DRAINED but not yet DOWN count towards the
+// budgets as well.
+func (d *driver) hasDrainBudget(ctx context.Context, pool string) (bool, error) {
+	if d.cfg.MaxDrainingHosts == 0 && d.cfg.MaxDrainingHostsPerPool == 0 {
+		return true, nil
+	}
+
+	hostInfos, err := d.hostInfoOps.GetAll(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var draining, drainingInPool int
+	for _, h := range hostInfos {
+		if h.GetState() != hpb.HostState_HOST_STATE_DRAINING &&
+			h.GetState() != hpb.HostState_HOST_STATE_DRAINED {
+			continue
+		}
+		draining++
+		if len(pool) != 0 && h.GetCurrentPool() == pool {
+			drainingInPool++
+		}
+	}
+
+	if d.cfg.MaxDrainingHosts > 0 && draining >= d.cfg.MaxDrainingHosts {
+		return false, nil
+	}
+	if d.cfg.MaxDrainingHostsPerPool > 0 && len(pool) != 0 &&
+		drainingInPool >= d.cfg.MaxDrainingHostsPerPool {
+		return false, nil
+	}
+	return true, nil
+}
+
 // getState returns the running state of the driver
 func (d *driver) getState() driverState {
 	return driverState(atomic.LoadInt32(&d.running))
@@ -177,3 +177,106 @@ func (suite *driverTestSuite) TestDeleteHost() {
 
 	suite.goalStateDriver.DeleteHost("host")
 }
+
+// TestHasDrainBudget tests the cluster-wide and per-pool drain budgets
+func (suite *driverTestSuite) TestHasDrainBudget() {
+	hostInfos := []*pbhost.HostInfo{
+		{
+			Hostname:    "host1",
+			State:       pbhost.HostState_HOST_STATE_DRAINING,
+			CurrentPool: "pool1",
+		},
+		{
+			Hostname:    "host2",
+			State:       pbhost.HostState_HOST_STATE_DRAINED,
+			CurrentPool: "pool1",
+		},
+		{
+			Hostname:    "host3",
+			State:       pbhost.HostState_HOST_STATE_DRAINING,
+			CurrentPool: "pool2",
+		},
+		{
+			Hostname:    "host4",
+			State:       pbhost.HostState_HOST_STATE_DOWN,
+			CurrentPool: "pool2",
+		},
+		{
+			Hostname:    "host5",
+			State:       pbhost.HostState_HOST_STATE_UP,
+			CurrentPool: "pool2",
+		},
+	}
+
+	tt := []struct {
+		msg        string
+		maxHosts   int
+		maxPerPool int
+		pool       string
+		expected   bool
+	}{
+		{
+			msg:      "cluster budget available",
+			maxHosts: 4,
+			pool:     "pool1",
+			expected: true,
+		},
+		{
+			msg:      "cluster budget exhausted",
+			maxHosts: 3,
+			pool:     "pool2",
+			expected: false,
+		},
+		{
+			msg:        "pool budget exhausted",
+			maxPerPool: 2,
+			pool:       "pool1",
+			expected:   false,
+		},
+		{
+			msg:        "pool budget available",
+			maxPerPool: 2,
+			pool:       "pool2",
+			expected:   true,
+		},
+		{
+			msg:        "pool budget ignored without pool",
+			maxPerPool: 1,
+			expected:   true,
+		},
+	}
+
+	for _, test := range tt {
+		suite.goalStateDriver.cfg.MaxDrainingHosts = test.maxHosts
+		suite.goalStateDriver.cfg.MaxDrainingHostsPerPool = test.maxPerPool
+		suite.mockHostInfoOps.EXPECT().
+			GetAll(gomock.Any()).
+			Return(hostInfos, nil)
+
+		ok, err := suite.goalStateDriver.hasDrainBudget(
+			context.Background(), test.pool)
+		suite.NoError(err, test.msg)
+		suite.Equal(test.expected, ok, test.msg)
+	}
+}
+
+// TestHasDrainBudgetNoLimit tests that the DB is not read
+// when no drain budget is configured
+func (suite *driverTestSuite) TestHasDrainBudgetNoLimit() {
+	ok, err := suite.goalStateDriver.hasDrainBudget(
+		context.Background(), "pool1")
+	suite.NoError(err)
+	suite.True(ok)
+}
+
+// TestHasDrainBudgetDBError tests hasDrainBudget with DB failure
+func (suite *driverTestSuite) TestHasDrainBudgetDBError() {
+	suite.goalStateDriver.cfg.MaxDrainingHosts = 1
+	suite.mockHostInfoOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("some error"))
+
+	_, err := suite.goalStateDriver.hasDrainBudget(
+		context.Background(), "pool1")
+	suite.Error(err)
+}
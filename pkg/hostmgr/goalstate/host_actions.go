@@ -79,6 +79,30 @@ func HostDrain(ctx context.Context, entity goalstate.Entity) error {
 	gsDriver.maintenanceScheduleLock.Lock()
 	defer gsDriver.maintenanceScheduleLock.Unlock()
 
+	// A host which has not started draining yet has to fit in the drain
+	// budget, otherwise it is evaluated again later. The budget is checked
+	// under the maintenance schedule lock so that concurrent drains of
+	// hosts cannot exceed it.
+	if currentState.hostState == hpb.HostState_HOST_STATE_UP {
+		ok, err := gsDriver.hasDrainBudget(ctx, h.GetCurrentPool())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			gsDriver.scope.Counter("drain_budget_exhausted").Inc(1)
+			log.WithFields(log.Fields{
+				"hostname":    hostname,
+				"host_pool":   h.GetCurrentPool(),
+				"action_name": "HostDrain",
+			}).Info("drain budget exhausted, delaying host drain")
+			gsDriver.EnqueueHost(
+				hostname,
+				time.Now().Add(gsDriver.cfg.DrainBudgetRetryDelay),
+			)
+			return nil
+		}
+	}
+
 	if err := mesoshelper.AddHostToMaintenanceSchedule(
 		gsDriver.mesosMasterClient,
 		hostname,
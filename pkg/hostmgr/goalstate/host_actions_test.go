@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_maintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
@@ -151,6 +152,65 @@ func (suite *actionTestSuite) TestHostDrain() {
 	suite.NoError(HostDrain(suite.ctx, suite.hostEntity))
 }
 
+// TestHostDrainBudgetExhausted tests that HostDrain delays draining
+// a host when the drain budget is exhausted
+func (suite *actionTestSuite) TestHostDrainBudgetExhausted() {
+	suite.goalStateDriver.cfg.MaxDrainingHostsPerPool = 1
+	suite.goalStateDriver.cfg.DrainBudgetRetryDelay = time.Minute
+
+	hostInfo := &pbhost.HostInfo{
+		Hostname:    suite.hostname,
+		Ip:          suite.IP,
+		State:       pbhost.HostState_HOST_STATE_UP,
+		GoalState:   pbhost.HostState_HOST_STATE_DOWN,
+		CurrentPool: "pool1",
+	}
+
+	suite.mockHostInfoOps.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(hostInfo, nil).
+		Times(3)
+	suite.mockHostInfoOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.HostInfo{
+			hostInfo,
+			{
+				Hostname:    "other",
+				State:       pbhost.HostState_HOST_STATE_DRAINING,
+				CurrentPool: "pool1",
+			},
+		}, nil)
+	suite.mockHostEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(entity goalstate.Entity, deadline time.Time) {
+			suite.Equal(suite.hostname, entity.GetID())
+			suite.True(deadline.After(time.Now().Add(30 * time.Second)))
+		})
+
+	suite.NoError(HostDrain(suite.ctx, suite.hostEntity))
+}
+
+// TestHostDrainBudgetDBError tests HostDrain with failures to read
+// the drain budget from DB
+func (suite *actionTestSuite) TestHostDrainBudgetDBError() {
+	suite.goalStateDriver.cfg.MaxDrainingHosts = 1
+
+	suite.mockHostInfoOps.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(&pbhost.HostInfo{
+			Hostname:  suite.hostname,
+			Ip:        suite.IP,
+			State:     pbhost.HostState_HOST_STATE_UP,
+			GoalState: pbhost.HostState_HOST_STATE_DOWN,
+		}, nil).
+		Times(3)
+	suite.mockHostInfoOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("some error"))
+
+	suite.Error(HostDrain(suite.ctx, suite.hostEntity))
+}
+
 // TestHostDrainFailure tests HostDrain with failures to read from DB
 func (suite *actionTestSuite) TestHostDrainFailureDBRead() {
 	// Failure to read from DB
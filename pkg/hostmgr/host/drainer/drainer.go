@@ -16,6 +16,7 @@ package drainer
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
@@ -27,10 +28,14 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/queue"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
+const _defaultMaintenanceWindowPeriod = time.Minute
+
 // Drainer defines the interface for host drainer
 type Drainer interface {
 	// Start the module
@@ -53,37 +58,60 @@ type Drainer interface {
 
 	// CompleteMaintenance brings a host out of maintenance.
 	CompleteMaintenance(ctx context.Context, hostname string) error
+
+	// CreateMaintenanceWindow schedules maintenance of the hosts of
+	// the window for the duration of the window.
+	CreateMaintenanceWindow(
+		ctx context.Context,
+		window *pbhost.MaintenanceWindow,
+	) (*pbhost.MaintenanceWindow, error)
+
+	// GetMaintenanceWindows returns all maintenance windows
+	// sorted by start time.
+	GetMaintenanceWindows(ctx context.Context) ([]*pbhost.MaintenanceWindow, error)
+
+	// DeleteMaintenanceWindow deletes a maintenance window.
+	DeleteMaintenanceWindow(ctx context.Context, id string) error
 }
 
 // drainer defines the host drainer which drains
 // the hosts which are to be put into maintenance
 type drainer struct {
-	drainerPeriod        time.Duration
-	pelotonAgentRole     string
-	masterOperatorClient mpb.MasterOperatorClient
-	lifecycle            lifecycle.LifeCycle // lifecycle manager
-	goalStateDriver      goalstate.Driver
-	hostInfoOps          ormobjects.HostInfoOps // DB ops for host_info table
-	taskEvictionQueue    queue.TaskQueue
+	drainerPeriod           time.Duration
+	maintenanceWindowPeriod time.Duration
+	pelotonAgentRole        string
+	masterOperatorClient    mpb.MasterOperatorClient
+	lifecycle               lifecycle.LifeCycle // lifecycle manager
+	goalStateDriver         goalstate.Driver
+	hostInfoOps             ormobjects.HostInfoOps          // DB ops for host_info table
+	maintenanceWindowOps    ormobjects.MaintenanceWindowOps // DB ops for maintenance_windows table
+	taskEvictionQueue       queue.TaskQueue
 }
 
 // NewDrainer creates a new host drainer
 func NewDrainer(
 	drainerPeriod time.Duration,
+	maintenanceWindowPeriod time.Duration,
 	pelotonAgentRole string,
 	masterOperatorClient mpb.MasterOperatorClient,
 	goalStateDriver goalstate.Driver,
 	hostInfoOps ormobjects.HostInfoOps,
+	maintenanceWindowOps ormobjects.MaintenanceWindowOps,
 	taskEvictionQueue queue.TaskQueue,
 ) Drainer {
+	if maintenanceWindowPeriod == 0 {
+		maintenanceWindowPeriod = _defaultMaintenanceWindowPeriod
+	}
 	return &drainer{
-		drainerPeriod:        drainerPeriod,
-		pelotonAgentRole:     pelotonAgentRole,
-		masterOperatorClient: masterOperatorClient,
-		lifecycle:            lifecycle.NewLifeCycle(),
-		goalStateDriver:      goalStateDriver,
-		hostInfoOps:          hostInfoOps,
-		taskEvictionQueue:    taskEvictionQueue,
+		drainerPeriod:           drainerPeriod,
+		maintenanceWindowPeriod: maintenanceWindowPeriod,
+		pelotonAgentRole:        pelotonAgentRole,
+		masterOperatorClient:    masterOperatorClient,
+		lifecycle:               lifecycle.NewLifeCycle(),
+		goalStateDriver:         goalStateDriver,
+		hostInfoOps:             hostInfoOps,
+		maintenanceWindowOps:    maintenanceWindowOps,
+		taskEvictionQueue:       taskEvictionQueue,
 	}
 }
 
@@ -101,6 +129,9 @@ func (d *drainer) Start() {
 		ticker := time.NewTicker(d.drainerPeriod)
 		defer ticker.Stop()
 
+		windowTicker := time.NewTicker(d.maintenanceWindowPeriod)
+		defer windowTicker.Stop()
+
 		log.Info("Starting Host drainer")
 
 		// Start goal state driver
@@ -117,6 +148,12 @@ func (d *drainer) Start() {
 					log.WithError(err).
 						Warn("Maintenance state reconciliation unsuccessful")
 				}
+			case <-windowTicker.C:
+				err := d.reconcileMaintenanceWindows()
+				if err != nil {
+					log.WithError(err).
+						Warn("Maintenance window reconciliation unsuccessful")
+				}
 			}
 		}
 	}()
@@ -315,3 +352,254 @@ func (d *drainer) getAllHostInfosPerState(state pbhost.HostState) ([]*pbhost.Hos
 	}
 	return results, nil
 }
+
+// CreateMaintenanceWindow validates and persists a maintenance window.
+// The window is started and completed by the maintenance window
+// reconciliation, maintenance starts immediately if no start time is set.
+func (d *drainer) CreateMaintenanceWindow(
+	ctx context.Context,
+	window *pbhost.MaintenanceWindow,
+) (*pbhost.MaintenanceWindow, error) {
+	if len(window.GetHostnames()) == 0 && len(window.GetHostPool()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"hosts or host pool must be specified")
+	}
+	if window.GetDurationSeconds() == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"duration must be specified")
+	}
+
+	now := time.Now().UTC()
+	startTime := now
+	if len(window.GetStartTime()) != 0 {
+		var err error
+		if startTime, err = time.Parse(
+			time.RFC3339, window.GetStartTime()); err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid start time %s", window.GetStartTime())
+		}
+	}
+
+	newWindow := &pbhost.MaintenanceWindow{
+		Id:              uuid.New(),
+		Hostnames:       window.GetHostnames(),
+		HostPool:        window.GetHostPool(),
+		StartTime:       startTime.UTC().Format(time.RFC3339),
+		DurationSeconds: window.GetDurationSeconds(),
+		State:           pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		CreationTime:    now.Format(time.RFC3339),
+	}
+	if err := d.maintenanceWindowOps.Create(ctx, newWindow); err != nil {
+		return nil, err
+	}
+
+	log.WithField("window", newWindow).Info("maintenance window created")
+	return newWindow, nil
+}
+
+// GetMaintenanceWindows returns all maintenance windows
+// sorted by start time.
+func (d *drainer) GetMaintenanceWindows(
+	ctx context.Context,
+) ([]*pbhost.MaintenanceWindow, error) {
+	windows, err := d.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// RFC3339 times in UTC sort lexicographically
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].GetStartTime() < windows[j].GetStartTime()
+	})
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow deletes a maintenance window. Hosts which
+// are already in maintenance are not brought back up.
+func (d *drainer) DeleteMaintenanceWindow(
+	ctx context.Context,
+	id string,
+) error {
+	if _, err := d.maintenanceWindowOps.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := d.maintenanceWindowOps.Delete(ctx, id); err != nil {
+		return err
+	}
+	log.WithField("window_id", id).Info("maintenance window deleted")
+	return nil
+}
+
+// reconcileMaintenanceWindows starts maintenance on the hosts of
+// the windows which have started and completes maintenance on the hosts
+// of the windows which have ended. How many of the hosts drain at
+// the same time is limited by the drain budgets of the goal state engine.
+func (d *drainer) reconcileMaintenanceWindows() error {
+	ctx := context.Background()
+
+	windows, err := d.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs error
+	for _, window := range windows {
+		startTime, err := time.Parse(time.RFC3339, window.GetStartTime())
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		endTime := startTime.Add(
+			time.Duration(window.GetDurationSeconds()) * time.Second)
+
+		switch window.GetState() {
+		case pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED:
+			if now.Before(startTime) {
+				continue
+			}
+			err = d.startMaintenanceWindow(ctx, window, now.Before(endTime))
+		case pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE:
+			if now.Before(endTime) {
+				continue
+			}
+			err = d.completeMaintenanceWindow(ctx, window)
+		}
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// startMaintenanceWindow starts maintenance on the hosts of a window and
+// records the hosts it resolved from the host pool of the window. A window
+// which ended before it could be started is completed right away.
+func (d *drainer) startMaintenanceWindow(
+	ctx context.Context,
+	window *pbhost.MaintenanceWindow,
+	inProgress bool,
+) error {
+	if !inProgress {
+		log.WithField("window_id", window.GetId()).
+			Warn("maintenance window ended before it was started")
+		window.State = pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED
+		return d.maintenanceWindowOps.Update(ctx, window)
+	}
+
+	hostnames, err := d.getMaintenanceWindowHosts(ctx, window)
+	if err != nil {
+		return err
+	}
+
+	for _, hostname := range hostnames {
+		if err := d.StartMaintenance(ctx, hostname); err != nil {
+			// A host which cannot be put into maintenance, e.g. because
+			// it is not a Peloton agent, should not block the others
+			log.WithError(err).
+				WithFields(log.Fields{
+					"window_id": window.GetId(),
+					"hostname":  hostname,
+				}).Warn("failed to start maintenance of window host")
+		}
+	}
+
+	window.Hostnames = hostnames
+	window.State = pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE
+	if err := d.maintenanceWindowOps.Update(ctx, window); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"window_id": window.GetId(),
+		"hostnames": hostnames,
+	}).Info("maintenance window started")
+	return nil
+}
+
+// completeMaintenanceWindow brings the hosts of an ended window back up.
+// Hosts which have not started draining yet, e.g. because the drain
+// budget was exhausted, are not drained anymore. The window completes
+// once none of its hosts is draining.
+func (d *drainer) completeMaintenanceWindow(
+	ctx context.Context,
+	window *pbhost.MaintenanceWindow,
+) error {
+	var pending bool
+	for _, hostname := range window.GetHostnames() {
+		hostInfo, err := d.hostInfoOps.Get(ctx, hostname)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		switch hostInfo.GetState() {
+		case pbhost.HostState_HOST_STATE_DOWN:
+			if err := d.CompleteMaintenance(ctx, hostname); err != nil {
+				return err
+			}
+		case pbhost.HostState_HOST_STATE_UP:
+			if hostInfo.GetGoalState() != pbhost.HostState_HOST_STATE_DOWN {
+				continue
+			}
+			if err := d.hostInfoOps.UpdateGoalState(
+				ctx,
+				hostname,
+				pbhost.HostState_HOST_STATE_UP,
+			); err != nil {
+				return err
+			}
+			d.goalStateDriver.EnqueueHost(hostname, time.Now())
+		case pbhost.HostState_HOST_STATE_DRAINING,
+			pbhost.HostState_HOST_STATE_DRAINED:
+			pending = true
+		}
+	}
+
+	if pending {
+		log.WithField("window_id", window.GetId()).
+			Info("maintenance window ended, waiting for hosts to drain")
+		return nil
+	}
+
+	window.State = pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED
+	if err := d.maintenanceWindowOps.Update(ctx, window); err != nil {
+		return err
+	}
+
+	log.WithField("window_id", window.GetId()).
+		Info("maintenance window completed")
+	return nil
+}
+
+// getMaintenanceWindowHosts returns the hosts of a window, including
+// the hosts which currently belong to the host pool of the window.
+func (d *drainer) getMaintenanceWindowHosts(
+	ctx context.Context,
+	window *pbhost.MaintenanceWindow,
+) ([]string, error) {
+	hostnames := make(map[string]bool)
+	for _, hostname := range window.GetHostnames() {
+		hostnames[hostname] = true
+	}
+
+	if len(window.GetHostPool()) != 0 {
+		hostInfos, err := d.hostInfoOps.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hostInfos {
+			if h.GetCurrentPool() == window.GetHostPool() {
+				hostnames[h.GetHostname()] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(hostnames))
+	for hostname := range hostnames {
+		result = append(result, hostname)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -52,6 +52,7 @@ type drainerTestSuite struct {
 	mockCtrl                 *gomock.Controller
 	mockMasterOperatorClient *mpb_mocks.MockMasterOperatorClient
 	mockHostInfoOps          *orm_mocks.MockHostInfoOps
+	mockMaintenanceWindowOps *orm_mocks.MockMaintenanceWindowOps
 	mockGoalStateDriver      *goalstate_mocks.MockDriver
 	mockTaskEvictionQueue    *queuemocks.MockTaskQueue
 	upHost                   string
@@ -68,17 +69,20 @@ func (suite *drainerTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockMasterOperatorClient = mpb_mocks.NewMockMasterOperatorClient(suite.mockCtrl)
 	suite.mockHostInfoOps = orm_mocks.NewMockHostInfoOps(suite.mockCtrl)
+	suite.mockMaintenanceWindowOps = orm_mocks.NewMockMaintenanceWindowOps(suite.mockCtrl)
 	suite.mockGoalStateDriver = goalstate_mocks.NewMockDriver(suite.mockCtrl)
 	suite.mockTaskEvictionQueue = queuemocks.NewMockTaskQueue(suite.mockCtrl)
 
 	suite.drainer = &drainer{
-		drainerPeriod:        drainerPeriod,
-		taskEvictionQueue:    suite.mockTaskEvictionQueue,
-		pelotonAgentRole:     pelotonAgentRole,
-		masterOperatorClient: suite.mockMasterOperatorClient,
-		lifecycle:            lifecycle.NewLifeCycle(),
-		goalStateDriver:      suite.mockGoalStateDriver,
-		hostInfoOps:          suite.mockHostInfoOps,
+		drainerPeriod:           drainerPeriod,
+		maintenanceWindowPeriod: drainerPeriod,
+		taskEvictionQueue:       suite.mockTaskEvictionQueue,
+		pelotonAgentRole:        pelotonAgentRole,
+		masterOperatorClient:    suite.mockMasterOperatorClient,
+		lifecycle:               lifecycle.NewLifeCycle(),
+		goalStateDriver:         suite.mockGoalStateDriver,
+		hostInfoOps:             suite.mockHostInfoOps,
+		maintenanceWindowOps:    suite.mockMaintenanceWindowOps,
 	}
 }
 
@@ -140,10 +144,12 @@ func TestDrainer(t *testing.T) {
 func (suite *drainerTestSuite) TestDrainerNewDrainer() {
 	drainer := NewDrainer(
 		drainerPeriod,
+		0,
 		pelotonAgentRole,
 		suite.mockMasterOperatorClient,
 		suite.mockGoalStateDriver,
 		orm_mocks.NewMockHostInfoOps(suite.mockCtrl),
+		orm_mocks.NewMockMaintenanceWindowOps(suite.mockCtrl),
 		suite.mockTaskEvictionQueue,
 	)
 	suite.NotNil(drainer)
@@ -430,6 +436,8 @@ func (suite *drainerTestSuite) TestDrainerStartSuccess() {
 	suite.mockMasterOperatorClient.EXPECT().
 		GetMaintenanceStatus().
 		Return(&mesosmaster.Response_GetMaintenanceStatus{}, nil).AnyTimes()
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).Return(nil, nil).AnyTimes()
 
 	// Starting drainer again should be no-op
 	suite.drainer.Start()
@@ -450,3 +458,270 @@ func (suite *drainerTestSuite) TestStop() {
 	suite.drainer.Stop()
 	<-suite.drainer.lifecycle.StopCh()
 }
+
+// TestCreateMaintenanceWindow tests creating maintenance windows
+func (suite *drainerTestSuite) TestCreateMaintenanceWindow() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, window *pbhost.MaintenanceWindow) {
+			suite.NotEmpty(window.GetId())
+			suite.Equal("2019-01-01T10:00:00Z", window.GetStartTime())
+			suite.Equal(
+				pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+				window.GetState(),
+			)
+		}).
+		Return(nil)
+
+	window, err := suite.drainer.CreateMaintenanceWindow(
+		suite.ctx,
+		&pbhost.MaintenanceWindow{
+			HostPool:        "pool1",
+			StartTime:       "2019-01-01T11:00:00+01:00",
+			DurationSeconds: 3600,
+		})
+	suite.NoError(err)
+	suite.Equal("pool1", window.GetHostPool())
+	suite.Equal(uint32(3600), window.GetDurationSeconds())
+	suite.NotEmpty(window.GetCreationTime())
+}
+
+// TestCreateMaintenanceWindowStartNow tests creating a maintenance window
+// without start time
+func (suite *drainerTestSuite) TestCreateMaintenanceWindowStartNow() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	window, err := suite.drainer.CreateMaintenanceWindow(
+		suite.ctx,
+		&pbhost.MaintenanceWindow{
+			Hostnames:       []string{suite.upHost},
+			DurationSeconds: 60,
+		})
+	suite.NoError(err)
+	startTime, err := time.Parse(time.RFC3339, window.GetStartTime())
+	suite.NoError(err)
+	suite.WithinDuration(time.Now(), startTime, time.Minute)
+}
+
+// TestCreateMaintenanceWindowInvalid tests creating invalid
+// maintenance windows
+func (suite *drainerTestSuite) TestCreateMaintenanceWindowInvalid() {
+	windows := []*pbhost.MaintenanceWindow{
+		{
+			DurationSeconds: 60,
+		},
+		{
+			Hostnames: []string{suite.upHost},
+		},
+		{
+			Hostnames:       []string{suite.upHost},
+			StartTime:       "tomorrow",
+			DurationSeconds: 60,
+		},
+	}
+	for _, window := range windows {
+		_, err := suite.drainer.CreateMaintenanceWindow(suite.ctx, window)
+		suite.True(yarpcerrors.IsInvalidArgument(err))
+	}
+}
+
+// TestCreateMaintenanceWindowDBError tests creating a maintenance window
+// with DB error
+func (suite *drainerTestSuite) TestCreateMaintenanceWindowDBError() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("some error"))
+
+	_, err := suite.drainer.CreateMaintenanceWindow(
+		suite.ctx,
+		&pbhost.MaintenanceWindow{
+			Hostnames:       []string{suite.upHost},
+			DurationSeconds: 60,
+		})
+	suite.Error(err)
+}
+
+// TestGetMaintenanceWindows tests that maintenance windows
+// are sorted by start time
+func (suite *drainerTestSuite) TestGetMaintenanceWindows() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.MaintenanceWindow{
+			{Id: "w2", StartTime: "2019-01-02T00:00:00Z"},
+			{Id: "w1", StartTime: "2019-01-01T00:00:00Z"},
+		}, nil)
+
+	windows, err := suite.drainer.GetMaintenanceWindows(suite.ctx)
+	suite.NoError(err)
+	suite.Len(windows, 2)
+	suite.Equal("w1", windows[0].GetId())
+	suite.Equal("w2", windows[1].GetId())
+}
+
+// TestDeleteMaintenanceWindow tests deleting maintenance windows
+func (suite *drainerTestSuite) TestDeleteMaintenanceWindow() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Get(gomock.Any(), "w1").
+		Return(&pbhost.MaintenanceWindow{Id: "w1"}, nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Delete(gomock.Any(), "w1").
+		Return(nil)
+	suite.NoError(suite.drainer.DeleteMaintenanceWindow(suite.ctx, "w1"))
+
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Get(gomock.Any(), "w2").
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+	suite.True(yarpcerrors.IsNotFound(
+		suite.drainer.DeleteMaintenanceWindow(suite.ctx, "w2")))
+}
+
+// TestReconcileMaintenanceWindowsStart tests starting maintenance
+// on the hosts of a window which has started
+func (suite *drainerTestSuite) TestReconcileMaintenanceWindowsStart() {
+	loader := &host.Loader{
+		OperatorClient: suite.mockMasterOperatorClient,
+		Scope:          tally.NoopScope,
+		HostInfoOps:    suite.mockHostInfoOps,
+	}
+	suite.setupLoaderMocks(suite.makeUpAgentResponse())
+	loader.Load(nil)
+
+	now := time.Now().UTC()
+	started := &pbhost.MaintenanceWindow{
+		Id:              "w1",
+		HostPool:        "pool1",
+		Hostnames:       []string{"unknown"},
+		StartTime:       now.Add(-time.Minute).Format(time.RFC3339),
+		DurationSeconds: 3600,
+		State:           pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+	future := &pbhost.MaintenanceWindow{
+		Id:              "w2",
+		Hostnames:       []string{suite.upHost},
+		StartTime:       now.Add(time.Hour).Format(time.RFC3339),
+		DurationSeconds: 3600,
+		State:           pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+	missed := &pbhost.MaintenanceWindow{
+		Id:              "w3",
+		Hostnames:       []string{suite.upHost},
+		StartTime:       now.Add(-2 * time.Hour).Format(time.RFC3339),
+		DurationSeconds: 3600,
+		State:           pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.MaintenanceWindow{started, future, missed}, nil)
+	suite.mockHostInfoOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.HostInfo{
+			{Hostname: suite.upHost, CurrentPool: "pool1"},
+			{Hostname: "host2", CurrentPool: "pool2"},
+		}, nil)
+	suite.mockHostInfoOps.EXPECT().
+		UpdateGoalState(gomock.Any(), suite.upHost, pbhost.HostState_HOST_STATE_DOWN).
+		Return(nil)
+	suite.mockGoalStateDriver.EXPECT().EnqueueHost(suite.upHost, gomock.Any())
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, window *pbhost.MaintenanceWindow) {
+			switch window.GetId() {
+			case "w1":
+				suite.Equal(
+					pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE,
+					window.GetState())
+				suite.Equal([]string{suite.upHost, "unknown"}, window.GetHostnames())
+			case "w3":
+				suite.Equal(
+					pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+					window.GetState())
+			default:
+				suite.Fail("unexpected window update")
+			}
+		}).
+		Return(nil).
+		Times(2)
+
+	suite.NoError(suite.drainer.reconcileMaintenanceWindows())
+}
+
+// TestReconcileMaintenanceWindowsComplete tests bringing back up
+// the hosts of a window which has ended
+func (suite *drainerTestSuite) TestReconcileMaintenanceWindowsComplete() {
+	window := &pbhost.MaintenanceWindow{
+		Id:              "w1",
+		Hostnames:       []string{"down", "up", "draining"},
+		StartTime:       time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339),
+		DurationSeconds: 3600,
+		State:           pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE,
+	}
+	downHost := &pbhost.HostInfo{
+		Hostname:  "down",
+		State:     pbhost.HostState_HOST_STATE_DOWN,
+		GoalState: pbhost.HostState_HOST_STATE_DOWN,
+	}
+	upHost := &pbhost.HostInfo{
+		Hostname:  "up",
+		State:     pbhost.HostState_HOST_STATE_UP,
+		GoalState: pbhost.HostState_HOST_STATE_DOWN,
+	}
+	drainingHost := &pbhost.HostInfo{
+		Hostname:  "draining",
+		State:     pbhost.HostState_HOST_STATE_DRAINING,
+		GoalState: pbhost.HostState_HOST_STATE_DOWN,
+	}
+
+	// The draining host keeps the window active
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.MaintenanceWindow{window}, nil)
+	suite.mockHostInfoOps.EXPECT().Get(gomock.Any(), "down").
+		Return(downHost, nil).Times(2)
+	suite.mockHostInfoOps.EXPECT().
+		UpdateGoalState(gomock.Any(), "down", pbhost.HostState_HOST_STATE_UP).
+		Return(nil)
+	suite.mockGoalStateDriver.EXPECT().EnqueueHost("down", gomock.Any())
+	suite.mockHostInfoOps.EXPECT().Get(gomock.Any(), "up").
+		Return(upHost, nil)
+	suite.mockHostInfoOps.EXPECT().
+		UpdateGoalState(gomock.Any(), "up", pbhost.HostState_HOST_STATE_UP).
+		Return(nil)
+	suite.mockGoalStateDriver.EXPECT().EnqueueHost("up", gomock.Any())
+	suite.mockHostInfoOps.EXPECT().Get(gomock.Any(), "draining").
+		Return(drainingHost, nil)
+
+	suite.NoError(suite.drainer.reconcileMaintenanceWindows())
+
+	// Once the host is down and brought back up, the window completes
+	window.Hostnames = []string{"draining"}
+	drainingHost.State = pbhost.HostState_HOST_STATE_UP
+	drainingHost.GoalState = pbhost.HostState_HOST_STATE_UP
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbhost.MaintenanceWindow{window}, nil)
+	suite.mockHostInfoOps.EXPECT().Get(gomock.Any(), "draining").
+		Return(drainingHost, nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, window *pbhost.MaintenanceWindow) {
+			suite.Equal(
+				pbhost.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+				window.GetState())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.drainer.reconcileMaintenanceWindows())
+}
+
+// TestReconcileMaintenanceWindowsDBError tests maintenance window
+// reconciliation with DB error
+func (suite *drainerTestSuite) TestReconcileMaintenanceWindowsDBError() {
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("some error"))
+
+	suite.Error(suite.drainer.reconcileMaintenanceWindows())
+}
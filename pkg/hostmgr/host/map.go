@@ -17,6 +17,7 @@ package host
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,8 +25,10 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
 	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
@@ -275,12 +278,40 @@ func BuildHostInfoForRegisteredAgents() (map[string]*pbhost.HostInfo, error) {
 			Hostname: hostname,
 			Ip:       agentIP,
 			State:    pbhost.HostState_HOST_STATE_UP,
+			Labels: buildHostLabels(
+				hostname,
+				agent.GetAgentInfo().GetAttributes()),
 		}
 		upHosts[hostname] = hostInfo
 	}
 	return upHosts, nil
 }
 
+// buildHostLabels converts the attributes of an agent into host labels,
+// with one label per attribute value, sorted by key and value.
+func buildHostLabels(
+	hostname string,
+	attributes []*mesos.Attribute) []*peloton.Label {
+	var labels []*peloton.Label
+	for key, values := range constraints.GetHostLabelValues(
+		hostname, attributes) {
+		// hostname is already part of the host info
+		if key == common.HostNameKey {
+			continue
+		}
+		for value := range values {
+			labels = append(labels, &peloton.Label{Key: key, Value: value})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].GetKey() != labels[j].GetKey() {
+			return labels[i].GetKey() < labels[j].GetKey()
+		}
+		return labels[i].GetValue() < labels[j].GetValue()
+	})
+	return labels
+}
+
 // GetUpHostIP gets the IP address of a host in UP state
 func GetUpHostIP(hostname string) (string, error) {
 	agentMap := GetAgentMap()
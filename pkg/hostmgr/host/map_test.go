@@ -23,6 +23,7 @@ import (
 	mesosmaintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
 	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
@@ -302,6 +303,31 @@ func (suite *hostMapTestSuite) TestBuildHostInfoForRegisteredAgents() {
 	suite.Equal(hostInfoMap, hostInfosBuilt)
 }
 
+// TestBuildHostLabels tests converting agent attributes into host labels
+func (suite *hostMapTestSuite) TestBuildHostLabels() {
+	textType := mesos.Value_TEXT
+	setType := mesos.Value_SET
+	attributes := []*mesos.Attribute{
+		{
+			Name: util.PtrPrintf("zone"),
+			Type: &textType,
+			Text: &mesos.Value_Text{Value: util.PtrPrintf("dca1")},
+		},
+		{
+			Name: util.PtrPrintf("rack"),
+			Type: &setType,
+			Set:  &mesos.Value_Set{Item: []string{"r2", "r1"}},
+		},
+	}
+
+	suite.Equal([]*peloton.Label{
+		{Key: "rack", Value: "r1"},
+		{Key: "rack", Value: "r2"},
+		{Key: "zone", Value: "dca1"},
+	}, buildHostLabels("host-0", attributes))
+	suite.Nil(buildHostLabels("host-0", nil))
+}
+
 func (suite *hostMapTestSuite) TestGetUpHostIP() {
 	loader := &Loader{
 		OperatorClient: suite.operatorClient,
@@ -365,3 +365,74 @@ func (m *serviceHandler) MoveHosts(
 	}
 	return
 }
+
+// CreateMaintenanceWindow schedules maintenance of a set of hosts, given
+// either by hostname or by host pool, for the duration of the window.
+// The hosts go through the same transitions as with StartMaintenance and
+// are brought back up once the window ends.
+func (m *serviceHandler) CreateMaintenanceWindow(
+	ctx context.Context,
+	request *host_svc.CreateMaintenanceWindowRequest,
+) (*host_svc.CreateMaintenanceWindowResponse, error) {
+	m.metrics.CreateMaintenanceWindowAPI.Inc(1)
+
+	window, err := m.drainer.CreateMaintenanceWindow(
+		ctx,
+		&hpb.MaintenanceWindow{
+			Hostnames:       request.GetHostnames(),
+			HostPool:        request.GetHostPool(),
+			StartTime:       request.GetStartTime(),
+			DurationSeconds: request.GetDurationSeconds(),
+		})
+	if err != nil {
+		m.metrics.CreateMaintenanceWindowFail.Inc(1)
+		if yarpcerrors.IsStatus(err) {
+			return nil, err
+		}
+		return nil, yarpcerrors.InternalErrorf(err.Error())
+	}
+
+	m.metrics.CreateMaintenanceWindowSuccess.Inc(1)
+	return &host_svc.CreateMaintenanceWindowResponse{
+		Window: window,
+	}, nil
+}
+
+// ListMaintenanceWindows returns all maintenance windows.
+func (m *serviceHandler) ListMaintenanceWindows(
+	ctx context.Context,
+	request *host_svc.ListMaintenanceWindowsRequest,
+) (*host_svc.ListMaintenanceWindowsResponse, error) {
+	m.metrics.ListMaintenanceWindowsAPI.Inc(1)
+
+	windows, err := m.drainer.GetMaintenanceWindows(ctx)
+	if err != nil {
+		m.metrics.ListMaintenanceWindowsFail.Inc(1)
+		return nil, yarpcerrors.InternalErrorf(err.Error())
+	}
+
+	m.metrics.ListMaintenanceWindowsSuccess.Inc(1)
+	return &host_svc.ListMaintenanceWindowsResponse{
+		Windows: windows,
+	}, nil
+}
+
+// DeleteMaintenanceWindow deletes a maintenance window.
+func (m *serviceHandler) DeleteMaintenanceWindow(
+	ctx context.Context,
+	request *host_svc.DeleteMaintenanceWindowRequest,
+) (*host_svc.DeleteMaintenanceWindowResponse, error) {
+	m.metrics.DeleteMaintenanceWindowAPI.Inc(1)
+
+	if err := m.drainer.DeleteMaintenanceWindow(
+		ctx, request.GetId()); err != nil {
+		m.metrics.DeleteMaintenanceWindowFail.Inc(1)
+		if yarpcerrors.IsStatus(err) {
+			return nil, err
+		}
+		return nil, yarpcerrors.InternalErrorf(err.Error())
+	}
+
+	m.metrics.DeleteMaintenanceWindowSuccess.Inc(1)
+	return &host_svc.DeleteMaintenanceWindowResponse{}, nil
+}
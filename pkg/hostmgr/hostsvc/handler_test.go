@@ -39,6 +39,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type hostSvcHandlerTestSuite struct {
@@ -234,6 +235,92 @@ func (suite *hostSvcHandlerTestSuite) TestCompleteMaintenance() {
 	suite.Nil(resp)
 }
 
+func (suite *hostSvcHandlerTestSuite) TestCreateMaintenanceWindow() {
+	window := &hpb.MaintenanceWindow{
+		Id:              "w1",
+		HostPool:        "pool1",
+		StartTime:       "2019-01-01T00:00:00Z",
+		DurationSeconds: 3600,
+		State:           hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+	suite.mockDrainer.EXPECT().
+		CreateMaintenanceWindow(gomock.Any(), &hpb.MaintenanceWindow{
+			HostPool:        "pool1",
+			StartTime:       "2019-01-01T00:00:00Z",
+			DurationSeconds: 3600,
+		}).
+		Return(window, nil)
+	resp, err := suite.handler.CreateMaintenanceWindow(
+		suite.ctx,
+		&svcpb.CreateMaintenanceWindowRequest{
+			HostPool:        "pool1",
+			StartTime:       "2019-01-01T00:00:00Z",
+			DurationSeconds: 3600,
+		})
+	suite.NoError(err)
+	suite.Equal(window, resp.GetWindow())
+
+	// invalid window
+	suite.mockDrainer.EXPECT().
+		CreateMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("invalid"))
+	_, err = suite.handler.CreateMaintenanceWindow(
+		suite.ctx,
+		&svcpb.CreateMaintenanceWindowRequest{})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// DB error
+	suite.mockDrainer.EXPECT().
+		CreateMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("some error"))
+	_, err = suite.handler.CreateMaintenanceWindow(
+		suite.ctx,
+		&svcpb.CreateMaintenanceWindowRequest{
+			Hostnames:       []string{"host1"},
+			DurationSeconds: 60,
+		})
+	suite.True(yarpcerrors.IsInternal(err))
+}
+
+func (suite *hostSvcHandlerTestSuite) TestListMaintenanceWindows() {
+	windows := []*hpb.MaintenanceWindow{{Id: "w1"}, {Id: "w2"}}
+	suite.mockDrainer.EXPECT().
+		GetMaintenanceWindows(gomock.Any()).
+		Return(windows, nil)
+	resp, err := suite.handler.ListMaintenanceWindows(
+		suite.ctx,
+		&svcpb.ListMaintenanceWindowsRequest{})
+	suite.NoError(err)
+	suite.Equal(windows, resp.GetWindows())
+
+	suite.mockDrainer.EXPECT().
+		GetMaintenanceWindows(gomock.Any()).
+		Return(nil, errors.New("some error"))
+	_, err = suite.handler.ListMaintenanceWindows(
+		suite.ctx,
+		&svcpb.ListMaintenanceWindowsRequest{})
+	suite.Error(err)
+}
+
+func (suite *hostSvcHandlerTestSuite) TestDeleteMaintenanceWindow() {
+	suite.mockDrainer.EXPECT().
+		DeleteMaintenanceWindow(gomock.Any(), "w1").
+		Return(nil)
+	resp, err := suite.handler.DeleteMaintenanceWindow(
+		suite.ctx,
+		&svcpb.DeleteMaintenanceWindowRequest{Id: "w1"})
+	suite.NoError(err)
+	suite.NotNil(resp)
+
+	suite.mockDrainer.EXPECT().
+		DeleteMaintenanceWindow(gomock.Any(), "w2").
+		Return(yarpcerrors.NotFoundErrorf("not found"))
+	_, err = suite.handler.DeleteMaintenanceWindow(
+		suite.ctx,
+		&svcpb.DeleteMaintenanceWindowRequest{Id: "w2"})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
 func (suite *hostSvcHandlerTestSuite) TestQueryHosts() {
 	suite.doTestQueryHosts()
 }
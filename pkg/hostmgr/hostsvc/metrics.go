@@ -29,6 +29,18 @@ type Metrics struct {
 	QueryHostsAPI     tally.Counter
 	QueryHostsSuccess tally.Counter
 	QueryHostsFail    tally.Counter
+
+	CreateMaintenanceWindowAPI     tally.Counter
+	CreateMaintenanceWindowSuccess tally.Counter
+	CreateMaintenanceWindowFail    tally.Counter
+
+	ListMaintenanceWindowsAPI     tally.Counter
+	ListMaintenanceWindowsSuccess tally.Counter
+	ListMaintenanceWindowsFail    tally.Counter
+
+	DeleteMaintenanceWindowAPI     tally.Counter
+	DeleteMaintenanceWindowSuccess tally.Counter
+	DeleteMaintenanceWindowFail    tally.Counter
 }
 
 // NewMetrics returns a new instance of host.svc.Metrics
@@ -48,5 +60,17 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		QueryHostsAPI:     apiScope.Counter("query_hosts"),
 		QueryHostsSuccess: successScope.Counter("query_hosts"),
 		QueryHostsFail:    failScope.Counter("query_hosts"),
+
+		CreateMaintenanceWindowAPI:     apiScope.Counter("create_maintenance_window"),
+		CreateMaintenanceWindowSuccess: successScope.Counter("create_maintenance_window"),
+		CreateMaintenanceWindowFail:    failScope.Counter("create_maintenance_window"),
+
+		ListMaintenanceWindowsAPI:     apiScope.Counter("list_maintenance_windows"),
+		ListMaintenanceWindowsSuccess: successScope.Counter("list_maintenance_windows"),
+		ListMaintenanceWindowsFail:    failScope.Counter("list_maintenance_windows"),
+
+		DeleteMaintenanceWindowAPI:     apiScope.Counter("delete_maintenance_window"),
+		DeleteMaintenanceWindowSuccess: successScope.Counter("delete_maintenance_window"),
+		DeleteMaintenanceWindowFail:    failScope.Counter("delete_maintenance_window"),
 	}
 }
@@ -26,6 +26,8 @@ type LaunchablePod struct {
 	PodId *peloton.PodID
 	Spec  *pbpod.PodSpec
 	Ports map[string]uint32
+	// ResourcePool is the path of the resource pool of the pod
+	ResourcePool string
 }
 
 // HostResources is a non-thread safe helper struct holding the Slack and NonSlack resources for a host.
@@ -182,9 +182,10 @@ func (h *ServiceHandler) LaunchPods(
 
 	for _, pod := range req.GetPods() {
 		launchablePods = append(launchablePods, &models.LaunchablePod{
-			PodId: pod.GetPodId(),
-			Spec:  pod.GetSpec(),
-			Ports: pod.GetPorts(),
+			PodId:        pod.GetPodId(),
+			Spec:         pod.GetSpec(),
+			Ports:        pod.GetPorts(),
+			ResourcePool: pod.GetResourcePool(),
 		})
 	}
 
@@ -17,6 +17,7 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/lifecycle"
@@ -26,6 +27,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc/yarpcerrors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
@@ -54,6 +56,15 @@ type K8SManager struct {
 
 	// Lifecycle manager.
 	lifecycle lifecycle.LifeCycle
+
+	// Lock protecting the namespace maps below.
+	sync.RWMutex
+
+	// Namespaces which have been created by the manager.
+	namespaces map[string]bool
+
+	// Namespace of every pod, keyed by pod name.
+	podNamespaces map[string]string
 }
 
 // NewK8sManager returns a new instance of K8SManager
@@ -104,6 +115,8 @@ func newK8sManagerWithClient(
 		podEventCh:      podEventCh,
 		hostEventCh:     hostEventCh,
 		lifecycle:       lifecycle.NewLifeCycle(),
+		namespaces:      make(map[string]bool),
+		podNamespaces:   make(map[string]string),
 	}
 }
 
@@ -260,6 +273,7 @@ func (k *K8SManager) addPod(obj interface{}) {
 		return
 	}
 
+	k.setPodNamespace(pod.Name, pod.Namespace)
 	evt := scalar.BuildPodEventFromPod(pod, scalar.AddPod)
 	log.WithFields(log.Fields{
 		"pod": pod,
@@ -278,6 +292,7 @@ func (k *K8SManager) updatePod(oldObj interface{}, newObj interface{}) {
 		return
 	}
 
+	k.setPodNamespace(pod.Name, pod.Namespace)
 	evt := scalar.BuildPodEventFromPod(pod, scalar.UpdatePod)
 	log.WithFields(log.Fields{
 		"pod": pod,
@@ -296,6 +311,7 @@ func (k *K8SManager) deletePod(obj interface{}) {
 		return
 	}
 
+	k.removePodNamespace(pod.Name)
 	evt := scalar.BuildPodEventFromPod(pod, scalar.DeletePod)
 	log.WithFields(log.Fields{
 		"pod": pod,
@@ -328,8 +344,14 @@ func (k *K8SManager) LaunchPods(
 		// system generated and is read only, so we cannot set it here.
 		pod.Name = lp.PodId.GetValue()
 
+		// Pods of a resource pool are created in the namespace of the pool.
+		pod.Namespace = toK8SNamespace(lp.ResourcePool)
+		if err = k.ensureNamespace(pod.Namespace); err != nil {
+			return launched, err
+		}
+
 		// Create the pod
-		_, err = k.kubeClient.CoreV1().Pods("default").Create(pod)
+		_, err = k.kubeClient.CoreV1().Pods(pod.Namespace).Create(pod)
 		if err != nil {
 			// For now can we just fail this call and keep the earlier pods
 			// launched. They will generate events which will go to JM, JM can
@@ -341,6 +363,7 @@ func (k *K8SManager) LaunchPods(
 			// allocation reduced on hosts upfront
 			return launched, err
 		}
+		k.setPodNamespace(pod.Name, pod.Namespace)
 		launched = append(launched, lp)
 	}
 	return launched, nil
@@ -353,6 +376,57 @@ func (k *K8SManager) KillPod(ctx context.Context, podID string) error {
 	// and just delete it from the API server. Special considerations need to be
 	// made for getting the logs of terminal pods, out of scope for Peloton.
 	return k.kubeClient.CoreV1().
-		Pods("default").
+		Pods(k.getPodNamespace(podID)).
 		Delete(podID, &metav1.DeleteOptions{})
 }
+
+// ensureNamespace creates the namespace if it does not exist.
+func (k *K8SManager) ensureNamespace(namespace string) error {
+	if namespace == _defaultNamespace {
+		return nil
+	}
+
+	k.RLock()
+	created := k.namespaces[namespace]
+	k.RUnlock()
+	if created {
+		return nil
+	}
+
+	_, err := k.kubeClient.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	k.Lock()
+	k.namespaces[namespace] = true
+	k.Unlock()
+	return nil
+}
+
+// setPodNamespace records the namespace of the pod.
+func (k *K8SManager) setPodNamespace(podName, namespace string) {
+	k.Lock()
+	defer k.Unlock()
+	k.podNamespaces[podName] = namespace
+}
+
+// removePodNamespace removes the namespace of a deleted pod.
+func (k *K8SManager) removePodNamespace(podName string) {
+	k.Lock()
+	defer k.Unlock()
+	delete(k.podNamespaces, podName)
+}
+
+// getPodNamespace returns the namespace of the pod, or the default
+// namespace if the pod is not known.
+func (k *K8SManager) getPodNamespace(podName string) string {
+	k.RLock()
+	defer k.RUnlock()
+	if namespace, ok := k.podNamespaces[podName]; ok {
+		return namespace
+	}
+	return _defaultNamespace
+}
@@ -96,6 +96,59 @@ func (suite *K8SManagerTestSuite) TestLaunchAndKillPod() {
 	suite.True(ok)
 }
 
+// TestLaunchAndKillPodInResourcePoolNamespace tests that pods are launched
+// in the namespace of their resource pool.
+func (suite *K8SManagerTestSuite) TestLaunchAndKillPodInResourcePoolNamespace() {
+	testPodName := "test_pod"
+	testHostName := "test_host"
+	testNamespace := "infra-compute"
+
+	suite.testManager.Start()
+
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+	launched, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{
+				PodId:        &peloton.PodID{Value: testPodName},
+				Spec:         testPodSpec,
+				ResourcePool: "/infra/compute",
+			},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.Equal(1, len(launched))
+
+	// the namespace is created for the resource pool
+	namespace, err := suite.
+		testKubeClient.
+		CoreV1().
+		Namespaces().
+		Get(testNamespace, metav1.GetOptions{})
+	suite.NoError(err)
+	suite.Equal(testNamespace, namespace.Name)
+
+	returnedPod, err := suite.
+		testKubeClient.
+		CoreV1().
+		Pods(testNamespace).
+		Get(testPodName, metav1.GetOptions{})
+	suite.NoError(err)
+	suite.Equal(testNamespace, returnedPod.Namespace)
+
+	// Kill pod and verify it is deleted from the namespace.
+	err = suite.testManager.KillPod(context.Background(), testPodName)
+	suite.NoError(err)
+
+	_, err = suite.
+		testKubeClient.
+		CoreV1().
+		Pods(testNamespace).
+		Get(testPodName, metav1.GetOptions{})
+	suite.True(apierrors.IsNotFound(err))
+}
+
 func (suite *K8SManagerTestSuite) TestPodEventHandlers() {
 	testPodName := "test_pod"
 	testHostName := "test_host"
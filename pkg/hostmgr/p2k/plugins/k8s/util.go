@@ -15,14 +15,20 @@
 package k8s
 
 import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"time"
 
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	pbvolume "github.com/uber/peloton/.gen/peloton/api/v1alpha/volume"
 
 	"github.com/pborman/uuid"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -31,12 +37,46 @@ const (
 	// K8S enforces minimum mem limit for container to be 4MB. KinD enforces
 	// this limit as 100MB.
 	_defaultMinMemMb = 100.0
+
+	// Namespace for pods which do not belong to a resource pool.
+	_defaultNamespace = "default"
+	// K8S namespace names are DNS labels of at most 63 characters.
+	_maxNamespaceLength = 63
+
+	// Extended resource name of GPUs exposed by the NVIDIA device plugin.
+	_gpuResourceName = corev1.ResourceName("nvidia.com/gpu")
+
+	// Default values of the health check config, same as the ones used for
+	// Mesos tasks.
+	_defaultHealthCheckInitialIntervalSecs    = 15
+	_defaultHealthCheckIntervalSecs           = 10
+	_defaultHealthCheckMaxConsecutiveFailures = 3
+	_defaultHealthCheckTimeoutSecs            = 20
 )
 
 // K8S node and pod informers will resync all nodes and pods at this
 // interval. This will be used for reconciliation of pods and hostcache.
 var _defaultResyncInterval = 30 * time.Second
 
+// _invalidNamespaceChars matches the characters which are not allowed in
+// k8s namespace names.
+var _invalidNamespaceChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// toK8SNamespace converts the path of a resource pool to the k8s namespace
+// of its pods, e.g. /infra/compute is mapped to infra-compute.
+func toK8SNamespace(respoolPath string) string {
+	namespace := strings.ToLower(strings.Trim(respoolPath, "/"))
+	namespace = _invalidNamespaceChars.ReplaceAllString(namespace, "-")
+	if len(namespace) > _maxNamespaceLength {
+		namespace = namespace[:_maxNamespaceLength]
+	}
+	namespace = strings.Trim(namespace, "-")
+	if namespace == "" {
+		return _defaultNamespace
+	}
+	return namespace
+}
+
 // Convert peloton container specs to k8s container specs
 func toK8SContainerSpecs(
 	containerSpecs []*pbpod.ContainerSpec,
@@ -50,8 +90,7 @@ func toK8SContainerSpecs(
 
 // Convert peloton container spec to k8s container spec
 func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
-	// TODO:
-	// add ports, health check, readiness check, affinity
+	// TODO: add affinity
 	var kEnvs []corev1.EnvVar
 	for _, e := range c.GetEnvironment() {
 		kEnvs = append(kEnvs, corev1.EnvVar{
@@ -66,6 +105,13 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 			Name:          p.GetName(),
 			ContainerPort: int32(p.GetValue()),
 		})
+		// export the port to the container like the Mesos runtime does
+		if p.GetEnvName() != "" {
+			kEnvs = append(kEnvs, corev1.EnvVar{
+				Name:  p.GetEnvName(),
+				Value: fmt.Sprint(p.GetValue()),
+			})
+		}
 	}
 
 	cname := c.GetName()
@@ -78,11 +124,6 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 		cimage = _defaultImageName
 	}
 
-	memMb := c.GetResource().GetMemLimitMb()
-	if memMb < _defaultMinMemMb {
-		memMb = _defaultMinMemMb
-	}
-
 	volumeMounts := []corev1.VolumeMount{}
 	for _, v := range c.GetVolumeMounts() {
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{
@@ -93,33 +134,14 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 	}
 
 	k8sSpec := corev1.Container{
-		Name:         cname,
-		Image:        cimage,
-		Env:          kEnvs,
-		Ports:        ports,
-		VolumeMounts: volumeMounts,
-		Resources: corev1.ResourceRequirements{
-			Limits: corev1.ResourceList{
-				corev1.ResourceCPU: *resource.NewMilliQuantity(
-					int64(c.GetResource().GetCpuLimit()*1000),
-					resource.DecimalSI,
-				),
-				corev1.ResourceMemory: *resource.NewMilliQuantity(
-					int64(memMb*1000000000),
-					resource.DecimalSI,
-				),
-			},
-			Requests: corev1.ResourceList{
-				corev1.ResourceCPU: *resource.NewMilliQuantity(
-					int64(c.GetResource().GetCpuLimit()*1000),
-					resource.DecimalSI,
-				),
-				corev1.ResourceMemory: *resource.NewMilliQuantity(
-					int64(memMb*1000000000),
-					resource.DecimalSI,
-				),
-			},
-		},
+		Name:           cname,
+		Image:          cimage,
+		Env:            kEnvs,
+		Ports:          ports,
+		VolumeMounts:   volumeMounts,
+		Resources:      toK8SResources(c.GetResource()),
+		LivenessProbe:  toK8SProbe(c.GetLivenessCheck(), true),
+		ReadinessProbe: toK8SProbe(c.GetReadinessCheck(), false),
 	}
 
 	if c.GetEntrypoint().GetValue() != "" {
@@ -130,6 +152,182 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 	return k8sSpec
 }
 
+// toK8SResources converts the peloton resource spec of a container to k8s
+// resource requirements. Peloton only has limits, so the requests are set
+// to the limits which gives the pods the guaranteed QoS class.
+func toK8SResources(r *pbpod.ResourceSpec) corev1.ResourceRequirements {
+	memMb := r.GetMemLimitMb()
+	if memMb < _defaultMinMemMb {
+		memMb = _defaultMinMemMb
+	}
+
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU: *resource.NewMilliQuantity(
+			int64(r.GetCpuLimit()*1000),
+			resource.DecimalSI,
+		),
+		corev1.ResourceMemory: *resource.NewMilliQuantity(
+			int64(memMb*1000000000),
+			resource.DecimalSI,
+		),
+	}
+
+	if r.GetDiskLimitMb() > 0 {
+		resources[corev1.ResourceEphemeralStorage] = *resource.NewMilliQuantity(
+			int64(r.GetDiskLimitMb()*1000000000),
+			resource.DecimalSI,
+		)
+	}
+
+	if r.GetGpuLimit() > 0 {
+		// GPUs can not be shared between containers
+		resources[_gpuResourceName] = *resource.NewQuantity(
+			int64(math.Ceil(r.GetGpuLimit())),
+			resource.DecimalSI,
+		)
+	}
+
+	return corev1.ResourceRequirements{
+		Limits:   resources,
+		Requests: resources.DeepCopy(),
+	}
+}
+
+// toK8SProbe converts a peloton health check to a k8s probe. It returns nil
+// if the health check is not enabled.
+func toK8SProbe(hc *pbpod.HealthCheckSpec, liveness bool) *corev1.Probe {
+	if !hc.GetEnabled() {
+		return nil
+	}
+
+	probe := &corev1.Probe{
+		InitialDelaySeconds: int32(valueOrDefault(
+			hc.GetInitialIntervalSecs(),
+			_defaultHealthCheckInitialIntervalSecs)),
+		PeriodSeconds: int32(valueOrDefault(
+			hc.GetIntervalSecs(),
+			_defaultHealthCheckIntervalSecs)),
+		TimeoutSeconds: int32(valueOrDefault(
+			hc.GetTimeoutSecs(),
+			_defaultHealthCheckTimeoutSecs)),
+		FailureThreshold: int32(valueOrDefault(
+			hc.GetMaxConsecutiveFailures(),
+			_defaultHealthCheckMaxConsecutiveFailures)),
+	}
+
+	// k8s requires the success threshold of liveness probes to be 1
+	if !liveness {
+		probe.SuccessThreshold = int32(hc.GetSuccessThreshold())
+	}
+
+	switch hc.GetType() {
+	case pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND:
+		if hc.GetCommand().GetValue() != "" {
+			probe.Exec = &corev1.ExecAction{
+				Command: append(
+					[]string{hc.GetCommand().GetValue()},
+					hc.GetCommand().GetArguments()...),
+			}
+		} else {
+			// the deprecated command check is a shell command line
+			probe.Exec = &corev1.ExecAction{
+				Command: []string{"sh", "-c", hc.GetCommandCheck().GetCommand()},
+			}
+		}
+	case pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP:
+		probe.HTTPGet = toK8SHTTPGetAction(hc)
+	default:
+		return nil
+	}
+
+	return probe
+}
+
+// toK8SHTTPGetAction converts a peloton HTTP health check to a k8s HTTP get
+// action, falling back to the deprecated HTTP check config.
+func toK8SHTTPGetAction(hc *pbpod.HealthCheckSpec) *corev1.HTTPGetAction {
+	if hc.GetHttpGet() == nil {
+		return &corev1.HTTPGetAction{
+			Scheme: toK8SURIScheme(hc.GetHttpCheck().GetScheme()),
+			Port:   intstr.FromInt(int(hc.GetHttpCheck().GetPort())),
+			Path:   hc.GetHttpCheck().GetPath(),
+		}
+	}
+
+	httpGet := hc.GetHttpGet()
+	port := intstr.FromInt(int(httpGet.GetPort()))
+	if portSpec := httpGet.GetPortSpec(); portSpec != nil {
+		if portSpec.GetValue() != 0 {
+			port = intstr.FromInt(int(portSpec.GetValue()))
+		} else {
+			// dynamic ports are looked up by name in the container ports
+			port = intstr.FromString(portSpec.GetName())
+		}
+	}
+
+	var headers []corev1.HTTPHeader
+	for _, h := range httpGet.GetHttpHeaders() {
+		headers = append(headers, corev1.HTTPHeader{
+			Name:  h.GetName(),
+			Value: h.GetValue(),
+		})
+	}
+
+	return &corev1.HTTPGetAction{
+		Scheme:      toK8SURIScheme(httpGet.GetScheme()),
+		Port:        port,
+		Path:        httpGet.GetPath(),
+		HTTPHeaders: headers,
+	}
+}
+
+// toK8SURIScheme converts the scheme of a HTTP health check to k8s URI scheme
+func toK8SURIScheme(scheme string) corev1.URIScheme {
+	if strings.ToLower(scheme) == "https" {
+		return corev1.URISchemeHTTPS
+	}
+	return corev1.URISchemeHTTP
+}
+
+// valueOrDefault returns the value if it is set, otherwise the default
+func valueOrDefault(value, defaultValue uint32) uint32 {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// toK8SVolumes converts the peloton volumes of a pod to k8s volumes
+func toK8SVolumes(volumes []*pbvolume.VolumeSpec) []corev1.Volume {
+	var kVolumes []corev1.Volume
+	for _, v := range volumes {
+		kVolume := corev1.Volume{Name: v.GetName()}
+
+		switch v.GetType() {
+		case pbvolume.VolumeSpec_VOLUME_TYPE_EMPTY_DIR:
+			emptyDir := &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMedium(v.GetEmptyDir().GetMedium()),
+			}
+			if sizeMb := v.GetEmptyDir().GetSizeInMb(); sizeMb > 0 {
+				emptyDir.SizeLimit = resource.NewQuantity(
+					int64(sizeMb)*1000000,
+					resource.DecimalSI,
+				)
+			}
+			kVolume.EmptyDir = emptyDir
+		case pbvolume.VolumeSpec_VOLUME_TYPE_HOST_PATH:
+			kVolume.HostPath = &corev1.HostPathVolumeSource{
+				Path: v.GetHostPath().GetPath(),
+			}
+		default:
+			continue
+		}
+
+		kVolumes = append(kVolumes, kVolume)
+	}
+	return kVolumes
+}
+
 // Convert peloton podspec to k8s podspec.
 func toK8SPodSpec(podSpec *pbpod.PodSpec) *corev1.Pod {
 	// Create pod template spec and apply configurations to spec.
@@ -147,6 +345,7 @@ func toK8SPodSpec(podSpec *pbpod.PodSpec) *corev1.Pod {
 		Spec: corev1.PodSpec{
 			Containers:                    toK8SContainerSpecs(podSpec.GetContainers()),
 			InitContainers:                toK8SContainerSpecs(podSpec.GetInitContainers()),
+			Volumes:                       toK8SVolumes(podSpec.GetVolumes()),
 			RestartPolicy:                 "Never",
 			TerminationGracePeriodSeconds: &termGracePeriod,
 		},
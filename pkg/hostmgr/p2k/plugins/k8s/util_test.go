@@ -18,9 +18,13 @@ import (
 	"testing"
 
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	pbvolume "github.com/uber/peloton/.gen/peloton/api/v1alpha/volume"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestToK8SPodSpec(t *testing.T) {
@@ -69,3 +73,204 @@ func TestToK8SPodSpec(t *testing.T) {
 		testPodSpec.Containers[0].VolumeMounts[0].Name,
 	)
 }
+
+func TestToK8SNamespace(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(_defaultNamespace, toK8SNamespace(""))
+	require.Equal(_defaultNamespace, toK8SNamespace("/"))
+	require.Equal("infra-compute", toK8SNamespace("/infra/compute"))
+	require.Equal("infra-my-pool", toK8SNamespace("/Infra/My_Pool/"))
+
+	long := toK8SNamespace("/" + string(make([]byte, 100)))
+	require.Equal(_defaultNamespace, long)
+
+	namespace := toK8SNamespace(
+		"/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/b")
+	require.True(len(namespace) <= _maxNamespaceLength)
+	require.NotEqual('-', namespace[len(namespace)-1])
+}
+
+func TestToK8SResources(t *testing.T) {
+	require := require.New(t)
+
+	resources := toK8SResources(&pbpod.ResourceSpec{
+		CpuLimit:    1.5,
+		MemLimitMb:  200,
+		DiskLimitMb: 1000,
+		GpuLimit:    1,
+	})
+	require.Equal(resources.Limits, resources.Requests)
+
+	cpu := resources.Limits[corev1.ResourceCPU]
+	require.Equal(int64(1500), cpu.MilliValue())
+	mem := resources.Limits[corev1.ResourceMemory]
+	require.Equal(int64(200000000), mem.Value())
+	disk := resources.Limits[corev1.ResourceEphemeralStorage]
+	require.Equal(int64(1000000000), disk.Value())
+	gpu := resources.Limits[_gpuResourceName]
+	require.Equal(int64(1), gpu.Value())
+
+	// memory below the minimum is raised to the minimum, and disk and gpu
+	// are not set if there is no limit
+	resources = toK8SResources(&pbpod.ResourceSpec{
+		CpuLimit:   1,
+		MemLimitMb: 10,
+	})
+	mem = resources.Limits[corev1.ResourceMemory]
+	require.Equal(int64(_defaultMinMemMb*1000000), mem.Value())
+	_, ok := resources.Limits[corev1.ResourceEphemeralStorage]
+	require.False(ok)
+	_, ok = resources.Limits[_gpuResourceName]
+	require.False(ok)
+}
+
+func TestToK8SProbe(t *testing.T) {
+	require := require.New(t)
+
+	require.Nil(toK8SProbe(nil, true))
+	require.Nil(toK8SProbe(&pbpod.HealthCheckSpec{Enabled: false}, true))
+
+	// command health check with default values
+	probe := toK8SProbe(&pbpod.HealthCheckSpec{
+		Enabled:          true,
+		Type:             pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+		SuccessThreshold: 2,
+		Command: &pbpod.CommandSpec{
+			Value:     "/bin/check",
+			Arguments: []string{"-v"},
+		},
+	}, true)
+	require.NotNil(probe)
+	require.Equal([]string{"/bin/check", "-v"}, probe.Exec.Command)
+	require.Equal(int32(_defaultHealthCheckInitialIntervalSecs), probe.InitialDelaySeconds)
+	require.Equal(int32(_defaultHealthCheckIntervalSecs), probe.PeriodSeconds)
+	require.Equal(int32(_defaultHealthCheckTimeoutSecs), probe.TimeoutSeconds)
+	require.Equal(int32(_defaultHealthCheckMaxConsecutiveFailures), probe.FailureThreshold)
+	require.Equal(int32(0), probe.SuccessThreshold)
+
+	// deprecated command check
+	probe = toK8SProbe(&pbpod.HealthCheckSpec{
+		Enabled: true,
+		Type:    pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+		CommandCheck: &pbpod.HealthCheckSpec_CommandCheck{
+			Command: "curl localhost",
+		},
+	}, false)
+	require.Equal([]string{"sh", "-c", "curl localhost"}, probe.Exec.Command)
+
+	// http health check
+	probe = toK8SProbe(&pbpod.HealthCheckSpec{
+		Enabled:             true,
+		Type:                pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP,
+		InitialIntervalSecs: 5,
+		IntervalSecs:        1,
+		TimeoutSecs:         2,
+		SuccessThreshold:    2,
+		HttpGet: &pbpod.HTTPGetSpec{
+			Scheme: "https",
+			Path:   "/health",
+			PortSpec: &pbpod.PortSpec{
+				Name: "http",
+			},
+			HttpHeaders: []*pbpod.HTTPGetSpec_HTTPHeader{
+				{Name: "key", Value: "value"},
+			},
+		},
+	}, false)
+	require.NotNil(probe.HTTPGet)
+	require.Equal(corev1.URISchemeHTTPS, probe.HTTPGet.Scheme)
+	require.Equal("/health", probe.HTTPGet.Path)
+	require.Equal(intstr.FromString("http"), probe.HTTPGet.Port)
+	require.Equal(
+		[]corev1.HTTPHeader{{Name: "key", Value: "value"}},
+		probe.HTTPGet.HTTPHeaders)
+	require.Equal(int32(5), probe.InitialDelaySeconds)
+	require.Equal(int32(1), probe.PeriodSeconds)
+	require.Equal(int32(2), probe.TimeoutSeconds)
+	require.Equal(int32(2), probe.SuccessThreshold)
+
+	// deprecated http check
+	probe = toK8SProbe(&pbpod.HealthCheckSpec{
+		Enabled: true,
+		Type:    pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP,
+		HttpCheck: &pbpod.HealthCheckSpec_HTTPCheck{
+			Port: 8080,
+			Path: "/health",
+		},
+	}, true)
+	require.Equal(corev1.URISchemeHTTP, probe.HTTPGet.Scheme)
+	require.Equal(intstr.FromInt(8080), probe.HTTPGet.Port)
+}
+
+func TestToK8SPodSpecFull(t *testing.T) {
+	require := require.New(t)
+
+	testPodSpec := &pbpod.PodSpec{
+		Containers: []*pbpod.ContainerSpec{
+			{
+				Name: "container",
+				Resource: &pbpod.ResourceSpec{
+					CpuLimit:   1.0,
+					MemLimitMb: 100.0,
+				},
+				Ports: []*pbpod.PortSpec{
+					{
+						Name:    "http",
+						Value:   31000,
+						EnvName: "PORT_HTTP",
+					},
+				},
+				Environment: []*pbpod.Environment{
+					{Name: "KEY", Value: "value"},
+				},
+				LivenessCheck: &pbpod.HealthCheckSpec{
+					Enabled: true,
+					Type:    pbpod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+					Command: &pbpod.CommandSpec{Value: "/bin/check"},
+				},
+				VolumeMounts: []*pbpod.VolumeMount{
+					{Name: "scratch", MountPath: "/scratch"},
+				},
+			},
+		},
+		Volumes: []*pbvolume.VolumeSpec{
+			{
+				Name: "scratch",
+				Type: pbvolume.VolumeSpec_VOLUME_TYPE_EMPTY_DIR,
+				EmptyDir: &pbvolume.VolumeSpec_EmptyDirVolumeSource{
+					Medium:   "Memory",
+					SizeInMb: 10,
+				},
+			},
+			{
+				Name: "logs",
+				Type: pbvolume.VolumeSpec_VOLUME_TYPE_HOST_PATH,
+				HostPath: &pbvolume.VolumeSpec_HostPathVolumeSource{
+					Path: "/var/log",
+				},
+			},
+			{
+				Name: "invalid",
+			},
+		},
+	}
+
+	pod := toK8SPodSpec(testPodSpec)
+	container := pod.Spec.Containers[0]
+	require.Equal([]corev1.EnvVar{
+		{Name: "KEY", Value: "value"},
+		{Name: "PORT_HTTP", Value: "31000"},
+	}, container.Env)
+	require.Equal(int32(31000), container.Ports[0].ContainerPort)
+	require.NotNil(container.LivenessProbe)
+	require.Nil(container.ReadinessProbe)
+
+	require.Len(pod.Spec.Volumes, 2)
+	require.Equal("scratch", pod.Spec.Volumes[0].Name)
+	require.Equal(corev1.StorageMediumMemory, pod.Spec.Volumes[0].EmptyDir.Medium)
+	require.Equal(
+		resource.NewQuantity(10000000, resource.DecimalSI).Value(),
+		pod.Spec.Volumes[0].EmptyDir.SizeLimit.Value())
+	require.Equal("/var/log", pod.Spec.Volumes[1].HostPath.Path)
+}
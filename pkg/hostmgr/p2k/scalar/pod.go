@@ -1,8 +1,12 @@
 package scalar
 
 import (
+	"fmt"
+	"strings"
+	"syscall"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
@@ -12,6 +16,15 @@ import (
 // PodEventType describes the type of pod event sent by plugin.
 type PodEventType int
 
+const (
+	// _oomKilledReason is the reason of containers killed for exceeding
+	// their memory limit.
+	_oomKilledReason = "OOMKilled"
+	// _containerCreatingReason is the reason of containers waiting to be
+	// created, which is not a failure.
+	_containerCreatingReason = "ContainerCreating"
+)
+
 const (
 	// AddPod event type.
 	AddPod PodEventType = iota + 1
@@ -56,6 +69,8 @@ func BuildPodEventFromPod(
 		convertedContainerStatuses[i] = buildContainerStatus(cspec, status)
 	}
 
+	reason, message := buildPodReasonAndMessage(pod)
+
 	return &PodEvent{
 		Event: &pbpod.PodEvent{
 			PodId:               &peloton.PodID{Value: pod.Name},
@@ -63,8 +78,8 @@ func BuildPodEventFromPod(
 			Timestamp:           time.Now().Format(time.RFC3339),
 			AgentId:             pod.Spec.NodeName,
 			Hostname:            pod.Spec.NodeName,
-			Message:             pod.Status.Message,
-			Reason:              pod.Status.Reason,
+			Message:             message,
+			Reason:              reason,
 			Healthy:             buildPodHealthStatus(pod.Status.Conditions),
 			InitContainerStatus: convertedInitStatuses,
 			ContainerStatus:     convertedContainerStatuses,
@@ -98,6 +113,66 @@ func buildPodState(phase corev1.PodPhase, e PodEventType) string {
 	return pbpod.PodState_POD_STATE_INVALID.String()
 }
 
+// buildPodReasonAndMessage returns the reason and message of the pod. K8s
+// sets them only for pod level failures like evictions, otherwise they are
+// derived from the container statuses so that container failures like OOM
+// kills and non-zero exit codes are surfaced in the pod event. The reasons
+// and messages of container failures are the same as the ones of Mesos
+// tasks, so that they are handled the same way by jobmgr.
+func buildPodReasonAndMessage(pod *corev1.Pod) (string, string) {
+	if pod.Status.Reason != "" || pod.Status.Message != "" {
+		return pod.Status.Reason, pod.Status.Message
+	}
+
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		terminated := status.State.Terminated
+		if terminated == nil ||
+			(terminated.ExitCode == 0 && terminated.Signal == 0) {
+			continue
+		}
+
+		reason := mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String()
+		if terminated.Reason == _oomKilledReason {
+			reason = mesos.TaskStatus_REASON_CONTAINER_LIMITATION_MEMORY.String()
+		}
+
+		message := fmt.Sprintf(
+			"Command exited with status %d", terminated.ExitCode)
+		if terminated.Signal != 0 {
+			message = fmt.Sprintf(
+				"Container terminated with signal %s",
+				signalName(terminated.Signal))
+		}
+		return reason, message
+	}
+
+	// surface the reason containers are stuck waiting, e.g. image pull
+	// failures or crash loops
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting != nil &&
+			waiting.Reason != "" &&
+			waiting.Reason != _containerCreatingReason {
+			return waiting.Reason, waiting.Message
+		}
+	}
+	return "", ""
+}
+
+// signalName returns the name of the signal in the format used by Mesos,
+// e.g. Killed for SIGKILL.
+func signalName(signal int32) string {
+	name := syscall.Signal(signal).String()
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
 // buildPodHealthStatus returns healthy only if default liveness and readiness health check all
 // passed, and all custom readiness gates defined in spec also passed.
 // See https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-readiness-gate
@@ -116,7 +191,6 @@ func buildPodHealthStatus(conditions []corev1.PodCondition) string {
 }
 
 // buildContainerStatus constructs peloton container status from k8s container status.
-// TODO: missing ports and termination reason.
 func buildContainerStatus(containerSpec *corev1.Container, containerStatus *corev1.ContainerStatus) *pbpod.ContainerStatus {
 	var reason, message string
 	var startedAt, finishedAt time.Time
@@ -143,15 +217,26 @@ func buildContainerStatus(containerSpec *corev1.Container, containerStatus *core
 		healthState = pbpod.HealthState_HEALTH_STATE_UNHEALTHY
 		terminationStatus = &pbpod.TerminationStatus{
 			ExitCode: uint32(containerStatus.State.Terminated.ExitCode),
-			Signal:   string(containerStatus.State.Terminated.Signal),
-			// TODO: Reason
+		}
+		if containerStatus.State.Terminated.Signal != 0 {
+			terminationStatus.Signal = signalName(
+				containerStatus.State.Terminated.Signal)
 		}
 
 		if containerStatus.State.Terminated.ExitCode == 0 &&
 			containerStatus.State.Terminated.Signal == 0 {
 			state = pbpod.ContainerState_CONTAINER_STATE_SUCCEEDED
 		} else {
-			if containerStatus.State.Terminated.Signal != 0 {
+			terminationStatus.Reason =
+				pbpod.TerminationStatus_TERMINATION_STATUS_REASON_FAILED
+			if containerStatus.State.Terminated.Reason == _oomKilledReason {
+				// the container was killed by the kernel for exceeding its
+				// memory limit, which is a failure of the container
+				state = pbpod.ContainerState_CONTAINER_STATE_FAILED
+				if message == "" {
+					message = "Memory limit exceeded"
+				}
+			} else if containerStatus.State.Terminated.Signal != 0 {
 				state = pbpod.ContainerState_CONTAINER_STATE_KILLED
 			} else {
 				state = pbpod.ContainerState_CONTAINER_STATE_FAILED
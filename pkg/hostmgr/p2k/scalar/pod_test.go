@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	corev1 "k8s.io/api/core/v1"
@@ -264,7 +265,6 @@ func TestBuildPodEventFromPodSucceeded(t *testing.T) {
 			CompletionTime: k8sContainerStatus[i].State.Terminated.FinishedAt.Time.Format(time.RFC3339),
 			TerminationStatus: &pbpod.TerminationStatus{
 				ExitCode: uint32(k8sContainerStatus[i].State.Terminated.ExitCode),
-				Signal:   string(k8sContainerStatus[i].State.Terminated.Signal),
 			},
 			FailureCount: uint32(k8sContainerStatus[i].RestartCount),
 			Healthy: &pbpod.HealthStatus{
@@ -366,3 +366,135 @@ func TestBuildPodState(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildPodEventFromPodContainerFailures(t *testing.T) {
+	testCases := []struct {
+		name           string
+		state          corev1.ContainerState
+		reason         string
+		message        string
+		containerState pbpod.ContainerState
+		signal         string
+	}{
+		{
+			"oom killed",
+			corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Reason:   "OOMKilled",
+					ExitCode: 137,
+				},
+			},
+			mesos.TaskStatus_REASON_CONTAINER_LIMITATION_MEMORY.String(),
+			"Command exited with status 137",
+			pbpod.ContainerState_CONTAINER_STATE_FAILED,
+			"",
+		},
+		{
+			"non-zero exit code",
+			corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Reason:   "Error",
+					ExitCode: 1,
+				},
+			},
+			mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			"Command exited with status 1",
+			pbpod.ContainerState_CONTAINER_STATE_FAILED,
+			"",
+		},
+		{
+			"signal",
+			corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Signal: 9,
+				},
+			},
+			mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			"Container terminated with signal Killed",
+			pbpod.ContainerState_CONTAINER_STATE_KILLED,
+			"Killed",
+		},
+		{
+			"image pull failure",
+			corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ErrImagePull",
+					Message: "image not found",
+				},
+			},
+			"ErrImagePull",
+			"image not found",
+			pbpod.ContainerState_CONTAINER_STATE_LAUNCHED,
+			"",
+		},
+		{
+			"container creating",
+			corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason: "ContainerCreating",
+				},
+			},
+			"",
+			"",
+			pbpod.ContainerState_CONTAINER_STATE_LAUNCHED,
+			"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod1",
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodFailed,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "container1",
+							State: tc.state,
+						},
+					},
+				},
+			}
+
+			evt := BuildPodEventFromPod(pod, UpdatePod)
+			require.Equal(tc.reason, evt.Event.GetReason())
+			require.Equal(tc.message, evt.Event.GetMessage())
+
+			status := evt.Event.GetContainerStatus()[0]
+			require.Equal(tc.containerState, status.GetState())
+			require.Equal(tc.signal, status.GetTerminationStatus().GetSignal())
+		})
+	}
+}
+
+func TestBuildPodEventFromPodReasonFromPodStatus(t *testing.T) {
+	require := require.New(t)
+
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "Evicted",
+			Message: "node is low on memory",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 137,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	evt := BuildPodEventFromPod(pod, UpdatePod)
+	require.Equal("Evicted", evt.Event.GetReason())
+	require.Equal("node is low on memory", evt.Event.GetMessage())
+	require.Equal(
+		pbpod.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
+		evt.Event.GetContainerStatus()[0].GetTerminationStatus().GetReason())
+}
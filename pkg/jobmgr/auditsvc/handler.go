@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _defaultQueryRange is the time range queried
+	// if the start of the time range is not set
+	_defaultQueryRange = 24 * time.Hour
+
+	// _maxQueryRange is the maximum time range which can be queried,
+	// since the audit events of each day are read from the store
+	_maxQueryRange = 31 * 24 * time.Hour
+
+	// _defaultQueryLimit is the number of audit events
+	// returned if the limit is not set
+	_defaultQueryLimit = 100
+)
+
+// InitServiceHandler initializes the audit service.
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+) {
+	handler := &serviceHandler{
+		auditEventOps: ormobjects.NewAuditEventOps(ormStore),
+		metrics:       NewMetrics(parent.SubScope("jobmgr").SubScope("audit")),
+		now:           time.Now,
+	}
+
+	d.Register(auditsvc.BuildAuditServiceYARPCProcedures(handler))
+}
+
+// serviceHandler implements peloton.api.v1alpha.audit.svc.AuditService
+type serviceHandler struct {
+	auditEventOps ormobjects.AuditEventOps
+	metrics       *Metrics
+	now           func() time.Time
+}
+
+// QueryAuditEvents returns the audit events recorded in a time range
+// which match the request, most recent first.
+func (h *serviceHandler) QueryAuditEvents(
+	ctx context.Context,
+	req *auditsvc.QueryAuditEventsRequest,
+) (resp *auditsvc.QueryAuditEventsResponse, err error) {
+	h.metrics.AuditAPIQuery.Inc(1)
+
+	defer func() {
+		if err != nil {
+			h.metrics.AuditQueryFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("AuditService.QueryAuditEvents failed")
+			return
+		}
+
+		h.metrics.AuditQuery.Inc(1)
+		log.WithField("request", req).
+			WithField("num_events", len(resp.GetEvents())).
+			Debug("AuditService.QueryAuditEvents succeeded")
+	}()
+
+	start, end, err := h.parseTimeRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*audit.AuditEvent
+	var eventTimes []time.Time
+	// events are partitioned by day in UTC, read each day of the range
+	lastDay := truncateToDay(end)
+	for day := truncateToDay(start); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		dayEvents, err := h.auditEventOps.GetAll(ctx, day)
+		if err != nil {
+			return nil, yarpcerrors.InternalErrorf(
+				"failed to get audit events: %v", err)
+		}
+
+		for _, event := range dayEvents {
+			eventTime, err := time.Parse(time.RFC3339Nano, event.GetTimestamp())
+			if err != nil ||
+				eventTime.Before(start) ||
+				eventTime.After(end) ||
+				!matchEvent(req, event) {
+				continue
+			}
+			events = append(events, event)
+			eventTimes = append(eventTimes, eventTime)
+		}
+	}
+
+	sort.Sort(byTimeDesc{events: events, times: eventTimes})
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = _defaultQueryLimit
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return &auditsvc.QueryAuditEventsResponse{Events: events}, nil
+}
+
+// parseTimeRange returns the time range of a query
+func (h *serviceHandler) parseTimeRange(
+	req *auditsvc.QueryAuditEventsRequest,
+) (time.Time, time.Time, error) {
+	end := h.now()
+	if len(req.GetEndTime()) != 0 {
+		var err error
+		if end, err = time.Parse(time.RFC3339, req.GetEndTime()); err != nil {
+			return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+				"invalid end time %s", req.GetEndTime())
+		}
+	}
+
+	start := end.Add(-_defaultQueryRange)
+	if len(req.GetStartTime()) != 0 {
+		var err error
+		if start, err = time.Parse(time.RFC3339, req.GetStartTime()); err != nil {
+			return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+				"invalid start time %s", req.GetStartTime())
+		}
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+			"start time is after end time")
+	}
+	if end.Sub(start) > _maxQueryRange {
+		return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+			"time range is longer than %v", _maxQueryRange)
+	}
+	return start, end, nil
+}
+
+// matchEvent returns true if the audit event matches the filters of the query
+func matchEvent(
+	req *auditsvc.QueryAuditEventsRequest,
+	event *audit.AuditEvent,
+) bool {
+	if len(req.GetUser()) != 0 && req.GetUser() != event.GetUser() {
+		return false
+	}
+
+	if len(req.GetProcedure()) != 0 &&
+		!strings.Contains(event.GetProcedure(), req.GetProcedure()) {
+		return false
+	}
+
+	if req.GetOutcome() != audit.Outcome_OUTCOME_INVALID &&
+		req.GetOutcome() != event.GetOutcome() {
+		return false
+	}
+
+	if len(req.GetResource()) != 0 {
+		for _, resources := range [][]string{
+			event.GetJobIds(),
+			event.GetPodNames(),
+			event.GetResourcePools(),
+			event.GetHostnames(),
+		} {
+			for _, resource := range resources {
+				if resource == req.GetResource() {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// byTimeDesc sorts audit events by time, most recent first
+type byTimeDesc struct {
+	events []*audit.AuditEvent
+	times  []time.Time
+}
+
+func (s byTimeDesc) Len() int { return len(s.events) }
+
+func (s byTimeDesc) Less(i, j int) bool { return s.times[i].After(s.times[j]) }
+
+func (s byTimeDesc) Swap(i, j int) {
+	s.events[i], s.events[j] = s.events[j], s.events[i]
+	s.times[i], s.times[j] = s.times[j], s.times[i]
+}
+
+// truncateToDay returns the start of the day of t in UTC
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type handlerTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	auditEventOps *objectmocks.MockAuditEventOps
+	handler       *serviceHandler
+
+	now    time.Time
+	events []*audit.AuditEvent
+}
+
+func (suite *handlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditEventOps = objectmocks.NewMockAuditEventOps(suite.ctrl)
+	suite.now = time.Date(2019, 3, 2, 12, 0, 0, 0, time.UTC)
+	suite.handler = &serviceHandler{
+		auditEventOps: suite.auditEventOps,
+		metrics:       NewMetrics(tally.NoopScope),
+		now:           func() time.Time { return suite.now },
+	}
+
+	suite.events = []*audit.AuditEvent{
+		{
+			EventId:   "event-1",
+			Timestamp: "2019-03-02T10:00:00Z",
+			User:      "alice",
+			Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			JobIds:    []string{"job-1"},
+			Outcome:   audit.Outcome_OUTCOME_SUCCEEDED,
+		},
+		{
+			EventId:       "event-2",
+			Timestamp:     "2019-03-02T10:00:00.5Z",
+			User:          "bob",
+			Procedure:     "peloton.api.v0.respool.ResourcePoolService::DeleteResourcePool",
+			ResourcePools: []string{"respool-1"},
+			Outcome:       audit.Outcome_OUTCOME_FAILED,
+		},
+		{
+			EventId:   "event-3",
+			Timestamp: "2019-03-02T11:00:00Z",
+			User:      "alice",
+			Procedure: "peloton.api.v0.host.svc.HostService::StartMaintenance",
+			Hostnames: []string{"host-1"},
+			Outcome:   audit.Outcome_OUTCOME_SUCCEEDED,
+		},
+	}
+}
+
+func (suite *handlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestAuditServiceHandler(t *testing.T) {
+	suite.Run(t, new(handlerTestSuite))
+}
+
+// eventIDs returns the identifiers of the audit events
+func eventIDs(events []*audit.AuditEvent) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.GetEventId())
+	}
+	return ids
+}
+
+// TestQueryAuditEventsDefaultRange tests querying the audit
+// events of the last day, most recent first
+func (suite *handlerTestSuite) TestQueryAuditEventsDefaultRange() {
+	suite.auditEventOps.EXPECT().
+		GetAll(gomock.Any(), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)).
+		Return(nil, nil)
+	suite.auditEventOps.EXPECT().
+		GetAll(gomock.Any(), time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)).
+		Return(suite.events, nil)
+
+	resp, err := suite.handler.QueryAuditEvents(
+		context.Background(),
+		&auditsvc.QueryAuditEventsRequest{},
+	)
+	suite.NoError(err)
+	suite.Equal(
+		[]string{"event-3", "event-2", "event-1"},
+		eventIDs(resp.GetEvents()),
+	)
+}
+
+// TestQueryAuditEventsFilters tests filtering the audit events
+func (suite *handlerTestSuite) TestQueryAuditEventsFilters() {
+	tests := []struct {
+		req      *auditsvc.QueryAuditEventsRequest
+		expected []string
+	}{
+		{
+			req:      &auditsvc.QueryAuditEventsRequest{User: "alice"},
+			expected: []string{"event-3", "event-1"},
+		},
+		{
+			req:      &auditsvc.QueryAuditEventsRequest{Procedure: "StopJob"},
+			expected: []string{"event-1"},
+		},
+		{
+			req:      &auditsvc.QueryAuditEventsRequest{Resource: "respool-1"},
+			expected: []string{"event-2"},
+		},
+		{
+			req:      &auditsvc.QueryAuditEventsRequest{Resource: "host-1"},
+			expected: []string{"event-3"},
+		},
+		{
+			req: &auditsvc.QueryAuditEventsRequest{
+				Outcome: audit.Outcome_OUTCOME_FAILED,
+			},
+			expected: []string{"event-2"},
+		},
+		{
+			req: &auditsvc.QueryAuditEventsRequest{
+				StartTime: "2019-03-02T10:30:00Z",
+			},
+			expected: []string{"event-3"},
+		},
+		{
+			req:      &auditsvc.QueryAuditEventsRequest{Limit: 1},
+			expected: []string{"event-3"},
+		},
+	}
+
+	suite.auditEventOps.EXPECT().
+		GetAll(gomock.Any(), time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)).
+		Return(nil, nil).
+		AnyTimes()
+	suite.auditEventOps.EXPECT().
+		GetAll(gomock.Any(), time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)).
+		Return(suite.events, nil).
+		AnyTimes()
+
+	for _, test := range tests {
+		resp, err := suite.handler.QueryAuditEvents(context.Background(), test.req)
+		suite.NoError(err)
+		suite.Equal(test.expected, eventIDs(resp.GetEvents()), test.req.String())
+	}
+}
+
+// TestQueryAuditEventsInvalidTimeRange tests querying
+// the audit events with an invalid time range
+func (suite *handlerTestSuite) TestQueryAuditEventsInvalidTimeRange() {
+	requests := []*auditsvc.QueryAuditEventsRequest{
+		{StartTime: "yesterday"},
+		{EndTime: "today"},
+		{StartTime: "2019-03-02T11:00:00Z", EndTime: "2019-03-02T10:00:00Z"},
+		{StartTime: "2019-01-01T00:00:00Z", EndTime: "2019-03-02T10:00:00Z"},
+	}
+
+	for _, req := range requests {
+		_, err := suite.handler.QueryAuditEvents(context.Background(), req)
+		suite.True(yarpcerrors.IsInvalidArgument(err), req.String())
+	}
+}
+
+// TestQueryAuditEventsStoreError tests the failure
+// to read the audit events from the store
+func (suite *handlerTestSuite) TestQueryAuditEventsStoreError() {
+	suite.auditEventOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("store error"))
+
+	_, err := suite.handler.QueryAuditEvents(
+		context.Background(),
+		&auditsvc.QueryAuditEventsRequest{},
+	)
+	suite.True(yarpcerrors.IsInternal(err))
+}
@@ -399,6 +399,13 @@ type job struct {
 	// failures.
 	jobType pbjob.JobType
 
+	// respoolID is the resource pool of the job, which is not changed by
+	// job updates. Unlike config, it is guarded by respoolLock instead of
+	// the job lock, so that the task listeners can be notified of the
+	// resource pool while the job lock is held.
+	respoolLock sync.RWMutex
+	respoolID   *peloton.ResourcePoolID
+
 	jobFactory *jobFactory // Pointer to the parent job factory object
 
 	tasks map[uint32]*task // map of all job tasks
@@ -1228,6 +1235,12 @@ func (j *job) IsPartiallyCreated(config jobmgrcommon.JobConfig) bool {
 	j.RLock()
 	defer j.RUnlock()
 
+	// The instances of a daemon job are only added by updates, and
+	// the IDs of the instances removed with their host are left vacant.
+	if config.GetType() == pbjob.JobType_DAEMON {
+		return false
+	}
+
 	// While the instance count is being reduced in an update,
 	// the number of instance in the cache will exceed the instance
 	// count in the configuration.
@@ -1300,6 +1313,15 @@ func (j *job) GetCachedConfig() jobmgrcommon.JobConfig {
 	return j.config
 }
 
+// getRespoolID returns the resource pool of the job, or nil if the job
+// config has not been cached yet. It does not acquire the job lock.
+func (j *job) getRespoolID() *peloton.ResourcePoolID {
+	j.respoolLock.RLock()
+	defer j.respoolLock.RUnlock()
+
+	return j.respoolID
+}
+
 // RepopulateInstanceAvailabilityInfo repopulates the instance availability information in the job cache
 func (j *job) RepopulateInstanceAvailabilityInfo(ctx context.Context) error {
 	if j.jobType != pbjob.JobType_SERVICE {
@@ -1838,6 +1860,10 @@ func (j *job) populateJobConfigCache(config *pbjob.JobConfig) {
 
 	if config.GetRespoolID() != nil {
 		j.config.respoolID = config.GetRespoolID()
+
+		j.respoolLock.Lock()
+		j.respoolID = config.GetRespoolID()
+		j.respoolLock.Unlock()
 	}
 
 	if config.GetLabels() != nil {
@@ -389,8 +389,26 @@ func (f *jobFactory) notifyTaskRuntimeChanged(
 			},
 			Status: api.ConvertTaskRuntimeToPodStatus(runtime),
 		}
+
+		// the listeners are notified with the job lock possibly held,
+		// so the resource pool is read without acquiring it
+		var respoolID *v1peloton.ResourcePoolID
+		f.RLock()
+		j, ok := f.jobs[jobID.GetValue()]
+		f.RUnlock()
+		if ok && j.getRespoolID() != nil {
+			respoolID = &v1peloton.ResourcePoolID{
+				Value: j.getRespoolID().GetValue(),
+			}
+		}
+
 		for _, l := range f.listeners {
-			l.PodSummaryChanged(jobType, summary, api.ConvertLabels(labels))
+			l.PodSummaryChanged(
+				jobType,
+				summary,
+				api.ConvertLabels(labels),
+				respoolID,
+			)
 		}
 		// TODO add metric for listener execution latency
 	}
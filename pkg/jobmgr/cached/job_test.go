@@ -1860,6 +1860,10 @@ func (suite *jobTestSuite) TestPartialJobCheck() {
 	// Test partial job check
 	suite.job.config.instanceCount = 20
 	suite.True(suite.job.IsPartiallyCreated(suite.job.config))
+
+	// the instance IDs of a daemon job can be vacant
+	suite.job.config.jobType = pbjob.JobType_DAEMON
+	suite.False(suite.job.IsPartiallyCreated(suite.job.config))
 }
 
 // TestPatchTasks_SetGetTasksSingle tests setting and getting single task in job in cache.
@@ -53,11 +53,13 @@ type JobTaskListener interface {
 	)
 
 	// PodSummaryChanged is invoked when the status for a task is updated
-	// in cache and persistent store.
+	// in cache and persistent store. The resource pool of the job is nil
+	// if the job config is not in cache.
 	PodSummaryChanged(
 		// TODO Remove once batch moves to v1 alpha apis
 		jobType pbjob.JobType,
 		summary *pod.PodSummary,
 		labels []*v1peloton.Label,
+		respoolID *v1peloton.ResourcePoolID,
 	)
 }
@@ -53,6 +53,7 @@ func (l *FakeJobListener) PodSummaryChanged(
 	jobType pbjob.JobType,
 	summary *pod.PodSummary,
 	labels []*peloton.Label,
+	respoolID *peloton.ResourcePoolID,
 ) {
 }
 
@@ -63,9 +64,10 @@ func (l *FakeJobListener) Reset() {
 }
 
 type FakeTaskListener struct {
-	jobType pbjob.JobType
-	summary *pod.PodSummary
-	labels  []*peloton.Label
+	jobType   pbjob.JobType
+	summary   *pod.PodSummary
+	labels    []*peloton.Label
+	respoolID *peloton.ResourcePoolID
 }
 
 func (l *FakeTaskListener) Name() string {
@@ -87,8 +89,10 @@ func (l *FakeTaskListener) PodSummaryChanged(
 	jobType pbjob.JobType,
 	summary *pod.PodSummary,
 	labels []*peloton.Label,
+	respoolID *peloton.ResourcePoolID,
 ) {
 	l.jobType = jobType
 	l.summary = summary
 	l.labels = labels
+	l.respoolID = respoolID
 }
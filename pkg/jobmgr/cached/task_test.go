@@ -108,6 +108,9 @@ func TestTask(t *testing.T) {
 	suite.Run(t, new(taskTestSuite))
 }
 
+// _testRespoolID is the resource pool of the test job
+const _testRespoolID = "test-respool"
+
 // initializeTask initializes a test task to be used in the unit test
 func (suite *taskTestSuite) initializeTask(
 	taskStore *storemocks.MockTaskStore,
@@ -141,6 +144,7 @@ func (suite *taskTestSuite) initializeTask(
 		config: config,
 		runtime: &pbjob.RuntimeInfo{
 			ConfigurationVersion: config.changeLog.Version},
+		respoolID: &peloton.ResourcePoolID{Value: _testRespoolID},
 	}
 	tt.jobFactory.jobs[jobID.GetValue()] = job
 	return tt
@@ -159,6 +163,7 @@ func (suite *taskTestSuite) checkListeners(tt *task, jobType pbjob.JobType) {
 			Status: api.ConvertTaskRuntimeToPodStatus(tt.runtime),
 		}
 		suite.Equal(summary, l.summary, msg)
+		suite.Equal(_testRespoolID, l.respoolID.GetValue(), msg)
 	}
 }
 
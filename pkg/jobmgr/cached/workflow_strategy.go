@@ -121,8 +121,9 @@ func (s *updateStrategy) GetRuntimeDiff(jobConfig *pbjob.JobConfig) jobmgrcommon
 		jobmgrcommon.DesiredConfigVersionField: jobConfig.GetChangeLog().GetVersion(),
 		jobmgrcommon.MessageField:              _updateTaskMessage,
 		// when updating a task, failure count due to old version should be reset
-		jobmgrcommon.FailureCountField: uint32(0),
-		jobmgrcommon.ReasonField:       "",
+		jobmgrcommon.FailureCountField:   uint32(0),
+		jobmgrcommon.CrashLoopCountField: uint32(0),
+		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.TerminationStatusField: &pbtask.TerminationStatus{
 			Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE,
 		},
@@ -143,8 +144,9 @@ func (s *rollbackStrategy) GetRuntimeDiff(jobConfig *pbjob.JobConfig) jobmgrcomm
 		jobmgrcommon.DesiredConfigVersionField: jobConfig.GetChangeLog().GetVersion(),
 		jobmgrcommon.MessageField:              _rollbackTaskMessage,
 		// when updating a task, failure count due to old version should be reset
-		jobmgrcommon.FailureCountField: uint32(0),
-		jobmgrcommon.ReasonField:       "",
+		jobmgrcommon.FailureCountField:   uint32(0),
+		jobmgrcommon.CrashLoopCountField: uint32(0),
+		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.TerminationStatusField: &pbtask.TerminationStatus{
 			Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE,
 		},
@@ -253,7 +255,7 @@ func (s *stopStrategy) GetRuntimeDiff(jobConfig *pbjob.JobConfig) jobmgrcommon.R
 // TODO: reuse the function in jobmgr/util, now it would create import cycle.
 func getDefaultTaskGoalState(jobType pbjob.JobType) pbtask.TaskState {
 	switch jobType {
-	case pbjob.JobType_SERVICE:
+	case pbjob.JobType_SERVICE, pbjob.JobType_DAEMON:
 		return pbtask.TaskState_RUNNING
 
 	default:
@@ -263,6 +263,11 @@ func TestUpdateStrategyGetRuntimeDiff(t *testing.T) {
 		runtimeDiff[jobmgrcommon.FailureCountField].(uint32),
 		uint32(0),
 	)
+	assert.Equal(
+		t,
+		runtimeDiff[jobmgrcommon.CrashLoopCountField].(uint32),
+		uint32(0),
+	)
 	assert.Empty(
 		t,
 		runtimeDiff[jobmgrcommon.ReasonField].(string),
@@ -20,6 +20,7 @@ const (
 	AgentIDField              = "AgentID"
 	CompletionTimeField       = "CompletionTime"
 	ConfigVersionField        = "ConfigVersion"
+	CrashLoopCountField       = "CrashLoopCount"
 	DesiredConfigVersionField = "DesiredConfigVersion"
 	DesiredHostField          = "DesiredHost"
 	DesiredMesosTaskIDField   = "DesiredMesosTaskId"
@@ -27,6 +27,7 @@ func TestTaskRuntimeInfoFieldNames(t *testing.T) {
 	fieldNames := []string{
 		AgentIDField,
 		CompletionTimeField,
+		CrashLoopCountField,
 		FailureCountField,
 		GoalStateField,
 		MesosTaskIDField,
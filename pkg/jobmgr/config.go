@@ -19,9 +19,13 @@ import (
 
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/jobmgr/cron"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
+	"github.com/uber/peloton/pkg/jobmgr/dag"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
+	"github.com/uber/peloton/pkg/jobmgr/task/descheduler"
 	"github.com/uber/peloton/pkg/jobmgr/task/evictor"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
 	"github.com/uber/peloton/pkg/jobmgr/watchsvc"
@@ -53,6 +57,18 @@ type Config struct {
 
 	Deadline deadline.Config `yaml:"deadline"`
 
+	// Descheduler specific configuration
+	Descheduler descheduler.Config `yaml:"descheduler"`
+
+	// Cron scheduler specific configuration
+	Cron cron.Config `yaml:"cron"`
+
+	// DAG manager specific configuration
+	DAG dag.Config `yaml:"dag"`
+
+	// Daemon job manager specific configuration
+	Daemon daemon.Config `yaml:"daemon"`
+
 	// Job service specific configuration
 	JobSvcCfg jobsvc.Config `yaml:"job_service"`
 
@@ -0,0 +1,42 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import "time"
+
+const (
+	_defaultSchedulingPeriod        = 30 * time.Second
+	_defaultHistoryLimit     uint32 = 10
+)
+
+// Config is the cron scheduler specific config
+type Config struct {
+	// SchedulingPeriod is the period to look for cron jobs which are due
+	SchedulingPeriod time.Duration `yaml:"scheduling_period"`
+
+	// DefaultHistoryLimit is the number of finished jobs kept for a cron
+	// job which does not specify a history limit
+	DefaultHistoryLimit uint32 `yaml:"default_history_limit"`
+}
+
+func (c *Config) normalize() {
+	if c.SchedulingPeriod == time.Duration(0) {
+		c.SchedulingPeriod = _defaultSchedulingPeriod
+	}
+
+	if c.DefaultHistoryLimit == 0 {
+		c.DefaultHistoryLimit = _defaultHistoryLimit
+	}
+}
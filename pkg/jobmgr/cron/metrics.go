@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of the cron scheduler.
+type Metrics struct {
+	SchedulingRunSuccess tally.Counter
+	SchedulingRunFail    tally.Counter
+
+	CronRunSuccess tally.Counter
+	CronRunFail    tally.Counter
+	CronRunSkip    tally.Counter
+
+	JobStopSuccess tally.Counter
+	JobStopFail    tally.Counter
+
+	JobDeleteSuccess tally.Counter
+	JobDeleteFail    tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		SchedulingRunSuccess: successScope.Counter("scheduling_run"),
+		SchedulingRunFail:    failScope.Counter("scheduling_run"),
+
+		CronRunSuccess: successScope.Counter("cron_run"),
+		CronRunFail:    failScope.Counter("cron_run"),
+		CronRunSkip:    scope.Counter("cron_run_skip"),
+
+		JobStopSuccess: successScope.Counter("job_stop"),
+		JobStopFail:    failScope.Counter("job_stop"),
+
+		JobDeleteSuccess: successScope.Counter("job_delete"),
+		JobDeleteFail:    failScope.Counter("job_delete"),
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// _maxSearchYears bounds the search for the next activation of a schedule,
+// so that schedules which never fire (e.g. February 30) terminate.
+const _maxSearchYears = 5
+
+// field describes the allowed values of a field of a cron schedule.
+type field struct {
+	name  string
+	min   uint
+	max   uint
+	names map[string]uint
+}
+
+var (
+	_minuteField = field{name: "minute", min: 0, max: 59}
+	_hourField   = field{name: "hour", min: 0, max: 23}
+	_domField    = field{name: "day of month", min: 1, max: 31}
+	_monthField  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// day of week accepts 7 as an alias of Sunday
+	_dowField = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// _descriptors maps the supported schedule descriptors to their
+// five field equivalent.
+var _descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron schedule. Each field is a bit set of the
+// values at which the schedule fires. Schedules are evaluated in UTC.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar are set if the corresponding field starts
+	// with *, e.g. * or */2, following Vixie cron. If both day fields
+	// are restricted, a day matches if either of them matches.
+	domStar bool
+	dowStar bool
+}
+
+// ParseSchedule parses a schedule in the standard five field cron
+// format (minute hour day-of-month month day-of-week), or one of the
+// descriptors @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight and @hourly.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := _descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"expected 5 fields in schedule %q, found %d", spec, len(fields))
+	}
+
+	s := &Schedule{
+		domStar: strings.HasPrefix(fields[2], "*") || fields[2] == "?",
+		dowStar: strings.HasPrefix(fields[4], "*") || fields[4] == "?",
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], _minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], _hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], _domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], _monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], _dowField); err != nil {
+		return nil, err
+	}
+
+	// fold Sunday as 7 into Sunday as 0
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	return s, nil
+}
+
+// parseField parses a comma separated list of values, ranges and
+// stepped ranges of a field into a bit set.
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, uint(1)
+		hasStep := false
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangeExpr = part[:i]
+			s, err := strconv.ParseUint(part[i+1:], 10, 32)
+			if err != nil || s == 0 {
+				return 0, fmt.Errorf(
+					"invalid step %q in %s field", part[i+1:], f.name)
+			}
+			step = uint(s)
+			hasStep = true
+		}
+
+		var start, end uint
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			start, end = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = parseValue(rangeExpr, f); err != nil {
+				return 0, err
+			}
+			end = start
+			// "a/n" means every n starting at a
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf(
+				"invalid range %q in %s field", rangeExpr, f.name)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a single numeric or named value of a field.
+func parseValue(expr string, f field) (uint, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(expr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", expr, f.name)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, fmt.Errorf(
+			"value %d out of range [%d, %d] in %s field",
+			v, f.min, f.max, f.name)
+	}
+	return uint(v), nil
+}
+
+// Next returns the first activation of the schedule strictly after t,
+// or the zero time if the schedule does not fire in the next years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + _maxSearchYears
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of t matches the day of month
+// and day of week fields of the schedule.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type scheduleTestSuite struct {
+	suite.Suite
+}
+
+func TestSchedule(t *testing.T) {
+	suite.Run(t, new(scheduleTestSuite))
+}
+
+func (suite *scheduleTestSuite) parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	suite.NoError(err)
+	return t
+}
+
+// TestNext tests computing the next activation of valid schedules
+func (suite *scheduleTestSuite) TestNext() {
+	tests := []struct {
+		spec     string
+		from     string
+		expected string
+	}{
+		{"* * * * *", "2019-01-01T00:00:00Z", "2019-01-01T00:01:00Z"},
+		{"* * * * *", "2019-01-01T00:00:30Z", "2019-01-01T00:01:00Z"},
+		{"*/15 * * * *", "2019-01-01T00:07:00Z", "2019-01-01T00:15:00Z"},
+		{"*/15 * * * *", "2019-01-01T00:45:00Z", "2019-01-01T01:00:00Z"},
+		{"5/20 * * * *", "2019-01-01T00:06:00Z", "2019-01-01T00:25:00Z"},
+		{"0 9-17/4 * * *", "2019-01-01T10:00:00Z", "2019-01-01T13:00:00Z"},
+		{"30 2 * * *", "2019-01-01T03:00:00Z", "2019-01-02T02:30:00Z"},
+		{"0 0 1 * *", "2019-01-31T12:00:00Z", "2019-02-01T00:00:00Z"},
+		{"0 0 31 * *", "2019-02-01T00:00:00Z", "2019-03-31T00:00:00Z"},
+		{"0 0 29 2 *", "2019-01-01T00:00:00Z", "2020-02-29T00:00:00Z"},
+		{"0 0 * * mon", "2019-01-01T00:00:00Z", "2019-01-07T00:00:00Z"},
+		{"0 0 * * 7", "2019-01-01T00:00:00Z", "2019-01-06T00:00:00Z"},
+		{"0 0 * jan-mar sat,sun", "2019-03-31T00:00:00Z", "2020-01-04T00:00:00Z"},
+		// both day fields restricted match either of them
+		{"0 0 15 * fri", "2019-01-01T00:00:00Z", "2019-01-04T00:00:00Z"},
+		{"0 0 15 * fri", "2019-01-12T00:00:00Z", "2019-01-15T00:00:00Z"},
+		// a day field with a step from * is not restricted, so
+		// both day fields must match
+		{"0 0 */2 * 1", "2019-01-01T00:00:00Z", "2019-01-07T00:00:00Z"},
+		{"0 0 1 * */2", "2019-01-02T00:00:00Z", "2019-06-01T00:00:00Z"},
+		{"@hourly", "2019-01-01T00:30:00Z", "2019-01-01T01:00:00Z"},
+		{"@daily", "2019-01-01T00:30:00Z", "2019-01-02T00:00:00Z"},
+		{"@weekly", "2019-01-01T00:30:00Z", "2019-01-06T00:00:00Z"},
+		{"@monthly", "2019-01-01T00:30:00Z", "2019-02-01T00:00:00Z"},
+		{"@yearly", "2019-01-01T00:30:00Z", "2020-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec)
+		suite.NoError(err, tt.spec)
+		suite.Equal(
+			suite.parseTime(tt.expected),
+			s.Next(suite.parseTime(tt.from)),
+			tt.spec)
+	}
+}
+
+// TestNextNeverFires tests that a schedule which never fires
+// returns the zero time
+func (suite *scheduleTestSuite) TestNextNeverFires() {
+	s, err := ParseSchedule("0 0 30 2 *")
+	suite.NoError(err)
+	suite.True(s.Next(suite.parseTime("2019-01-01T00:00:00Z")).IsZero())
+}
+
+// TestParseInvalid tests parsing invalid schedules
+func (suite *scheduleTestSuite) TestParseInvalid() {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every5m",
+	} {
+		_, err := ParseSchedule(spec)
+		suite.Error(err, spec)
+	}
+}
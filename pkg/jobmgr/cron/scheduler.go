@@ -0,0 +1,592 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_cronSchedulerName = "cronScheduler"
+
+	// LabelKey is the key of the label added to the jobs created by a
+	// cron job, its value is the name of the cron job.
+	LabelKey = "cron_job"
+
+	// _jobNameTimeFormat is the format of the schedule time appended to
+	// the name of the jobs created by a cron job
+	_jobNameTimeFormat = "20060102-1504"
+
+	_timeoutFunctionCall = 60 * time.Second
+
+	// _createJobProcedure is the procedure the owner of a cron job must
+	// be permitted to call on its template for the cron job to run
+	_createJobProcedure = "peloton.api.v0.job.JobManager::Create"
+)
+
+// Scheduler defines the interface of the cron scheduler which creates
+// batch jobs from the templates of cron jobs according to their
+// schedule, applies their concurrency policy and deletes the finished
+// jobs exceeding their history limit. All the changes to cron jobs go
+// through the scheduler so that they are serialized with its runs.
+type Scheduler interface {
+	// Register registers the scheduler as a background work, so that it
+	// only runs on the leader.
+	Register(manager background.Manager) error
+
+	// Schedule runs the cron jobs which are due and reconciles the jobs
+	// created by all the cron jobs.
+	Schedule()
+
+	// Create creates a cron job.
+	Create(ctx context.Context, config *pbcron.CronJobConfig) error
+
+	// Replace replaces the config of a cron job.
+	Replace(ctx context.Context, config *pbcron.CronJobConfig) error
+
+	// Delete deletes a cron job. The jobs created by the cron job
+	// are not affected.
+	Delete(ctx context.Context, name string) error
+
+	// Run runs a cron job immediately. It returns the ID of the created
+	// job, or nil if the run was skipped by the concurrency policy.
+	Run(ctx context.Context, name string) (*peloton.JobID, error)
+}
+
+// scheduler implements the Scheduler interface
+type scheduler struct {
+	sync.Mutex
+
+	cronJobOps      ormobjects.CronJobOps
+	jobRuntimeOps   ormobjects.JobRuntimeOps
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	jobManager      job.JobManagerYARPCServer
+	respoolClient   respool.ResourceManagerYARPCClient
+	users           auth.UserLookup
+	config          *Config
+	metrics         *Metrics
+	now             func() time.Time
+}
+
+// New creates a cron scheduler. The jobs of the cron jobs are created
+// and deleted through jobManager, so that they are validated like the
+// jobs created through the API. The runs are authorized for the owner of
+// the cron job looked up in users, which is nil if the security manager
+// cannot look up users, e.g. when auth is disabled.
+func New(
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	jobManager job.JobManagerYARPCServer,
+	respoolClient respool.ResourceManagerYARPCClient,
+	users auth.UserLookup,
+	parent tally.Scope,
+	config *Config,
+) Scheduler {
+	config.normalize()
+
+	return &scheduler{
+		cronJobOps:      ormobjects.NewCronJobOps(ormStore),
+		jobRuntimeOps:   ormobjects.NewJobRuntimeOps(ormStore),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		jobManager:      jobManager,
+		respoolClient:   respoolClient,
+		users:           users,
+		config:          config,
+		metrics:         NewMetrics(parent.SubScope("jobmgr").SubScope("cron")),
+		now:             time.Now,
+	}
+}
+
+// ValidateConfig validates the config of a cron job.
+func ValidateConfig(config *pbcron.CronJobConfig) error {
+	if len(config.GetName()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("cron job name is empty")
+	}
+
+	if _, err := ParseSchedule(config.GetSchedule()); err != nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"invalid cron schedule: %v", err)
+	}
+
+	if config.GetTemplate() == nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"cron job template is not set")
+	}
+
+	if config.GetTemplate().GetType() != job.JobType_BATCH {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"cron job template must be a batch job")
+	}
+
+	return nil
+}
+
+// Register registers the scheduler as a background work.
+func (s *scheduler) Register(manager background.Manager) error {
+	return manager.RegisterWorks(
+		background.Work{
+			Name: _cronSchedulerName,
+			Func: func(_ *atomic.Bool) {
+				s.Schedule()
+			},
+			Period: s.config.SchedulingPeriod,
+		},
+	)
+}
+
+// Schedule runs the cron jobs which are due.
+func (s *scheduler) Schedule() {
+	s.Lock()
+	defer s.Unlock()
+
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_timeoutFunctionCall)
+	defer cancelFunc()
+
+	infos, err := s.cronJobOps.GetAll(ctx)
+	if err != nil {
+		s.metrics.SchedulingRunFail.Inc(1)
+		log.WithError(err).Error("failed to get cron jobs")
+		return
+	}
+
+	now := s.now()
+	for _, info := range infos {
+		if err := s.schedule(ctx, info, now); err != nil {
+			log.WithError(err).
+				WithField("cron_job", info.GetConfig().GetName()).
+				Error("failed to schedule cron job")
+		}
+	}
+	s.metrics.SchedulingRunSuccess.Inc(1)
+}
+
+// schedule reconciles the jobs of a cron job and runs it if it is due.
+func (s *scheduler) schedule(
+	ctx context.Context,
+	info *pbcron.CronJobInfo,
+	now time.Time,
+) error {
+	config := info.GetConfig()
+	runtime := proto.Clone(info.GetRuntime()).(*pbcron.CronJobRuntime)
+
+	s.reconcile(ctx, config, runtime)
+
+	if !config.GetPaused() {
+		schedule, err := ParseSchedule(config.GetSchedule())
+		if err != nil {
+			return err
+		}
+
+		next, err := time.Parse(time.RFC3339, runtime.GetNextScheduleTime())
+		switch {
+		case err != nil:
+			// the next schedule time is not set if the schedule
+			// did not fire in the next years when it was computed
+			runtime.NextScheduleTime = formatTime(schedule.Next(now))
+		case !now.Before(next):
+			// runs missed while there was no leader are coalesced
+			// into a single run
+			s.run(ctx, config, runtime, next)
+			runtime.NextScheduleTime = formatTime(schedule.Next(now))
+		}
+	}
+
+	if proto.Equal(runtime, info.GetRuntime()) {
+		return nil
+	}
+	return s.cronJobOps.UpdateRuntime(ctx, config.GetName(), runtime)
+}
+
+// Create creates a cron job owned by the user in ctx.
+func (s *scheduler) Create(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+) error {
+	if err := ValidateConfig(config); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	runtime := &pbcron.CronJobRuntime{Owner: ownerFromContext(ctx)}
+	if err := s.setNextScheduleTime(config, runtime); err != nil {
+		return err
+	}
+	return s.cronJobOps.Create(ctx, config, runtime)
+}
+
+// Replace replaces the config of a cron job. The next schedule time is
+// computed again from the new schedule, and the user in ctx becomes the
+// owner of the cron job.
+func (s *scheduler) Replace(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+) error {
+	if err := ValidateConfig(config); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	info, err := s.cronJobOps.Get(ctx, config.GetName())
+	if err != nil {
+		return err
+	}
+
+	runtime := info.GetRuntime()
+	runtime.Owner = ownerFromContext(ctx)
+	if err := s.setNextScheduleTime(config, runtime); err != nil {
+		return err
+	}
+
+	if err := s.cronJobOps.UpdateConfig(ctx, config); err != nil {
+		return err
+	}
+	return s.cronJobOps.UpdateRuntime(ctx, config.GetName(), runtime)
+}
+
+// Delete deletes a cron job.
+func (s *scheduler) Delete(ctx context.Context, name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, err := s.cronJobOps.Get(ctx, name); err != nil {
+		return err
+	}
+	return s.cronJobOps.Delete(ctx, name)
+}
+
+// Run runs a cron job immediately.
+func (s *scheduler) Run(
+	ctx context.Context,
+	name string,
+) (*peloton.JobID, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	info, err := s.cronJobOps.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := info.GetRuntime()
+	s.reconcile(ctx, info.GetConfig(), runtime)
+	jobID, runErr := s.run(ctx, info.GetConfig(), runtime, s.now())
+
+	// persist the runtime even if the run failed, so that the
+	// error is visible in the cron job
+	if err := s.cronJobOps.UpdateRuntime(ctx, name, runtime); err != nil {
+		return nil, err
+	}
+	return jobID, runErr
+}
+
+// setNextScheduleTime sets the next schedule time of a cron job
+// from the current time.
+func (s *scheduler) setNextScheduleTime(
+	config *pbcron.CronJobConfig,
+	runtime *pbcron.CronJobRuntime,
+) error {
+	schedule, err := ParseSchedule(config.GetSchedule())
+	if err != nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"invalid cron schedule: %v", err)
+	}
+	runtime.NextScheduleTime = formatTime(schedule.Next(s.now()))
+	return nil
+}
+
+// run applies the concurrency policy of a cron job and creates a job
+// from its template. The runtime of the cron job is updated in place.
+func (s *scheduler) run(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+	runtime *pbcron.CronJobRuntime,
+	scheduleTime time.Time,
+) (*peloton.JobID, error) {
+	runtime.LastScheduleTime = formatTime(scheduleTime)
+
+	// the jobs are created in the background without the user, so
+	// the owner must still have access to the template at each run
+	if err := s.checkOwnerAccess(ctx, config, runtime.GetOwner()); err != nil {
+		s.metrics.CronRunFail.Inc(1)
+		runtime.Message = fmt.Sprintf("owner not permitted: %v", err)
+		return nil, err
+	}
+
+	if len(runtime.GetActiveJobs()) > 0 {
+		switch config.GetConcurrencyPolicy() {
+		case pbcron.ConcurrencyPolicy_SKIP:
+			log.WithField("cron_job", config.GetName()).
+				WithField("active_jobs", runtime.GetActiveJobs()).
+				Info("skip cron job run since previous runs are active")
+			runtime.SkipCount++
+			s.metrics.CronRunSkip.Inc(1)
+			return nil, nil
+
+		case pbcron.ConcurrencyPolicy_REPLACE:
+			for _, jobID := range runtime.GetActiveJobs() {
+				if err := s.stopJob(ctx, jobID); err != nil {
+					s.metrics.JobStopFail.Inc(1)
+					s.metrics.CronRunFail.Inc(1)
+					runtime.Message = fmt.Sprintf(
+						"failed to stop job %s: %v", jobID.GetValue(), err)
+					return nil, errors.Wrapf(
+						err, "failed to stop job %s", jobID.GetValue())
+				}
+				s.metrics.JobStopSuccess.Inc(1)
+			}
+		}
+	}
+
+	jobID, err := s.createJob(ctx, config, scheduleTime)
+	if err != nil {
+		s.metrics.CronRunFail.Inc(1)
+		runtime.Message = fmt.Sprintf("failed to create job: %v", err)
+		return nil, err
+	}
+
+	log.WithField("cron_job", config.GetName()).
+		WithField("job_id", jobID.GetValue()).
+		Info("cron job run created job")
+
+	runtime.ActiveJobs = append(runtime.ActiveJobs, jobID)
+	runtime.RunCount++
+	runtime.Message = ""
+	s.metrics.CronRunSuccess.Inc(1)
+	return jobID, nil
+}
+
+// checkOwnerAccess returns a permission denied error if the owner of a
+// cron job is not permitted to create jobs from its template any more.
+func (s *scheduler) checkOwnerAccess(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+	owner string,
+) error {
+	if s.users == nil {
+		return nil
+	}
+
+	user, err := s.users.LookupUser(owner)
+	if err != nil {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"owner %q of cron job not found: %v", owner, err)
+	}
+
+	if !user.IsPermitted(_createJobProcedure) {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"owner %q of cron job not permitted to call %s",
+			owner, _createJobProcedure)
+	}
+
+	if _, ok := user.(auth.ResourceUser); !ok {
+		return nil
+	}
+
+	respoolPath, err := handlerutil.GetResourcePoolPath(
+		ctx,
+		s.respoolClient,
+		config.GetTemplate().GetRespoolID(),
+	)
+	if err != nil {
+		return err
+	}
+
+	resource := &auth.Resource{
+		ResourcePoolPath: respoolPath,
+		Owner:            config.GetTemplate().GetOwningTeam(),
+	}
+	if !auth.IsPermittedOnResource(
+		auth.ContextWithUser(ctx, user), _createJobProcedure, resource) {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"owner %q of cron job not permitted on resource pool %s owned by %s",
+			owner, resource.ResourcePoolPath, resource.Owner)
+	}
+	return nil
+}
+
+// createJob creates a batch job from the template of a cron job.
+func (s *scheduler) createJob(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+	scheduleTime time.Time,
+) (*peloton.JobID, error) {
+	jobConfig := proto.Clone(config.GetTemplate()).(*job.JobConfig)
+	jobConfig.Type = job.JobType_BATCH
+	jobConfig.Name = fmt.Sprintf("%s-%s",
+		config.GetName(),
+		scheduleTime.UTC().Format(_jobNameTimeFormat))
+	jobConfig.Labels = append(jobConfig.Labels, &peloton.Label{
+		Key:   LabelKey,
+		Value: config.GetName(),
+	})
+
+	resp, err := s.jobManager.Create(ctx, &job.CreateRequest{
+		Id:     &peloton.JobID{Value: uuid.New()},
+		Config: jobConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != nil {
+		return nil, errors.New(resp.GetError().String())
+	}
+	return resp.GetJobId(), nil
+}
+
+// stopJob sets the goal state of a job to KILLED.
+func (s *scheduler) stopJob(ctx context.Context, jobID *peloton.JobID) error {
+	var count int
+	cachedJob := s.jobFactory.AddJob(jobID)
+	for {
+		jobRuntime, err := cachedJob.GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+
+		if jobRuntime.GetGoalState() == job.JobState_KILLED ||
+			util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+			return nil
+		}
+
+		jobRuntime.DesiredStateVersion++
+		jobRuntime.GoalState = job.JobState_KILLED
+
+		_, err = cachedJob.CompareAndSetRuntime(ctx, jobRuntime)
+		if err == nil {
+			break
+		}
+		if err == jobmgrcommon.UnexpectedVersionError {
+			// concurrency error; retry MaxConcurrencyErrorRetry times
+			count++
+			if count < jobmgrcommon.MaxConcurrencyErrorRetry {
+				continue
+			}
+		}
+		return err
+	}
+
+	s.goalStateDriver.EnqueueJob(jobID, time.Now())
+	return nil
+}
+
+// reconcile moves the jobs of a cron job which reached a terminal state
+// from the active jobs to the recent jobs, and deletes the oldest
+// recent jobs exceeding the history limit.
+func (s *scheduler) reconcile(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+	runtime *pbcron.CronJobRuntime,
+) {
+	var activeJobs []*peloton.JobID
+	for _, jobID := range runtime.GetActiveJobs() {
+		jobRuntime, err := handlerutil.GetJobRuntimeWithoutFillingCache(
+			ctx, jobID, s.jobFactory, s.jobRuntimeOps)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				// the job was deleted out of band
+				continue
+			}
+			log.WithError(err).
+				WithField("cron_job", config.GetName()).
+				WithField("job_id", jobID.GetValue()).
+				Warn("failed to get job runtime")
+			activeJobs = append(activeJobs, jobID)
+			continue
+		}
+
+		if util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+			runtime.RecentJobs = append(runtime.RecentJobs, jobID)
+			continue
+		}
+		activeJobs = append(activeJobs, jobID)
+	}
+	runtime.ActiveJobs = activeJobs
+
+	historyLimit := config.GetHistoryLimit()
+	if historyLimit == 0 {
+		historyLimit = s.config.DefaultHistoryLimit
+	}
+
+	for uint32(len(runtime.GetRecentJobs())) > historyLimit {
+		jobID := runtime.GetRecentJobs()[0]
+		_, err := s.jobManager.Delete(ctx, &job.DeleteRequest{Id: jobID})
+		if err != nil && !yarpcerrors.IsNotFound(err) {
+			s.metrics.JobDeleteFail.Inc(1)
+			log.WithError(err).
+				WithField("cron_job", config.GetName()).
+				WithField("job_id", jobID.GetValue()).
+				Warn("failed to delete finished job of cron job")
+			break
+		}
+		s.metrics.JobDeleteSuccess.Inc(1)
+		runtime.RecentJobs = runtime.GetRecentJobs()[1:]
+	}
+}
+
+// ownerFromContext returns the name of the user in ctx, which is empty
+// for the default user or if there is no user.
+func ownerFromContext(ctx context.Context) string {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if named, ok := user.(auth.NamedUser); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// formatTime formats a schedule time, the zero time is formatted as
+// an empty string.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,548 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	jobmocks "github.com/uber/peloton/.gen/peloton/api/v0/job/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+	"github.com/uber/peloton/pkg/common/background"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type schedulerTestSuite struct {
+	suite.Suite
+
+	mockCtrl        *gomock.Controller
+	cronJobOps      *objectmocks.MockCronJobOps
+	jobRuntimeOps   *objectmocks.MockJobRuntimeOps
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *goalstatemocks.MockDriver
+	jobManager      *jobmocks.MockJobManagerYARPCServer
+	respoolClient   *respoolmocks.MockResourceManagerYARPCClient
+	users           *auth_mocks.MockUserLookup
+	scheduler       *scheduler
+
+	now    time.Time
+	config *pbcron.CronJobConfig
+}
+
+func (suite *schedulerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.mockCtrl)
+	suite.jobRuntimeOps = objectmocks.NewMockJobRuntimeOps(suite.mockCtrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.mockCtrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.mockCtrl)
+	suite.jobManager = jobmocks.NewMockJobManagerYARPCServer(suite.mockCtrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.mockCtrl)
+	suite.users = auth_mocks.NewMockUserLookup(suite.mockCtrl)
+
+	suite.now = time.Date(2019, 1, 1, 0, 10, 0, 0, time.UTC)
+	suite.scheduler = &scheduler{
+		cronJobOps:      suite.cronJobOps,
+		jobRuntimeOps:   suite.jobRuntimeOps,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		jobManager:      suite.jobManager,
+		respoolClient:   suite.respoolClient,
+		config: &Config{
+			SchedulingPeriod:    time.Minute,
+			DefaultHistoryLimit: 2,
+		},
+		metrics: NewMetrics(tally.NoopScope),
+		now: func() time.Time {
+			return suite.now
+		},
+	}
+
+	suite.config = &pbcron.CronJobConfig{
+		Name:              "cron",
+		Schedule:          "*/5 * * * *",
+		ConcurrencyPolicy: pbcron.ConcurrencyPolicy_SKIP,
+		Template: &job.JobConfig{
+			Name:          "template",
+			Type:          job.JobType_BATCH,
+			InstanceCount: 1,
+		},
+	}
+}
+
+func (suite *schedulerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestScheduler(t *testing.T) {
+	suite.Run(t, new(schedulerTestSuite))
+}
+
+// expectJobState sets up the runtime returned for an uncached job
+func (suite *schedulerTestSuite) expectJobState(
+	jobID *peloton.JobID,
+	state job.JobState,
+) {
+	suite.jobFactory.EXPECT().GetJob(jobID).Return(nil)
+	suite.jobRuntimeOps.EXPECT().Get(gomock.Any(), jobID).
+		Return(&job.RuntimeInfo{State: state}, nil)
+}
+
+// expectCreate sets up job manager to create a job and returns its ID
+func (suite *schedulerTestSuite) expectCreate() *peloton.JobID {
+	jobID := &peloton.JobID{Value: "created"}
+	suite.jobManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			req *job.CreateRequest,
+		) (*job.CreateResponse, error) {
+			suite.NotEmpty(req.GetId().GetValue())
+			suite.Equal(job.JobType_BATCH, req.GetConfig().GetType())
+			suite.Equal("cron-20190101-0010", req.GetConfig().GetName())
+			suite.Equal(&peloton.Label{Key: LabelKey, Value: "cron"},
+				req.GetConfig().GetLabels()[0])
+			return &job.CreateResponse{JobId: jobID}, nil
+		})
+	return jobID
+}
+
+// TestScheduleDue tests that a due cron job creates a job
+func (suite *schedulerTestSuite) TestScheduleDue() {
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	jobID := suite.expectCreate()
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Equal("2019-01-01T00:10:00Z", r.GetLastScheduleTime())
+			suite.Equal("2019-01-01T00:15:00Z", r.GetNextScheduleTime())
+			suite.Equal([]*peloton.JobID{jobID}, r.GetActiveJobs())
+			suite.Equal(uint64(1), r.GetRunCount())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleNotDue tests that a cron job which is not due
+// is not updated
+func (suite *schedulerTestSuite) TestScheduleNotDue() {
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:15:00Z",
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+
+	suite.scheduler.Schedule()
+}
+
+// TestSchedulePaused tests that a paused cron job does not run
+func (suite *schedulerTestSuite) TestSchedulePaused() {
+	suite.config.Paused = true
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:05:00Z",
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleGetAllFailure tests failing to read the cron jobs
+func (suite *schedulerTestSuite) TestScheduleGetAllFailure() {
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleSkip tests that a run is skipped when a previous run
+// is active and the concurrency policy is SKIP
+func (suite *schedulerTestSuite) TestScheduleSkip() {
+	activeJob := &peloton.JobID{Value: "active"}
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+		ActiveJobs:       []*peloton.JobID{activeJob},
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	suite.expectJobState(activeJob, job.JobState_RUNNING)
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Equal(uint64(1), r.GetSkipCount())
+			suite.Equal(uint64(0), r.GetRunCount())
+			suite.Equal([]*peloton.JobID{activeJob}, r.GetActiveJobs())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleReplace tests that the active runs are stopped when the
+// concurrency policy is REPLACE
+func (suite *schedulerTestSuite) TestScheduleReplace() {
+	suite.config.ConcurrencyPolicy = pbcron.ConcurrencyPolicy_REPLACE
+	activeJob := &peloton.JobID{Value: "active"}
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+		ActiveJobs:       []*peloton.JobID{activeJob},
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	suite.expectJobState(activeJob, job.JobState_RUNNING)
+
+	cachedJob := cachedmocks.NewMockJob(suite.mockCtrl)
+	suite.jobFactory.EXPECT().AddJob(activeJob).Return(cachedJob)
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(&job.RuntimeInfo{
+		State:     job.JobState_RUNNING,
+		GoalState: job.JobState_SUCCEEDED,
+	}, nil)
+	cachedJob.EXPECT().CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			r *job.RuntimeInfo,
+		) (*job.RuntimeInfo, error) {
+			suite.Equal(job.JobState_KILLED, r.GetGoalState())
+			suite.Equal(uint64(1), r.GetDesiredStateVersion())
+			return r, nil
+		})
+	suite.goalStateDriver.EXPECT().EnqueueJob(activeJob, gomock.Any())
+
+	jobID := suite.expectCreate()
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Equal([]*peloton.JobID{activeJob, jobID}, r.GetActiveJobs())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleCreateFailure tests that a failed run is recorded
+// in the cron job runtime
+func (suite *schedulerTestSuite) TestScheduleCreateFailure() {
+	suite.config.ConcurrencyPolicy = pbcron.ConcurrencyPolicy_ALLOW
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	suite.jobManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Contains(r.GetMessage(), "test error")
+			suite.Equal("2019-01-01T00:15:00Z", r.GetNextScheduleTime())
+			suite.Empty(r.GetActiveJobs())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// expectOwner sets up the lookup of the owner of the cron job and
+// whether it is permitted on the resource pool of the template
+func (suite *schedulerTestSuite) expectOwner(permitted bool) {
+	suite.scheduler.users = suite.users
+	suite.config.Template.RespoolID = &peloton.ResourcePoolID{Value: "respool"}
+	suite.config.Template.OwningTeam = "team"
+
+	user := auth_mocks.NewMockResourceUser(suite.mockCtrl)
+	suite.users.EXPECT().LookupUser("alice").Return(user, nil)
+	user.EXPECT().IsPermitted(_createJobProcedure).Return(true)
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: "respool"},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Path: &respool.ResourcePoolPath{Value: "/infra/team"},
+			},
+		}, nil)
+	user.EXPECT().
+		IsPermittedOnResource(_createJobProcedure, &auth.Resource{
+			ResourcePoolPath: "/infra/team",
+			Owner:            "team",
+		}).
+		Return(permitted)
+}
+
+// TestScheduleOwnerPermitted tests that a cron job runs while its
+// owner has access to its template
+func (suite *schedulerTestSuite) TestScheduleOwnerPermitted() {
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+		Owner:            "alice",
+	}
+	suite.expectOwner(true)
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	jobID := suite.expectCreate()
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Equal([]*peloton.JobID{jobID}, r.GetActiveJobs())
+			suite.Equal("alice", r.GetOwner())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestScheduleOwnerNotPermitted tests that a cron job does not create
+// jobs after its owner loses access to its template
+func (suite *schedulerTestSuite) TestScheduleOwnerNotPermitted() {
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:10:00Z",
+		Owner:            "alice",
+	}
+	suite.expectOwner(false)
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Contains(r.GetMessage(), "not permitted")
+			suite.Equal("2019-01-01T00:15:00Z", r.GetNextScheduleTime())
+			suite.Empty(r.GetActiveJobs())
+			suite.Zero(r.GetRunCount())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestRunOwnerNotFound tests that a cron job whose owner no longer
+// exists does not create jobs
+func (suite *schedulerTestSuite) TestRunOwnerNotFound() {
+	suite.scheduler.users = suite.users
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{
+			Config: suite.config,
+			Runtime: &pbcron.CronJobRuntime{
+				NextScheduleTime: "2019-01-01T00:15:00Z",
+				Owner:            "bob",
+			},
+		}, nil)
+	suite.users.EXPECT().LookupUser("bob").
+		Return(nil, yarpcerrors.NotFoundErrorf("test error"))
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		Return(nil)
+
+	_, err := suite.scheduler.Run(context.Background(), "cron")
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestScheduleHistoryLimit tests that finished jobs are moved to the
+// recent jobs and the oldest ones are deleted
+func (suite *schedulerTestSuite) TestScheduleHistoryLimit() {
+	finishedJob := &peloton.JobID{Value: "finished"}
+	deletedJob := &peloton.JobID{Value: "deleted"}
+	oldJobs := []*peloton.JobID{{Value: "old1"}, {Value: "old2"}}
+	runtime := &pbcron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:15:00Z",
+		ActiveJobs:       []*peloton.JobID{finishedJob, deletedJob},
+		RecentJobs:       oldJobs,
+	}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{{Config: suite.config, Runtime: runtime}}, nil)
+	suite.expectJobState(finishedJob, job.JobState_SUCCEEDED)
+	suite.jobFactory.EXPECT().GetJob(deletedJob).Return(nil)
+	suite.jobRuntimeOps.EXPECT().Get(gomock.Any(), deletedJob).
+		Return(nil, yarpcerrors.NotFoundErrorf("test error"))
+	suite.jobManager.EXPECT().
+		Delete(gomock.Any(), &job.DeleteRequest{Id: oldJobs[0]}).
+		Return(&job.DeleteResponse{}, nil)
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ string,
+			r *pbcron.CronJobRuntime,
+		) error {
+			suite.Empty(r.GetActiveJobs())
+			suite.Equal(
+				[]*peloton.JobID{oldJobs[1], finishedJob},
+				r.GetRecentJobs())
+			return nil
+		})
+
+	suite.scheduler.Schedule()
+}
+
+// TestCreate tests creating a cron job
+func (suite *schedulerTestSuite) TestCreate() {
+	suite.cronJobOps.EXPECT().
+		Create(gomock.Any(), suite.config, &pbcron.CronJobRuntime{
+			NextScheduleTime: "2019-01-01T00:15:00Z",
+		}).
+		Return(nil)
+	suite.NoError(suite.scheduler.Create(context.Background(), suite.config))
+}
+
+// TestCreateOwner tests that the user creating a cron job becomes
+// its owner
+func (suite *schedulerTestSuite) TestCreateOwner() {
+	user := auth_mocks.NewMockNamedUser(suite.mockCtrl)
+	user.EXPECT().Name().Return("alice")
+	ctx := auth.ContextWithUser(context.Background(), user)
+
+	suite.cronJobOps.EXPECT().
+		Create(gomock.Any(), suite.config, &pbcron.CronJobRuntime{
+			NextScheduleTime: "2019-01-01T00:15:00Z",
+			Owner:            "alice",
+		}).
+		Return(nil)
+	suite.NoError(suite.scheduler.Create(ctx, suite.config))
+}
+
+// TestCreateInvalid tests creating invalid cron jobs
+func (suite *schedulerTestSuite) TestCreateInvalid() {
+	suite.config.Template.Type = job.JobType_SERVICE
+	err := suite.scheduler.Create(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	suite.config.Template = nil
+	err = suite.scheduler.Create(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	suite.config.Schedule = "* * *"
+	err = suite.scheduler.Create(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	suite.config.Name = ""
+	err = suite.scheduler.Create(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestReplace tests replacing the config of a cron job
+func (suite *schedulerTestSuite) TestReplace() {
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{
+			Config: suite.config,
+			Runtime: &pbcron.CronJobRuntime{
+				NextScheduleTime: "2019-01-01T00:15:00Z",
+				RunCount:         3,
+			},
+		}, nil)
+
+	newConfig := *suite.config
+	newConfig.Schedule = "@hourly"
+	suite.cronJobOps.EXPECT().UpdateConfig(gomock.Any(), &newConfig).
+		Return(nil)
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", &pbcron.CronJobRuntime{
+			NextScheduleTime: "2019-01-01T01:00:00Z",
+			RunCount:         3,
+		}).
+		Return(nil)
+
+	suite.NoError(suite.scheduler.Replace(context.Background(), &newConfig))
+}
+
+// TestReplaceNotFound tests replacing a cron job which does not exist
+func (suite *schedulerTestSuite) TestReplaceNotFound() {
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(nil, yarpcerrors.NotFoundErrorf("test error"))
+
+	err := suite.scheduler.Replace(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestDelete tests deleting a cron job
+func (suite *schedulerTestSuite) TestDelete() {
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{Config: suite.config}, nil)
+	suite.cronJobOps.EXPECT().Delete(gomock.Any(), "cron").Return(nil)
+
+	suite.NoError(suite.scheduler.Delete(context.Background(), "cron"))
+}
+
+// TestRun tests running a cron job immediately
+func (suite *schedulerTestSuite) TestRun() {
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{
+			Config: suite.config,
+			Runtime: &pbcron.CronJobRuntime{
+				NextScheduleTime: "2019-01-01T00:15:00Z",
+			},
+		}, nil)
+	jobID := suite.expectCreate()
+	suite.cronJobOps.EXPECT().
+		UpdateRuntime(gomock.Any(), "cron", &pbcron.CronJobRuntime{
+			LastScheduleTime: "2019-01-01T00:10:00Z",
+			NextScheduleTime: "2019-01-01T00:15:00Z",
+			ActiveJobs:       []*peloton.JobID{jobID},
+			RunCount:         1,
+		}).
+		Return(nil)
+
+	result, err := suite.scheduler.Run(context.Background(), "cron")
+	suite.NoError(err)
+	suite.Equal(jobID, result)
+}
+
+// TestRegister tests registering the scheduler as a background work
+func (suite *schedulerTestSuite) TestRegister() {
+	manager := background.NewManager()
+	suite.NoError(suite.scheduler.Register(manager))
+	suite.Error(suite.scheduler.Register(manager))
+}
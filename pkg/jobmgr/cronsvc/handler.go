@@ -0,0 +1,306 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"context"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	pbcronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	v1alphacronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/jobmgr/cron"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// InitServiceHandler initializes the v0 and v1alpha cron job services.
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+	scheduler cron.Scheduler,
+	candidate leader.Candidate,
+	clientName string,
+) {
+	handler := &serviceHandler{
+		cronJobOps:    ormobjects.NewCronJobOps(ormStore),
+		respoolClient: respool.NewResourceManagerYARPCClient(d.ClientConfig(clientName)),
+		scheduler:     scheduler,
+		candidate:     candidate,
+		metrics:       NewMetrics(parent.SubScope("jobmgr").SubScope("cron")),
+	}
+
+	d.Register(pbcronsvc.BuildCronServiceYARPCProcedures(handler))
+	d.Register(v1alphacronsvc.BuildCronJobServiceYARPCProcedures(
+		&v1AlphaServiceHandler{handler: handler}))
+}
+
+// serviceHandler implements peloton.api.v0.cron.svc.CronService
+type serviceHandler struct {
+	cronJobOps    ormobjects.CronJobOps
+	respoolClient respool.ResourceManagerYARPCClient
+	scheduler     cron.Scheduler
+	candidate     leader.Candidate
+	metrics       *Metrics
+}
+
+// checkLeader returns an error if the current node is not the leader.
+// Changes to cron jobs go through the scheduler which only runs on
+// the leader.
+func (h *serviceHandler) checkLeader(api string) error {
+	if !h.candidate.IsLeader() {
+		return yarpcerrors.UnavailableErrorf(
+			"Cron %s API not suppported on non-leader", api)
+	}
+	return nil
+}
+
+// checkAccess returns a permission denied error if the user in ctx is
+// not permitted on the resource pool and owner of the template of a cron
+// job. The scheduler creates the jobs of cron jobs without the user, so
+// the access is checked when the cron jobs are changed or run, and the
+// scheduler checks the access of the owner of the cron job at each run.
+func (h *serviceHandler) checkAccess(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+) error {
+	if _, ok := auth.ResourceUserFromContext(ctx); !ok {
+		return nil
+	}
+
+	respoolPath, err := handlerutil.GetResourcePoolPath(
+		ctx,
+		h.respoolClient,
+		config.GetTemplate().GetRespoolID(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return handlerutil.CheckResourceAccess(ctx, &auth.Resource{
+		ResourcePoolPath: respoolPath,
+		Owner:            config.GetTemplate().GetOwningTeam(),
+	})
+}
+
+// checkAccessByName checks the access to the existing cron job with
+// the given name.
+func (h *serviceHandler) checkAccessByName(
+	ctx context.Context,
+	name string,
+) error {
+	if _, ok := auth.ResourceUserFromContext(ctx); !ok {
+		return nil
+	}
+
+	info, err := h.cronJobOps.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	return h.checkAccess(ctx, info.GetConfig())
+}
+
+// CreateCronJob creates a cron job.
+func (h *serviceHandler) CreateCronJob(
+	ctx context.Context,
+	req *pbcronsvc.CreateCronJobRequest,
+) (*pbcronsvc.CreateCronJobResponse, error) {
+	h.metrics.CronAPICreate.Inc(1)
+
+	if err := h.createCronJob(ctx, req.GetConfig()); err != nil {
+		h.metrics.CronCreateFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.CronCreate.Inc(1)
+	return &pbcronsvc.CreateCronJobResponse{}, nil
+}
+
+func (h *serviceHandler) createCronJob(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+) error {
+	if err := h.checkLeader("Create"); err != nil {
+		return err
+	}
+
+	if err := h.checkAccess(ctx, config); err != nil {
+		return err
+	}
+
+	if err := h.scheduler.Create(ctx, config); err != nil {
+		log.WithError(err).
+			WithField("cron_job", config.GetName()).
+			Warn("CronService.CreateCronJob failed")
+		return err
+	}
+
+	log.WithField("cron_job", config.GetName()).
+		WithField("schedule", config.GetSchedule()).
+		Info("CronService.CreateCronJob succeeded")
+	return nil
+}
+
+// ReplaceCronJob replaces the config of a cron job.
+func (h *serviceHandler) ReplaceCronJob(
+	ctx context.Context,
+	req *pbcronsvc.ReplaceCronJobRequest,
+) (*pbcronsvc.ReplaceCronJobResponse, error) {
+	h.metrics.CronAPIReplace.Inc(1)
+
+	if err := h.replaceCronJob(ctx, req.GetConfig()); err != nil {
+		h.metrics.CronReplaceFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.CronReplace.Inc(1)
+	return &pbcronsvc.ReplaceCronJobResponse{}, nil
+}
+
+func (h *serviceHandler) replaceCronJob(
+	ctx context.Context,
+	config *pbcron.CronJobConfig,
+) error {
+	if err := h.checkLeader("Replace"); err != nil {
+		return err
+	}
+
+	if err := h.checkAccessByName(ctx, config.GetName()); err != nil {
+		return err
+	}
+
+	if err := h.checkAccess(ctx, config); err != nil {
+		return err
+	}
+
+	if err := h.scheduler.Replace(ctx, config); err != nil {
+		log.WithError(err).
+			WithField("cron_job", config.GetName()).
+			Warn("CronService.ReplaceCronJob failed")
+		return err
+	}
+
+	log.WithField("cron_job", config.GetName()).
+		WithField("schedule", config.GetSchedule()).
+		Info("CronService.ReplaceCronJob succeeded")
+	return nil
+}
+
+// GetCronJob returns a cron job.
+func (h *serviceHandler) GetCronJob(
+	ctx context.Context,
+	req *pbcronsvc.GetCronJobRequest,
+) (*pbcronsvc.GetCronJobResponse, error) {
+	h.metrics.CronAPIGet.Inc(1)
+
+	info, err := h.cronJobOps.Get(ctx, req.GetName())
+	if err != nil {
+		h.metrics.CronGetFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.CronGet.Inc(1)
+	return &pbcronsvc.GetCronJobResponse{CronJob: info}, nil
+}
+
+// ListCronJobs returns all cron jobs.
+func (h *serviceHandler) ListCronJobs(
+	ctx context.Context,
+	req *pbcronsvc.ListCronJobsRequest,
+) (*pbcronsvc.ListCronJobsResponse, error) {
+	h.metrics.CronAPIList.Inc(1)
+
+	infos, err := h.cronJobOps.GetAll(ctx)
+	if err != nil {
+		h.metrics.CronListFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.CronList.Inc(1)
+	return &pbcronsvc.ListCronJobsResponse{CronJobs: infos}, nil
+}
+
+// DeleteCronJob deletes a cron job.
+func (h *serviceHandler) DeleteCronJob(
+	ctx context.Context,
+	req *pbcronsvc.DeleteCronJobRequest,
+) (*pbcronsvc.DeleteCronJobResponse, error) {
+	h.metrics.CronAPIDelete.Inc(1)
+
+	if err := h.checkLeader("Delete"); err != nil {
+		h.metrics.CronDeleteFail.Inc(1)
+		return nil, err
+	}
+
+	if err := h.checkAccessByName(ctx, req.GetName()); err != nil {
+		h.metrics.CronDeleteFail.Inc(1)
+		return nil, err
+	}
+
+	if err := h.scheduler.Delete(ctx, req.GetName()); err != nil {
+		log.WithError(err).
+			WithField("cron_job", req.GetName()).
+			Warn("CronService.DeleteCronJob failed")
+		h.metrics.CronDeleteFail.Inc(1)
+		return nil, err
+	}
+
+	log.WithField("cron_job", req.GetName()).
+		Info("CronService.DeleteCronJob succeeded")
+	h.metrics.CronDelete.Inc(1)
+	return &pbcronsvc.DeleteCronJobResponse{}, nil
+}
+
+// RunCronJob runs a cron job immediately.
+func (h *serviceHandler) RunCronJob(
+	ctx context.Context,
+	req *pbcronsvc.RunCronJobRequest,
+) (*pbcronsvc.RunCronJobResponse, error) {
+	h.metrics.CronAPIRun.Inc(1)
+
+	if err := h.checkLeader("Run"); err != nil {
+		h.metrics.CronRunFail.Inc(1)
+		return nil, err
+	}
+
+	if err := h.checkAccessByName(ctx, req.GetName()); err != nil {
+		h.metrics.CronRunFail.Inc(1)
+		return nil, err
+	}
+
+	jobID, err := h.scheduler.Run(ctx, req.GetName())
+	if err != nil {
+		log.WithError(err).
+			WithField("cron_job", req.GetName()).
+			Warn("CronService.RunCronJob failed")
+		h.metrics.CronRunFail.Inc(1)
+		return nil, err
+	}
+
+	log.WithField("cron_job", req.GetName()).
+		WithField("job_id", jobID.GetValue()).
+		Info("CronService.RunCronJob succeeded")
+	h.metrics.CronRun.Inc(1)
+	return &pbcronsvc.RunCronJobResponse{JobId: jobID}, nil
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"context"
+	"testing"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	pbcronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	v1alphacron "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	v1alphacronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	cronmocks "github.com/uber/peloton/pkg/jobmgr/cron/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type handlerTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	cronJobOps    *objectmocks.MockCronJobOps
+	respoolClient *respoolmocks.MockResourceManagerYARPCClient
+	scheduler     *cronmocks.MockScheduler
+	candidate     *leadermocks.MockCandidate
+	handler       *serviceHandler
+	v1Handler     *v1AlphaServiceHandler
+
+	config *pbcron.CronJobConfig
+}
+
+func (suite *handlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.scheduler = cronmocks.NewMockScheduler(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		cronJobOps:    suite.cronJobOps,
+		respoolClient: suite.respoolClient,
+		scheduler:     suite.scheduler,
+		candidate:     suite.candidate,
+		metrics:       NewMetrics(tally.NoopScope),
+	}
+	suite.v1Handler = &v1AlphaServiceHandler{handler: suite.handler}
+
+	suite.config = &pbcron.CronJobConfig{
+		Name:              "cron",
+		Schedule:          "@daily",
+		ConcurrencyPolicy: pbcron.ConcurrencyPolicy_REPLACE,
+		Template: &job.JobConfig{
+			Type:          job.JobType_BATCH,
+			InstanceCount: 1,
+			OwningTeam:    "team-b",
+			RespoolID:     &peloton.ResourcePoolID{Value: "respool"},
+		},
+	}
+}
+
+func (suite *handlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestCronServiceHandler(t *testing.T) {
+	suite.Run(t, new(handlerTestSuite))
+}
+
+// TestCreateCronJob tests creating a cron job
+func (suite *handlerTestSuite) TestCreateCronJob() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.scheduler.EXPECT().Create(gomock.Any(), suite.config).Return(nil)
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&pbcronsvc.CreateCronJobRequest{Config: suite.config},
+	)
+	suite.NoError(err)
+}
+
+// TestCreateCronJobNonLeader tests creating a cron job on a non-leader
+func (suite *handlerTestSuite) TestCreateCronJobNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&pbcronsvc.CreateCronJobRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestReplaceCronJobFailure tests failing to replace a cron job
+func (suite *handlerTestSuite) TestReplaceCronJobFailure() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.scheduler.EXPECT().Replace(gomock.Any(), suite.config).
+		Return(yarpcerrors.NotFoundErrorf("test error"))
+
+	_, err := suite.handler.ReplaceCronJob(
+		context.Background(),
+		&pbcronsvc.ReplaceCronJobRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetAndListCronJobs tests reading cron jobs
+func (suite *handlerTestSuite) TestGetAndListCronJobs() {
+	info := &pbcron.CronJobInfo{
+		Config:  suite.config,
+		Runtime: &pbcron.CronJobRuntime{RunCount: 1},
+	}
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").Return(info, nil)
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbcron.CronJobInfo{info}, nil)
+
+	getResp, err := suite.handler.GetCronJob(
+		context.Background(),
+		&pbcronsvc.GetCronJobRequest{Name: "cron"},
+	)
+	suite.NoError(err)
+	suite.Equal(info, getResp.GetCronJob())
+
+	listResp, err := suite.handler.ListCronJobs(
+		context.Background(),
+		&pbcronsvc.ListCronJobsRequest{},
+	)
+	suite.NoError(err)
+	suite.Equal([]*pbcron.CronJobInfo{info}, listResp.GetCronJobs())
+}
+
+// TestDeleteCronJob tests deleting a cron job
+func (suite *handlerTestSuite) TestDeleteCronJob() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.scheduler.EXPECT().Delete(gomock.Any(), "cron").Return(nil)
+
+	_, err := suite.handler.DeleteCronJob(
+		context.Background(),
+		&pbcronsvc.DeleteCronJobRequest{Name: "cron"},
+	)
+	suite.NoError(err)
+}
+
+// TestCronJobPermissionDenied tests that creating, deleting and running
+// a cron job fail for a user without a grant on the resource pool of
+// its template
+func (suite *handlerTestSuite) TestCronJobPermissionDenied() {
+	user := authmocks.NewMockResourceUser(suite.ctrl)
+	ctx := auth.ContextWithUser(context.Background(), user)
+
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(3)
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{Config: suite.config}, nil).
+		Times(2)
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: "respool"},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   &peloton.ResourcePoolID{Value: "respool"},
+				Path: &respool.ResourcePoolPath{Value: "/infra/teamB"},
+			},
+		}, nil).
+		Times(3)
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), &auth.Resource{
+			ResourcePoolPath: "/infra/teamB",
+			Owner:            "team-b",
+		}).
+		Return(false).
+		Times(3)
+
+	_, err := suite.handler.CreateCronJob(
+		ctx,
+		&pbcronsvc.CreateCronJobRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	_, err = suite.handler.DeleteCronJob(
+		ctx,
+		&pbcronsvc.DeleteCronJobRequest{Name: "cron"},
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	_, err = suite.handler.RunCronJob(
+		ctx,
+		&pbcronsvc.RunCronJobRequest{Name: "cron"},
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestRunCronJob tests running a cron job
+func (suite *handlerTestSuite) TestRunCronJob() {
+	jobID := &peloton.JobID{Value: "job"}
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.scheduler.EXPECT().Run(gomock.Any(), "cron").Return(jobID, nil)
+
+	resp, err := suite.handler.RunCronJob(
+		context.Background(),
+		&pbcronsvc.RunCronJobRequest{Name: "cron"},
+	)
+	suite.NoError(err)
+	suite.Equal(jobID, resp.GetJobId())
+}
+
+// TestV1AlphaCreateCronJob tests creating a cron job through
+// the v1alpha API
+func (suite *handlerTestSuite) TestV1AlphaCreateCronJob() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.scheduler.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, config *pbcron.CronJobConfig) error {
+			suite.Equal("cron", config.GetName())
+			suite.Equal(pbcron.ConcurrencyPolicy_ALLOW, config.GetConcurrencyPolicy())
+			suite.Equal(job.JobType_BATCH, config.GetTemplate().GetType())
+			suite.Equal(uint32(3), config.GetTemplate().GetInstanceCount())
+			return nil
+		})
+
+	_, err := suite.v1Handler.CreateCronJob(
+		context.Background(),
+		&v1alphacronsvc.CreateCronJobRequest{
+			Spec: &v1alphacron.CronJobSpec{
+				Name:              "cron",
+				Schedule:          "@daily",
+				ConcurrencyPolicy: v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW,
+				Template:          &stateless.JobSpec{InstanceCount: 3},
+			},
+		},
+	)
+	suite.NoError(err)
+}
+
+// TestV1AlphaCreateCronJobNoTemplate tests creating a cron job
+// without template through the v1alpha API
+func (suite *handlerTestSuite) TestV1AlphaCreateCronJobNoTemplate() {
+	_, err := suite.v1Handler.CreateCronJob(
+		context.Background(),
+		&v1alphacronsvc.CreateCronJobRequest{
+			Spec: &v1alphacron.CronJobSpec{Name: "cron"},
+		},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestV1AlphaGetCronJob tests getting a cron job through the v1alpha API
+func (suite *handlerTestSuite) TestV1AlphaGetCronJob() {
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "cron").
+		Return(&pbcron.CronJobInfo{
+			Config: suite.config,
+			Runtime: &pbcron.CronJobRuntime{
+				ActiveJobs: []*peloton.JobID{{Value: "job"}},
+				RunCount:   1,
+			},
+		}, nil)
+
+	resp, err := suite.v1Handler.GetCronJob(
+		context.Background(),
+		&v1alphacronsvc.GetCronJobRequest{Name: "cron"},
+	)
+	suite.NoError(err)
+	suite.Equal(
+		v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE,
+		resp.GetCronJob().GetSpec().GetConcurrencyPolicy())
+	suite.Equal(
+		[]*v1alphapeloton.JobID{{Value: "job"}},
+		resp.GetCronJob().GetStatus().GetActiveJobs())
+	suite.Equal(uint64(1), resp.GetCronJob().GetStatus().GetRunCount())
+}
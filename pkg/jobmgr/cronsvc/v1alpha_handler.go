@@ -0,0 +1,236 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"context"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	pbcronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	v1alphacron "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	v1alphacronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/common/api"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// v1AlphaServiceHandler implements
+// peloton.api.v1alpha.job.cron.svc.CronJobService on top of the
+// v0 cron job service.
+type v1AlphaServiceHandler struct {
+	handler *serviceHandler
+}
+
+// CreateCronJob creates a cron job.
+func (h *v1AlphaServiceHandler) CreateCronJob(
+	ctx context.Context,
+	req *v1alphacronsvc.CreateCronJobRequest,
+) (*v1alphacronsvc.CreateCronJobResponse, error) {
+	h.handler.metrics.CronAPICreate.Inc(1)
+
+	config, err := convertCronJobSpecToConfig(req.GetSpec())
+	if err == nil {
+		err = h.handler.createCronJob(ctx, config)
+	}
+	if err != nil {
+		h.handler.metrics.CronCreateFail.Inc(1)
+		return nil, err
+	}
+
+	h.handler.metrics.CronCreate.Inc(1)
+	return &v1alphacronsvc.CreateCronJobResponse{}, nil
+}
+
+// ReplaceCronJob replaces the config of a cron job.
+func (h *v1AlphaServiceHandler) ReplaceCronJob(
+	ctx context.Context,
+	req *v1alphacronsvc.ReplaceCronJobRequest,
+) (*v1alphacronsvc.ReplaceCronJobResponse, error) {
+	h.handler.metrics.CronAPIReplace.Inc(1)
+
+	config, err := convertCronJobSpecToConfig(req.GetSpec())
+	if err == nil {
+		err = h.handler.replaceCronJob(ctx, config)
+	}
+	if err != nil {
+		h.handler.metrics.CronReplaceFail.Inc(1)
+		return nil, err
+	}
+
+	h.handler.metrics.CronReplace.Inc(1)
+	return &v1alphacronsvc.ReplaceCronJobResponse{}, nil
+}
+
+// GetCronJob returns a cron job.
+func (h *v1AlphaServiceHandler) GetCronJob(
+	ctx context.Context,
+	req *v1alphacronsvc.GetCronJobRequest,
+) (*v1alphacronsvc.GetCronJobResponse, error) {
+	resp, err := h.handler.GetCronJob(
+		ctx,
+		&pbcronsvc.GetCronJobRequest{Name: req.GetName()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alphacronsvc.GetCronJobResponse{
+		CronJob: convertCronJobInfo(resp.GetCronJob()),
+	}, nil
+}
+
+// ListCronJobs returns all cron jobs.
+func (h *v1AlphaServiceHandler) ListCronJobs(
+	ctx context.Context,
+	req *v1alphacronsvc.ListCronJobsRequest,
+) (*v1alphacronsvc.ListCronJobsResponse, error) {
+	resp, err := h.handler.ListCronJobs(ctx, &pbcronsvc.ListCronJobsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var cronJobs []*v1alphacron.CronJobInfo
+	for _, info := range resp.GetCronJobs() {
+		cronJobs = append(cronJobs, convertCronJobInfo(info))
+	}
+	return &v1alphacronsvc.ListCronJobsResponse{CronJobs: cronJobs}, nil
+}
+
+// DeleteCronJob deletes a cron job.
+func (h *v1AlphaServiceHandler) DeleteCronJob(
+	ctx context.Context,
+	req *v1alphacronsvc.DeleteCronJobRequest,
+) (*v1alphacronsvc.DeleteCronJobResponse, error) {
+	_, err := h.handler.DeleteCronJob(
+		ctx,
+		&pbcronsvc.DeleteCronJobRequest{Name: req.GetName()},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alphacronsvc.DeleteCronJobResponse{}, nil
+}
+
+// RunCronJob runs a cron job immediately.
+func (h *v1AlphaServiceHandler) RunCronJob(
+	ctx context.Context,
+	req *v1alphacronsvc.RunCronJobRequest,
+) (*v1alphacronsvc.RunCronJobResponse, error) {
+	resp, err := h.handler.RunCronJob(
+		ctx,
+		&pbcronsvc.RunCronJobRequest{Name: req.GetName()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobID *v1alphapeloton.JobID
+	if resp.GetJobId() != nil {
+		jobID = &v1alphapeloton.JobID{Value: resp.GetJobId().GetValue()}
+	}
+	return &v1alphacronsvc.RunCronJobResponse{JobId: jobID}, nil
+}
+
+// convertCronJobSpecToConfig converts a v1alpha cron job spec to
+// a v0 cron job config.
+func convertCronJobSpecToConfig(
+	spec *v1alphacron.CronJobSpec,
+) (*pbcron.CronJobConfig, error) {
+	if spec.GetTemplate() == nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"cron job template is not set")
+	}
+
+	template, err := api.ConvertJobSpecToJobConfig(spec.GetTemplate())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid cron job template: %v", err)
+	}
+	template.Type = job.JobType_BATCH
+
+	var policy pbcron.ConcurrencyPolicy
+	switch spec.GetConcurrencyPolicy() {
+	case v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_INVALID,
+		v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP:
+		policy = pbcron.ConcurrencyPolicy_SKIP
+	case v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE:
+		policy = pbcron.ConcurrencyPolicy_REPLACE
+	case v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW:
+		policy = pbcron.ConcurrencyPolicy_ALLOW
+	default:
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"unknown concurrency policy %s", spec.GetConcurrencyPolicy())
+	}
+
+	return &pbcron.CronJobConfig{
+		Name:              spec.GetName(),
+		Schedule:          spec.GetSchedule(),
+		ConcurrencyPolicy: policy,
+		HistoryLimit:      spec.GetHistoryLimit(),
+		Paused:            spec.GetPaused(),
+		Template:          template,
+	}, nil
+}
+
+// convertCronJobInfo converts a v0 cron job info to a v1alpha
+// cron job info.
+func convertCronJobInfo(info *pbcron.CronJobInfo) *v1alphacron.CronJobInfo {
+	config := info.GetConfig()
+	runtime := info.GetRuntime()
+
+	var policy v1alphacron.ConcurrencyPolicy
+	switch config.GetConcurrencyPolicy() {
+	case pbcron.ConcurrencyPolicy_SKIP:
+		policy = v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_SKIP
+	case pbcron.ConcurrencyPolicy_REPLACE:
+		policy = v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_REPLACE
+	case pbcron.ConcurrencyPolicy_ALLOW:
+		policy = v1alphacron.ConcurrencyPolicy_CONCURRENCY_POLICY_ALLOW
+	}
+
+	return &v1alphacron.CronJobInfo{
+		Spec: &v1alphacron.CronJobSpec{
+			Name:              config.GetName(),
+			Schedule:          config.GetSchedule(),
+			ConcurrencyPolicy: policy,
+			HistoryLimit:      config.GetHistoryLimit(),
+			Paused:            config.GetPaused(),
+			Template:          api.ConvertJobConfigToJobSpec(config.GetTemplate()),
+		},
+		Status: &v1alphacron.CronJobStatus{
+			LastScheduleTime: runtime.GetLastScheduleTime(),
+			NextScheduleTime: runtime.GetNextScheduleTime(),
+			ActiveJobs:       convertJobIDs(runtime.GetActiveJobs()),
+			RecentJobs:       convertJobIDs(runtime.GetRecentJobs()),
+			RunCount:         runtime.GetRunCount(),
+			SkipCount:        runtime.GetSkipCount(),
+			Message:          runtime.GetMessage(),
+			Owner:            runtime.GetOwner(),
+		},
+	}
+}
+
+// convertJobIDs converts v0 job IDs to v1alpha job IDs.
+func convertJobIDs(jobIDs []*peloton.JobID) []*v1alphapeloton.JobID {
+	var result []*v1alphapeloton.JobID
+	for _, jobID := range jobIDs {
+		result = append(result, &v1alphapeloton.JobID{Value: jobID.GetValue()})
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import "time"
+
+const (
+	_defaultReconcilePeriod = 30 * time.Second
+	_defaultMaxRemovalRatio = 0.1
+)
+
+// Config is the daemon job manager specific config
+type Config struct {
+	// ReconcilePeriod is the period to compare the instances of the
+	// daemon jobs with the hosts eligible to run them, besides when the
+	// host events show that a host joined or left
+	ReconcilePeriod time.Duration `yaml:"reconcile_period"`
+
+	// MaxRemovalRatio is the fraction of the instances of a daemon job
+	// which a reconciliation may remove, at least one instance, so that
+	// a short list of hosts, e.g. while the host manager recovers after
+	// a restart, does not remove most instances at once
+	MaxRemovalRatio float64 `yaml:"max_removal_ratio"`
+}
+
+func (c *Config) normalize() {
+	if c.ReconcilePeriod == time.Duration(0) {
+		c.ReconcilePeriod = _defaultReconcilePeriod
+	}
+	if c.MaxRemovalRatio <= 0 {
+		c.MaxRemovalRatio = _defaultMaxRemovalRatio
+	}
+}
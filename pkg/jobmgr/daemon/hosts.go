@@ -0,0 +1,277 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"sort"
+
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/constraints"
+)
+
+// EligibleHosts returns the sorted names of the hosts which satisfy the
+// host constraint of a daemon job. The host pool and the labels of a host
+// can be used in the constraint besides its name. All the hosts are
+// eligible if the constraint is not set.
+func EligibleHosts(
+	hostInfos []*pbhost.HostInfo,
+	constraint *task.Constraint,
+) ([]string, error) {
+	evaluator := constraints.NewEvaluator(task.LabelConstraint_HOST)
+
+	var hosts []string
+	for _, hostInfo := range hostInfos {
+		if constraint != nil {
+			result, err := evaluator.Evaluate(
+				constraint,
+				hostLabelValues(hostInfo))
+			if err != nil {
+				return nil, err
+			}
+			if result == constraints.EvaluateResultMismatch {
+				continue
+			}
+		}
+		hosts = append(hosts, hostInfo.GetHostname())
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// hostLabelValues returns the label values of a host used to evaluate
+// the constraint of a daemon job.
+func hostLabelValues(hostInfo *pbhost.HostInfo) constraints.LabelValues {
+	labelValues := constraints.LabelValues{
+		common.HostNameKey: {hostInfo.GetHostname(): 1},
+	}
+	if pool := hostInfo.GetCurrentPool(); len(pool) > 0 {
+		labelValues[common.HostPoolKey] = map[string]uint32{pool: 1}
+	}
+	for _, label := range hostInfo.GetLabels() {
+		if _, ok := labelValues[label.GetKey()]; !ok {
+			labelValues[label.GetKey()] = make(map[string]uint32)
+		}
+		labelValues[label.GetKey()][label.GetValue()]++
+	}
+	return labelValues
+}
+
+// InstanceHosts returns the host each instance of a daemon job is pinned
+// to, indexed by instance ID. The host of an instance which is not pinned,
+// like the vacant instance IDs of removed instances, is empty.
+func InstanceHosts(config *job.JobConfig) []string {
+	hosts := make([]string, config.GetInstanceCount())
+	for i := range hosts {
+		hosts[i] = pinnedHost(
+			config.GetInstanceConfig()[uint32(i)].GetConstraint())
+	}
+	return hosts
+}
+
+// SetInstanceHosts sets the instance count and the instance configs of
+// a daemon job, so that there is one instance pinned to each host. The
+// instance IDs with an empty host are left vacant without a config.
+func SetInstanceHosts(config *job.JobConfig, hosts []string) {
+	config.InstanceCount = uint32(len(hosts))
+	config.InstanceConfig = make(map[uint32]*task.TaskConfig, len(hosts))
+	for i, host := range hosts {
+		if len(host) == 0 {
+			continue
+		}
+		config.InstanceConfig[uint32(i)] = &task.TaskConfig{
+			Constraint: pinConstraint(
+				config.GetDefaultConfig().GetConstraint(),
+				host),
+		}
+	}
+}
+
+// AssignHosts assigns the eligible hosts to the instances of a daemon job,
+// given the hosts the instances are currently pinned to. Instances on hosts
+// which are still eligible keep their instance ID, and the instance IDs of
+// hosts which are not eligible anymore are left vacant, with an empty host,
+// rather than moving a running instance into them. New hosts take the
+// vacant instance IDs first, then new IDs after the last instance. Vacant
+// IDs after the last instance are dropped. It also returns whether the
+// assignment changed.
+func AssignHosts(current []string, eligible []string) ([]string, bool) {
+	eligibleSet := make(map[string]bool, len(eligible))
+	for _, host := range eligible {
+		eligibleSet[host] = true
+	}
+
+	assigned := make([]string, len(current))
+	kept := make(map[string]bool)
+	var vacant []int
+	for i, host := range current {
+		if eligibleSet[host] && !kept[host] {
+			assigned[i] = host
+			kept[host] = true
+			continue
+		}
+		vacant = append(vacant, i)
+	}
+
+	var added []string
+	for _, host := range eligible {
+		if !kept[host] {
+			added = append(added, host)
+		}
+	}
+
+	for len(vacant) > 0 && len(added) > 0 {
+		assigned[vacant[0]] = added[0]
+		vacant = vacant[1:]
+		added = added[1:]
+	}
+	assigned = append(assigned, added...)
+
+	for len(assigned) > 0 && len(assigned[len(assigned)-1]) == 0 {
+		assigned = assigned[:len(assigned)-1]
+	}
+
+	if len(assigned) != len(current) {
+		return assigned, true
+	}
+	for i := range assigned {
+		if assigned[i] != current[i] {
+			return assigned, true
+		}
+	}
+	return assigned, false
+}
+
+// LimitRemovals limits the number of instances of a daemon job which
+// lose their current host in the assignment to maxRemoved, by keeping
+// the current host of the instances beyond the limit. This prevents a
+// short list of eligible hosts, e.g. while the host manager is still
+// recovering the hosts, from removing most instances at once. The kept
+// instances are removed by the following reconciliations if their hosts
+// are still not eligible. It also returns whether the result differs from
+// the current hosts.
+func LimitRemovals(
+	current []string,
+	assigned []string,
+	maxRemoved int) ([]string, bool) {
+	limited := make([]string, len(assigned))
+	copy(limited, assigned)
+
+	var removed int
+	for i, from := range current {
+		if len(from) == 0 || (i < len(limited) && limited[i] == from) {
+			continue
+		}
+		if removed < maxRemoved {
+			removed++
+			continue
+		}
+		for i >= len(limited) {
+			limited = append(limited, "")
+		}
+		limited[i] = from
+	}
+
+	for len(limited) > 0 && len(limited[len(limited)-1]) == 0 {
+		limited = limited[:len(limited)-1]
+	}
+
+	if len(limited) != len(current) {
+		return limited, true
+	}
+	for i := range limited {
+		if limited[i] != current[i] {
+			return limited, true
+		}
+	}
+	return limited, false
+}
+
+// InstanceChanges returns the instances of a daemon job which are added,
+// moved to another host and removed, when its instances are pinned to the
+// assigned hosts instead of the current ones.
+func InstanceChanges(current []string, assigned []string) (
+	added []uint32, updated []uint32, removed []uint32) {
+	for i := 0; i < len(current) || i < len(assigned); i++ {
+		var from, to string
+		if i < len(current) {
+			from = current[i]
+		}
+		if i < len(assigned) {
+			to = assigned[i]
+		}
+
+		switch {
+		case from == to:
+		case len(from) == 0:
+			added = append(added, uint32(i))
+		case len(to) == 0:
+			removed = append(removed, uint32(i))
+		default:
+			updated = append(updated, uint32(i))
+		}
+	}
+	return added, updated, removed
+}
+
+// pinConstraint returns the constraint of an instance pinned to a host,
+// which also has to satisfy the constraint of the default config.
+func pinConstraint(constraint *task.Constraint, host string) *task.Constraint {
+	hostConstraint := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   common.HostNameKey,
+				Value: host,
+			},
+			Requirement: 1,
+		},
+	}
+	if constraint == nil {
+		return hostConstraint
+	}
+	return &task.Constraint{
+		Type: task.Constraint_AND_CONSTRAINT,
+		AndConstraint: &task.AndConstraint{
+			Constraints: []*task.Constraint{constraint, hostConstraint},
+		},
+	}
+}
+
+// pinnedHost returns the host a constraint created by pinConstraint pins
+// an instance to.
+func pinnedHost(constraint *task.Constraint) string {
+	switch constraint.GetType() {
+	case task.Constraint_LABEL_CONSTRAINT:
+		labelConstraint := constraint.GetLabelConstraint()
+		if labelConstraint.GetKind() == task.LabelConstraint_HOST &&
+			labelConstraint.GetCondition() == task.LabelConstraint_CONDITION_EQUAL &&
+			labelConstraint.GetLabel().GetKey() == common.HostNameKey {
+			return labelConstraint.GetLabel().GetValue()
+		}
+	case task.Constraint_AND_CONSTRAINT:
+		children := constraint.GetAndConstraint().GetConstraints()
+		if len(children) > 0 {
+			return pinnedHost(children[len(children)-1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"testing"
+
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hostLabelConstraint returns a host constraint on a label
+func hostLabelConstraint(key, value string) *task.Constraint {
+	return &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:        task.LabelConstraint_HOST,
+			Condition:   task.LabelConstraint_CONDITION_EQUAL,
+			Label:       &peloton.Label{Key: key, Value: value},
+			Requirement: 1,
+		},
+	}
+}
+
+func TestEligibleHosts(t *testing.T) {
+	hostInfos := []*pbhost.HostInfo{
+		{
+			Hostname:    "host-2",
+			CurrentPool: "shared",
+			Labels:      []*peloton.Label{{Key: "zone", Value: "dca1"}},
+		},
+		{
+			Hostname:    "host-1",
+			CurrentPool: "stateless",
+			Labels:      []*peloton.Label{{Key: "zone", Value: "dca1"}},
+		},
+		{
+			Hostname:    "host-0",
+			CurrentPool: "stateless",
+			Labels:      []*peloton.Label{{Key: "zone", Value: "phx2"}},
+		},
+	}
+
+	hosts, err := EligibleHosts(hostInfos, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host-0", "host-1", "host-2"}, hosts)
+
+	hosts, err = EligibleHosts(
+		hostInfos,
+		hostLabelConstraint(common.HostPoolKey, "stateless"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host-0", "host-1"}, hosts)
+
+	hosts, err = EligibleHosts(
+		hostInfos,
+		&task.Constraint{
+			Type: task.Constraint_AND_CONSTRAINT,
+			AndConstraint: &task.AndConstraint{
+				Constraints: []*task.Constraint{
+					hostLabelConstraint(common.HostPoolKey, "stateless"),
+					hostLabelConstraint("zone", "dca1"),
+				},
+			},
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host-1"}, hosts)
+
+	_, err = EligibleHosts(hostInfos, &task.Constraint{})
+	assert.Error(t, err)
+}
+
+func TestSetInstanceHosts(t *testing.T) {
+	defaultConstraint := hostLabelConstraint(common.HostPoolKey, "stateless")
+	config := &job.JobConfig{
+		Type: job.JobType_DAEMON,
+		DefaultConfig: &task.TaskConfig{
+			Constraint: defaultConstraint,
+		},
+	}
+	assert.Empty(t, InstanceHosts(config))
+
+	SetInstanceHosts(config, []string{"host-0", "host-1"})
+	assert.Equal(t, uint32(2), config.GetInstanceCount())
+	assert.Equal(t, []string{"host-0", "host-1"}, InstanceHosts(config))
+
+	// the pinned constraint also includes the default constraint
+	constraint := config.GetInstanceConfig()[1].GetConstraint()
+	assert.Equal(t, task.Constraint_AND_CONSTRAINT, constraint.GetType())
+	assert.Equal(t, defaultConstraint,
+		constraint.GetAndConstraint().GetConstraints()[0])
+	assert.Equal(t, hostLabelConstraint(common.HostNameKey, "host-1"),
+		constraint.GetAndConstraint().GetConstraints()[1])
+
+	// vacant instance IDs have no config
+	SetInstanceHosts(config, []string{"", "host-1"})
+	assert.Equal(t, uint32(2), config.GetInstanceCount())
+	assert.Nil(t, config.GetInstanceConfig()[0])
+	assert.Equal(t, []string{"", "host-1"}, InstanceHosts(config))
+
+	// without default constraint, the instance is only pinned
+	config.DefaultConfig.Constraint = nil
+	SetInstanceHosts(config, []string{"host-2"})
+	assert.Equal(t, hostLabelConstraint(common.HostNameKey, "host-2"),
+		config.GetInstanceConfig()[0].GetConstraint())
+	assert.Equal(t, []string{"host-2"}, InstanceHosts(config))
+}
+
+func TestAssignHosts(t *testing.T) {
+	tt := []struct {
+		name     string
+		current  []string
+		eligible []string
+		assigned []string
+		changed  bool
+	}{
+		{
+			name:     "no change",
+			current:  []string{"b", "a"},
+			eligible: []string{"a", "b"},
+			assigned: []string{"b", "a"},
+		},
+		{
+			name:     "initial hosts",
+			eligible: []string{"a", "b"},
+			assigned: []string{"a", "b"},
+			changed:  true,
+		},
+		{
+			name:     "host joins",
+			current:  []string{"b", "a"},
+			eligible: []string{"a", "b", "c"},
+			assigned: []string{"b", "a", "c"},
+			changed:  true,
+		},
+		{
+			name:     "host leaves and another joins",
+			current:  []string{"a", "b", "c"},
+			eligible: []string{"a", "c", "d"},
+			assigned: []string{"a", "d", "c"},
+			changed:  true,
+		},
+		{
+			name:     "last host leaves",
+			current:  []string{"a", "b", "c"},
+			eligible: []string{"a", "b"},
+			assigned: []string{"a", "b"},
+			changed:  true,
+		},
+		{
+			name:     "middle host leaves",
+			current:  []string{"a", "b", "c"},
+			eligible: []string{"a", "c"},
+			assigned: []string{"a", "", "c"},
+			changed:  true,
+		},
+		{
+			name:     "host joins into vacant instance",
+			current:  []string{"a", "", "c"},
+			eligible: []string{"a", "c", "d"},
+			assigned: []string{"a", "d", "c"},
+			changed:  true,
+		},
+		{
+			name:     "hosts leave",
+			current:  []string{"a", "b", "c", "d"},
+			eligible: []string{"c", "d"},
+			assigned: []string{"", "", "c", "d"},
+			changed:  true,
+		},
+		{
+			name:     "vacant instances",
+			current:  []string{"", "b", ""},
+			eligible: []string{"b"},
+			assigned: []string{"", "b"},
+			changed:  true,
+		},
+		{
+			name:     "unpinned and duplicate instances",
+			current:  []string{"a", "", "a"},
+			eligible: []string{"a", "b"},
+			assigned: []string{"a", "b"},
+			changed:  true,
+		},
+		{
+			name:     "all hosts leave",
+			current:  []string{"a", "b"},
+			assigned: []string{},
+			changed:  true,
+		},
+	}
+
+	for _, test := range tt {
+		assigned, changed := AssignHosts(test.current, test.eligible)
+		assert.Equal(t, test.changed, changed, test.name)
+		if len(test.assigned) == 0 {
+			assert.Empty(t, assigned, test.name)
+			continue
+		}
+		assert.Equal(t, test.assigned, assigned, test.name)
+	}
+}
+
+func TestLimitRemovals(t *testing.T) {
+	tt := []struct {
+		name       string
+		current    []string
+		assigned   []string
+		maxRemoved int
+		limited    []string
+		changed    bool
+	}{
+		{
+			name:       "within limit",
+			current:    []string{"a", "b", "c"},
+			assigned:   []string{"a", "d", "c"},
+			maxRemoved: 1,
+			limited:    []string{"a", "d", "c"},
+			changed:    true,
+		},
+		{
+			name:       "removals beyond limit are kept",
+			current:    []string{"a", "b", "c", "d"},
+			assigned:   []string{"", "e"},
+			maxRemoved: 2,
+			limited:    []string{"", "e", "c", "d"},
+			changed:    true,
+		},
+		{
+			name:       "all instances kept",
+			current:    []string{"a", "b"},
+			maxRemoved: 0,
+			limited:    []string{"a", "b"},
+		},
+		{
+			name:       "added hosts are not limited",
+			current:    []string{"a", ""},
+			assigned:   []string{"a", "b", "c"},
+			maxRemoved: 0,
+			limited:    []string{"a", "b", "c"},
+			changed:    true,
+		},
+	}
+
+	for _, test := range tt {
+		limited, changed := LimitRemovals(
+			test.current, test.assigned, test.maxRemoved)
+		assert.Equal(t, test.changed, changed, test.name)
+		assert.Equal(t, test.limited, limited, test.name)
+	}
+}
+
+func TestInstanceChanges(t *testing.T) {
+	added, updated, removed := InstanceChanges(
+		[]string{"a", "b", "", "d", "e"},
+		[]string{"a", "", "c", "f"})
+	assert.Equal(t, []uint32{2}, added)
+	assert.Equal(t, []uint32{3}, updated)
+	assert.Equal(t, []uint32{1, 4}, removed)
+
+	added, updated, removed = InstanceChanges(nil, []string{"a", "b"})
+	assert.Equal(t, []uint32{0, 1}, added)
+	assert.Empty(t, updated)
+	assert.Empty(t, removed)
+}
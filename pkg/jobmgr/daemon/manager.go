@@ -0,0 +1,436 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	halphapb "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/util"
+	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	updateutil "github.com/uber/peloton/pkg/jobmgr/util/update"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+const (
+	_timeoutFunctionCall = 60 * time.Second
+
+	// _hostSummaryTopic is the topic of the host events of the host manager
+	_hostSummaryTopic = "hostSummary"
+	// _hostEventDelay is the time the host events of a burst, like a rack
+	// joining, are coalesced into a single reconciliation
+	_hostEventDelay = 5 * time.Second
+	// _watchRetryInterval is the time to wait before subscribing to the
+	// host events again after the watch broke
+	_watchRetryInterval = 10 * time.Second
+)
+
+// Manager defines the interface of the daemon job manager, which keeps
+// one instance of each daemon job on every host eligible to run it.
+// Hosts which join the cluster or start matching the constraint of a
+// daemon job get a new instance, and the instances of hosts which are
+// drained, leave the cluster or stop matching the constraint are removed.
+// The changes are applied through an update of the job which rolls the
+// instances one host at a time.
+type Manager interface {
+	// Start starts reconciling the daemon jobs as the host manager reports
+	// host events, and periodically to resync.
+	Start() error
+	// Stop stops reconciling the daemon jobs.
+	Stop() error
+}
+
+// manager implements the Manager interface
+type manager struct {
+	jobConfigOps    ormobjects.JobConfigOps
+	updateStore     storage.UpdateStore
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	hostClient      host_svc.HostServiceYARPCClient
+	hostmgrClient   hostsvc.InternalHostServiceYARPCClient
+	config          *Config
+	metrics         *Metrics
+	lifeCycle       lifecycle.LifeCycle
+
+	// hostsLock guards hosts
+	hostsLock sync.RWMutex
+	// hosts is the set of the hosts which were up on the last
+	// reconciliation
+	hosts map[string]bool
+
+	// reconcileCh signals that a host may have joined or left
+	reconcileCh chan struct{}
+}
+
+// New creates a daemon job manager. The hosts which are up are queried
+// from the host manager through hostClient, and the host events are
+// watched through hostmgrClient.
+func New(
+	ormStore *ormobjects.Store,
+	updateStore storage.UpdateStore,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	hostClient host_svc.HostServiceYARPCClient,
+	hostmgrClient hostsvc.InternalHostServiceYARPCClient,
+	parent tally.Scope,
+	config *Config,
+) Manager {
+	config.normalize()
+
+	return &manager{
+		jobConfigOps:    ormobjects.NewJobConfigOps(ormStore),
+		updateStore:     updateStore,
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		hostClient:      hostClient,
+		hostmgrClient:   hostmgrClient,
+		config:          config,
+		metrics: NewMetrics(
+			parent.SubScope("jobmgr").SubScope("daemon")),
+		lifeCycle:   lifecycle.NewLifeCycle(),
+		hosts:       make(map[string]bool),
+		reconcileCh: make(chan struct{}, 1),
+	}
+}
+
+// Start starts the daemon job manager, which reconciles the daemon jobs
+// when a host joins or leaves, and every reconcile period to resync.
+func (m *manager) Start() error {
+	if !m.lifeCycle.Start() {
+		log.Warn("Daemon job manager is already running, no action will be performed")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.watchHosts(ctx)
+	}()
+
+	go func() {
+		defer m.lifeCycle.StopComplete()
+		defer wg.Wait()
+		defer cancel()
+
+		ticker := time.NewTicker(m.config.ReconcilePeriod)
+		defer ticker.Stop()
+
+		log.Info("Starting daemon job manager")
+		m.reconcile()
+		for {
+			select {
+			case <-m.lifeCycle.StopCh():
+				log.Info("Exiting daemon job manager")
+				return
+			case <-ticker.C:
+			case <-m.reconcileCh:
+				select {
+				case <-m.lifeCycle.StopCh():
+					log.Info("Exiting daemon job manager")
+					return
+				case <-time.After(_hostEventDelay):
+				}
+				// the events received meanwhile are covered
+				select {
+				case <-m.reconcileCh:
+				default:
+				}
+			}
+			m.reconcile()
+		}
+	}()
+	return nil
+}
+
+// Stop stops the daemon job manager
+func (m *manager) Stop() error {
+	if !m.lifeCycle.Stop() {
+		log.Warn("Daemon job manager is already stopped, no action will be performed")
+		return nil
+	}
+
+	// Wait for the daemon job manager to be stopped
+	m.lifeCycle.Wait()
+	log.Info("Daemon job manager stopped")
+	return nil
+}
+
+// watchHosts watches the host events of the host manager until ctx is
+// cancelled, and watches them again whenever the watch breaks.
+func (m *manager) watchHosts(ctx context.Context) {
+	for {
+		if err := m.watchHostEvents(ctx); ctx.Err() == nil {
+			m.metrics.HostWatchFail.Inc(1)
+			log.WithError(err).Warn("host event watch ended")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(_watchRetryInterval):
+		}
+	}
+}
+
+// watchHostEvents receives the host events of the host manager until the
+// watch breaks, and signals a reconciliation when a host may have joined
+// or left.
+func (m *manager) watchHostEvents(ctx context.Context) error {
+	stream, err := m.hostmgrClient.WatchHostSummaryEvent(
+		ctx,
+		&hostsvc.WatchEventRequest{Topic: _hostSummaryTopic})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		// the first response only has the watch id
+		event := resp.GetHostSummaryEvent()
+		if event == nil || !m.isHostChange(event) {
+			continue
+		}
+
+		m.metrics.HostEvents.Inc(1)
+		select {
+		case m.reconcileCh <- struct{}{}:
+		default:
+			// a reconciliation is already pending
+		}
+	}
+}
+
+// isHostChange returns true if a host event may change the hosts which
+// are up, which is the case for the hosts unknown to the last
+// reconciliation, and for the hosts without any offer left since their
+// offers are rescinded when they are drained or lost.
+func (m *manager) isHostChange(event *halphapb.HostSummary) bool {
+	m.hostsLock.RLock()
+	defer m.hostsLock.RUnlock()
+
+	if !m.hosts[event.GetHostname()] {
+		return true
+	}
+	return len(event.GetOffers()) == 0
+}
+
+// reconcile compares the instances of each daemon job with the hosts
+// which are currently eligible to run it, and updates the jobs whose
+// hosts changed.
+func (m *manager) reconcile() {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_timeoutFunctionCall)
+	defer cancelFunc()
+
+	// draining, drained and down hosts are not returned
+	resp, err := m.hostClient.QueryHosts(
+		ctx,
+		&host_svc.QueryHostsRequest{
+			HostStates: []pbhost.HostState{pbhost.HostState_HOST_STATE_UP},
+		})
+	if err != nil {
+		m.metrics.ReconcileFail.Inc(1)
+		log.WithError(err).Error("failed to query hosts")
+		return
+	}
+
+	// the host manager has no hosts until the agents are loaded after
+	// it restarts or fails over, which would remove all the instances
+	if len(resp.GetHostInfos()) == 0 {
+		m.metrics.ReconcileSkipped.Inc(1)
+		log.Warn("no hosts are up, skip reconciling daemon jobs")
+		return
+	}
+
+	hosts := make(map[string]bool, len(resp.GetHostInfos()))
+	for _, hostInfo := range resp.GetHostInfos() {
+		hosts[hostInfo.GetHostname()] = true
+	}
+	m.hostsLock.Lock()
+	m.hosts = hosts
+	m.hostsLock.Unlock()
+
+	for _, cachedJob := range m.jobFactory.GetAllJobs() {
+		if cachedJob.GetJobType() != job.JobType_DAEMON {
+			continue
+		}
+		if err := m.reconcileJob(
+			ctx,
+			cachedJob,
+			resp.GetHostInfos(),
+		); err != nil {
+			m.metrics.JobReconcileFail.Inc(1)
+			log.WithError(err).
+				WithField("job_id", cachedJob.ID().GetValue()).
+				Warn("failed to reconcile daemon job")
+		}
+	}
+	m.metrics.ReconcileSuccess.Inc(1)
+}
+
+// reconcileJob creates an update of a daemon job if the hosts eligible
+// to run it changed. Jobs which are being created, stopped or updated
+// are reconciled once done.
+func (m *manager) reconcileJob(
+	ctx context.Context,
+	cachedJob cached.Job,
+	hostInfos []*pbhost.HostInfo,
+) error {
+	runtime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if runtime.GetState() == job.JobState_INITIALIZED ||
+		util.IsPelotonJobStateTerminal(runtime.GetGoalState()) {
+		m.metrics.JobSkipped.Inc(1)
+		return nil
+	}
+
+	if updateutil.HasUpdate(runtime) {
+		updateModel, err := m.updateStore.GetUpdateProgress(
+			ctx,
+			runtime.GetUpdateID())
+		if err != nil {
+			return err
+		}
+		if !cached.IsUpdateStateTerminal(updateModel.GetState()) {
+			m.metrics.JobSkipped.Inc(1)
+			return nil
+		}
+	}
+
+	config, configAddOn, err := m.jobConfigOps.Get(
+		ctx,
+		cachedJob.ID(),
+		runtime.GetConfigurationVersion())
+	if err != nil {
+		return err
+	}
+
+	eligible, err := EligibleHosts(
+		hostInfos,
+		config.GetDefaultConfig().GetConstraint())
+	if err != nil {
+		return err
+	}
+
+	current := InstanceHosts(config)
+	assigned, changed := AssignHosts(current, eligible)
+	if !changed {
+		return nil
+	}
+
+	// a short list of hosts must not remove most instances at once
+	maxRemoved := int(float64(countHosts(current)) * m.config.MaxRemovalRatio)
+	if maxRemoved < 1 {
+		maxRemoved = 1
+	}
+	limited, changed := LimitRemovals(current, assigned, maxRemoved)
+	var kept int
+	for i := range limited {
+		if i >= len(assigned) || limited[i] != assigned[i] {
+			kept++
+		}
+	}
+	if kept > 0 {
+		m.metrics.InstancesKept.Inc(int64(kept))
+		log.WithFields(log.Fields{
+			"job_id":      cachedJob.ID().GetValue(),
+			"max_removed": maxRemoved,
+			"kept":        kept,
+		}).Warn("limited the instances removed from daemon job")
+	}
+	if !changed {
+		return nil
+	}
+	assigned = limited
+
+	newConfig := proto.Clone(config).(*job.JobConfig)
+	SetInstanceHosts(newConfig, assigned)
+
+	// the instances are given explicitly, since the vacant instance IDs
+	// are not removed by changing the instance count
+	added, updated, removed := InstanceChanges(current, assigned)
+
+	updateID, _, err := cachedJob.CreateWorkflow(
+		ctx,
+		models.WorkflowType_UPDATE,
+		&pbupdate.UpdateConfig{BatchSize: 1},
+		versionutil.GetJobEntityVersion(
+			runtime.GetConfigurationVersion(),
+			runtime.GetDesiredStateVersion(),
+			runtime.GetWorkflowVersion()),
+		cached.WithConfig(newConfig, config, configAddOn, nil),
+		cached.WithInstanceToProcess(added, updated, removed),
+	)
+
+	// Enqueue the update even if the creation failed, as it may have
+	// been persisted. The goal state engine either runs it or aborts it.
+	if len(updateID.GetValue()) > 0 {
+		m.goalStateDriver.EnqueueUpdate(cachedJob.ID(), updateID, time.Now())
+	}
+
+	if err != nil {
+		m.metrics.UpdateCreateFail.Inc(1)
+		return err
+	}
+	m.metrics.UpdateCreateSuccess.Inc(1)
+	m.metrics.InstancesAdded.Inc(int64(len(added)))
+	m.metrics.InstancesMoved.Inc(int64(len(updated)))
+	m.metrics.InstancesRemoved.Inc(int64(len(removed)))
+
+	log.WithFields(log.Fields{
+		"job_id":    cachedJob.ID().GetValue(),
+		"update_id": updateID.GetValue(),
+		"hosts":     assigned,
+	}).Info("updated hosts of daemon job")
+	return nil
+}
+
+// countHosts returns the number of instances pinned to a host
+func countHosts(hosts []string) int {
+	var count int
+	for _, host := range hosts {
+		if len(host) > 0 {
+			count++
+		}
+	}
+	return count
+}
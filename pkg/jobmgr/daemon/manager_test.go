@@ -0,0 +1,399 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/api/v0/host/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	halphapb "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostsvcmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type managerTestSuite struct {
+	suite.Suite
+
+	mockCtrl        *gomock.Controller
+	jobConfigOps    *objectmocks.MockJobConfigOps
+	updateStore     *storemocks.MockUpdateStore
+	jobFactory      *cachedmocks.MockJobFactory
+	cachedJob       *cachedmocks.MockJob
+	goalStateDriver *goalstatemocks.MockDriver
+	hostClient      *hostmocks.MockHostServiceYARPCClient
+	hostmgrClient   *hostsvcmocks.MockInternalHostServiceYARPCClient
+	manager         *manager
+
+	jobID     *peloton.JobID
+	runtime   *job.RuntimeInfo
+	config    *job.JobConfig
+	hostInfos []*pbhost.HostInfo
+}
+
+func (suite *managerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.mockCtrl)
+	suite.updateStore = storemocks.NewMockUpdateStore(suite.mockCtrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.mockCtrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.mockCtrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.mockCtrl)
+	suite.hostClient = hostmocks.NewMockHostServiceYARPCClient(suite.mockCtrl)
+	suite.hostmgrClient = hostsvcmocks.NewMockInternalHostServiceYARPCClient(
+		suite.mockCtrl)
+
+	suite.manager = &manager{
+		jobConfigOps:    suite.jobConfigOps,
+		updateStore:     suite.updateStore,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		hostClient:      suite.hostClient,
+		hostmgrClient:   suite.hostmgrClient,
+		config:          &Config{},
+		metrics:         NewMetrics(tally.NoopScope),
+		lifeCycle:       lifecycle.NewLifeCycle(),
+		hosts:           make(map[string]bool),
+		reconcileCh:     make(chan struct{}, 1),
+	}
+	suite.manager.config.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.New()}
+	suite.runtime = &job.RuntimeInfo{
+		State:                job.JobState_RUNNING,
+		GoalState:            job.JobState_RUNNING,
+		ConfigurationVersion: 2,
+	}
+	suite.config = &job.JobConfig{
+		Type: job.JobType_DAEMON,
+		DefaultConfig: &task.TaskConfig{
+			Constraint: hostLabelConstraint("zone", "dca1"),
+		},
+		ChangeLog: &peloton.ChangeLog{Version: 2},
+	}
+	SetInstanceHosts(suite.config, []string{"host-0", "host-1"})
+
+	suite.hostInfos = []*pbhost.HostInfo{
+		newHostInfo("host-0", "dca1"),
+		newHostInfo("host-1", "dca1"),
+	}
+}
+
+func (suite *managerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestManager(t *testing.T) {
+	suite.Run(t, new(managerTestSuite))
+}
+
+// newHostInfo returns an up host in the given zone
+func newHostInfo(hostname string, zone string) *pbhost.HostInfo {
+	return &pbhost.HostInfo{
+		Hostname: hostname,
+		State:    pbhost.HostState_HOST_STATE_UP,
+		Labels:   []*peloton.Label{{Key: "zone", Value: zone}},
+	}
+}
+
+// expectJob sets up the expectations to get the daemon job from cache
+// and the hosts from the host manager
+func (suite *managerTestSuite) expectJob() {
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), &host_svc.QueryHostsRequest{
+			HostStates: []pbhost.HostState{pbhost.HostState_HOST_STATE_UP},
+		}).
+		Return(&host_svc.QueryHostsResponse{HostInfos: suite.hostInfos}, nil)
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().GetJobType().Return(job.JobType_DAEMON)
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(suite.runtime, nil)
+}
+
+// TestReconcileNoChange tests that a daemon job is not updated if its
+// hosts did not change
+func (suite *managerTestSuite) TestReconcileNoChange() {
+	suite.expectJob()
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), suite.jobID, uint64(2)).
+		Return(suite.config, &models.ConfigAddOn{}, nil)
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileHostChanges tests that a daemon job is updated one host
+// at a time when a host leaves and another joins
+func (suite *managerTestSuite) TestReconcileHostChanges() {
+	suite.hostInfos = []*pbhost.HostInfo{
+		newHostInfo("host-1", "dca1"),
+		newHostInfo("host-2", "dca1"),
+		newHostInfo("host-3", "dca1"),
+		newHostInfo("host-4", "phx2"),
+	}
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+	configAddOn := &models.ConfigAddOn{}
+
+	suite.expectJob()
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), suite.jobID, uint64(2)).
+		Return(suite.config, configAddOn, nil)
+	suite.cachedJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			models.WorkflowType_UPDATE,
+			&pbupdate.UpdateConfig{BatchSize: 1},
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Return(updateID, nil, nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(suite.jobID, updateID, gomock.Any())
+
+	suite.manager.reconcile()
+
+	// the config of the current version is not modified
+	suite.Equal([]string{"host-0", "host-1"}, InstanceHosts(suite.config))
+
+	// the hosts which are up are known to the host event watch
+	suite.Equal(map[string]bool{
+		"host-1": true,
+		"host-2": true,
+		"host-3": true,
+		"host-4": true,
+	}, suite.manager.hosts)
+}
+
+// TestReconcileSkipUpdatingJob tests that a daemon job with an update
+// in progress is not reconciled
+func (suite *managerTestSuite) TestReconcileSkipUpdatingJob() {
+	suite.runtime.UpdateID = &peloton.UpdateID{Value: uuid.New()}
+
+	suite.expectJob()
+	suite.updateStore.EXPECT().
+		GetUpdateProgress(gomock.Any(), suite.runtime.GetUpdateID()).
+		Return(&models.UpdateModel{State: pbupdate.State_ROLLING_FORWARD}, nil)
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileSkipStoppedJob tests that a daemon job being stopped is
+// not reconciled
+func (suite *managerTestSuite) TestReconcileSkipStoppedJob() {
+	suite.runtime.GoalState = job.JobState_KILLED
+
+	suite.expectJob()
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileSkipOtherJobs tests that jobs of other types are not
+// reconciled
+func (suite *managerTestSuite) TestReconcileSkipOtherJobs() {
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&host_svc.QueryHostsResponse{HostInfos: suite.hostInfos}, nil)
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE)
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileQueryHostsFailure tests that no job is reconciled if the
+// hosts cannot be queried
+func (suite *managerTestSuite) TestReconcileQueryHostsFailure() {
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileNoHosts tests that no job is reconciled if no host is
+// up, as is the case until the host manager loads the agents
+func (suite *managerTestSuite) TestReconcileNoHosts() {
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&host_svc.QueryHostsResponse{}, nil)
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileLimitRemovals tests that a reconciliation only removes
+// a fraction of the instances of a daemon job when most hosts are missing
+func (suite *managerTestSuite) TestReconcileLimitRemovals() {
+	var hosts []string
+	for i := 0; i < 10; i++ {
+		hosts = append(hosts, fmt.Sprintf("host-%d", i))
+	}
+	SetInstanceHosts(suite.config, hosts)
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+
+	suite.expectJob()
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), suite.jobID, uint64(2)).
+		Return(suite.config, &models.ConfigAddOn{}, nil)
+	suite.cachedJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			models.WorkflowType_UPDATE,
+			&pbupdate.UpdateConfig{BatchSize: 1},
+			gomock.Any(),
+			gomock.Any(),
+			cached.WithInstanceToProcess(nil, nil, []uint32{2}),
+		).
+		Return(updateID, nil, nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(suite.jobID, updateID, gomock.Any())
+
+	suite.manager.reconcile()
+}
+
+// TestReconcileCreateWorkflowFailure tests that the update is enqueued
+// if its creation failed after being persisted
+func (suite *managerTestSuite) TestReconcileCreateWorkflowFailure() {
+	suite.hostInfos = append(suite.hostInfos, newHostInfo("host-2", "dca1"))
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+
+	suite.expectJob()
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), suite.jobID, uint64(2)).
+		Return(suite.config, &models.ConfigAddOn{}, nil)
+	suite.cachedJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Return(updateID, nil, errors.New("test error"))
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(suite.jobID, updateID, gomock.Any())
+
+	suite.manager.reconcile()
+}
+
+// TestWatchHostEvents tests that a reconciliation is signaled when a host
+// joins or loses all its offers, but not on the other host events
+func (suite *managerTestSuite) TestWatchHostEvents() {
+	suite.manager.hosts = map[string]bool{"host-0": true, "host-1": true}
+	offers := map[string]*mesos.Offer{"offer-0": {}}
+
+	stream := hostsvcmocks.
+		NewMockInternalHostServiceServiceWatchHostSummaryEventYARPCClient(
+			suite.mockCtrl)
+	suite.hostmgrClient.EXPECT().
+		WatchHostSummaryEvent(
+			gomock.Any(),
+			&hostsvc.WatchEventRequest{Topic: _hostSummaryTopic}).
+		Return(stream, nil)
+
+	gomock.InOrder(
+		// the initial response only has the watch id
+		stream.EXPECT().Recv().
+			Return(&hostsvc.WatchHostSummaryEventResponse{WatchId: "watch"}, nil),
+		// a known host gets offers
+		stream.EXPECT().Recv().
+			Return(&hostsvc.WatchHostSummaryEventResponse{
+				HostSummaryEvent: &halphapb.HostSummary{
+					Hostname: "host-0",
+					Offers:   offers,
+				},
+			}, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	suite.Equal(io.EOF, suite.manager.watchHostEvents(context.Background()))
+	suite.Empty(suite.manager.reconcileCh)
+
+	suite.hostmgrClient.EXPECT().
+		WatchHostSummaryEvent(gomock.Any(), gomock.Any()).
+		Return(stream, nil)
+	gomock.InOrder(
+		// a host joins
+		stream.EXPECT().Recv().
+			Return(&hostsvc.WatchHostSummaryEventResponse{
+				HostSummaryEvent: &halphapb.HostSummary{
+					Hostname: "host-2",
+					Offers:   offers,
+				},
+			}, nil),
+		// a host loses all its offers while a reconciliation is pending
+		stream.EXPECT().Recv().
+			Return(&hostsvc.WatchHostSummaryEventResponse{
+				HostSummaryEvent: &halphapb.HostSummary{Hostname: "host-1"},
+			}, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	suite.Equal(io.EOF, suite.manager.watchHostEvents(context.Background()))
+	suite.Len(suite.manager.reconcileCh, 1)
+}
+
+// TestWatchHostEventsFailure tests the failure to watch the host events
+func (suite *managerTestSuite) TestWatchHostEventsFailure() {
+	suite.hostmgrClient.EXPECT().
+		WatchHostSummaryEvent(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.manager.watchHostEvents(context.Background()))
+	suite.Empty(suite.manager.reconcileCh)
+}
+
+// TestStartStop tests starting and stopping the daemon job manager
+func (suite *managerTestSuite) TestStartStop() {
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error")).
+		AnyTimes()
+	suite.hostmgrClient.EXPECT().
+		WatchHostSummaryEvent(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error")).
+		AnyTimes()
+
+	suite.NoError(suite.manager.Start())
+	// starting again is a no-op
+	suite.NoError(suite.manager.Start())
+	suite.NoError(suite.manager.Stop())
+	// stopping again is a no-op
+	suite.NoError(suite.manager.Stop())
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of the daemon job manager.
+type Metrics struct {
+	ReconcileSuccess tally.Counter
+	ReconcileFail    tally.Counter
+	ReconcileSkipped tally.Counter
+
+	JobReconcileFail tally.Counter
+	JobSkipped       tally.Counter
+
+	HostEvents    tally.Counter
+	HostWatchFail tally.Counter
+
+	UpdateCreateSuccess tally.Counter
+	UpdateCreateFail    tally.Counter
+
+	InstancesAdded   tally.Counter
+	InstancesRemoved tally.Counter
+	InstancesMoved   tally.Counter
+	InstancesKept    tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		ReconcileSuccess: successScope.Counter("reconcile"),
+		ReconcileFail:    failScope.Counter("reconcile"),
+		ReconcileSkipped: scope.Counter("reconcile_skipped"),
+
+		JobReconcileFail: failScope.Counter("job_reconcile"),
+		JobSkipped:       scope.Counter("job_skipped"),
+
+		HostEvents:    scope.Counter("host_events"),
+		HostWatchFail: failScope.Counter("host_watch"),
+
+		UpdateCreateSuccess: successScope.Counter("update_create"),
+		UpdateCreateFail:    failScope.Counter("update_create"),
+
+		InstancesAdded:   scope.Counter("instances_added"),
+		InstancesRemoved: scope.Counter("instances_removed"),
+		InstancesMoved:   scope.Counter("instances_moved"),
+		InstancesKept:    scope.Counter("instances_kept"),
+	}
+}
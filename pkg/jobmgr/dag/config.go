@@ -0,0 +1,71 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import "time"
+
+const (
+	_defaultEvaluationPeriod  = 15 * time.Second
+	_defaultReconcilePeriod   = 60 * time.Second
+	_defaultNumWorkerThreads  = 4
+	_defaultFailureRetryDelay = 10 * time.Second
+	_defaultMaxRetryDelay     = 5 * time.Minute
+)
+
+// Config is the DAG manager specific config
+type Config struct {
+	// EvaluationPeriod is the period to evaluate a running DAG, i.e. to
+	// refresh the states of its nodes and create the jobs of the nodes
+	// whose parents reached a terminal state
+	EvaluationPeriod time.Duration `yaml:"evaluation_period"`
+
+	// ReconcilePeriod is the period to look for running DAGs which are
+	// not tracked by the goal state engine, e.g. after a leader change
+	ReconcilePeriod time.Duration `yaml:"reconcile_period"`
+
+	// NumWorkerThreads is the number of goal state engine workers
+	// evaluating DAGs
+	NumWorkerThreads int `yaml:"num_worker_threads"`
+
+	// FailureRetryDelay is the initial delay to evaluate a DAG again
+	// after a failed evaluation
+	FailureRetryDelay time.Duration `yaml:"failure_retry_delay"`
+
+	// MaxRetryDelay is the maximum delay to evaluate a DAG again after
+	// consecutive failed evaluations
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay"`
+}
+
+func (c *Config) normalize() {
+	if c.EvaluationPeriod == time.Duration(0) {
+		c.EvaluationPeriod = _defaultEvaluationPeriod
+	}
+
+	if c.ReconcilePeriod == time.Duration(0) {
+		c.ReconcilePeriod = _defaultReconcilePeriod
+	}
+
+	if c.NumWorkerThreads == 0 {
+		c.NumWorkerThreads = _defaultNumWorkerThreads
+	}
+
+	if c.FailureRetryDelay == time.Duration(0) {
+		c.FailureRetryDelay = _defaultFailureRetryDelay
+	}
+
+	if c.MaxRetryDelay == time.Duration(0) {
+		c.MaxRetryDelay = _defaultMaxRetryDelay
+	}
+}
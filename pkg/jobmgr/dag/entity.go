@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"context"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DAGAction is a string for DAG actions.
+type DAGAction string
+
+const (
+	// NoDAGAction implies do not take any action
+	NoDAGAction DAGAction = "noop"
+	// EvaluateDAGAction refreshes the states of the nodes of a DAG,
+	// and creates or kills their jobs according to its goal state
+	EvaluateDAGAction DAGAction = "evaluate"
+	// UntrackDAGAction removes a DAG from the goal state engine
+	UntrackDAGAction DAGAction = "untrack"
+)
+
+// _dagActionsMaps maps the DAGAction string to the Action function.
+var (
+	_dagActionsMaps = map[DAGAction]goalstate.ActionExecute{
+		NoDAGAction:       nil,
+		EvaluateDAGAction: DAGEvaluate,
+		UntrackDAGAction:  DAGUntrack,
+	}
+)
+
+var (
+	_dagRules = map[pbdag.DAGState]DAGAction{
+		// unknown state, the DAG is not cached, evaluate it from db
+		pbdag.DAGState_DAG_STATE_INVALID: EvaluateDAGAction,
+		// run or kill the DAG
+		pbdag.DAGState_DAG_STATE_RUNNING: EvaluateDAGAction,
+		// DAG is complete, clean it up from the cache and goal state
+		pbdag.DAGState_DAG_STATE_SUCCEEDED: UntrackDAGAction,
+		// DAG is complete, clean it up from the cache and goal state
+		pbdag.DAGState_DAG_STATE_FAILED: UntrackDAGAction,
+		// DAG is complete, clean it up from the cache and goal state
+		pbdag.DAGState_DAG_STATE_KILLED: UntrackDAGAction,
+	}
+)
+
+// dagEntity implements the goal state Entity interface for DAGs.
+type dagEntity struct {
+	id      *pbdag.DAGID // DAG identifier
+	manager *manager     // the DAG manager
+}
+
+// newEntity returns the goal state entity of a DAG.
+func (m *manager) newEntity(id *pbdag.DAGID) goalstate.Entity {
+	return &dagEntity{
+		id:      id,
+		manager: m,
+	}
+}
+
+func (d *dagEntity) GetID() string {
+	return d.id.GetValue()
+}
+
+func (d *dagEntity) GetState() interface{} {
+	return d.manager.getRuntime(d.id).GetState()
+}
+
+func (d *dagEntity) GetGoalState() interface{} {
+	return d.manager.getRuntime(d.id).GetGoalState()
+}
+
+func (d *dagEntity) GetActionList(
+	state interface{},
+	goalState interface{}) (
+	context.Context,
+	context.CancelFunc,
+	[]goalstate.Action) {
+	var actions []goalstate.Action
+
+	currentState := state.(pbdag.DAGState)
+	dagGoalState := goalState.(pbdag.DAGState)
+
+	actionStr, ok := _dagRules[currentState]
+	if !ok {
+		actionStr = NoDAGAction
+	}
+	action := _dagActionsMaps[actionStr]
+
+	log.WithFields(
+		log.Fields{
+			"dag_id":        d.id.GetValue(),
+			"current_state": currentState.String(),
+			"goal_state":    dagGoalState.String(),
+			"dag_action":    actionStr,
+		}).Debug("running DAG action")
+
+	if action != nil {
+		actions = append(actions, goalstate.Action{
+			Name:    string(actionStr),
+			Execute: action,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		_timeoutFunctionCall)
+	return ctx, cancel, actions
+}
+
+// DAGEvaluate evaluates a DAG.
+func DAGEvaluate(ctx context.Context, entity goalstate.Entity) error {
+	dagEnt := entity.(*dagEntity)
+	return dagEnt.manager.evaluate(ctx, dagEnt.id)
+}
+
+// DAGUntrack removes a DAG which reached a terminal state from the
+// cache and the goal state engine.
+func DAGUntrack(ctx context.Context, entity goalstate.Entity) error {
+	dagEnt := entity.(*dagEntity)
+	dagEnt.manager.untrack(dagEnt.id)
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ValidateConfig validates the config of a DAG. The nodes must have
+// unique names and batch job configs, and the edges must reference
+// existing nodes without forming cycles.
+func ValidateConfig(config *pbdag.DAGConfig) error {
+	if len(config.GetNodes()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("DAG has no nodes")
+	}
+
+	nodes := make(map[string]*pbdag.Node)
+	for _, node := range config.GetNodes() {
+		name := node.GetName()
+		if len(name) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf("DAG node name is empty")
+		}
+		if _, ok := nodes[name]; ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"duplicate DAG node %s", name)
+		}
+		if node.GetConfig() == nil {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"job config of DAG node %s is not set", name)
+		}
+		if node.GetConfig().GetType() != job.JobType_BATCH {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"job of DAG node %s must be a batch job", name)
+		}
+		nodes[name] = node
+	}
+
+	for _, node := range config.GetNodes() {
+		parents := make(map[string]bool)
+		for _, edge := range node.GetParents() {
+			parent := edge.GetParent()
+			if _, ok := nodes[parent]; !ok {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"DAG node %s depends on unknown node %s",
+					node.GetName(), parent)
+			}
+			if parents[parent] {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"DAG node %s depends more than once on node %s",
+					node.GetName(), parent)
+			}
+			parents[parent] = true
+		}
+	}
+
+	if len(topologicalOrder(config)) != len(config.GetNodes()) {
+		return yarpcerrors.InvalidArgumentErrorf("DAG has a cycle")
+	}
+	return nil
+}
+
+// topologicalOrder returns the names of the nodes of a DAG such that
+// every node comes after its parents. The nodes which are part of a
+// cycle are left out.
+func topologicalOrder(config *pbdag.DAGConfig) []string {
+	inDegree := make(map[string]int)
+	children := make(map[string][]string)
+	for _, node := range config.GetNodes() {
+		inDegree[node.GetName()] = len(node.GetParents())
+		for _, edge := range node.GetParents() {
+			children[edge.GetParent()] = append(
+				children[edge.GetParent()], node.GetName())
+		}
+	}
+
+	// start from the nodes without parents in the order of the config,
+	// so that the order is deterministic
+	var order []string
+	for _, node := range config.GetNodes() {
+		if inDegree[node.GetName()] == 0 {
+			order = append(order, node.GetName())
+		}
+	}
+
+	for i := 0; i < len(order); i++ {
+		for _, child := range children[order[i]] {
+			inDegree[child]--
+			if inDegree[child] == 0 {
+				order = append(order, child)
+			}
+		}
+	}
+	return order
+}
+
+// isNodeStateTerminal returns true if a node reached a terminal state.
+func isNodeStateTerminal(state pbdag.NodeState) bool {
+	switch state {
+	case pbdag.NodeState_NODE_STATE_SUCCEEDED,
+		pbdag.NodeState_NODE_STATE_FAILED,
+		pbdag.NodeState_NODE_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_SKIPPED:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDAGStateTerminal returns true if a DAG reached a terminal state.
+func IsDAGStateTerminal(state pbdag.DAGState) bool {
+	switch state {
+	case pbdag.DAGState_DAG_STATE_SUCCEEDED,
+		pbdag.DAGState_DAG_STATE_FAILED,
+		pbdag.DAGState_DAG_STATE_KILLED:
+		return true
+	default:
+		return false
+	}
+}
+
+// isEdgeSatisfied returns true if the terminal state of a parent node
+// satisfies the condition of an edge.
+func isEdgeSatisfied(
+	condition pbdag.EdgeCondition,
+	parentState pbdag.NodeState,
+) bool {
+	switch condition {
+	case pbdag.EdgeCondition_ON_SUCCESS:
+		return parentState == pbdag.NodeState_NODE_STATE_SUCCEEDED
+	case pbdag.EdgeCondition_ON_FAILURE:
+		return parentState == pbdag.NodeState_NODE_STATE_FAILED ||
+			parentState == pbdag.NodeState_NODE_STATE_KILLED
+	case pbdag.EdgeCondition_ALWAYS:
+		return true
+	default:
+		return false
+	}
+}
+
+// evaluateNode decides what to do with a pending node given the states
+// of all the nodes. A node is ready once all its parents reached a
+// terminal state satisfying the conditions of their edges, and it is
+// skipped if any of the conditions is not satisfied.
+func evaluateNode(
+	node *pbdag.Node,
+	states map[string]pbdag.NodeState,
+) (ready bool, skip bool) {
+	for _, edge := range node.GetParents() {
+		if !isNodeStateTerminal(states[edge.GetParent()]) {
+			return false, false
+		}
+	}
+
+	for _, edge := range node.GetParents() {
+		if !isEdgeSatisfied(edge.GetCondition(), states[edge.GetParent()]) {
+			return false, true
+		}
+	}
+	return true, false
+}
+
+// nodeStateFromJobState returns the state of a node whose job is in
+// the given state.
+func nodeStateFromJobState(state job.JobState) pbdag.NodeState {
+	switch state {
+	case job.JobState_SUCCEEDED:
+		return pbdag.NodeState_NODE_STATE_SUCCEEDED
+	case job.JobState_FAILED:
+		return pbdag.NodeState_NODE_STATE_FAILED
+	case job.JobState_KILLED, job.JobState_DELETED:
+		return pbdag.NodeState_NODE_STATE_KILLED
+	default:
+		return pbdag.NodeState_NODE_STATE_RUNNING
+	}
+}
+
+// dagState returns the state of a DAG from the states of its nodes.
+// A DAG is running until all its nodes reached a terminal state, it
+// then failed if any node failed or was killed, unless the DAG was
+// cancelled.
+func dagState(runtime *pbdag.DAGRuntime) pbdag.DAGState {
+	failed := false
+	for _, node := range runtime.GetNodes() {
+		if !isNodeStateTerminal(node.GetState()) {
+			return pbdag.DAGState_DAG_STATE_RUNNING
+		}
+		if node.GetState() == pbdag.NodeState_NODE_STATE_FAILED ||
+			node.GetState() == pbdag.NodeState_NODE_STATE_KILLED {
+			failed = true
+		}
+	}
+
+	switch {
+	case runtime.GetGoalState() == pbdag.DAGState_DAG_STATE_KILLED:
+		return pbdag.DAGState_DAG_STATE_KILLED
+	case failed:
+		return pbdag.DAGState_DAG_STATE_FAILED
+	default:
+		return pbdag.DAGState_DAG_STATE_SUCCEEDED
+	}
+}
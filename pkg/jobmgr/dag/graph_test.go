@@ -0,0 +1,271 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"testing"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// newNode returns a node with a batch job config depending on parents
+func newNode(name string, parents ...*pbdag.Edge) *pbdag.Node {
+	return &pbdag.Node{
+		Name: name,
+		Config: &job.JobConfig{
+			Type:          job.JobType_BATCH,
+			InstanceCount: 1,
+		},
+		Parents: parents,
+	}
+}
+
+// onSuccess returns an edge from parent with ON_SUCCESS condition
+func onSuccess(parent string) *pbdag.Edge {
+	return &pbdag.Edge{Parent: parent}
+}
+
+// onFailure returns an edge from parent with ON_FAILURE condition
+func onFailure(parent string) *pbdag.Edge {
+	return &pbdag.Edge{
+		Parent:    parent,
+		Condition: pbdag.EdgeCondition_ON_FAILURE,
+	}
+}
+
+// always returns an edge from parent with ALWAYS condition
+func always(parent string) *pbdag.Edge {
+	return &pbdag.Edge{
+		Parent:    parent,
+		Condition: pbdag.EdgeCondition_ALWAYS,
+	}
+}
+
+// TestValidateConfig tests the validation of DAG configs
+func TestValidateConfig(t *testing.T) {
+	serviceNode := newNode("service")
+	serviceNode.Config.Type = job.JobType_SERVICE
+
+	tests := []struct {
+		name  string
+		nodes []*pbdag.Node
+		valid bool
+	}{
+		{
+			name: "valid",
+			nodes: []*pbdag.Node{
+				newNode("a"),
+				newNode("b", onSuccess("a")),
+				newNode("c", onFailure("a")),
+				newNode("d", always("b"), always("c")),
+			},
+			valid: true,
+		},
+		{
+			name: "no nodes",
+		},
+		{
+			name:  "empty name",
+			nodes: []*pbdag.Node{newNode("")},
+		},
+		{
+			name:  "duplicate name",
+			nodes: []*pbdag.Node{newNode("a"), newNode("a")},
+		},
+		{
+			name:  "missing config",
+			nodes: []*pbdag.Node{{Name: "a"}},
+		},
+		{
+			name:  "service job",
+			nodes: []*pbdag.Node{serviceNode},
+		},
+		{
+			name:  "unknown parent",
+			nodes: []*pbdag.Node{newNode("a", onSuccess("b"))},
+		},
+		{
+			name: "duplicate parent",
+			nodes: []*pbdag.Node{
+				newNode("a"),
+				newNode("b", onSuccess("a"), onFailure("a")),
+			},
+		},
+		{
+			name:  "self dependency",
+			nodes: []*pbdag.Node{newNode("a", onSuccess("a"))},
+		},
+		{
+			name: "cycle",
+			nodes: []*pbdag.Node{
+				newNode("a"),
+				newNode("b", onSuccess("a"), onSuccess("d")),
+				newNode("c", onSuccess("b")),
+				newNode("d", onSuccess("c")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateConfig(&pbdag.DAGConfig{Name: "dag", Nodes: tt.nodes})
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.True(t, yarpcerrors.IsInvalidArgument(err), tt.name)
+	}
+}
+
+// TestTopologicalOrder tests that nodes are ordered after their parents
+func TestTopologicalOrder(t *testing.T) {
+	config := &pbdag.DAGConfig{
+		Nodes: []*pbdag.Node{
+			newNode("d", onSuccess("b"), onSuccess("c")),
+			newNode("c", onSuccess("a")),
+			newNode("b", onSuccess("a")),
+			newNode("a"),
+		},
+	}
+	assert.Equal(t, []string{"a", "c", "b", "d"}, topologicalOrder(config))
+}
+
+// TestEvaluateNode tests the evaluation of the edges of a pending node
+func TestEvaluateNode(t *testing.T) {
+	tests := []struct {
+		name   string
+		node   *pbdag.Node
+		states map[string]pbdag.NodeState
+		ready  bool
+		skip   bool
+	}{
+		{
+			name:  "no parents",
+			node:  newNode("a"),
+			ready: true,
+		},
+		{
+			name: "parent running",
+			node: newNode("b", always("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_RUNNING,
+			},
+		},
+		{
+			name: "parent succeeded",
+			node: newNode("b", onSuccess("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_SUCCEEDED,
+			},
+			ready: true,
+		},
+		{
+			name: "parent failed",
+			node: newNode("b", onSuccess("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_FAILED,
+			},
+			skip: true,
+		},
+		{
+			name: "parent killed on failure",
+			node: newNode("b", onFailure("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_KILLED,
+			},
+			ready: true,
+		},
+		{
+			name: "parent succeeded on failure",
+			node: newNode("b", onFailure("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_SUCCEEDED,
+			},
+			skip: true,
+		},
+		{
+			name: "parent skipped always",
+			node: newNode("b", always("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_SKIPPED,
+			},
+			ready: true,
+		},
+		{
+			name: "parent skipped on success",
+			node: newNode("b", onSuccess("a")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_SKIPPED,
+			},
+			skip: true,
+		},
+		{
+			name: "wait for all parents",
+			node: newNode("c", onSuccess("a"), onSuccess("b")),
+			states: map[string]pbdag.NodeState{
+				"a": pbdag.NodeState_NODE_STATE_FAILED,
+				"b": pbdag.NodeState_NODE_STATE_PENDING,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		ready, skip := evaluateNode(tt.node, tt.states)
+		assert.Equal(t, tt.ready, ready, tt.name)
+		assert.Equal(t, tt.skip, skip, tt.name)
+	}
+}
+
+// TestDAGState tests the state of a DAG computed from its nodes
+func TestDAGState(t *testing.T) {
+	newRuntime := func(
+		goalState pbdag.DAGState,
+		states ...pbdag.NodeState,
+	) *pbdag.DAGRuntime {
+		runtime := &pbdag.DAGRuntime{GoalState: goalState}
+		for _, state := range states {
+			runtime.Nodes = append(runtime.Nodes,
+				&pbdag.NodeStatus{State: state})
+		}
+		return runtime
+	}
+
+	assert.Equal(t, pbdag.DAGState_DAG_STATE_RUNNING, dagState(newRuntime(
+		pbdag.DAGState_DAG_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_SUCCEEDED,
+		pbdag.NodeState_NODE_STATE_PENDING)))
+
+	assert.Equal(t, pbdag.DAGState_DAG_STATE_SUCCEEDED, dagState(newRuntime(
+		pbdag.DAGState_DAG_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_SUCCEEDED,
+		pbdag.NodeState_NODE_STATE_SKIPPED)))
+
+	assert.Equal(t, pbdag.DAGState_DAG_STATE_FAILED, dagState(newRuntime(
+		pbdag.DAGState_DAG_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_FAILED,
+		pbdag.NodeState_NODE_STATE_SUCCEEDED)))
+
+	assert.Equal(t, pbdag.DAGState_DAG_STATE_KILLED, dagState(newRuntime(
+		pbdag.DAGState_DAG_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_SUCCEEDED)))
+
+	assert.Equal(t, pbdag.DAGState_DAG_STATE_RUNNING, dagState(newRuntime(
+		pbdag.DAGState_DAG_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_RUNNING)))
+}
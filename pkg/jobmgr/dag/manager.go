@@ -0,0 +1,624 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	jobmgrgoalstate "github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_dagReconcilerName = "dagReconciler"
+
+	// LabelKey is the key of the label added to the jobs created for
+	// the nodes of a DAG, its value is the ID of the DAG.
+	LabelKey = "dag_id"
+
+	// NodeLabelKey is the key of the label added to the jobs created
+	// for the nodes of a DAG, its value is the name of the node.
+	NodeLabelKey = "dag_node"
+
+	_timeoutFunctionCall = 60 * time.Second
+)
+
+// Manager defines the interface of the DAG manager which drives DAGs
+// of batch jobs through a goal state engine. The job of a node is
+// created once all its parents reached a terminal state satisfying
+// the conditions of their edges. All the changes to DAGs go through
+// the manager so that they are serialized with their evaluation.
+type Manager interface {
+	// Register registers the manager as a background work, so that
+	// DAGs are only evaluated on the leader.
+	Register(manager background.Manager) error
+
+	// Create creates a DAG and returns its ID.
+	Create(ctx context.Context, config *pbdag.DAGConfig) (*pbdag.DAGID, error)
+
+	// Cancel cancels a DAG. The jobs of the running nodes are killed
+	// and the jobs of the pending nodes are not created.
+	Cancel(ctx context.Context, id *pbdag.DAGID) error
+}
+
+// manager implements the Manager interface
+type manager struct {
+	// Mutex guards running and dagLocks
+	sync.Mutex
+
+	dagOps          ormobjects.DAGOps
+	jobRuntimeOps   ormobjects.JobRuntimeOps
+	jobFactory      cached.JobFactory
+	goalStateDriver jobmgrgoalstate.Driver
+	jobManager      job.JobManagerYARPCServer
+	engine          goalstate.Engine
+	config          *Config
+	metrics         *Metrics
+	now             func() time.Time
+
+	// running is the flag of the background work of the manager, it
+	// is unset when the job manager loses leadership
+	running *atomic.Bool
+
+	// dagLocks serializes the changes to each DAG, so that the DAGs
+	// are evaluated and cancelled without blocking each other
+	dagLocks map[string]*dagLock
+
+	// runtimes caches the runtimes of the DAGs tracked by the
+	// goal state engine
+	runtimeLock sync.RWMutex
+	runtimes    map[string]*pbdag.DAGRuntime
+}
+
+// New creates a DAG manager. The jobs of the nodes are created through
+// jobManager, so that they are validated like the jobs created through
+// the API.
+func New(
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver jobmgrgoalstate.Driver,
+	jobManager job.JobManagerYARPCServer,
+	parent tally.Scope,
+	config *Config,
+) Manager {
+	config.normalize()
+
+	scope := parent.SubScope("jobmgr").SubScope("dag")
+	return &manager{
+		dagOps:          ormobjects.NewDAGOps(ormStore),
+		jobRuntimeOps:   ormobjects.NewJobRuntimeOps(ormStore),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		jobManager:      jobManager,
+		engine: goalstate.NewEngine(
+			config.NumWorkerThreads,
+			config.FailureRetryDelay,
+			config.MaxRetryDelay,
+			scope.SubScope("goalstate")),
+		config:   config,
+		metrics:  NewMetrics(scope),
+		now:      time.Now,
+		dagLocks: make(map[string]*dagLock),
+		runtimes: make(map[string]*pbdag.DAGRuntime),
+	}
+}
+
+// dagLock is the lock of a DAG, it is removed from the manager once
+// it is released by its holder and all its waiters.
+type dagLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockDAG acquires the lock of a DAG.
+func (m *manager) lockDAG(id *pbdag.DAGID) {
+	m.Lock()
+	l, ok := m.dagLocks[id.GetValue()]
+	if !ok {
+		l = &dagLock{}
+		m.dagLocks[id.GetValue()] = l
+	}
+	l.refs++
+	m.Unlock()
+
+	l.Lock()
+}
+
+// unlockDAG releases the lock of a DAG.
+func (m *manager) unlockDAG(id *pbdag.DAGID) {
+	m.Lock()
+	l := m.dagLocks[id.GetValue()]
+	l.refs--
+	if l.refs == 0 {
+		delete(m.dagLocks, id.GetValue())
+	}
+	m.Unlock()
+
+	l.Unlock()
+}
+
+// Register starts the goal state engine and registers the manager as
+// a background work. The engine evaluates the DAGs only while the
+// background work is running.
+func (m *manager) Register(manager background.Manager) error {
+	m.engine.Start()
+	return manager.RegisterWorks(
+		background.Work{
+			Name: _dagReconcilerName,
+			Func: func(running *atomic.Bool) {
+				m.reconcile(running)
+			},
+			Period: m.config.ReconcilePeriod,
+		},
+	)
+}
+
+// reconcile enqueues the running DAGs which are not tracked by the
+// goal state engine.
+func (m *manager) reconcile(running *atomic.Bool) {
+	m.Lock()
+	m.running = running
+	m.Unlock()
+
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_timeoutFunctionCall)
+	defer cancelFunc()
+
+	infos, err := m.dagOps.GetAll(ctx)
+	if err != nil {
+		m.metrics.ReconcileFail.Inc(1)
+		log.WithError(err).Error("failed to get DAGs")
+		return
+	}
+
+	for _, info := range infos {
+		if IsDAGStateTerminal(info.GetRuntime().GetState()) {
+			continue
+		}
+		entity := m.newEntity(info.GetId())
+		if m.engine.IsScheduled(entity) {
+			continue
+		}
+		m.track(info.GetId(), info.GetRuntime(), time.Now())
+	}
+	m.metrics.ReconcileSuccess.Inc(1)
+}
+
+// isRunning returns true if the background work of the manager is
+// running, i.e. the job manager is the leader.
+func (m *manager) isRunning() bool {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.running != nil && m.running.Load()
+}
+
+// Create creates a DAG.
+func (m *manager) Create(
+	ctx context.Context,
+	config *pbdag.DAGConfig,
+) (*pbdag.DAGID, error) {
+	if err := ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	id := &pbdag.DAGID{Value: uuid.New()}
+	runtime := &pbdag.DAGRuntime{
+		State:        pbdag.DAGState_DAG_STATE_RUNNING,
+		GoalState:    pbdag.DAGState_DAG_STATE_RUNNING,
+		CreationTime: formatTime(m.now()),
+	}
+	for _, node := range config.GetNodes() {
+		runtime.Nodes = append(runtime.Nodes, &pbdag.NodeStatus{
+			Name:  node.GetName(),
+			State: pbdag.NodeState_NODE_STATE_PENDING,
+		})
+	}
+
+	if err := m.dagOps.Create(ctx, id, config, runtime); err != nil {
+		return nil, err
+	}
+
+	m.metrics.DAGCreate.Inc(1)
+	m.track(id, runtime, time.Now())
+	return id, nil
+}
+
+// Cancel sets the goal state of a DAG to KILLED. Cancelling a DAG
+// which reached a terminal state is a no-op.
+func (m *manager) Cancel(ctx context.Context, id *pbdag.DAGID) error {
+	m.lockDAG(id)
+	defer m.unlockDAG(id)
+
+	info, err := m.dagOps.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	runtime := info.GetRuntime()
+	if IsDAGStateTerminal(runtime.GetState()) ||
+		runtime.GetGoalState() == pbdag.DAGState_DAG_STATE_KILLED {
+		return nil
+	}
+
+	runtime.GoalState = pbdag.DAGState_DAG_STATE_KILLED
+	if err := m.dagOps.UpdateRuntime(ctx, id, runtime); err != nil {
+		return err
+	}
+
+	m.metrics.DAGCancel.Inc(1)
+	m.track(id, runtime, time.Now())
+	return nil
+}
+
+// track caches the runtime of a DAG and enqueues it into the goal
+// state engine.
+func (m *manager) track(
+	id *pbdag.DAGID,
+	runtime *pbdag.DAGRuntime,
+	deadline time.Time,
+) {
+	m.runtimeLock.Lock()
+	m.runtimes[id.GetValue()] = runtime
+	m.runtimeLock.Unlock()
+
+	m.engine.Enqueue(m.newEntity(id), deadline)
+}
+
+// untrack removes a DAG from the cache and the goal state engine.
+func (m *manager) untrack(id *pbdag.DAGID) {
+	m.runtimeLock.Lock()
+	delete(m.runtimes, id.GetValue())
+	m.runtimeLock.Unlock()
+
+	m.engine.Delete(m.newEntity(id))
+}
+
+// getRuntime returns the cached runtime of a DAG, or nil if the DAG
+// is not cached.
+func (m *manager) getRuntime(id *pbdag.DAGID) *pbdag.DAGRuntime {
+	m.runtimeLock.RLock()
+	defer m.runtimeLock.RUnlock()
+
+	return m.runtimes[id.GetValue()]
+}
+
+// evaluate reads a DAG from db, runs or kills it according to its goal
+// state and persists its runtime if it changed. The DAG is evaluated
+// again after the evaluation period until it reaches a terminal state.
+// The lock of the DAG is not held while the jobs of its nodes are
+// created or stopped, so that Cancel is not blocked by the calls.
+func (m *manager) evaluate(ctx context.Context, id *pbdag.DAGID) error {
+	if !m.isRunning() {
+		// the job manager lost leadership, the DAG is enqueued
+		// again by the next leader
+		m.untrack(id)
+		return nil
+	}
+
+	m.lockDAG(id)
+	info, err := m.dagOps.Get(ctx, id)
+	m.unlockDAG(id)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			m.untrack(id)
+			return nil
+		}
+		m.metrics.DAGEvaluateFail.Inc(1)
+		return err
+	}
+
+	if IsDAGStateTerminal(info.GetRuntime().GetState()) {
+		// the cached runtime is stale, untrack the DAG
+		m.track(id, info.GetRuntime(), time.Now())
+		return nil
+	}
+
+	runtime := proto.Clone(info.GetRuntime()).(*pbdag.DAGRuntime)
+	if runtime.GetGoalState() == pbdag.DAGState_DAG_STATE_KILLED {
+		m.kill(ctx, info, runtime)
+	} else {
+		m.run(ctx, info, runtime)
+	}
+
+	m.lockDAG(id)
+	defer m.unlockDAG(id)
+
+	// the DAG may have been cancelled while its lock was released,
+	// keep the goal state so that the jobs are killed by the next
+	// evaluation
+	if m.getRuntime(id).GetGoalState() == pbdag.DAGState_DAG_STATE_KILLED {
+		runtime.GoalState = pbdag.DAGState_DAG_STATE_KILLED
+	}
+
+	runtime.State = dagState(runtime)
+	if IsDAGStateTerminal(runtime.GetState()) {
+		runtime.CompletionTime = formatTime(m.now())
+		m.recordCompletion(id, runtime)
+	}
+
+	if !proto.Equal(runtime, info.GetRuntime()) {
+		if err := m.dagOps.UpdateRuntime(ctx, id, runtime); err != nil {
+			m.metrics.DAGEvaluateFail.Inc(1)
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(m.config.EvaluationPeriod)
+	if IsDAGStateTerminal(runtime.GetState()) {
+		deadline = time.Now()
+	}
+	m.track(id, runtime, deadline)
+	return nil
+}
+
+// run refreshes the states of the running nodes of a DAG from their
+// jobs and creates the jobs of the nodes which are ready. Nodes are
+// evaluated in topological order so that skipped nodes cascade to
+// their children in a single evaluation.
+func (m *manager) run(
+	ctx context.Context,
+	info *pbdag.DAGInfo,
+	runtime *pbdag.DAGRuntime,
+) {
+	m.refreshNodes(ctx, info.GetId(), runtime)
+
+	states := make(map[string]pbdag.NodeState)
+	statuses := make(map[string]*pbdag.NodeStatus)
+	for _, status := range runtime.GetNodes() {
+		states[status.GetName()] = status.GetState()
+		statuses[status.GetName()] = status
+	}
+
+	nodes := make(map[string]*pbdag.Node)
+	for _, node := range info.GetConfig().GetNodes() {
+		nodes[node.GetName()] = node
+	}
+
+	for _, name := range topologicalOrder(info.GetConfig()) {
+		status := statuses[name]
+		if status.GetState() != pbdag.NodeState_NODE_STATE_PENDING {
+			continue
+		}
+
+		ready, skip := evaluateNode(nodes[name], states)
+		switch {
+		case skip:
+			status.State = pbdag.NodeState_NODE_STATE_SKIPPED
+			status.Message = "conditions of the parent nodes not satisfied"
+			m.metrics.NodeSkip.Inc(1)
+		case ready:
+			m.createNode(ctx, info, nodes[name], status)
+		}
+		states[name] = status.GetState()
+	}
+}
+
+// kill kills the jobs of the running nodes of a DAG and marks its
+// pending nodes killed. The DAG is killed once all the jobs reached a
+// terminal state.
+func (m *manager) kill(
+	ctx context.Context,
+	info *pbdag.DAGInfo,
+	runtime *pbdag.DAGRuntime,
+) {
+	m.refreshNodes(ctx, info.GetId(), runtime)
+
+	for _, status := range runtime.GetNodes() {
+		switch status.GetState() {
+		case pbdag.NodeState_NODE_STATE_PENDING:
+			status.State = pbdag.NodeState_NODE_STATE_KILLED
+			status.Message = "DAG cancelled"
+		case pbdag.NodeState_NODE_STATE_RUNNING:
+			if err := m.stopJob(ctx, status.GetJobId()); err != nil {
+				m.metrics.JobStopFail.Inc(1)
+				status.Message = fmt.Sprintf("failed to stop job: %v", err)
+				continue
+			}
+			m.metrics.JobStopSuccess.Inc(1)
+		}
+	}
+}
+
+// refreshNodes updates the states of the running nodes of a DAG from
+// the states of their jobs.
+func (m *manager) refreshNodes(
+	ctx context.Context,
+	id *pbdag.DAGID,
+	runtime *pbdag.DAGRuntime,
+) {
+	for _, status := range runtime.GetNodes() {
+		if status.GetState() != pbdag.NodeState_NODE_STATE_RUNNING {
+			continue
+		}
+
+		jobRuntime, err := handlerutil.GetJobRuntimeWithoutFillingCache(
+			ctx, status.GetJobId(), m.jobFactory, m.jobRuntimeOps)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				// the job was deleted out of band
+				status.State = pbdag.NodeState_NODE_STATE_KILLED
+				status.Message = "job not found"
+				continue
+			}
+			log.WithError(err).
+				WithField("dag_id", id.GetValue()).
+				WithField("dag_node", status.GetName()).
+				WithField("job_id", status.GetJobId().GetValue()).
+				Warn("failed to get job runtime")
+			continue
+		}
+
+		if util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+			status.State = nodeStateFromJobState(jobRuntime.GetState())
+		}
+	}
+}
+
+// createNode creates the job of a node. The ID of the job is derived
+// from the IDs of the DAG and the node, so that an evaluation retried
+// after a failure does not create the job twice.
+func (m *manager) createNode(
+	ctx context.Context,
+	info *pbdag.DAGInfo,
+	node *pbdag.Node,
+	status *pbdag.NodeStatus,
+) {
+	jobConfig := proto.Clone(node.GetConfig()).(*job.JobConfig)
+	jobConfig.Type = job.JobType_BATCH
+	if len(jobConfig.GetName()) == 0 {
+		jobConfig.Name = fmt.Sprintf("%s-%s",
+			info.GetConfig().GetName(), node.GetName())
+	}
+	jobConfig.Labels = append(jobConfig.Labels,
+		&peloton.Label{
+			Key:   LabelKey,
+			Value: info.GetId().GetValue(),
+		},
+		&peloton.Label{
+			Key:   NodeLabelKey,
+			Value: node.GetName(),
+		},
+	)
+
+	jobID := &peloton.JobID{
+		Value: uuid.NewSHA1(
+			uuid.Parse(info.GetId().GetValue()),
+			[]byte(node.GetName())).String(),
+	}
+
+	resp, err := m.jobManager.Create(ctx, &job.CreateRequest{
+		Id:     jobID,
+		Config: jobConfig,
+	})
+	switch {
+	case err != nil && yarpcerrors.IsInvalidArgument(err),
+		resp.GetError().GetInvalidConfig() != nil:
+		// the job can never be created, fail the node
+		if err == nil {
+			err = errors.New(resp.GetError().GetInvalidConfig().GetMessage())
+		}
+		m.metrics.NodeCreateFail.Inc(1)
+		status.State = pbdag.NodeState_NODE_STATE_FAILED
+		status.Message = fmt.Sprintf("invalid job config: %v", err)
+		return
+
+	case err != nil:
+		m.metrics.NodeCreateFail.Inc(1)
+		status.Message = fmt.Sprintf("failed to create job: %v", err)
+		return
+
+	case resp.GetError().GetAlreadyExists() != nil:
+		// the job was created by a previous evaluation
+
+	case resp.GetError() != nil:
+		m.metrics.NodeCreateFail.Inc(1)
+		status.Message = fmt.Sprintf(
+			"failed to create job: %s", resp.GetError().String())
+		return
+	}
+
+	log.WithField("dag_id", info.GetId().GetValue()).
+		WithField("dag_node", node.GetName()).
+		WithField("job_id", jobID.GetValue()).
+		Info("DAG node created job")
+
+	m.metrics.NodeCreateSuccess.Inc(1)
+	status.State = pbdag.NodeState_NODE_STATE_RUNNING
+	status.JobId = jobID
+	status.Message = ""
+}
+
+// stopJob sets the goal state of a job to KILLED.
+func (m *manager) stopJob(ctx context.Context, jobID *peloton.JobID) error {
+	var count int
+	cachedJob := m.jobFactory.AddJob(jobID)
+	for {
+		jobRuntime, err := cachedJob.GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+
+		if jobRuntime.GetGoalState() == job.JobState_KILLED ||
+			util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+			return nil
+		}
+
+		jobRuntime.DesiredStateVersion++
+		jobRuntime.GoalState = job.JobState_KILLED
+
+		_, err = cachedJob.CompareAndSetRuntime(ctx, jobRuntime)
+		if err == nil {
+			break
+		}
+		if err == jobmgrcommon.UnexpectedVersionError {
+			// concurrency error; retry MaxConcurrencyErrorRetry times
+			count++
+			if count < jobmgrcommon.MaxConcurrencyErrorRetry {
+				continue
+			}
+		}
+		return err
+	}
+
+	m.goalStateDriver.EnqueueJob(jobID, time.Now())
+	return nil
+}
+
+// recordCompletion logs and counts a DAG which reached a terminal state.
+func (m *manager) recordCompletion(
+	id *pbdag.DAGID,
+	runtime *pbdag.DAGRuntime,
+) {
+	switch runtime.GetState() {
+	case pbdag.DAGState_DAG_STATE_SUCCEEDED:
+		m.metrics.DAGSucceeded.Inc(1)
+	case pbdag.DAGState_DAG_STATE_FAILED:
+		m.metrics.DAGFailed.Inc(1)
+	case pbdag.DAGState_DAG_STATE_KILLED:
+		m.metrics.DAGKilled.Inc(1)
+	}
+
+	log.WithField("dag_id", id.GetValue()).
+		WithField("state", runtime.GetState().String()).
+		Info("DAG reached terminal state")
+}
+
+// formatTime formats a time in RFC3339 format.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
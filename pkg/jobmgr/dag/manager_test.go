@@ -0,0 +1,614 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	jobmocks "github.com/uber/peloton/.gen/peloton/api/v0/job/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobgoalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type managerTestSuite struct {
+	suite.Suite
+
+	mockCtrl        *gomock.Controller
+	dagOps          *objectmocks.MockDAGOps
+	jobRuntimeOps   *objectmocks.MockJobRuntimeOps
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *jobgoalstatemocks.MockDriver
+	jobManager      *jobmocks.MockJobManagerYARPCServer
+	engine          *goalstatemocks.MockEngine
+	manager         *manager
+
+	now    time.Time
+	id     *pbdag.DAGID
+	config *pbdag.DAGConfig
+}
+
+func (suite *managerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.dagOps = objectmocks.NewMockDAGOps(suite.mockCtrl)
+	suite.jobRuntimeOps = objectmocks.NewMockJobRuntimeOps(suite.mockCtrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.mockCtrl)
+	suite.goalStateDriver = jobgoalstatemocks.NewMockDriver(suite.mockCtrl)
+	suite.jobManager = jobmocks.NewMockJobManagerYARPCServer(suite.mockCtrl)
+	suite.engine = goalstatemocks.NewMockEngine(suite.mockCtrl)
+
+	suite.now = time.Date(2019, 1, 1, 0, 10, 0, 0, time.UTC)
+	suite.manager = &manager{
+		dagOps:          suite.dagOps,
+		jobRuntimeOps:   suite.jobRuntimeOps,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		jobManager:      suite.jobManager,
+		engine:          suite.engine,
+		config: &Config{
+			EvaluationPeriod: time.Minute,
+			ReconcilePeriod:  time.Minute,
+		},
+		metrics: NewMetrics(tally.NoopScope),
+		now: func() time.Time {
+			return suite.now
+		},
+		running:  atomic.NewBool(true),
+		dagLocks: make(map[string]*dagLock),
+		runtimes: make(map[string]*pbdag.DAGRuntime),
+	}
+
+	// a -> b on success, a -> c on failure, b and c -> d always
+	suite.id = &pbdag.DAGID{Value: uuid.New()}
+	suite.config = &pbdag.DAGConfig{
+		Name: "dag",
+		Nodes: []*pbdag.Node{
+			newNode("a"),
+			newNode("b", onSuccess("a")),
+			newNode("c", onFailure("a")),
+			newNode("d", always("b"), always("c")),
+		},
+	}
+}
+
+func (suite *managerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestManager(t *testing.T) {
+	suite.Run(t, new(managerTestSuite))
+}
+
+// newRuntime returns a running DAG runtime with the given node states
+// in the order of the nodes of the test config
+func (suite *managerTestSuite) newRuntime(
+	states ...pbdag.NodeState,
+) *pbdag.DAGRuntime {
+	runtime := &pbdag.DAGRuntime{
+		State:        pbdag.DAGState_DAG_STATE_RUNNING,
+		GoalState:    pbdag.DAGState_DAG_STATE_RUNNING,
+		CreationTime: "2019-01-01T00:00:00Z",
+	}
+	for i, state := range states {
+		status := &pbdag.NodeStatus{
+			Name:  suite.config.GetNodes()[i].GetName(),
+			State: state,
+		}
+		if state != pbdag.NodeState_NODE_STATE_PENDING {
+			status.JobId = suite.nodeJobID(status.GetName())
+		}
+		runtime.Nodes = append(runtime.Nodes, status)
+	}
+	return runtime
+}
+
+// nodeJobID returns the ID of the job created for a node
+func (suite *managerTestSuite) nodeJobID(name string) *peloton.JobID {
+	return &peloton.JobID{
+		Value: uuid.NewSHA1(uuid.Parse(suite.id.GetValue()), []byte(name)).String(),
+	}
+}
+
+// expectGet sets up the DAG returned from the store
+func (suite *managerTestSuite) expectGet(runtime *pbdag.DAGRuntime) {
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(&pbdag.DAGInfo{
+			Id:      suite.id,
+			Config:  suite.config,
+			Runtime: runtime,
+		}, nil)
+}
+
+// expectJobState sets up the runtime returned for an uncached job
+func (suite *managerTestSuite) expectJobState(
+	name string,
+	state job.JobState,
+) {
+	jobID := suite.nodeJobID(name)
+	suite.jobFactory.EXPECT().GetJob(jobID).Return(nil)
+	suite.jobRuntimeOps.EXPECT().Get(gomock.Any(), jobID).
+		Return(&job.RuntimeInfo{State: state}, nil)
+}
+
+// expectCreate sets up job manager to create the job of a node
+func (suite *managerTestSuite) expectCreate(name string) {
+	suite.jobManager.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			req *job.CreateRequest,
+		) (*job.CreateResponse, error) {
+			suite.Equal(suite.nodeJobID(name), req.GetId())
+			suite.Equal(job.JobType_BATCH, req.GetConfig().GetType())
+			suite.Equal("dag-"+name, req.GetConfig().GetName())
+			suite.Equal([]*peloton.Label{
+				{Key: LabelKey, Value: suite.id.GetValue()},
+				{Key: NodeLabelKey, Value: name},
+			}, req.GetConfig().GetLabels())
+			return &job.CreateResponse{JobId: req.GetId()}, nil
+		})
+}
+
+// expectUpdate captures the runtime persisted by an evaluation
+func (suite *managerTestSuite) expectUpdate() *pbdag.DAGRuntime {
+	updated := &pbdag.DAGRuntime{}
+	suite.dagOps.EXPECT().
+		UpdateRuntime(gomock.Any(), suite.id, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *pbdag.DAGID,
+			runtime *pbdag.DAGRuntime,
+		) error {
+			*updated = *runtime
+			return nil
+		})
+	return updated
+}
+
+// nodeStates returns the states of the nodes of a DAG runtime
+func nodeStates(runtime *pbdag.DAGRuntime) []pbdag.NodeState {
+	var states []pbdag.NodeState
+	for _, node := range runtime.GetNodes() {
+		states = append(states, node.GetState())
+	}
+	return states
+}
+
+// TestCreate tests creating a DAG
+func (suite *managerTestSuite) TestCreate() {
+	suite.dagOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), suite.config, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *pbdag.DAGID,
+			_ *pbdag.DAGConfig,
+			runtime *pbdag.DAGRuntime,
+		) error {
+			suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING, runtime.GetState())
+			suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING, runtime.GetGoalState())
+			suite.Equal("2019-01-01T00:10:00Z", runtime.GetCreationTime())
+			suite.Len(runtime.GetNodes(), 4)
+			for _, node := range runtime.GetNodes() {
+				suite.Equal(pbdag.NodeState_NODE_STATE_PENDING, node.GetState())
+			}
+			return nil
+		})
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	id, err := suite.manager.Create(context.Background(), suite.config)
+	suite.NoError(err)
+	suite.NotEmpty(id.GetValue())
+	suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING,
+		suite.manager.getRuntime(id).GetState())
+}
+
+// TestCreateInvalid tests creating a DAG with an invalid config
+func (suite *managerTestSuite) TestCreateInvalid() {
+	suite.config.Nodes[0].Parents = []*pbdag.Edge{onSuccess("d")}
+	_, err := suite.manager.Create(context.Background(), suite.config)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestCreateStoreError tests a store failure while creating a DAG
+func (suite *managerTestSuite) TestCreateStoreError() {
+	suite.dagOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), suite.config, gomock.Any()).
+		Return(errors.New("create failed"))
+	_, err := suite.manager.Create(context.Background(), suite.config)
+	suite.Error(err)
+}
+
+// TestEvaluateCreatesRoots tests that the nodes without parents are
+// created first
+func (suite *managerTestSuite) TestEvaluateCreatesRoots() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.expectCreate("a")
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal([]pbdag.NodeState{
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	}, nodeStates(updated))
+	suite.Equal(suite.nodeJobID("a"), updated.GetNodes()[0].GetJobId())
+	suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING, updated.GetState())
+}
+
+// TestEvaluateFailureBranch tests that a failed node skips its
+// ON_SUCCESS children and runs its ON_FAILURE and ALWAYS descendants
+func (suite *managerTestSuite) TestEvaluateFailureBranch() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.expectJobState("a", job.JobState_FAILED)
+	suite.expectCreate("c")
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal([]pbdag.NodeState{
+		pbdag.NodeState_NODE_STATE_FAILED,
+		pbdag.NodeState_NODE_STATE_SKIPPED,
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	}, nodeStates(updated))
+}
+
+// TestEvaluateComplete tests that a DAG completes once all its nodes
+// reached a terminal state
+func (suite *managerTestSuite) TestEvaluateComplete() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_SUCCEEDED,
+		pbdag.NodeState_NODE_STATE_SUCCEEDED,
+		pbdag.NodeState_NODE_STATE_SKIPPED,
+		pbdag.NodeState_NODE_STATE_RUNNING,
+	))
+	suite.expectJobState("d", job.JobState_SUCCEEDED)
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal(pbdag.DAGState_DAG_STATE_SUCCEEDED, updated.GetState())
+	suite.Equal("2019-01-01T00:10:00Z", updated.GetCompletionTime())
+	suite.Equal(pbdag.DAGState_DAG_STATE_SUCCEEDED,
+		suite.manager.getRuntime(suite.id).GetState())
+}
+
+// TestEvaluateNoChange tests that an unchanged runtime is not persisted
+func (suite *managerTestSuite) TestEvaluateNoChange() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.expectJobState("a", job.JobState_RUNNING)
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+}
+
+// TestEvaluateJobAlreadyExists tests that a job created by a previous
+// evaluation is adopted by the node
+func (suite *managerTestSuite) TestEvaluateJobAlreadyExists() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.jobManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(&job.CreateResponse{
+			Error: &job.CreateResponse_Error{
+				AlreadyExists: &job.JobAlreadyExists{},
+			},
+		}, nil)
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal(pbdag.NodeState_NODE_STATE_RUNNING,
+		updated.GetNodes()[0].GetState())
+	suite.Equal(suite.nodeJobID("a"), updated.GetNodes()[0].GetJobId())
+}
+
+// TestEvaluateCreateFailure tests the failures to create the job of a
+// node
+func (suite *managerTestSuite) TestEvaluateCreateFailure() {
+	// a transient failure keeps the node pending
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.jobManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("not leader"))
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal(pbdag.NodeState_NODE_STATE_PENDING,
+		updated.GetNodes()[0].GetState())
+	suite.NotEmpty(updated.GetNodes()[0].GetMessage())
+
+	// an invalid job config fails the node
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	suite.jobManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(&job.CreateResponse{
+			Error: &job.CreateResponse_Error{
+				InvalidConfig: &job.InvalidJobConfig{Message: "bad config"},
+			},
+		}, nil)
+	suite.expectCreate("c")
+	updated = suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal([]pbdag.NodeState{
+		pbdag.NodeState_NODE_STATE_FAILED,
+		pbdag.NodeState_NODE_STATE_SKIPPED,
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	}, nodeStates(updated))
+}
+
+// TestEvaluateStoreError tests that a store failure is returned so that
+// the goal state engine retries the evaluation
+func (suite *managerTestSuite) TestEvaluateStoreError() {
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(nil, errors.New("get failed"))
+	suite.Error(suite.manager.evaluate(context.Background(), suite.id))
+
+	// a deleted DAG is untracked
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+	suite.engine.EXPECT().Delete(gomock.Any())
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+}
+
+// TestEvaluateNotLeader tests that DAGs are untracked once the
+// background work of the manager stopped
+func (suite *managerTestSuite) TestEvaluateNotLeader() {
+	suite.manager.running.Store(false)
+	suite.engine.EXPECT().Delete(gomock.Any())
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+}
+
+// TestEvaluateKill tests that evaluating a cancelled DAG kills the jobs
+// of its running nodes and the pending nodes
+func (suite *managerTestSuite) TestEvaluateKill() {
+	runtime := suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	)
+	runtime.GoalState = pbdag.DAGState_DAG_STATE_KILLED
+	suite.expectGet(runtime)
+	suite.expectJobState("a", job.JobState_RUNNING)
+
+	jobID := suite.nodeJobID("a")
+	cachedJob := cachedmocks.NewMockJob(suite.mockCtrl)
+	suite.jobFactory.EXPECT().AddJob(jobID).Return(cachedJob)
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:     job.JobState_RUNNING,
+			GoalState: job.JobState_SUCCEEDED,
+		}, nil)
+	cachedJob.EXPECT().CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			jobRuntime *job.RuntimeInfo,
+		) (*job.RuntimeInfo, error) {
+			suite.Equal(job.JobState_KILLED, jobRuntime.GetGoalState())
+			return jobRuntime, nil
+		})
+	suite.goalStateDriver.EXPECT().EnqueueJob(jobID, gomock.Any())
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal([]pbdag.NodeState{
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_KILLED,
+		pbdag.NodeState_NODE_STATE_KILLED,
+	}, nodeStates(updated))
+	suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING, updated.GetState())
+
+	// the DAG is killed once the job of the running node is killed
+	suite.expectGet(updated)
+	suite.expectJobState("a", job.JobState_KILLED)
+	updated = suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal(pbdag.DAGState_DAG_STATE_KILLED, updated.GetState())
+}
+
+// TestCancel tests cancelling a DAG
+func (suite *managerTestSuite) TestCancel() {
+	suite.expectGet(suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_RUNNING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	))
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(suite.manager.Cancel(context.Background(), suite.id))
+	suite.Equal(pbdag.DAGState_DAG_STATE_KILLED, updated.GetGoalState())
+	suite.Equal(pbdag.DAGState_DAG_STATE_RUNNING, updated.GetState())
+}
+
+// TestCancelDuringEvaluate tests that a DAG can be cancelled while the
+// jobs of its nodes are being created, and that the evaluation keeps
+// the goal state set by the cancellation
+func (suite *managerTestSuite) TestCancelDuringEvaluate() {
+	pending := suite.newRuntime(
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+		pbdag.NodeState_NODE_STATE_PENDING,
+	)
+	suite.expectGet(pending)
+	suite.expectGet(pending)
+	suite.jobManager.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			req *job.CreateRequest,
+		) (*job.CreateResponse, error) {
+			suite.NoError(suite.manager.Cancel(ctx, suite.id))
+			return &job.CreateResponse{JobId: req.GetId()}, nil
+		})
+	// the runtimes persisted by the cancellation and the evaluation
+	cancelled := suite.expectUpdate()
+	updated := suite.expectUpdate()
+	suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Times(2)
+
+	suite.NoError(suite.manager.evaluate(context.Background(), suite.id))
+	suite.Equal(pbdag.DAGState_DAG_STATE_KILLED, cancelled.GetGoalState())
+	suite.Equal(pbdag.DAGState_DAG_STATE_KILLED, updated.GetGoalState())
+	suite.Equal(pbdag.NodeState_NODE_STATE_RUNNING,
+		updated.GetNodes()[0].GetState())
+	suite.Empty(suite.manager.dagLocks)
+}
+
+// TestCancelTerminal tests that cancelling a completed DAG is a no-op
+func (suite *managerTestSuite) TestCancelTerminal() {
+	runtime := suite.newRuntime(pbdag.NodeState_NODE_STATE_SUCCEEDED)
+	runtime.State = pbdag.DAGState_DAG_STATE_SUCCEEDED
+	suite.expectGet(runtime)
+
+	suite.NoError(suite.manager.Cancel(context.Background(), suite.id))
+}
+
+// TestCancelNotFound tests cancelling an unknown DAG
+func (suite *managerTestSuite) TestCancelNotFound() {
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+
+	err := suite.manager.Cancel(context.Background(), suite.id)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestReconcile tests that the running DAGs which are not scheduled
+// are enqueued into the goal state engine
+func (suite *managerTestSuite) TestReconcile() {
+	running := &pbdag.DAGInfo{
+		Id:      suite.id,
+		Config:  suite.config,
+		Runtime: suite.newRuntime(pbdag.NodeState_NODE_STATE_RUNNING),
+	}
+	scheduled := &pbdag.DAGInfo{
+		Id:      &pbdag.DAGID{Value: uuid.New()},
+		Config:  suite.config,
+		Runtime: suite.newRuntime(pbdag.NodeState_NODE_STATE_RUNNING),
+	}
+	completed := &pbdag.DAGInfo{
+		Id:     &pbdag.DAGID{Value: uuid.New()},
+		Config: suite.config,
+		Runtime: &pbdag.DAGRuntime{
+			State: pbdag.DAGState_DAG_STATE_FAILED,
+		},
+	}
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbdag.DAGInfo{running, scheduled, completed}, nil)
+	gomock.InOrder(
+		suite.engine.EXPECT().IsScheduled(gomock.Any()).Return(false),
+		suite.engine.EXPECT().Enqueue(gomock.Any(), gomock.Any()).
+			Do(func(entity interface{}, _ time.Time) {
+				suite.Equal(suite.id.GetValue(),
+					entity.(*dagEntity).GetID())
+			}),
+		suite.engine.EXPECT().IsScheduled(gomock.Any()).Return(true),
+	)
+
+	flag := atomic.NewBool(true)
+	suite.manager.reconcile(flag)
+	suite.Equal(flag, suite.manager.running)
+
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).
+		Return(nil, errors.New("get all failed"))
+	suite.manager.reconcile(flag)
+}
+
+// TestGetActionList tests the actions of the DAG goal state entity
+func (suite *managerTestSuite) TestGetActionList() {
+	entity := suite.manager.newEntity(suite.id)
+
+	getActions := func() []string {
+		_, cancel, actions := entity.GetActionList(
+			entity.GetState(), entity.GetGoalState())
+		cancel()
+		var names []string
+		for _, action := range actions {
+			names = append(names, action.Name)
+		}
+		return names
+	}
+
+	// an uncached DAG is evaluated from db
+	suite.Equal([]string{string(EvaluateDAGAction)}, getActions())
+
+	suite.manager.runtimes[suite.id.GetValue()] = &pbdag.DAGRuntime{
+		State: pbdag.DAGState_DAG_STATE_RUNNING,
+	}
+	suite.Equal([]string{string(EvaluateDAGAction)}, getActions())
+
+	suite.manager.runtimes[suite.id.GetValue()] = &pbdag.DAGRuntime{
+		State: pbdag.DAGState_DAG_STATE_SUCCEEDED,
+	}
+	suite.Equal([]string{string(UntrackDAGAction)}, getActions())
+
+	// untracking removes the DAG from the cache and the engine
+	suite.engine.EXPECT().Delete(gomock.Any())
+	suite.NoError(DAGUntrack(context.Background(), entity))
+	suite.Nil(suite.manager.getRuntime(suite.id))
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of the DAG manager.
+type Metrics struct {
+	ReconcileSuccess tally.Counter
+	ReconcileFail    tally.Counter
+
+	DAGCreate       tally.Counter
+	DAGCancel       tally.Counter
+	DAGSucceeded    tally.Counter
+	DAGFailed       tally.Counter
+	DAGKilled       tally.Counter
+	DAGEvaluateFail tally.Counter
+
+	NodeCreateSuccess tally.Counter
+	NodeCreateFail    tally.Counter
+	NodeSkip          tally.Counter
+
+	JobStopSuccess tally.Counter
+	JobStopFail    tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		ReconcileSuccess: successScope.Counter("reconcile"),
+		ReconcileFail:    failScope.Counter("reconcile"),
+
+		DAGCreate:       scope.Counter("dag_create"),
+		DAGCancel:       scope.Counter("dag_cancel"),
+		DAGSucceeded:    scope.Counter("dag_succeeded"),
+		DAGFailed:       scope.Counter("dag_failed"),
+		DAGKilled:       scope.Counter("dag_killed"),
+		DAGEvaluateFail: failScope.Counter("dag_evaluate"),
+
+		NodeCreateSuccess: successScope.Counter("node_create"),
+		NodeCreateFail:    failScope.Counter("node_create"),
+		NodeSkip:          scope.Counter("node_skip"),
+
+		JobStopSuccess: successScope.Counter("job_stop"),
+		JobStopFail:    failScope.Counter("job_stop"),
+	}
+}
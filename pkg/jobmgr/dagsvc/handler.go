@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"context"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	pbdagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/jobmgr/dag"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// InitServiceHandler initializes the DAG service.
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+	manager dag.Manager,
+	candidate leader.Candidate,
+	clientName string,
+) {
+	handler := &serviceHandler{
+		dagOps:        ormobjects.NewDAGOps(ormStore),
+		respoolClient: respool.NewResourceManagerYARPCClient(d.ClientConfig(clientName)),
+		manager:       manager,
+		candidate:     candidate,
+		metrics:       NewMetrics(parent.SubScope("jobmgr").SubScope("dag")),
+	}
+
+	d.Register(pbdagsvc.BuildDAGServiceYARPCProcedures(handler))
+}
+
+// serviceHandler implements peloton.api.v0.dag.svc.DAGService
+type serviceHandler struct {
+	dagOps        ormobjects.DAGOps
+	respoolClient respool.ResourceManagerYARPCClient
+	manager       dag.Manager
+	candidate     leader.Candidate
+	metrics       *Metrics
+}
+
+// checkLeader returns an error if the current node is not the leader.
+// Changes to DAGs go through the DAG manager which only runs on the
+// leader.
+func (h *serviceHandler) checkLeader(api string) error {
+	if !h.candidate.IsLeader() {
+		return yarpcerrors.UnavailableErrorf(
+			"DAG %s API not suppported on non-leader", api)
+	}
+	return nil
+}
+
+// checkAccess returns a permission denied error if the user in ctx is
+// not permitted on the resource pools and owners of the jobs of the
+// nodes of a DAG. The DAG manager creates the jobs without the user, so
+// the access is checked when the DAG is created or cancelled.
+func (h *serviceHandler) checkAccess(
+	ctx context.Context,
+	config *pbdag.DAGConfig,
+) error {
+	if _, ok := auth.ResourceUserFromContext(ctx); !ok {
+		return nil
+	}
+
+	// the nodes of a DAG usually share a few resource pools
+	respoolPaths := make(map[string]string)
+	for _, node := range config.GetNodes() {
+		respoolID := node.GetConfig().GetRespoolID()
+		respoolPath, ok := respoolPaths[respoolID.GetValue()]
+		if !ok {
+			var err error
+			respoolPath, err = handlerutil.GetResourcePoolPath(
+				ctx,
+				h.respoolClient,
+				respoolID,
+			)
+			if err != nil {
+				return err
+			}
+			respoolPaths[respoolID.GetValue()] = respoolPath
+		}
+
+		if err := handlerutil.CheckResourceAccess(ctx, &auth.Resource{
+			ResourcePoolPath: respoolPath,
+			Owner:            node.GetConfig().GetOwningTeam(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userName returns the name of the user in ctx, or an empty name if
+// the user cannot be identified.
+func userName(ctx context.Context) string {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if namedUser, ok := user.(auth.NamedUser); ok {
+		return namedUser.Name()
+	}
+	return ""
+}
+
+// CreateDAG creates a DAG.
+func (h *serviceHandler) CreateDAG(
+	ctx context.Context,
+	req *pbdagsvc.CreateDAGRequest,
+) (*pbdagsvc.CreateDAGResponse, error) {
+	h.metrics.DAGAPICreate.Inc(1)
+
+	if err := h.checkLeader("Create"); err != nil {
+		h.metrics.DAGCreateFail.Inc(1)
+		return nil, err
+	}
+
+	if err := h.checkAccess(ctx, req.GetConfig()); err != nil {
+		h.metrics.DAGCreateFail.Inc(1)
+		return nil, err
+	}
+
+	if req.GetConfig() != nil {
+		req.GetConfig().Owner = userName(ctx)
+	}
+
+	id, err := h.manager.Create(ctx, req.GetConfig())
+	if err != nil {
+		log.WithError(err).
+			WithField("dag", req.GetConfig().GetName()).
+			Warn("DAGService.CreateDAG failed")
+		h.metrics.DAGCreateFail.Inc(1)
+		return nil, err
+	}
+
+	log.WithField("dag", req.GetConfig().GetName()).
+		WithField("dag_id", id.GetValue()).
+		WithField("owner", req.GetConfig().GetOwner()).
+		Info("DAGService.CreateDAG succeeded")
+	h.metrics.DAGCreate.Inc(1)
+	return &pbdagsvc.CreateDAGResponse{Id: id}, nil
+}
+
+// GetDAG returns a DAG.
+func (h *serviceHandler) GetDAG(
+	ctx context.Context,
+	req *pbdagsvc.GetDAGRequest,
+) (*pbdagsvc.GetDAGResponse, error) {
+	h.metrics.DAGAPIGet.Inc(1)
+
+	info, err := h.dagOps.Get(ctx, req.GetId())
+	if err != nil {
+		h.metrics.DAGGetFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.DAGGet.Inc(1)
+	return &pbdagsvc.GetDAGResponse{Dag: info}, nil
+}
+
+// ListDAGs returns all DAGs.
+func (h *serviceHandler) ListDAGs(
+	ctx context.Context,
+	req *pbdagsvc.ListDAGsRequest,
+) (*pbdagsvc.ListDAGsResponse, error) {
+	h.metrics.DAGAPIList.Inc(1)
+
+	infos, err := h.dagOps.GetAll(ctx)
+	if err != nil {
+		h.metrics.DAGListFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.DAGList.Inc(1)
+	return &pbdagsvc.ListDAGsResponse{Dags: infos}, nil
+}
+
+// CancelDAG cancels a DAG.
+func (h *serviceHandler) CancelDAG(
+	ctx context.Context,
+	req *pbdagsvc.CancelDAGRequest,
+) (*pbdagsvc.CancelDAGResponse, error) {
+	h.metrics.DAGAPICancel.Inc(1)
+
+	if err := h.checkLeader("Cancel"); err != nil {
+		h.metrics.DAGCancelFail.Inc(1)
+		return nil, err
+	}
+
+	if _, ok := auth.ResourceUserFromContext(ctx); ok {
+		info, err := h.dagOps.Get(ctx, req.GetId())
+		if err != nil {
+			h.metrics.DAGCancelFail.Inc(1)
+			return nil, err
+		}
+		if err := h.checkAccess(ctx, info.GetConfig()); err != nil {
+			h.metrics.DAGCancelFail.Inc(1)
+			return nil, err
+		}
+	}
+
+	if err := h.manager.Cancel(ctx, req.GetId()); err != nil {
+		log.WithError(err).
+			WithField("dag_id", req.GetId().GetValue()).
+			Warn("DAGService.CancelDAG failed")
+		h.metrics.DAGCancelFail.Inc(1)
+		return nil, err
+	}
+
+	log.WithField("dag_id", req.GetId().GetValue()).
+		Info("DAGService.CancelDAG succeeded")
+	h.metrics.DAGCancel.Inc(1)
+	return &pbdagsvc.CancelDAGResponse{}, nil
+}
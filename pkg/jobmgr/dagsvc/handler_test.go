@@ -0,0 +1,288 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"context"
+	"testing"
+
+	pbdag "github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	pbdagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	dagmocks "github.com/uber/peloton/pkg/jobmgr/dag/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type handlerTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	dagOps        *objectmocks.MockDAGOps
+	respoolClient *respoolmocks.MockResourceManagerYARPCClient
+	manager       *dagmocks.MockManager
+	candidate     *leadermocks.MockCandidate
+	handler       *serviceHandler
+
+	id     *pbdag.DAGID
+	config *pbdag.DAGConfig
+}
+
+func (suite *handlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.dagOps = objectmocks.NewMockDAGOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.manager = dagmocks.NewMockManager(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		dagOps:        suite.dagOps,
+		respoolClient: suite.respoolClient,
+		manager:       suite.manager,
+		candidate:     suite.candidate,
+		metrics:       NewMetrics(tally.NoopScope),
+	}
+
+	suite.id = &pbdag.DAGID{Value: "dag-id"}
+	suite.config = &pbdag.DAGConfig{
+		Name: "dag",
+		Nodes: []*pbdag.Node{
+			{
+				Name: "a",
+				Config: &job.JobConfig{
+					Type:          job.JobType_BATCH,
+					InstanceCount: 1,
+					OwningTeam:    "team-b",
+					RespoolID:     &peloton.ResourcePoolID{Value: "respool"},
+				},
+			},
+			{
+				Name: "b",
+				Config: &job.JobConfig{
+					Type:          job.JobType_BATCH,
+					InstanceCount: 1,
+					OwningTeam:    "team-b",
+					RespoolID:     &peloton.ResourcePoolID{Value: "respool"},
+				},
+			},
+		},
+	}
+}
+
+func (suite *handlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestDAGServiceHandler(t *testing.T) {
+	suite.Run(t, new(handlerTestSuite))
+}
+
+// TestCreateDAG tests creating a DAG
+func (suite *handlerTestSuite) TestCreateDAG() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.manager.EXPECT().Create(gomock.Any(), suite.config).
+		Return(suite.id, nil)
+
+	resp, err := suite.handler.CreateDAG(
+		context.Background(),
+		&pbdagsvc.CreateDAGRequest{Config: suite.config},
+	)
+	suite.NoError(err)
+	suite.Equal(suite.id, resp.GetId())
+}
+
+// testUser is an authenticated user with a name, whose permissions
+// are not scoped to resources
+type testUser struct {
+	name string
+}
+
+func (u *testUser) IsPermitted(procedure string) bool { return true }
+
+func (u *testUser) Name() string { return u.name }
+
+// TestCreateDAGOwner tests that the user who creates a DAG is
+// recorded as its owner
+func (suite *handlerTestSuite) TestCreateDAGOwner() {
+	ctx := auth.ContextWithUser(context.Background(), &testUser{name: "alice"})
+	suite.config.Owner = "bob"
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.manager.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			config *pbdag.DAGConfig,
+		) (*pbdag.DAGID, error) {
+			suite.Equal("alice", config.GetOwner())
+			return suite.id, nil
+		})
+
+	_, err := suite.handler.CreateDAG(
+		ctx,
+		&pbdagsvc.CreateDAGRequest{Config: suite.config},
+	)
+	suite.NoError(err)
+}
+
+// TestDAGResourceAccess tests that the resource pools and owners of
+// the jobs of the nodes are checked when a DAG is created or cancelled
+func (suite *handlerTestSuite) TestDAGResourceAccess() {
+	user := authmocks.NewMockResourceUser(suite.ctrl)
+	ctx := auth.ContextWithUser(context.Background(), user)
+	resource := &auth.Resource{
+		ResourcePoolPath: "/infra/teamB",
+		Owner:            "team-b",
+	}
+
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(2)
+	// the resource pool shared by the nodes is resolved once per call
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: "respool"},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   &peloton.ResourcePoolID{Value: "respool"},
+				Path: &respool.ResourcePoolPath{Value: "/infra/teamB"},
+			},
+		}, nil).
+		Times(2)
+
+	// the user is permitted to create the DAG
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), resource).
+		Return(true).
+		Times(2)
+	suite.manager.EXPECT().Create(gomock.Any(), suite.config).
+		Return(suite.id, nil)
+
+	_, err := suite.handler.CreateDAG(
+		ctx,
+		&pbdagsvc.CreateDAGRequest{Config: suite.config},
+	)
+	suite.NoError(err)
+
+	// the user is not permitted to cancel the DAG any more
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(&pbdag.DAGInfo{Id: suite.id, Config: suite.config}, nil)
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), resource).
+		Return(false)
+
+	_, err = suite.handler.CancelDAG(
+		ctx,
+		&pbdagsvc.CancelDAGRequest{Id: suite.id},
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestCreateDAGNonLeader tests creating a DAG on a non-leader
+func (suite *handlerTestSuite) TestCreateDAGNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.CreateDAG(
+		context.Background(),
+		&pbdagsvc.CreateDAGRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestCreateDAGInvalid tests creating an invalid DAG
+func (suite *handlerTestSuite) TestCreateDAGInvalid() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.manager.EXPECT().Create(gomock.Any(), suite.config).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("DAG has a cycle"))
+
+	_, err := suite.handler.CreateDAG(
+		context.Background(),
+		&pbdagsvc.CreateDAGRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestGetAndListDAGs tests reading DAGs
+func (suite *handlerTestSuite) TestGetAndListDAGs() {
+	info := &pbdag.DAGInfo{
+		Id:     suite.id,
+		Config: suite.config,
+		Runtime: &pbdag.DAGRuntime{
+			State: pbdag.DAGState_DAG_STATE_RUNNING,
+		},
+	}
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).Return(info, nil)
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).
+		Return([]*pbdag.DAGInfo{info}, nil)
+
+	getResp, err := suite.handler.GetDAG(
+		context.Background(),
+		&pbdagsvc.GetDAGRequest{Id: suite.id},
+	)
+	suite.NoError(err)
+	suite.Equal(info, getResp.GetDag())
+
+	listResp, err := suite.handler.ListDAGs(
+		context.Background(),
+		&pbdagsvc.ListDAGsRequest{},
+	)
+	suite.NoError(err)
+	suite.Equal([]*pbdag.DAGInfo{info}, listResp.GetDags())
+}
+
+// TestGetDAGNotFound tests getting an unknown DAG
+func (suite *handlerTestSuite) TestGetDAGNotFound() {
+	suite.dagOps.EXPECT().Get(gomock.Any(), suite.id).
+		Return(nil, yarpcerrors.NotFoundErrorf("test error"))
+
+	_, err := suite.handler.GetDAG(
+		context.Background(),
+		&pbdagsvc.GetDAGRequest{Id: suite.id},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestCancelDAG tests cancelling a DAG
+func (suite *handlerTestSuite) TestCancelDAG() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.manager.EXPECT().Cancel(gomock.Any(), suite.id).Return(nil)
+
+	_, err := suite.handler.CancelDAG(
+		context.Background(),
+		&pbdagsvc.CancelDAGRequest{Id: suite.id},
+	)
+	suite.NoError(err)
+}
+
+// TestCancelDAGFailure tests failing to cancel a DAG
+func (suite *handlerTestSuite) TestCancelDAGFailure() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.manager.EXPECT().Cancel(gomock.Any(), suite.id).
+		Return(yarpcerrors.NotFoundErrorf("test error"))
+
+	_, err := suite.handler.CancelDAG(
+		context.Background(),
+		&pbdagsvc.CancelDAGRequest{Id: suite.id},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track
+// internal state of the DAG service
+type Metrics struct {
+	DAGAPICreate  tally.Counter
+	DAGCreate     tally.Counter
+	DAGCreateFail tally.Counter
+
+	DAGAPIGet  tally.Counter
+	DAGGet     tally.Counter
+	DAGGetFail tally.Counter
+
+	DAGAPIList  tally.Counter
+	DAGList     tally.Counter
+	DAGListFail tally.Counter
+
+	DAGAPICancel  tally.Counter
+	DAGCancel     tally.Counter
+	DAGCancelFail tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+	apiScope := scope.SubScope("api")
+
+	return &Metrics{
+		DAGAPICreate:  apiScope.Counter("create"),
+		DAGCreate:     successScope.Counter("create"),
+		DAGCreateFail: failScope.Counter("create"),
+
+		DAGAPIGet:  apiScope.Counter("get"),
+		DAGGet:     successScope.Counter("get"),
+		DAGGetFail: failScope.Counter("get"),
+
+		DAGAPIList:  apiScope.Counter("list"),
+		DAGList:     successScope.Counter("list"),
+		DAGListFail: failScope.Counter("list"),
+
+		DAGAPICancel:  apiScope.Counter("cancel"),
+		DAGCancel:     successScope.Counter("cancel"),
+		DAGCancelFail: failScope.Counter("cancel"),
+	}
+}
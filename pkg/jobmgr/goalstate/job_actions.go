@@ -51,8 +51,8 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 			// if config is not found, untrack the job from cache
 			return err
 		}
-	} else if jobConfig.GetType() == job.JobType_SERVICE {
-		// service jobs are always active and never untracked.
+	} else if util.IsLongRunningJobType(jobConfig.GetType()) {
+		// service and daemon jobs are always active and never untracked.
 		// Call runtime updater, because job runtime can change
 		// when an update is running on the job.
 		return JobRuntimeUpdater(ctx, entity)
@@ -361,7 +361,7 @@ func EnqueueJobUpdate(
 		return err
 	}
 
-	if jobConfig.GetType() != job.JobType_SERVICE {
+	if !util.IsLongRunningJobType(jobConfig.GetType()) {
 		return nil
 	}
 
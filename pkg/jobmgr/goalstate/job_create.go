@@ -146,11 +146,29 @@ func sendTasksToResMgr(
 	if len(tasks) == 0 {
 		return nil
 	}
+
+	var jobRuntime *job.RuntimeInfo
+	if jobConfig.GetSLA().GetMaxRunningTime() > 0 {
+		cachedJob := goalStateDriver.jobFactory.GetJob(jobID)
+		if cachedJob == nil {
+			return yarpcerrors.AbortedErrorf("failed to get job from cache")
+		}
+		var err error
+		if jobRuntime, err = getJobRuntimeForDeadline(
+			ctx, cachedJob, jobConfig); err != nil {
+			log.WithError(err).
+				WithField("job_id", jobID.GetValue()).
+				Error("failed to get job runtime")
+			return err
+		}
+	}
+
 	// Send tasks to resource manager
 	response, err := jobmgr_task.EnqueueGangs(
 		ctx,
 		tasks,
 		jobConfig,
+		jobRuntime,
 		goalStateDriver.resmgrClient)
 
 	if err != nil {
@@ -312,6 +330,20 @@ func recoverTasks(
 	return sendTasksToResMgr(ctx, jobID, tasks, jobConfig, goalStateDriver)
 }
 
+// getJobRuntimeForDeadline returns the runtime of the job, which holds the
+// submission time the deadline of its tasks is computed from. The runtime
+// is only read for the jobs with a max running time, the tasks of the
+// other jobs have no deadline.
+func getJobRuntimeForDeadline(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig jobmgrcommon.JobConfig) (*job.RuntimeInfo, error) {
+	if jobConfig.GetSLA().GetMaxRunningTime() == 0 {
+		return nil, nil
+	}
+	return cachedJob.GetRuntime(ctx)
+}
+
 // createAndEnqueueTasks creates all tasks in the job and enqueues them to resource manager.
 func createAndEnqueueTasks(
 	ctx context.Context,
@@ -614,7 +614,7 @@ func (suite *JobCreateTestSuite) TestJobCreateExistTasks() {
 			InstanceId: uint32(i),
 			JobId:      suite.jobID,
 		}
-		resmgrTasks = append(resmgrTasks, taskutil.ConvertTaskToResMgrTask(taskInfo, suite.jobConfig))
+		resmgrTasks = append(resmgrTasks, taskutil.ConvertTaskToResMgrTask(taskInfo, suite.jobConfig, nil))
 	}
 
 	wrongTask := &pbtask.TaskInfo{
@@ -625,7 +625,7 @@ func (suite *JobCreateTestSuite) TestJobCreateExistTasks() {
 		InstanceId: uint32(0),
 		JobId:      &peloton.JobID{Value: uuid.NewRandom().String()},
 	}
-	resmgrTasks = append(resmgrTasks, taskutil.ConvertTaskToResMgrTask(wrongTask, suite.jobConfig))
+	resmgrTasks = append(resmgrTasks, taskutil.ConvertTaskToResMgrTask(wrongTask, suite.jobConfig, nil))
 
 	failedGangs := []*resmgrsvc.EnqueueGangsFailure_FailedTask{
 		{
@@ -270,12 +270,20 @@ func (d *jobStateDeterminer) getState(
 			return job.JobState_PENDING, nil
 		}
 
-		if d.config.GetType() == job.JobType_SERVICE &&
+		if util.IsLongRunningJobType(d.config.GetType()) &&
 			!util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) {
 			return job.JobState_PENDING, nil
 		}
 	}
 
+	// a daemon job without any eligible host has no instances,
+	// and stays pending until a host becomes eligible
+	if totalInstanceCount == 0 &&
+		d.config.GetType() == job.JobType_DAEMON &&
+		!util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) {
+		return job.JobState_PENDING, nil
+	}
+
 	// all succeeded -> succeeded
 	if d.stateCounts[task.TaskState_SUCCEEDED.String()] >= totalInstanceCount {
 		return job.JobState_SUCCEEDED, nil
@@ -336,7 +344,7 @@ func (d *partiallyCreatedJobStateDeterminer) getState(
 	switch d.cachedJob.GetJobType() {
 	case job.JobType_BATCH:
 		return job.JobState_INITIALIZED, nil
-	case job.JobType_SERVICE:
+	case job.JobType_SERVICE, job.JobType_DAEMON:
 
 		// job goal state is terminal &&
 		// some killed + some succeeded + some failed + some lost -> killed
@@ -695,7 +703,7 @@ func getTaskStateSummaryForJobInCache(ctx context.Context,
 	for _, taskinCache := range cachedJob.GetAllTasks() {
 		stateCounts[taskinCache.CurrentState().State.String()]++
 		// update the configuration version state map for stateless jobs
-		if config.GetType() == job.JobType_SERVICE {
+		if util.IsLongRunningJobType(config.GetType()) {
 			runtime, err := taskinCache.GetRuntime(ctx)
 			if err != nil {
 				return nil, nil, err
@@ -2278,6 +2278,36 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobStateDeterminer_NoInstancesCreat
 	suite.Equal(job.JobState_KILLED, jobState)
 }
 
+// TestJobStateDeterminer_DaemonJobWithoutHosts tests that a daemon job
+// without any instance stays pending
+func (suite *JobRuntimeUpdaterTestSuite) TestJobStateDeterminer_DaemonJobWithoutHosts() {
+	stateCounts := make(map[string]uint32)
+	jobRuntime := &pbjob.RuntimeInfo{
+		State:     job.JobState_PENDING,
+		GoalState: job.JobState_RUNNING,
+	}
+
+	suite.cachedConfig.EXPECT().
+		GetType().
+		Return(pbjob.JobType_DAEMON).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetInstanceCount().
+		Return(uint32(0)).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		HasControllerTask().
+		Return(false).
+		AnyTimes()
+
+	jobState, _, err := determineJobRuntimeStateAndCounts(context.Background(),
+		jobRuntime, stateCounts, suite.cachedConfig, suite.goalStateDriver, suite.cachedJob)
+	suite.NoError(err)
+	suite.Equal(job.JobState_PENDING, jobState)
+}
+
 // TestDetermineBatchJobRuntimeState tests determining JobRuntimeState for batch jobs
 func (suite *JobRuntimeUpdaterTestSuite) TestDetermineBatchJobRuntimeState() {
 	var instanceCount uint32 = 100
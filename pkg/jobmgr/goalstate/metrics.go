@@ -49,6 +49,7 @@ type TaskMetrics struct {
 	RetryFailedLaunchTotal tally.Counter
 	RetryFailedTasksTotal  tally.Counter
 	RetryLostTasksTotal    tally.Counter
+	CrashLoopTasksTotal    tally.Counter
 }
 
 // UpdateMetrics contains all counters to track
@@ -64,6 +65,8 @@ type UpdateMetrics struct {
 	UpdateRunFail           tally.Counter
 	UpdateWriteProgress     tally.Counter
 	UpdateWriteProgressFail tally.Counter
+	UpdateCanaryPromoted    tally.Counter
+	UpdateCanaryRolledBack  tally.Counter
 }
 
 // Metrics is the struct containing all the counters that track job and task
@@ -108,6 +111,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		RetryFailedLaunchTotal: taskScope.Counter("retry_system_failure_total"),
 		RetryFailedTasksTotal:  taskScope.Counter("retry_failed_total"),
 		RetryLostTasksTotal:    taskScope.Counter("retry_lost_total"),
+		CrashLoopTasksTotal:    taskScope.Counter("crash_loop_total"),
 	}
 
 	updateMetrics := &UpdateMetrics{
@@ -121,6 +125,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		UpdateRunFail:           updateScope.Counter("run_fail"),
 		UpdateWriteProgress:     updateScope.Counter("write_progress"),
 		UpdateWriteProgressFail: updateScope.Counter("write_progress_fail"),
+		UpdateCanaryPromoted:    updateScope.Counter("canary_promoted"),
+		UpdateCanaryRolledBack:  updateScope.Counter("canary_rolled_back"),
 	}
 
 	return &Metrics{
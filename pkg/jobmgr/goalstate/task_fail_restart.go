@@ -16,7 +16,10 @@ package goalstate
 
 import (
 	"context"
+	"hash/fnv"
 	"math"
+	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -32,6 +35,15 @@ import (
 
 const (
 	_rescheduleMessage = "Rescheduled after task terminated"
+
+	// _defaultBackoffMultiplier is the factor the restart delay of a task
+	// is multiplied by for every consecutive failure, if its restart
+	// policy does not set one
+	_defaultBackoffMultiplier = 2
+	// _defaultCrashLoopThreshold is the number of consecutive failures
+	// after which a task is crash looping, if its restart policy does
+	// not set one
+	_defaultCrashLoopThreshold = 3
 )
 
 // rescheduleTask patch the new job runtime and enqueue the task into goalstate engine
@@ -58,6 +70,7 @@ func rescheduleTask(
 	var runtimeDiff jobmgrcommon.RuntimeDiff
 	scheduleDelay := getScheduleDelay(
 		taskRuntime,
+		taskConfig.GetRestartPolicy(),
 		goalStateDriver.cfg.InitialTaskBackoff,
 		goalStateDriver.cfg.MaxTaskBackoff,
 		throttleOnFailure,
@@ -81,6 +94,16 @@ func rescheduleTask(
 		runtimeDiff = jobmgrcommon.RuntimeDiff{
 			jobmgrcommon.MessageField: common.TaskThrottleMessage,
 		}
+		if isCrashLooping(taskRuntime, taskConfig.GetRestartPolicy()) {
+			runtimeDiff[jobmgrcommon.ReasonField] =
+				common.TaskCrashLoopBackoffReason
+			goalStateDriver.mtx.taskMetrics.CrashLoopTasksTotal.Inc(1)
+			log.WithField("job_id", jobID).
+				WithField("instance_id", instanceID).
+				WithField("crash_loop_count", taskRuntime.GetCrashLoopCount()).
+				WithField("schedule_delay", scheduleDelay.String()).
+				Info("crash looping task throttled")
+		}
 	}
 
 	if len(runtimeDiff) != 0 {
@@ -108,6 +131,7 @@ func rescheduleTask(
 // and the task should be rescheduled immediately
 func getScheduleDelay(
 	taskRuntime *task.RuntimeInfo,
+	restartPolicy *task.RestartPolicy,
 	initialTaskBackOff time.Duration,
 	maxTaskBackOff time.Duration,
 	throttleOnFailure bool,
@@ -116,7 +140,16 @@ func getScheduleDelay(
 		return time.Duration(0)
 	}
 
-	backOff := getBackoff(taskRuntime, initialTaskBackOff, maxTaskBackOff)
+	var backOff time.Duration
+	if hasRestartBackoff(restartPolicy) {
+		backOff = getRestartPolicyBackoff(
+			taskRuntime,
+			restartPolicy,
+			initialTaskBackOff,
+			maxTaskBackOff)
+	} else {
+		backOff = getBackoff(taskRuntime, initialTaskBackOff, maxTaskBackOff)
+	}
 	ddl := time.Unix(0, int64(taskRuntime.GetRevision().GetUpdatedAt())).Add(backOff)
 
 	return ddl.Sub(time.Now())
@@ -131,23 +164,110 @@ func getBackoff(
 	}
 
 	// rawBackOff = _initialTaskBackOff * 2 ^ (failureCount - 1)
-	rawBackOff := float64(initialTaskBackOff.Nanoseconds()) *
-		math.Pow(2, float64(taskRuntime.GetFailureCount()-1))
+	return exponentialBackoff(
+		initialTaskBackOff,
+		maxTaskBackOff,
+		_defaultBackoffMultiplier,
+		taskRuntime.GetFailureCount())
+}
+
+// hasRestartBackoff returns true if the restart policy configures the
+// restart delay of the task
+func hasRestartBackoff(restartPolicy *task.RestartPolicy) bool {
+	return restartPolicy.GetInitialBackoffSecs() != 0 ||
+		restartPolicy.GetBackoffMultiplier() != 0 ||
+		restartPolicy.GetMaxBackoffSecs() != 0 ||
+		restartPolicy.GetJitter() != 0 ||
+		restartPolicy.GetResetAfterHealthySecs() != 0
+}
+
+// getRestartPolicyBackoff returns the restart delay of a task according
+// to its restart policy, based on its consecutive failures. The defaults
+// of the job manager apply to the fields not set in the policy.
+func getRestartPolicyBackoff(
+	taskRuntime *task.RuntimeInfo,
+	restartPolicy *task.RestartPolicy,
+	initialTaskBackOff time.Duration,
+	maxTaskBackOff time.Duration) time.Duration {
+	if taskRuntime.GetCrashLoopCount() == 0 {
+		return time.Duration(0)
+	}
+
+	if restartPolicy.GetInitialBackoffSecs() != 0 {
+		initialTaskBackOff =
+			time.Duration(restartPolicy.GetInitialBackoffSecs()) * time.Second
+	}
+	if restartPolicy.GetMaxBackoffSecs() != 0 {
+		maxTaskBackOff =
+			time.Duration(restartPolicy.GetMaxBackoffSecs()) * time.Second
+	}
+	multiplier := restartPolicy.GetBackoffMultiplier()
+	if multiplier == 0 {
+		multiplier = _defaultBackoffMultiplier
+	}
+
+	backOff := exponentialBackoff(
+		initialTaskBackOff,
+		maxTaskBackOff,
+		multiplier,
+		taskRuntime.GetCrashLoopCount())
+
+	if jitter := restartPolicy.GetJitter(); jitter > 0 {
+		// the jitter is derived from the task run rather than drawn at
+		// random, so that the delay is the same every time the task is
+		// evaluated while it is throttled
+		backOff += time.Duration(
+			float64(backOff) * jitter * (2*jitterFraction(taskRuntime) - 1))
+	}
+	return backOff
+}
+
+// exponentialBackoff returns
+// min(initialBackOff * multiplier ^ (failureCount - 1), maxBackOff)
+func exponentialBackoff(
+	initialBackOff time.Duration,
+	maxBackOff time.Duration,
+	multiplier float64,
+	failureCount uint32) time.Duration {
+	rawBackOff := float64(initialBackOff.Nanoseconds()) *
+		math.Pow(multiplier, float64(failureCount-1))
 
 	// type time.Duration is internally int64,
 	// have to make sure rawBackOff does not overflow when
 	// convert to int64, otherwise a negative value would return.
 	if rawBackOff > math.MaxInt64 {
-		return maxTaskBackOff
+		return maxBackOff
 	}
 
 	backOff := time.Duration(rawBackOff)
-	if backOff > maxTaskBackOff {
-		return maxTaskBackOff
+	if backOff > maxBackOff {
+		return maxBackOff
 	}
 	return backOff
 }
 
+// jitterFraction returns a number in [0, 1) which is stable for a run of
+// the task and its number of consecutive failures
+func jitterFraction(taskRuntime *task.RuntimeInfo) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(taskRuntime.GetMesosTaskId().GetValue()))
+	h.Write([]byte(strconv.FormatUint(
+		uint64(taskRuntime.GetCrashLoopCount()), 10)))
+	return rand.New(rand.NewSource(int64(h.Sum64()))).Float64()
+}
+
+// isCrashLooping returns true if the task has failed consecutively at
+// least the crash loop threshold of its restart policy
+func isCrashLooping(
+	taskRuntime *task.RuntimeInfo,
+	restartPolicy *task.RestartPolicy) bool {
+	threshold := restartPolicy.GetCrashLoopThreshold()
+	if threshold == 0 {
+		threshold = _defaultCrashLoopThreshold
+	}
+	return taskRuntime.GetCrashLoopCount() >= threshold
+}
+
 // TaskFailRetry retries on task failure
 func TaskFailRetry(ctx context.Context, entity goalstate.Entity) error {
 	taskEnt := entity.(*taskEntity)
@@ -19,12 +19,12 @@ import (
 	"time"
 
 	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
-	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/util"
 	jobmgrtask "github.com/uber/peloton/pkg/jobmgr/task"
 
 	log "github.com/sirupsen/logrus"
@@ -106,7 +106,7 @@ func TaskLaunchRetry(ctx context.Context, entity goalstate.Entity) error {
 			return err
 		}
 
-		if cachedConfig.GetType() == job.JobType_SERVICE {
+		if util.IsLongRunningJobType(cachedConfig.GetType()) {
 			return nil
 		}
 
@@ -84,11 +84,21 @@ func TaskStart(ctx context.Context, entity goalstate.Entity) error {
 		return fmt.Errorf("task info not found for %v", taskID)
 	}
 
+	jobRuntime, err := getJobRuntimeForDeadline(ctx, cachedJob, cachedConfig)
+	if err != nil {
+		log.WithError(err).
+			WithField("job_id", taskEnt.jobID).
+			WithField("instance_id", taskEnt.instanceID).
+			Error("failed to get job runtime in task start")
+		return err
+	}
+
 	// TODO: Investigate how to create proper gangs for scheduling (currently, task are treat independently)
 	response, err := jobmgr_task.EnqueueGangs(
 		ctx,
 		[]*task.TaskInfo{taskInfo},
 		cachedConfig,
+		jobRuntime,
 		goalStateDriver.resmgrClient)
 
 	// Parse the EnqueueGangs response to determine if the task is successfully enqueued
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/mesos/v1"
 	job2 "github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -149,7 +150,7 @@ func (suite *TaskStartTestSuite) TestTaskStartStateless() {
 		Return(taskInfo, nil)
 
 	request := &resmgrsvc.EnqueueGangsRequest{
-		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig),
+		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig, nil),
 		ResPool: jobConfig.RespoolID,
 	}
 
@@ -172,6 +173,77 @@ func (suite *TaskStartTestSuite) TestTaskStartStateless() {
 	suite.NoError(err)
 }
 
+// TestTaskStartWithDeadline tests that the deadline of the task sent to
+// resource manager is computed from the job submission time
+func (suite *TaskStartTestSuite) TestTaskStartWithDeadline() {
+	jobConfig := &job2.JobConfig{
+		RespoolID: &peloton.ResourcePoolID{
+			Value: "my-respool-id",
+		},
+		SLA: &job2.SlaConfig{
+			MaxRunningTime: 60,
+		},
+	}
+	jobRuntime := &job2.RuntimeInfo{
+		Revision: &peloton.ChangeLog{CreatedAt: 1000},
+	}
+	taskInfo := &pbtask.TaskInfo{
+		InstanceId: suite.instanceID,
+		Config:     &pbtask.TaskConfig{},
+		Runtime:    &pbtask.RuntimeInfo{},
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(suite.cachedConfig, nil)
+
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(jobConfig.SLA).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetRespoolID().
+		Return(jobConfig.RespoolID)
+
+	suite.cachedConfig.EXPECT().
+		GetType().
+		Return(job2.JobType_BATCH).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetPlacementStrategy().
+		Return(job2.PlacementStrategy_PLACEMENT_STRATEGY_INVALID)
+
+	suite.taskStore.EXPECT().
+		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
+		Return(taskInfo, nil)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(jobRuntime, nil)
+
+	suite.resmgrClient.EXPECT().
+		EnqueueGangs(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *resmgrsvc.EnqueueGangsRequest) {
+			suite.Equal(
+				uint64(1000+60*time.Second),
+				req.GetGangs()[0].GetTasks()[0].GetDeadline())
+		}).
+		Return(nil, nil)
+
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Return(nil, nil, nil)
+
+	err := TaskStart(context.Background(), suite.taskEnt)
+	suite.NoError(err)
+}
+
 func (suite *TaskStartTestSuite) TestTaskStartWithSlaMaxRunningInstances() {
 	jobConfig := &job2.JobConfig{
 		InstanceCount: 2,
@@ -310,7 +382,7 @@ func (suite *TaskStartTestSuite) TestTaskStartEnqueueExist() {
 		},
 		Runtime: &pbtask.RuntimeInfo{},
 	}
-	resmgrTask := taskutil.ConvertTaskToResMgrTask(taskInfo, jobConfig)
+	resmgrTask := taskutil.ConvertTaskToResMgrTask(taskInfo, jobConfig, nil)
 	resmgrEnqueueFailures := map[string]*resmgrsvc.EnqueueGangsResponse{
 		"already_exists": {
 			Error: &resmgrsvc.EnqueueGangsResponse_Error{
@@ -448,7 +520,7 @@ func (suite *TaskStartTestSuite) TestTaskStartTaskNotInCache() {
 		Return(taskInfo, nil)
 
 	request := &resmgrsvc.EnqueueGangsRequest{
-		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig),
+		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig, nil),
 		ResPool: jobConfig.RespoolID,
 	}
 
@@ -141,6 +141,7 @@ func stopInitializedTask(ctx context.Context, taskEnt *taskEntity) error {
 	if runtime.GetConfigVersion() != runtime.GetDesiredConfigVersion() {
 		// Kill is due to update, reset failure count
 		runtimeDiff[jobmgrcommon.FailureCountField] = uint32(0)
+		runtimeDiff[jobmgrcommon.CrashLoopCountField] = uint32(0)
 	}
 
 	// we do not need to handle `instancesToBeRetried` here since the task
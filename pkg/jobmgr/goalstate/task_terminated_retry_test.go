@@ -26,6 +26,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/models"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/goalstate"
 	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
@@ -365,3 +367,172 @@ func (suite *TaskTerminatedRetryTestSuite) TestGetBackoffOverflow() {
 		suite.True(backOff <= 60*time.Minute)
 	}
 }
+
+// TestTaskTerminatedRetryCrashLoop tests that a task failing repeatedly is
+// throttled with the crash loop backoff reason
+func (suite *TaskTerminatedRetryTestSuite) TestTaskTerminatedRetryCrashLoop() {
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		AddTask(gomock.Any(), suite.instanceID).Return(suite.cachedTask, nil)
+	suite.taskRuntime.CrashLoopCount = 3
+	suite.taskRuntime.Revision = &peloton.ChangeLog{
+		UpdatedAt: uint64(time.Now().UnixNano()),
+	}
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.taskRuntime, nil)
+	suite.taskConfig = &pbtask.TaskConfig{
+		RestartPolicy: &pbtask.RestartPolicy{
+			MaxFailures:        10,
+			InitialBackoffSecs: 10,
+			CrashLoopThreshold: 2,
+		},
+	}
+	suite.taskConfigV2Ops.EXPECT().GetTaskConfig(
+		gomock.Any(),
+		suite.jobID,
+		suite.instanceID,
+		gomock.Any()).Return(suite.taskConfig, &models.ConfigAddOn{}, nil)
+
+	suite.cachedJob.EXPECT().
+		ID().Return(suite.jobID)
+
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Do(func(ctx context.Context,
+			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+			_ bool) {
+			suite.Equal(jobmgrcommon.RuntimeDiff{
+				jobmgrcommon.MessageField: common.TaskThrottleMessage,
+				jobmgrcommon.ReasonField:  common.TaskCrashLoopBackoffReason,
+			}, runtimeDiffs[suite.instanceID])
+		}).Return(nil, nil, nil)
+
+	suite.cachedJob.EXPECT().
+		GetJobType().Return(pbjob.JobType_SERVICE)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			// 10s * 2 ^ (3 - 1)
+			suite.True(deadline.After(time.Now().Add(30 * time.Second)))
+			suite.True(deadline.Before(time.Now().Add(41 * time.Second)))
+		}).
+		Return()
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := TaskTerminatedRetry(context.Background(), suite.taskEnt)
+	suite.Nil(err)
+}
+
+// TestGetRestartPolicyBackoff tests the restart delay of a task according
+// to its restart policy
+func (suite *TaskTerminatedRetryTestSuite) TestGetRestartPolicyBackoff() {
+	tt := []struct {
+		msg            string
+		restartPolicy  *pbtask.RestartPolicy
+		crashLoopCount uint32
+		backOff        time.Duration
+	}{
+		{
+			msg:            "no consecutive failure",
+			restartPolicy:  &pbtask.RestartPolicy{InitialBackoffSecs: 10},
+			crashLoopCount: 0,
+			backOff:        0,
+		},
+		{
+			msg:            "initial backoff",
+			restartPolicy:  &pbtask.RestartPolicy{InitialBackoffSecs: 10},
+			crashLoopCount: 1,
+			backOff:        10 * time.Second,
+		},
+		{
+			msg: "backoff multiplier",
+			restartPolicy: &pbtask.RestartPolicy{
+				InitialBackoffSecs: 10,
+				BackoffMultiplier:  3,
+			},
+			crashLoopCount: 3,
+			backOff:        90 * time.Second,
+		},
+		{
+			msg: "max backoff",
+			restartPolicy: &pbtask.RestartPolicy{
+				InitialBackoffSecs: 10,
+				MaxBackoffSecs:     60,
+			},
+			crashLoopCount: 10,
+			backOff:        60 * time.Second,
+		},
+		{
+			msg:            "job manager defaults",
+			restartPolicy:  &pbtask.RestartPolicy{ResetAfterHealthySecs: 600},
+			crashLoopCount: 2,
+			backOff:        60 * time.Second,
+		},
+		{
+			msg:            "no overflow",
+			restartPolicy:  &pbtask.RestartPolicy{ResetAfterHealthySecs: 600},
+			crashLoopCount: math.MaxUint32,
+			backOff:        60 * time.Minute,
+		},
+	}
+
+	for _, t := range tt {
+		suite.True(hasRestartBackoff(t.restartPolicy), t.msg)
+		suite.Equal(t.backOff, getRestartPolicyBackoff(
+			&pbtask.RuntimeInfo{CrashLoopCount: t.crashLoopCount},
+			t.restartPolicy,
+			30*time.Second,
+			60*time.Minute,
+		), t.msg)
+	}
+
+	suite.False(hasRestartBackoff(&pbtask.RestartPolicy{MaxFailures: 3}))
+}
+
+// TestGetRestartPolicyBackoffJitter tests that the jitter of the restart
+// delay of a task is bounded, and stable for a task run
+func (suite *TaskTerminatedRetryTestSuite) TestGetRestartPolicyBackoffJitter() {
+	restartPolicy := &pbtask.RestartPolicy{
+		InitialBackoffSecs: 100,
+		Jitter:             0.5,
+	}
+
+	for i := 0; i < 10; i++ {
+		mesosTaskID := fmt.Sprintf("%s-%d-%d", suite.jobID.GetValue(), 0, i)
+		taskRuntime := &pbtask.RuntimeInfo{
+			MesosTaskId:    &mesosv1.TaskID{Value: &mesosTaskID},
+			CrashLoopCount: 1,
+		}
+		backOff := getRestartPolicyBackoff(
+			taskRuntime,
+			restartPolicy,
+			30*time.Second,
+			60*time.Minute)
+		suite.True(backOff >= 50*time.Second)
+		suite.True(backOff <= 150*time.Second)
+		suite.Equal(backOff, getRestartPolicyBackoff(
+			taskRuntime,
+			restartPolicy,
+			30*time.Second,
+			60*time.Minute))
+	}
+}
+
+// TestIsCrashLooping tests crash loop detection
+func (suite *TaskTerminatedRetryTestSuite) TestIsCrashLooping() {
+	suite.False(isCrashLooping(
+		&pbtask.RuntimeInfo{CrashLoopCount: 2}, nil))
+	suite.True(isCrashLooping(
+		&pbtask.RuntimeInfo{CrashLoopCount: 3}, nil))
+	suite.False(isCrashLooping(
+		&pbtask.RuntimeInfo{CrashLoopCount: 3},
+		&pbtask.RestartPolicy{CrashLoopThreshold: 5}))
+	suite.True(isCrashLooping(
+		&pbtask.RuntimeInfo{CrashLoopCount: 5},
+		&pbtask.RestartPolicy{CrashLoopThreshold: 5}))
+}
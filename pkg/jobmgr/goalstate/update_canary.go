@@ -0,0 +1,335 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// canaryStage is the stage of the canary of an update
+type canaryStage int
+
+const (
+	// canaryStageNone indicates that the update has no canary
+	// stage, or that the canary has been promoted
+	canaryStageNone canaryStage = iota
+	// canaryStageRolling indicates that the canary instances
+	// are being updated
+	canaryStageRolling
+	// canaryStageBaking indicates that all canary instances have
+	// been processed and the update is held for the bake time
+	canaryStageBaking
+)
+
+// getCanaryInstanceCount returns the number of canary instances of
+// the update, or 0 if the update does not have a canary stage.
+func getCanaryInstanceCount(
+	cachedUpdate cached.Update,
+	canary *pbupdate.CanaryConfig,
+) uint32 {
+	if canary.GetInstanceCount() == 0 {
+		return 0
+	}
+
+	// rollbacks and restarts are never canaried
+	if cachedUpdate.GetWorkflowType() != models.WorkflowType_UPDATE ||
+		isUpdateRollback(cachedUpdate) {
+		return 0
+	}
+
+	if canary.GetInstanceCount() >=
+		uint32(len(cachedUpdate.GetGoalState().Instances)) {
+		return 0
+	}
+
+	return canary.GetInstanceCount()
+}
+
+// getCanaryStage returns the canary stage of the update given the
+// instances which are being processed, done and failed.
+func getCanaryStage(
+	cachedUpdate cached.Update,
+	canary *pbupdate.CanaryConfig,
+	instancesCurrent []uint32,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+) canaryStage {
+	canaryCount := int(getCanaryInstanceCount(cachedUpdate, canary))
+	if canaryCount == 0 {
+		return canaryStageNone
+	}
+
+	// instances beyond the canary have been picked up,
+	// which means the canary has been promoted
+	if len(instancesCurrent)+len(instancesDone)+len(instancesFailed) >
+		canaryCount {
+		return canaryStageNone
+	}
+
+	if len(instancesDone)+len(instancesFailed) < canaryCount {
+		return canaryStageRolling
+	}
+
+	return canaryStageBaking
+}
+
+// processCanary evaluates the success criteria of the canary
+// instances. It rolls back the update if any criterion is violated,
+// and holds the update until the bake time has passed once all the
+// canary instances have been processed. It returns true if the update
+// should not proceed further in this run.
+func processCanary(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	canary *pbupdate.CanaryConfig,
+	stage canaryStage,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+	goalStateDriver *driver,
+) (bool, error) {
+	violation, bakeStart, err := evaluateCanary(
+		ctx,
+		cachedJob,
+		cachedUpdate,
+		canary,
+		instancesDone,
+		instancesFailed,
+		instancesCurrent,
+		goalStateDriver,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if len(violation) != 0 {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+			"violation": violation,
+		}).Info("canary failed, rolling back update")
+
+		if err := rollbackUpdate(
+			ctx,
+			cachedJob,
+			cachedUpdate,
+			instancesDone,
+			instancesFailed,
+			instancesCurrent,
+		); err != nil {
+			return false, err
+		}
+
+		goalStateDriver.mtx.updateMetrics.UpdateCanaryRolledBack.Inc(1)
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(),
+			cachedUpdate.ID(),
+			time.Now())
+		return true, nil
+	}
+
+	if stage != canaryStageBaking {
+		return false, nil
+	}
+
+	// the canary has not started baking if none of the updated canary
+	// instances has a start time yet, so check it again after a delay
+	// instead of promoting it right away
+	baked := !bakeStart.IsZero() || len(instancesDone) == 0
+	bakeEnd := bakeStart.Add(
+		time.Duration(canary.GetBakeTimeSeconds()) * time.Second)
+	if !baked {
+		bakeEnd = time.Now().Add(goalStateDriver.cfg.FailureRetryDelay)
+	}
+	if !baked || time.Now().Before(bakeEnd) {
+		// persist the newly finished canary instances
+		// before holding the update
+		if err := cachedJob.WriteWorkflowProgress(
+			ctx,
+			cachedUpdate.ID(),
+			cachedUpdate.GetState().State,
+			instancesDone,
+			instancesFailed,
+			instancesCurrent,
+		); err != nil {
+			return false, err
+		}
+
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(),
+			cachedUpdate.ID(),
+			bakeEnd)
+		return true, nil
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": cachedUpdate.ID().GetValue(),
+		"job_id":    cachedJob.ID().GetValue(),
+	}).Info("canary promoted")
+	goalStateDriver.mtx.updateMetrics.UpdateCanaryPromoted.Inc(1)
+	return false, nil
+}
+
+// evaluateCanary checks the canary instances against the success
+// criteria of the canary. It returns a description of the first
+// violated criterion, if any, along with the time the last canary
+// instance started running, which is zero if no updated canary instance
+// has a start time.
+func evaluateCanary(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	canary *pbupdate.CanaryConfig,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+	goalStateDriver *driver,
+) (string, time.Time, error) {
+	var bakeStart time.Time
+
+	canaryCount := getCanaryInstanceCount(cachedUpdate, canary)
+	failurePercent := float64(len(instancesFailed)) * 100 /
+		float64(canaryCount)
+	if failurePercent > canary.GetMaxFailurePercent() {
+		violation := fmt.Sprintf(
+			"%.2f%% of canary instances failed", failurePercent)
+		return violation, bakeStart, nil
+	}
+
+	jobVersion := cachedUpdate.GetGoalState().JobVersion
+	done := make(map[uint32]bool)
+	var instances []uint32
+	for _, instID := range instancesDone {
+		done[instID] = true
+		instances = append(instances, instID)
+	}
+	instances = append(instances, instancesCurrent...)
+
+	for _, instID := range instances {
+		cachedTask := cachedJob.GetTask(instID)
+		if cachedTask == nil {
+			continue
+		}
+
+		runtime, err := cachedTask.GetRuntime(ctx)
+		if err != nil {
+			return "", bakeStart, err
+		}
+
+		// instance is yet to run with the new configuration
+		if runtime.GetConfigVersion() != jobVersion {
+			continue
+		}
+
+		if runtime.GetFailureCount() > canary.GetMaxRestarts() {
+			violation := fmt.Sprintf(
+				"canary instance %d restarted %d times",
+				instID, runtime.GetFailureCount())
+			return violation, bakeStart, nil
+		}
+
+		podEvents, err := goalStateDriver.podEventsOps.GetAll(
+			ctx,
+			cachedJob.ID().GetValue(),
+			instID)
+		if err != nil {
+			return "", bakeStart, err
+		}
+
+		flaps := countHealthCheckFlaps(podEvents, jobVersion)
+		if flaps > canary.GetMaxHealthCheckFlaps() {
+			violation := fmt.Sprintf(
+				"canary instance %d health check flapped %d times",
+				instID, flaps)
+			return violation, bakeStart, nil
+		}
+
+		if !done[instID] {
+			continue
+		}
+
+		startTime, err := time.Parse(time.RFC3339Nano, runtime.GetStartTime())
+		if err == nil && startTime.After(bakeStart) {
+			bakeStart = startTime
+		}
+	}
+
+	return "", bakeStart, nil
+}
+
+// countHealthCheckFlaps returns the number of times an instance went
+// from healthy to unhealthy while running the given configuration
+// version. Pod events are ordered from the most recent to the oldest.
+func countHealthCheckFlaps(
+	podEvents []*pbtask.PodEvent,
+	configVersion uint64,
+) uint32 {
+	var flaps uint32
+	healthy := false
+	for i := len(podEvents) - 1; i >= 0; i-- {
+		event := podEvents[i]
+		if event.GetConfigVersion() != configVersion {
+			continue
+		}
+
+		switch event.GetHealthy() {
+		case pbtask.HealthState_HEALTHY.String():
+			healthy = true
+		case pbtask.HealthState_UNHEALTHY.String():
+			if healthy {
+				flaps++
+			}
+			healthy = false
+		}
+	}
+	return flaps
+}
+
+// limitInstancesToProcess trims the instances to add, update and
+// remove so that at most limit instances are processed in total.
+func limitInstancesToProcess(
+	limit int,
+	instancesToAdd []uint32,
+	instancesToUpdate []uint32,
+	instancesToRemove []uint32,
+) ([]uint32, []uint32, []uint32) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	trim := func(instances []uint32) []uint32 {
+		if len(instances) > limit {
+			instances = instances[:limit]
+		}
+		limit -= len(instances)
+		return instances
+	}
+
+	instancesToAdd = trim(instancesToAdd)
+	instancesToUpdate = trim(instancesToUpdate)
+	instancesToRemove = trim(instancesToRemove)
+	return instancesToAdd, instancesToUpdate, instancesToRemove
+}
@@ -0,0 +1,464 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type UpdateCanaryTestSuite struct {
+	suite.Suite
+	ctrl                  *gomock.Controller
+	updateGoalStateEngine *goalstatemocks.MockEngine
+	goalStateDriver       *driver
+	jobID                 *peloton.JobID
+	updateID              *peloton.UpdateID
+	cachedJob             *cachedmocks.MockJob
+	cachedUpdate          *cachedmocks.MockUpdate
+	cachedTask            *cachedmocks.MockTask
+	mockedPodEventsOps    *objectmocks.MockPodEventsOps
+	canary                *pbupdate.CanaryConfig
+}
+
+func TestUpdateCanary(t *testing.T) {
+	suite.Run(t, new(UpdateCanaryTestSuite))
+}
+
+func (suite *UpdateCanaryTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.updateGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.mockedPodEventsOps = objectmocks.NewMockPodEventsOps(suite.ctrl)
+	suite.goalStateDriver = &driver{
+		updateEngine: suite.updateGoalStateEngine,
+		podEventsOps: suite.mockedPodEventsOps,
+		mtx:          NewMetrics(tally.NoopScope),
+		cfg:          &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.updateID = &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedUpdate = cachedmocks.NewMockUpdate(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+
+	suite.canary = &pbupdate.CanaryConfig{
+		InstanceCount:       2,
+		BakeTimeSeconds:     600,
+		MaxFailurePercent:   0,
+		MaxHealthCheckFlaps: 1,
+		MaxRestarts:         1,
+	}
+
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	suite.cachedUpdate.EXPECT().ID().Return(suite.updateID).AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetGoalState().
+		Return(&cached.UpdateStateVector{
+			Instances:  []uint32{0, 1, 2, 3, 4},
+			JobVersion: 4,
+		}).
+		AnyTimes()
+}
+
+func (suite *UpdateCanaryTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectState sets the expectation for the state of the update
+func (suite *UpdateCanaryTestSuite) expectState(state pbupdate.State) {
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{State: state}).
+		AnyTimes()
+}
+
+// expectCanaryInstance sets the expectations for evaluating a
+// canary instance with the given runtime and pod events
+func (suite *UpdateCanaryTestSuite) expectCanaryInstance(
+	instID uint32,
+	runtime *pbtask.RuntimeInfo,
+	podEvents []*pbtask.PodEvent,
+) {
+	suite.cachedJob.EXPECT().
+		GetTask(instID).
+		Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(runtime, nil)
+	if runtime.GetConfigVersion() != 4 ||
+		runtime.GetFailureCount() > suite.canary.GetMaxRestarts() {
+		return
+	}
+	suite.mockedPodEventsOps.EXPECT().
+		GetAll(gomock.Any(), suite.jobID.GetValue(), instID).
+		Return(podEvents, nil)
+}
+
+// TestGetCanaryStage tests the canary stage of an update
+// given the instances processed so far
+func (suite *UpdateCanaryTestSuite) TestGetCanaryStage() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+
+	suite.Equal(canaryStageNone, getCanaryStage(
+		suite.cachedUpdate, nil, nil, nil, nil))
+	suite.Equal(canaryStageNone, getCanaryStage(
+		suite.cachedUpdate,
+		&pbupdate.CanaryConfig{InstanceCount: 5},
+		nil, nil, nil))
+	suite.Equal(canaryStageRolling, getCanaryStage(
+		suite.cachedUpdate, suite.canary, nil, nil, nil))
+	suite.Equal(canaryStageRolling, getCanaryStage(
+		suite.cachedUpdate, suite.canary, []uint32{0}, []uint32{1}, nil))
+	suite.Equal(canaryStageBaking, getCanaryStage(
+		suite.cachedUpdate, suite.canary, nil, []uint32{0}, []uint32{1}))
+	suite.Equal(canaryStageNone, getCanaryStage(
+		suite.cachedUpdate, suite.canary, []uint32{2}, []uint32{0, 1}, nil))
+}
+
+// TestGetCanaryStageRollback tests that an update
+// which is rolling back has no canary stage
+func (suite *UpdateCanaryTestSuite) TestGetCanaryStageRollback() {
+	suite.expectState(pbupdate.State_ROLLING_BACKWARD)
+
+	suite.Equal(canaryStageNone, getCanaryStage(
+		suite.cachedUpdate, suite.canary, nil, nil, nil))
+}
+
+// TestCountHealthCheckFlaps tests counting the healthy to unhealthy
+// transitions of an instance running the new configuration
+func (suite *UpdateCanaryTestSuite) TestCountHealthCheckFlaps() {
+	healthy := pbtask.HealthState_HEALTHY.String()
+	unhealthy := pbtask.HealthState_UNHEALTHY.String()
+
+	// pod events are ordered from the most recent to the oldest
+	podEvents := []*pbtask.PodEvent{
+		{Healthy: unhealthy, ConfigVersion: 4},
+		{Healthy: healthy, ConfigVersion: 4},
+		{Healthy: unhealthy, ConfigVersion: 4},
+		{Healthy: unhealthy, ConfigVersion: 4},
+		{Healthy: healthy, ConfigVersion: 4},
+		{Healthy: pbtask.HealthState_HEALTH_UNKNOWN.String(), ConfigVersion: 4},
+		{Healthy: unhealthy, ConfigVersion: 3},
+		{Healthy: healthy, ConfigVersion: 3},
+	}
+
+	suite.Equal(uint32(2), countHealthCheckFlaps(podEvents, 4))
+	suite.Equal(uint32(1), countHealthCheckFlaps(podEvents, 3))
+	suite.Equal(uint32(0), countHealthCheckFlaps(nil, 4))
+}
+
+// TestLimitInstancesToProcess tests trimming the instances
+// to process to the remaining canary instances
+func (suite *UpdateCanaryTestSuite) TestLimitInstancesToProcess() {
+	add, update, remove := limitInstancesToProcess(
+		3, []uint32{0, 1}, []uint32{2, 3}, []uint32{4})
+	suite.Equal([]uint32{0, 1}, add)
+	suite.Equal([]uint32{2}, update)
+	suite.Empty(remove)
+
+	add, update, remove = limitInstancesToProcess(
+		-1, []uint32{0, 1}, []uint32{2, 3}, []uint32{4})
+	suite.Empty(add)
+	suite.Empty(update)
+	suite.Empty(remove)
+}
+
+// TestProcessCanaryRolling tests that the update proceeds while
+// the canary instances are being updated without violations
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryRolling() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	suite.expectCanaryInstance(0, &pbtask.RuntimeInfo{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 4,
+		StartTime:     time.Now().Format(time.RFC3339Nano),
+	}, nil)
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageRolling,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.False(hold)
+}
+
+// TestProcessCanaryBaking tests that the update is held
+// until the bake time of the canary has passed
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryBaking() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	startTime := time.Now().Add(-time.Minute)
+	for _, instID := range []uint32{0, 1} {
+		suite.expectCanaryInstance(instID, &pbtask.RuntimeInfo{
+			State:         pbtask.TaskState_RUNNING,
+			ConfigVersion: 4,
+			StartTime:     startTime.Format(time.RFC3339Nano),
+		}, nil)
+	}
+
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			[]uint32{0, 1},
+			nil,
+			nil,
+		).Return(nil)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, deadline time.Time) {
+			suite.True(deadline.After(time.Now()))
+		})
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageBaking,
+		[]uint32{0, 1},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.True(hold)
+}
+
+// TestProcessCanaryPromoted tests that the update proceeds
+// once the bake time of the canary has passed
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryPromoted() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	startTime := time.Now().Add(-time.Hour)
+	for _, instID := range []uint32{0, 1} {
+		suite.expectCanaryInstance(instID, &pbtask.RuntimeInfo{
+			State:         pbtask.TaskState_RUNNING,
+			ConfigVersion: 4,
+			StartTime:     startTime.Format(time.RFC3339Nano),
+		}, nil)
+	}
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageBaking,
+		[]uint32{0, 1},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.False(hold)
+}
+
+// TestProcessCanaryBakingWithoutStartTime tests that the update is
+// held if no canary instance has a start time to bake from
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryBakingWithoutStartTime() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	for _, instID := range []uint32{0, 1} {
+		suite.expectCanaryInstance(instID, &pbtask.RuntimeInfo{
+			State:         pbtask.TaskState_RUNNING,
+			ConfigVersion: 4,
+		}, nil)
+	}
+
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			[]uint32{0, 1},
+			nil,
+			nil,
+		).Return(nil)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ interface{}, deadline time.Time) {
+			suite.True(deadline.After(time.Now()))
+		})
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageBaking,
+		[]uint32{0, 1},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.True(hold)
+}
+
+// TestProcessCanaryRollbackOnRestarts tests that the update is
+// rolled back if a canary instance restarts too many times
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryRollbackOnRestarts() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	suite.expectCanaryInstance(0, &pbtask.RuntimeInfo{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 4,
+		FailureCount:  2,
+	}, nil)
+
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			nil,
+			nil,
+			[]uint32{0},
+		).Return(nil)
+	suite.cachedJob.EXPECT().
+		RollbackWorkflow(gomock.Any()).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{InstanceCount: 5}, nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageRolling,
+		nil,
+		nil,
+		[]uint32{0},
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.True(hold)
+}
+
+// TestProcessCanaryRollbackOnHealthCheckFlaps tests that the update is
+// rolled back if the health check of a canary instance flaps too often
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryRollbackOnHealthCheckFlaps() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+	healthy := pbtask.HealthState_HEALTHY.String()
+	unhealthy := pbtask.HealthState_UNHEALTHY.String()
+	suite.expectCanaryInstance(0, &pbtask.RuntimeInfo{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 4,
+	}, []*pbtask.PodEvent{
+		{Healthy: unhealthy, ConfigVersion: 4},
+		{Healthy: healthy, ConfigVersion: 4},
+		{Healthy: unhealthy, ConfigVersion: 4},
+		{Healthy: healthy, ConfigVersion: 4},
+	})
+
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			[]uint32{0},
+			nil,
+			nil,
+		).Return(nil)
+	suite.cachedJob.EXPECT().
+		RollbackWorkflow(gomock.Any()).
+		Return(yarpcerrors.InternalErrorf("test error"))
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageRolling,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.Error(err)
+	suite.False(hold)
+}
+
+// TestProcessCanaryRollbackOnFailures tests that the update is rolled
+// back if too many canary instances fail to be updated
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryRollbackOnFailures() {
+	suite.expectState(pbupdate.State_ROLLING_FORWARD)
+
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			nil,
+			[]uint32{0},
+			nil,
+		).Return(nil)
+	suite.cachedJob.EXPECT().
+		RollbackWorkflow(gomock.Any()).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{InstanceCount: 5}, nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	hold, err := processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.canary,
+		canaryStageRolling,
+		nil,
+		[]uint32{0},
+		nil,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.True(hold)
+}
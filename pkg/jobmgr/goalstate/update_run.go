@@ -78,13 +78,15 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		cachedWorkflow.GetInstancesDone(),
 		instancesDoneFromLastRun...)
 
+	updateConfig := cachedWorkflow.GetUpdateConfig()
+
 	// number of failed instances in the workflow exceeds limit and
 	// max instance retries is set, process the failed workflow and
 	// return directly
 	// TODO: use job SLA if GetMaxFailureInstances is not set
-	if cachedWorkflow.GetUpdateConfig().GetMaxFailureInstances() != 0 &&
+	if updateConfig.GetMaxFailureInstances() != 0 &&
 		uint32(len(instancesFailed)) >=
-			cachedWorkflow.GetUpdateConfig().GetMaxFailureInstances() {
+			updateConfig.GetMaxFailureInstances() {
 		err := processFailedUpdate(
 			ctx,
 			cachedJob,
@@ -100,6 +102,37 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	// hold the update while the canary instances bake, and roll it
+	// back if the canary instances violate the success criteria
+	stage := getCanaryStage(
+		cachedWorkflow,
+		updateConfig.GetCanary(),
+		instancesCurrent,
+		instancesDone,
+		instancesFailed,
+	)
+	if stage != canaryStageNone {
+		hold, err := processCanary(
+			ctx,
+			cachedJob,
+			cachedWorkflow,
+			updateConfig.GetCanary(),
+			stage,
+			instancesDone,
+			instancesFailed,
+			instancesCurrent,
+			goalStateDriver,
+		)
+		if err != nil {
+			goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+			return err
+		}
+		if hold {
+			goalStateDriver.mtx.updateMetrics.UpdateRun.Inc(1)
+			return nil
+		}
+	}
+
 	instancesToAdd, instancesToUpdate, instancesToRemove :=
 		getInstancesForUpdateRun(
 			ctx,
@@ -110,6 +143,19 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 			instancesFailed,
 		)
 
+	// only the canary instances may be updated before the
+	// canary is promoted
+	if stage == canaryStageRolling {
+		instancesToAdd, instancesToUpdate, instancesToRemove =
+			limitInstancesToProcess(
+				int(getCanaryInstanceCount(cachedWorkflow, updateConfig.GetCanary()))-
+					len(instancesCurrent)-len(instancesDone)-len(instancesFailed),
+				instancesToAdd,
+				instancesToUpdate,
+				instancesToRemove,
+			)
+	}
+
 	instancesToAdd, instancesToUpdate, instancesToRemove, instancesRemovedDone, err :=
 		confirmInstancesStatus(
 			ctx,
@@ -203,54 +249,16 @@ func processFailedUpdate(
 	// the update itself is not a rollback
 	if cachedUpdate.GetUpdateConfig().RollbackOnFailure &&
 		!isUpdateRollback(cachedUpdate) {
-		// write the progress first, because when rollback happens,
-		// workflow does not know the newly finished/failed instances.
-		cachedJob.WriteWorkflowProgress(
+		if err := rollbackUpdate(
 			ctx,
-			cachedUpdate.ID(),
-			cachedUpdate.GetState().State,
+			cachedJob,
+			cachedUpdate,
 			instancesDone,
 			instancesFailed,
 			instancesCurrent,
-		)
-
-		if err := cachedJob.RollbackWorkflow(ctx); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to rollback update")
-			return err
-		}
-
-		cachedConfig, err := cachedJob.GetConfig(ctx)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to get job config to rollback update")
-			return err
-		}
-
-		if err := handleUnchangedInstancesInUpdate(
-			ctx,
-			cachedUpdate,
-			cachedJob,
-			cachedConfig,
 		); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to update unchanged instances to rollback update")
 			return err
 		}
-
-		log.WithFields(log.Fields{
-			"update_id": cachedUpdate.ID().GetValue(),
-			"job_id":    cachedJob.ID().GetValue(),
-		}).Info("update rolling back")
 	} else {
 		if err := cachedJob.WriteWorkflowProgress(
 			ctx,
@@ -268,6 +276,67 @@ func processFailedUpdate(
 	return nil
 }
 
+// rollbackUpdate rolls back the update to the previous job
+// configuration using the rollback workflow.
+func rollbackUpdate(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+) error {
+	// write the progress first, because when rollback happens,
+	// workflow does not know the newly finished/failed instances.
+	cachedJob.WriteWorkflowProgress(
+		ctx,
+		cachedUpdate.ID(),
+		cachedUpdate.GetState().State,
+		instancesDone,
+		instancesFailed,
+		instancesCurrent,
+	)
+
+	if err := cachedJob.RollbackWorkflow(ctx); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to rollback update")
+		return err
+	}
+
+	cachedConfig, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to get job config to rollback update")
+		return err
+	}
+
+	if err := handleUnchangedInstancesInUpdate(
+		ctx,
+		cachedUpdate,
+		cachedJob,
+		cachedConfig,
+	); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to update unchanged instances to rollback update")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": cachedUpdate.ID().GetValue(),
+		"job_id":    cachedJob.ID().GetValue(),
+	}).Info("update rolling back")
+	return nil
+}
+
 // isUpdateRollback returns if an update is a rolling back to a
 // previous version
 func isUpdateRollback(cachedUpdate cached.Update) bool {
@@ -653,7 +722,8 @@ func removeInstancesInUpdate(
 			jobmgrcommon.TerminationStatusField: &pbtask.TerminationStatus{
 				Reason: pbtask.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE,
 			},
-			jobmgrcommon.FailureCountField: uint32(0),
+			jobmgrcommon.FailureCountField:   uint32(0),
+			jobmgrcommon.CrashLoopCountField: uint32(0),
 		}
 	}
 
@@ -2754,7 +2754,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateFailed() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
@@ -2891,7 +2891,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBack() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range totalInstancesToUpdate {
 		if uint32(i) < failedInstances {
@@ -3057,7 +3057,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBackFail() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range totalInstancesToUpdate {
 		if uint32(i) < failedInstances {
@@ -3200,7 +3200,7 @@ func (suite *UpdateRunTestSuite) TestUpdateRollingBackFailed() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
@@ -3360,7 +3360,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdatePartialFailure() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
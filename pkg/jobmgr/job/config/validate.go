@@ -62,6 +62,21 @@ var (
 		"Data field not set in executor config")
 	errIncorrectRevocableSLA = yarpcerrors.InvalidArgumentErrorf(
 		"revocable job must be preemptible")
+	errDaemonInstanceCount = yarpcerrors.InvalidArgumentErrorf(
+		"InstanceCount should be 0 for daemon job, " +
+			"instances are added for each eligible host")
+	errDaemonInstanceConfig = yarpcerrors.InvalidArgumentErrorf(
+		"InstanceConfig should not be set for daemon job")
+	errDaemonMissingCommand = yarpcerrors.InvalidArgumentErrorf(
+		"missing command info in default config of daemon job")
+	errDaemonController = yarpcerrors.InvalidArgumentErrorf(
+		"daemon job should not have a controller task")
+	errInvalidBackoffMultiplier = yarpcerrors.InvalidArgumentErrorf(
+		"restart policy backoff multiplier should be at least 1")
+	errInvalidBackoffJitter = yarpcerrors.InvalidArgumentErrorf(
+		"restart policy jitter should be between 0 and 1")
+	errInvalidMaxBackoff = yarpcerrors.InvalidArgumentErrorf(
+		"restart policy max backoff should not be less than initial backoff")
 	errInvalidPreemptionOverride = yarpcerrors.InvalidArgumentErrorf(
 		"can't override the preemption policy of a task" +
 			" which is going to be a part of a gang having tasks with" +
@@ -70,11 +85,13 @@ var (
 	_jobTypeTaskValidate = map[job.JobType]func(*task.TaskConfig) error{
 		job.JobType_BATCH:   validateBatchTaskConfig,
 		job.JobType_SERVICE: validateStatelessTaskConfig,
+		job.JobType_DAEMON:  validateStatelessTaskConfig,
 	}
 
 	_jobTypeJobValidate = map[job.JobType]func(*job.JobConfig) error{
 		job.JobType_BATCH:   validateBatchJobConfig,
 		job.JobType_SERVICE: validateStatelessJobConfig,
+		job.JobType_DAEMON:  validateDaemonJobConfig,
 	}
 )
 
@@ -222,6 +239,10 @@ func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32
 			restartPolicy.MaxFailures = _maxTaskRetries
 		}
 
+		if err := validateRestartPolicy(restartPolicy); err != nil {
+			return errInvalidTaskConfig(i, err)
+		}
+
 		if err := validatePortConfig(taskConfig); err != nil {
 			return errInvalidTaskConfig(i, err)
 		}
@@ -293,6 +314,23 @@ func validatePreemptionPolicy(instanceID uint32, taskConfig *task.TaskConfig,
 	return nil
 }
 
+// validateRestartPolicy validates the restart backoff of a task
+func validateRestartPolicy(restartPolicy *task.RestartPolicy) error {
+	if restartPolicy.GetBackoffMultiplier() != 0 &&
+		restartPolicy.GetBackoffMultiplier() < 1 {
+		return errInvalidBackoffMultiplier
+	}
+	if restartPolicy.GetJitter() < 0 || restartPolicy.GetJitter() > 1 {
+		return errInvalidBackoffJitter
+	}
+	if restartPolicy.GetMaxBackoffSecs() != 0 &&
+		restartPolicy.GetMaxBackoffSecs() <
+			restartPolicy.GetInitialBackoffSecs() {
+		return errInvalidMaxBackoff
+	}
+	return nil
+}
+
 // validatePortConfig checks port name and port env name exists for dynamic port.
 func validatePortConfig(taskConfig *task.TaskConfig) error {
 	portConfigs := taskConfig.GetPorts()
@@ -371,3 +409,33 @@ func validateStatelessJobConfig(jobConfig *job.JobConfig) error {
 
 	return nil
 }
+
+// validateDaemonJobConfig validate jobconfig for daemon job. The instances of
+// a daemon job are added and removed by job manager as hosts become eligible
+// or ineligible, so only the default config can be specified.
+func validateDaemonJobConfig(jobConfig *job.JobConfig) error {
+	if err := validateStatelessJobConfig(jobConfig); err != nil {
+		return err
+	}
+
+	if jobConfig.GetInstanceCount() != 0 {
+		return errDaemonInstanceCount
+	}
+
+	if len(jobConfig.GetInstanceConfig()) != 0 {
+		return errDaemonInstanceConfig
+	}
+
+	// the job is created without instances, so validate
+	// the default config which is used for each of them
+	defaultConfig := jobConfig.GetDefaultConfig()
+	if defaultConfig.GetCommand() == nil {
+		return errDaemonMissingCommand
+	}
+
+	if defaultConfig.GetController() {
+		return errDaemonController
+	}
+
+	return validateStatelessTaskConfig(defaultConfig)
+}
@@ -427,6 +427,49 @@ func TestValidatePortConfigMissingEnvName_NoFailureCustomExecutor(t *testing.T)
 	assert.NoError(t, err)
 }
 
+// TestValidateRestartPolicy verifies validateRestartPolicy rejects
+// invalid restart backoffs.
+func TestValidateRestartPolicy(t *testing.T) {
+	tt := []struct {
+		restartPolicy *task.RestartPolicy
+		err           error
+	}{
+		{
+			restartPolicy: nil,
+		},
+		{
+			restartPolicy: &task.RestartPolicy{
+				MaxFailures:           10,
+				InitialBackoffSecs:    5,
+				BackoffMultiplier:     1.5,
+				MaxBackoffSecs:        300,
+				Jitter:                0.2,
+				ResetAfterHealthySecs: 600,
+				CrashLoopThreshold:    5,
+			},
+		},
+		{
+			restartPolicy: &task.RestartPolicy{BackoffMultiplier: 0.5},
+			err:           errInvalidBackoffMultiplier,
+		},
+		{
+			restartPolicy: &task.RestartPolicy{Jitter: 1.5},
+			err:           errInvalidBackoffJitter,
+		},
+		{
+			restartPolicy: &task.RestartPolicy{
+				InitialBackoffSecs: 60,
+				MaxBackoffSecs:     30,
+			},
+			err: errInvalidMaxBackoff,
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.err, validateRestartPolicy(test.restartPolicy))
+	}
+}
+
 func TestValidateTaskConfigWithInvalidFieldType(t *testing.T) {
 	// Validates task config field type is string/ptr/slice/bool, otherwise
 	// we cannot distinguish between unset value and default value through
@@ -551,6 +594,92 @@ func TestValidateStatelessJobConfig(t *testing.T) {
 
 }
 
+// TestValidateDaemonJobConfig tests validation of daemon job config
+func TestValidateDaemonJobConfig(t *testing.T) {
+	command := &mesos.CommandInfo{Value: util.PtrPrintf("echo hello")}
+	testCases := []struct {
+		name      string
+		jobConfig *job.JobConfig
+		err       error
+	}{
+		{
+			name: "valid config",
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{Command: command},
+			},
+		},
+		{
+			name: "instance count set",
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{Command: command},
+			},
+			err: errDaemonInstanceCount,
+		},
+		{
+			name: "instance config set",
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{Command: command},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					0: {Name: "instance0"},
+				},
+			},
+			err: errDaemonInstanceConfig,
+		},
+		{
+			name: "missing command",
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{},
+			},
+			err: errDaemonMissingCommand,
+		},
+		{
+			name: "controller task",
+			jobConfig: &job.JobConfig{
+				Type: job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{
+					Command:    command,
+					Controller: true,
+				},
+			},
+			err: errDaemonController,
+		},
+		{
+			name: "kill on preempt",
+			jobConfig: &job.JobConfig{
+				Type: job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{
+					Command: command,
+					PreemptionPolicy: &task.PreemptionPolicy{
+						KillOnPreempt: true,
+					},
+				},
+			},
+			err: errKillOnPreemptNotFalse,
+		},
+		{
+			name: "min running instances set",
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{Command: command},
+				SLA:           &job.SlaConfig{MinimumRunningInstances: 1},
+			},
+			err: errIncorrectMinInstancesSLA,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.err, validateDaemonJobConfig(tc.jobConfig), tc.name)
+	}
+
+	// a valid daemon job config passes the full validation
+	assert.NoError(t, ValidateConfig(testCases[0].jobConfig, maxTasksPerJob))
+}
+
 func TestValidateStatelessTaskConfig(t *testing.T) {
 	testCases := []struct {
 		task.PreemptionPolicy
@@ -0,0 +1,696 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	v1alphaquery "github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/common/util"
+	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	yarpcutil "github.com/uber/peloton/pkg/common/util/yarpc"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	jobconfig "github.com/uber/peloton/pkg/jobmgr/job/config"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type serviceHandler struct {
+	jobStore        storage.JobStore
+	taskStore       storage.TaskStore
+	jobIndexOps     ormobjects.JobIndexOps
+	jobConfigOps    ormobjects.JobConfigOps
+	jobRuntimeOps   ormobjects.JobRuntimeOps
+	respoolClient   respool.ResourceManagerYARPCClient
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	candidate       leader.Candidate
+	jobSvcCfg       jobsvc.Config
+}
+
+var (
+	errNullResourcePoolID   = yarpcerrors.InvalidArgumentErrorf("resource pool ID is null")
+	errResourcePoolNotFound = yarpcerrors.NotFoundErrorf("resource pool not found")
+	errRootResourcePoolID   = yarpcerrors.InvalidArgumentErrorf("cannot submit jobs to the `root` resource pool")
+	errNonLeafResourcePool  = yarpcerrors.InvalidArgumentErrorf("cannot submit jobs to a non leaf resource pool")
+	errNotBatchJob          = yarpcerrors.InvalidArgumentErrorf("job is not a batch job")
+)
+
+// InitV1AlphaBatchJobServiceHandler initializes the Job Manager V1Alpha
+// Batch Job Service Handler
+func InitV1AlphaBatchJobServiceHandler(
+	d *yarpc.Dispatcher,
+	jobStore storage.JobStore,
+	taskStore storage.TaskStore,
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	candidate leader.Candidate,
+	jobSvcCfg jobsvc.Config,
+) {
+	handler := &serviceHandler{
+		jobStore:      jobStore,
+		taskStore:     taskStore,
+		jobIndexOps:   ormobjects.NewJobIndexOps(ormStore),
+		jobConfigOps:  ormobjects.NewJobConfigOps(ormStore),
+		jobRuntimeOps: ormobjects.NewJobRuntimeOps(ormStore),
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager),
+		),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		candidate:       candidate,
+		jobSvcCfg:       jobSvcCfg,
+	}
+	d.Register(svc.BuildJobServiceYARPCProcedures(handler))
+}
+
+func (h *serviceHandler) CreateJob(
+	ctx context.Context,
+	req *svc.CreateJobRequest,
+) (resp *svc.CreateJobResponse, err error) {
+	defer func() {
+		jobID := req.GetJobId().GetValue()
+		instanceCount := req.GetSpec().GetInstanceCount()
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			log.WithField("job_id", jobID).
+				WithField("instance_count", instanceCount).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.CreateJob failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("job_id", jobID).
+			WithField("response", resp).
+			WithField("instance_count", instanceCount).
+			WithField("headers", headers).
+			Info("BatchJobSVC.CreateJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("BatchJobSVC.CreateJob is not supported on non-leader")
+	}
+
+	pelotonJobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
+
+	// It is possible that jobId is nil since protobuf doesn't enforce it
+	if len(pelotonJobID.GetValue()) == 0 {
+		pelotonJobID = &peloton.JobID{Value: uuid.New()}
+	}
+
+	if uuid.Parse(pelotonJobID.GetValue()) == nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf("jobID is not valid UUID")
+	}
+
+	jobSpec := req.GetSpec()
+
+	respoolPath, err := h.validateResourcePoolForJobCreation(ctx, jobSpec.GetRespoolId())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to validate resource pool")
+	}
+
+	if err := handlerutil.CheckResourceAccess(ctx, &auth.Resource{
+		ResourcePoolPath: respoolPath.GetValue(),
+		Owner:            jobSpec.GetOwningTeam(),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckResourcePoolRateLimit(
+		ctx, respoolPath.GetValue()); err != nil {
+		return nil, err
+	}
+
+	jobConfig, err := api.ConvertBatchJobSpecToJobConfig(jobSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert job spec")
+	}
+
+	// Validate job config with default task configs
+	if err := jobconfig.ValidateConfig(
+		jobConfig,
+		h.jobSvcCfg.MaxTasksPerJob,
+	); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid job spec: %v", err)
+	}
+
+	// Create job in cache and db
+	cachedJob := h.jobFactory.AddJob(pelotonJobID)
+
+	systemLabels := jobutil.ConstructSystemLabels(jobConfig, respoolPath.GetValue())
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: systemLabels,
+	}
+
+	err = cachedJob.Create(ctx, jobConfig, configAddOn, nil)
+
+	// enqueue the job into goal state engine even in failure case,
+	// because the job may be partially created. Goal state engine
+	// knows if the job can be recovered.
+	h.goalStateDriver.EnqueueJob(pelotonJobID, time.Now())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create job in db")
+	}
+
+	runtimeInfo, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job runtime from cache")
+	}
+
+	return &svc.CreateJobResponse{
+		JobId: &v1alphapeloton.JobID{Value: pelotonJobID.GetValue()},
+		Version: versionutil.GetJobEntityVersion(
+			runtimeInfo.GetConfigurationVersion(),
+			runtimeInfo.GetDesiredStateVersion(),
+			runtimeInfo.GetWorkflowVersion(),
+		),
+	}, nil
+}
+
+func (h *serviceHandler) GetJob(
+	ctx context.Context,
+	req *svc.GetJobRequest,
+) (resp *svc.GetJobResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.GetJob failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Debug("BatchJobSVC.GetJob succeeded")
+	}()
+
+	pelotonJobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
+
+	// Get the summary only
+	if req.GetSummaryOnly() {
+		jobSummary, err := h.jobIndexOps.GetSummary(ctx, pelotonJobID)
+		if err != nil {
+			if err == gocql.ErrNotFound {
+				return nil, yarpcerrors.NotFoundErrorf(
+					"job:%s not found", pelotonJobID.GetValue())
+			}
+			return nil, errors.Wrap(err, "failed to get job summary from DB")
+		}
+		if jobSummary.GetType() != pbjob.JobType_BATCH {
+			return nil, errNotBatchJob
+		}
+		return &svc.GetJobResponse{
+			Summary: api.ConvertJobSummary(jobSummary, nil),
+		}, nil
+	}
+
+	jobRuntime, err := h.jobRuntimeOps.Get(ctx, pelotonJobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job status")
+	}
+
+	jobConfig, _, err := h.jobConfigOps.Get(
+		ctx,
+		pelotonJobID,
+		jobRuntime.GetConfigurationVersion(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job spec")
+	}
+	if jobConfig.GetType() != pbjob.JobType_BATCH {
+		return nil, errNotBatchJob
+	}
+
+	// Do not display the secret volumes in default config,
+	// they are internal to peloton.
+	util.RemoveSecretVolumesFromJobConfig(jobConfig)
+
+	return &svc.GetJobResponse{
+		JobInfo: &batch.JobInfo{
+			JobId:  req.GetJobId(),
+			Spec:   api.ConvertJobConfigToBatchJobSpec(jobConfig),
+			Status: api.ConvertRuntimeInfoToJobStatus(jobRuntime, nil),
+		},
+	}, nil
+}
+
+func (h *serviceHandler) QueryJobs(
+	ctx context.Context,
+	req *svc.QueryJobsRequest,
+) (resp *svc.QueryJobsResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.QueryJobs failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			WithField("num_of_results", len(resp.GetRecords())).
+			Debug("BatchJobSVC.QueryJobs succeeded")
+	}()
+
+	var respoolID *peloton.ResourcePoolID
+	if len(req.GetSpec().GetRespool().GetValue()) > 0 {
+		respoolResp, err := h.respoolClient.LookupResourcePoolID(ctx, &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: req.GetSpec().GetRespool().GetValue()},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get respool id")
+		}
+		respoolID = respoolResp.GetId()
+	}
+
+	querySpec := api.ConvertStatelessQuerySpecToJobQuerySpec(req.GetSpec())
+
+	_, jobSummaries, total, err := h.jobStore.QueryJobs(
+		ctx,
+		respoolID,
+		querySpec,
+		true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job summaries")
+	}
+
+	var records []*stateless.JobSummary
+	for _, jobSummary := range jobSummaries {
+		if jobSummary.GetType() != pbjob.JobType_BATCH {
+			continue
+		}
+		records = append(records, api.ConvertJobSummary(jobSummary, nil))
+	}
+
+	return &svc.QueryJobsResponse{
+		Records: records,
+		Pagination: &v1alphaquery.Pagination{
+			Offset: req.GetSpec().GetPagination().GetOffset(),
+			Limit:  req.GetSpec().GetPagination().GetLimit(),
+			Total:  total,
+		},
+		Spec: req.GetSpec(),
+	}, nil
+}
+
+func (h *serviceHandler) StopJob(
+	ctx context.Context,
+	req *svc.StopJobRequest,
+) (resp *svc.StopJobResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.StopJob failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			WithField("headers", headers).
+			Info("BatchJobSVC.StopJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("BatchJobSVC.StopJob is not supported on non-leader")
+	}
+
+	jobRuntime, err := h.setGoalState(
+		ctx,
+		req.GetJobId(),
+		req.GetVersion(),
+		pbjob.JobState_KILLED,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.StopJobResponse{
+		Version: versionutil.GetJobEntityVersion(
+			jobRuntime.GetConfigurationVersion(),
+			jobRuntime.GetDesiredStateVersion(),
+			jobRuntime.GetWorkflowVersion(),
+		),
+	}, nil
+}
+
+func (h *serviceHandler) DeleteJob(
+	ctx context.Context,
+	req *svc.DeleteJobRequest,
+) (resp *svc.DeleteJobResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.DeleteJob failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Info("BatchJobSVC.DeleteJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("BatchJobSVC.DeleteJob is not supported on non-leader")
+	}
+
+	if _, err := h.setGoalState(
+		ctx,
+		req.GetJobId(),
+		req.GetVersion(),
+		pbjob.JobState_DELETED,
+		!req.GetForce(),
+	); err != nil {
+		return nil, err
+	}
+
+	return &svc.DeleteJobResponse{}, nil
+}
+
+// setGoalState sets the goal state of a batch job and enqueues the job
+// into the goal state engine. If requireTerminal is set, the job must be
+// in a terminal state.
+func (h *serviceHandler) setGoalState(
+	ctx context.Context,
+	jobID *v1alphapeloton.JobID,
+	version *v1alphapeloton.EntityVersion,
+	goalState pbjob.JobState,
+	requireTerminal bool,
+) (*pbjob.RuntimeInfo, error) {
+	pelotonJobID := &peloton.JobID{Value: jobID.GetValue()}
+
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		pelotonJobID,
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		pelotonJobID,
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	cachedJob := h.jobFactory.AddJob(pelotonJobID)
+
+	config, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job config")
+	}
+	if config.GetType() != pbjob.JobType_BATCH {
+		return nil, errNotBatchJob
+	}
+
+	count := 0
+	for {
+		jobRuntime, err := cachedJob.GetRuntime(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get job runtime")
+		}
+
+		entityVersion := versionutil.GetJobEntityVersion(
+			jobRuntime.GetConfigurationVersion(),
+			jobRuntime.GetDesiredStateVersion(),
+			jobRuntime.GetWorkflowVersion(),
+		)
+		if entityVersion.GetValue() != version.GetValue() {
+			return nil, jobmgrcommon.InvalidEntityVersionError
+		}
+
+		if requireTerminal &&
+			!util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+			return nil, yarpcerrors.AbortedErrorf("job is not in terminal state")
+		}
+
+		jobRuntime.GoalState = goalState
+		jobRuntime.DesiredStateVersion++
+
+		if jobRuntime, err = cachedJob.CompareAndSetRuntime(ctx, jobRuntime); err != nil {
+			if err == jobmgrcommon.UnexpectedVersionError {
+				// concurrency error; retry MaxConcurrencyErrorRetry times
+				count = count + 1
+				if count < jobmgrcommon.MaxConcurrencyErrorRetry {
+					continue
+				}
+			}
+			// it is uncertain whether job runtime is updated successfully,
+			// let goal state engine figure it out.
+			h.goalStateDriver.EnqueueJob(cachedJob.ID(), time.Now())
+			return nil, errors.Wrap(err, "fail to update job runtime")
+		}
+
+		h.goalStateDriver.EnqueueJob(cachedJob.ID(), time.Now())
+		return jobRuntime, nil
+	}
+}
+
+func (h *serviceHandler) ListPods(
+	req *svc.ListPodsRequest,
+	stream svc.JobServiceServiceListPodsYARPCServer,
+) (err error) {
+	var instanceRange *task.InstanceRange
+
+	defer func() {
+		headers := yarpcutil.GetHeaders(stream.Context())
+		if err != nil {
+			log.WithError(err).
+				WithField("job_id", req.GetJobId().GetValue()).
+				WithField("headers", headers).
+				Warn("BatchJobSVC.ListPods failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("job_id", req.GetJobId().GetValue()).
+			WithField("headers", headers).
+			Debug("BatchJobSVC.ListPods succeeded")
+	}()
+
+	if req.GetRange() != nil {
+		instanceRange = &task.InstanceRange{
+			From: req.GetRange().GetFrom(),
+			To:   req.GetRange().GetTo(),
+		}
+	}
+
+	taskRuntimes, err := h.taskStore.GetTaskRuntimesForJobByRange(
+		stream.Context(),
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		instanceRange,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get tasks")
+	}
+
+	for instID, taskRuntime := range taskRuntimes {
+		resp := &svc.ListPodsResponse{
+			Pods: []*pod.PodSummary{
+				{
+					PodName: &v1alphapeloton.PodName{
+						Value: util.CreatePelotonTaskID(req.GetJobId().GetValue(), instID),
+					},
+					Status: api.ConvertTaskRuntimeToPodStatus(taskRuntime),
+				},
+			},
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *serviceHandler) GetJobCache(
+	ctx context.Context,
+	req *svc.GetJobCacheRequest,
+) (resp *svc.GetJobCacheResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("BatchJobSVC.GetJobCache failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			WithField("headers", headers).
+			Debug("BatchJobSVC.GetJobCache succeeded")
+	}()
+
+	cachedJob := h.jobFactory.GetJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
+	if cachedJob == nil {
+		return nil,
+			yarpcerrors.NotFoundErrorf("job not found in cache")
+	}
+
+	runtime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get job runtime")
+	}
+
+	config, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get job config")
+	}
+	if config.GetType() != pbjob.JobType_BATCH {
+		return nil, errNotBatchJob
+	}
+
+	return &svc.GetJobCacheResponse{
+		Spec:   convertCacheJobConfigToJobSpec(config),
+		Status: convertCacheToJobStatus(runtime),
+	}, nil
+}
+
+// validateResourcePoolForJobCreation validates the resource pool before submitting job
+func (h *serviceHandler) validateResourcePoolForJobCreation(
+	ctx context.Context,
+	respoolID *v1alphapeloton.ResourcePoolID,
+) (*respool.ResourcePoolPath, error) {
+	if respoolID == nil {
+		return nil, errNullResourcePoolID
+	}
+
+	if respoolID.GetValue() == common.RootResPoolID {
+		return nil, errRootResourcePoolID
+	}
+
+	request := &respool.GetRequest{
+		Id: &peloton.ResourcePoolID{Value: respoolID.GetValue()},
+	}
+	response, err := h.respoolClient.GetResourcePool(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.GetPoolinfo().GetId() == nil ||
+		response.GetPoolinfo().GetId().GetValue() != respoolID.GetValue() {
+		return nil, errResourcePoolNotFound
+	}
+
+	if len(response.GetPoolinfo().GetChildren()) > 0 {
+		return nil, errNonLeafResourcePool
+	}
+
+	return response.GetPoolinfo().GetPath(), nil
+}
+
+func convertCacheJobConfigToJobSpec(config jobmgrcommon.JobConfig) *batch.JobSpec {
+	result := &batch.JobSpec{}
+	// set the fields used by both job config and cached job config
+	result.InstanceCount = config.GetInstanceCount()
+	result.RespoolId = &v1alphapeloton.ResourcePoolID{
+		Value: config.GetRespoolID().GetValue(),
+	}
+	if config.GetSLA() != nil {
+		result.Sla = &batch.SlaSpec{
+			Priority:                config.GetSLA().GetPriority(),
+			Preemptible:             config.GetSLA().GetPreemptible(),
+			Revocable:               config.GetSLA().GetRevocable(),
+			MaximumRunningInstances: config.GetSLA().GetMaximumRunningInstances(),
+			MinimumRunningInstances: config.GetSLA().GetMinimumRunningInstances(),
+			MaxRunningTime:          config.GetSLA().GetMaxRunningTime(),
+		}
+	}
+	result.Revision = &v1alphapeloton.Revision{
+		Version:   config.GetChangeLog().GetVersion(),
+		CreatedAt: config.GetChangeLog().GetCreatedAt(),
+		UpdatedAt: config.GetChangeLog().GetUpdatedAt(),
+		UpdatedBy: config.GetChangeLog().GetUpdatedBy(),
+	}
+	return result
+}
+
+func convertCacheToJobStatus(
+	runtime *pbjob.RuntimeInfo,
+) *stateless.JobStatus {
+	return &stateless.JobStatus{
+		Revision: &v1alphapeloton.Revision{
+			Version:   runtime.GetRevision().GetVersion(),
+			CreatedAt: runtime.GetRevision().GetCreatedAt(),
+			UpdatedAt: runtime.GetRevision().GetUpdatedAt(),
+			UpdatedBy: runtime.GetRevision().GetUpdatedBy(),
+		},
+		State:        stateless.JobState(runtime.GetState()),
+		CreationTime: runtime.GetCreationTime(),
+		PodStats:     api.ConvertTaskStatsToPodStats(runtime.TaskStats),
+		DesiredState: stateless.JobState(runtime.GetGoalState()),
+		Version: versionutil.GetJobEntityVersion(
+			runtime.GetConfigurationVersion(),
+			runtime.GetDesiredStateVersion(),
+			runtime.GetWorkflowVersion()),
+	}
+}
@@ -0,0 +1,582 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch"
+	batchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc"
+	batchsvcmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/batch/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/common"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	testJobID                = "481d565e-28da-457d-8434-f6bb7faa0e95"
+	testEntityVersion        = "2-3-4"
+	testConfigurationVersion = uint64(2)
+	testDesiredStateVersion  = uint64(3)
+	testWorkflowVersion      = uint64(4)
+)
+
+var (
+	testRespoolID = &v1alphapeloton.ResourcePoolID{
+		Value: "test-respool",
+	}
+	testCmd          = "echo test"
+	testPelotonJobID = &peloton.JobID{Value: testJobID}
+)
+
+type batchHandlerTestSuite struct {
+	suite.Suite
+
+	handler *serviceHandler
+
+	ctrl            *gomock.Controller
+	cachedJob       *cachedmocks.MockJob
+	jobFactory      *cachedmocks.MockJobFactory
+	candidate       *leadermocks.MockCandidate
+	respoolClient   *respoolmocks.MockResourceManagerYARPCClient
+	goalStateDriver *goalstatemocks.MockDriver
+	jobStore        *storemocks.MockJobStore
+	taskStore       *storemocks.MockTaskStore
+	listPodsServer  *batchsvcmocks.MockJobServiceServiceListPodsYARPCServer
+	jobIndexOps     *objectmocks.MockJobIndexOps
+	jobConfigOps    *objectmocks.MockJobConfigOps
+	jobRuntimeOps   *objectmocks.MockJobRuntimeOps
+}
+
+func (suite *batchHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
+	suite.taskStore = storemocks.NewMockTaskStore(suite.ctrl)
+	suite.jobIndexOps = objectmocks.NewMockJobIndexOps(suite.ctrl)
+	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.ctrl)
+	suite.jobRuntimeOps = objectmocks.NewMockJobRuntimeOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.listPodsServer = batchsvcmocks.NewMockJobServiceServiceListPodsYARPCServer(suite.ctrl)
+	suite.listPodsServer.EXPECT().Context().Return(context.Background()).AnyTimes()
+	suite.handler = &serviceHandler{
+		jobFactory:      suite.jobFactory,
+		candidate:       suite.candidate,
+		goalStateDriver: suite.goalStateDriver,
+		jobStore:        suite.jobStore,
+		taskStore:       suite.taskStore,
+		jobIndexOps:     suite.jobIndexOps,
+		jobConfigOps:    suite.jobConfigOps,
+		jobRuntimeOps:   suite.jobRuntimeOps,
+		respoolClient:   suite.respoolClient,
+		jobSvcCfg: jobsvc.Config{
+			MaxTasksPerJob: 100000,
+		},
+	}
+}
+
+func (suite *batchHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestBatchServiceHandler(t *testing.T) {
+	suite.Run(t, new(batchHandlerTestSuite))
+}
+
+func (suite *batchHandlerTestSuite) testRuntime(
+	state pbjob.JobState,
+) *pbjob.RuntimeInfo {
+	return &pbjob.RuntimeInfo{
+		State:                state,
+		GoalState:            pbjob.JobState_SUCCEEDED,
+		ConfigurationVersion: testConfigurationVersion,
+		DesiredStateVersion:  testDesiredStateVersion,
+		WorkflowVersion:      testWorkflowVersion,
+	}
+}
+
+// TestCreateJobSuccess tests the success case of creating a batch job
+func (suite *batchHandlerTestSuite) TestCreateJobSuccess() {
+	jobSpec := &batch.JobSpec{
+		InstanceCount: 3,
+		DefaultSpec: &pod.PodSpec{
+			Containers: []*pod.ContainerSpec{
+				{
+					Command: &mesos.CommandInfo{Value: &testCmd},
+				},
+			},
+		},
+		RespoolId: testRespoolID,
+	}
+
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+
+		suite.respoolClient.EXPECT().
+			GetResourcePool(
+				gomock.Any(),
+				&respool.GetRequest{
+					Id: &peloton.ResourcePoolID{Value: testRespoolID.GetValue()},
+				},
+			).Return(
+			&respool.GetResponse{
+				Poolinfo: &respool.ResourcePoolInfo{
+					Id: &peloton.ResourcePoolID{Value: testRespoolID.GetValue()},
+				},
+			}, nil),
+
+		suite.jobFactory.EXPECT().
+			AddJob(gomock.Any()).
+			Return(suite.cachedJob),
+
+		suite.cachedJob.EXPECT().
+			Create(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+			Do(func(
+				_ context.Context,
+				config *pbjob.JobConfig,
+				_ interface{},
+				_ interface{}) {
+				suite.Equal(pbjob.JobType_BATCH, config.GetType())
+				suite.Equal(uint32(3), config.GetInstanceCount())
+			}).
+			Return(nil),
+
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(gomock.Any(), gomock.Any()),
+
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(suite.testRuntime(pbjob.JobState_INITIALIZED), nil),
+	)
+
+	resp, err := suite.handler.CreateJob(
+		context.Background(),
+		&batchsvc.CreateJobRequest{Spec: jobSpec},
+	)
+	suite.NoError(err)
+	suite.NotEmpty(resp.GetJobId().GetValue())
+	suite.Equal(testEntityVersion, resp.GetVersion().GetValue())
+}
+
+// TestCreateJobFailNonLeader tests creating a job on a non-leader jobmgr
+func (suite *batchHandlerTestSuite) TestCreateJobFailNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	resp, err := suite.handler.CreateJob(
+		context.Background(),
+		&batchsvc.CreateJobRequest{},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestCreateJobFailRootResourcePool tests submitting a job to the
+// root resource pool
+func (suite *batchHandlerTestSuite) TestCreateJobFailRootResourcePool() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+
+	resp, err := suite.handler.CreateJob(
+		context.Background(),
+		&batchsvc.CreateJobRequest{
+			Spec: &batch.JobSpec{
+				RespoolId: &v1alphapeloton.ResourcePoolID{
+					Value: common.RootResPoolID,
+				},
+			},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestCreateJobFailInvalidJobID tests creating a job with a non UUID id
+func (suite *batchHandlerTestSuite) TestCreateJobFailInvalidJobID() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+
+	resp, err := suite.handler.CreateJob(
+		context.Background(),
+		&batchsvc.CreateJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: "not-a-uuid"},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestGetJobSuccess tests getting the spec and status of a batch job
+func (suite *batchHandlerTestSuite) TestGetJobSuccess() {
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID).
+		Return(suite.testRuntime(pbjob.JobState_RUNNING), nil)
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID, testConfigurationVersion).
+		Return(&pbjob.JobConfig{
+			Type:          pbjob.JobType_BATCH,
+			Name:          "test-job",
+			InstanceCount: 3,
+			SLA: &pbjob.SlaConfig{
+				MaximumRunningInstances: 2,
+			},
+		}, nil, nil)
+
+	resp, err := suite.handler.GetJob(
+		context.Background(),
+		&batchsvc.GetJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal("test-job", resp.GetJobInfo().GetSpec().GetName())
+	suite.Equal(uint32(3), resp.GetJobInfo().GetSpec().GetInstanceCount())
+	suite.Equal(uint32(2),
+		resp.GetJobInfo().GetSpec().GetSla().GetMaximumRunningInstances())
+	suite.Equal(stateless.JobState_JOB_STATE_RUNNING,
+		resp.GetJobInfo().GetStatus().GetState())
+	suite.Equal(testEntityVersion,
+		resp.GetJobInfo().GetStatus().GetVersion().GetValue())
+}
+
+// TestGetJobFailNotBatch tests getting a job which is not a batch job
+func (suite *batchHandlerTestSuite) TestGetJobFailNotBatch() {
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID).
+		Return(suite.testRuntime(pbjob.JobState_RUNNING), nil)
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID, testConfigurationVersion).
+		Return(&pbjob.JobConfig{Type: pbjob.JobType_SERVICE}, nil, nil)
+
+	resp, err := suite.handler.GetJob(
+		context.Background(),
+		&batchsvc.GetJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestGetJobSummaryOnlyNotFound tests getting the summary of a
+// job which does not exist
+func (suite *batchHandlerTestSuite) TestGetJobSummaryOnlyNotFound() {
+	suite.jobIndexOps.EXPECT().
+		GetSummary(gomock.Any(), testPelotonJobID).
+		Return(nil, gocql.ErrNotFound)
+
+	resp, err := suite.handler.GetJob(
+		context.Background(),
+		&batchsvc.GetJobRequest{
+			JobId:       &v1alphapeloton.JobID{Value: testJobID},
+			SummaryOnly: true,
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestQueryJobsFiltersNonBatchJobs tests that jobs of other types
+// are not returned from QueryJobs
+func (suite *batchHandlerTestSuite) TestQueryJobsFiltersNonBatchJobs() {
+	suite.jobStore.EXPECT().
+		QueryJobs(gomock.Any(), nil, gomock.Any(), true).
+		Return(nil, []*pbjob.JobSummary{
+			{Id: testPelotonJobID, Type: pbjob.JobType_BATCH},
+			{Id: &peloton.JobID{Value: "other"}, Type: pbjob.JobType_SERVICE},
+		}, uint32(2), nil)
+
+	resp, err := suite.handler.QueryJobs(
+		context.Background(),
+		&batchsvc.QueryJobsRequest{Spec: &stateless.QuerySpec{}},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetRecords(), 1)
+	suite.Equal(testJobID, resp.GetRecords()[0].GetJobId().GetValue())
+	suite.Equal(uint32(2), resp.GetPagination().GetTotal())
+}
+
+// TestStopJobSuccess tests the success case of stopping a batch job
+func (suite *batchHandlerTestSuite) TestStopJobSuccess() {
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+		suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetConfig(gomock.Any()).
+			Return(&pbjob.JobConfig{Type: pbjob.JobType_BATCH}, nil),
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(suite.testRuntime(pbjob.JobState_RUNNING), nil),
+		suite.cachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				runtime *pbjob.RuntimeInfo,
+			) (*pbjob.RuntimeInfo, error) {
+				suite.Equal(pbjob.JobState_KILLED, runtime.GetGoalState())
+				suite.Equal(testDesiredStateVersion+1, runtime.GetDesiredStateVersion())
+				return runtime, nil
+			}),
+		suite.cachedJob.EXPECT().ID().Return(testPelotonJobID),
+		suite.goalStateDriver.EXPECT().EnqueueJob(testPelotonJobID, gomock.Any()),
+	)
+
+	resp, err := suite.handler.StopJob(
+		context.Background(),
+		&batchsvc.StopJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal("2-4-4", resp.GetVersion().GetValue())
+}
+
+// TestStopJobFailInvalidVersion tests stopping a job with a stale
+// entity version
+func (suite *batchHandlerTestSuite) TestStopJobFailInvalidVersion() {
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+		suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetConfig(gomock.Any()).
+			Return(&pbjob.JobConfig{Type: pbjob.JobType_BATCH}, nil),
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(suite.testRuntime(pbjob.JobState_RUNNING), nil),
+	)
+
+	resp, err := suite.handler.StopJob(
+		context.Background(),
+		&batchsvc.StopJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: "1-1-1"},
+		},
+	)
+	suite.Nil(resp)
+	suite.Equal(jobmgrcommon.InvalidEntityVersionError, err)
+}
+
+// TestStopJobFailNotBatch tests stopping a job which is not a batch job
+func (suite *batchHandlerTestSuite) TestStopJobFailNotBatch() {
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+		suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetConfig(gomock.Any()).
+			Return(&pbjob.JobConfig{Type: pbjob.JobType_SERVICE}, nil),
+	)
+
+	resp, err := suite.handler.StopJob(
+		context.Background(),
+		&batchsvc.StopJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestDeleteJobFailNonTerminal tests deleting a running job without force
+func (suite *batchHandlerTestSuite) TestDeleteJobFailNonTerminal() {
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+		suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetConfig(gomock.Any()).
+			Return(&pbjob.JobConfig{Type: pbjob.JobType_BATCH}, nil),
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(suite.testRuntime(pbjob.JobState_RUNNING), nil),
+	)
+
+	resp, err := suite.handler.DeleteJob(
+		context.Background(),
+		&batchsvc.DeleteJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsAborted(err))
+}
+
+// TestDeleteJobForceSuccess tests force deleting a running job
+func (suite *batchHandlerTestSuite) TestDeleteJobForceSuccess() {
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+		suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetConfig(gomock.Any()).
+			Return(&pbjob.JobConfig{Type: pbjob.JobType_BATCH}, nil),
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(suite.testRuntime(pbjob.JobState_RUNNING), nil),
+		suite.cachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				runtime *pbjob.RuntimeInfo,
+			) (*pbjob.RuntimeInfo, error) {
+				suite.Equal(pbjob.JobState_DELETED, runtime.GetGoalState())
+				return runtime, nil
+			}),
+		suite.cachedJob.EXPECT().ID().Return(testPelotonJobID),
+		suite.goalStateDriver.EXPECT().EnqueueJob(testPelotonJobID, gomock.Any()),
+	)
+
+	resp, err := suite.handler.DeleteJob(
+		context.Background(),
+		&batchsvc.DeleteJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			Force:   true,
+		},
+	)
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestStopJobRetryOnConcurrencyError tests that runtime updates are
+// retried on concurrency errors, and the job is still enqueued on failure
+func (suite *batchHandlerTestSuite) TestStopJobRetryOnConcurrencyError() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.jobFactory.EXPECT().AddJob(testPelotonJobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{Type: pbjob.JobType_BATCH}, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		DoAndReturn(func(context.Context) (*pbjob.RuntimeInfo, error) {
+			return suite.testRuntime(pbjob.JobState_RUNNING), nil
+		}).
+		Times(jobmgrcommon.MaxConcurrencyErrorRetry)
+	suite.cachedJob.EXPECT().
+		CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		Return(nil, jobmgrcommon.UnexpectedVersionError).
+		Times(jobmgrcommon.MaxConcurrencyErrorRetry)
+	suite.cachedJob.EXPECT().ID().Return(testPelotonJobID)
+	suite.goalStateDriver.EXPECT().EnqueueJob(testPelotonJobID, gomock.Any())
+
+	resp, err := suite.handler.StopJob(
+		context.Background(),
+		&batchsvc.StopJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		},
+	)
+	suite.Nil(resp)
+	suite.Error(err)
+}
+
+// TestListPodsSuccess tests streaming the pods of a batch job
+func (suite *batchHandlerTestSuite) TestListPodsSuccess() {
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), testPelotonJobID, nil).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: {State: pbtask.TaskState_RUNNING},
+		}, nil)
+	suite.listPodsServer.EXPECT().
+		Send(gomock.Any()).
+		Do(func(resp *batchsvc.ListPodsResponse) {
+			suite.Len(resp.GetPods(), 1)
+			suite.Equal(testJobID+"-0", resp.GetPods()[0].GetPodName().GetValue())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.handler.ListPods(
+		&batchsvc.ListPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+		suite.listPodsServer,
+	))
+}
+
+// TestListPodsFailStoreError tests ListPods when the task store fails
+func (suite *batchHandlerTestSuite) TestListPodsFailStoreError() {
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), testPelotonJobID, nil).
+		Return(nil, errors.New("test error"))
+
+	suite.Error(suite.handler.ListPods(
+		&batchsvc.ListPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+		suite.listPodsServer,
+	))
+}
+
+// TestGetJobCacheSuccess tests getting a batch job from the cache
+func (suite *batchHandlerTestSuite) TestGetJobCacheSuccess() {
+	suite.jobFactory.EXPECT().GetJob(testPelotonJobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(suite.testRuntime(pbjob.JobState_RUNNING), nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{
+			Type:          pbjob.JobType_BATCH,
+			InstanceCount: 5,
+			SLA:           &pbjob.SlaConfig{Priority: 2},
+		}, nil)
+
+	resp, err := suite.handler.GetJobCache(
+		context.Background(),
+		&batchsvc.GetJobCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal(uint32(5), resp.GetSpec().GetInstanceCount())
+	suite.Equal(uint32(2), resp.GetSpec().GetSla().GetPriority())
+	suite.Equal(stateless.JobState_JOB_STATE_RUNNING, resp.GetStatus().GetState())
+}
+
+// TestGetJobCacheNotFound tests getting a job which is not in the cache
+func (suite *batchHandlerTestSuite) TestGetJobCacheNotFound() {
+	suite.jobFactory.EXPECT().GetJob(testPelotonJobID).Return(nil)
+
+	resp, err := suite.handler.GetJobCache(
+		context.Background(),
+		&batchsvc.GetJobCacheRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
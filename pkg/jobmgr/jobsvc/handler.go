@@ -31,6 +31,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
@@ -61,7 +62,8 @@ var (
 		"resource pool")
 )
 
-// InitServiceHandler initializes the job manager
+// InitServiceHandler initializes the job manager and returns the handler,
+// so that it can be used to create jobs from within job manager
 func InitServiceHandler(
 	d *yarpc.Dispatcher,
 	parent tally.Scope,
@@ -72,7 +74,7 @@ func InitServiceHandler(
 	goalStateDriver goalstate.Driver,
 	candidate leader.Candidate,
 	clientName string,
-	jobSvcCfg Config) {
+	jobSvcCfg Config) job.JobManagerYARPCServer {
 
 	jobSvcCfg.normalize()
 	handler := &serviceHandler{
@@ -93,6 +95,7 @@ func InitServiceHandler(
 	}
 
 	d.Register(job.BuildJobManagerYARPCProcedures(handler))
+	return handler
 }
 
 // serviceHandler implements peloton.api.job.JobManager
@@ -190,6 +193,20 @@ func (h *serviceHandler) Create(
 		}, nil
 	}
 
+	if err := handler.CheckResourceAccess(ctx, &auth.Resource{
+		ResourcePoolPath: respoolPath.GetValue(),
+		Owner:            jobConfig.GetOwningTeam(),
+	}); err != nil {
+		h.metrics.JobCreateFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handler.CheckResourcePoolRateLimit(
+		ctx, respoolPath.GetValue()); err != nil {
+		h.metrics.JobCreateFail.Inc(1)
+		return nil, err
+	}
+
 	// Validate job config with default task configs
 	err = jobconfig.ValidateConfig(jobConfig, h.jobSvcCfg.MaxTasksPerJob)
 	if err != nil {
@@ -327,6 +344,18 @@ func (h *serviceHandler) Update(
 		return nil, err
 	}
 
+	resource := handler.NewJobResource(oldConfig.GetOwningTeam(), oldConfigAddOn)
+	if err := handler.CheckResourceAccess(ctx, resource); err != nil {
+		h.metrics.JobUpdateFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handler.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		h.metrics.JobUpdateFail.Inc(1)
+		return nil, err
+	}
+
 	if oldConfig.GetType() != job.JobType_BATCH {
 		return nil, yarpcerrors.InvalidArgumentErrorf(
 			"job update is only supported for batch jobs")
@@ -550,6 +579,18 @@ func (h *serviceHandler) Refresh(ctx context.Context, req *job.RefreshRequest) (
 		return &job.RefreshResponse{}, yarpcerrors.NotFoundErrorf("job not found")
 	}
 
+	resource := handler.NewJobResource(jobConfig.GetOwningTeam(), configAddOn)
+	if err := handler.CheckResourceAccess(ctx, resource); err != nil {
+		h.metrics.JobRefreshFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handler.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		h.metrics.JobRefreshFail.Inc(1)
+		return nil, err
+	}
+
 	// Update cache and enqueue job into goal state
 	cachedJob := h.jobFactory.AddJob(req.GetId())
 	cachedJob.Update(ctx, &job.JobInfo{
@@ -651,6 +692,18 @@ func (h *serviceHandler) Delete(
 
 	h.metrics.JobAPIDelete.Inc(1)
 
+	if err := handler.CheckJobAccess(
+		ctx, req.GetId(), h.jobConfigOps); err != nil {
+		h.metrics.JobDeleteFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handler.CheckJobRateLimit(
+		ctx, req.GetId(), h.jobConfigOps); err != nil {
+		h.metrics.JobDeleteFail.Inc(1)
+		return nil, err
+	}
+
 	jobRuntime, err := handler.GetJobRuntimeWithoutFillingCache(
 		ctx, req.Id, h.jobFactory, h.jobRuntimeOps)
 	if err != nil {
@@ -864,9 +917,20 @@ func (h *serviceHandler) createNonUpdateWorkflow(
 		return nil, 0, err
 	}
 
-	if jobConfig.GetType() != job.JobType_SERVICE {
+	resource := handler.NewJobResource(jobConfig.GetOwningTeam(), configAddOn)
+	if err := handler.CheckResourceAccess(ctx, resource); err != nil {
+		return nil, 0, err
+	}
+
+	if err := handler.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		return nil, 0, err
+	}
+
+	if !util.IsLongRunningJobType(jobConfig.GetType()) {
 		return nil, 0, yarpcerrors.InvalidArgumentErrorf(
-			"%s supported only for service jobs", workflowType.String())
+			"%s supported only for service and daemon jobs",
+			workflowType.String())
 	}
 
 	// copy the config with provided resource version number
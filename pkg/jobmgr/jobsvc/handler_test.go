@@ -840,7 +840,7 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgr() {
 	for _, v := range suite.taskInfos {
 		tasksInfo = append(tasksInfo, v)
 	}
-	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig)
+	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig, nil)
 	var expectedGangs []*resmgrsvc.Gang
 	gomock.InOrder(
 		suite.mockedResmgrClient.EXPECT().
@@ -862,6 +862,7 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgr() {
 		suite.handler.rootCtx,
 		tasksInfo,
 		suite.testJobConfig,
+		nil,
 		suite.mockedResmgrClient)
 	suite.Equal(gangs, expectedGangs)
 }
@@ -871,7 +872,7 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgrError() {
 	for _, v := range suite.taskInfos {
 		tasksInfo = append(tasksInfo, v)
 	}
-	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig)
+	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig, nil)
 	var expectedGangs []*resmgrsvc.Gang
 	gomock.InOrder(
 		suite.mockedResmgrClient.EXPECT().
@@ -892,6 +893,7 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgrError() {
 		suite.handler.rootCtx,
 		tasksInfo,
 		suite.testJobConfig,
+		nil,
 		suite.mockedResmgrClient)
 	suite.Error(err)
 }
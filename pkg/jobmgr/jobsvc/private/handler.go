@@ -114,7 +114,7 @@ func (h *serviceHandler) GetThrottledPods(
 			continue
 		}
 
-		if cachedConfig.GetType() != pbjob.JobType_SERVICE {
+		if !util.IsLongRunningJobType(cachedConfig.GetType()) {
 			continue
 		}
 
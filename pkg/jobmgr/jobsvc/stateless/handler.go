@@ -34,6 +34,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	v1alphaquery "github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
 	"github.com/uber/peloton/.gen/peloton/private/models"
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/concurrency"
 
@@ -177,6 +178,18 @@ func (h *serviceHandler) CreateJob(
 		return nil, errors.Wrap(err, "failed to validate resource pool")
 	}
 
+	if err := handlerutil.CheckResourceAccess(ctx, &auth.Resource{
+		ResourcePoolPath: respoolPath.GetValue(),
+		Owner:            jobSpec.GetOwningTeam(),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckResourcePoolRateLimit(
+		ctx, respoolPath.GetValue()); err != nil {
+		return nil, err
+	}
+
 	jobSpec, err = handlerutil.ConvertForThermosExecutor(
 		jobSpec,
 		h.jobSvcCfg.ThermosExecutor,
@@ -293,6 +306,20 @@ func (h *serviceHandler) ReplaceJob(
 			yarpcerrors.UnavailableErrorf("JobSVC.ReplaceJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	// TODO: handle secretes
 	jobUUID := uuid.Parse(req.GetJobId().GetValue())
 	if jobUUID == nil {
@@ -300,6 +327,12 @@ func (h *serviceHandler) ReplaceJob(
 			"JobID must be of UUID format")
 	}
 
+	if err := handlerutil.ValidateCanaryConfig(
+		api.ConvertCanarySpecToCanaryConfig(
+			req.GetUpdateSpec().GetCanary())); err != nil {
+		return nil, err
+	}
+
 	jobSpec, err := handlerutil.ConvertForThermosExecutor(
 		req.GetSpec(),
 		h.jobSvcCfg.ThermosExecutor,
@@ -426,6 +459,20 @@ func (h *serviceHandler) RestartJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.RestartJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	jobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
 	cachedJob := h.jobFactory.AddJob(jobID)
 	runtime, err := cachedJob.GetRuntime(ctx)
@@ -538,6 +585,20 @@ func (h *serviceHandler) PauseJobWorkflow(
 			Info("JobSVC.PauseJobWorkflow succeeded")
 	}()
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -587,6 +648,20 @@ func (h *serviceHandler) ResumeJobWorkflow(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.ResumeJobWorkflow is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -636,6 +711,20 @@ func (h *serviceHandler) AbortJobWorkflow(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.AbortJobWorkflow is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -687,6 +776,20 @@ func (h *serviceHandler) StartJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.StartJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	pelotonJobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
 
 	var jobRuntime *pbjob.RuntimeInfo
@@ -762,6 +865,20 @@ func (h *serviceHandler) StopJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.StopJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{
 		Value: req.GetJobId().GetValue(),
 	})
@@ -847,6 +964,20 @@ func (h *serviceHandler) DeleteJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.DeleteJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{
 		Value: req.GetJobId().GetValue(),
 	})
@@ -1255,7 +1386,7 @@ func (h *serviceHandler) QueryPods(
 		return nil, errors.Wrap(err, "failed to find job runtime")
 	}
 
-	_, _, err = h.jobConfigOps.Get(
+	jobConfig, configAddOn, err := h.jobConfigOps.Get(
 		ctx,
 		pelotonJobID,
 		jobRuntime.GetConfigurationVersion())
@@ -1263,6 +1394,16 @@ func (h *serviceHandler) QueryPods(
 		return nil, errors.Wrap(err, "failed to find job")
 	}
 
+	resource := handlerutil.NewJobResource(jobConfig.GetOwningTeam(), configAddOn)
+	if err := handlerutil.CheckResourceAccess(ctx, resource); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		return nil, err
+	}
+
 	taskQuerySpec := api.ConvertPodQuerySpecToTaskQuerySpec(
 		req.GetSpec(),
 	)
@@ -1735,6 +1876,16 @@ func (h *serviceHandler) RefreshJob(
 		return nil, errors.Wrap(err, "fail to get job config")
 	}
 
+	resource := handlerutil.NewJobResource(jobConfig.GetOwningTeam(), configAddOn)
+	if err := handlerutil.CheckResourceAccess(ctx, resource); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(pelotonJobID)
 	cachedJob.Update(ctx, &pbjob.JobInfo{
 		Config:  jobConfig,
@@ -1408,6 +1408,32 @@ func (suite *statelessHandlerTestSuite) TestReplaceJobFailNonLeader() {
 	suite.Error(err)
 }
 
+// TestReplaceJobInvalidCanarySpec tests the failure case of replacing job
+// due to an invalid canary spec
+func (suite *statelessHandlerTestSuite) TestReplaceJobInvalidCanarySpec() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(true)
+
+	resp, err := suite.handler.ReplaceJob(
+		context.Background(),
+		&statelesssvc.ReplaceJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			Spec:    &stateless.JobSpec{},
+			UpdateSpec: &stateless.UpdateSpec{
+				BatchSize: 1,
+				Canary: &stateless.CanarySpec{
+					InstanceCount:             1,
+					MaxInstanceFailurePercent: 150,
+				},
+			},
+		},
+	)
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
 // TestReplaceJobGetJobConfigFailure tests the failure case of replacing job
 // due to not able to get job config
 func (suite *statelessHandlerTestSuite) TestReplaceJobGetJobConfigFailure() {
@@ -38,6 +38,7 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/logmanager"
 	jobmgrtask "github.com/uber/peloton/pkg/jobmgr/task"
 	goalstateutil "github.com/uber/peloton/pkg/jobmgr/util/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
@@ -60,6 +61,7 @@ type serviceHandler struct {
 	frameworkInfoStore storage.FrameworkInfoStore
 	podEventsOps       ormobjects.PodEventsOps
 	taskConfigV2Ops    ormobjects.TaskConfigV2Ops
+	jobConfigOps       ormobjects.JobConfigOps
 	jobFactory         cached.JobFactory
 	goalStateDriver    goalstate.Driver
 	candidate          leader.Candidate
@@ -88,6 +90,7 @@ func InitV1AlphaPodServiceHandler(
 		frameworkInfoStore: frameworkInfoStore,
 		podEventsOps:       ormobjects.NewPodEventsOps(ormStore),
 		taskConfigV2Ops:    ormobjects.NewTaskConfigV2Ops(ormStore),
+		jobConfigOps:       ormobjects.NewJobConfigOps(ormStore),
 		jobFactory:         jobFactory,
 		goalStateDriver:    goalStateDriver,
 		candidate:          candidate,
@@ -129,6 +132,16 @@ func (h *serviceHandler) StartPod(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 	if err != nil {
@@ -301,6 +314,16 @@ func (h *serviceHandler) StopPod(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 
 	runtimeInfo, err := h.podStore.GetTaskRuntime(
@@ -373,6 +396,16 @@ func (h *serviceHandler) RestartPod(
 		return nil, yarpcerrors.InvalidArgumentErrorf("invalid pod name")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 
 	newPodID, err := h.getPodIDForRestart(ctx,
@@ -658,6 +691,16 @@ func (h *serviceHandler) RefreshPod(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	pelotonJobID := &v0peloton.JobID{Value: jobID}
 	taskInfo, err := h.podStore.GetTaskForJob(ctx, jobID, instanceID)
 
@@ -759,6 +802,16 @@ func (h *serviceHandler) DeletePodEvents(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, &v0peloton.JobID{Value: jobID}, h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	runID, err := util.ParseRunID(req.GetPodId().GetValue())
 	if err != nil {
 		return nil, err
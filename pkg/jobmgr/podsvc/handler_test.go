@@ -32,6 +32,8 @@ import (
 	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	"github.com/uber/peloton/pkg/common/util"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
@@ -68,6 +70,7 @@ type podHandlerTestSuite struct {
 	podStore            *storemocks.MockTaskStore
 	mockedPodEventsOps  *objectmocks.MockPodEventsOps
 	mockTaskConfigV2Ops *objectmocks.MockTaskConfigV2Ops
+	mockJobConfigOps    *objectmocks.MockJobConfigOps
 	goalStateDriver     *goalstatemocks.MockDriver
 	frameworkInfoStore  *storemocks.MockFrameworkInfoStore
 	hostmgrClient       *hostmocks.MockInternalHostServiceYARPCClient
@@ -89,12 +92,14 @@ func (suite *podHandlerTestSuite) SetupTest() {
 	suite.mesosAgentWorkDir = "test"
 	suite.mockedPodEventsOps = objectmocks.NewMockPodEventsOps(suite.ctrl)
 	suite.mockTaskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
+	suite.mockJobConfigOps = objectmocks.NewMockJobConfigOps(suite.ctrl)
 	suite.handler = &serviceHandler{
 		jobFactory:         suite.jobFactory,
 		candidate:          suite.candidate,
 		podStore:           suite.podStore,
 		podEventsOps:       suite.mockedPodEventsOps,
 		taskConfigV2Ops:    suite.mockTaskConfigV2Ops,
+		jobConfigOps:       suite.mockJobConfigOps,
 		goalStateDriver:    suite.goalStateDriver,
 		frameworkInfoStore: suite.frameworkInfoStore,
 		hostMgrClient:      suite.hostmgrClient,
@@ -907,6 +912,47 @@ func (suite *podHandlerTestSuite) TestStopPodAlreadyStoppedPod() {
 	suite.NotNil(response)
 }
 
+// TestPodActionsPermissionDenied tests that starting, stopping and
+// restarting a pod fail for a user without a grant on the resource
+// pool of the job
+func (suite *podHandlerTestSuite) TestPodActionsPermissionDenied() {
+	user := authmocks.NewMockResourceUser(suite.ctrl)
+	ctx := auth.ContextWithUser(context.Background(), user)
+	podName := &v1alphapeloton.PodName{Value: testPodName}
+
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(3)
+	suite.mockJobConfigOps.EXPECT().
+		GetCurrentVersion(gomock.Any(), &peloton.JobID{Value: testJobID}).
+		Return(&pbjob.JobConfig{OwningTeam: "team-b"}, &models.ConfigAddOn{
+			SystemLabels: []*peloton.Label{
+				{Key: "peloton.resource_pool", Value: "/infra/teamB"},
+			},
+		}, nil).
+		Times(3)
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), &auth.Resource{
+			ResourcePoolPath: "/infra/teamB",
+			Owner:            "team-b",
+		}).
+		Return(false).
+		Times(3)
+
+	startResp, err := suite.handler.StartPod(ctx,
+		&svc.StartPodRequest{PodName: podName})
+	suite.Nil(startResp)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	stopResp, err := suite.handler.StopPod(ctx,
+		&svc.StopPodRequest{PodName: podName})
+	suite.Nil(stopResp)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	restartResp, err := suite.handler.RestartPod(ctx,
+		&svc.RestartPodRequest{PodName: podName})
+	suite.Nil(restartResp)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
 // TestStopPodNonLeader tests calling stop pod
 // on non-leader jobmgr
 func (suite *podHandlerTestSuite) TestStopPodNonLeader() {
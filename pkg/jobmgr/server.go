@@ -22,8 +22,10 @@ import (
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
+	"github.com/uber/peloton/pkg/jobmgr/task/descheduler"
 	"github.com/uber/peloton/pkg/jobmgr/task/event"
 	"github.com/uber/peloton/pkg/jobmgr/task/evictor"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
@@ -44,10 +46,12 @@ type Server struct {
 	taskEvictor        evictor.Evictor
 	goalstateDriver    goalstate.Driver
 	deadlineTracker    deadline.Tracker
+	descheduler        descheduler.Descheduler
 	placementProcessor placement.Processor
 	statusUpdate       event.StatusUpdate
 	backgroundManager  background.Manager
 	watchProcessor     watchsvc.WatchProcessor
+	daemonManager      daemon.Manager
 
 	// isLeader is set once leadership callback completes
 	isLeader bool
@@ -60,10 +64,12 @@ func NewServer(
 	goalstateDriver goalstate.Driver,
 	taskPreemptor evictor.Evictor,
 	deadlineTracker deadline.Tracker,
+	descheduler descheduler.Descheduler,
 	placementProcessor placement.Processor,
 	statusUpdate event.StatusUpdate,
 	backgroundManager background.Manager,
 	watchProcessor watchsvc.WatchProcessor,
+	daemonManager daemon.Manager,
 ) *Server {
 	return &Server{
 		ID:                 leader.NewID(httpPort, grpcPort),
@@ -72,10 +78,12 @@ func NewServer(
 		taskEvictor:        taskPreemptor,
 		goalstateDriver:    goalstateDriver,
 		deadlineTracker:    deadlineTracker,
+		descheduler:        descheduler,
 		placementProcessor: placementProcessor,
 		statusUpdate:       statusUpdate,
 		backgroundManager:  backgroundManager,
 		watchProcessor:     watchProcessor,
+		daemonManager:      daemonManager,
 	}
 }
 
@@ -102,8 +110,10 @@ func (s *Server) GainedLeadershipCallback() error {
 	s.taskEvictor.Start()
 	s.placementProcessor.Start()
 	s.deadlineTracker.Start()
+	s.descheduler.Start()
 	s.statusUpdate.Start()
 	s.backgroundManager.Start()
+	s.daemonManager.Start()
 
 	return nil
 }
@@ -121,10 +131,14 @@ func (s *Server) LostLeadershipCallback() error {
 	s.placementProcessor.Stop()
 	s.taskEvictor.Stop()
 	s.deadlineTracker.Stop()
+	s.descheduler.Stop()
 	s.backgroundManager.Stop()
+	s.daemonManager.Stop()
 	s.goalstateDriver.Stop(true)
 	s.jobFactory.Stop()
 	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.StopJobClients()
+	s.watchProcessor.CompactHistory()
 
 	return nil
 }
@@ -150,10 +164,14 @@ func (s *Server) ShutDownCallback() error {
 	s.placementProcessor.Stop()
 	s.taskEvictor.Stop()
 	s.deadlineTracker.Stop()
+	s.descheduler.Stop()
 	s.backgroundManager.Stop()
+	s.daemonManager.Stop()
 	s.goalstateDriver.Stop(true)
 	s.jobFactory.Stop()
 	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.StopJobClients()
+	s.watchProcessor.CompactHistory()
 
 	return nil
 }
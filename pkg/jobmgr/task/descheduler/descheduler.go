@@ -0,0 +1,613 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package descheduler
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/taskconfig"
+	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	taskutil "github.com/uber/peloton/pkg/common/util/task"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	mimir "github.com/uber/peloton/pkg/placement/plugins/mimir/common"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/metrics"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/orderings"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/v0"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+)
+
+const (
+	_defaultDeschedulingPeriod = 10 * time.Minute
+	_defaultMaxPodsPerRun      = 10
+	_defaultMinRelocationRank  = 1
+
+	// _concurrencyMinimumSize is the minimum number of hosts for which
+	// the relocation ranks are computed concurrently
+	_concurrencyMinimumSize = 100
+
+	_timeoutFunctionCall = 120 * time.Second
+)
+
+// availableMetrics maps the metrics reserved by a pod to the metrics
+// available on its host
+var availableMetrics = map[metrics.Type]metrics.Type{
+	mimir.CPUReserved:    mimir.CPUAvailable,
+	mimir.GPUReserved:    mimir.GPUAvailable,
+	mimir.MemoryReserved: mimir.MemoryAvailable,
+	mimir.DiskReserved:   mimir.DiskAvailable,
+	mimir.PortsReserved:  mimir.PortsAvailable,
+}
+
+// Config is the descheduler specific config
+type Config struct {
+	// Enabled is true if the descheduler should run on the leader
+	Enabled bool `yaml:"enabled"`
+
+	// DeschedulingPeriod is the period to look for pods to relocate
+	DeschedulingPeriod time.Duration `yaml:"descheduling_period"`
+
+	// MaxPodsPerRun is the maximum number of pods restarted in one run
+	MaxPodsPerRun int `yaml:"max_pods_per_run"`
+
+	// MinRelocationRank is the minimum number of hosts which have to be
+	// better than the current host of a pod for it to be relocated
+	MinRelocationRank int `yaml:"min_relocation_rank"`
+
+	// Concurrency is the number of goroutines used to rank a pod
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Descheduler defines the interface of the descheduler which
+// periodically restarts running stateless pods which can be placed
+// on a better host, so that the cluster gets less fragmented and pods
+// which violate their affinity constraints are moved.
+type Descheduler interface {
+	// Start starts the descheduler
+	Start() error
+	// Stop stops the descheduler
+	Stop() error
+}
+
+// descheduler implements the Descheduler interface
+type descheduler struct {
+	hostMgrClient   hostsvc.InternalHostServiceYARPCClient
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	jobConfigOps    ormobjects.JobConfigOps
+	relocator       algorithms.Relocator
+	config          *Config
+	metrics         *Metrics
+	lifeCycle       lifecycle.LifeCycle // lifecycle manager
+}
+
+// New creates a descheduler
+func New(
+	d *yarpc.Dispatcher,
+	hostMgrClientName string,
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	parent tally.Scope,
+	config *Config,
+) Descheduler {
+	if config.DeschedulingPeriod <= 0 {
+		config.DeschedulingPeriod = _defaultDeschedulingPeriod
+	}
+	if config.MaxPodsPerRun <= 0 {
+		config.MaxPodsPerRun = _defaultMaxPodsPerRun
+	}
+	if config.MinRelocationRank <= 0 {
+		config.MinRelocationRank = _defaultMinRelocationRank
+	}
+
+	return &descheduler{
+		hostMgrClient: hostsvc.NewInternalHostServiceYARPCClient(
+			d.ClientConfig(hostMgrClientName),
+		),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		jobConfigOps:    ormobjects.NewJobConfigOps(ormStore),
+		relocator: algorithms.NewRelocator(
+			config.Concurrency,
+			_concurrencyMinimumSize,
+		),
+		config:    config,
+		metrics:   NewMetrics(parent.SubScope("jobmgr").SubScope("descheduler")),
+		lifeCycle: lifecycle.NewLifeCycle(),
+	}
+}
+
+// Start starts the descheduler
+func (d *descheduler) Start() error {
+	if !d.config.Enabled {
+		log.Info("Descheduler is disabled")
+		return nil
+	}
+
+	if d.lifeCycle.Start() {
+		go func() {
+			defer d.lifeCycle.StopComplete()
+
+			ticker := time.NewTicker(d.config.DeschedulingPeriod)
+			defer ticker.Stop()
+
+			log.Info("Starting Descheduler")
+
+			for {
+				select {
+				case <-d.lifeCycle.StopCh():
+					log.Info("Exiting Descheduler")
+					return
+				case <-ticker.C:
+					if err := d.deschedule(); err != nil {
+						d.metrics.DeschedulingRunFail.Inc(1)
+						log.WithError(err).Error("descheduling run failed")
+						continue
+					}
+					d.metrics.DeschedulingRunSuccess.Inc(1)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop stops the descheduler
+func (d *descheduler) Stop() error {
+	if !d.config.Enabled {
+		return nil
+	}
+
+	if !d.lifeCycle.Stop() {
+		log.Warn("Descheduler is already stopped, no action will be performed")
+		return nil
+	}
+
+	log.Info("Stopping Descheduler")
+
+	// Wait for descheduler to be stopped
+	d.lifeCycle.Wait()
+	log.Info("Descheduler Stopped")
+	return nil
+}
+
+// candidate is a running pod along with its relocation rank
+type candidate struct {
+	jobID      *peloton.JobID
+	instanceID uint32
+	rank       *placement.RelocationRank
+	// violated is true if the current host of the pod does not
+	// satisfy the constraints of the pod
+	violated bool
+}
+
+// restartableJob is a stateless job whose pods can be restarted
+// by the descheduler
+type restartableJob struct {
+	cachedJob cached.Job
+	runtime   *pbjob.RuntimeInfo
+	config    *ormobjects.JobConfigOpsResult
+}
+
+// deschedule ranks the running stateless pods by the number of hosts
+// which are better than their current host and restarts the pods
+// with the highest rank.
+func (d *descheduler) deschedule() error {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_timeoutFunctionCall)
+	defer cancelFunc()
+
+	groups, err := d.getGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	jobs, candidates := d.getCandidates(ctx, groups)
+
+	groupList := make([]*placement.Group, 0, len(groups))
+	for _, group := range groups {
+		group.Update()
+		groupList = append(groupList, group)
+	}
+	scopeSet := placement.NewScopeSet(groupList)
+
+	ranks := make([]*placement.RelocationRank, 0, len(candidates))
+	for _, c := range candidates {
+		c.violated = isViolated(c.rank, scopeSet)
+		if c.violated {
+			// any host which satisfies the constraints of the pod is
+			// better than its current host
+			c.rank.Entity.Ordering = orderings.Label(
+				nil,
+				labels.NewLabel(mimir.HostNameLabel, c.rank.CurrentGroup.Name))
+		}
+		ranks = append(ranks, c.rank)
+	}
+	d.relocator.Relocate(ranks, groupList, scopeSet)
+
+	instancesToRestart := d.selectInstances(ctx, jobs, candidates)
+	for jobID, instances := range instancesToRestart {
+		if err := d.restartInstances(ctx, jobs[jobID], instances); err != nil {
+			log.WithError(err).
+				WithFields(log.Fields{
+					"job_id":    jobID,
+					"instances": instances,
+				}).Error("failed to restart instances")
+			d.metrics.PodRestartFail.Inc(int64(len(instances)))
+			continue
+		}
+		log.WithFields(log.Fields{
+			"job_id":    jobID,
+			"instances": instances,
+		}).Info("instances restarted to be relocated")
+		d.metrics.PodRestartSuccess.Inc(int64(len(instances)))
+	}
+	return nil
+}
+
+// getGroups returns the mimir groups of all the hosts along with
+// their unused resources, keyed by hostname
+func (d *descheduler) getGroups(
+	ctx context.Context) (map[string]*placement.Group, error) {
+	hosts, err := d.hostMgrClient.GetHostsByQuery(
+		ctx,
+		&hostsvc.GetHostsByQueryRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hosts")
+	}
+
+	agents, err := d.hostMgrClient.GetMesosAgentInfo(
+		ctx,
+		&hostsvc.GetMesosAgentInfoRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get agent info")
+	}
+
+	hostOffers := make(map[string]*hostsvc.HostOffer)
+	for _, agent := range agents.GetAgents() {
+		hostname := agent.GetAgentInfo().GetHostname()
+		hostOffers[hostname] = &hostsvc.HostOffer{
+			Hostname:   hostname,
+			Attributes: agent.GetAgentInfo().GetAttributes(),
+		}
+	}
+
+	groups := make(map[string]*placement.Group)
+	for _, host := range hosts.GetHosts() {
+		hostOffer, ok := hostOffers[host.GetHostname()]
+		if !ok {
+			hostOffer = &hostsvc.HostOffer{Hostname: host.GetHostname()}
+		}
+		hostOffer.Resources = host.GetResources()
+		groups[host.GetHostname()] = mimir_v0.OfferToGroup(hostOffer)
+	}
+	return groups, nil
+}
+
+// getCandidates adds the running pods of the stateless jobs, which
+// do not have a workflow in progress, to the groups of their hosts.
+// It returns the jobs keyed by job id along with the candidate pods.
+func (d *descheduler) getCandidates(
+	ctx context.Context,
+	groups map[string]*placement.Group,
+) (map[string]*restartableJob, []*candidate) {
+	jobs := make(map[string]*restartableJob)
+	var candidates []*candidate
+
+	for id, cachedJob := range d.jobFactory.GetAllJobs() {
+		if cachedJob.GetJobType() != pbjob.JobType_SERVICE {
+			continue
+		}
+
+		j, err := d.getRestartableJob(ctx, cachedJob)
+		if err != nil {
+			log.WithError(err).
+				WithField("job_id", id).
+				Info("skip descheduling of job")
+			continue
+		}
+
+		for instanceID, cachedTask := range cachedJob.GetAllTasks() {
+			runtime, err := cachedTask.GetRuntime(ctx)
+			if err != nil {
+				log.WithError(err).
+					WithFields(log.Fields{
+						"job_id":      id,
+						"instance_id": instanceID,
+					}).Info("failed to fetch task runtime")
+				continue
+			}
+
+			if runtime.GetState() != pbtask.TaskState_RUNNING ||
+				runtime.GetGoalState() != pbtask.TaskState_RUNNING ||
+				runtime.GetConfigVersion() != j.runtime.GetConfigurationVersion() {
+				continue
+			}
+
+			group, ok := groups[runtime.GetHost()]
+			if !ok {
+				continue
+			}
+
+			entity := toEntity(
+				cachedJob.ID(),
+				j.config.JobConfig,
+				instanceID,
+				runtime)
+			for reserved, available := range availableMetrics {
+				group.Metrics.Add(available, entity.Metrics.Get(reserved))
+			}
+			group.Entities.Add(entity)
+
+			candidates = append(candidates, &candidate{
+				jobID:      cachedJob.ID(),
+				instanceID: instanceID,
+				rank:       placement.NewRelocationRank(entity, group),
+			})
+		}
+		jobs[id] = j
+	}
+	return jobs, candidates
+}
+
+// getRestartableJob returns the job if it can be restarted,
+// else it returns an error
+func (d *descheduler) getRestartableJob(
+	ctx context.Context,
+	cachedJob cached.Job,
+) (*restartableJob, error) {
+	runtime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job runtime")
+	}
+
+	if runtime.GetGoalState() != pbjob.JobState_RUNNING {
+		return nil, errors.New("job is not running")
+	}
+
+	if len(runtime.GetUpdateID().GetValue()) > 0 {
+		workflow := cachedJob.GetWorkflow(runtime.GetUpdateID())
+		if workflow == nil ||
+			!cached.IsUpdateStateTerminal(workflow.GetState().State) {
+			return nil, errors.New("job has a workflow in progress")
+		}
+	}
+
+	config, err := d.jobConfigOps.GetResult(
+		ctx,
+		cachedJob.ID(),
+		runtime.GetConfigurationVersion())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job config")
+	}
+
+	return &restartableJob{
+		cachedJob: cachedJob,
+		runtime:   runtime,
+		config:    config,
+	}, nil
+}
+
+// selectInstances returns the instances to restart keyed by job id.
+// Pods which violate their constraints are selected first and the
+// rest of the pods in the order of their relocation rank. The number
+// of instances of a job selected is bounded by the number of instances
+// the job can have unavailable.
+func (d *descheduler) selectInstances(
+	ctx context.Context,
+	jobs map[string]*restartableJob,
+	candidates []*candidate,
+) map[string][]uint32 {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].violated != candidates[j].violated {
+			return candidates[i].violated
+		}
+		return candidates[i].rank.Rank > candidates[j].rank.Rank
+	})
+
+	budgets := make(map[string]uint32)
+	instances := make(map[string][]uint32)
+	selected := 0
+	for _, c := range candidates {
+		if selected >= d.config.MaxPodsPerRun {
+			break
+		}
+
+		if c.rank.Rank < d.config.MinRelocationRank &&
+			!(c.violated && c.rank.Rank > 0) {
+			continue
+		}
+
+		jobID := c.jobID.GetValue()
+		budget, ok := budgets[jobID]
+		if !ok {
+			budget = d.getUnavailabilityBudget(ctx, jobs[jobID])
+		}
+		if budget == 0 {
+			budgets[jobID] = budget
+			continue
+		}
+
+		budgets[jobID] = budget - 1
+		instances[jobID] = append(instances[jobID], c.instanceID)
+		selected++
+		d.metrics.PodSelected.Inc(1)
+		if c.violated {
+			d.metrics.PodViolated.Inc(1)
+		}
+	}
+	return instances
+}
+
+// getUnavailabilityBudget returns the number of instances of the job
+// which can be made unavailable without violating the maximum
+// unavailable instances of the job. Jobs which do not configure the
+// maximum unavailable instances are relocated one instance at a time.
+func (d *descheduler) getUnavailabilityBudget(
+	ctx context.Context,
+	j *restartableJob) uint32 {
+	maxUnavailable := j.config.JobConfig.GetSLA().GetMaximumUnavailableInstances()
+	if maxUnavailable == 0 {
+		return 1
+	}
+
+	var unavailable uint32
+	for _, availability := range j.cachedJob.GetInstanceAvailabilityType(ctx) {
+		if availability == jobmgrcommon.InstanceAvailability_UNAVAILABLE {
+			unavailable++
+		}
+	}
+
+	if unavailable >= maxUnavailable {
+		return 0
+	}
+	return maxUnavailable - unavailable
+}
+
+// restartInstances restarts the instances of the job using
+// a restart workflow
+func (d *descheduler) restartInstances(
+	ctx context.Context,
+	j *restartableJob,
+	instances []uint32) error {
+	jobConfig := j.config.JobConfig
+
+	// copy the config with provided resource version number
+	newConfig := *jobConfig
+	now := time.Now()
+	newConfig.ChangeLog = &peloton.ChangeLog{
+		Version:   jobConfig.GetChangeLog().GetVersion(),
+		CreatedAt: uint64(now.UnixNano()),
+		UpdatedAt: uint64(now.UnixNano()),
+	}
+
+	var newSpec stateless.JobSpec
+	if j.config.JobSpec != nil {
+		newSpec = *j.config.JobSpec
+		newSpec.Revision = &v1alphapeloton.Revision{
+			Version:   newConfig.GetChangeLog().GetVersion(),
+			CreatedAt: newConfig.GetChangeLog().GetCreatedAt(),
+			UpdatedAt: newConfig.GetChangeLog().GetUpdatedAt(),
+		}
+	}
+
+	batchSize := jobConfig.GetSLA().GetMaximumUnavailableInstances()
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	jobID := j.cachedJob.ID()
+	updateID, _, err := j.cachedJob.CreateWorkflow(
+		ctx,
+		models.WorkflowType_RESTART,
+		&pbupdate.UpdateConfig{
+			BatchSize: batchSize,
+		},
+		versionutil.GetJobEntityVersion(
+			j.runtime.GetConfigurationVersion(),
+			j.runtime.GetDesiredStateVersion(),
+			j.runtime.GetWorkflowVersion()),
+		cached.WithInstanceToProcess(
+			nil,
+			instances,
+			nil),
+		cached.WithConfig(
+			&newConfig,
+			jobConfig,
+			j.config.ConfigAddOn,
+			&newSpec,
+		),
+	)
+
+	// In case of error, since it is not clear if job runtime was
+	// persisted with the update ID or not, enqueue the update to
+	// the goal state. If the update ID got persisted, update should
+	// start running, else, it should be aborted.
+	if len(updateID.GetValue()) > 0 {
+		d.goalStateDriver.EnqueueUpdate(jobID, updateID, time.Now())
+	}
+	return err
+}
+
+// toEntity converts a running pod to a mimir entity which prefers
+// the hosts with the least free resources, so that pods get packed
+// onto fewer hosts.
+func toEntity(
+	jobID *peloton.JobID,
+	jobConfig *pbjob.JobConfig,
+	instanceID uint32,
+	runtime *pbtask.RuntimeInfo,
+) *placement.Entity {
+	taskInfo := &pbtask.TaskInfo{
+		JobId:      jobID,
+		InstanceId: instanceID,
+		Config: taskconfig.Merge(
+			jobConfig.GetDefaultConfig(),
+			jobConfig.GetInstanceConfig()[instanceID]),
+		Runtime: runtime,
+	}
+	entity := mimir_v0.TaskToEntity(
+		taskutil.ConvertTaskToResMgrTask(taskInfo, jobConfig, nil),
+		false)
+	entity.Ordering = orderings.Concatenate(
+		orderings.Metric(orderings.GroupSource, mimir.DiskFree),
+		orderings.Metric(orderings.GroupSource, mimir.MemoryFree),
+		orderings.Metric(orderings.GroupSource, mimir.CPUFree),
+		orderings.Metric(orderings.GroupSource, mimir.GPUFree),
+	)
+	return entity
+}
+
+// isViolated returns true if the current host of the pod does not
+// satisfy the constraints of the pod
+func isViolated(
+	rank *placement.RelocationRank,
+	scopeSet *placement.ScopeSet) bool {
+	group := rank.CurrentGroup
+	entity := rank.Entity
+
+	// the pod itself should not count towards its own constraints
+	group.Entities.Remove(entity)
+	group.Update()
+	defer func() {
+		group.Entities.Add(entity)
+		group.Update()
+	}()
+
+	return !entity.Requirement.Passed(group, scopeSet, entity, rank.Transcript)
+}
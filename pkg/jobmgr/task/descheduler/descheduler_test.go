@@ -0,0 +1,358 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package descheduler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/transport/http"
+)
+
+type deschedulerTestSuite struct {
+	suite.Suite
+	mockCtrl        *gomock.Controller
+	descheduler     *descheduler
+	mockHostMgr     *hostmocks.MockInternalHostServiceYARPCClient
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *goalstatemocks.MockDriver
+	jobConfigOps    *objectmocks.MockJobConfigOps
+
+	jobID     *peloton.JobID
+	mockJob   *cachedmocks.MockJob
+	jobConfig *pbjob.JobConfig
+	runtime   *pbjob.RuntimeInfo
+}
+
+func (suite *deschedulerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHostMgr = hostmocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.mockCtrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.mockCtrl)
+	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.mockCtrl)
+	suite.mockJob = cachedmocks.NewMockJob(suite.mockCtrl)
+
+	suite.descheduler = &descheduler{
+		hostMgrClient:   suite.mockHostMgr,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		jobConfigOps:    suite.jobConfigOps,
+		relocator:       algorithms.NewRelocator(0, 0),
+		config: &Config{
+			Enabled:            true,
+			DeschedulingPeriod: time.Minute,
+			MaxPodsPerRun:      10,
+			MinRelocationRank:  1,
+		},
+		metrics:   NewMetrics(tally.NoopScope),
+		lifeCycle: lifecycle.NewLifeCycle(),
+	}
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.jobConfig = &pbjob.JobConfig{
+		Type:          pbjob.JobType_SERVICE,
+		InstanceCount: 2,
+		SLA: &pbjob.SlaConfig{
+			MaximumUnavailableInstances: 1,
+		},
+		ChangeLog: &peloton.ChangeLog{Version: 2},
+		DefaultConfig: &pbtask.TaskConfig{
+			Resource: &pbtask.ResourceConfig{CpuLimit: 1},
+		},
+	}
+	suite.runtime = &pbjob.RuntimeInfo{
+		State:                pbjob.JobState_RUNNING,
+		GoalState:            pbjob.JobState_RUNNING,
+		ConfigurationVersion: 2,
+		DesiredStateVersion:  1,
+		WorkflowVersion:      1,
+	}
+}
+
+func (suite *deschedulerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestDescheduler(t *testing.T) {
+	suite.Run(t, new(deschedulerTestSuite))
+}
+
+// expectHosts sets up host manager to return the hosts
+// with the given free cpus
+func (suite *deschedulerTestSuite) expectHosts(freeCPUs map[string]float64) {
+	var hosts []*hostsvc.GetHostsByQueryResponse_Host
+	for hostname, cpus := range freeCPUs {
+		hosts = append(hosts, &hostsvc.GetHostsByQueryResponse_Host{
+			Hostname: hostname,
+			Resources: util.CreateMesosScalarResources(
+				map[string]float64{"cpus": cpus}, "*"),
+		})
+	}
+	suite.mockHostMgr.EXPECT().
+		GetHostsByQuery(gomock.Any(), &hostsvc.GetHostsByQueryRequest{}).
+		Return(&hostsvc.GetHostsByQueryResponse{Hosts: hosts}, nil)
+	suite.mockHostMgr.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), &hostsvc.GetMesosAgentInfoRequest{}).
+		Return(&hostsvc.GetMesosAgentInfoResponse{}, nil)
+}
+
+// expectJob sets up the job to run its instances on the given hosts
+func (suite *deschedulerTestSuite) expectJob(hosts []string) {
+	tasks := make(map[uint32]cached.Task)
+	for i, host := range hosts {
+		mockTask := cachedmocks.NewMockTask(suite.mockCtrl)
+		mesosTaskID := fmt.Sprintf("%s-%d-1", suite.jobID.GetValue(), i)
+		mockTask.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&pbtask.RuntimeInfo{
+				State:         pbtask.TaskState_RUNNING,
+				GoalState:     pbtask.TaskState_RUNNING,
+				Host:          host,
+				ConfigVersion: 2,
+				MesosTaskId:   &mesos.TaskID{Value: &mesosTaskID},
+			}, nil)
+		tasks[uint32(i)] = mockTask
+	}
+
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.mockJob})
+	suite.mockJob.EXPECT().GetJobType().Return(pbjob.JobType_SERVICE)
+	suite.mockJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	suite.mockJob.EXPECT().GetRuntime(gomock.Any()).Return(suite.runtime, nil)
+	suite.mockJob.EXPECT().GetAllTasks().Return(tasks)
+	suite.jobConfigOps.EXPECT().
+		GetResult(gomock.Any(), suite.jobID, uint64(2)).
+		Return(&ormobjects.JobConfigOpsResult{
+			JobConfig:   suite.jobConfig,
+			ConfigAddOn: &models.ConfigAddOn{},
+		}, nil)
+}
+
+// expectRestart sets up the job to expect a restart workflow
+func (suite *deschedulerTestSuite) expectRestart(batchSize uint32) {
+	updateID := &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.mockJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			models.WorkflowType_RESTART,
+			&pbupdate.UpdateConfig{BatchSize: batchSize},
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any()).
+		Return(updateID, nil, nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(suite.jobID, updateID, gomock.Any())
+}
+
+// TestDeschedulePacksPods tests that the pod on the emptiest host is
+// relocated, and that the maximum unavailable instances of the job
+// is respected.
+func (suite *deschedulerTestSuite) TestDeschedulePacksPods() {
+	suite.expectHosts(map[string]float64{
+		"host1": 3,
+		"host2": 1.5,
+		"host3": 1.2,
+	})
+	suite.expectJob([]string{"host1", "host2"})
+	suite.mockJob.EXPECT().
+		GetInstanceAvailabilityType(gomock.Any()).
+		Return(map[uint32]jobmgrcommon.InstanceAvailability_Type{
+			0: jobmgrcommon.InstanceAvailability_AVAILABLE,
+			1: jobmgrcommon.InstanceAvailability_AVAILABLE,
+		})
+	suite.expectRestart(1)
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleMinRelocationRank tests that pods are not relocated
+// if there are not enough better hosts.
+func (suite *deschedulerTestSuite) TestDescheduleMinRelocationRank() {
+	suite.descheduler.config.MinRelocationRank = 3
+
+	suite.expectHosts(map[string]float64{
+		"host1": 3,
+		"host2": 1.5,
+		"host3": 1.2,
+	})
+	suite.expectJob([]string{"host1", "host2"})
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleUnavailabilityBudgetExhausted tests that pods are not
+// relocated if the job already has the maximum unavailable instances.
+func (suite *deschedulerTestSuite) TestDescheduleUnavailabilityBudgetExhausted() {
+	suite.expectHosts(map[string]float64{
+		"host1": 3,
+		"host2": 1.5,
+		"host3": 1.2,
+	})
+	suite.expectJob([]string{"host1", "host2"})
+	suite.mockJob.EXPECT().
+		GetInstanceAvailabilityType(gomock.Any()).
+		Return(map[uint32]jobmgrcommon.InstanceAvailability_Type{
+			0: jobmgrcommon.InstanceAvailability_AVAILABLE,
+			1: jobmgrcommon.InstanceAvailability_UNAVAILABLE,
+		})
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleViolatedConstraint tests that pods which violate their
+// constraints are relocated even if their relocation rank is low.
+func (suite *deschedulerTestSuite) TestDescheduleViolatedConstraint() {
+	suite.descheduler.config.MinRelocationRank = 10
+	suite.jobConfig.DefaultConfig.Labels = []*peloton.Label{
+		{Key: "app", Value: "web"},
+	}
+	suite.jobConfig.DefaultConfig.Constraint = &pbtask.Constraint{
+		Type: pbtask.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &pbtask.LabelConstraint{
+			Kind:      pbtask.LabelConstraint_TASK,
+			Condition: pbtask.LabelConstraint_CONDITION_LESS_THAN,
+			Label: &peloton.Label{
+				Key:   "app",
+				Value: "web",
+			},
+			Requirement: 1,
+		},
+	}
+
+	suite.expectHosts(map[string]float64{
+		"host1": 3,
+		"host2": 1,
+	})
+	suite.expectJob([]string{"host1", "host1"})
+	suite.mockJob.EXPECT().
+		GetInstanceAvailabilityType(gomock.Any()).
+		Return(map[uint32]jobmgrcommon.InstanceAvailability_Type{
+			0: jobmgrcommon.InstanceAvailability_AVAILABLE,
+			1: jobmgrcommon.InstanceAvailability_AVAILABLE,
+		})
+	suite.expectRestart(1)
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleSkipsJobWithWorkflowInProgress tests that jobs which
+// have a workflow in progress are not descheduled.
+func (suite *deschedulerTestSuite) TestDescheduleSkipsJobWithWorkflowInProgress() {
+	updateID := &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.runtime.UpdateID = updateID
+	mockUpdate := cachedmocks.NewMockUpdate(suite.mockCtrl)
+
+	suite.expectHosts(map[string]float64{
+		"host1": 3,
+		"host2": 1.5,
+	})
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.mockJob})
+	suite.mockJob.EXPECT().GetJobType().Return(pbjob.JobType_SERVICE)
+	suite.mockJob.EXPECT().GetRuntime(gomock.Any()).Return(suite.runtime, nil)
+	suite.mockJob.EXPECT().GetWorkflow(updateID).Return(mockUpdate)
+	mockUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{State: pbupdate.State_ROLLING_FORWARD})
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleSkipsBatchJobs tests that batch jobs are not descheduled.
+func (suite *deschedulerTestSuite) TestDescheduleSkipsBatchJobs() {
+	suite.expectHosts(map[string]float64{"host1": 3})
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.mockJob})
+	suite.mockJob.EXPECT().GetJobType().Return(pbjob.JobType_BATCH)
+
+	suite.NoError(suite.descheduler.deschedule())
+}
+
+// TestDescheduleGetHostsFailure tests that the run fails if the hosts
+// can not be fetched from host manager.
+func (suite *deschedulerTestSuite) TestDescheduleGetHostsFailure() {
+	suite.mockHostMgr.EXPECT().
+		GetHostsByQuery(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake error"))
+
+	suite.Error(suite.descheduler.deschedule())
+}
+
+// TestStartStop tests starting and stopping the descheduler
+func (suite *deschedulerTestSuite) TestStartStop() {
+	suite.NoError(suite.descheduler.Start())
+	suite.NoError(suite.descheduler.Stop())
+
+	suite.descheduler.config.Enabled = false
+	suite.NoError(suite.descheduler.Start())
+	suite.NoError(suite.descheduler.Stop())
+}
+
+// TestNewDefaults tests that New sets the defaults of the config
+func (suite *deschedulerTestSuite) TestNewDefaults() {
+	dispatcher := yarpc.NewDispatcher(yarpc.Config{
+		Name: common.PelotonJobManager,
+		Outbounds: yarpc.Outbounds{
+			common.PelotonHostManager: transport.Outbounds{
+				Unary: http.NewTransport().NewSingleOutbound(""),
+			},
+		},
+	})
+	config := &Config{Enabled: true}
+	d := New(
+		dispatcher,
+		common.PelotonHostManager,
+		&ormobjects.Store{},
+		suite.jobFactory,
+		suite.goalStateDriver,
+		tally.NoopScope,
+		config,
+	)
+	suite.NotNil(d)
+	suite.Equal(_defaultDeschedulingPeriod, config.DeschedulingPeriod)
+	suite.Equal(_defaultMaxPodsPerRun, config.MaxPodsPerRun)
+	suite.Equal(_defaultMinRelocationRank, config.MinRelocationRank)
+}
@@ -20,6 +20,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
@@ -29,15 +30,17 @@ import (
 )
 
 // EnqueueGangs enqueues all tasks organized in gangs to respool in resmgr.
+// jobRuntime can be nil for the jobs without a max running time.
 func EnqueueGangs(
 	ctx context.Context,
 	tasks []*task.TaskInfo,
 	jobConfig jobmgrcommon.JobConfig,
+	jobRuntime *job.RuntimeInfo,
 	client resmgrsvc.ResourceManagerServiceYARPCClient) (*resmgrsvc.EnqueueGangsResponse, error) {
 	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
 	defer cancelFunc()
 
-	gangs := taskutil.ConvertToResMgrGangs(tasks, jobConfig)
+	gangs := taskutil.ConvertToResMgrGangs(tasks, jobConfig, jobRuntime)
 	var request = &resmgrsvc.EnqueueGangsRequest{
 		Gangs:   gangs,
 		ResPool: jobConfig.GetRespoolID(),
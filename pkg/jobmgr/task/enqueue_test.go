@@ -114,7 +114,7 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangs() {
 	for _, v := range suite.taskInfos {
 		tasksInfo = append(tasksInfo, v)
 	}
-	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig)
+	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig, nil)
 	var expectedGangs []*resmgrsvc.Gang
 	gomock.InOrder(
 		mockResmgrClient.EXPECT().EnqueueGangs(
@@ -135,6 +135,7 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangs() {
 		context.Background(),
 		tasksInfo,
 		suite.testJobConfig,
+		nil,
 		mockResmgrClient)
 	suite.Equal(gangs, expectedGangs)
 }
@@ -148,7 +149,7 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangsFailure() {
 	for _, v := range suite.taskInfos {
 		tasksInfo = append(tasksInfo, v)
 	}
-	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig)
+	gangs := taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig, nil)
 	var expectedGangs []*resmgrsvc.Gang
 	gomock.InOrder(
 		mockResmgrClient.EXPECT().EnqueueGangs(
@@ -168,6 +169,7 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangsFailure() {
 		context.Background(),
 		tasksInfo,
 		suite.testJobConfig,
+		nil,
 		mockResmgrClient)
 	suite.Error(err)
 }
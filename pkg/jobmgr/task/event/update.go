@@ -151,7 +151,19 @@ func NewTaskStatusUpdate(
 // OnV0Event is the callback function notifying an event
 func (p *statusUpdate) OnV0Event(event *pb_eventstream.Event) {
 	log.WithField("event_offset", event.Offset).Debug("JobMgr received v0 event")
-	if event.GetType() != pbeventstream.Event_HOST_EVENT {
+	switch event.GetType() {
+	case pbeventstream.Event_HOST_EVENT:
+	case pbeventstream.Event_SLA_VIOLATION_EVENT:
+		violation := event.GetSlaViolation()
+		log.WithFields(log.Fields{
+			"task_id":         violation.GetId().GetValue(),
+			"respool_path":    violation.GetRespoolPath(),
+			"from_state":      violation.GetFromState().String(),
+			"to_state":        violation.GetToState().String(),
+			"sla_seconds":     violation.GetSlaSeconds(),
+			"elapsed_seconds": violation.GetElapsedSeconds(),
+		}).Warn("Task state transition SLA violated")
+	default:
 		p.applier.addV0Event(event)
 	}
 }
@@ -230,6 +242,11 @@ func (p *statusUpdate) ProcessStatusUpdate(
 
 	// Update FailureCount
 	updateFailureCount(updateEvent.State(), taskInfo.GetRuntime(), newRuntime)
+	updateCrashLoopCount(
+		taskInfo.GetConfig().GetRestartPolicy(),
+		taskInfo.GetRuntime(),
+		newRuntime,
+		now())
 
 	switch updateEvent.State() {
 	case pb_task.TaskState_FAILED:
@@ -371,8 +388,8 @@ func (p *statusUpdate) ProcessStatusUpdate(
 				newRuntime.ResourceUsage = aggregateTaskResourceUsage
 			}
 		}
-	} else if cachedJob.GetJobType() == pbjob.JobType_SERVICE {
-		// for service job, reset resource usage
+	} else if util.IsLongRunningJobType(cachedJob.GetJobType()) {
+		// for service and daemon jobs, reset resource usage
 		currTaskResourceUsage = nil
 		newRuntime.ResourceUsage = nil
 	}
@@ -631,6 +648,32 @@ func updateFailureCount(
 	}
 }
 
+// updateCrashLoopCount counts a failure of the task as consecutive to the
+// previous ones, unless the task had been running for the reset after
+// healthy duration of its restart policy before failing.
+func updateCrashLoopCount(
+	restartPolicy *pb_task.RestartPolicy,
+	runtime *pb_task.RuntimeInfo,
+	newRuntime *pb_task.RuntimeInfo,
+	now time.Time) {
+
+	if newRuntime.GetFailureCount() <= runtime.GetFailureCount() {
+		// the task did not fail
+		return
+	}
+
+	resetAfter := time.Duration(restartPolicy.GetResetAfterHealthySecs()) *
+		time.Second
+	if resetAfter > 0 && len(runtime.GetStartTime()) != 0 {
+		startTime, err := time.Parse(time.RFC3339Nano, runtime.GetStartTime())
+		if err == nil && now.Sub(startTime) >= resetAfter {
+			newRuntime.CrashLoopCount = 1
+			return
+		}
+	}
+	newRuntime.CrashLoopCount = runtime.GetCrashLoopCount() + 1
+}
+
 // isDuplicateStateUpdate validates if the current instance state is left unchanged
 // by this status update.
 // If it is left unchanged, then the status update should be ignored.
@@ -904,6 +904,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessTaskFailureCountUpdate() {
 			suite.Equal(runtime.GetState(), t.pelotnState)
 			suite.Equal(runtime.GetHealthy(), task.HealthState_INVALID)
 			suite.Equal(runtime.GetFailureCount(), t.desiredFailureCount)
+			suite.Equal(uint32(1), runtime.GetCrashLoopCount())
 		}).Return(nil, nil)
 		suite.goalStateDriver.EXPECT().EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).Return()
 		cachedJob.EXPECT().UpdateResourceUsage(gomock.Any()).Return()
@@ -918,6 +919,87 @@ func (suite *TaskUpdaterTestSuite) TestProcessTaskFailureCountUpdate() {
 	}
 }
 
+// TestUpdateCrashLoopCount tests counting the consecutive failures of a task
+func (suite *TaskUpdaterTestSuite) TestUpdateCrashLoopCount() {
+	defer suite.ctrl.Finish()
+
+	currentTime := nowMock()
+	restartPolicy := &task.RestartPolicy{
+		MaxFailures:           10,
+		ResetAfterHealthySecs: 600,
+	}
+
+	tt := []struct {
+		msg                   string
+		restartPolicy         *task.RestartPolicy
+		startTime             string
+		failureCount          uint32
+		newFailureCount       uint32
+		crashLoopCount        uint32
+		desiredCrashLoopCount uint32
+	}{
+		{
+			msg:                   "task did not fail",
+			restartPolicy:         restartPolicy,
+			failureCount:          2,
+			newFailureCount:       2,
+			crashLoopCount:        2,
+			desiredCrashLoopCount: 2,
+		},
+		{
+			msg:                   "task failed without running",
+			restartPolicy:         restartPolicy,
+			failureCount:          2,
+			newFailureCount:       3,
+			crashLoopCount:        2,
+			desiredCrashLoopCount: 3,
+		},
+		{
+			msg:           "task failed shortly after running",
+			restartPolicy: restartPolicy,
+			startTime: currentTime.Add(-time.Minute).
+				Format(time.RFC3339Nano),
+			failureCount:          2,
+			newFailureCount:       3,
+			crashLoopCount:        2,
+			desiredCrashLoopCount: 3,
+		},
+		{
+			msg:           "task failed after running long enough",
+			restartPolicy: restartPolicy,
+			startTime: currentTime.Add(-time.Hour).
+				Format(time.RFC3339Nano),
+			failureCount:          2,
+			newFailureCount:       3,
+			crashLoopCount:        2,
+			desiredCrashLoopCount: 1,
+		},
+		{
+			msg: "task failures are never reset without reset duration",
+			startTime: currentTime.Add(-time.Hour).
+				Format(time.RFC3339Nano),
+			failureCount:          2,
+			newFailureCount:       3,
+			crashLoopCount:        2,
+			desiredCrashLoopCount: 3,
+		},
+	}
+
+	for _, t := range tt {
+		runtime := &task.RuntimeInfo{
+			StartTime:      t.startTime,
+			FailureCount:   t.failureCount,
+			CrashLoopCount: t.crashLoopCount,
+		}
+		newRuntime := &task.RuntimeInfo{
+			FailureCount:   t.newFailureCount,
+			CrashLoopCount: t.crashLoopCount,
+		}
+		updateCrashLoopCount(t.restartPolicy, runtime, newRuntime, currentTime)
+		suite.Equal(t.desiredCrashLoopCount, newRuntime.GetCrashLoopCount(), t.msg)
+	}
+}
+
 // Test processing task LOST status update w/o retry for stateful task.
 func (suite *TaskUpdaterTestSuite) TestProcessTaskLostStatusUpdateNoRetryForStatefulTask() {
 	defer suite.ctrl.Finish()
@@ -1488,3 +1570,14 @@ func (suite *TaskUpdaterTestSuite) TestOnV0EventHostEvent() {
 	}
 	suite.updater.OnV0Event(ev)
 }
+
+// TestOnV0EventSLAViolationEvent tests that OnV0Event for an SLA violation
+// event is a no-op
+func (suite *TaskUpdaterTestSuite) TestOnV0EventSLAViolationEvent() {
+	defer suite.ctrl.Finish()
+
+	ev := &pbeventstream.Event{
+		Type: pb_eventstream.Event_SLA_VIOLATION_EVENT,
+	}
+	suite.updater.OnV0Event(ev)
+}
@@ -351,7 +351,7 @@ func (e *evictor) evictTasks(
 		// task is being enqueued into the goalstate. The goalstate will reload
 		// runtime into cache if needed. The task preemption will be retried
 		// in the next preemption cycle.
-		_, _, err = cachedJob.PatchTasks(
+		instancesSucceeded, _, err := cachedJob.PatchTasks(
 			ctx,
 			map[uint32]jobmgrcommon.RuntimeDiff{uint32(instanceID): runtimeDiff},
 			false,
@@ -359,6 +359,16 @@ func (e *evictor) evictTasks(
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		} else {
+			// Stateless jobs are patched in a SLA aware manner, a task whose
+			// kill would exceed the maximum unavailable instances of its job
+			// is not patched. The host keeps DRAINING until the task can be
+			// evicted in a later cycle.
+			if reason == EvictionReason_HOST_MAINTENANCE &&
+				len(instancesSucceeded) == 0 {
+				e.metrics.TaskEvictHostMaintenanceDeferred.Inc(1)
+				log.WithField("task_id", id).
+					Info("host maintenance eviction deferred by job SLA")
+			}
 			e.goalStateDriver.EnqueueTask(jobID, uint32(instanceID), time.Now())
 			goalstate.EnqueueJobWithDefaultDelay(
 				jobID, e.goalStateDriver, cachedJob)
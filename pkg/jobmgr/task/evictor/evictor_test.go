@@ -450,6 +450,80 @@ func (suite *evictorTestSuite) TestHostMaintenanceCycle() {
 		),
 	}
 
+	cachedJob.EXPECT().PatchTasks(gomock.Any(), gomock.Any(), false).
+		Do(func(ctx context.Context,
+			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+			_ bool) {
+			suite.EqualValues(runtimeDiff, runtimeDiffs[0])
+		}).Return([]uint32{0}, nil, nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueTask(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return()
+	cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE)
+	suite.goalStateDriver.EXPECT().
+		JobRuntimeDuration(job.JobType_SERVICE).
+		Return(1 * time.Second)
+	suite.goalStateDriver.EXPECT().
+		EnqueueJob(gomock.Any(), gomock.Any()).
+		Return()
+
+	testScope := tally.NewTestScope("", nil)
+	suite.evictor.metrics = NewMetrics(testScope)
+
+	suite.NoError(suite.evictor.performHostMaintenanceCycle())
+	suite.Nil(testScope.Snapshot().Counters()["host_maintenance+result=deferred"])
+}
+
+// TestHostMaintenanceCycleDeferredBySLA tests the case of a host maintenance
+// kill which is held back because it would violate the job SLA
+func (suite *evictorTestSuite) TestHostMaintenanceCycleDeferredBySLA() {
+	cachedJob := cachedmocks.NewMockJob(suite.mockCtrl)
+	runningCachedTask := cachedmocks.NewMockTask(suite.mockCtrl)
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	taskID := fmt.Sprintf("%s-%d", jobID.GetValue(), 0)
+	runningMesosTaskID := &mesos.TaskID{Value: &[]string{fmt.Sprintf("%s-1", taskID)}[0]}
+	runningTaskInfo := &peloton_task.TaskInfo{
+		InstanceId: 0,
+		Runtime: &peloton_task.RuntimeInfo{
+			State:       peloton_task.TaskState_RUNNING,
+			GoalState:   peloton_task.TaskState_RUNNING,
+			MesosTaskId: runningMesosTaskID,
+		},
+	}
+
+	suite.mockLifecycleManager.EXPECT().
+		GetTasksOnDrainingHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]string{runningMesosTaskID.GetValue()}, nil)
+	suite.jobFactory.EXPECT().AddJob(gomock.Any()).Return(cachedJob)
+	cachedJob.EXPECT().
+		AddTask(gomock.Any(), uint32(0)).
+		Return(runningCachedTask, nil)
+	runningCachedTask.EXPECT().GetRuntime(gomock.Any()).Return(
+		runningTaskInfo.Runtime,
+		nil,
+	)
+	cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE)
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(
+			gomock.Any(),
+			jobID,
+			runningTaskInfo.InstanceId,
+			runningTaskInfo.Runtime.ConfigVersion).
+		Return(nil, nil, nil)
+
+	runtimeDiff := jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.MessageField: _msgEvictingRunningTask,
+		jobmgrcommon.ReasonField:  EvictionReason_HOST_MAINTENANCE.String(),
+		jobmgrcommon.TerminationStatusField: &peloton_task.TerminationStatus{
+			Reason: peloton_task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_HOST_MAINTENANCE,
+		},
+		jobmgrcommon.DesiredMesosTaskIDField: util.CreateMesosTaskID(
+			jobID,
+			runningTaskInfo.InstanceId,
+			2,
+		),
+	}
+
 	cachedJob.EXPECT().PatchTasks(gomock.Any(), gomock.Any(), false).
 		Do(func(ctx context.Context,
 			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
@@ -467,7 +541,12 @@ func (suite *evictorTestSuite) TestHostMaintenanceCycle() {
 		EnqueueJob(gomock.Any(), gomock.Any()).
 		Return()
 
+	testScope := tally.NewTestScope("", nil)
+	suite.evictor.metrics = NewMetrics(testScope)
+
 	suite.NoError(suite.evictor.performHostMaintenanceCycle())
+	suite.Equal(int64(1),
+		testScope.Snapshot().Counters()["host_maintenance+result=deferred"].Value())
 }
 
 // TestHostMaintenanceCycleGetTasksError tests the failure case
@@ -26,6 +26,9 @@ type Metrics struct {
 
 	TaskEvictHostMaintenanceSuccess tally.Counter
 	TaskEvictHostMaintenanceFail    tally.Counter
+	// TaskEvictHostMaintenanceDeferred counts the host maintenance evictions
+	// which are held back because they would violate the job SLA
+	TaskEvictHostMaintenanceDeferred tally.Counter
 
 	GetPreemptibleTasksCallDuration tally.Timer
 
@@ -46,6 +49,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		TaskEvictHostMaintenanceSuccess: taskSuccessScope.Counter("host_maintenance"),
 		TaskEvictHostMaintenanceFail:    taskFailScope.Counter("host_maintenance"),
+		TaskEvictHostMaintenanceDeferred: scope.Tagged(
+			map[string]string{"result": "deferred"}).Counter("host_maintenance"),
 
 		GetPreemptibleTasksCallDuration: getTasksToPreemptScope.Timer("call_duration"),
 
@@ -16,11 +16,13 @@ package lifecyclemgr
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbhostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	v1_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
@@ -97,8 +99,9 @@ func (l *v1LifecycleMgr) Launch(
 		launchablePod := pbhostmgr.LaunchablePod{
 			PodId: util.CreatePodIDFromMesosTaskID(
 				pod.Runtime.GetMesosTaskId()),
-			Spec:  pod.Spec,
-			Ports: pod.Runtime.Ports,
+			Spec:         pod.Spec,
+			Ports:        pod.Runtime.Ports,
+			ResourcePool: getResourcePoolPath(pod.ConfigAddOn),
 		}
 
 		// TODO: peloton system labels contain invalid characters for labels in
@@ -250,3 +253,18 @@ func (l *v1LifecycleMgr) GetTasksOnDrainingHosts(
 ) ([]string, error) {
 	return nil, errors.New("not implemented")
 }
+
+// getResourcePoolPath returns the path of the resource pool of the pod
+// from the system labels of the job.
+func getResourcePoolPath(addOn *models.ConfigAddOn) string {
+	respoolLabel := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelResourcePool)
+	for _, label := range addOn.GetSystemLabels() {
+		if label.GetKey() == respoolLabel {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -20,11 +20,13 @@ import (
 	"strings"
 	"testing"
 
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pbhostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	v1_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 	v1_host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/rpc"
@@ -254,6 +256,30 @@ func (suite *v1LifecycleTestSuite) TestLaunch() {
 	suite.Equal(launchedPodSpecMap, expectedPodSpecs)
 }
 
+// TestGetResourcePoolPath tests reading the resource pool path of a pod
+// from the system labels.
+func (suite *v1LifecycleTestSuite) TestGetResourcePoolPath() {
+	suite.Equal("", getResourcePoolPath(nil))
+	suite.Equal("/infra/compute", getResourcePoolPath(&models.ConfigAddOn{
+		SystemLabels: []*v0peloton.Label{
+			{
+				Key: fmt.Sprintf(
+					common.SystemLabelKeyTemplate,
+					common.SystemLabelPrefix,
+					common.SystemLabelJobName),
+				Value: "job",
+			},
+			{
+				Key: fmt.Sprintf(
+					common.SystemLabelKeyTemplate,
+					common.SystemLabelPrefix,
+					common.SystemLabelResourcePool),
+				Value: "/infra/compute",
+			},
+		},
+	}))
+}
+
 // TestLaunchErrors tests Launch errors.
 func (suite *v1LifecycleTestSuite) TestLaunchErrors() {
 	taskInfos := make(map[string]*LaunchableTaskInfo)
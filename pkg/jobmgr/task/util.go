@@ -61,7 +61,7 @@ func CreateInitializingTask(jobID *peloton.JobID, instanceID uint32, jobConfig *
 // GetDefaultTaskGoalState from the job type.
 func GetDefaultTaskGoalState(jobType job.JobType) task.TaskState {
 	switch jobType {
-	case job.JobType_SERVICE:
+	case job.JobType_SERVICE, job.JobType_DAEMON:
 		return task.TaskState_RUNNING
 
 	default:
@@ -72,7 +72,7 @@ func GetDefaultTaskGoalState(jobType job.JobType) task.TaskState {
 // GetDefaultPodGoalState from the job type.
 func GetDefaultPodGoalState(jobType job.JobType) pod.PodState {
 	switch jobType {
-	case job.JobType_SERVICE:
+	case job.JobType_SERVICE, job.JobType_DAEMON:
 		return pod.PodState_POD_STATE_RUNNING
 
 	default:
@@ -238,6 +238,9 @@ func (suite *JobmgrTaskUtilTestSuite) TestGetDefaultTaskGoalState() {
 	state := GetDefaultTaskGoalState(job.JobType_SERVICE)
 	suite.Equal(state, task.TaskState_RUNNING)
 
+	state = GetDefaultTaskGoalState(job.JobType_DAEMON)
+	suite.Equal(state, task.TaskState_RUNNING)
+
 	state = GetDefaultTaskGoalState(job.JobType_BATCH)
 	suite.Equal(state, task.TaskState_SUCCEEDED)
 
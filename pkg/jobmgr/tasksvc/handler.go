@@ -299,6 +299,16 @@ func (m *serviceHandler) DeletePodEvents(
 			Info("TaskManager.DeletePodEvents succeeded")
 	}()
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	if err := m.taskStore.DeletePodEvents(
 		ctx,
 		body.GetJobId().GetValue(),
@@ -392,6 +402,18 @@ func (m *serviceHandler) Refresh(ctx context.Context, req *task.RefreshRequest)
 		return nil, yarpcerrors.UnavailableErrorf("Task Refresh API not suppported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, req.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskRefreshFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, req.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskRefreshFail.Inc(1)
+		return nil, err
+	}
+
 	jobConfig, _, err := m.jobConfigOps.GetCurrentVersion(ctx, req.GetJobId())
 	if err != nil {
 		log.WithError(err).
@@ -518,6 +540,18 @@ func (m *serviceHandler) Start(
 		return nil, yarpcerrors.UnavailableErrorf("Task Start API not suppported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskStartFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskStartFail.Inc(1)
+		return nil, err
+	}
+
 	cachedJob := m.jobFactory.AddJob(body.JobId)
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 
@@ -814,6 +848,18 @@ func (m *serviceHandler) Stop(
 		return nil, yarpcerrors.UnavailableErrorf("Task Stop API not suppported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskStopFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, body.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskStopFail.Inc(1)
+		return nil, err
+	}
+
 	cachedJob := m.jobFactory.AddJob(body.JobId)
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 
@@ -954,6 +1000,18 @@ func (m *serviceHandler) Restart(
 				"Task Restart API not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, req.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskRestartFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, req.GetJobId(), m.jobConfigOps); err != nil {
+		m.metrics.TaskRestartFail.Inc(1)
+		return nil, err
+	}
+
 	ctx, cancelFunc := context.WithTimeout(
 		ctx,
 		_rpcTimeout,
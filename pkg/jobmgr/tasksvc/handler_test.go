@@ -33,6 +33,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
@@ -751,6 +753,49 @@ func (suite *TaskHandlerTestSuite) TestStartTasks_NonLeader() {
 	suite.Nil(resp)
 }
 
+// TestTaskActionsPermissionDenied tests that starting, stopping and
+// restarting tasks fail for a user without a grant on the resource
+// pool of the job
+func (suite *TaskHandlerTestSuite) TestTaskActionsPermissionDenied() {
+	user := authmocks.NewMockResourceUser(suite.ctrl)
+	ctx := auth.ContextWithUser(context.Background(), user)
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: []*peloton.Label{
+			{Key: "peloton.resource_pool", Value: "/infra/teamB"},
+		},
+	}
+
+	suite.mockedCandidate.EXPECT().IsLeader().Return(true).Times(3)
+	suite.jobConfigOps.EXPECT().
+		GetCurrentVersion(gomock.Any(), suite.testJobID).
+		Return(suite.testJobConfig, configAddOn, nil).
+		Times(3)
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), &auth.Resource{
+			ResourcePoolPath: "/infra/teamB",
+		}).
+		Return(false).
+		Times(3)
+
+	startResp, err := suite.handler.Start(ctx, &task.StartRequest{
+		JobId: suite.testJobID,
+	})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+	suite.Nil(startResp)
+
+	stopResp, err := suite.handler.Stop(ctx, &task.StopRequest{
+		JobId: suite.testJobID,
+	})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+	suite.Nil(stopResp)
+
+	restartResp, err := suite.handler.Restart(ctx, &task.RestartRequest{
+		JobId: suite.testJobID,
+	})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+	suite.Nil(restartResp)
+}
+
 func (suite *TaskHandlerTestSuite) TestStartTasks_GetConfigFailure() {
 	gomock.InOrder(
 		suite.mockedCandidate.EXPECT().IsLeader().Return(true),
@@ -25,13 +25,17 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
@@ -98,6 +102,24 @@ func (h *serviceHandler) validateJobConfigUpdate(
 	return nil
 }
 
+// daemonUpdateConfig keeps the instances of a daemon job on the hosts they
+// are pinned to in the previous configuration, since they are managed by
+// the daemon job manager. The instances are updated one host at a time.
+func daemonUpdateConfig(
+	prevJobConfig *job.JobConfig,
+	newJobConfig *job.JobConfig,
+	updateConfig *update.UpdateConfig) *update.UpdateConfig {
+	daemon.SetInstanceHosts(newJobConfig, daemon.InstanceHosts(prevJobConfig))
+
+	daemonConfig := &update.UpdateConfig{}
+	if updateConfig != nil {
+		daemonConfig = proto.Clone(updateConfig).(*update.UpdateConfig)
+	}
+	daemonConfig.BatchSize = 1
+	daemonConfig.BatchPercentage = 0
+	return daemonConfig
+}
+
 // validateJobRuntime validates that the job state allows updating it
 func (h *serviceHandler) validateJobRuntime(jobRuntime *job.RuntimeInfo) error {
 	// cannot update a job which is still being created
@@ -129,6 +151,12 @@ func (h *serviceHandler) CreateUpdate(
 		return nil, yarpcerrors.UnimplementedErrorf("in-place update is not supported yet")
 	}
 
+	if err := handlerutil.ValidateCanaryConfig(
+		req.GetUpdateConfig().GetCanary()); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, err
+	}
+
 	// Validate that the job does exist
 	jobRuntime, err := h.jobRuntimeOps.Get(ctx, pelotonJobID)
 	if err != nil {
@@ -155,11 +183,23 @@ func (h *serviceHandler) CreateUpdate(
 		return nil, err
 	}
 
-	// check that job type is service
-	if prevJobConfig.GetType() != job.JobType_SERVICE {
+	resource := handlerutil.NewJobResource(prevJobConfig.GetOwningTeam(), prevConfigAddOn)
+	if err := handlerutil.CheckResourceAccess(ctx, resource); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, err
+	}
+
+	if err := handlerutil.CheckResourcePoolRateLimit(
+		ctx, resource.ResourcePoolPath); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, err
+	}
+
+	// check that job type is service or daemon
+	if !util.IsLongRunningJobType(prevJobConfig.GetType()) {
 		h.metrics.UpdateCreateFail.Inc(1)
 		return nil, yarpcerrors.InvalidArgumentErrorf(
-			"job must be of type service")
+			"job must be of type service or daemon")
 	}
 
 	// validate the new configuration
@@ -169,6 +209,11 @@ func (h *serviceHandler) CreateUpdate(
 		return nil, err
 	}
 
+	updateConfig := req.GetUpdateConfig()
+	if prevJobConfig.GetType() == job.JobType_DAEMON {
+		updateConfig = daemonUpdateConfig(prevJobConfig, jobConfig, updateConfig)
+	}
+
 	var respoolPath string
 	for _, label := range prevConfigAddOn.GetSystemLabels() {
 		if label.GetKey() == common.SystemLabelResourcePool {
@@ -183,7 +228,7 @@ func (h *serviceHandler) CreateUpdate(
 	updateID, _, err := cachedJob.CreateWorkflow(
 		ctx,
 		models.WorkflowType_UPDATE,
-		req.GetUpdateConfig(),
+		updateConfig,
 		versionutil.GetJobEntityVersion(
 			jobRuntime.GetConfigurationVersion(),
 			jobRuntime.GetDesiredStateVersion(),
@@ -481,6 +526,16 @@ func (h *serviceHandler) getCachedJobWithUpdateID(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobAccess(
+		ctx, updateModel.GetJobID(), h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
+	if err := handlerutil.CheckJobRateLimit(
+		ctx, updateModel.GetJobID(), h.jobConfigOps); err != nil {
+		return nil, err
+	}
+
 	return h.jobFactory.AddJob(updateModel.GetJobID()), nil
 }
 
@@ -29,6 +29,7 @@ import (
 
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
 	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
@@ -300,6 +301,26 @@ func (suite *UpdateSvcTestSuite) TestCreateBadJobUUID() {
 		"code:invalid-argument message:JobID must be of UUID format")
 }
 
+// TestCreateInvalidCanaryConfig tests creating a job update
+// with an invalid canary config
+func (suite *UpdateSvcTestSuite) TestCreateInvalidCanaryConfig() {
+	_, err := suite.h.CreateUpdate(
+		context.Background(),
+		&svc.CreateUpdateRequest{
+			JobId:     suite.jobID,
+			JobConfig: suite.newJobConfig,
+			UpdateConfig: &update.UpdateConfig{
+				BatchSize: 1,
+				Canary: &update.CanaryConfig{
+					InstanceCount:     1,
+					MaxFailurePercent: 150,
+				},
+			},
+		},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
 // TestCreateFailJobNotFound tests failing to find the job provided
 // in the create update request
 func (suite *UpdateSvcTestSuite) TestCreateFailJobNotFound() {
@@ -381,7 +402,54 @@ func (suite *UpdateSvcTestSuite) TestCreateBatchJob() {
 
 	suite.True(yarpcerrors.IsInvalidArgument(err))
 	suite.EqualError(err,
-		"code:invalid-argument message:job must be of type service")
+		"code:invalid-argument message:job must be of type service or daemon")
+}
+
+// TestCreateDaemonJob tests that updating a daemon job keeps its instances
+// on their hosts and updates them one host at a time
+func (suite *UpdateSvcTestSuite) TestCreateDaemonJob() {
+	suite.jobConfig.Type = job.JobType_DAEMON
+	daemon.SetInstanceHosts(suite.jobConfig, []string{"host-0", "host-1"})
+	suite.newJobConfig.Type = job.JobType_DAEMON
+
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).Return(suite.jobRuntime, nil)
+
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), suite.jobID, gomock.Any()).
+		Return(suite.jobConfig, &models.ConfigAddOn{}, nil)
+
+	suite.cachedJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			models.WorkflowType_UPDATE,
+			&update.UpdateConfig{BatchSize: 1},
+			gomock.Any(),
+			gomock.Any(),
+		).
+		Return(suite.updateID, nil, nil)
+
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(gomock.Any(), gomock.Any(), gomock.Any())
+
+	_, err := suite.h.CreateUpdate(
+		context.Background(),
+		&svc.CreateUpdateRequest{
+			JobId:        suite.jobID,
+			JobConfig:    suite.newJobConfig,
+			UpdateConfig: suite.updateConfig,
+		},
+	)
+	suite.NoError(err)
+	suite.Equal(uint32(2), suite.newJobConfig.GetInstanceCount())
+	suite.Equal(
+		[]string{"host-0", "host-1"},
+		daemon.InstanceHosts(suite.newJobConfig))
+	suite.Equal(uint32(2), suite.updateConfig.GetBatchSize())
 }
 
 // TestCreateMissingChangeLog tests creating a job update with no changelog
@@ -177,10 +177,11 @@ func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
 			UpdatedAt: runtime.GetRevision().GetUpdatedAt(),
 			UpdatedBy: runtime.GetRevision().GetUpdatedBy(),
 		},
-		PrevPodId:     &v1alphapeloton.PodID{Value: runtime.GetPrevMesosTaskId().GetValue()},
-		ResourceUsage: runtime.GetResourceUsage(),
-		DesiredPodId:  &v1alphapeloton.PodID{Value: runtime.GetDesiredMesosTaskId().GetValue()},
-		DesiredHost:   runtime.GetDesiredHost(),
+		PrevPodId:      &v1alphapeloton.PodID{Value: runtime.GetPrevMesosTaskId().GetValue()},
+		ResourceUsage:  runtime.GetResourceUsage(),
+		DesiredPodId:   &v1alphapeloton.PodID{Value: runtime.GetDesiredMesosTaskId().GetValue()},
+		DesiredHost:    runtime.GetDesiredHost(),
+		CrashLoopCount: runtime.GetCrashLoopCount(),
 	}
 }
 
@@ -221,7 +222,13 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 
 	if taskConfig.GetRestartPolicy() != nil {
 		result.RestartPolicy = &pod.RestartPolicy{
-			MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:           taskConfig.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSecs:    taskConfig.GetRestartPolicy().GetInitialBackoffSecs(),
+			BackoffMultiplier:     taskConfig.GetRestartPolicy().GetBackoffMultiplier(),
+			MaxBackoffSecs:        taskConfig.GetRestartPolicy().GetMaxBackoffSecs(),
+			Jitter:                taskConfig.GetRestartPolicy().GetJitter(),
+			ResetAfterHealthySecs: taskConfig.GetRestartPolicy().GetResetAfterHealthySecs(),
+			CrashLoopThreshold:    taskConfig.GetRestartPolicy().GetCrashLoopThreshold(),
 		}
 	}
 
@@ -1231,7 +1238,13 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 
 	if spec.GetRestartPolicy() != nil {
 		result.RestartPolicy = &task.RestartPolicy{
-			MaxFailures: spec.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:           spec.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSecs:    spec.GetRestartPolicy().GetInitialBackoffSecs(),
+			BackoffMultiplier:     spec.GetRestartPolicy().GetBackoffMultiplier(),
+			MaxBackoffSecs:        spec.GetRestartPolicy().GetMaxBackoffSecs(),
+			Jitter:                spec.GetRestartPolicy().GetJitter(),
+			ResetAfterHealthySecs: spec.GetRestartPolicy().GetResetAfterHealthySecs(),
+			CrashLoopThreshold:    spec.GetRestartPolicy().GetCrashLoopThreshold(),
 		}
 	}
 
@@ -415,7 +415,13 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				OrConstraint:  &task.OrConstraint{},
 			},
 			RestartPolicy: &task.RestartPolicy{
-				MaxFailures: 5,
+				MaxFailures:           5,
+				InitialBackoffSecs:    10,
+				BackoffMultiplier:     1.5,
+				MaxBackoffSecs:        300,
+				Jitter:                0.1,
+				ResetAfterHealthySecs: 600,
+				CrashLoopThreshold:    4,
 			},
 			Volume: &task.PersistentVolumeConfig{
 				ContainerPath: "test/container/path",
@@ -511,7 +517,13 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				OrConstraint:  &pod.OrConstraint{},
 			},
 			RestartPolicy: &pod.RestartPolicy{
-				MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+				MaxFailures:           taskConfig.GetRestartPolicy().GetMaxFailures(),
+				InitialBackoffSecs:    taskConfig.GetRestartPolicy().GetInitialBackoffSecs(),
+				BackoffMultiplier:     taskConfig.GetRestartPolicy().GetBackoffMultiplier(),
+				MaxBackoffSecs:        taskConfig.GetRestartPolicy().GetMaxBackoffSecs(),
+				Jitter:                taskConfig.GetRestartPolicy().GetJitter(),
+				ResetAfterHealthySecs: taskConfig.GetRestartPolicy().GetResetAfterHealthySecs(),
+				CrashLoopThreshold:    taskConfig.GetRestartPolicy().GetCrashLoopThreshold(),
 			},
 			Volume: &pod.PersistentVolumeSpec{
 				ContainerPath: taskConfig.GetVolume().GetContainerPath(),
@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// CheckJobAccess returns a permission denied error if the user in ctx
+// is not permitted to call the current procedure on the job. The job config
+// is only loaded when the user has resource scoped permissions.
+func CheckJobAccess(
+	ctx context.Context,
+	id *peloton.JobID,
+	jobConfigOps ormobjects.JobConfigOps) error {
+	if _, ok := auth.ResourceUserFromContext(ctx); !ok {
+		return nil
+	}
+
+	jobConfig, configAddOn, err := jobConfigOps.GetCurrentVersion(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get job config")
+	}
+
+	return CheckResourceAccess(
+		ctx,
+		NewJobResource(jobConfig.GetOwningTeam(), configAddOn),
+	)
+}
+
+// CheckResourceAccess returns a permission denied error if the user in
+// ctx is not permitted to call the current procedure on the resource.
+func CheckResourceAccess(ctx context.Context, resource *auth.Resource) error {
+	var procedure string
+	if call := yarpc.CallFromContext(ctx); call != nil {
+		procedure = call.Procedure()
+	}
+
+	if !auth.IsPermittedOnResource(ctx, procedure, resource) {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"not permitted to call %s on resource pool %s owned by %s",
+			procedure, resource.ResourcePoolPath, resource.Owner)
+	}
+	return nil
+}
+
+// NewJobResource returns the auth resource of a job from its owner and
+// the system labels in its config add-on.
+func NewJobResource(
+	owner string,
+	configAddOn *models.ConfigAddOn) *auth.Resource {
+	respoolKey := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelResourcePool)
+
+	resource := &auth.Resource{Owner: owner}
+	for _, label := range configAddOn.GetSystemLabels() {
+		if label.GetKey() == respoolKey {
+			resource.ResourcePoolPath = label.GetValue()
+		}
+	}
+	return resource
+}
+
+// GetResourcePoolPath returns the path of a resource pool for checking
+// the access to it. A resource pool which is not set or does not exist,
+// e.g. after it is deleted, has an empty path which is only permitted to
+// unscoped users.
+func GetResourcePoolPath(
+	ctx context.Context,
+	respoolClient respool.ResourceManagerYARPCClient,
+	id *peloton.ResourcePoolID) (string, error) {
+	if id == nil {
+		return "", nil
+	}
+
+	resp, err := respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: id},
+	)
+	if err != nil {
+		return "", err
+	}
+	if resp.GetError() != nil {
+		return "", nil
+	}
+	return resp.GetPoolinfo().GetPath().GetValue(), nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type HandlerAuthTestSuite struct {
+	suite.Suite
+
+	ctrl         *gomock.Controller
+	jobConfigOps *objectmocks.MockJobConfigOps
+	user         *authmocks.MockResourceUser
+	jobID        *peloton.JobID
+}
+
+func TestHandlerAuth(t *testing.T) {
+	suite.Run(t, new(HandlerAuthTestSuite))
+}
+
+func (suite *HandlerAuthTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.ctrl)
+	suite.user = authmocks.NewMockResourceUser(suite.ctrl)
+	suite.jobID = &peloton.JobID{Value: uuid.New()}
+}
+
+func (suite *HandlerAuthTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *HandlerAuthTestSuite) jobAddOn() *models.ConfigAddOn {
+	return &models.ConfigAddOn{
+		SystemLabels: []*peloton.Label{
+			{Key: "peloton.resource_pool", Value: "/infra/teamA"},
+			{Key: "peloton.job_owner", Value: "team-a"},
+		},
+	}
+}
+
+// TestCheckJobAccessWithoutUser tests that the job config is not
+// loaded when there is no resource scoped user in the context
+func (suite *HandlerAuthTestSuite) TestCheckJobAccessWithoutUser() {
+	suite.NoError(CheckJobAccess(
+		context.Background(), suite.jobID, suite.jobConfigOps))
+}
+
+// TestCheckJobAccessPermitted tests that the job resource is built
+// from the job config and permitted
+func (suite *HandlerAuthTestSuite) TestCheckJobAccessPermitted() {
+	ctx := auth.ContextWithUser(context.Background(), suite.user)
+
+	suite.jobConfigOps.EXPECT().
+		GetCurrentVersion(ctx, suite.jobID).
+		Return(&job.JobConfig{OwningTeam: "team-a"}, suite.jobAddOn(), nil)
+	suite.user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), &auth.Resource{
+			ResourcePoolPath: "/infra/teamA",
+			Owner:            "team-a",
+		}).
+		Return(true)
+
+	suite.NoError(CheckJobAccess(ctx, suite.jobID, suite.jobConfigOps))
+}
+
+// TestCheckJobAccessDenied tests that a permission denied error is
+// returned when the user is not permitted on the job
+func (suite *HandlerAuthTestSuite) TestCheckJobAccessDenied() {
+	ctx := auth.ContextWithUser(context.Background(), suite.user)
+
+	suite.jobConfigOps.EXPECT().
+		GetCurrentVersion(ctx, suite.jobID).
+		Return(&job.JobConfig{OwningTeam: "team-a"}, suite.jobAddOn(), nil)
+	suite.user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), gomock.Any()).
+		Return(false)
+
+	err := CheckJobAccess(ctx, suite.jobID, suite.jobConfigOps)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestCheckJobAccessConfigFailure tests that an error is returned
+// when the job config cannot be loaded
+func (suite *HandlerAuthTestSuite) TestCheckJobAccessConfigFailure() {
+	ctx := auth.ContextWithUser(context.Background(), suite.user)
+
+	suite.jobConfigOps.EXPECT().
+		GetCurrentVersion(ctx, suite.jobID).
+		Return(nil, nil, errors.New("test error"))
+
+	suite.Error(CheckJobAccess(ctx, suite.jobID, suite.jobConfigOps))
+}
+
+// TestGetResourcePoolPath tests resolving the path of a resource pool
+func (suite *HandlerAuthTestSuite) TestGetResourcePoolPath() {
+	respoolClient := respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	id := &peloton.ResourcePoolID{Value: "respool"}
+
+	// the resource pool is not set
+	path, err := GetResourcePoolPath(context.Background(), respoolClient, nil)
+	suite.NoError(err)
+	suite.Empty(path)
+
+	respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: id}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   id,
+				Path: &respool.ResourcePoolPath{Value: "/infra/teamA"},
+			},
+		}, nil)
+	path, err = GetResourcePoolPath(context.Background(), respoolClient, id)
+	suite.NoError(err)
+	suite.Equal("/infra/teamA", path)
+
+	// the resource pool does not exist
+	respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: id}).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{Id: id},
+			},
+		}, nil)
+	path, err = GetResourcePoolPath(context.Background(), respoolClient, id)
+	suite.NoError(err)
+	suite.Empty(path)
+
+	respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: id}).
+		Return(nil, errors.New("unavailable"))
+	_, err = GetResourcePoolPath(context.Background(), respoolClient, id)
+	suite.Error(err)
+}
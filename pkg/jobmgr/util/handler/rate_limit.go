@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/pkg/errors"
+)
+
+// CheckJobRateLimit returns a resource exhausted error if the resource
+// pool of the job has reached its rate limit for the current procedure.
+// The job config is only loaded when resource pools are rate limited.
+func CheckJobRateLimit(
+	ctx context.Context,
+	id *peloton.JobID,
+	jobConfigOps ormobjects.JobConfigOps) error {
+	if !inbound.HasResourcePoolQuota(ctx) {
+		return nil
+	}
+
+	jobConfig, configAddOn, err := jobConfigOps.GetCurrentVersion(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get job config")
+	}
+
+	return CheckResourcePoolRateLimit(
+		ctx,
+		NewJobResource(jobConfig.GetOwningTeam(), configAddOn).ResourcePoolPath,
+	)
+}
+
+// CheckResourcePoolRateLimit returns a resource exhausted error if the
+// resource pool has reached its rate limit for the current procedure.
+func CheckResourcePoolRateLimit(
+	ctx context.Context,
+	resourcePoolPath string) error {
+	return inbound.AllowResourcePool(ctx, resourcePoolPath)
+}
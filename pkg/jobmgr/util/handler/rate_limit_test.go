@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type HandlerRateLimitTestSuite struct {
+	suite.Suite
+
+	ctrl         *gomock.Controller
+	jobConfigOps *objectmocks.MockJobConfigOps
+	jobID        *peloton.JobID
+}
+
+func TestHandlerRateLimit(t *testing.T) {
+	suite.Run(t, new(HandlerRateLimitTestSuite))
+}
+
+func (suite *HandlerRateLimitTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.ctrl)
+	suite.jobID = &peloton.JobID{Value: uuid.New()}
+}
+
+func (suite *HandlerRateLimitTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// quotaContext returns the context passed to handlers by the rate
+// limit middleware, which allows one mutating call per resource pool
+func (suite *HandlerRateLimitTestSuite) quotaContext() context.Context {
+	mw, err := inbound.NewRateLimitInboundMiddleware(inbound.RateLimitConfig{
+		Enabled: true,
+		ResourcePool: &inbound.KeyedRateLimitConfig{
+			Write: &inbound.TokenBucket{Rate: 0, Burst: 1},
+		},
+	})
+	suite.NoError(err)
+
+	var ctx context.Context
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(c context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			ctx = c
+		}).
+		Return(nil)
+	suite.NoError(mw.Handle(
+		context.Background(),
+		&transport.Request{Procedure: "testService::Stop"},
+		nil,
+		h))
+	return ctx
+}
+
+// TestCheckJobRateLimitWithoutQuota tests that the job config is not
+// loaded when resource pools are not rate limited
+func (suite *HandlerRateLimitTestSuite) TestCheckJobRateLimitWithoutQuota() {
+	suite.NoError(CheckJobRateLimit(
+		context.Background(), suite.jobID, suite.jobConfigOps))
+}
+
+// TestCheckJobRateLimit tests that calls on a job are rate limited
+// by the resource pool of the job
+func (suite *HandlerRateLimitTestSuite) TestCheckJobRateLimit() {
+	ctx := suite.quotaContext()
+
+	suite.jobConfigOps.EXPECT().
+		GetCurrentVersion(ctx, suite.jobID).
+		Return(&job.JobConfig{}, &models.ConfigAddOn{
+			SystemLabels: []*peloton.Label{
+				{Key: "peloton.resource_pool", Value: "/infra/teamA"},
+			},
+		}, nil).
+		Times(2)
+
+	suite.NoError(CheckJobRateLimit(ctx, suite.jobID, suite.jobConfigOps))
+	err := CheckJobRateLimit(ctx, suite.jobID, suite.jobConfigOps)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+
+	// other resource pools are not affected
+	suite.NoError(CheckResourcePoolRateLimit(ctx, "/infra/teamB"))
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ValidateCanaryConfig validates the canary stage of an update. It is
+// shared by all the APIs which create updates, so that the goal state
+// engine never runs a canary with invalid success criteria.
+func ValidateCanaryConfig(canary *pbupdate.CanaryConfig) error {
+	if canary.GetInstanceCount() == 0 {
+		return nil
+	}
+
+	if canary.GetMaxFailurePercent() < 0 ||
+		canary.GetMaxFailurePercent() > 100 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"canary max instance failure percent must be between 0 and 100")
+	}
+
+	return nil
+}
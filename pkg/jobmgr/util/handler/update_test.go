@@ -0,0 +1,52 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type HandlerUpdateTestSuite struct {
+	suite.Suite
+}
+
+func TestHandlerUpdate(t *testing.T) {
+	suite.Run(t, new(HandlerUpdateTestSuite))
+}
+
+// TestValidateCanaryConfig tests validating the canary stage of an update
+func (suite *HandlerUpdateTestSuite) TestValidateCanaryConfig() {
+	suite.NoError(ValidateCanaryConfig(nil))
+	suite.NoError(ValidateCanaryConfig(&pbupdate.CanaryConfig{
+		MaxFailurePercent: 150,
+	}))
+	suite.NoError(ValidateCanaryConfig(&pbupdate.CanaryConfig{
+		InstanceCount:     1,
+		MaxFailurePercent: 50,
+	}))
+
+	for _, percent := range []float64{-1, 150} {
+		err := ValidateCanaryConfig(&pbupdate.CanaryConfig{
+			InstanceCount:     1,
+			MaxFailurePercent: percent,
+		})
+		suite.True(yarpcerrors.IsInvalidArgument(err))
+	}
+}
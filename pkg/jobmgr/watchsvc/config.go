@@ -15,8 +15,9 @@
 package watchsvc
 
 const (
-	_defaultBufferSize int = 100
-	_defaultMaxClient  int = 1000
+	_defaultBufferSize  int = 100
+	_defaultMaxClient   int = 1000
+	_defaultHistorySize int = 10000
 )
 
 // Config for Watch API
@@ -26,6 +27,9 @@ type Config struct {
 
 	// Maximum number of concurrent watch clients
 	MaxClient int `yaml:"max_client"`
+
+	// Number of most recent events kept for watches to resume from
+	HistorySize int `yaml:"history_size"`
 }
 
 func (c *Config) normalize() {
@@ -35,4 +39,7 @@ func (c *Config) normalize() {
 	if c.MaxClient <= 0 {
 		c.MaxClient = _defaultMaxClient
 	}
+	if c.HistorySize <= 0 {
+		c.HistorySize = _defaultHistorySize
+	}
 }
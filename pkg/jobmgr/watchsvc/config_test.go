@@ -26,4 +26,5 @@ func TestConfigNormalize(t *testing.T) {
 	c.normalize()
 	assert.True(t, c.BufferSize > 0)
 	assert.True(t, c.MaxClient > 0)
+	assert.True(t, c.HistorySize > 0)
 }
@@ -0,0 +1,385 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+
+	"github.com/uber/peloton/pkg/common/util"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// podChange is a pod event along with the context needed
+// to evaluate the pod filters.
+type podChange struct {
+	event  *PodEvent
+	labels []*peloton.Label
+
+	// batch is true if the pod belongs to a batch job
+	batch bool
+	// transition is true if the pod moved into its current state
+	transition bool
+	// respoolID is the resource pool of the job of the pod,
+	// empty if the job is unknown
+	respoolID string
+}
+
+// jobChange is a job event along with the context needed
+// to evaluate the job filters.
+type jobChange struct {
+	event *JobEvent
+
+	// batch is true if the job is a batch job
+	batch bool
+	// transition is true if the job moved into its current state
+	transition bool
+}
+
+type podFilter struct {
+	jobIDs       map[string]struct{}
+	podNames     map[string]struct{}
+	labels       []*peloton.Label
+	selectors    []*watch.LabelSelector
+	states       map[pod.PodState]struct{}
+	hosts        map[string]struct{}
+	respoolIDs   map[string]struct{}
+	includeBatch bool
+}
+
+type jobFilter struct {
+	jobIDs     map[string]struct{}
+	labels     []*peloton.Label
+	selectors  []*watch.LabelSelector
+	states     map[stateless.JobState]struct{}
+	respoolIDs map[string]struct{}
+	batch      bool
+}
+
+// newPodFilter creates the pod filter of a watch. respoolIDs are the
+// resource pools resolved from the resource pool path of the filter.
+func newPodFilter(
+	filter *watch.PodFilter,
+	respoolIDs map[string]struct{},
+) (*podFilter, error) {
+	f := &podFilter{respoolIDs: respoolIDs}
+	if filter == nil {
+		return f, nil
+	}
+
+	if err := validateLabelSelectors(filter.GetLabelSelectors()); err != nil {
+		return nil, err
+	}
+
+	if filter.GetJobId() != nil || len(filter.GetJobIds()) > 0 {
+		f.jobIDs = map[string]struct{}{}
+		if filter.GetJobId() != nil {
+			f.jobIDs[filter.GetJobId().GetValue()] = struct{}{}
+		}
+		for _, jobID := range filter.GetJobIds() {
+			f.jobIDs[jobID.GetValue()] = struct{}{}
+		}
+	}
+
+	if len(filter.GetPodNames()) > 0 {
+		f.podNames = map[string]struct{}{}
+		for _, podName := range filter.GetPodNames() {
+			f.podNames[podName.GetValue()] = struct{}{}
+		}
+	}
+
+	if len(filter.GetLabels()) > 0 {
+		f.labels = filter.GetLabels()
+	}
+	f.selectors = filter.GetLabelSelectors()
+
+	if len(filter.GetStates()) > 0 {
+		f.states = map[pod.PodState]struct{}{}
+		for _, state := range filter.GetStates() {
+			f.states[state] = struct{}{}
+		}
+	}
+
+	if len(filter.GetHosts()) > 0 {
+		f.hosts = map[string]struct{}{}
+		for _, host := range filter.GetHosts() {
+			f.hosts[host] = struct{}{}
+		}
+	}
+
+	f.includeBatch = filter.GetIncludeBatchPods()
+	return f, nil
+}
+
+// newJobFilter creates the job filter of a stateless or batch job
+// watch. respoolIDs are the resource pools resolved from the resource
+// pool path of the filter.
+func newJobFilter(
+	jobIDs []*peloton.JobID,
+	labels []*peloton.Label,
+	selectors []*watch.LabelSelector,
+	states []stateless.JobState,
+	respoolIDs map[string]struct{},
+	batch bool,
+) (*jobFilter, error) {
+	if err := validateLabelSelectors(selectors); err != nil {
+		return nil, err
+	}
+
+	f := &jobFilter{
+		selectors:  selectors,
+		respoolIDs: respoolIDs,
+		batch:      batch,
+	}
+
+	if len(jobIDs) > 0 {
+		f.jobIDs = map[string]struct{}{}
+		for _, jobID := range jobIDs {
+			f.jobIDs[jobID.GetValue()] = struct{}{}
+		}
+	}
+
+	if len(labels) > 0 {
+		f.labels = labels
+	}
+
+	if len(states) > 0 {
+		f.states = map[stateless.JobState]struct{}{}
+		for _, state := range states {
+			f.states[state] = struct{}{}
+		}
+	}
+
+	return f, nil
+}
+
+// match returns true if the pod change passes the filter
+func (f *podFilter) match(c *podChange) bool {
+	if f == nil {
+		return !c.batch
+	}
+
+	if c.batch && !f.includeBatch {
+		return false
+	}
+
+	podName := c.event.Pod.GetPodName().GetValue()
+
+	// Check job ID filter
+	if len(f.jobIDs) > 0 {
+		jobID, _, err := util.ParseTaskID(podName)
+		if err != nil {
+			// Cannot parse podName to match the jobID, assume that
+			// filter does not match.
+			return false
+		}
+
+		if _, ok := f.jobIDs[jobID]; !ok {
+			// job id filter did not match
+			return false
+		}
+	}
+
+	// Check pod name filter
+	if len(f.podNames) > 0 {
+		if _, ok := f.podNames[podName]; !ok {
+			return false
+		}
+	}
+
+	// Check pod state filter, only the changes which move
+	// the pod into one of the states match
+	if len(f.states) > 0 {
+		if !c.transition {
+			return false
+		}
+		if _, ok := f.states[c.event.Pod.GetStatus().GetState()]; !ok {
+			return false
+		}
+	}
+
+	// Check host filter
+	if len(f.hosts) > 0 {
+		if _, ok := f.hosts[c.event.Pod.GetStatus().GetHost()]; !ok {
+			return false
+		}
+	}
+
+	// Check resource pool filter
+	if f.respoolIDs != nil {
+		if _, ok := f.respoolIDs[c.respoolID]; !ok {
+			return false
+		}
+	}
+
+	// Check pod label filter
+	return matchLabels(f.labels, c.labels) &&
+		matchLabelSelectors(f.selectors, c.labels)
+}
+
+// match returns true if the job change passes the filter
+func (f *jobFilter) match(c *jobChange) bool {
+	if f == nil {
+		return !c.batch
+	}
+
+	if c.batch != f.batch {
+		return false
+	}
+
+	job := c.event.Job
+
+	// Check job IDs filter
+	if len(f.jobIDs) > 0 {
+		if _, ok := f.jobIDs[job.GetJobId().GetValue()]; !ok {
+			return false
+		}
+	}
+
+	// Check job state filter, only the changes which move
+	// the job into one of the states match
+	if len(f.states) > 0 {
+		if !c.transition {
+			return false
+		}
+		if _, ok := f.states[job.GetStatus().GetState()]; !ok {
+			return false
+		}
+	}
+
+	// Check resource pool filter
+	if f.respoolIDs != nil {
+		if _, ok := f.respoolIDs[job.GetRespoolId().GetValue()]; !ok {
+			return false
+		}
+	}
+
+	// Check job label filter
+	return matchLabels(f.labels, job.GetLabels()) &&
+		matchLabelSelectors(f.selectors, job.GetLabels())
+}
+
+// matchLabels returns true if the labels contain all the label filters
+func matchLabels(filters []*peloton.Label, labels []*peloton.Label) bool {
+	for _, labelFilter := range filters {
+		found := false
+		for _, label := range labels {
+			if labelFilter.GetKey() == label.GetKey() &&
+				labelFilter.GetValue() == label.GetValue() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// label filter did not match
+			return false
+		}
+	}
+	return true
+}
+
+// matchLabelSelectors returns true if the labels match all the selectors
+func matchLabelSelectors(
+	selectors []*watch.LabelSelector,
+	labels []*peloton.Label,
+) bool {
+	for _, selector := range selectors {
+		exists := false
+		in := false
+		for _, label := range labels {
+			if label.GetKey() != selector.GetKey() {
+				continue
+			}
+			exists = true
+			for _, value := range selector.GetValues() {
+				if label.GetValue() == value {
+					in = true
+					break
+				}
+			}
+		}
+
+		switch selector.GetOperator() {
+		case watch.LabelSelector_OPERATOR_IN:
+			if !in {
+				return false
+			}
+		case watch.LabelSelector_OPERATOR_NOT_IN:
+			if in {
+				return false
+			}
+		case watch.LabelSelector_OPERATOR_EXISTS:
+			if !exists {
+				return false
+			}
+		case watch.LabelSelector_OPERATOR_DOES_NOT_EXIST:
+			if exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validateLabelSelectors returns "invalid-argument" error
+// if any of the label selectors is not valid
+func validateLabelSelectors(selectors []*watch.LabelSelector) error {
+	for _, selector := range selectors {
+		if len(selector.GetKey()) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"label selector without key")
+		}
+
+		switch selector.GetOperator() {
+		case watch.LabelSelector_OPERATOR_IN,
+			watch.LabelSelector_OPERATOR_NOT_IN:
+			if len(selector.GetValues()) == 0 {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"label selector %s on key %s requires values",
+					selector.GetOperator(), selector.GetKey())
+			}
+		case watch.LabelSelector_OPERATOR_EXISTS,
+			watch.LabelSelector_OPERATOR_DOES_NOT_EXIST:
+			if len(selector.GetValues()) > 0 {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"label selector %s on key %s does not take values",
+					selector.GetOperator(), selector.GetKey())
+			}
+		default:
+			return yarpcerrors.InvalidArgumentErrorf(
+				"label selector on key %s has invalid operator %s",
+				selector.GetKey(), selector.GetOperator())
+		}
+	}
+	return nil
+}
+
+// isJobStateTerminal returns true if the job state is terminal
+func isJobStateTerminal(state stateless.JobState) bool {
+	switch state {
+	case stateless.JobState_JOB_STATE_SUCCEEDED,
+		stateless.JobState_JOB_STATE_FAILED,
+		stateless.JobState_JOB_STATE_KILLED,
+		stateless.JobState_JOB_STATE_DELETED:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// TestMatchLabelSelectors tests matching labels with label selectors
+func TestMatchLabelSelectors(t *testing.T) {
+	labels := []*peloton.Label{
+		{Key: "env", Value: "prod"},
+		{Key: "team", Value: "compute"},
+	}
+
+	testCases := map[string]struct {
+		selector *watch.LabelSelector
+		match    bool
+	}{
+		"in-match": {
+			selector: &watch.LabelSelector{
+				Key:      "env",
+				Operator: watch.LabelSelector_OPERATOR_IN,
+				Values:   []string{"prod", "staging"},
+			},
+			match: true,
+		},
+		"in-no-match": {
+			selector: &watch.LabelSelector{
+				Key:      "env",
+				Operator: watch.LabelSelector_OPERATOR_IN,
+				Values:   []string{"test"},
+			},
+			match: false,
+		},
+		"in-missing-key": {
+			selector: &watch.LabelSelector{
+				Key:      "zone",
+				Operator: watch.LabelSelector_OPERATOR_IN,
+				Values:   []string{"dca"},
+			},
+			match: false,
+		},
+		"not-in-match": {
+			selector: &watch.LabelSelector{
+				Key:      "env",
+				Operator: watch.LabelSelector_OPERATOR_NOT_IN,
+				Values:   []string{"test"},
+			},
+			match: true,
+		},
+		"not-in-no-match": {
+			selector: &watch.LabelSelector{
+				Key:      "env",
+				Operator: watch.LabelSelector_OPERATOR_NOT_IN,
+				Values:   []string{"prod"},
+			},
+			match: false,
+		},
+		"not-in-missing-key": {
+			selector: &watch.LabelSelector{
+				Key:      "zone",
+				Operator: watch.LabelSelector_OPERATOR_NOT_IN,
+				Values:   []string{"dca"},
+			},
+			match: true,
+		},
+		"exists": {
+			selector: &watch.LabelSelector{
+				Key:      "team",
+				Operator: watch.LabelSelector_OPERATOR_EXISTS,
+			},
+			match: true,
+		},
+		"exists-missing-key": {
+			selector: &watch.LabelSelector{
+				Key:      "zone",
+				Operator: watch.LabelSelector_OPERATOR_EXISTS,
+			},
+			match: false,
+		},
+		"does-not-exist": {
+			selector: &watch.LabelSelector{
+				Key:      "zone",
+				Operator: watch.LabelSelector_OPERATOR_DOES_NOT_EXIST,
+			},
+			match: true,
+		},
+		"does-not-exist-present-key": {
+			selector: &watch.LabelSelector{
+				Key:      "team",
+				Operator: watch.LabelSelector_OPERATOR_DOES_NOT_EXIST,
+			},
+			match: false,
+		},
+	}
+
+	for name, tc := range testCases {
+		assert.Equal(
+			t,
+			tc.match,
+			matchLabelSelectors([]*watch.LabelSelector{tc.selector}, labels),
+			name,
+		)
+	}
+}
+
+// TestValidateLabelSelectors tests validation of label selectors
+func TestValidateLabelSelectors(t *testing.T) {
+	assert.NoError(t, validateLabelSelectors([]*watch.LabelSelector{
+		{
+			Key:      "env",
+			Operator: watch.LabelSelector_OPERATOR_IN,
+			Values:   []string{"prod"},
+		},
+		{
+			Key:      "team",
+			Operator: watch.LabelSelector_OPERATOR_EXISTS,
+		},
+	}))
+
+	invalid := []*watch.LabelSelector{
+		{
+			Operator: watch.LabelSelector_OPERATOR_EXISTS,
+		},
+		{
+			Key:      "env",
+			Operator: watch.LabelSelector_OPERATOR_NOT_IN,
+		},
+		{
+			Key:      "env",
+			Operator: watch.LabelSelector_OPERATOR_DOES_NOT_EXIST,
+			Values:   []string{"prod"},
+		},
+		{
+			Key: "env",
+		},
+	}
+	for _, selector := range invalid {
+		err := validateLabelSelectors([]*watch.LabelSelector{selector})
+		assert.True(t, yarpcerrors.IsInvalidArgument(err))
+	}
+}
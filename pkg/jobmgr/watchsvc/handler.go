@@ -18,10 +18,13 @@ import (
 	"context"
 	"strings"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 
+	"github.com/uber/peloton/pkg/common"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
@@ -52,7 +55,12 @@ func InitV1AlphaWatchServiceHandler(
 	parent tally.Scope,
 	config Config,
 ) WatchProcessor {
-	InitWatchProcessor(config, parent)
+	InitWatchProcessor(
+		config,
+		NewResourcePoolResolver(respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager))),
+		parent,
+	)
 	processor := GetWatchProcessor()
 
 	handler := NewServiceHandler(NewMetrics(parent), processor)
@@ -69,7 +77,10 @@ func (h *ServiceHandler) watchPod(
 	log.WithField("request", req).
 		Debug("starting new pod watch")
 
-	watchID, watchClient, err := h.processor.NewTaskClient(req.GetPodFilter())
+	watchID, watchClient, err := h.processor.NewTaskClient(
+		req.GetPodFilter(),
+		req.GetStartRevision(),
+	)
 	if err != nil {
 		log.WithError(err).
 			Warn("failed to create pod watch client")
@@ -81,7 +92,8 @@ func (h *ServiceHandler) watchPod(
 	}()
 
 	initResp := &svc.WatchResponse{
-		WatchId: watchID,
+		WatchId:  watchID,
+		Revision: watchClient.Revision,
 	}
 	if err := stream.Send(initResp); err != nil {
 		log.WithField("watch_id", watchID).
@@ -90,17 +102,32 @@ func (h *ServiceHandler) watchPod(
 		return err
 	}
 
+	sendPod := func(e *PodEvent) error {
+		resp := &svc.WatchResponse{
+			WatchId:  watchID,
+			Revision: e.Revision,
+			Pods:     []*pod.PodSummary{e.Pod},
+		}
+		if err := stream.Send(resp); err != nil {
+			log.WithField("watch_id", watchID).
+				WithError(err).
+				Warn("failed to send response for pod watch")
+			return err
+		}
+		return nil
+	}
+
+	// replay the events the client missed before sending new ones
+	for _, e := range watchClient.Backlog {
+		if err := sendPod(e); err != nil {
+			return err
+		}
+	}
+
 	for {
 		select {
-		case p := <-watchClient.Input:
-			resp := &svc.WatchResponse{
-				WatchId: watchID,
-				Pods:    []*pod.PodSummary{p},
-			}
-			if err := stream.Send(resp); err != nil {
-				log.WithField("watch_id", watchID).
-					WithError(err).
-					Warn("failed to send response for pod watch")
+		case e := <-watchClient.Input:
+			if err := sendPod(e); err != nil {
 				return err
 			}
 		case s := <-watchClient.Signal:
@@ -129,7 +156,8 @@ func (h *ServiceHandler) watchPod(
 	}
 }
 
-// watchJob implements the watch handler for job events.
+// watchJob implements the watch handler for stateless
+// and batch job events.
 func (h *ServiceHandler) watchJob(
 	req *svc.WatchRequest,
 	stream svc.WatchServiceServiceWatchYARPCServer,
@@ -137,7 +165,22 @@ func (h *ServiceHandler) watchJob(
 	log.WithField("request", req).
 		Debug("starting new job watch")
 
-	watchID, watchClient, err := h.processor.NewJobClient(req.GetStatelessJobFilter())
+	batch := req.GetBatchJobFilter() != nil
+
+	var watchID string
+	var watchClient *JobClient
+	var err error
+	if batch {
+		watchID, watchClient, err = h.processor.NewBatchJobClient(
+			req.GetBatchJobFilter(),
+			req.GetStartRevision(),
+		)
+	} else {
+		watchID, watchClient, err = h.processor.NewJobClient(
+			req.GetStatelessJobFilter(),
+			req.GetStartRevision(),
+		)
+	}
 	if err != nil {
 		log.WithError(err).
 			Warn("failed to create job watch client")
@@ -149,7 +192,8 @@ func (h *ServiceHandler) watchJob(
 	}()
 
 	initResp := &svc.WatchResponse{
-		WatchId: watchID,
+		WatchId:  watchID,
+		Revision: watchClient.Revision,
 	}
 	if err := stream.Send(initResp); err != nil {
 		log.WithField("watch_id", watchID).
@@ -158,17 +202,36 @@ func (h *ServiceHandler) watchJob(
 		return err
 	}
 
+	sendJob := func(e *JobEvent) error {
+		resp := &svc.WatchResponse{
+			WatchId:  watchID,
+			Revision: e.Revision,
+		}
+		if batch {
+			resp.BatchJobs = []*stateless.JobSummary{e.Job}
+		} else {
+			resp.StatelessJobs = []*stateless.JobSummary{e.Job}
+		}
+		if err := stream.Send(resp); err != nil {
+			log.WithField("watch_id", watchID).
+				WithError(err).
+				Warn("failed to send response for job watch")
+			return err
+		}
+		return nil
+	}
+
+	// replay the events the client missed before sending new ones
+	for _, e := range watchClient.Backlog {
+		if err := sendJob(e); err != nil {
+			return err
+		}
+	}
+
 	for {
 		select {
-		case j := <-watchClient.Input:
-			resp := &svc.WatchResponse{
-				WatchId:       watchID,
-				StatelessJobs: []*stateless.JobSummary{j},
-			}
-			if err := stream.Send(resp); err != nil {
-				log.WithField("watch_id", watchID).
-					WithError(err).
-					Warn("failed to send response for job watch")
+		case e := <-watchClient.Input:
+			if err := sendJob(e); err != nil {
 				return err
 			}
 		case s := <-watchClient.Signal:
@@ -209,8 +272,8 @@ func (h *ServiceHandler) Watch(
 		return h.watchPod(req, stream)
 	}
 
-	// Create watch for job
-	if req.GetStatelessJobFilter() != nil {
+	// Create watch for stateless or batch job
+	if req.GetStatelessJobFilter() != nil || req.GetBatchJobFilter() != nil {
 		return h.watchJob(req, stream)
 	}
 
@@ -101,11 +101,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -139,7 +139,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 
 	go func() {
 		for _, p := range pods {
-			taskClient.Input <- p
+			taskClient.Input <- &PodEvent{Pod: p}
 		}
 		// cancelling task watch
 		taskClient.Signal <- StopSignalCancel
@@ -160,11 +160,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -198,7 +198,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 
 	go func() {
 		for _, p := range pods {
-			taskClient.Input <- p
+			taskClient.Input <- &PodEvent{Pod: p}
 		}
 		// simulate buffer overflow
 		taskClient.Signal <- StopSignalOverflow
@@ -209,10 +209,186 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 	suite.True(yarpcerrors.IsAborted(err))
 }
 
+// TestTaskWatch_Resume verifies the events replayed from the event
+// history are streamed back before the new events.
+func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Resume() {
+	watchID := NewWatchID(ClientTypeTask)
+	taskClient := &TaskClient{
+		Input:    make(chan *PodEvent),
+		Signal:   make(chan StopSignal, 1),
+		Revision: 11,
+		Backlog: []*PodEvent{
+			{
+				Revision: 10,
+				Pod:      &pod.PodSummary{PodName: &peloton.PodName{Value: "pod-0"}},
+			},
+		},
+	}
+	newEvent := &PodEvent{
+		Revision: 12,
+		Pod:      &pod.PodSummary{PodName: &peloton.PodName{Value: "pod-1"}},
+	}
+
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(10)).
+		Return(watchID, taskClient, nil)
+	suite.processor.EXPECT().StopTaskClient(watchID)
+
+	gomock.InOrder(
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 11,
+			}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 10,
+				Pods:     []*pod.PodSummary{taskClient.Backlog[0].Pod},
+			}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 12,
+				Pods:     []*pod.PodSummary{newEvent.Pod},
+			}).
+			Return(nil),
+	)
+
+	req := &watchsvc.WatchRequest{
+		StartRevision: 10,
+		PodFilter:     &watch.PodFilter{},
+	}
+
+	go func() {
+		taskClient.Input <- newEvent
+		// cancelling task watch
+		taskClient.Signal <- StopSignalCancel
+	}()
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestTaskWatch_Compacted checks Watch will return out-of-range
+// error when the start revision has been compacted.
+func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Compacted() {
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(1)).
+		Return("", nil, yarpcerrors.OutOfRangeErrorf("compacted"))
+
+	req := &watchsvc.WatchRequest{
+		StartRevision: 1,
+		PodFilter:     &watch.PodFilter{},
+	}
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+}
+
+// TestJobWatch_Resume verifies the events replayed from the event
+// history are streamed back for job watch.
+func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Resume() {
+	watchID := NewWatchID(ClientTypeJob)
+	jobClient := &JobClient{
+		Input:    make(chan *JobEvent),
+		Signal:   make(chan StopSignal, 1),
+		Revision: 10,
+		Backlog: []*JobEvent{
+			{
+				Revision: 10,
+				Job: &stateless.JobSummary{
+					JobId: &peloton.JobID{Value: uuid.New()},
+				},
+			},
+		},
+	}
+
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(10)).
+		Return(watchID, jobClient, nil)
+	suite.processor.EXPECT().StopJobClient(watchID)
+
+	gomock.InOrder(
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 10,
+			}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:       watchID,
+				Revision:      10,
+				StatelessJobs: []*stateless.JobSummary{jobClient.Backlog[0].Job},
+			}).
+			Return(nil),
+	)
+
+	req := &watchsvc.WatchRequest{
+		StartRevision:      10,
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+	}
+
+	jobClient.Signal <- StopSignalCancel
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestBatchJobWatch verifies the batch job changes are streamed back
+// in the batch jobs of the responses.
+func (suite *WatchServiceHandlerTestSuite) TestBatchJobWatch() {
+	watchID := NewWatchID(ClientTypeJob)
+	jobClient := &JobClient{
+		Input:  make(chan *JobEvent),
+		Signal: make(chan StopSignal, 1),
+	}
+	filter := &watch.BatchJobFilter{
+		States: []stateless.JobState{stateless.JobState_JOB_STATE_FAILED},
+	}
+	job := &stateless.JobSummary{
+		JobId: &peloton.JobID{Value: uuid.New()},
+		Status: &stateless.JobStatus{
+			State: stateless.JobState_JOB_STATE_FAILED,
+		},
+	}
+
+	suite.processor.EXPECT().NewBatchJobClient(filter, uint64(0)).
+		Return(watchID, jobClient, nil)
+	suite.processor.EXPECT().StopJobClient(watchID)
+
+	gomock.InOrder(
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{WatchId: watchID}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:   watchID,
+				Revision:  5,
+				BatchJobs: []*stateless.JobSummary{job},
+			}).
+			Return(nil),
+	)
+
+	go func() {
+		jobClient.Input <- &JobEvent{Revision: 5, Job: job}
+		jobClient.Signal <- StopSignalCancel
+	}()
+
+	err := suite.handler.Watch(
+		&watchsvc.WatchRequest{BatchJobFilter: filter},
+		suite.watchServer,
+	)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
 // TestTaskWatch_MaxClientReached checks Watch will return resource-exhausted
 // error when NewTaskClient reached max client.
 func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
@@ -231,11 +407,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -266,11 +442,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -301,7 +477,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 	}
 
 	go func() {
-		taskClient.Input <- p
+		taskClient.Input <- &PodEvent{Pod: p}
 		taskClient.Signal <- StopSignalCancel
 	}()
 
@@ -319,11 +495,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), gomock.Any()).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -357,7 +533,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
 
 	go func() {
 		for _, j := range jobs {
-			jobClient.Input <- j
+			jobClient.Input <- &JobEvent{Job: j}
 		}
 		// cancelling task watch
 		jobClient.Signal <- StopSignalCancel
@@ -378,11 +554,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), gomock.Any()).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -416,7 +592,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 
 	go func() {
 		for _, j := range jobs {
-			jobClient.Input <- j
+			jobClient.Input <- &JobEvent{Job: j}
 		}
 		// cancelling task watch
 		jobClient.Signal <- StopSignalOverflow
@@ -430,7 +606,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 // TestJobWatch_MaxClientReached checks Watch will return resource-exhausted
 // error when NewJobClient reached max client.
 func (suite *WatchServiceHandlerTestSuite) TestJobWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), gomock.Any()).
 		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
@@ -449,11 +625,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_InitSendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), gomock.Any()).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -483,11 +659,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_SendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), gomock.Any()).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -518,7 +694,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_SendError() {
 	}
 
 	go func() {
-		jobClient.Input <- j
+		jobClient.Input <- &JobEvent{Job: j}
 		jobClient.Signal <- StopSignalCancel
 	}()
 
@@ -21,6 +21,8 @@ import (
 	v1peloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
+	"github.com/uber/peloton/pkg/common/api"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -62,11 +64,27 @@ func (l WatchListener) StatelessJobSummaryChanged(
 	l.processor.NotifyJobChange(jobSummary)
 }
 
+// BatchJobSummaryChanged is invoked when the runtime for a batch job
+// is updated in cache and persistent store.
 func (l WatchListener) BatchJobSummaryChanged(
 	jobID *v0peloton.JobID,
 	jobSummary *job.JobSummary,
 ) {
-	// for now watch api only supports stateless
+	if jobSummary == nil {
+		log.Debug("skip BatchJobSummaryChanged due to jobSummary being nil")
+		return
+	}
+
+	if len(jobID.GetValue()) == 0 {
+		log.Debug("skip BatchJobSummaryChanged due to jobID being nil")
+		return
+	}
+
+	summary := api.ConvertJobSummary(jobSummary, nil)
+	if len(summary.GetJobId().GetValue()) == 0 {
+		summary.JobId = &v1peloton.JobID{Value: jobID.GetValue()}
+	}
+	l.processor.NotifyBatchJobChange(summary)
 }
 
 // PodSummaryChanged is invoked when the summary for a pod is updated
@@ -75,13 +93,8 @@ func (l WatchListener) PodSummaryChanged(
 	jobType job.JobType,
 	summary *pod.PodSummary,
 	labels []*v1peloton.Label,
+	respoolID *v1peloton.ResourcePoolID,
 ) {
-	// for now watch api only supports stateless
-	if jobType != job.JobType_SERVICE {
-		log.Debug("skip TaskRuntimeChanged due to not being service type job")
-		return
-	}
-
 	if summary == nil {
 		log.Debug("skip TaskRuntimeChanged due to runtime being nil")
 		return
@@ -92,5 +105,5 @@ func (l WatchListener) PodSummaryChanged(
 		return
 	}
 
-	l.processor.NotifyPodChange(summary, labels)
+	l.processor.NotifyPodChange(summary, labels, jobType, respoolID)
 }
@@ -58,7 +58,7 @@ func (suite *WatchListenerTestSuite) TestWatchListenerName() {
 // when TestPodSummaryChanged is called on listener
 func (suite *WatchListenerTestSuite) TestPodSummaryChanged() {
 	suite.processor.EXPECT().
-		NotifyPodChange(gomock.Any(), gomock.Any()).
+		NotifyPodChange(gomock.Any(), gomock.Any(), job.JobType_SERVICE, nil).
 		Times(1)
 
 	summary := &pod.PodSummary{
@@ -72,15 +72,14 @@ func (suite *WatchListenerTestSuite) TestPodSummaryChanged() {
 		job.JobType_SERVICE,
 		summary,
 		[]*v1peloton.Label{},
+		nil,
 	)
 }
 
-// TestPodSummaryChangedNonServiceType checks
-// WatchProcessor.NotifyTaskChange() is not called when not service type
-// event is passed in.
-func (suite *WatchListenerTestSuite) TestPodSummaryChangedNonServiceType() {
-	// do not expect call to processor.NotifyPodChange
-
+// TestPodSummaryChangedBatchType checks WatchProcessor.NotifyPodChange()
+// is called with the job type and resource pool when batch type event
+// is passed in.
+func (suite *WatchListenerTestSuite) TestPodSummaryChangedBatchType() {
 	summary := &pod.PodSummary{
 		PodName: &v1peloton.PodName{
 			Value: util.CreatePelotonTaskID("test-job-1", 0),
@@ -88,10 +87,17 @@ func (suite *WatchListenerTestSuite) TestPodSummaryChangedNonServiceType() {
 		Status: api.ConvertTaskRuntimeToPodStatus(&task.RuntimeInfo{}),
 	}
 
+	respoolID := &v1peloton.ResourcePoolID{Value: "respool"}
+
+	suite.processor.EXPECT().
+		NotifyPodChange(summary, gomock.Any(), job.JobType_BATCH, respoolID).
+		Times(1)
+
 	suite.listener.PodSummaryChanged(
 		job.JobType_BATCH,
 		summary,
 		[]*v1peloton.Label{},
+		respoolID,
 	)
 }
 
@@ -108,12 +114,14 @@ func (suite *WatchListenerTestSuite) TestPodSummaryChangedNilFields() {
 		job.JobType_SERVICE,
 		summary,
 		[]*v1peloton.Label{},
+		nil,
 	)
 
 	suite.listener.PodSummaryChanged(
 		job.JobType_SERVICE,
 		nil,
 		[]*v1peloton.Label{},
+		nil,
 	)
 }
 
@@ -134,16 +142,24 @@ func (suite *WatchListenerTestSuite) TestStatelessJobSummaryChanged() {
 	)
 }
 
-// TestJobSummaryChangedNonServiceType checks
-// WatchProcessor.NotifyJobChange() is not called when not service type
-// event is passed in.
-func (suite *WatchListenerTestSuite) TestJobSummaryChangedNonServiceType() {
-	// do not expect call to processor.NotifyTaskChange
+// TestBatchJobSummaryChanged checks WatchProcessor.NotifyBatchJobChange()
+// is called with the converted summary when BatchJobSummaryChanged is
+// called on listener
+func (suite *WatchListenerTestSuite) TestBatchJobSummaryChanged() {
+	suite.processor.EXPECT().
+		NotifyBatchJobChange(gomock.Any()).
+		Do(func(summary *stateless.JobSummary) {
+			suite.Equal("test-job-1", summary.GetJobId().GetValue())
+			suite.Equal(
+				stateless.JobState_JOB_STATE_RUNNING,
+				summary.GetStatus().GetState(),
+			)
+		})
 
 	suite.listener.BatchJobSummaryChanged(
 		&v0peloton.JobID{Value: "test-job-1"},
 		&job.JobSummary{
-			Runtime: &job.RuntimeInfo{},
+			Runtime: &job.RuntimeInfo{State: job.JobState_RUNNING},
 		},
 	)
 }
@@ -158,6 +174,16 @@ func (suite *WatchListenerTestSuite) TestJobSummaryChangedNilFields() {
 	)
 
 	suite.listener.StatelessJobSummaryChanged(nil)
+
+	suite.listener.BatchJobSummaryChanged(
+		&v0peloton.JobID{Value: "test-job-1"},
+		nil,
+	)
+
+	suite.listener.BatchJobSummaryChanged(
+		&v0peloton.JobID{},
+		&job.JobSummary{Runtime: &job.RuntimeInfo{}},
+	)
 }
 
 func TestWatchListener(t *testing.T) {
@@ -28,6 +28,9 @@ type Metrics struct {
 
 	CancelNotFound tally.Counter
 
+	// Watches which failed to resume from a compacted revision
+	WatchCompacted tally.Counter
+
 	// Time takes to acquire lock in watch processor
 	ProcessorLockDuration tally.Timer
 }
@@ -44,6 +47,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		CancelNotFound: subScope.Counter("cancel_not_found"),
 
+		WatchCompacted: subScope.Counter("watch_compacted"),
+
 		ProcessorLockDuration: subScope.Timer("processor_lock_duration"),
 	}
 }
@@ -15,14 +15,18 @@
 package watchsvc
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	v0job "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
 
+	"github.com/uber/peloton/pkg/common/cirbuf"
 	"github.com/uber/peloton/pkg/common/util"
 
 	"github.com/pborman/uuid"
@@ -57,6 +61,9 @@ func (s StopSignal) String() string {
 	}
 }
 
+// _resolveTimeout is the timeout to resolve the resource pools of a filter
+const _resolveTimeout = 10 * time.Second
+
 // ClientType is a enum string to be embedded in the watch id
 // returned to the client, used to indicate the watch client type.
 type ClientType string
@@ -76,8 +83,13 @@ func (t ClientType) String() string {
 // client lifecycle, and task / job event fan-out.
 type WatchProcessor interface {
 	// NewTaskClient creates a new watch client for task event changes.
-	// Returns the watch id and a new instance of TaskClient.
-	NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error)
+	// If startRevision is set, the events since that revision are
+	// replayed to the client from the event history. Returns the
+	// watch id and a new instance of TaskClient.
+	NewTaskClient(
+		filter *watch.PodFilter,
+		startRevision uint64,
+	) (string, *TaskClient, error)
 
 	// StopTaskClient stops a task watch client. Returns "not-found" error
 	// if the corresponding watch client is not found.
@@ -87,12 +99,32 @@ type WatchProcessor interface {
 	StopTaskClients()
 
 	// NotifyPodChange receives pod event, and notifies all the clients
-	// which are interested in the pod.
-	NotifyPodChange(pod *pod.PodSummary, podLabels []*peloton.Label)
+	// which are interested in the pod. respoolID is the resource pool
+	// of the job of the pod, which is nil if unknown.
+	NotifyPodChange(
+		pod *pod.PodSummary,
+		podLabels []*peloton.Label,
+		jobType v0job.JobType,
+		respoolID *peloton.ResourcePoolID,
+	)
 
 	// NewJobClient creates a new watch client for job event changes.
-	// Returns the watch id and an new instance of JobClient.
-	NewJobClient(filter *watch.StatelessJobFilter) (string, *JobClient, error)
+	// If startRevision is set, the events since that revision are
+	// replayed to the client from the event history. Returns the
+	// watch id and an new instance of JobClient.
+	NewJobClient(
+		filter *watch.StatelessJobFilter,
+		startRevision uint64,
+	) (string, *JobClient, error)
+
+	// NewBatchJobClient creates a new watch client for batch job event
+	// changes. If startRevision is set, the events since that revision
+	// are replayed to the client from the event history. Returns the
+	// watch id and an new instance of JobClient.
+	NewBatchJobClient(
+		filter *watch.BatchJobFilter,
+		startRevision uint64,
+	) (string, *JobClient, error)
 
 	// StopJobClient stops a job watch client. Returns "not-found" error
 	// if the corresponding watch client is not found.
@@ -104,6 +136,16 @@ type WatchProcessor interface {
 	// NotifyJobChange receives job event, and notifies all the clients
 	// which are interested in the job.
 	NotifyJobChange(job *stateless.JobSummary)
+
+	// NotifyBatchJobChange receives batch job event, and notifies all
+	// the clients which are interested in the batch job.
+	NotifyBatchJobChange(job *stateless.JobSummary)
+
+	// CompactHistory drops the event history and the known states of
+	// the jobs and pods on leadership change, since the events which
+	// happen while not being the leader are not recorded. Watches
+	// cannot resume from the dropped revisions.
+	CompactHistory()
 }
 
 // watchProcessor is an implementation of WatchProcessor interface.
@@ -114,35 +156,75 @@ type watchProcessor struct {
 	taskClients map[string]*TaskClient
 	jobClients  map[string]*JobClient
 	metrics     *Metrics
+
+	// history holds the most recent events, so that watches can
+	// resume from a revision. The revision of an event is the sum
+	// of baseRevision and the sequence id of the event in history.
+	history *cirbuf.CircularBuffer
+	// baseRevision is derived from the time the processor is created,
+	// and rebased on the time it gains leadership, so that the revisions
+	// of a previous job manager process or leader are older than the
+	// revisions of this one.
+	baseRevision uint64
+
+	// jobStates holds the known states of the jobs which
+	// are not terminal, learned from the job events
+	jobStates map[string]stateless.JobState
+	// podStates holds the known states of the pods which
+	// are not terminal, learned from the pod events
+	podStates map[string]pod.PodState
+
+	// resolver resolves the resource pools of the filters, it is
+	// nil if resource pool filters are not supported
+	resolver ResourcePoolResolver
 }
 
 var processor *watchProcessor
 var onceInitWatchProcessor sync.Once
 
-type podFilter struct {
-	jobID    string
-	podNames map[string]struct{}
-	labels   []*peloton.Label
+// PodEvent is a change to a pod, along with the revision of the change.
+type PodEvent struct {
+	Revision uint64
+	Pod      *pod.PodSummary
+}
+
+// JobEvent is a change to a job, along with the revision of the change.
+type JobEvent struct {
+	Revision uint64
+	Job      *stateless.JobSummary
+}
+
+// historyEvent is an event kept in the event history of the processor.
+type historyEvent struct {
+	pod *podChange
+	job *jobChange
 }
 
 // TaskClient represents a client which interested in task event changes.
 type TaskClient struct {
-	Input  chan *pod.PodSummary
+	Input  chan *PodEvent
 	Signal chan StopSignal
 
-	filter *podFilter
-}
+	// Revision is the server revision when the client was created.
+	Revision uint64
+	// Backlog holds the events replayed from the event history, which
+	// should be sent to the client before the events from Input.
+	Backlog []*PodEvent
 
-type jobFilter struct {
-	jobIDs map[string]struct{}
-	labels []*peloton.Label
+	filter *podFilter
 }
 
 // JobClient represents a client which interested in job event changes.
 type JobClient struct {
-	Input  chan *stateless.JobSummary
+	Input  chan *JobEvent
 	Signal chan StopSignal
 
+	// Revision is the server revision when the client was created.
+	Revision uint64
+	// Backlog holds the events replayed from the event history, which
+	// should be sent to the client before the events from Input.
+	Backlog []*JobEvent
+
 	filter *jobFilter
 }
 
@@ -150,6 +232,7 @@ type JobClient struct {
 // Call InitWatchProcessor for regular case use.
 func newWatchProcessor(
 	cfg Config,
+	resolver ResourcePoolResolver,
 	parent tally.Scope,
 ) *watchProcessor {
 	cfg.normalize()
@@ -159,16 +242,24 @@ func newWatchProcessor(
 		taskClients: make(map[string]*TaskClient),
 		jobClients:  make(map[string]*JobClient),
 		metrics:     NewMetrics(parent),
+
+		history:      cirbuf.NewCircularBuffer(cfg.HistorySize),
+		baseRevision: uint64(time.Now().UnixNano()),
+
+		jobStates: make(map[string]stateless.JobState),
+		podStates: make(map[string]pod.PodState),
+		resolver:  resolver,
 	}
 }
 
 // InitWatchProcessor initializes WatchProcessor singleton.
 func InitWatchProcessor(
 	cfg Config,
+	resolver ResourcePoolResolver,
 	parent tally.Scope,
 ) {
 	onceInitWatchProcessor.Do(func() {
-		processor = newWatchProcessor(cfg, parent)
+		processor = newWatchProcessor(cfg, resolver, parent)
 	})
 }
 
@@ -184,8 +275,24 @@ func NewWatchID(clientType ClientType) string {
 }
 
 // NewTaskClient creates a new watch client for task event changes.
-// Returns the watch id and a new instance of TaskClient.
-func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error) {
+// If startRevision is set, the events since that revision are
+// replayed to the client from the event history. Returns the
+// watch id and a new instance of TaskClient.
+func (p *watchProcessor) NewTaskClient(
+	filter *watch.PodFilter,
+	startRevision uint64,
+) (string, *TaskClient, error) {
+	// resolve the resource pools before acquiring the lock,
+	// since it needs a call to Resource Manager
+	respoolIDs, err := p.resolveRespools(filter.GetRespoolPath())
+	if err != nil {
+		return "", nil, err
+	}
+	podFilter, err := newPodFilter(filter, respoolIDs)
+	if err != nil {
+		return "", nil, err
+	}
+
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
@@ -195,35 +302,34 @@ func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskCl
 		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
 	}
 
-	podFilter := &podFilter{}
-	if filter != nil {
-		if filter.GetJobId() != nil {
-			podFilter.jobID = filter.GetJobId().GetValue()
+	var backlog []*PodEvent
+	if startRevision > 0 {
+		events, err := p.eventsSince(startRevision)
+		if err != nil {
+			return "", nil, err
 		}
-
-		if len(filter.GetPodNames()) > 0 {
-			podFilter.podNames = map[string]struct{}{}
-			for _, podName := range filter.GetPodNames() {
-				podFilter.podNames[podName.GetValue()] = struct{}{}
+		for _, e := range events {
+			if e.pod != nil && podFilter.match(e.pod) {
+				backlog = append(backlog, e.pod.event)
 			}
 		}
-
-		if len(filter.GetLabels()) > 0 {
-			podFilter.labels = filter.GetLabels()
-		}
 	}
 
 	watchID := NewWatchID(ClientTypeTask)
 	p.taskClients[watchID] = &TaskClient{
-		Input: make(chan *pod.PodSummary, p.bufferSize),
+		Input: make(chan *PodEvent, p.bufferSize),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
-		Signal: make(chan StopSignal, 1),
-		filter: podFilter,
+		Signal:   make(chan StopSignal, 1),
+		Revision: p.currentRevision(),
+		Backlog:  backlog,
+		filter:   podFilter,
 	}
 
 	log.WithField("watch_id", watchID).
 		WithField("filter", filter).
+		WithField("start_revision", startRevision).
+		WithField("backlog", len(backlog)).
 		Info("task watch client created")
 	return watchID, p.taskClients[watchID], nil
 }
@@ -276,64 +382,31 @@ func (p *watchProcessor) stopTaskClient(
 // which are interested in the pod.
 func (p *watchProcessor) NotifyPodChange(
 	pod *pod.PodSummary,
-	podLabels []*peloton.Label) {
+	podLabels []*peloton.Label,
+	jobType v0job.JobType,
+	respoolID *peloton.ResourcePoolID) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
 	sw.Stop()
 
-	for watchID, c := range p.taskClients {
-		filterCheckPass := func() bool {
-			if c.filter != nil {
-				// Check job ID filter
-				if len(c.filter.jobID) > 0 {
-					jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
-					if err != nil {
-						// Cannot parse podName to match the jobID, assume that
-						// filter does not match.
-						return false
-					}
-
-					if jobID != c.filter.jobID {
-						// job id filter did not match
-						return false
-					}
-				}
-
-				// Check pod name filter
-				if len(c.filter.podNames) > 0 {
-					if _, ok := c.filter.podNames[pod.GetPodName().GetValue()]; !ok {
-						return false
-					}
-				}
-
-				// Check pod label filter
-				for _, labelFilter := range c.filter.labels {
-					found := false
-					for _, labelPod := range podLabels {
-						if labelFilter.GetKey() == labelPod.GetKey() &&
-							labelFilter.GetValue() == labelPod.GetValue() {
-							found = true
-							break
-						}
-					}
-
-					if !found {
-						// label filter did not match
-						return false
-					}
-				}
-			}
-
-			return true
-		}()
+	event := &PodEvent{Pod: pod}
+	change := &podChange{
+		event:      event,
+		labels:     podLabels,
+		batch:      jobType == v0job.JobType_BATCH,
+		respoolID:  respoolID.GetValue(),
+		transition: p.updatePodState(pod),
+	}
+	p.addEvent(&historyEvent{pod: change})
 
-		if !filterCheckPass {
+	for watchID, c := range p.taskClients {
+		if !c.filter.match(change) {
 			continue
 		}
 
 		select {
-		case c.Input <- pod:
+		case c.Input <- event:
 		default:
 			log.WithField("watch_id", watchID).
 				Warn("event overflow for task watch client")
@@ -343,8 +416,66 @@ func (p *watchProcessor) NotifyPodChange(
 }
 
 // NewJobClient creates a new watch client for job event changes.
-// Returns the watch id and an new instance of JobClient.
-func (p *watchProcessor) NewJobClient(filter *watch.StatelessJobFilter) (string, *JobClient, error) {
+// If startRevision is set, the events since that revision are
+// replayed to the client from the event history. Returns the
+// watch id and an new instance of JobClient.
+func (p *watchProcessor) NewJobClient(
+	filter *watch.StatelessJobFilter,
+	startRevision uint64,
+) (string, *JobClient, error) {
+	respoolIDs, err := p.resolveRespools(filter.GetRespoolPath())
+	if err != nil {
+		return "", nil, err
+	}
+	jobFilter, err := newJobFilter(
+		filter.GetJobIds(),
+		filter.GetLabels(),
+		filter.GetLabelSelectors(),
+		filter.GetStates(),
+		respoolIDs,
+		false,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.newJobClient(jobFilter, filter, startRevision)
+}
+
+// NewBatchJobClient creates a new watch client for batch job event
+// changes. If startRevision is set, the events since that revision
+// are replayed to the client from the event history. Returns the
+// watch id and an new instance of JobClient.
+func (p *watchProcessor) NewBatchJobClient(
+	filter *watch.BatchJobFilter,
+	startRevision uint64,
+) (string, *JobClient, error) {
+	respoolIDs, err := p.resolveRespools(filter.GetRespoolPath())
+	if err != nil {
+		return "", nil, err
+	}
+	jobFilter, err := newJobFilter(
+		filter.GetJobIds(),
+		filter.GetLabels(),
+		filter.GetLabelSelectors(),
+		filter.GetStates(),
+		respoolIDs,
+		true,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.newJobClient(jobFilter, filter, startRevision)
+}
+
+// newJobClient creates a new watch client for the job filter. The
+// filter of the request is only used for logging.
+func (p *watchProcessor) newJobClient(
+	jobFilter *jobFilter,
+	filter interface{},
+	startRevision uint64,
+) (string, *JobClient, error) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
@@ -354,31 +485,34 @@ func (p *watchProcessor) NewJobClient(filter *watch.StatelessJobFilter) (string,
 		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
 	}
 
-	jobFilter := &jobFilter{}
-	if filter != nil {
-		if len(filter.GetJobIds()) > 0 {
-			jobFilter.jobIDs = map[string]struct{}{}
-			for _, jobID := range filter.GetJobIds() {
-				jobFilter.jobIDs[jobID.GetValue()] = struct{}{}
-			}
+	var backlog []*JobEvent
+	if startRevision > 0 {
+		events, err := p.eventsSince(startRevision)
+		if err != nil {
+			return "", nil, err
 		}
-
-		if len(filter.GetLabels()) > 0 {
-			jobFilter.labels = filter.GetLabels()
+		for _, e := range events {
+			if e.job != nil && jobFilter.match(e.job) {
+				backlog = append(backlog, e.job.event)
+			}
 		}
 	}
 
 	watchID := NewWatchID(ClientTypeJob)
 	p.jobClients[watchID] = &JobClient{
-		Input: make(chan *stateless.JobSummary, p.bufferSize),
+		Input: make(chan *JobEvent, p.bufferSize),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
-		Signal: make(chan StopSignal, 1),
-		filter: jobFilter,
+		Signal:   make(chan StopSignal, 1),
+		Revision: p.currentRevision(),
+		Backlog:  backlog,
+		filter:   jobFilter,
 	}
 
 	log.WithField("watch_id", watchID).
 		WithField("filter", filter).
+		WithField("start_revision", startRevision).
+		WithField("backlog", len(backlog)).
 		Info("job watch client created")
 	return watchID, p.jobClients[watchID], nil
 }
@@ -430,48 +564,39 @@ func (p *watchProcessor) stopJobClient(
 // NotifyJobChange receives job event, and notifies all the clients
 // which are interested in the job.
 func (p *watchProcessor) NotifyJobChange(job *stateless.JobSummary) {
+	p.notifyJobChange(job, false)
+}
+
+// NotifyBatchJobChange receives batch job event, and notifies all
+// the clients which are interested in the batch job.
+func (p *watchProcessor) NotifyBatchJobChange(job *stateless.JobSummary) {
+	p.notifyJobChange(job, true)
+}
+
+func (p *watchProcessor) notifyJobChange(
+	job *stateless.JobSummary,
+	batch bool,
+) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
 	sw.Stop()
 
-	for watchID, c := range p.jobClients {
-		filterCheckPass := func() bool {
-			if c.filter != nil {
-				// Check job IDs filter
-				if len(c.filter.jobIDs) > 0 {
-					jobID := job.GetJobId().GetValue()
-					if _, ok := c.filter.jobIDs[jobID]; !ok {
-						return false
-					}
-				}
-
-				// Check job label filter
-				for _, labelFilter := range c.filter.labels {
-					found := false
-					for _, labelJob := range job.GetLabels() {
-						if labelFilter.GetKey() == labelJob.GetKey() &&
-							labelFilter.GetValue() == labelJob.GetValue() {
-							found = true
-							break
-						}
-					}
-
-					if !found {
-						return false
-					}
-				}
-			}
-
-			return true
-		}()
+	event := &JobEvent{Job: job}
+	change := &jobChange{
+		event:      event,
+		batch:      batch,
+		transition: p.updateJobState(job),
+	}
+	p.addEvent(&historyEvent{job: change})
 
-		if !filterCheckPass {
+	for watchID, c := range p.jobClients {
+		if !c.filter.match(change) {
 			continue
 		}
 
 		select {
-		case c.Input <- job:
+		case c.Input <- event:
 		default:
 			log.WithField("watch_id", watchID).
 				Warn("event overflow for job watch client")
@@ -479,3 +604,149 @@ func (p *watchProcessor) NotifyJobChange(job *stateless.JobSummary) {
 		}
 	}
 }
+
+// CompactHistory drops the event history and the known states of
+// the jobs and pods on leadership change, since the events which
+// happen while not being the leader are not recorded. Watches
+// cannot resume from the dropped revisions, nor from the revision
+// following the last dropped one. The revisions are rebased on the
+// current time, so that the revisions of the previous leader, which
+// may have started after this processor, are compacted as well.
+func (p *watchProcessor) CompactHistory() {
+	sw := p.metrics.ProcessorLockDuration.Start()
+	p.Lock()
+	defer p.Unlock()
+	sw.Stop()
+
+	head, _ := p.history.GetRange()
+	p.history.MoveTail(head)
+	// skip at least a revision, so that the clients which have seen
+	// all the events before compaction cannot resume either, since they
+	// would miss the changes which happened while not being the leader
+	baseRevision := p.baseRevision + 1
+	if now := uint64(time.Now().UnixNano()); now > baseRevision+head {
+		baseRevision = now - head
+	}
+	p.baseRevision = baseRevision
+
+	p.jobStates = make(map[string]stateless.JobState)
+	p.podStates = make(map[string]pod.PodState)
+}
+
+// resolveRespools returns the resource pools in the subtree of the
+// path, or nil if the path is empty. The subtree is resolved once when
+// the watch is created, so resource pools created later are not watched.
+func (p *watchProcessor) resolveRespools(path string) (map[string]struct{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	if p.resolver == nil {
+		return nil, yarpcerrors.UnimplementedErrorf(
+			"resource pool filter is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _resolveTimeout)
+	defer cancel()
+	return p.resolver.ResolveSubtree(ctx, path)
+}
+
+// updatePodState records the state of the pod, and returns true
+// if the pod moved into the state. Terminal pods are forgotten,
+// so the known states are only kept for the active pods.
+// Note: not thread safe and need to be called with lock
+func (p *watchProcessor) updatePodState(pod *pod.PodSummary) bool {
+	podName := pod.GetPodName().GetValue()
+	state := pod.GetStatus().GetState()
+
+	prevState, ok := p.podStates[podName]
+	if util.IsPelotonPodStateTerminal(state) {
+		delete(p.podStates, podName)
+	} else {
+		p.podStates[podName] = state
+	}
+	return !ok || prevState != state
+}
+
+// updateJobState records the state of the job, and returns true if the job moved into the state. Terminal jobs are
+// forgotten, so the known states are only kept for the active jobs.
+// Note: not thread safe and need to be called with lock
+func (p *watchProcessor) updateJobState(job *stateless.JobSummary) bool {
+	jobID := job.GetJobId().GetValue()
+	state := job.GetStatus().GetState()
+
+	prevState, ok := p.jobStates[jobID]
+	if isJobStateTerminal(state) {
+		delete(p.jobStates, jobID)
+	} else {
+		p.jobStates[jobID] = state
+	}
+	return !ok || prevState != state
+}
+
+// currentRevision returns the revision of the latest event.
+// Note: not thread safe and need to be called with lock
+func (p *watchProcessor) currentRevision() uint64 {
+	head, _ := p.history.GetRange()
+	return p.baseRevision + head - 1
+}
+
+// addEvent adds an event to the history, dropping the oldest event if
+// the history is full, and sets the revision of the event.
+// Note: not thread safe and need to be called with lock
+func (p *watchProcessor) addEvent(e *historyEvent) {
+	head, tail := p.history.GetRange()
+	if int(head-tail) >= p.history.Capacity() {
+		p.history.MoveTail(tail + 1)
+	}
+
+	item, err := p.history.AddItem(e)
+	if err != nil {
+		// not expected since room is made for the event above
+		log.WithError(err).Error("failed to add event to watch history")
+		return
+	}
+
+	revision := p.baseRevision + item.SequenceID
+	if e.pod != nil {
+		e.pod.event.Revision = revision
+	}
+	if e.job != nil {
+		e.job.event.Revision = revision
+	}
+}
+
+// eventsSince returns the events in the history starting from the
+// revision. Returns "out-of-range" error if the revision has been
+// compacted, and "invalid-argument" error if the revision is newer
+// than the next revision of the server.
+// Note: not thread safe and need to be called with lock
+func (p *watchProcessor) eventsSince(revision uint64) ([]*historyEvent, error) {
+	head, tail := p.history.GetRange()
+	if revision > p.baseRevision+head {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"start revision %d is newer than server revision %d",
+			revision, p.currentRevision())
+	}
+	if revision < p.baseRevision+tail {
+		p.metrics.WatchCompacted.Inc(1)
+		return nil, yarpcerrors.OutOfRangeErrorf(
+			"start revision %d is compacted, oldest available revision is %d",
+			revision, p.baseRevision+tail)
+	}
+	if revision == p.baseRevision+head {
+		// the client has seen all the events
+		return nil, nil
+	}
+
+	items, err := p.history.GetItemsByRange(revision-p.baseRevision, head-1)
+	if err != nil {
+		return nil, yarpcerrors.InternalErrorf(
+			"failed to read watch history: %v", err)
+	}
+
+	events := make([]*historyEvent, 0, len(items))
+	for _, item := range items {
+		events = append(events, item.Value.(*historyEvent))
+	}
+	return events, nil
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	v0job "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
@@ -43,9 +44,27 @@ type WatchProcessorTestSuite struct {
 	instanceID uint32
 	podName    *peloton.PodName
 
+	resolver  *fakeResolver
 	processor WatchProcessor
 }
 
+// fakeResolver resolves the resource pools from fixed subtrees
+type fakeResolver struct {
+	subtrees map[string]map[string]struct{}
+}
+
+func (r *fakeResolver) ResolveSubtree(
+	ctx context.Context,
+	path string,
+) (map[string]struct{}, error) {
+	ids, ok := r.subtrees[path]
+	if !ok {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"resource pool %s not found", path)
+	}
+	return ids, nil
+}
+
 func (suite *WatchProcessorTestSuite) SetupTest() {
 	suite.ctx = context.Background()
 	suite.testScope = tally.NewTestScope("", map[string]string{})
@@ -57,7 +76,12 @@ func (suite *WatchProcessorTestSuite) SetupTest() {
 	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
 	suite.instanceID = uint32(1)
 	suite.podName = &peloton.PodName{Value: fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)}
-	suite.processor = newWatchProcessor(suite.config, suite.testScope)
+	suite.resolver = &fakeResolver{
+		subtrees: map[string]map[string]struct{}{
+			"/infra": {"respool-infra": {}, "respool-infra-compute": {}},
+		},
+	}
+	suite.processor = newWatchProcessor(suite.config, suite.resolver, suite.testScope)
 }
 
 func TestWatchProcessor(t *testing.T) {
@@ -67,13 +91,13 @@ func TestWatchProcessor(t *testing.T) {
 // TestInitWatchProcessor tests initialization of WatchProcessor
 func (suite *WatchProcessorTestSuite) TestInitWatchProcessor() {
 	suite.Nil(GetWatchProcessor())
-	InitWatchProcessor(suite.config, suite.testScope)
+	InitWatchProcessor(suite.config, suite.resolver, suite.testScope)
 	suite.NotNil(GetWatchProcessor())
 }
 
 // TestTaskClient tests basic setup and teardown of task watch client
 func (suite *WatchProcessorTestSuite) TestTaskClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -103,7 +127,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient() {
 // TestTaskClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -115,12 +139,12 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
 
 // TestTaskClient_StopAllClients tests stop all clients on losing leadership
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewTaskClient(nil)
+	watchID1, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID1)
 	suite.NotNil(c)
 
-	watchID2, c, err := suite.processor.NewTaskClient(nil)
+	watchID2, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID2)
 	suite.NotNil(c)
@@ -136,7 +160,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
 // creating a new client if max number of clients is reached.
 func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewTaskClient(nil)
+		watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -152,7 +176,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
 func (suite *WatchProcessorTestSuite) TestTaskClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -173,13 +197,13 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_EventOverflow() {
 
 	// send number of events equal to buffer size
 	for i := 0; i < 10; i++ {
-		suite.processor.NotifyPodChange(&pod.PodSummary{}, nil)
+		suite.processor.NotifyPodChange(&pod.PodSummary{}, nil, v0job.JobType_SERVICE, nil)
 	}
 	time.Sleep(1 * time.Second)
 	suite.Equal(StopSignalUnknown, stopSignal)
 
 	// trigger buffer overflow
-	suite.processor.NotifyPodChange(&pod.PodSummary{}, nil)
+	suite.processor.NotifyPodChange(&pod.PodSummary{}, nil, v0job.JobType_SERVICE, nil)
 	wg.Wait()
 	suite.Equal(StopSignalOverflow, stopSignal)
 }
@@ -195,7 +219,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -216,15 +240,15 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
 
 	suite.processor.NotifyPodChange(&pod.PodSummary{
 		PodName: suite.podName,
-	}, nil)
+	}, nil, v0job.JobType_SERVICE, nil)
 
 	suite.processor.NotifyPodChange(&pod.PodSummary{
 		PodName: &peloton.PodName{Value: "abc-1"},
-	}, nil)
+	}, nil, v0job.JobType_SERVICE, nil)
 
 	suite.processor.NotifyPodChange(&pod.PodSummary{
 		PodName: &peloton.PodName{Value: fmt.Sprintf("%s-%d", suite.jobID, 5)},
-	}, nil)
+	}, nil, v0job.JobType_SERVICE, nil)
 
 	time.Sleep(1 * time.Second)
 	err = suite.processor.StopTaskClient(watchID)
@@ -255,7 +279,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -277,16 +301,22 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 	suite.processor.NotifyPodChange(
 		&pod.PodSummary{},
 		[]*peloton.Label{label1},
+		v0job.JobType_SERVICE,
+		nil,
 	)
 
 	suite.processor.NotifyPodChange(
 		&pod.PodSummary{},
 		[]*peloton.Label{label2},
+		v0job.JobType_SERVICE,
+		nil,
 	)
 
 	suite.processor.NotifyPodChange(
 		&pod.PodSummary{},
 		[]*peloton.Label{label1, label2},
+		v0job.JobType_SERVICE,
+		nil,
 	)
 
 	time.Sleep(1 * time.Second)
@@ -301,7 +331,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 
 // TestJobClient tests basic setup and teardown of job watch client
 func (suite *WatchProcessorTestSuite) TestJobClient() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -331,7 +361,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient() {
 // TestJobClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
 func (suite *WatchProcessorTestSuite) TestJobClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -343,12 +373,12 @@ func (suite *WatchProcessorTestSuite) TestJobClient_StopNonexistentClient() {
 
 // TestJobClient_StopAllClients tests stop all clients on losing leadership
 func (suite *WatchProcessorTestSuite) TestJobClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewJobClient(nil)
+	watchID1, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID1)
 	suite.NotNil(c)
 
-	watchID2, c, err := suite.processor.NewJobClient(nil)
+	watchID2, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID2)
 	suite.NotNil(c)
@@ -364,7 +394,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient_StopAllClients() {
 // creating a new client if max number of clients is reached.
 func (suite *WatchProcessorTestSuite) TestJobClient_MaxClientReached() {
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewJobClient(nil)
+		watchID, c, err := suite.processor.NewJobClient(nil, 0)
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -380,7 +410,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient_MaxClientReached() {
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
 func (suite *WatchProcessorTestSuite) TestJobClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -422,7 +452,7 @@ func (suite *WatchProcessorTestSuite) TestJobClientJobFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewJobClient(filter)
+	watchID, c, err := suite.processor.NewJobClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -480,7 +510,7 @@ func (suite *WatchProcessorTestSuite) TestJobClientLabelFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewJobClient(filter)
+	watchID, c, err := suite.processor.NewJobClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -526,3 +556,433 @@ func (suite *WatchProcessorTestSuite) TestJobClientLabelFilter() {
 	suite.Equal(2, received)
 	mutex.Unlock()
 }
+
+// TestTaskClientResume tests that the pod events since the start
+// revision are replayed to a task watch client.
+func (suite *WatchProcessorTestSuite) TestTaskClientResume() {
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.Empty(c.Backlog)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	revision := c.Revision
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyPodChange(&pod.PodSummary{
+			PodName: &peloton.PodName{
+				Value: fmt.Sprintf("%s-%d", suite.jobID.GetValue(), i),
+			},
+		}, nil, v0job.JobType_SERVICE, nil)
+	}
+	suite.processor.NotifyJobChange(&stateless.JobSummary{JobId: suite.jobID})
+
+	// resume after the first pod event
+	watchID, c, err = suite.processor.NewTaskClient(nil, revision+2)
+	suite.NoError(err)
+	suite.Equal(revision+4, c.Revision)
+	suite.Len(c.Backlog, 2)
+	for i, e := range c.Backlog {
+		suite.Equal(revision+2+uint64(i), e.Revision)
+		suite.Equal(
+			fmt.Sprintf("%s-%d", suite.jobID.GetValue(), i+1),
+			e.Pod.GetPodName().GetValue(),
+		)
+	}
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// resume after the latest event
+	watchID, c, err = suite.processor.NewTaskClient(nil, revision+5)
+	suite.NoError(err)
+	suite.Empty(c.Backlog)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// resume from a revision newer than the server revision
+	_, _, err = suite.processor.NewTaskClient(nil, revision+6)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestTaskClientResumeFilter tests that only the pod events which
+// pass the filter are replayed to a task watch client.
+func (suite *WatchProcessorTestSuite) TestTaskClientResumeFilter() {
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	revision := c.Revision
+
+	suite.processor.NotifyPodChange(&pod.PodSummary{PodName: suite.podName}, nil, v0job.JobType_SERVICE, nil)
+	suite.processor.NotifyPodChange(&pod.PodSummary{
+		PodName: &peloton.PodName{
+			Value: fmt.Sprintf("%s-%d", uuid.NewRandom().String(), 0),
+		},
+	}, nil, v0job.JobType_SERVICE, nil)
+
+	_, c, err = suite.processor.NewTaskClient(&watch.PodFilter{
+		JobId: suite.jobID,
+	}, revision+1)
+	suite.NoError(err)
+	suite.Len(c.Backlog, 1)
+	suite.Equal(revision+1, c.Backlog[0].Revision)
+	suite.Equal(suite.podName.GetValue(), c.Backlog[0].Pod.GetPodName().GetValue())
+}
+
+// TestJobClientResume tests that the job events since the start
+// revision are replayed to a job watch client.
+func (suite *WatchProcessorTestSuite) TestJobClientResume() {
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopJobClient(watchID))
+	revision := c.Revision
+
+	suite.processor.NotifyJobChange(&stateless.JobSummary{JobId: suite.jobID})
+	suite.processor.NotifyPodChange(&pod.PodSummary{PodName: suite.podName}, nil, v0job.JobType_SERVICE, nil)
+	suite.processor.NotifyJobChange(&stateless.JobSummary{
+		JobId: &peloton.JobID{Value: uuid.NewRandom().String()},
+	})
+
+	_, c, err = suite.processor.NewJobClient(&watch.StatelessJobFilter{
+		JobIds: []*peloton.JobID{suite.jobID},
+	}, revision+1)
+	suite.NoError(err)
+	suite.Equal(revision+3, c.Revision)
+	suite.Len(c.Backlog, 1)
+	suite.Equal(revision+1, c.Backlog[0].Revision)
+	suite.Equal(suite.jobID.GetValue(), c.Backlog[0].Job.GetJobId().GetValue())
+}
+
+// TestClientResumeCompacted tests that watch clients cannot resume from
+// the revisions which are dropped from the event history.
+func (suite *WatchProcessorTestSuite) TestClientResumeCompacted() {
+	suite.config.HistorySize = 2
+	suite.processor = newWatchProcessor(suite.config, suite.resolver, suite.testScope)
+
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	revision := c.Revision
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyPodChange(&pod.PodSummary{PodName: suite.podName}, nil, v0job.JobType_SERVICE, nil)
+	}
+
+	// the first event is dropped from the history
+	_, _, err = suite.processor.NewTaskClient(nil, revision+1)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+	_, _, err = suite.processor.NewJobClient(nil, revision+1)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	watchID, c, err = suite.processor.NewTaskClient(nil, revision+2)
+	suite.NoError(err)
+	suite.Len(c.Backlog, 2)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// all events are dropped after leadership change
+	suite.processor.CompactHistory()
+	_, _, err = suite.processor.NewTaskClient(nil, revision+3)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	// a client which has seen all the events cannot resume either,
+	// since the events while not being the leader are missed
+	_, _, err = suite.processor.NewTaskClient(nil, revision+4)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	watchID, c, err = suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	suite.True(c.Revision > revision+3)
+
+	suite.processor.NotifyPodChange(&pod.PodSummary{PodName: suite.podName}, nil, v0job.JobType_SERVICE, nil)
+	watchID, c, err = suite.processor.NewTaskClient(nil, c.Revision+1)
+	suite.NoError(err)
+	suite.Len(c.Backlog, 1)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	suite.Equal(
+		int64(4),
+		suite.testScope.Snapshot().Counters()["watch.watch_compacted+"].Value(),
+	)
+}
+
+// TestClientResumeAfterFailover tests that a watch client of the
+// previous leader cannot resume on a standby which started before the
+// previous leader and gained leadership.
+func (suite *WatchProcessorTestSuite) TestClientResumeAfterFailover() {
+	standby := newWatchProcessor(suite.config, suite.resolver, suite.testScope)
+	leader := newWatchProcessor(suite.config, suite.resolver, suite.testScope)
+	// the standby started a second before the leader
+	standby.baseRevision = leader.baseRevision - uint64(time.Second)
+
+	for i := 0; i < 3; i++ {
+		leader.NotifyPodChange(&pod.PodSummary{PodName: suite.podName}, nil, v0job.JobType_SERVICE, nil)
+	}
+	watchID, c, err := leader.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(leader.StopTaskClient(watchID))
+	revision := c.Revision
+
+	// the standby rebases its revisions when it gains leadership, so
+	// the revisions of the previous leader are compacted instead of
+	// being newer than the server revision
+	standby.CompactHistory()
+	_, _, err = standby.NewTaskClient(nil, revision)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+	_, _, err = standby.NewJobClient(nil, revision+1)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	watchID, c, err = standby.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(standby.StopTaskClient(watchID))
+	suite.True(c.Revision > revision)
+}
+
+// podSummary returns the summary of a pod of the test job
+func (suite *WatchProcessorTestSuite) podSummary(
+	instanceID int,
+	state pod.PodState,
+	host string,
+) *pod.PodSummary {
+	return &pod.PodSummary{
+		PodName: &peloton.PodName{
+			Value: fmt.Sprintf("%s-%d", suite.jobID.GetValue(), instanceID),
+		},
+		Status: &pod.PodStatus{State: state, Host: host},
+	}
+}
+
+// podNames returns the names of the pods of the events
+func podNames(events []*PodEvent) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Pod.GetPodName().GetValue())
+	}
+	return names
+}
+
+// TestTaskClientStateFilter tests that only the pod changes which move
+// the pods into the states of the filter are sent to the client.
+func (suite *WatchProcessorTestSuite) TestTaskClientStateFilter() {
+	_, c, err := suite.processor.NewTaskClient(&watch.PodFilter{
+		States: []pod.PodState{pod.PodState_POD_STATE_RUNNING},
+	}, 0)
+	suite.NoError(err)
+
+	running := suite.podSummary(0, pod.PodState_POD_STATE_RUNNING, "host-0")
+	suite.processor.NotifyPodChange(
+		suite.podSummary(0, pod.PodState_POD_STATE_LAUNCHED, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		nil,
+	)
+	suite.processor.NotifyPodChange(running, nil, v0job.JobType_SERVICE, nil)
+	// the pod stays in the same state
+	suite.processor.NotifyPodChange(
+		suite.podSummary(0, pod.PodState_POD_STATE_RUNNING, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		nil,
+	)
+
+	suite.Len(c.Input, 1)
+	e := <-c.Input
+	suite.Equal(running, e.Pod)
+}
+
+// TestTaskClientHostAndRespoolFilter tests filtering
+// pods by host and by resource pool subtree.
+func (suite *WatchProcessorTestSuite) TestTaskClientHostAndRespoolFilter() {
+	watchID, c, err := suite.processor.NewTaskClient(&watch.PodFilter{
+		Hosts:       []string{"host-0"},
+		RespoolPath: "/infra",
+	}, 0)
+	suite.NoError(err)
+
+	respoolID := &peloton.ResourcePoolID{Value: "respool-infra-compute"}
+	// the resource pool of the job is not known
+	suite.processor.NotifyPodChange(
+		suite.podSummary(0, pod.PodState_POD_STATE_RUNNING, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		nil,
+	)
+	suite.processor.NotifyPodChange(
+		suite.podSummary(1, pod.PodState_POD_STATE_RUNNING, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		respoolID,
+	)
+	// the resource pool is not lost on leadership change
+	suite.processor.CompactHistory()
+	suite.processor.NotifyPodChange(
+		suite.podSummary(2, pod.PodState_POD_STATE_RUNNING, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		respoolID,
+	)
+	suite.processor.NotifyPodChange(
+		suite.podSummary(4, pod.PodState_POD_STATE_RUNNING, "host-1"),
+		nil,
+		v0job.JobType_SERVICE,
+		respoolID,
+	)
+	// the resource pool is not in the subtree
+	suite.processor.NotifyPodChange(
+		suite.podSummary(3, pod.PodState_POD_STATE_RUNNING, "host-0"),
+		nil,
+		v0job.JobType_SERVICE,
+		&peloton.ResourcePoolID{Value: "respool-other"},
+	)
+
+	suite.Len(c.Input, 2)
+	for _, instanceID := range []int{1, 2} {
+		e := <-c.Input
+		suite.Equal(
+			fmt.Sprintf("%s-%d", suite.jobID.GetValue(), instanceID),
+			e.Pod.GetPodName().GetValue(),
+		)
+	}
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// unknown resource pool
+	_, _, err = suite.processor.NewTaskClient(&watch.PodFilter{
+		RespoolPath: "/unknown",
+	}, 0)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// resource pool filter is not supported without resolver
+	suite.processor = newWatchProcessor(suite.config, nil, suite.testScope)
+	_, _, err = suite.processor.NewTaskClient(&watch.PodFilter{
+		RespoolPath: "/infra",
+	}, 0)
+	suite.True(yarpcerrors.IsUnimplemented(err))
+}
+
+// TestTaskClientLabelSelectorAndBatchFilter tests filtering pods
+// by label selectors, and watching the pods of batch jobs.
+func (suite *WatchProcessorTestSuite) TestTaskClientLabelSelectorAndBatchFilter() {
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	revision := c.Revision
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	prod := &peloton.Label{Key: "env", Value: "prod"}
+	test := &peloton.Label{Key: "env", Value: "test"}
+	suite.processor.NotifyPodChange(
+		suite.podSummary(0, pod.PodState_POD_STATE_RUNNING, ""),
+		[]*peloton.Label{prod},
+		v0job.JobType_SERVICE,
+		nil,
+	)
+	suite.processor.NotifyPodChange(
+		suite.podSummary(1, pod.PodState_POD_STATE_RUNNING, ""),
+		[]*peloton.Label{test},
+		v0job.JobType_SERVICE,
+		nil,
+	)
+	suite.processor.NotifyPodChange(
+		suite.podSummary(2, pod.PodState_POD_STATE_RUNNING, ""),
+		nil,
+		v0job.JobType_SERVICE,
+		nil,
+	)
+	suite.processor.NotifyPodChange(
+		suite.podSummary(3, pod.PodState_POD_STATE_RUNNING, ""),
+		[]*peloton.Label{prod},
+		v0job.JobType_BATCH,
+		nil,
+	)
+
+	name := func(instanceID int) string {
+		return fmt.Sprintf("%s-%d", suite.jobID.GetValue(), instanceID)
+	}
+
+	testCases := []struct {
+		filter   *watch.PodFilter
+		expected []string
+	}{
+		{
+			filter:   &watch.PodFilter{},
+			expected: []string{name(0), name(1), name(2)},
+		},
+		{
+			filter:   &watch.PodFilter{IncludeBatchPods: true},
+			expected: []string{name(0), name(1), name(2), name(3)},
+		},
+		{
+			filter: &watch.PodFilter{
+				LabelSelectors: []*watch.LabelSelector{{
+					Key:      "env",
+					Operator: watch.LabelSelector_OPERATOR_IN,
+					Values:   []string{"prod", "staging"},
+				}},
+			},
+			expected: []string{name(0)},
+		},
+		{
+			filter: &watch.PodFilter{
+				LabelSelectors: []*watch.LabelSelector{{
+					Key:      "env",
+					Operator: watch.LabelSelector_OPERATOR_NOT_IN,
+					Values:   []string{"prod"},
+				}},
+			},
+			expected: []string{name(1), name(2)},
+		},
+		{
+			filter: &watch.PodFilter{
+				LabelSelectors: []*watch.LabelSelector{{
+					Key:      "env",
+					Operator: watch.LabelSelector_OPERATOR_DOES_NOT_EXIST,
+				}},
+			},
+			expected: []string{name(2)},
+		},
+	}
+
+	for _, tc := range testCases {
+		watchID, c, err := suite.processor.NewTaskClient(tc.filter, revision+1)
+		suite.NoError(err)
+		suite.Equal(tc.expected, podNames(c.Backlog))
+		suite.NoError(suite.processor.StopTaskClient(watchID))
+	}
+
+	// invalid label selector
+	_, _, err = suite.processor.NewTaskClient(&watch.PodFilter{
+		LabelSelectors: []*watch.LabelSelector{{
+			Key:      "env",
+			Operator: watch.LabelSelector_OPERATOR_IN,
+		}},
+	}, 0)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestBatchJobClient tests that batch job changes are only sent to
+// batch job watch clients, and filtering the jobs by state.
+func (suite *WatchProcessorTestSuite) TestBatchJobClient() {
+	jobWatchID, jobClient, err := suite.processor.NewJobClient(nil, 0)
+	suite.NoError(err)
+	batchWatchID, batchClient, err := suite.processor.NewBatchJobClient(
+		&watch.BatchJobFilter{
+			States: []stateless.JobState{stateless.JobState_JOB_STATE_SUCCEEDED},
+		}, 0)
+	suite.NoError(err)
+
+	succeeded := &stateless.JobSummary{
+		JobId: suite.jobID,
+		Status: &stateless.JobStatus{
+			State: stateless.JobState_JOB_STATE_SUCCEEDED,
+		},
+	}
+	suite.processor.NotifyBatchJobChange(&stateless.JobSummary{
+		JobId: suite.jobID,
+		Status: &stateless.JobStatus{
+			State: stateless.JobState_JOB_STATE_RUNNING,
+		},
+	})
+	suite.processor.NotifyBatchJobChange(succeeded)
+
+	suite.Len(jobClient.Input, 0)
+	suite.Len(batchClient.Input, 1)
+	e := <-batchClient.Input
+	suite.Equal(succeeded, e.Job)
+
+	suite.NoError(suite.processor.StopJobClient(jobWatchID))
+	suite.NoError(suite.processor.StopJobClient(batchWatchID))
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ResourcePoolResolver resolves the resource pools
+// used by the resource pool filters of watches.
+type ResourcePoolResolver interface {
+	// ResolveSubtree returns the IDs of the resource pool at the path
+	// and all its descendant resource pools.
+	ResolveSubtree(ctx context.Context, path string) (map[string]struct{}, error)
+}
+
+// respoolResolver resolves the resource pools with Resource Manager.
+type respoolResolver struct {
+	client respool.ResourceManagerYARPCClient
+}
+
+// NewResourcePoolResolver returns a new ResourcePoolResolver, which
+// queries the resource pool tree from Resource Manager.
+func NewResourcePoolResolver(
+	client respool.ResourceManagerYARPCClient,
+) ResourcePoolResolver {
+	return &respoolResolver{client: client}
+}
+
+// ResolveSubtree returns the IDs of the resource pool at the path
+// and all its descendant resource pools.
+func (r *respoolResolver) ResolveSubtree(
+	ctx context.Context,
+	path string,
+) (map[string]struct{}, error) {
+	resp, err := r.client.Query(ctx, &respool.QueryRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != nil {
+		return nil, yarpcerrors.InternalErrorf(
+			"failed to query resource pools: %s", resp.GetError())
+	}
+
+	// the path of a descendant has the path of the
+	// resource pool, followed by a separator, as prefix
+	path = strings.TrimSuffix(path, "/")
+	prefix := path + "/"
+
+	ids := map[string]struct{}{}
+	for _, info := range resp.GetResourcePools() {
+		p := info.GetPath().GetValue()
+		if p == path || strings.HasPrefix(p, prefix) {
+			ids[info.GetId().GetValue()] = struct{}{}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"resource pool %s not found", path)
+	}
+	return ids, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// TestResolveSubtree tests resolving the resource
+// pools of a subtree with Resource Manager
+func TestResolveSubtree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := respoolmocks.NewMockResourceManagerYARPCClient(ctrl)
+	resolver := NewResourcePoolResolver(client)
+
+	pools := map[string]string{
+		"root":          "/",
+		"infra":         "/infra",
+		"infra-compute": "/infra/compute",
+		"infrastaging":  "/infrastaging",
+	}
+	var infos []*respool.ResourcePoolInfo
+	for id, path := range pools {
+		infos = append(infos, &respool.ResourcePoolInfo{
+			Id:   &peloton.ResourcePoolID{Value: id},
+			Path: &respool.ResourcePoolPath{Value: path},
+		})
+	}
+
+	client.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(&respool.QueryResponse{ResourcePools: infos}, nil).
+		Times(4)
+
+	ids, err := resolver.ResolveSubtree(context.Background(), "/infra")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{
+		"infra":         {},
+		"infra-compute": {},
+	}, ids)
+
+	ids, err = resolver.ResolveSubtree(context.Background(), "/infra/")
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+
+	ids, err = resolver.ResolveSubtree(context.Background(), "/")
+	assert.NoError(t, err)
+	assert.Len(t, ids, len(pools))
+
+	_, err = resolver.ResolveSubtree(context.Background(), "/unknown")
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	client.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("resmgr unavailable"))
+	_, err = resolver.ResolveSubtree(context.Background(), "/infra")
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+
+	"github.com/uber/peloton/pkg/auth"
+	pelotonaudit "github.com/uber/peloton/pkg/common/audit"
+	"github.com/uber/peloton/pkg/common/procedure"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// _pelotonAPIPrefix is the prefix of the procedures of the public
+// peloton APIs. The private APIs between the peloton components are
+// not audited.
+const _pelotonAPIPrefix = "peloton.api."
+
+// _applicationErrorCode is the error code of the calls
+// whose handler sets an application error
+const _applicationErrorCode = "application-error"
+
+// AuditInboundMiddleware records an audit event for each call to a
+// mutating procedure of the peloton APIs. It must be placed before the
+// auth middleware, so that the calls which are not authenticated or not
+// permitted are audited too. The auth middleware reports the
+// authenticated user of the call back to the audit middleware.
+type AuditInboundMiddleware struct {
+	enabled bool
+	// component is the name of the component which serves the calls
+	component string
+	sink      pelotonaudit.Sink
+	// labels the read only procedures, which are not audited
+	labelManager *procedure.LabelManager
+
+	now func() time.Time
+}
+
+// NewAuditInboundMiddleware returns a new audit inbound middleware,
+// which records the audit events to sink
+func NewAuditInboundMiddleware(
+	config pelotonaudit.Config,
+	component string,
+	sink pelotonaudit.Sink,
+) (*AuditInboundMiddleware, error) {
+	result := &AuditInboundMiddleware{
+		component: component,
+		now:       time.Now,
+	}
+	if !config.Enabled {
+		return result, nil
+	}
+
+	readAPIs := config.ReadAPIs
+	if len(readAPIs) == 0 {
+		readAPIs = _defaultReadAPIs
+	}
+	for _, api := range readAPIs {
+		if len(strings.Split(api, _ruleSeparator)) != 2 {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid config for read api: %s", api)
+		}
+	}
+
+	result.enabled = true
+	result.sink = sink
+	result.labelManager = procedure.NewLabelManager(&procedure.LabelManagerConfig{
+		Entries: []*procedure.LabelManagerConfigEntry{
+			{Procedures: readAPIs, Labels: []string{_readOnlyLabel}},
+		},
+	})
+	return result, nil
+}
+
+// Handle invokes the underlying handler and records
+// an audit event if the procedure is audited
+func (m *AuditInboundMiddleware) Handle(
+	ctx context.Context,
+	req *transport.Request,
+	resw transport.ResponseWriter,
+	h transport.UnaryHandler,
+) error {
+	if !m.isAudited(req.Procedure) {
+		return h.Handle(ctx, req, resw)
+	}
+
+	resources := m.extractResources(req)
+	ctx, call := withAuditCall(ctx)
+	if resw != nil {
+		call.resw = &auditResponseWriter{ResponseWriter: resw}
+		resw = call.resw
+	}
+	start := m.now()
+	err := h.Handle(ctx, req, resw)
+	m.record(ctx, req, call, resources, start, err)
+	return err
+}
+
+// HandleOneway invokes the underlying handler and records
+// an audit event if the procedure is audited
+func (m *AuditInboundMiddleware) HandleOneway(
+	ctx context.Context,
+	req *transport.Request,
+	h transport.OnewayHandler,
+) error {
+	if !m.isAudited(req.Procedure) {
+		return h.HandleOneway(ctx, req)
+	}
+
+	resources := m.extractResources(req)
+	ctx, call := withAuditCall(ctx)
+	start := m.now()
+	err := h.HandleOneway(ctx, req)
+	m.record(ctx, req, call, resources, start, err)
+	return err
+}
+
+// HandleStream invokes the underlying handler. Streams are only
+// used by read only procedures, so they are not audited.
+func (m *AuditInboundMiddleware) HandleStream(
+	s *transport.ServerStream,
+	h transport.StreamHandler,
+) error {
+	return h.HandleStream(s)
+}
+
+// isAudited returns true if the procedure is
+// a mutating procedure of the public peloton APIs
+func (m *AuditInboundMiddleware) isAudited(procedure string) bool {
+	if !m.enabled {
+		return false
+	}
+	if !strings.HasPrefix(procedure, _pelotonAPIPrefix) {
+		return false
+	}
+	return !m.labelManager.HasLabel(procedure, _readOnlyLabel)
+}
+
+// extractResources returns the resources targeted by the call.
+// The request body is read, and replaced so that the underlying
+// handler can read it again.
+func (m *AuditInboundMiddleware) extractResources(
+	req *transport.Request,
+) *pelotonaudit.Resources {
+	if req.Body == nil {
+		return &pelotonaudit.Resources{}
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body = bytes.NewReader(body)
+	if err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Debug("failed to read request body for audit")
+		return &pelotonaudit.Resources{}
+	}
+
+	resources, err := pelotonaudit.ExtractResources(
+		req.Procedure, string(req.Encoding), body)
+	if err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Debug("failed to extract resources for audit")
+		return &pelotonaudit.Resources{}
+	}
+	return resources
+}
+
+// record writes the audit event of a call to the sink
+func (m *AuditInboundMiddleware) record(
+	ctx context.Context,
+	req *transport.Request,
+	call *auditCall,
+	resources *pelotonaudit.Resources,
+	start time.Time,
+	err error,
+) {
+	event := &audit.AuditEvent{
+		EventId:       uuid.New(),
+		Timestamp:     start.UTC().Format(time.RFC3339Nano),
+		User:          call.userName(ctx),
+		Caller:        req.Caller,
+		Procedure:     req.Procedure,
+		Component:     m.component,
+		JobIds:        resources.JobIDs,
+		PodNames:      resources.PodNames,
+		ResourcePools: resources.ResourcePools,
+		Hostnames:     resources.Hostnames,
+		Outcome:       audit.Outcome_OUTCOME_SUCCEEDED,
+		LatencyMs:     uint64(m.now().Sub(start) / time.Millisecond),
+	}
+	if err != nil {
+		event.Outcome = audit.Outcome_OUTCOME_FAILED
+		yarpcErr := yarpcerrors.FromError(err)
+		event.ErrorCode = yarpcErr.Code().String()
+		event.ErrorMessage = yarpcErr.Message()
+	} else if call.resw != nil {
+		m.recordResponseError(req, call.resw, event)
+	}
+
+	if err := m.sink.Write(ctx, event); err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			WithField("user", event.GetUser()).
+			Warn("failed to record audit event")
+	}
+}
+
+// recordResponseError marks the event of a call as failed if the handler
+// set an application error, or returned an error in the response
+// message, which is how the v0 APIs report failures
+func (m *AuditInboundMiddleware) recordResponseError(
+	req *transport.Request,
+	resw *auditResponseWriter,
+	event *audit.AuditEvent,
+) {
+	if resw.applicationError {
+		event.Outcome = audit.Outcome_OUTCOME_FAILED
+		event.ErrorCode = _applicationErrorCode
+		return
+	}
+
+	code, message, err := pelotonaudit.ExtractResponseError(
+		req.Procedure, string(req.Encoding), resw.body.Bytes())
+	if err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Debug("failed to extract response error for audit")
+		return
+	}
+	if len(code) != 0 {
+		event.Outcome = audit.Outcome_OUTCOME_FAILED
+		event.ErrorCode = code
+		event.ErrorMessage = message
+	}
+}
+
+type auditCallKey struct{}
+
+// auditCall is the state of an audited call, which is
+// collected while the call passes the inner middleware
+type auditCall struct {
+	// user authenticated by the auth middleware
+	user auth.User
+	// resw records the response of unary calls
+	resw *auditResponseWriter
+}
+
+// withAuditCall returns a copy of the context which carries the state
+// of the audited call, so that the auth middleware can report the
+// authenticated user even if the call is not permitted
+func withAuditCall(ctx context.Context) (context.Context, *auditCall) {
+	call := &auditCall{}
+	return context.WithValue(ctx, auditCallKey{}, call), call
+}
+
+// setAuditUser reports the authenticated user of the call
+// to the audit middleware, if the call is audited
+func setAuditUser(ctx context.Context, user auth.User) {
+	if call, ok := ctx.Value(auditCallKey{}).(*auditCall); ok {
+		call.user = user
+	}
+}
+
+// userName returns the name of the user authenticated for the call,
+// or of the user in ctx if the audit middleware is placed after the
+// auth middleware. It is empty if the user has no name.
+func (c *auditCall) userName(ctx context.Context) string {
+	if c.user != nil {
+		ctx = auth.ContextWithUser(ctx, c.user)
+	}
+	return callerName(ctx, "")
+}
+
+// auditResponseWriter records the response of an audited call, so
+// that the errors reported in the response are audited
+type auditResponseWriter struct {
+	transport.ResponseWriter
+
+	body             bytes.Buffer
+	applicationError bool
+}
+
+// Write records the response body and writes it to the response
+func (w *auditResponseWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// SetApplicationError records the application error
+// and sets it on the response
+func (w *auditResponseWriter) SetApplicationError() {
+	w.applicationError = true
+	w.ResponseWriter.SetApplicationError()
+}
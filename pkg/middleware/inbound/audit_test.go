@@ -0,0 +1,347 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	pberrors "github.com/uber/peloton/.gen/peloton/api/v0/errors"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+	pelotonaudit "github.com/uber/peloton/pkg/common/audit"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/middleware"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const _testStopJobProcedure = "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob"
+
+// testSink records the audit events written to it
+type testSink struct {
+	events []*audit.AuditEvent
+	err    error
+}
+
+func (s *testSink) Write(ctx context.Context, event *audit.AuditEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+type AuditInboundMiddlewareTestSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	sink *testSink
+	mw   *AuditInboundMiddleware
+	ctx  context.Context
+}
+
+func (suite *AuditInboundMiddlewareTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.sink = &testSink{}
+
+	var err error
+	suite.mw, err = NewAuditInboundMiddleware(
+		pelotonaudit.Config{Enabled: true},
+		"peloton-jobmgr",
+		suite.sink,
+	)
+	suite.NoError(err)
+
+	suite.ctx = auth.ContextWithUser(
+		context.Background(), &testUser{name: "alice"})
+}
+
+func (suite *AuditInboundMiddlewareTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// newStopJobRequest returns the transport request of a StopJob call
+func (suite *AuditInboundMiddlewareTestSuite) newStopJobRequest(
+	jobID string,
+) *transport.Request {
+	body, err := proto.Marshal(&statelesssvc.StopJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: jobID},
+	})
+	suite.NoError(err)
+
+	return &transport.Request{
+		Caller:    "peloton-cli",
+		Procedure: _testStopJobProcedure,
+		Encoding:  "proto",
+		Body:      bytes.NewReader(body),
+	}
+}
+
+// TestHandleSuccess tests recording the audit event of a successful call
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleSuccess() {
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, nil).
+		Do(func(
+			ctx context.Context,
+			req *transport.Request,
+			resw transport.ResponseWriter,
+		) {
+			// the handler can still read the request body
+			body, err := ioutil.ReadAll(req.Body)
+			suite.NoError(err)
+			request := &statelesssvc.StopJobRequest{}
+			suite.NoError(proto.Unmarshal(body, request))
+			suite.Equal("job-1", request.GetJobId().GetValue())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.mw.Handle(suite.ctx, req, nil, h))
+
+	suite.Len(suite.sink.events, 1)
+	event := suite.sink.events[0]
+	suite.NotEmpty(event.GetEventId())
+	suite.NotEmpty(event.GetTimestamp())
+	suite.Equal("alice", event.GetUser())
+	suite.Equal("peloton-cli", event.GetCaller())
+	suite.Equal(_testStopJobProcedure, event.GetProcedure())
+	suite.Equal("peloton-jobmgr", event.GetComponent())
+	suite.Equal([]string{"job-1"}, event.GetJobIds())
+	suite.Equal(audit.Outcome_OUTCOME_SUCCEEDED, event.GetOutcome())
+	suite.Empty(event.GetErrorCode())
+}
+
+// TestHandleFailure tests recording the audit event of a failed call
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleFailure() {
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, nil).
+		Return(yarpcerrors.NotFoundErrorf("job not found"))
+
+	suite.Error(suite.mw.Handle(suite.ctx, req, nil, h))
+
+	suite.Len(suite.sink.events, 1)
+	event := suite.sink.events[0]
+	suite.Equal(audit.Outcome_OUTCOME_FAILED, event.GetOutcome())
+	suite.Equal(yarpcerrors.CodeNotFound.String(), event.GetErrorCode())
+	suite.Equal("job not found", event.GetErrorMessage())
+}
+
+// TestHandleNotPermitted tests that the calls which are not permitted
+// by the auth middleware placed after the audit middleware are
+// recorded with the authenticated user
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleNotPermitted() {
+	securityManager := auth_mocks.NewMockSecurityManager(suite.ctrl)
+	user := auth_mocks.NewMockNamedUser(suite.ctrl)
+	securityManager.EXPECT().Authenticate(gomock.Any()).Return(user, nil)
+	securityManager.EXPECT().RedactToken(gomock.Any())
+	user.EXPECT().IsPermitted(_testStopJobProcedure).Return(false)
+	user.EXPECT().Name().Return("bob").AnyTimes()
+
+	h := middleware.ApplyUnaryInbound(
+		transporttest.NewMockUnaryHandler(suite.ctrl),
+		NewAuthInboundMiddleware(securityManager),
+	)
+
+	req := suite.newStopJobRequest("job-1")
+	req.Service = _testService
+	suite.Error(suite.mw.Handle(context.Background(), req, nil, h))
+
+	suite.Len(suite.sink.events, 1)
+	event := suite.sink.events[0]
+	suite.Equal("bob", event.GetUser())
+	suite.Equal(audit.Outcome_OUTCOME_FAILED, event.GetOutcome())
+	suite.Equal(yarpcerrors.CodePermissionDenied.String(), event.GetErrorCode())
+}
+
+// TestHandleApplicationError tests recording the audit event
+// of a call whose handler sets an application error
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleApplicationError() {
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, gomock.Any()).
+		Do(func(
+			ctx context.Context,
+			req *transport.Request,
+			resw transport.ResponseWriter,
+		) {
+			resw.SetApplicationError()
+		}).
+		Return(nil)
+
+	suite.NoError(suite.mw.Handle(suite.ctx, req, &testResponseWriter{}, h))
+
+	suite.Len(suite.sink.events, 1)
+	event := suite.sink.events[0]
+	suite.Equal(audit.Outcome_OUTCOME_FAILED, event.GetOutcome())
+	suite.Equal(_applicationErrorCode, event.GetErrorCode())
+}
+
+// TestHandleResponseError tests recording the audit event of a
+// v0 call which reports the failure in the response message
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleResponseError() {
+	body, err := proto.Marshal(&job.DeleteRequest{
+		Id: &peloton.JobID{Value: "job-1"},
+	})
+	suite.NoError(err)
+	req := &transport.Request{
+		Caller:    "peloton-cli",
+		Procedure: "peloton.api.v0.job.JobManager::Delete",
+		Encoding:  "proto",
+		Body:      bytes.NewReader(body),
+	}
+
+	respBody, err := proto.Marshal(&job.DeleteResponse{
+		Error: &job.DeleteResponse_Error{
+			NotFound: &pberrors.JobNotFound{
+				Id:      &peloton.JobID{Value: "job-1"},
+				Message: "job not found",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, gomock.Any()).
+		Do(func(
+			ctx context.Context,
+			req *transport.Request,
+			resw transport.ResponseWriter,
+		) {
+			_, err := resw.Write(respBody)
+			suite.NoError(err)
+		}).
+		Return(nil)
+
+	resw := &testResponseWriter{}
+	suite.NoError(suite.mw.Handle(suite.ctx, req, resw, h))
+	// the response is still written to the caller
+	suite.Equal(respBody, resw.Bytes())
+
+	suite.Len(suite.sink.events, 1)
+	event := suite.sink.events[0]
+	suite.Equal([]string{"job-1"}, event.GetJobIds())
+	suite.Equal(audit.Outcome_OUTCOME_FAILED, event.GetOutcome())
+	suite.Equal("NotFound", event.GetErrorCode())
+	suite.Contains(event.GetErrorMessage(), "job not found")
+}
+
+// TestHandleSinkError tests that the call succeeds
+// when the audit event cannot be recorded
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleSinkError() {
+	suite.sink.err = errors.New("sink error")
+
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, nil).Return(nil)
+
+	suite.NoError(suite.mw.Handle(suite.ctx, req, nil, h))
+	suite.Len(suite.sink.events, 1)
+}
+
+// TestHandleUndecodableBody tests that the call is audited
+// even if the targeted resources cannot be extracted
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleUndecodableBody() {
+	req := &transport.Request{
+		Procedure: _testStopJobProcedure,
+		Encoding:  "proto",
+		Body:      bytes.NewReader([]byte("not a proto")),
+	}
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, nil).Return(nil)
+
+	suite.NoError(suite.mw.Handle(suite.ctx, req, nil, h))
+	suite.Len(suite.sink.events, 1)
+	suite.Empty(suite.sink.events[0].GetJobIds())
+}
+
+// TestHandleNotAudited tests that read only procedures, private
+// peloton procedures and non peloton procedures are not audited
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleNotAudited() {
+	procedures := []string{
+		"peloton.api.v1alpha.job.stateless.svc.JobService::GetJob",
+		"peloton.api.v1alpha.job.stateless.svc.JobService::ListJobs",
+		"peloton.api.v1alpha.watch.svc.WatchService::Cancel",
+		"peloton.private.resmgr.ResourceManagerService::EnqueueGangs",
+		"peloton.private.hostmgr.hostsvc.InternalHostService::LaunchTasks",
+		"grpc.health.v1.Health::Check",
+	}
+
+	for _, procedure := range procedures {
+		req := &transport.Request{Procedure: procedure}
+		h := transporttest.NewMockUnaryHandler(suite.ctrl)
+		h.EXPECT().Handle(gomock.Any(), req, nil).Return(nil)
+		suite.NoError(suite.mw.Handle(suite.ctx, req, nil, h))
+	}
+	suite.Empty(suite.sink.events)
+}
+
+// TestHandleDisabled tests that no call is audited
+// when the audit log is disabled
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleDisabled() {
+	mw, err := NewAuditInboundMiddleware(
+		pelotonaudit.Config{}, "peloton-jobmgr", nil)
+	suite.NoError(err)
+
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, nil).Return(nil)
+	suite.NoError(mw.Handle(suite.ctx, req, nil, h))
+}
+
+// TestHandleOneway tests recording the audit event of a oneway call
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleOneway() {
+	req := suite.newStopJobRequest("job-1")
+	h := transporttest.NewMockOnewayHandler(suite.ctrl)
+	h.EXPECT().HandleOneway(gomock.Any(), req).Return(nil)
+
+	suite.NoError(suite.mw.HandleOneway(suite.ctx, req, h))
+	suite.Len(suite.sink.events, 1)
+	suite.Equal([]string{"job-1"}, suite.sink.events[0].GetJobIds())
+}
+
+// TestHandleStream tests that streams are passed through
+func (suite *AuditInboundMiddlewareTestSuite) TestHandleStream() {
+	h := transporttest.NewMockStreamHandler(suite.ctrl)
+	h.EXPECT().HandleStream(gomock.Any()).Return(nil)
+
+	suite.NoError(suite.mw.HandleStream(nil, h))
+	suite.Empty(suite.sink.events)
+}
+
+// TestInvalidReadAPIs tests creating the middleware with invalid read apis
+func (suite *AuditInboundMiddlewareTestSuite) TestInvalidReadAPIs() {
+	_, err := NewAuditInboundMiddleware(
+		pelotonaudit.Config{Enabled: true, ReadAPIs: []string{"Get*"}},
+		"peloton-jobmgr",
+		suite.sink,
+	)
+	suite.Error(err)
+}
+
+func TestAuditInboundMiddleware(t *testing.T) {
+	suite.Run(t, new(AuditInboundMiddlewareTestSuite))
+}
@@ -40,30 +40,32 @@ type AuthInboundMiddleware struct {
 
 // Handle authenticates user and invokes the underlying handler
 func (m *AuthInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure, req.Caller)
+	user, permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure, req.Caller)
 	if err != nil {
 		return err
 	}
+	setAuditUser(ctx, user)
 
 	if !permitted {
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
 	}
 
-	return h.Handle(ctx, req, resw)
+	return h.Handle(withUser(ctx, user), req, resw)
 }
 
 // HandleOneway authenticates user and invokes the underlying handler
 func (m *AuthInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure, req.Caller)
+	user, permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure, req.Caller)
 	if err != nil {
 		return err
 	}
+	setAuditUser(ctx, user)
 
 	if !permitted {
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
 	}
 
-	return h.HandleOneway(ctx, req)
+	return h.HandleOneway(withUser(ctx, user), req)
 }
 
 // HandleStream authenticates user and invokes the underlying handler
@@ -71,7 +73,7 @@ func (m *AuthInboundMiddleware) HandleStream(s *transport.ServerStream, h transp
 	service := s.Request().Meta.Service
 	procedure := s.Request().Meta.Procedure
 
-	permitted, err := m.isPermitted(s.Request().Meta.Headers, service, procedure, s.Request().Meta.Caller)
+	user, permitted, err := m.isPermitted(s.Request().Meta.Headers, service, procedure, s.Request().Meta.Caller)
 	if err != nil {
 		return err
 	}
@@ -80,6 +82,17 @@ func (m *AuthInboundMiddleware) HandleStream(s *transport.ServerStream, h transp
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, service, procedure)
 	}
 
+	if user == nil {
+		return h.HandleStream(s)
+	}
+
+	s, err = transport.NewServerStream(&userStream{
+		Stream: s,
+		ctx:    withUser(s.Context(), user),
+	})
+	if err != nil {
+		return err
+	}
 	return h.HandleStream(s)
 }
 
@@ -87,17 +100,17 @@ func (m *AuthInboundMiddleware) isPermitted(
 	headers transport.Headers,
 	service string,
 	procedure string,
-	caller string) (permitted bool, err error) {
+	caller string) (user auth.User, permitted bool, err error) {
 	// check the service name and authenticate only peloton services.
 	// Other services such as Mesos callback (service name: Scheduler)
 	// cannot be authenticated by peloton auth mechanism for now.
 	if !strings.HasPrefix(service, _pelotonServicePrefix) {
-		return true, nil
+		return nil, true, nil
 	}
 
-	user, err := m.Authenticate(headers)
+	user, err = m.Authenticate(headers)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 
 	m.RedactToken(headers)
@@ -111,7 +124,29 @@ func (m *AuthInboundMiddleware) isPermitted(
 		}).Info("procedure called not permitted for user")
 	}
 
-	return permitted, err
+	return user, permitted, err
+}
+
+// withUser returns a copy of the context which carries the user,
+// so that handlers can authorize the user on the resources the
+// procedure is called on
+func withUser(ctx context.Context, user auth.User) context.Context {
+	if user == nil {
+		return ctx
+	}
+	return auth.ContextWithUser(ctx, user)
+}
+
+// userStream is a stream whose context carries the authenticated user
+type userStream struct {
+	transport.Stream
+
+	ctx context.Context
+}
+
+// Context returns the context of the stream which carries the user
+func (s *userStream) Context() context.Context {
+	return s.ctx
 }
 
 // NewAuthInboundMiddleware returns AuthInboundMiddleware with auth check
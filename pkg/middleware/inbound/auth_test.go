@@ -18,6 +18,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
 	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -66,6 +67,21 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleSuccess() {
 	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
 }
 
+func (suite *AuthInboundMiddlewareSuite) TestHandlePassesUserToHandler() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.s.EXPECT().RedactToken(gomock.Any()).Return()
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			user, ok := auth.UserFromContext(ctx)
+			suite.True(ok)
+			suite.Equal(suite.u, user)
+		}).
+		Return(nil)
+	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
+}
+
 func (suite *AuthInboundMiddlewareSuite) TestHandleAuthenticateFail() {
 	h := transporttest.NewMockUnaryHandler(suite.ctrl)
 	suite.s.EXPECT().Authenticate(gomock.Any()).Return(nil, errors.New("test error"))
@@ -130,6 +146,7 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleStreamSuccess() {
 		Request().
 		Return(&transport.StreamRequest{Meta: &transport.RequestMeta{Service: _testService}}).
 		MinTimes(1)
+	s.EXPECT().Context().Return(context.Background())
 	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
 	suite.s.EXPECT().RedactToken(gomock.Any()).Return()
 	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
@@ -137,6 +154,29 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleStreamSuccess() {
 	suite.NoError(suite.m.HandleStream(ss, h))
 }
 
+func (suite *AuthInboundMiddlewareSuite) TestHandleStreamPassesUserToHandler() {
+	h := transporttest.NewMockStreamHandler(suite.ctrl)
+	s := transporttest.NewMockStream(suite.ctrl)
+	ss, err := transport.NewServerStream(s)
+	suite.NoError(err)
+	s.EXPECT().
+		Request().
+		Return(&transport.StreamRequest{Meta: &transport.RequestMeta{Service: _testService}}).
+		MinTimes(1)
+	s.EXPECT().Context().Return(context.Background())
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.s.EXPECT().RedactToken(gomock.Any()).Return()
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	h.EXPECT().HandleStream(gomock.Any()).
+		Do(func(ss *transport.ServerStream) {
+			user, ok := auth.UserFromContext(ss.Context())
+			suite.True(ok)
+			suite.Equal(suite.u, user)
+		}).
+		Return(nil)
+	suite.NoError(suite.m.HandleStream(ss, h))
+}
+
 func (suite *AuthInboundMiddlewareSuite) TestHandleStreamAuthenticateFail() {
 	h := transporttest.NewMockStreamHandler(suite.ctrl)
 	s := transporttest.NewMockStream(suite.ctrl)
@@ -174,6 +214,7 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleStreamHandlerFail() {
 		Request().
 		Return(&transport.StreamRequest{Meta: &transport.RequestMeta{Service: _testService}}).
 		MinTimes(1)
+	s.EXPECT().Context().Return(context.Background())
 	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
 	suite.s.EXPECT().RedactToken(gomock.Any()).Return()
 	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
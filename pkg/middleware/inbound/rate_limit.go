@@ -16,7 +16,12 @@ package inbound
 
 import (
 	"context"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/procedure"
 
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -25,6 +30,26 @@ import (
 
 var rateLimitError = yarpcerrors.ResourceExhaustedErrorf("rate limit reached for the endpoint")
 
+// _rateLimitRemainingHeader is the response header which reports
+// the number of calls left in the quota of the caller
+const _rateLimitRemainingHeader = "x-ratelimit-remaining"
+
+// _readOnlyLabel is the label of the read only procedures,
+// which are rate limited separately from the mutating ones
+const _readOnlyLabel = "read"
+
+// default read only procedures, aurora bridge methods start
+// with a lower case letter
+var _defaultReadAPIs = []string{
+	"*:Get*",
+	"*:get*",
+	"*:Query*",
+	"*:List*",
+	"*:Browse*",
+	"*:Lookup*",
+	"peloton.api.v1alpha.watch.svc.WatchService:*",
+}
+
 type RateLimitInboundMiddleware struct {
 	enabled bool
 
@@ -32,6 +57,16 @@ type RateLimitInboundMiddleware struct {
 	// same order as defined in RateLimitConfig.Methods
 	rateLimits       map[string][]*rateLimiter
 	defaultRateLimit *rate.Limiter
+
+	// labels the read only procedures
+	labelManager *procedure.LabelManager
+	// rate limits of each caller, nil if callers are not rate limited
+	callerLimiter *keyedLimiter
+	// rate limits of each resource pool,
+	// nil if resource pools are not rate limited
+	resourcePoolLimiter *keyedLimiter
+
+	now func() time.Time
 }
 
 type rateLimiter struct {
@@ -69,10 +104,49 @@ type RateLimitConfig struct {
 	// if the method called is not defined in Methods field.
 	// If not set, there is no rate limit.
 	Default *TokenBucket `yaml:",omitempty"`
+	// ReadAPIs are the read only procedures, e.g. *:Get*, which are
+	// rate limited separately from the mutating procedures by the
+	// Caller and ResourcePool rate limits. If not set, the Get, Query,
+	// List, Browse and Lookup methods and the watch service are read only.
+	ReadAPIs []string `yaml:"read_apis"`
+	// Caller is the rate limit of each caller, in addition to the
+	// rate limit of the method. A caller is identified by the name of
+	// the authenticated user, or by the caller service name if the user
+	// has no name. If not set, callers are not rate limited.
+	Caller *KeyedRateLimitConfig `yaml:"caller,omitempty"`
+	// ResourcePool is the rate limit of each resource pool, for the
+	// procedures called on a job in the resource pool. If not set,
+	// resource pools are not rate limited.
+	ResourcePool *KeyedRateLimitConfig `yaml:"resource_pool,omitempty"`
+}
+
+// KeyedRateLimitConfig is the config of the rate limits of each key,
+// e.g. each caller or each resource pool. If the rate limit is not
+// set for read only or mutating methods, there is no rate limit for them.
+type KeyedRateLimitConfig struct {
+	// Read is the rate limit of the read only methods
+	Read *TokenBucket `yaml:",omitempty"`
+	// Write is the rate limit of the mutating methods
+	Write *TokenBucket `yaml:",omitempty"`
+	// Overrides are the rate limits of specific keys
+	Overrides []*RateLimitOverride
+}
+
+// RateLimitOverride overrides the rate limit of a key, the rate
+// limit which is not set falls back to the one of KeyedRateLimitConfig
+type RateLimitOverride struct {
+	// Name is the key, e.g. the name of the caller,
+	// or the path of the resource pool
+	Name  string
+	Read  *TokenBucket `yaml:",omitempty"`
+	Write *TokenBucket `yaml:",omitempty"`
 }
 
 func NewRateLimitInboundMiddleware(config RateLimitConfig) (*RateLimitInboundMiddleware, error) {
-	result := &RateLimitInboundMiddleware{rateLimits: make(map[string][]*rateLimiter)}
+	result := &RateLimitInboundMiddleware{
+		rateLimits: make(map[string][]*rateLimiter),
+		now:        time.Now,
+	}
 	if !config.Enabled {
 		return result, nil
 	}
@@ -100,6 +174,29 @@ func NewRateLimitInboundMiddleware(config RateLimitConfig) (*RateLimitInboundMid
 	} else {
 		result.defaultRateLimit = createLimiter(config.Default.Rate, config.Default.Burst)
 	}
+
+	readAPIs := config.ReadAPIs
+	if len(readAPIs) == 0 {
+		readAPIs = _defaultReadAPIs
+	}
+	for _, api := range readAPIs {
+		if len(strings.Split(api, _ruleSeparator)) != 2 {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid config for read api: %s", api)
+		}
+	}
+	result.labelManager = procedure.NewLabelManager(&procedure.LabelManagerConfig{
+		Entries: []*procedure.LabelManagerConfigEntry{
+			{Procedures: readAPIs, Labels: []string{_readOnlyLabel}},
+		},
+	})
+
+	if config.Caller != nil {
+		result.callerLimiter = newKeyedLimiter(config.Caller)
+	}
+	if config.ResourcePool != nil {
+		result.resourcePoolLimiter = newKeyedLimiter(config.ResourcePool)
+	}
 	return result, nil
 }
 
@@ -112,7 +209,8 @@ func createLimiter(r rate.Limit, b int) *rate.Limiter {
 	return rate.NewLimiter(r, b)
 }
 
-// Handle checks rate limit quota and invokes underlying handler
+// Handle checks the rate limit quota of the method
+// and invokes underlying handler
 func (m *RateLimitInboundMiddleware) Handle(
 	ctx context.Context,
 	req *transport.Request,
@@ -126,7 +224,8 @@ func (m *RateLimitInboundMiddleware) Handle(
 	return h.Handle(ctx, req, resw)
 }
 
-// HandleOneway checks rate limit quota and invokes underlying handler
+// HandleOneway checks the rate limit quota of the method
+// and invokes underlying handler
 func (m *RateLimitInboundMiddleware) HandleOneway(
 	ctx context.Context,
 	req *transport.Request,
@@ -139,7 +238,8 @@ func (m *RateLimitInboundMiddleware) HandleOneway(
 	return h.HandleOneway(ctx, req)
 }
 
-// HandleStream checks rate limit quota and invokes underlying handler
+// HandleStream checks the rate limit quota of the method
+// and invokes underlying handler
 func (m *RateLimitInboundMiddleware) HandleStream(
 	s *transport.ServerStream,
 	h transport.StreamHandler,
@@ -151,6 +251,165 @@ func (m *RateLimitInboundMiddleware) HandleStream(
 	return h.HandleStream(s)
 }
 
+// CallerRateLimitInboundMiddleware is the inbound middleware which checks
+// the rate limits of the callers and the resource pools. It must be placed
+// after the auth middleware, so that callers are identified by the
+// authenticated users, while RateLimitInboundMiddleware is placed before
+// the auth middleware, so that unauthenticated calls are rate limited too.
+type CallerRateLimitInboundMiddleware struct {
+	*RateLimitInboundMiddleware
+}
+
+// CallerMiddleware returns the inbound middleware which checks the
+// rate limits of the callers and the resource pools of the config
+func (m *RateLimitInboundMiddleware) CallerMiddleware() *CallerRateLimitInboundMiddleware {
+	return &CallerRateLimitInboundMiddleware{RateLimitInboundMiddleware: m}
+}
+
+// Handle checks rate limit quota of the caller and invokes underlying
+// handler, the quota left for the caller is reported in the response header
+func (m *CallerRateLimitInboundMiddleware) Handle(
+	ctx context.Context,
+	req *transport.Request,
+	resw transport.ResponseWriter,
+	h transport.UnaryHandler,
+) error {
+	remaining, err := m.allowCaller(ctx, req.Procedure, req.Caller)
+	if err != nil {
+		return err
+	}
+
+	if remaining >= 0 && resw != nil {
+		resw.AddHeaders(transport.NewHeaders().
+			With(_rateLimitRemainingHeader, strconv.Itoa(remaining)))
+	}
+
+	return h.Handle(m.withResourcePoolQuota(ctx, req.Procedure), req, resw)
+}
+
+// HandleOneway checks rate limit quota of the caller
+// and invokes underlying handler
+func (m *CallerRateLimitInboundMiddleware) HandleOneway(
+	ctx context.Context,
+	req *transport.Request,
+	h transport.OnewayHandler,
+) error {
+	if _, err := m.allowCaller(ctx, req.Procedure, req.Caller); err != nil {
+		return err
+	}
+
+	return h.HandleOneway(m.withResourcePoolQuota(ctx, req.Procedure), req)
+}
+
+// HandleStream checks rate limit quota of the caller
+// and invokes underlying handler
+func (m *CallerRateLimitInboundMiddleware) HandleStream(
+	s *transport.ServerStream,
+	h transport.StreamHandler,
+) error {
+	if _, err := m.allowCaller(
+		s.Context(),
+		s.Request().Meta.Procedure,
+		s.Request().Meta.Caller,
+	); err != nil {
+		return err
+	}
+
+	return h.HandleStream(s)
+}
+
+// allowCaller checks the quota of the caller for the procedure, and
+// returns the number of calls left in the quota. The number of calls
+// left is -1 if the caller is not rate limited.
+func (m *RateLimitInboundMiddleware) allowCaller(
+	ctx context.Context,
+	procedure string,
+	caller string,
+) (int, error) {
+	if !m.enabled || m.callerLimiter == nil {
+		return -1, nil
+	}
+
+	name := callerName(ctx, caller)
+	allowed, remaining := m.callerLimiter.take(
+		name, !m.isReadOnly(procedure), m.now())
+	if !allowed {
+		return 0, yarpcerrors.ResourceExhaustedErrorf(
+			"rate limit reached for caller %s", name)
+	}
+	return remaining, nil
+}
+
+// callerName returns the name of the authenticated user in ctx,
+// or the caller service name if the user has no name
+func callerName(ctx context.Context, caller string) string {
+	if user, ok := auth.UserFromContext(ctx); ok {
+		if namedUser, ok := user.(auth.NamedUser); ok &&
+			len(namedUser.Name()) != 0 {
+			return namedUser.Name()
+		}
+	}
+	return caller
+}
+
+// isReadOnly returns true if the procedure is read only
+func (m *RateLimitInboundMiddleware) isReadOnly(procedure string) bool {
+	return m.labelManager.HasLabel(procedure, _readOnlyLabel)
+}
+
+type resourcePoolQuotaKey struct{}
+
+// resourcePoolQuota checks the quota of the
+// resource pools for a procedure call
+type resourcePoolQuota struct {
+	limiter *keyedLimiter
+	write   bool
+	now     func() time.Time
+}
+
+// withResourcePoolQuota returns a copy of the context which carries
+// the resource pool quota for the procedure, so that handlers can
+// check the quota of the resource pool the procedure is called on
+func (m *RateLimitInboundMiddleware) withResourcePoolQuota(
+	ctx context.Context,
+	procedure string,
+) context.Context {
+	if !m.enabled || m.resourcePoolLimiter == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, resourcePoolQuotaKey{}, &resourcePoolQuota{
+		limiter: m.resourcePoolLimiter,
+		write:   !m.isReadOnly(procedure),
+		now:     m.now,
+	})
+}
+
+// HasResourcePoolQuota returns true if the context carries the
+// resource pool quota, i.e. resource pools are rate limited
+func HasResourcePoolQuota(ctx context.Context) bool {
+	_, ok := ctx.Value(resourcePoolQuotaKey{}).(*resourcePoolQuota)
+	return ok
+}
+
+// AllowResourcePool checks the quota of the resource pool for the
+// procedure call of the context, and returns a resource exhausted
+// error if the quota is reached. Calls are always allowed if the
+// context does not carry the resource pool quota.
+func AllowResourcePool(ctx context.Context, resourcePoolPath string) error {
+	quota, ok := ctx.Value(resourcePoolQuotaKey{}).(*resourcePoolQuota)
+	if !ok {
+		return nil
+	}
+
+	if allowed, _ := quota.limiter.take(
+		resourcePoolPath, quota.write, quota.now()); !allowed {
+		return yarpcerrors.ResourceExhaustedErrorf(
+			"rate limit reached for resource pool %s", resourcePoolPath)
+	}
+	return nil
+}
+
 // allow returns if a procedure can be called given the rate limit
 func (m *RateLimitInboundMiddleware) allow(procedure string) bool {
 	// if rate limit is not enabled, always allow a method call
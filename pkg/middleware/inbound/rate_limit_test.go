@@ -15,13 +15,17 @@
 package inbound
 
 import (
+	"bytes"
 	"context"
-	"github.com/golang/mock/gomock"
-	"go.uber.org/yarpc/api/transport"
-	"go.uber.org/yarpc/api/transport/transporttest"
 	"testing"
+	"time"
 
+	"github.com/uber/peloton/pkg/auth"
+
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
 	"golang.org/x/time/rate"
 )
 
@@ -196,6 +200,249 @@ func (suite *RateLimitInboundMiddlewareTestSuite) TestHandleStreamFailure() {
 	suite.Error(mw.HandleStream(ss, h))
 }
 
+// testUser is an authenticated user with a name
+type testUser struct {
+	name string
+}
+
+func (u *testUser) IsPermitted(procedure string) bool { return true }
+
+func (u *testUser) Name() string { return u.name }
+
+// testResponseWriter records the headers written to the response
+type testResponseWriter struct {
+	bytes.Buffer
+	headers transport.Headers
+}
+
+func (w *testResponseWriter) AddHeaders(h transport.Headers) {
+	for k, v := range h.Items() {
+		w.headers = w.headers.With(k, v)
+	}
+}
+
+func (w *testResponseWriter) SetApplicationError() {}
+
+// TestAllowCaller tests that each caller has its own quota for
+// read only and mutating procedures
+func (suite *RateLimitInboundMiddlewareTestSuite) TestAllowCaller() {
+	now := time.Now()
+	mw, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled: true,
+		Caller: &KeyedRateLimitConfig{
+			Read:  &TokenBucket{Rate: 1, Burst: 2},
+			Write: &TokenBucket{Rate: 1, Burst: 1},
+			Overrides: []*RateLimitOverride{
+				{Name: "peloton-apiserver", Read: &TokenBucket{Rate: -1}},
+			},
+		},
+	})
+	suite.NoError(err)
+	mw.now = func() time.Time { return now }
+
+	alice := auth.ContextWithUser(context.Background(), &testUser{name: "alice"})
+	bob := auth.ContextWithUser(context.Background(), &testUser{name: "bob"})
+	// user without name is identified by the caller
+	anonymous := auth.ContextWithUser(context.Background(), &testUser{})
+
+	read := "peloton.api.v1alpha.job.stateless.svc.JobService::QueryPods"
+	write := "peloton.api.v1alpha.job.stateless.svc.JobService::ReplaceJob"
+
+	tests := []struct {
+		ctx       context.Context
+		procedure string
+		caller    string
+		remaining int
+		allow     bool
+	}{
+		{ctx: alice, procedure: read, caller: "cli", remaining: 1, allow: true},
+		{ctx: alice, procedure: read, caller: "cli", remaining: 0, allow: true},
+		{ctx: alice, procedure: read, caller: "cli", allow: false},
+		// mutating procedures have a separate quota
+		{ctx: alice, procedure: write, caller: "cli", remaining: 0, allow: true},
+		{ctx: alice, procedure: write, caller: "cli", allow: false},
+		{ctx: bob, procedure: read, caller: "cli", remaining: 1, allow: true},
+		{ctx: anonymous, procedure: read, caller: "ci", remaining: 1, allow: true},
+		{ctx: anonymous, procedure: read, caller: "ci", remaining: 0, allow: true},
+		{ctx: anonymous, procedure: read, caller: "ci", allow: false},
+		{ctx: anonymous, procedure: read, caller: "peloton-apiserver", remaining: -1, allow: true},
+		{ctx: anonymous, procedure: read, caller: "peloton-apiserver", remaining: -1, allow: true},
+		{ctx: anonymous, procedure: write, caller: "peloton-apiserver", remaining: 0, allow: true},
+		{ctx: anonymous, procedure: write, caller: "peloton-apiserver", allow: false},
+	}
+
+	for i, test := range tests {
+		remaining, err := mw.allowCaller(test.ctx, test.procedure, test.caller)
+		if !test.allow {
+			suite.Error(err, i)
+			continue
+		}
+		suite.NoError(err, i)
+		suite.Equal(test.remaining, remaining, i)
+	}
+
+	// quota is refilled over time
+	now = now.Add(time.Second)
+	_, err = mw.allowCaller(alice, read, "cli")
+	suite.NoError(err)
+	_, err = mw.allowCaller(alice, read, "cli")
+	suite.Error(err)
+}
+
+// TestHandleRemainingHeader tests that the quota left for the caller
+// is reported in the response header
+func (suite *RateLimitInboundMiddlewareTestSuite) TestHandleRemainingHeader() {
+	mw, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled: true,
+		Caller: &KeyedRateLimitConfig{
+			Read:  &TokenBucket{Rate: 1, Burst: 3},
+			Write: &TokenBucket{Rate: 1, Burst: 3},
+		},
+	})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	resw := &testResponseWriter{}
+	suite.NoError(mw.CallerMiddleware().Handle(context.Background(), suite.r, resw, h))
+	remaining, ok := resw.headers.Get(_rateLimitRemainingHeader)
+	suite.True(ok)
+	suite.Equal("2", remaining)
+}
+
+// TestHandleNoCallerLimitBeforeAuth tests that the middleware placed
+// before auth only checks the rate limits of the methods
+func (suite *RateLimitInboundMiddlewareTestSuite) TestHandleNoCallerLimitBeforeAuth() {
+	mw, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled: true,
+		Caller: &KeyedRateLimitConfig{
+			Read:  &TokenBucket{Rate: 0, Burst: 0},
+			Write: &TokenBucket{Rate: 0, Burst: 0},
+		},
+	})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(mw.Handle(context.Background(), suite.r, nil, h))
+	suite.Error(mw.CallerMiddleware().Handle(context.Background(), suite.r, nil, h))
+}
+
+// TestHandleStreamCaller tests that the streams of each
+// user in the stream context have their own quota
+func (suite *RateLimitInboundMiddlewareTestSuite) TestHandleStreamCaller() {
+	mw, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled: true,
+		Caller: &KeyedRateLimitConfig{
+			Read: &TokenBucket{Rate: 0, Burst: 1},
+		},
+	})
+	suite.NoError(err)
+
+	procedure := "peloton.api.v1alpha.watch.svc.WatchService::Watch"
+	handleStream := func(name string) error {
+		s := transporttest.NewMockStream(suite.ctrl)
+		s.EXPECT().Request().Return(
+			&transport.StreamRequest{
+				Meta: &transport.RequestMeta{
+					Procedure: procedure,
+					Caller:    "cli",
+				}},
+		).AnyTimes()
+		s.EXPECT().Context().Return(auth.ContextWithUser(
+			context.Background(), &testUser{name: name}))
+		ss, err := transport.NewServerStream(s)
+		suite.NoError(err)
+
+		h := transporttest.NewMockStreamHandler(suite.ctrl)
+		h.EXPECT().HandleStream(gomock.Any()).Return(nil).MaxTimes(1)
+		return mw.CallerMiddleware().HandleStream(ss, h)
+	}
+
+	suite.NoError(handleStream("alice"))
+	suite.NoError(handleStream("bob"))
+	suite.Error(handleStream("alice"))
+}
+
+// TestAllowResourcePool tests the quota of resource pools
+// carried by the context passed to the handler
+func (suite *RateLimitInboundMiddlewareTestSuite) TestAllowResourcePool() {
+	mw, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled:  true,
+		ReadAPIs: []string{"testService:get*"},
+		ResourcePool: &KeyedRateLimitConfig{
+			Read: &TokenBucket{Rate: 1, Burst: 1},
+			Overrides: []*RateLimitOverride{
+				{Name: "/infra", Read: &TokenBucket{Rate: 1, Burst: 2}},
+			},
+		},
+	})
+	suite.NoError(err)
+
+	// handlers are always allowed when there is no quota in the context
+	suite.False(HasResourcePoolQuota(context.Background()))
+	suite.NoError(AllowResourcePool(context.Background(), "/infra"))
+
+	var ctx context.Context
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(c context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			ctx = c
+		}).
+		Return(nil)
+	suite.NoError(mw.CallerMiddleware().Handle(context.Background(), suite.r, nil, h))
+
+	suite.True(HasResourcePoolQuota(ctx))
+	suite.NoError(AllowResourcePool(ctx, "/infra"))
+	suite.NoError(AllowResourcePool(ctx, "/infra"))
+	suite.Error(AllowResourcePool(ctx, "/infra"))
+	suite.NoError(AllowResourcePool(ctx, "/other"))
+	suite.Error(AllowResourcePool(ctx, "/other"))
+
+	// mutating procedures are not rate limited by the config
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(c context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			ctx = c
+		}).
+		Return(nil)
+	suite.NoError(mw.CallerMiddleware().Handle(
+		context.Background(),
+		&transport.Request{Procedure: "testService::create"},
+		nil,
+		h,
+	))
+	suite.NoError(AllowResourcePool(ctx, "/infra"))
+}
+
+// TestInvalidReadAPIs tests that invalid read apis are rejected
+func (suite *RateLimitInboundMiddlewareTestSuite) TestInvalidReadAPIs() {
+	_, err := NewRateLimitInboundMiddleware(RateLimitConfig{
+		Enabled:  true,
+		ReadAPIs: []string{"Get*"},
+	})
+	suite.Error(err)
+}
+
+// TestKeyedLimiterSweep tests that full buckets are removed
+func (suite *RateLimitInboundMiddlewareTestSuite) TestKeyedLimiterSweep() {
+	now := time.Now()
+	l := newKeyedLimiter(&KeyedRateLimitConfig{
+		Read: &TokenBucket{Rate: 1, Burst: 10},
+	})
+
+	allowed, remaining := l.take("a", false, now)
+	suite.True(allowed)
+	suite.Equal(9, remaining)
+	suite.Len(l.readBuckets, 1)
+
+	now = now.Add(_bucketSweepInterval)
+	l.take("b", false, now)
+	suite.Len(l.readBuckets, 1)
+	_, ok := l.readBuckets["b"]
+	suite.True(ok)
+}
+
 func TestRateLimitInboundMiddlewareTestSuite(t *testing.T) {
 	suite.Run(t, &RateLimitInboundMiddlewareTestSuite{})
 }
@@ -0,0 +1,168 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// interval to remove the buckets which are full from keyedLimiter,
+// a full bucket behaves the same as a newly created one
+const _bucketSweepInterval = time.Minute
+
+// tokenBucket is a token bucket which reports the tokens left,
+// so callers can be told about their remaining quota
+type tokenBucket struct {
+	sync.Mutex
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(config *TokenBucket, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(config.Rate),
+		burst:  float64(config.Burst),
+		tokens: float64(config.Burst),
+		last:   now,
+	}
+}
+
+// take takes a token from the bucket if there is one,
+// and returns the number of tokens left
+func (b *tokenBucket) take(now time.Time) (bool, int) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(now)
+	if b.tokens < 1 {
+		return false, 0
+	}
+	b.tokens--
+	return true, int(b.tokens)
+}
+
+// full returns true if the bucket has all its tokens
+func (b *tokenBucket) full(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// isUnlimited returns true if the config sets no rate limit
+func isUnlimited(config *TokenBucket) bool {
+	return config == nil ||
+		config.Rate < 0 ||
+		config.Burst < 0 ||
+		config.Rate == rate.Inf
+}
+
+// keyedLimiter keeps a token bucket for each key, e.g. each caller,
+// with distinct limits for read only and mutating procedures
+type keyedLimiter struct {
+	sync.Mutex
+
+	config    *KeyedRateLimitConfig
+	overrides map[string]*RateLimitOverride
+
+	// buckets of read only procedures
+	readBuckets map[string]*tokenBucket
+	// buckets of mutating procedures
+	writeBuckets map[string]*tokenBucket
+	lastSweep    time.Time
+}
+
+func newKeyedLimiter(config *KeyedRateLimitConfig) *keyedLimiter {
+	overrides := make(map[string]*RateLimitOverride)
+	for _, override := range config.Overrides {
+		overrides[override.Name] = override
+	}
+
+	return &keyedLimiter{
+		config:       config,
+		overrides:    overrides,
+		readBuckets:  make(map[string]*tokenBucket),
+		writeBuckets: make(map[string]*tokenBucket),
+	}
+}
+
+// take takes a token from the bucket of the key, and returns the
+// number of tokens left. The number of tokens left is -1 if the key
+// is not rate limited.
+func (l *keyedLimiter) take(key string, write bool, now time.Time) (bool, int) {
+	config := l.config.Read
+	buckets := l.readBuckets
+	if write {
+		config = l.config.Write
+		buckets = l.writeBuckets
+	}
+
+	if override, ok := l.overrides[key]; ok {
+		if write && override.Write != nil {
+			config = override.Write
+		} else if !write && override.Read != nil {
+			config = override.Read
+		}
+	}
+
+	if isUnlimited(config) {
+		return true, -1
+	}
+
+	l.Lock()
+	if now.Sub(l.lastSweep) >= _bucketSweepInterval {
+		l.sweep(now)
+	}
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = newTokenBucket(config, now)
+		buckets[key] = bucket
+	}
+	l.Unlock()
+
+	return bucket.take(now)
+}
+
+// sweep removes the buckets which are full, so the buckets of keys
+// which are no longer seen do not accumulate. It expects the lock
+// to be held.
+func (l *keyedLimiter) sweep(now time.Time) {
+	for _, buckets := range []map[string]*tokenBucket{
+		l.readBuckets,
+		l.writeBuckets,
+	} {
+		for key, bucket := range buckets {
+			if bucket.full(now) {
+				delete(buckets, key)
+			}
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbound
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/auth"
+
+	"go.uber.org/yarpc/api/transport"
+)
+
+// ForwardedUserOutboundMiddleware is the outbound middleware which
+// forwards the name of the authenticated caller of the inbound call,
+// so that the called component authorizes the call for the caller.
+// It is used by the API server, which forwards calls on behalf of the
+// callers with the internal token.
+type ForwardedUserOutboundMiddleware struct{}
+
+// Call adds the forwarded user into request and invoke the underlying outbound call
+func (m *ForwardedUserOutboundMiddleware) Call(
+	ctx context.Context,
+	request *transport.Request,
+	out transport.UnaryOutbound,
+) (*transport.Response, error) {
+	request.Headers = getHeadersWithForwardedUser(ctx, request.Headers)
+	return out.Call(ctx, request)
+}
+
+// CallOneway adds the forwarded user into request and invoke the underlying outbound call
+func (m *ForwardedUserOutboundMiddleware) CallOneway(
+	ctx context.Context,
+	request *transport.Request,
+	out transport.OnewayOutbound,
+) (transport.Ack, error) {
+	request.Headers = getHeadersWithForwardedUser(ctx, request.Headers)
+	return out.CallOneway(ctx, request)
+}
+
+// CallStream adds the forwarded user into request and invoke the underlying outbound call
+func (m *ForwardedUserOutboundMiddleware) CallStream(
+	ctx context.Context,
+	request *transport.StreamRequest,
+	out transport.StreamOutbound,
+) (*transport.ClientStream, error) {
+	request.Meta.Headers = getHeadersWithForwardedUser(ctx, request.Meta.Headers)
+	return out.CallStream(ctx, request)
+}
+
+// getHeadersWithForwardedUser sets the forwarded user header to the name
+// of the user in ctx. A forwarded user sent by the caller is removed, so
+// that callers cannot impersonate other users.
+func getHeadersWithForwardedUser(
+	ctx context.Context,
+	headers transport.Headers,
+) transport.Headers {
+	headers.Del(auth.ForwardedUserHeaderKey)
+
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return headers
+	}
+	namedUser, ok := user.(auth.NamedUser)
+	if !ok {
+		return headers
+	}
+	return headers.With(auth.ForwardedUserHeaderKey, namedUser.Name())
+}
+
+// NewForwardedUserOutboundMiddleware returns ForwardedUserOutboundMiddleware
+func NewForwardedUserOutboundMiddleware() *ForwardedUserOutboundMiddleware {
+	return &ForwardedUserOutboundMiddleware{}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbound
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+)
+
+type ForwardedUserOutboundMiddlewareSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	m    *ForwardedUserOutboundMiddleware
+	ctx  context.Context
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.m = NewForwardedUserOutboundMiddleware()
+
+	user := auth_mocks.NewMockNamedUser(suite.ctrl)
+	user.EXPECT().Name().Return("alice").AnyTimes()
+	suite.ctx = auth.ContextWithUser(context.Background(), user)
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) expectForwardedUser(
+	headers transport.Headers, expected string) {
+	value, ok := headers.Get(auth.ForwardedUserHeaderKey)
+	suite.True(ok)
+	suite.Equal(expected, value)
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) TestCallSuccess() {
+	out := transporttest.NewMockUnaryOutbound(suite.ctrl)
+	out.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			suite.expectForwardedUser(request.Headers, "alice")
+		}).Return(nil, nil)
+	_, err := suite.m.Call(suite.ctx, &transport.Request{}, out)
+	suite.NoError(err)
+}
+
+// TestCallReplacesForwardedUser tests that the forwarded user
+// sent by the caller is replaced by the authenticated user
+func (suite *ForwardedUserOutboundMiddlewareSuite) TestCallReplacesForwardedUser() {
+	out := transporttest.NewMockUnaryOutbound(suite.ctrl)
+	out.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			suite.expectForwardedUser(request.Headers, "alice")
+		}).Return(nil, nil)
+	_, err := suite.m.Call(suite.ctx, &transport.Request{
+		Headers: transport.NewHeaders().
+			With(auth.ForwardedUserHeaderKey, "admin"),
+	}, out)
+	suite.NoError(err)
+}
+
+// TestCallWithoutUser tests that the forwarded user sent by the
+// caller is removed when there is no authenticated user
+func (suite *ForwardedUserOutboundMiddlewareSuite) TestCallWithoutUser() {
+	out := transporttest.NewMockUnaryOutbound(suite.ctrl)
+	out.EXPECT().
+		Call(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			_, ok := request.Headers.Get(auth.ForwardedUserHeaderKey)
+			suite.False(ok)
+		}).Return(nil, nil)
+	_, err := suite.m.Call(context.Background(), &transport.Request{
+		Headers: transport.NewHeaders().
+			With(auth.ForwardedUserHeaderKey, "admin"),
+	}, out)
+	suite.NoError(err)
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) TestCallOnewaySuccess() {
+	out := transporttest.NewMockOnewayOutbound(suite.ctrl)
+	out.EXPECT().
+		CallOneway(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.Request) {
+			suite.expectForwardedUser(request.Headers, "alice")
+		}).Return(nil, nil)
+	_, err := suite.m.CallOneway(suite.ctx, &transport.Request{}, out)
+	suite.NoError(err)
+}
+
+func (suite *ForwardedUserOutboundMiddlewareSuite) TestCallStreamSuccess() {
+	out := transporttest.NewMockStreamOutbound(suite.ctrl)
+	out.EXPECT().
+		CallStream(gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, request *transport.StreamRequest) {
+			suite.expectForwardedUser(request.Meta.Headers, "alice")
+		}).Return(nil, nil)
+	_, err := suite.m.CallStream(
+		suite.ctx,
+		&transport.StreamRequest{Meta: &transport.RequestMeta{}},
+		out,
+	)
+	suite.NoError(err)
+}
+
+func TestForwardedUserOutboundMiddlewareSuite(t *testing.T) {
+	suite.Run(t, &ForwardedUserOutboundMiddlewareSuite{})
+}
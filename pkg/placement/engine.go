@@ -41,6 +41,8 @@ const (
 	_noTasksTimeoutPenalty = 1 * time.Second
 	// error message for failed placed task
 	_failedToPlaceTaskAfterTimeout = "failed to place task after timeout"
+	// error message for tasks unplaced because of their gang
+	_failedToPlaceGang = "failed to place all tasks of the gang"
 )
 
 // Engine represents a placement engine that can be started and stopped.
@@ -317,6 +319,9 @@ func (e *engine) placeAssignmentGroup(
 		// should be retried or were unassigned.
 		assigned, retryable, unassigned := e.filterAssignments(time.Now(), assignments)
 
+		// Only place the tasks of a gang if all of them could be placed.
+		assigned, retryable, unassigned = e.filterGangs(assigned, retryable, unassigned)
+
 		// If number of hosts returned by host manager are more than number of tasks,
 		// still tasks are unassigned then it reflects potential error on
 		// affinity check at host manager.
@@ -479,6 +484,96 @@ func (e *engine) filterAssignments(
 	return assigned, retryable, unassigned
 }
 
+// filterGangs enforces all-or-nothing placement of gangs on the output of
+// filterAssignments. If any task of a gang is unassigned, the whole gang is
+// unassigned. If any task of a gang is retryable, the assigned tasks of the
+// gang are retried as well; they keep their placement so that the offers
+// stay held until the rest of the gang is placed, unless the host is used
+// by another assigned task. Tasks which are not part of a gang are left
+// untouched.
+func (e *engine) filterGangs(
+	assigned, retryable, unassigned []models.Task) (
+	[]models.Task, []models.Task, []models.Task) {
+	failedGangs := make(map[string]struct{})
+	for _, assignment := range unassigned {
+		if id := assignment.GangID(); id != "" {
+			failedGangs[id] = struct{}{}
+		}
+	}
+	pendingGangs := make(map[string]struct{})
+	for _, assignment := range retryable {
+		if id := assignment.GangID(); id != "" {
+			pendingGangs[id] = struct{}{}
+		}
+	}
+	if len(failedGangs)+len(pendingGangs) == 0 {
+		e.countPlacedGangs(assigned)
+		return assigned, retryable, unassigned
+	}
+
+	var newAssigned, newRetryable, newUnassigned, deferred []models.Task
+	newUnassigned = append(newUnassigned, unassigned...)
+	for _, assignment := range retryable {
+		if _, ok := failedGangs[assignment.GangID()]; ok {
+			assignment.SetPlacement(nil)
+			newUnassigned = append(newUnassigned, assignment)
+			continue
+		}
+		newRetryable = append(newRetryable, assignment)
+	}
+	for _, assignment := range assigned {
+		id := assignment.GangID()
+		if _, ok := failedGangs[id]; ok {
+			assignment.SetPlacement(nil)
+			newUnassigned = append(newUnassigned, assignment)
+			continue
+		}
+		if _, ok := pendingGangs[id]; ok {
+			deferred = append(deferred, assignment)
+			continue
+		}
+		newAssigned = append(newAssigned, assignment)
+	}
+
+	// Tasks held back for their gang cannot keep a host which is going to
+	// be used by the assigned tasks, since that offer is consumed by the
+	// placement.
+	usedHosts := make(map[string]struct{})
+	for _, assignment := range newAssigned {
+		usedHosts[assignment.GetPlacement().Hostname()] = struct{}{}
+	}
+	for _, assignment := range deferred {
+		if _, ok := usedHosts[assignment.GetPlacement().Hostname()]; ok {
+			assignment.SetPlacement(nil)
+		}
+		newRetryable = append(newRetryable, assignment)
+	}
+
+	for _, assignment := range newUnassigned {
+		if assignment.GetPlacementFailure() == "" {
+			assignment.SetPlacementFailure(_failedToPlaceGang)
+		}
+	}
+
+	if len(deferred) > 0 {
+		e.metrics.GangPlacementDeferred.Inc(int64(len(deferred)))
+	}
+	e.metrics.GangPlacementFail.Inc(int64(len(failedGangs)))
+	e.countPlacedGangs(newAssigned)
+	return newAssigned, newRetryable, newUnassigned
+}
+
+// countPlacedGangs updates the metric of fully placed gangs.
+func (e *engine) countPlacedGangs(assigned []models.Task) {
+	placed := make(map[string]struct{})
+	for _, assignment := range assigned {
+		if id := assignment.GangID(); id != "" {
+			placed[id] = struct{}{}
+		}
+	}
+	e.metrics.GangPlaced.Inc(int64(len(placed)))
+}
+
 // returns true if we have tried past max rounds or reached the deadline or
 // the host is already placed on the desired host.
 func (e *engine) isAssignmentGoodEnough(
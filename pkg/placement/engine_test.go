@@ -781,3 +781,66 @@ func TestEngineFindUnusedOffers(t *testing.T) {
 	assert.Equal(t, 1, len(unused))
 	assert.Equal(t, host2, unused[0])
 }
+
+func TestEngineFilterGangs(t *testing.T) {
+	ctrl, engine, _, _, _, _ := setupEngine(t)
+	defer ctrl.Finish()
+
+	deadline := time.Now().Add(30 * time.Second)
+	host := testutil.SetupHostOffers()
+	otherHost := testutil.SetupHostOffers()
+	otherHost.Offer.Hostname = "other-hostname"
+
+	t.Run("gang fully assigned", func(t *testing.T) {
+		gang := testutil.SetupGangAssignments(deadline, 1, 2)
+		single := testutil.SetupAssignment(deadline, 1)
+		assigned := []models.Task{gang[0], gang[1], single}
+
+		newAssigned, retryable, unassigned := engine.filterGangs(
+			assigned, nil, nil)
+		assert.Equal(t, assigned, newAssigned)
+		assert.Empty(t, retryable)
+		assert.Empty(t, unassigned)
+	})
+
+	t.Run("gang member unassigned fails the gang", func(t *testing.T) {
+		gang := testutil.SetupGangAssignments(deadline, 1, 3)
+		gang[0].SetPlacement(host)
+		gang[1].SetPlacement(otherHost)
+		single := testutil.SetupAssignment(deadline, 1)
+		single.SetPlacement(host)
+
+		newAssigned, retryable, unassigned := engine.filterGangs(
+			[]models.Task{gang[0], single},
+			[]models.Task{gang[1]},
+			[]models.Task{gang[2]})
+		assert.Equal(t, []models.Task{single}, newAssigned)
+		assert.Empty(t, retryable)
+		assert.Len(t, unassigned, 3)
+		for _, a := range gang {
+			assert.Nil(t, a.GetPlacement())
+			assert.Equal(t, _failedToPlaceGang, a.GetPlacementFailure())
+		}
+	})
+
+	t.Run("gang member retryable defers the gang", func(t *testing.T) {
+		gang := testutil.SetupGangAssignments(deadline, 1, 3)
+		gang[0].SetPlacement(otherHost)
+		gang[1].SetPlacement(host)
+		single := testutil.SetupAssignment(deadline, 1)
+		single.SetPlacement(host)
+
+		newAssigned, retryable, unassigned := engine.filterGangs(
+			[]models.Task{gang[0], gang[1], single},
+			[]models.Task{gang[2]},
+			nil)
+		assert.Equal(t, []models.Task{single}, newAssigned)
+		assert.Equal(t, []models.Task{gang[2], gang[0], gang[1]}, retryable)
+		assert.Empty(t, unassigned)
+
+		// the offer on the other host is held for the gang, while the
+		// offer used by the assigned task is not.
+		assert.Equal(t, otherHost, gang[0].GetPlacement())
+		assert.Nil(t, gang[1].GetPlacement())
+	})
+}
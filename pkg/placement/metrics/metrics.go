@@ -95,6 +95,21 @@ type Metrics struct {
 	// TaskAffinityFail indicates failure on host manager to return
 	// host with affinity constraint satisfied.
 	TaskAffinityFail tally.Counter
+
+	// Gang Metrics
+
+	// GangPlaced counts the number of gangs for which every task in the
+	// placement group was assigned to a host.
+	GangPlaced tally.Counter
+
+	// GangPlacementDeferred counts the number of times assigned tasks were
+	// held back because other tasks of their gang had not been placed yet.
+	GangPlacementDeferred tally.Counter
+
+	// GangPlacementFail counts the number of gangs returned to the
+	// resource manager because at least one of their tasks could not be
+	// placed before the deadline.
+	GangPlacementFail tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct with all metrics initialized and
@@ -144,5 +159,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		HostGetFail: HostFailScope.Counter("get"),
 
 		TaskAffinityFail: placementFailScope.Counter("host_limit"),
+
+		GangPlaced:            placementSuccessScope.Counter("gang"),
+		GangPlacementDeferred: placementScope.Counter("gang_deferred"),
+		GangPlacementFail:     placementFailScope.Counter("gang"),
 	}
 }
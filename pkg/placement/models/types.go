@@ -72,6 +72,11 @@ type Task interface {
 
 	// Returns the reason for the placement failure.
 	GetPlacementFailure() string
+
+	// Returns the identifier of the gang the task belongs to. All tasks
+	// of a gang must be placed together or not at all. Tasks which are
+	// scheduled singly return an empty string.
+	GangID() string
 }
 
 // ToPluginTasks transforms an array of tasks into an array of placement
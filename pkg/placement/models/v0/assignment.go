@@ -86,6 +86,17 @@ func (a *Assignment) OrchestrationID() string {
 	return a.Task.GetTask().GetTaskId().GetValue()
 }
 
+// GangID returns the identifier of the gang of the task, which is the
+// peloton task id of the first task in the gang. Returns an empty string
+// if the task is not part of a scheduling gang.
+func (a *Assignment) GangID() string {
+	gang := a.Task.GetGang()
+	if len(gang.GetTasks()) <= 1 {
+		return ""
+	}
+	return gang.GetTasks()[0].GetId().GetValue()
+}
+
 // GetPlacementFailure returns the reason why the assignment was unsuccessful
 func (a *Assignment) GetPlacementFailure() string {
 	return a.PlacementFailure
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	peloton_api_v0_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -162,4 +163,15 @@ func TestAssignment(t *testing.T) {
 		task.Task.DesiredHost = "hostname1"
 		require.False(t, assignment.IsPastDeadline(now))
 	})
+	t.Run("gang id", func(t *testing.T) {
+		_, gang, resmgrTask, _, _, assignment := setupAssignmentVariables()
+		// a gang of one task is scheduled singly
+		assert.Empty(t, assignment.GangID())
+
+		resmgrTask.Id = &peloton.TaskID{Value: "job-0"}
+		gang.Tasks = append(gang.Tasks, &resmgr.Task{
+			Id: &peloton.TaskID{Value: "job-1"},
+		})
+		assert.Equal(t, "job-0", assignment.GangID())
+	})
 }
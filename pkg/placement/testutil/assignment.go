@@ -35,3 +35,20 @@ func SetupAssignment(deadline time.Time, maxRounds int) *models_v0.Assignment {
 	task := models_v0.NewTask(resmgrGang, resmgrTask, deadline, deadline, maxRounds)
 	return models_v0.NewAssignment(task)
 }
+
+// SetupGangAssignments creates assignments for the tasks of a single gang.
+func SetupGangAssignments(
+	deadline time.Time,
+	maxRounds int,
+	size int) []*models_v0.Assignment {
+	resmgrGang := &resmgrsvc.Gang{}
+	for i := 0; i < size; i++ {
+		resmgrGang.Tasks = append(resmgrGang.Tasks, v0_testutil.SetupRMTask())
+	}
+	var assignments []*models_v0.Assignment
+	for _, resmgrTask := range resmgrGang.GetTasks() {
+		task := models_v0.NewTask(resmgrGang, resmgrTask, deadline, deadline, maxRounds)
+		assignments = append(assignments, models_v0.NewAssignment(task))
+	}
+	return assignments
+}
@@ -30,4 +30,8 @@ type PreemptionConfig struct {
 	// If the value exceeds this number then the preemption logic will kick
 	// in to reduce the allocation.
 	SustainedOverAllocationCount int `yaml:"sustained_over_allocation_count"`
+
+	// Name of the ranker used to pick the tasks to preempt from a resource
+	// pool. Defaults to STATE_PRIORITY_RUNTIME if not set.
+	Ranker string `yaml:"ranker"`
 }
@@ -20,6 +20,8 @@ import (
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/resmgr/common"
 	"github.com/uber/peloton/pkg/resmgr/task"
+
+	"github.com/pkg/errors"
 )
 
 // Config is Resource Manager specific configuration
@@ -57,4 +59,42 @@ type Config struct {
 
 	// UseHostPool is the config switch to use host pool in Resource manager
 	UseHostPool bool `yaml:"use_host_pool"`
+
+	// GangPlacementTimeout is the time the placements of a partially
+	// placed gang are held before the gang is returned for placement
+	GangPlacementTimeout time.Duration `yaml:"gang_placement_timeout"`
+
+	// HostPlacingOfferTimeout is the time the host manager holds the host
+	// offers of the placements before reclaiming them, it has to match
+	// host_placing_offer_status_sec of the host manager
+	HostPlacingOfferTimeout time.Duration `yaml:"host_placing_offer_timeout"`
+}
+
+// ValidateGangPlacementTimeout validates that the placements of a partially
+// placed gang are given up before the tasks of the gang time out of
+// PLACING, and before the host manager reclaims the host offers of the
+// placements.
+func ValidateGangPlacementTimeout(c Config) error {
+	timeout := c.GangPlacementTimeout
+	if timeout <= 0 {
+		timeout = _defaultGangPlacementTimeout
+	}
+
+	var placingTimeout time.Duration
+	if c.RmTaskConfig != nil {
+		placingTimeout = c.RmTaskConfig.PlacingTimeout
+	}
+	if timeout >= placingTimeout {
+		return errors.Errorf(
+			"gang placement timeout %v is not below the task placing timeout %v",
+			timeout,
+			placingTimeout)
+	}
+	if timeout >= c.HostPlacingOfferTimeout {
+		return errors.Errorf(
+			"gang placement timeout %v is not below the host placing offer timeout %v",
+			timeout,
+			c.HostPlacingOfferTimeout)
+	}
+	return nil
 }
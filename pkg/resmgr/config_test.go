@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/resmgr/task"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -70,3 +71,58 @@ func TestResourceManagerConfig(t *testing.T) {
 	assert.Equal(t, 5, testConfig.PreemptionConfig.SustainedOverAllocationCount)
 	assert.Equal(t, true, testConfig.PreemptionConfig.Enabled)
 }
+
+func TestValidateGangPlacementTimeout(t *testing.T) {
+	tests := []struct {
+		msg                  string
+		gangTimeout          time.Duration
+		placingTimeout       time.Duration
+		hostPlacingTimeout   time.Duration
+		expectedErrorMessage string
+	}{
+		{
+			msg:                "default gang timeout below both timeouts",
+			placingTimeout:     10 * time.Minute,
+			hostPlacingTimeout: 5 * time.Minute,
+		},
+		{
+			msg:                "gang timeout below both timeouts",
+			gangTimeout:        15 * time.Second,
+			placingTimeout:     20 * time.Second,
+			hostPlacingTimeout: 30 * time.Second,
+		},
+		{
+			msg:                  "default gang timeout above placing timeout",
+			placingTimeout:       20 * time.Second,
+			hostPlacingTimeout:   5 * time.Minute,
+			expectedErrorMessage: "gang placement timeout 2m0s is not below the task placing timeout 20s",
+		},
+		{
+			msg:                  "gang timeout equal to host placing offer timeout",
+			gangTimeout:          30 * time.Second,
+			placingTimeout:       time.Minute,
+			hostPlacingTimeout:   30 * time.Second,
+			expectedErrorMessage: "gang placement timeout 30s is not below the host placing offer timeout 30s",
+		},
+		{
+			msg:                  "host placing offer timeout not configured",
+			placingTimeout:       10 * time.Minute,
+			expectedErrorMessage: "gang placement timeout 2m0s is not below the host placing offer timeout 0s",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateGangPlacementTimeout(Config{
+			GangPlacementTimeout: test.gangTimeout,
+			RmTaskConfig: &task.Config{
+				PlacingTimeout: test.placingTimeout,
+			},
+			HostPlacingOfferTimeout: test.hostPlacingTimeout,
+		})
+		if test.expectedErrorMessage == "" {
+			assert.NoError(t, err, test.msg)
+			continue
+		}
+		assert.EqualError(t, err, test.expectedErrorMessage, test.msg)
+	}
+}
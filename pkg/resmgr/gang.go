@@ -0,0 +1,301 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// _defaultGangPlacementTimeout is the time a partially placed gang is
+// held if no timeout is configured.
+const _defaultGangPlacementTimeout = 2 * time.Minute
+
+// heldGang is a gang which has been dequeued for placement and of which
+// the placements are held until all of its tasks are placed.
+type heldGang struct {
+	// id of the gang, which is the id of its first task.
+	id string
+	// tasks of the gang.
+	tasks []*resmgr.Task
+	// placed is the set of tasks of the gang which have been placed.
+	placed map[string]struct{}
+	// placements held for the gang.
+	placements []*resmgr.Placement
+	// dequeuedAt is the time the gang was dequeued for placement.
+	dequeuedAt time.Time
+	// firstPlacedAt is the time the first task of the gang was placed.
+	firstPlacedAt time.Time
+}
+
+// complete returns true if all the tasks of the gang have been placed.
+func (g *heldGang) complete() bool {
+	return len(g.placed) == len(g.tasks)
+}
+
+// gangPlacements holds the placements of gangs which have only been
+// partially placed. The tasks of a gang can be placed in several
+// placement rounds when they have different placement needs, so the
+// placements are only released once every task of the gang is placed.
+// If that does not happen within the timeout the gang has to be placed
+// again from scratch.
+type gangPlacements struct {
+	sync.Mutex
+
+	// timeout after which a partially placed gang is given up.
+	timeout time.Duration
+	// staleTimeout after which a gang without any placement is forgotten.
+	staleTimeout time.Duration
+
+	// gangs by gang id.
+	gangs map[string]*heldGang
+	// gang ids by peloton task id.
+	members map[string]string
+	// held placements, with the ids of the incomplete gangs they wait for.
+	held map[*resmgr.Placement]map[string]struct{}
+
+	now func() time.Time
+}
+
+// newGangPlacements returns a new holder of partial gang placements.
+func newGangPlacements(
+	timeout time.Duration,
+	staleTimeout time.Duration) *gangPlacements {
+	if timeout <= 0 {
+		timeout = _defaultGangPlacementTimeout
+	}
+	if staleTimeout < timeout {
+		staleTimeout = timeout
+	}
+	return &gangPlacements{
+		timeout:      timeout,
+		staleTimeout: staleTimeout,
+		gangs:        make(map[string]*heldGang),
+		members:      make(map[string]string),
+		held:         make(map[*resmgr.Placement]map[string]struct{}),
+		now:          time.Now,
+	}
+}
+
+// track starts tracking a gang dequeued for placement. Gangs with a single
+// task are not tracked, since they are always placed as a whole.
+func (g *gangPlacements) track(gang *resmgrsvc.Gang) {
+	if g == nil || len(gang.GetTasks()) <= 1 {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	// A task dequeued again is no longer part of its previous gang. Gangs
+	// which still hold placements are left to expire.
+	for _, task := range gang.GetTasks() {
+		id, ok := g.members[task.GetId().GetValue()]
+		if ok && len(g.gangs[id].placements) == 0 {
+			g.remove(id)
+		}
+	}
+
+	hg := &heldGang{
+		id:         gang.GetTasks()[0].GetId().GetValue(),
+		tasks:      gang.GetTasks(),
+		placed:     make(map[string]struct{}),
+		dequeuedAt: g.now(),
+	}
+	g.gangs[hg.id] = hg
+	for _, task := range hg.tasks {
+		g.members[task.GetId().GetValue()] = hg.id
+	}
+}
+
+// gangOf returns the id of the tracked gang of a task, if any.
+func (g *gangPlacements) gangOf(taskID string) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	id, ok := g.members[taskID]
+	return id, ok
+}
+
+// add adds a placement and returns the placements which are ready to be
+// launched, together with the gangs which got completely placed. A
+// placement is held as long as it contains a task of an incomplete gang.
+func (g *gangPlacements) add(
+	placement *resmgr.Placement,
+) ([]*resmgr.Placement, []*heldGang) {
+	if g == nil {
+		return []*resmgr.Placement{placement}, nil
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	now := g.now()
+	waiting := make(map[string]struct{})
+	var touched []*heldGang
+	for _, task := range placement.GetTaskIDs() {
+		id, ok := g.members[task.GetPelotonTaskID().GetValue()]
+		if !ok {
+			continue
+		}
+		hg := g.gangs[id]
+		if _, ok := waiting[id]; !ok {
+			hg.placements = append(hg.placements, placement)
+			touched = append(touched, hg)
+		}
+		waiting[id] = struct{}{}
+		if hg.firstPlacedAt.IsZero() {
+			hg.firstPlacedAt = now
+		}
+		hg.placed[task.GetPelotonTaskID().GetValue()] = struct{}{}
+	}
+
+	if len(waiting) == 0 {
+		return []*resmgr.Placement{placement}, nil
+	}
+	g.held[placement] = waiting
+
+	var ready []*resmgr.Placement
+	var completed []*heldGang
+	for _, hg := range touched {
+		if !hg.complete() {
+			continue
+		}
+		completed = append(completed, hg)
+		for _, p := range hg.placements {
+			delete(g.held[p], hg.id)
+			if len(g.held[p]) == 0 {
+				delete(g.held, p)
+				ready = append(ready, p)
+			}
+		}
+		g.remove(hg.id)
+	}
+	return ready, completed
+}
+
+// abort gives up the given gangs, together with any gang sharing a held
+// placement with them. It returns the held placements which were dropped,
+// the aborted gangs, and the tasks of the dropped placements which are not
+// part of an aborted gang.
+func (g *gangPlacements) abort(ids ...string) (
+	[]*resmgr.Placement, []*heldGang, []*resmgr.Placement_Task) {
+	if g == nil {
+		return nil, nil, nil
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	aborted := make(map[string]*heldGang)
+	dropped := make(map[*resmgr.Placement]struct{})
+	var placements []*resmgr.Placement
+	var gangs []*heldGang
+
+	queue := append([]string{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		hg, ok := g.gangs[id]
+		if !ok {
+			continue
+		}
+		if _, ok := aborted[id]; ok {
+			continue
+		}
+		aborted[id] = hg
+		gangs = append(gangs, hg)
+		for _, p := range hg.placements {
+			if _, ok := dropped[p]; ok {
+				continue
+			}
+			dropped[p] = struct{}{}
+			placements = append(placements, p)
+			for other := range g.held[p] {
+				queue = append(queue, other)
+			}
+			delete(g.held, p)
+		}
+	}
+
+	abortedTasks := make(map[string]struct{})
+	for _, hg := range gangs {
+		for _, task := range hg.tasks {
+			abortedTasks[task.GetId().GetValue()] = struct{}{}
+		}
+	}
+	var others []*resmgr.Placement_Task
+	for _, p := range placements {
+		for _, task := range p.GetTaskIDs() {
+			if _, ok := abortedTasks[task.GetPelotonTaskID().GetValue()]; !ok {
+				others = append(others, task)
+			}
+		}
+	}
+
+	for id := range aborted {
+		g.remove(id)
+	}
+	return placements, gangs, others
+}
+
+// expired returns the ids of the partially placed gangs which have been
+// held for longer than the timeout. Gangs without any placement which
+// have been tracked for longer than the stale timeout are forgotten.
+func (g *gangPlacements) expired() []string {
+	if g == nil {
+		return nil
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	now := g.now()
+	var ids []string
+	for id, hg := range g.gangs {
+		if hg.firstPlacedAt.IsZero() {
+			if now.Sub(hg.dequeuedAt) > g.staleTimeout {
+				g.remove(id)
+			}
+			continue
+		}
+		if now.Sub(hg.firstPlacedAt) > g.timeout {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// remove stops tracking a gang.
+// NB: Acquire lock before calling.
+func (g *gangPlacements) remove(id string) {
+	hg, ok := g.gangs[id]
+	if !ok {
+		return
+	}
+	for _, task := range hg.tasks {
+		if g.members[task.GetId().GetValue()] == id {
+			delete(g.members, task.GetId().GetValue())
+		}
+	}
+	delete(g.gangs, id)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGang(job string, size int) *resmgrsvc.Gang {
+	gang := &resmgrsvc.Gang{}
+	for i := 0; i < size; i++ {
+		gang.Tasks = append(gang.Tasks, &resmgr.Task{
+			Id: &peloton.TaskID{Value: fmt.Sprintf("%s-%d", job, i)},
+		})
+	}
+	return gang
+}
+
+func testPlacement(host string, tasks ...*resmgr.Task) *resmgr.Placement {
+	placement := &resmgr.Placement{Hostname: host}
+	for _, task := range tasks {
+		placement.TaskIDs = append(placement.TaskIDs, &resmgr.Placement_Task{
+			PelotonTaskID: task.GetId(),
+		})
+	}
+	return placement
+}
+
+func TestGangPlacementsSingleTasks(t *testing.T) {
+	g := newGangPlacements(0, 0)
+	assert.Equal(t, _defaultGangPlacementTimeout, g.timeout)
+
+	gang := testGang("job", 1)
+	g.track(gang)
+	_, ok := g.gangOf("job-0")
+	assert.False(t, ok)
+
+	placement := testPlacement("host", gang.GetTasks()...)
+	ready, completed := g.add(placement)
+	assert.Equal(t, []*resmgr.Placement{placement}, ready)
+	assert.Empty(t, completed)
+}
+
+func TestGangPlacementsHoldUntilComplete(t *testing.T) {
+	g := newGangPlacements(time.Minute, 0)
+	gang := testGang("job", 3)
+	g.track(gang)
+
+	id, ok := g.gangOf("job-2")
+	assert.True(t, ok)
+	assert.Equal(t, "job-0", id)
+
+	single := &resmgr.Task{Id: &peloton.TaskID{Value: "other-0"}}
+	p1 := testPlacement("host1", gang.GetTasks()[0], single)
+	ready, completed := g.add(p1)
+	assert.Empty(t, ready)
+	assert.Empty(t, completed)
+
+	p2 := testPlacement("host2", gang.GetTasks()[1:]...)
+	ready, completed = g.add(p2)
+	assert.Equal(t, []*resmgr.Placement{p1, p2}, ready)
+	assert.Len(t, completed, 1)
+	assert.Equal(t, "job-0", completed[0].id)
+
+	_, ok = g.gangOf("job-0")
+	assert.False(t, ok)
+	assert.Empty(t, g.held)
+}
+
+func TestGangPlacementsAbort(t *testing.T) {
+	g := newGangPlacements(time.Minute, 0)
+	gang1 := testGang("job1", 2)
+	gang2 := testGang("job2", 2)
+	g.track(gang1)
+	g.track(gang2)
+
+	single := &resmgr.Task{Id: &peloton.TaskID{Value: "other-0"}}
+	// the placement is shared by both gangs, so aborting the first gang
+	// aborts the second one as well
+	p := testPlacement(
+		"host", gang1.GetTasks()[0], gang2.GetTasks()[0], single)
+	ready, _ := g.add(p)
+	assert.Empty(t, ready)
+
+	placements, gangs, others := g.abort("job1-0")
+	assert.Equal(t, []*resmgr.Placement{p}, placements)
+	assert.Len(t, gangs, 2)
+	assert.Len(t, others, 1)
+	assert.Equal(t, "other-0", others[0].GetPelotonTaskID().GetValue())
+
+	assert.Empty(t, g.gangs)
+	assert.Empty(t, g.members)
+	assert.Empty(t, g.held)
+
+	placements, gangs, others = g.abort("job1-0")
+	assert.Empty(t, placements)
+	assert.Empty(t, gangs)
+	assert.Empty(t, others)
+}
+
+func TestGangPlacementsExpired(t *testing.T) {
+	now := time.Now()
+	g := newGangPlacements(time.Minute, 5*time.Minute)
+	g.now = func() time.Time { return now }
+
+	placed := testGang("placed", 2)
+	unplaced := testGang("unplaced", 2)
+	g.track(placed)
+	g.track(unplaced)
+	g.add(testPlacement("host", placed.GetTasks()[0]))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, []string{"placed-0"}, g.expired())
+	_, ok := g.gangOf("unplaced-0")
+	assert.True(t, ok)
+
+	// gangs which never got placed are forgotten after the stale timeout
+	now = now.Add(5 * time.Minute)
+	assert.Equal(t, []string{"placed-0"}, g.expired())
+	_, ok = g.gangOf("unplaced-0")
+	assert.False(t, ok)
+}
+
+func TestGangPlacementsRetrack(t *testing.T) {
+	g := newGangPlacements(time.Minute, 0)
+	g.track(testGang("job", 2))
+
+	// the tasks got requeued individually and dequeued as a new gang
+	gang := &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			{Id: &peloton.TaskID{Value: "job-1"}},
+			{Id: &peloton.TaskID{Value: "new-0"}},
+		},
+	}
+	g.track(gang)
+	id, ok := g.gangOf("job-1")
+	assert.True(t, ok)
+	assert.Equal(t, "job-1", id)
+	_, ok = g.gangOf("job-0")
+	assert.False(t, ok)
+}
+
+func TestGangPlacementsNil(t *testing.T) {
+	var g *gangPlacements
+	g.track(testGang("job", 2))
+	_, ok := g.gangOf("job-0")
+	assert.False(t, ok)
+
+	placement := testPlacement("host")
+	ready, completed := g.add(placement)
+	assert.Equal(t, []*resmgr.Placement{placement}, ready)
+	assert.Empty(t, completed)
+	assert.Empty(t, g.expired())
+	placements, gangs, others := g.abort("job-0")
+	assert.Empty(t, placements)
+	assert.Empty(t, gangs)
+	assert.Empty(t, others)
+}
@@ -60,10 +60,16 @@ var (
 const (
 	_reasonPlacementReceived = "placement received"
 	_reasonDequeuedForLaunch = "placement dequeued, waiting for launch"
+	_reasonGangTimedOut      = "gang placement timed out"
+	_reasonGangFailed        = "placement of gang member failed"
 )
 
 const _eventStreamBufferSize = 1000
 
+// _releaseHostOffersTimeout is the timeout to release the host offers of
+// the placements dropped outside of a request.
+const _releaseHostOffersTimeout = 10 * time.Second
+
 // ServiceHandler implements peloton.private.resmgr.ResourceManagerService
 type ServiceHandler struct {
 	// the handler config
@@ -84,6 +90,9 @@ type ServiceHandler struct {
 	// rmtasks tracker
 	rmTracker rmtask.Tracker
 
+	// placements of partially placed gangs
+	gangs *gangPlacements
+
 	// batch host scorer
 	batchScorer hostmover.Scorer
 
@@ -105,6 +114,10 @@ func NewServiceHandler(
 	conf Config) *ServiceHandler {
 
 	var maxOffset uint64
+	var placingTimeout time.Duration
+	if conf.RmTaskConfig != nil {
+		placingTimeout = conf.RmTaskConfig.PlacingTimeout
+	}
 	handler := &ServiceHandler{
 		metrics:     NewMetrics(parent.SubScope("resmgr")),
 		resPoolTree: tree,
@@ -113,7 +126,11 @@ func NewServiceHandler(
 			reflect.TypeOf(resmgr.Placement{}),
 			maxPlacementQueueSize,
 		),
-		rmTracker:       rmTracker,
+		rmTracker: rmTracker,
+		gangs: newGangPlacements(
+			conf.GangPlacementTimeout,
+			placingTimeout,
+		),
 		batchScorer:     batchScorer,
 		preemptionQueue: preemptionQueue,
 		maxOffset:       &maxOffset,
@@ -466,6 +483,7 @@ func (h *ServiceHandler) DequeueGangs(
 			}
 		}
 		gang = h.removeFromGang(gang, tasksToRemove)
+		h.gangs.track(gang)
 		gangs = append(gangs, gang)
 	}
 	// TODO: handle the dequeue errors better
@@ -499,43 +517,68 @@ func (h *ServiceHandler) SetPlacements(
 	log.WithField("request", req).Debug("SetPlacements called.")
 	h.metrics.APISetPlacements.Inc(1)
 
+	h.releaseExpiredGangs(ctx)
+
 	var failed []*resmgrsvc.SetPlacementsFailure_FailedPlacement
 
 	// first go through all the successful placements
 	for _, placement := range req.GetPlacements() {
-		newPlacement := h.transitTasksInPlacement(
-			placement,
-			[]t.TaskState{
-				t.TaskState_PLACING,
-				t.TaskState_RESERVED,
-			},
-			t.TaskState_PLACED,
-			_reasonPlacementReceived)
-
-		h.rmTracker.SetPlacement(newPlacement)
-
-		err := h.placements.Enqueue(newPlacement)
-		if err == nil {
-			h.metrics.SetPlacementSuccess.Inc(1)
+		// placements of partially placed gangs are held until the
+		// whole gang is placed
+		ready, completed := h.gangs.add(placement)
+		h.recordPlacedGangs(completed)
+		if len(ready) == 0 {
+			h.metrics.GangPlacementHeld.Inc(1)
 			continue
 		}
 
-		// lets log the error and add the failed placement
-		log.WithField("placement", newPlacement).
-			WithError(err).
-			Error("Failed to enqueue placement")
-		failed = append(
-			failed,
-			&resmgrsvc.SetPlacementsFailure_FailedPlacement{
-				Placement: newPlacement,
-				Message:   err.Error(),
-			},
-		)
-		h.metrics.SetPlacementFail.Inc(1)
+		for _, readyPlacement := range ready {
+			newPlacement := h.transitTasksInPlacement(
+				readyPlacement,
+				[]t.TaskState{
+					t.TaskState_PLACING,
+					t.TaskState_RESERVED,
+				},
+				t.TaskState_PLACED,
+				_reasonPlacementReceived)
+
+			h.rmTracker.SetPlacement(newPlacement)
+
+			err := h.placements.Enqueue(newPlacement)
+			if err == nil {
+				h.metrics.SetPlacementSuccess.Inc(1)
+				continue
+			}
+
+			// lets log the error and add the failed placement
+			log.WithField("placement", newPlacement).
+				WithError(err).
+				Error("Failed to enqueue placement")
+			failed = append(
+				failed,
+				&resmgrsvc.SetPlacementsFailure_FailedPlacement{
+					Placement: newPlacement,
+					Message:   err.Error(),
+				},
+			)
+			h.metrics.SetPlacementFail.Inc(1)
+		}
 	}
 
 	// now we go through all the unsuccessful placements
 	for _, failedPlacement := range req.GetFailedPlacements() {
+		// a gang is placed all together or not at all, so the failure of
+		// one task returns the whole gang for placement
+		var gangIDs []string
+		for _, task := range failedPlacement.GetGang().GetTasks() {
+			if id, ok := h.gangs.gangOf(task.GetId().GetValue()); ok {
+				gangIDs = append(gangIDs, id)
+			}
+		}
+		if len(gangIDs) > 0 {
+			h.abortGangs(ctx, gangIDs, _reasonGangFailed)
+		}
+
 		err := h.returnFailedPlacement(
 			failedPlacement.GetGang(),
 			failedPlacement.GetReason(),
@@ -587,6 +630,124 @@ func (h *ServiceHandler) returnFailedPlacement(
 	return errs.ErrorOrNil()
 }
 
+// recordPlacedGangs records the wait time of gangs which got placed.
+func (h *ServiceHandler) recordPlacedGangs(gangs []*heldGang) {
+	if len(gangs) == 0 {
+		return
+	}
+	now := h.gangs.now()
+	for _, hg := range gangs {
+		h.metrics.GangPlacementReleased.Inc(1)
+		h.metrics.GangWaitTime.Record(now.Sub(hg.dequeuedAt))
+	}
+}
+
+// releaseExpiredGangs gives up the gangs which have been partially placed
+// for too long, and returns them for placement.
+func (h *ServiceHandler) releaseExpiredGangs(ctx context.Context) {
+	ids := h.gangs.expired()
+	if len(ids) == 0 {
+		return
+	}
+	log.WithField("gangs", ids).Info("Gang placements timed out")
+	h.metrics.GangPlacementTimeout.Inc(int64(len(ids)))
+	h.abortGangs(ctx, ids, _reasonGangTimedOut)
+}
+
+// abortGangs drops the held placements of the gangs, releases their host
+// offers and returns the tasks of the dropped placements for placement.
+func (h *ServiceHandler) abortGangs(
+	ctx context.Context,
+	ids []string,
+	reason string) {
+	placements, gangs, others := h.gangs.abort(ids...)
+
+	h.releaseHostOffers(ctx, placements)
+
+	for _, hg := range gangs {
+		if err := h.requeueGang(hg.tasks, reason); err != nil {
+			log.WithField("gang", hg.id).
+				WithError(err).
+				Error("Failed to requeue gang")
+		}
+	}
+
+	for _, task := range others {
+		rmTask := h.rmTracker.GetTask(task.GetPelotonTaskID())
+		if rmTask == nil {
+			continue
+		}
+		if err := rmTask.RequeueUnPlaced(reason); err != nil {
+			log.WithField("task_id", task.GetPelotonTaskID().GetValue()).
+				WithError(err).
+				Error("Failed to requeue task of dropped placement")
+		}
+	}
+}
+
+// requeueGang returns the tasks of a gang still being placed for placement
+// as a single gang.
+func (h *ServiceHandler) requeueGang(
+	tasks []*resmgr.Task,
+	reason string) error {
+	var rmTasks []*rmtask.RMTask
+	for _, task := range tasks {
+		// the task could have been deleted
+		if rmTask := h.rmTracker.GetTask(task.GetId()); rmTask != nil {
+			rmTasks = append(rmTasks, rmTask)
+		}
+	}
+	if len(rmTasks) == 0 {
+		return nil
+	}
+	return rmtask.RequeueUnPlacedGang(rmTasks, reason)
+}
+
+// RequeueGangOf implements rmtask.GangRequeuer. It gives up the gang of a
+// task being placed, and returns the gang for placement.
+func (h *ServiceHandler) RequeueGangOf(
+	taskID *peloton.TaskID,
+	reason string) bool {
+	id, ok := h.gangs.gangOf(taskID.GetValue())
+	if !ok {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		_releaseHostOffersTimeout)
+	defer cancel()
+	h.abortGangs(ctx, []string{id}, reason)
+	return true
+}
+
+// releaseHostOffers returns the host offers of dropped placements to the
+// host manager.
+func (h *ServiceHandler) releaseHostOffers(
+	ctx context.Context,
+	placements []*resmgr.Placement) {
+	if len(placements) == 0 {
+		return
+	}
+	var hostOffers []*hostsvc.HostOffer
+	for _, placement := range placements {
+		hostOffers = append(hostOffers, &hostsvc.HostOffer{
+			Hostname: placement.GetHostname(),
+			AgentId:  placement.GetAgentId(),
+			Id:       placement.GetHostOfferID(),
+		})
+	}
+	_, err := h.hostmgrClient.ReleaseHostOffers(
+		ctx,
+		&hostsvc.ReleaseHostOffersRequest{HostOffers: hostOffers})
+	if err != nil {
+		// the host manager returns the offers on its own once the
+		// placing host offers time out
+		log.WithError(err).
+			WithField("placements", placements).
+			Warn("Failed to release host offers of dropped placements")
+	}
+}
+
 // GetTasksByHosts returns all tasks of the given task type running on the given list of hosts.
 func (h *ServiceHandler) GetTasksByHosts(ctx context.Context,
 	req *resmgrsvc.GetTasksByHostsRequest) (*resmgrsvc.GetTasksByHostsResponse, error) {
@@ -639,6 +800,8 @@ func (h *ServiceHandler) GetPlacements(
 	log.WithField("request", req).Debug("GetPlacements called.")
 	h.metrics.APIGetPlacements.Inc(1)
 
+	h.releaseExpiredGangs(ctx)
+
 	limit := req.GetLimit()
 	timeout := time.Duration(req.GetTimeout())
 
@@ -1198,6 +1361,30 @@ func (h *ServiceHandler) GetHostsByScores(
 
 }
 
+// GetSLAViolations returns the state transition SLA violations of the
+// active tasks, if jobID or respoolID is provided only the violations of
+// the tasks of that job or respool are returned
+func (h *ServiceHandler) GetSLAViolations(
+	ctx context.Context,
+	req *resmgrsvc.GetSLAViolationsRequest,
+) (*resmgrsvc.GetSLAViolationsResponse, error) {
+	var violations []*resmgr.SLAViolation
+	taskStateMap := h.rmTracker.GetActiveTasks(
+		req.GetJobID(),
+		req.GetRespoolID(),
+		nil,
+	)
+	for _, tasks := range taskStateMap {
+		for _, task := range tasks {
+			violations = append(violations, task.SLAViolations()...)
+		}
+	}
+
+	return &resmgrsvc.GetSLAViolationsResponse{
+		Violations: violations,
+	}, nil
+}
+
 // NewTestServiceHandler returns an empty new ServiceHandler ptr for testing.
 func NewTestServiceHandler() *ServiceHandler {
 	return &ServiceHandler{}
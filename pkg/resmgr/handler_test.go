@@ -575,6 +575,201 @@ func (s *handlerTestSuite) TestSetAndGetPlacementsSuccess() {
 	s.Equal(placements, getResp.GetPlacements())
 }
 
+// gangPlacementsHandler returns a handler tracking a gang made of all the
+// tasks of the given placements, which are in PLACING state.
+func (s *handlerTestSuite) gangPlacementsHandler(
+	placements []*resmgr.Placement) *ServiceHandler {
+	handler := &ServiceHandler{
+		metrics:     NewMetrics(tally.NoopScope),
+		resPoolTree: nil,
+		placements: queue.NewQueue(
+			"placement-queue",
+			reflect.TypeOf(resmgr.Placement{}),
+			maxPlacementQueueSize,
+		),
+		rmTracker:     s.rmTaskTracker,
+		gangs:         newGangPlacements(time.Minute, 0),
+		hostmgrClient: s.mockHostmgrClient,
+	}
+	handler.eventStreamHandler = s.handler.eventStreamHandler
+
+	gang := &resmgrsvc.Gang{}
+	for _, placement := range placements {
+		for _, t := range placement.GetTaskIDs() {
+			rmTask := handler.rmTracker.GetTask(t.GetPelotonTaskID())
+			tasktestutil.ValidateStateTransitions(rmTask, []task.TaskState{
+				task.TaskState_PENDING,
+				task.TaskState_READY,
+				task.TaskState_PLACING})
+			gang.Tasks = append(gang.Tasks, rmTask.Task())
+		}
+	}
+	handler.gangs.track(gang)
+	return handler
+}
+
+// TestSetPlacementsHoldsPartialGang tests that the placements of a gang are
+// only released once all the tasks of the gang are placed.
+func (s *handlerTestSuite) TestSetPlacementsHoldsPartialGang() {
+	placements := s.getPlacements(2, 2)
+	handler := s.gangPlacementsHandler(placements)
+
+	getReq := &resmgrsvc.GetPlacementsRequest{
+		Limit:   10,
+		Timeout: 100,
+	}
+
+	setResp, err := handler.SetPlacements(
+		s.context,
+		&resmgrsvc.SetPlacementsRequest{
+			Placements: placements[:1],
+		})
+	s.NoError(err)
+	s.Nil(setResp.GetError())
+
+	getResp, err := handler.GetPlacements(s.context, getReq)
+	s.NoError(err)
+	s.Empty(getResp.GetPlacements())
+	for _, t := range placements[0].GetTaskIDs() {
+		s.Equal(
+			task.TaskState_PLACING,
+			s.rmTaskTracker.GetTask(t.GetPelotonTaskID()).GetCurrentState().State)
+	}
+
+	setResp, err = handler.SetPlacements(
+		s.context,
+		&resmgrsvc.SetPlacementsRequest{
+			Placements: placements[1:],
+		})
+	s.NoError(err)
+	s.Nil(setResp.GetError())
+
+	getResp, err = handler.GetPlacements(s.context, getReq)
+	s.NoError(err)
+	s.Equal(placements, getResp.GetPlacements())
+}
+
+// TestGangPlacementTimeout tests that the held placements of a partially
+// placed gang are dropped on timeout, and the gang is placed again.
+func (s *handlerTestSuite) TestGangPlacementTimeout() {
+	placements := s.getPlacements(2, 2)
+	handler := s.gangPlacementsHandler(placements)
+
+	setResp, err := handler.SetPlacements(
+		s.context,
+		&resmgrsvc.SetPlacementsRequest{
+			Placements: placements[:1],
+		})
+	s.NoError(err)
+	s.Nil(setResp.GetError())
+
+	s.mockHostmgrClient.EXPECT().
+		ReleaseHostOffers(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *hostsvc.ReleaseHostOffersRequest) {
+			s.Len(req.GetHostOffers(), 1)
+			s.Equal(placements[0].GetHostname(),
+				req.GetHostOffers()[0].GetHostname())
+		}).
+		Return(&hostsvc.ReleaseHostOffersResponse{}, nil)
+
+	now := time.Now().Add(2 * time.Minute)
+	handler.gangs.now = func() time.Time { return now }
+
+	getResp, err := handler.GetPlacements(
+		s.context,
+		&resmgrsvc.GetPlacementsRequest{
+			Limit:   10,
+			Timeout: 100,
+		})
+	s.NoError(err)
+	s.Empty(getResp.GetPlacements())
+
+	// all tasks of the gang are back in the ready queue
+	for _, placement := range placements {
+		for _, t := range placement.GetTaskIDs() {
+			s.Equal(
+				task.TaskState_READY,
+				s.rmTaskTracker.GetTask(t.GetPelotonTaskID()).GetCurrentState().State)
+		}
+	}
+	_, ok := handler.gangs.gangOf(
+		placements[0].GetTaskIDs()[0].GetPelotonTaskID().GetValue())
+	s.False(ok)
+}
+
+// TestRequeueGangOf tests that requeueing a member of a partially placed
+// gang drops the held placements, and returns the whole gang for placement.
+func (s *handlerTestSuite) TestRequeueGangOf() {
+	placements := s.getPlacements(2, 2)
+	handler := s.gangPlacementsHandler(placements)
+
+	setResp, err := handler.SetPlacements(
+		s.context,
+		&resmgrsvc.SetPlacementsRequest{
+			Placements: placements[:1],
+		})
+	s.NoError(err)
+	s.Nil(setResp.GetError())
+
+	s.mockHostmgrClient.EXPECT().
+		ReleaseHostOffers(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.ReleaseHostOffersResponse{}, nil)
+
+	unplaced := placements[1].GetTaskIDs()[0].GetPelotonTaskID()
+	s.True(handler.RequeueGangOf(unplaced, "sla violated"))
+
+	for _, placement := range placements {
+		for _, t := range placement.GetTaskIDs() {
+			s.Equal(
+				task.TaskState_READY,
+				s.rmTaskTracker.GetTask(t.GetPelotonTaskID()).GetCurrentState().State)
+		}
+	}
+	_, ok := handler.gangs.gangOf(unplaced.GetValue())
+	s.False(ok)
+
+	// the task is no longer part of a gang being placed
+	s.False(handler.RequeueGangOf(unplaced, "sla violated"))
+}
+
+// TestSetFailedPlacementOfGangMember tests that the failed placement of a
+// gang member returns the whole gang for placement.
+func (s *handlerTestSuite) TestSetFailedPlacementOfGangMember() {
+	placements := s.getPlacements(2, 2)
+	handler := s.gangPlacementsHandler(placements)
+
+	failedTask := s.rmTaskTracker.GetTask(
+		placements[1].GetTaskIDs()[0].GetPelotonTaskID()).Task()
+
+	s.mockHostmgrClient.EXPECT().
+		ReleaseHostOffers(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.ReleaseHostOffersResponse{}, nil)
+
+	setResp, err := handler.SetPlacements(
+		s.context,
+		&resmgrsvc.SetPlacementsRequest{
+			Placements: placements[:1],
+			FailedPlacements: []*resmgrsvc.SetPlacementsRequest_FailedPlacement{
+				{
+					Gang: &resmgrsvc.Gang{
+						Tasks: []*resmgr.Task{failedTask},
+					},
+					Reason: "no hosts",
+				},
+			},
+		})
+	s.NoError(err)
+	s.Nil(setResp.GetError())
+
+	for _, placement := range placements {
+		for _, t := range placement.GetTaskIDs() {
+			s.Equal(
+				task.TaskState_READY,
+				s.rmTaskTracker.GetTask(t.GetPelotonTaskID()).GetCurrentState().State)
+		}
+	}
+}
+
 // TestSetPlacementsRunIDDifferentFromTracker tests the failure case of
 // writing placements due to mesos task id of the task in placement being
 // different from that in the tracker
@@ -2094,3 +2289,69 @@ func getPlacementTasks(rmTasks []*resmgr.Task) []*resmgr.Placement_Task {
 	}
 	return placementTasks
 }
+
+func (s *handlerTestSuite) TestGetSLAViolations() {
+	tracker := task_mocks.NewMockTracker(s.ctrl)
+	s.handler.rmTracker = tracker
+	defer func() {
+		s.handler.rmTracker = s.rmTaskTracker
+	}()
+
+	resp, err := respool.NewRespool(tally.NoopScope, "respool-1", nil,
+		&pb_respool.ResourcePoolConfig{
+			Name:      "respool-1",
+			Resources: s.getResourceConfig(),
+			Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		},
+		s.cfg)
+	s.NoError(err)
+
+	jobID := uuid.NewUUID().String()
+	rmTask, err := rm_task.CreateRMTask(
+		tally.NoopScope,
+		&resmgr.Task{
+			Id:    &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, 0)},
+			JobId: &peloton.JobID{Value: jobID},
+		},
+		nil,
+		resp,
+		&rm_task.Config{
+			LaunchingTimeout:  1 * time.Minute,
+			PlacingTimeout:    1 * time.Minute,
+			PolicyName:        rm_task.ExponentialBackOffPolicy,
+			EnableSLATracking: true,
+			SLARules: []*rm_task.SLARuleConfig{
+				{
+					From: task.TaskState_READY.String(),
+					To:   task.TaskState_PLACED.String(),
+					SLA:  time.Nanosecond,
+				},
+			},
+		},
+	)
+	s.NoError(err)
+	tasktestutil.ValidateStateTransitions(rmTask, []task.TaskState{
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+	})
+
+	tracker.EXPECT().GetActiveTasks(jobID, "", nil).Return(
+		map[string][]*rm_task.RMTask{
+			task.TaskState_PLACED.String(): {rmTask},
+		})
+
+	res, err := s.handler.GetSLAViolations(
+		s.context,
+		&resmgrsvc.GetSLAViolationsRequest{JobID: jobID},
+	)
+	s.NoError(err)
+	s.Len(res.GetViolations(), 1)
+	violation := res.GetViolations()[0]
+	s.Equal(rmTask.Task().GetId().GetValue(), violation.GetId().GetValue())
+	s.Equal(jobID, violation.GetJobId().GetValue())
+	s.Equal(task.TaskState_READY, violation.GetFromState())
+	s.Equal(task.TaskState_PLACED, violation.GetToState())
+	s.False(violation.GetInProgress())
+}
@@ -51,6 +51,11 @@ type Metrics struct {
 	PlacementQueueLen tally.Gauge
 	PlacementFailed   tally.Counter
 
+	GangPlacementHeld     tally.Counter
+	GangPlacementReleased tally.Counter
+	GangPlacementTimeout  tally.Counter
+	GangWaitTime          tally.Timer
+
 	Elected tally.Gauge
 }
 
@@ -98,6 +103,11 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		PlacementQueueLen: placement.Gauge("placement_queue_length"),
 		PlacementFailed:   placement.Counter("fail"),
 
+		GangPlacementHeld:     placement.Counter("gang_held"),
+		GangPlacementReleased: placement.Counter("gang_released"),
+		GangPlacementTimeout:  placement.Counter("gang_timeout"),
+		GangWaitTime:          placement.Timer("gang_wait_time"),
+
 		Elected: serverScope.Gauge("elected"),
 	}
 }
@@ -0,0 +1,236 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preemption
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+	rm_task "github.com/uber/peloton/pkg/resmgr/task"
+)
+
+// costOfEvictionRanker ranks the tasks to minimize the work lost by
+// preempting them. It sorts the tasks in the following order
+// * Task State : READY > PLACING > RUNNING
+// * If task state is the same it sorts on the task Priority
+// * If the priority is the same it sorts on the task runtime(shortest first)
+// * If the runtime is the same it sorts on the task failure count(fewest first)
+// Running tasks of stateless jobs whose eviction would take the job below
+// its minimum running instances or above its maximum unavailable instances
+// are ranked after all the other tasks.
+type costOfEvictionRanker struct {
+	tracker rm_task.Tracker
+	sorter  taskSorter
+}
+
+// newCostOfEvictionRanker returns a new instance of the costOfEvictionRanker
+func newCostOfEvictionRanker(tracker rm_task.Tracker) ranker {
+	return &costOfEvictionRanker{
+		tracker: tracker,
+		sorter: taskSorter{
+			cmpFuncs: []cmpFunc{
+				priorityCmp,
+				runtimeCmp,
+				failureCountCmp,
+			},
+		},
+	}
+}
+
+// GetTasksToEvict returns the tasks in the order in which they should be evicted from
+// the resource pool such that the cumulative resources of those tasks >= requiredResources
+func (r *costOfEvictionRanker) GetTasksToEvict(
+	respoolID string,
+	slackResourcesToFree, nonSlackResourcesToFree *scalar.Resources) []*rm_task.RMTask {
+
+	// get all active tasks for this resource pool
+	stateTaskMap := r.tracker.GetActiveTasks("", respoolID, nil)
+	budget := newAvailabilityBudget(stateTaskMap[task.TaskState_RUNNING.String()])
+
+	// get revocable tasks to preempt and filter on slack resources to free
+	revocableTasksToEvict := r.selectTasks(
+		slackResourcesToFree,
+		r.rankTasks(stateTaskMap, filterRevocableTasks),
+		budget)
+
+	// get non-revocable preemptible tasks to preempt and filter on
+	// non-slack resources to free
+	nonRevocTasksToEvict := r.selectTasks(
+		nonSlackResourcesToFree,
+		r.rankTasks(stateTaskMap, filterNonRevocableTasks),
+		budget)
+	return append(revocableTasksToEvict, nonRevocTasksToEvict...)
+}
+
+// rankTasks returns a ranked list of the tasks which pass the filter
+func (r *costOfEvictionRanker) rankTasks(
+	stateTaskMap map[string][]*rm_task.RMTask,
+	filter func([]*rm_task.RMTask) []*rm_task.RMTask) []*rm_task.RMTask {
+	var allTasks []*rm_task.RMTask
+	for _, taskState := range taskStatesPreemptionOrder {
+		tasksInState := filter(stateTaskMap[taskState.String()])
+		r.sorter.Sort(tasksInState)
+		allTasks = append(allTasks, tasksInState...)
+	}
+	return allTasks
+}
+
+// selectTasks moves the tasks which would violate the availability of their
+// job to the end of the ranked list, filters the tasks which satisfy the
+// resourcesToFree and consumes the budget for the selected tasks.
+func (r *costOfEvictionRanker) selectTasks(
+	resourcesToFree *scalar.Resources,
+	rankedTasks []*rm_task.RMTask,
+	budget availabilityBudget) []*rm_task.RMTask {
+	var tasks, deferredTasks []*rm_task.RMTask
+
+	tentativeBudget := budget.copy()
+	for _, t := range rankedTasks {
+		if !tentativeBudget.allows(t) {
+			deferredTasks = append(deferredTasks, t)
+			continue
+		}
+		tentativeBudget.consume(t)
+		tasks = append(tasks, t)
+	}
+
+	tasksToEvict := filterTasks(resourcesToFree, append(tasks, deferredTasks...))
+	for _, t := range tasksToEvict {
+		budget.consume(t)
+	}
+	return tasksToEvict
+}
+
+// jobAvailability tracks the running instances of a stateless job
+// against its availability SLA
+type jobAvailability struct {
+	instanceCount      uint32
+	running            uint32
+	minimumRunning     uint32
+	maximumUnavailable uint32
+}
+
+// canEvict returns true if one more running instance of the job can be
+// evicted without violating its availability SLA
+func (a jobAvailability) canEvict() bool {
+	if a.minimumRunning > 0 && a.running <= a.minimumRunning {
+		return false
+	}
+
+	var unavailable uint32
+	if a.instanceCount > a.running {
+		unavailable = a.instanceCount - a.running
+	}
+	if a.maximumUnavailable > 0 && unavailable >= a.maximumUnavailable {
+		return false
+	}
+	return true
+}
+
+// availabilityBudget is the map of job id to the availability of the
+// stateless jobs which have running tasks in the resource pool
+type availabilityBudget map[string]jobAvailability
+
+// newAvailabilityBudget creates the budget from the running tasks
+func newAvailabilityBudget(runningTasks []*rm_task.RMTask) availabilityBudget {
+	budget := make(availabilityBudget)
+	// orphan tasks share the peloton task id with the task which
+	// replaced them, so count every peloton task only once
+	seen := make(map[string]bool)
+	for _, t := range runningTasks {
+		if t.Task().GetType() != resmgr.TaskType_STATELESS {
+			continue
+		}
+		taskID := t.Task().GetId().GetValue()
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+
+		jobID := t.Task().GetJobId().GetValue()
+		availability := budget[jobID]
+		availability.running++
+		availability.instanceCount = t.Task().GetJobInstanceCount()
+		availability.minimumRunning = t.Task().GetJobMinimumRunningInstances()
+		availability.maximumUnavailable = t.Task().GetJobMaximumUnavailableInstances()
+		budget[jobID] = availability
+	}
+	return budget
+}
+
+// allows returns true if evicting the task does not violate the
+// availability SLA of its job
+func (b availabilityBudget) allows(t *rm_task.RMTask) bool {
+	if t.GetCurrentState().State != task.TaskState_RUNNING {
+		// the task is already unavailable
+		return true
+	}
+	availability, ok := b[t.Task().GetJobId().GetValue()]
+	if !ok {
+		return true
+	}
+	return availability.canEvict()
+}
+
+// consume records the eviction of the task
+func (b availabilityBudget) consume(t *rm_task.RMTask) {
+	if t.GetCurrentState().State != task.TaskState_RUNNING {
+		return
+	}
+	jobID := t.Task().GetJobId().GetValue()
+	availability, ok := b[jobID]
+	if !ok || availability.running == 0 {
+		return
+	}
+	availability.running--
+	b[jobID] = availability
+}
+
+// copy returns a copy of the budget
+func (b availabilityBudget) copy() availabilityBudget {
+	c := make(availabilityBudget, len(b))
+	for jobID, availability := range b {
+		c[jobID] = availability
+	}
+	return c
+}
+
+// runtimeCmp compares running tasks based on their start time, the task
+// which has been running for the shortest time is evicted first
+func runtimeCmp(t1, t2 *rm_task.RMTask) int {
+	if t1.GetCurrentState().State != task.TaskState_RUNNING ||
+		t2.GetCurrentState().State != task.TaskState_RUNNING {
+		// only running tasks have a start time
+		return 0
+	}
+
+	t1StartTime := t1.RunTimeStats().StartTime
+	t2StartTime := t2.RunTimeStats().StartTime
+
+	switch {
+	case t1StartTime.After(t2StartTime):
+		return -1
+	case t1StartTime.Before(t2StartTime):
+		return 1
+	}
+	return 0
+}
+
+// failureCountCmp compares tasks based on the number of times they
+// have failed
+func failureCountCmp(t1, t2 *rm_task.RMTask) int {
+	return int(t1.Task().GetFailureCount()) - int(t2.Task().GetFailureCount())
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preemption
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+// adds a running task of the job to the tracker, which was started
+// runningFor ago
+func (suite *RankerTestSuite) addRunningTask(
+	jobID string,
+	instance int,
+	runningFor time.Duration,
+	update func(t *resmgr.Task)) {
+	t := suite.createTask(instance, 0)
+	t.JobId = &peloton.JobID{Value: jobID}
+	t.Id = &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, instance)}
+	t.Name = t.Id.Value
+	if update != nil {
+		update(t)
+	}
+	suite.addTaskToTracker(t)
+	suite.transitToRunning(t.Id)
+	suite.tracker.GetTask(t.Id).RunTimeStats().StartTime =
+		time.Now().Add(-runningFor)
+}
+
+func (suite *RankerTestSuite) TestNewRanker() {
+	suite.NoError(ValidateRanker(""))
+	suite.NoError(ValidateRanker(StatePriorityRuntime))
+	suite.NoError(ValidateRanker(CostOfEviction))
+	suite.Error(ValidateRanker("UNKNOWN"))
+
+	suite.IsType(&statePriorityRuntimeRanker{}, newRanker("", suite.tracker))
+	suite.IsType(
+		&statePriorityRuntimeRanker{},
+		newRanker("UNKNOWN", suite.tracker))
+	suite.IsType(
+		&costOfEvictionRanker{},
+		newRanker(CostOfEviction, suite.tracker))
+}
+
+func (suite *RankerTestSuite) TestCostOfEvictionRanker_GetTasksToEvict() {
+	suite.addRunningTask("job1", 0, time.Hour, nil)
+	suite.addRunningTask("job1", 1, time.Minute, func(t *resmgr.Task) {
+		t.FailureCount = 2
+	})
+	suite.addRunningTask("job1", 2, time.Minute, nil)
+	// make job1-1 and job1-2 have the same start time
+	suite.tracker.GetTask(&peloton.TaskID{Value: "job1-1"}).
+		RunTimeStats().StartTime = suite.tracker.
+		GetTask(&peloton.TaskID{Value: "job1-2"}).RunTimeStats().StartTime
+
+	suite.addTaskToTracker(suite.createTask(3, 0))
+	suite.transitToReady(&peloton.TaskID{Value: "job1-3"})
+
+	ranker := newCostOfEvictionRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    10,
+			MEMORY: 1000,
+			GPU:    0,
+			DISK:   100,
+		})
+
+	expectedTasks := []string{
+		// READY tasks don't lose any work
+		"job1-3",
+		// RUNNING task with the shortest runtime and fewest failures
+		"job1-2",
+		"job1-1",
+		// RUNNING task with the longest runtime
+		"job1-0",
+	}
+	suite.Equal(len(expectedTasks), len(tasksToEvict))
+	for i, taskToEvict := range tasksToEvict {
+		suite.Equal(expectedTasks[i], taskToEvict.Task().GetId().Value)
+	}
+}
+
+func (suite *RankerTestSuite) TestCostOfEvictionRanker_JobAvailability() {
+	tt := []struct {
+		msg                string
+		minimumRunning     uint32
+		maximumUnavailable uint32
+	}{
+		{
+			msg:                "maximum unavailable instances",
+			maximumUnavailable: 1,
+		},
+		{
+			msg:            "minimum running instances",
+			minimumRunning: 2,
+		},
+	}
+
+	for _, test := range tt {
+		// stateless job with 3 running instances, which have been
+		// running for less time than the batch job
+		for i := 0; i < 3; i++ {
+			suite.addRunningTask(
+				"job2",
+				i,
+				time.Duration(i+1)*time.Minute,
+				func(t *resmgr.Task) {
+					t.Type = resmgr.TaskType_STATELESS
+					t.JobInstanceCount = 3
+					t.JobMinimumRunningInstances = test.minimumRunning
+					t.JobMaximumUnavailableInstances = test.maximumUnavailable
+				})
+		}
+		suite.addRunningTask("job3", 0, time.Hour, nil)
+
+		ranker := newCostOfEvictionRanker(suite.tracker)
+		tasksToEvict := ranker.GetTasksToEvict(
+			"respool-1",
+			scalar.ZeroResource,
+			&scalar.Resources{
+				CPU:    10,
+				MEMORY: 1000,
+				GPU:    0,
+				DISK:   100,
+			})
+
+		expectedTasks := []string{
+			// only one instance of the stateless job can be evicted
+			// without violating its availability
+			"job2-0",
+			"job3-0",
+			"job2-1",
+			"job2-2",
+		}
+		suite.Equal(len(expectedTasks), len(tasksToEvict), test.msg)
+		for i, taskToEvict := range tasksToEvict {
+			suite.Equal(
+				expectedTasks[i],
+				taskToEvict.Task().GetId().Value,
+				test.msg)
+		}
+		suite.tracker.Clear()
+	}
+}
+
+func (suite *RankerTestSuite) TestCostOfEvictionRanker_BudgetSharedAcrossKinds() {
+	// one revocable and one non-revocable instance of a stateless job
+	// which can have only one unavailable instance
+	for i := 0; i < 2; i++ {
+		revocable := i == 0
+		suite.addRunningTask("job2", i, time.Minute, func(t *resmgr.Task) {
+			t.Type = resmgr.TaskType_STATELESS
+			t.Revocable = revocable
+			t.JobInstanceCount = 2
+			t.JobMaximumUnavailableInstances = 1
+		})
+	}
+	suite.addRunningTask("job3", 0, time.Hour, nil)
+
+	ranker := newCostOfEvictionRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		&scalar.Resources{
+			CPU:    1,
+			MEMORY: 100,
+			DISK:   9,
+		},
+		&scalar.Resources{
+			CPU:    1,
+			MEMORY: 100,
+			DISK:   9,
+		})
+
+	// the revocable instance uses up the budget of the job, so the
+	// batch task is evicted to free non-slack resources
+	suite.Equal(2, len(tasksToEvict))
+	suite.Equal("job2-0", tasksToEvict[0].Task().GetId().Value)
+	suite.Equal("job3-0", tasksToEvict[1].Task().GetId().Value)
+}
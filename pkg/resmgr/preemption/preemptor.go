@@ -113,7 +113,7 @@ func NewPreemptor(
 			reflect.TypeOf(resmgr.PreemptionCandidate{}),
 			maxPreemptionQueueSize,
 		),
-		ranker:  newStatePriorityRuntimeRanker(tracker),
+		ranker:  newRanker(cfg.Ranker, tracker),
 		tracker: tracker,
 		scope:   parent.SubScope("preemption"),
 		m:       make(map[string]*Metrics),
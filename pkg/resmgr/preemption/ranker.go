@@ -15,6 +15,7 @@
 package preemption
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -25,6 +26,50 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// StatePriorityRuntime is the name of the ranker which ranks tasks by
+	// state, priority and runtime
+	StatePriorityRuntime = "STATE_PRIORITY_RUNTIME"
+
+	// CostOfEviction is the name of the ranker which ranks tasks by the
+	// work lost on eviction and the availability of their jobs
+	CostOfEviction = "COST_OF_EVICTION"
+)
+
+// map of ranker name to the function creating the ranker. Not thread-safe ->
+// should be updated at initialization only; only reads are safe after
+// initialization.
+var rankers = map[string]func(tracker rm_task.Tracker) ranker{
+	StatePriorityRuntime: newStatePriorityRuntimeRanker,
+	CostOfEviction:       newCostOfEvictionRanker,
+}
+
+// ValidateRanker returns an error if there is no ranker registered with
+// the specified name. An empty name selects the default ranker.
+func ValidateRanker(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := rankers[name]; !ok {
+		return fmt.Errorf("preemption ranker %s not found", name)
+	}
+	return nil
+}
+
+// newRanker creates the ranker with the specified name, falling back
+// to the StatePriorityRuntime ranker if the name is not registered.
+func newRanker(name string, tracker rm_task.Tracker) ranker {
+	rankerFunc, ok := rankers[name]
+	if !ok {
+		if name != "" {
+			log.WithField("ranker_name", name).
+				Error("preemption ranker not found, using default")
+		}
+		rankerFunc = newStatePriorityRuntimeRanker
+	}
+	return rankerFunc(tracker)
+}
+
 // Represents the task states in the order in which they should be
 // evaluated for preemption.
 // For each task revocable tasks are first selected then
@@ -0,0 +1,175 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// DeadlineQueue is an earliest-deadline-first queue. Gangs are ordered by
+// the earliest deadline of their tasks, and gangs without a deadline come
+// after all gangs with one. Gangs with the same deadline are ordered by
+// priority and then by the order they came into the queue.
+type DeadlineQueue struct {
+	sync.RWMutex
+	// gangs sorted in the order they will be dequeued
+	items []*deadlineItem
+	// max number of gangs in the queue, if negative there is no limit
+	limit int64
+}
+
+// deadlineItem is a gang along with the keys it is ordered by
+type deadlineItem struct {
+	gang     *resmgrsvc.Gang
+	deadline uint64
+	priority uint32
+}
+
+// before returns true if the item should be dequeued before the other one
+func (i *deadlineItem) before(other *deadlineItem) bool {
+	if i.deadline != other.deadline {
+		return i.deadline < other.deadline
+	}
+	return i.priority > other.priority
+}
+
+// NewDeadlineQueue initializes the earliest-deadline-first queue and
+// returns the pointer
+func NewDeadlineQueue(limit int64) *DeadlineQueue {
+	return &DeadlineQueue{
+		limit: limit,
+	}
+}
+
+// Enqueue queues a gang (task list gang) based on its deadline
+func (q *DeadlineQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+
+	if q.limit >= 0 && q.limit <= int64(len(q.items)) {
+		return fmt.Errorf("list size limit reached")
+	}
+
+	item := &deadlineItem{
+		gang:     gang,
+		deadline: gangDeadline(gang),
+		priority: gang.GetTasks()[0].GetPriority(),
+	}
+
+	// insert after all the items which are not ordered after the new item
+	// to keep the order of arrival for items with the same keys
+	index := sort.Search(len(q.items), func(i int) bool {
+		return item.before(q.items[i])
+	})
+	q.items = append(q.items, nil)
+	copy(q.items[index+1:], q.items[index:])
+	q.items[index] = item
+	return nil
+}
+
+// Dequeue dequeues the gang (task list gang) with the earliest deadline
+func (q *DeadlineQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, ErrorQueueEmpty("dequeue failed, queue is empty")
+	}
+
+	gang := q.items[0].gang
+	q.removeAt(0)
+	return gang, nil
+}
+
+// Peek peeks the limit number of gangs based on the deadline, priority and
+// order they came into the queue.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (q *DeadlineQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	q.RLock()
+	defer q.RUnlock()
+
+	var items []*resmgrsvc.Gang
+	for _, item := range q.items {
+		if uint32(len(items)) == limit {
+			break
+		}
+		items = append(items, item.gang)
+	}
+
+	if len(items) == 0 {
+		return items, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return items, nil
+}
+
+// Remove removes the item from the queue
+func (q *DeadlineQueue) Remove(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+
+	for i, item := range q.items {
+		if item.gang == gang {
+			q.removeAt(i)
+			return nil
+		}
+	}
+	return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+}
+
+// Size returns the number of elements in the DeadlineQueue
+func (q *DeadlineQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.items)
+}
+
+// removeAt removes the item at the given index. The caller must hold the lock.
+func (q *DeadlineQueue) removeAt(index int) {
+	if index == 0 {
+		// removing from the head is the common case, avoid the copy
+		q.items[0] = nil
+		q.items = q.items[1:]
+		return
+	}
+	copy(q.items[index:], q.items[index+1:])
+	q.items[len(q.items)-1] = nil
+	q.items = q.items[:len(q.items)-1]
+}
+
+// gangDeadline returns the earliest deadline of the tasks in the gang, or
+// math.MaxUint64 if none of the tasks has a deadline.
+func gangDeadline(gang *resmgrsvc.Gang) uint64 {
+	deadline := uint64(math.MaxUint64)
+	for _, task := range gang.GetTasks() {
+		if d := task.GetDeadline(); d > 0 && d < deadline {
+			deadline = d
+		}
+	}
+	return deadline
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadlineQueueTestSuite struct {
+	suite.Suite
+	q *DeadlineQueue
+}
+
+func TestDeadlineQueue(t *testing.T) {
+	suite.Run(t, new(DeadlineQueueTestSuite))
+}
+
+func createDeadlineGang(
+	jobID string,
+	instance int,
+	priority uint32,
+	deadline uint64) *resmgrsvc.Gang {
+	gang := createGang(jobID, instance, priority)
+	gang.Tasks[0].Deadline = deadline
+	return gang
+}
+
+func (suite *DeadlineQueueTestSuite) SetupTest() {
+	suite.q = NewDeadlineQueue(math.MaxInt64)
+	suite.NoError(suite.q.Enqueue(createDeadlineGang("job1", 0, 2, 0)))
+	suite.NoError(suite.q.Enqueue(createDeadlineGang("job2", 0, 0, 300)))
+	suite.NoError(suite.q.Enqueue(createDeadlineGang("job3", 0, 0, 100)))
+	suite.NoError(suite.q.Enqueue(createDeadlineGang("job2", 1, 0, 300)))
+	suite.NoError(suite.q.Enqueue(createDeadlineGang("job4", 0, 1, 300)))
+}
+
+// expected order of the gangs which have been enqueued in SetupTest
+var _deadlineOrder = []string{
+	"job3-0",
+	"job4-0",
+	"job2-0",
+	"job2-1",
+	"job1-0",
+}
+
+func (suite *DeadlineQueueTestSuite) TestDequeue() {
+	for _, expected := range _deadlineOrder {
+		gang, err := suite.q.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, gang.Tasks[0].Id.GetValue())
+	}
+
+	_, err := suite.q.Dequeue()
+	suite.Error(err)
+	suite.IsType(ErrorQueueEmpty(""), err)
+}
+
+func (suite *DeadlineQueueTestSuite) TestPeek() {
+	gangs, err := suite.q.Peek(10)
+	suite.NoError(err)
+	suite.Len(gangs, len(_deadlineOrder))
+	for i, expected := range _deadlineOrder {
+		suite.Equal(expected, gangs[i].Tasks[0].Id.GetValue())
+	}
+
+	gangs, err = suite.q.Peek(2)
+	suite.NoError(err)
+	suite.Len(gangs, 2)
+	suite.Equal(5, suite.q.Size())
+}
+
+func (suite *DeadlineQueueTestSuite) TestRemove() {
+	gangs, err := suite.q.Peek(10)
+	suite.NoError(err)
+
+	suite.NoError(suite.q.Remove(gangs[2]))
+	suite.NoError(suite.q.Remove(gangs[0]))
+	suite.Equal(3, suite.q.Size())
+
+	gangs, err = suite.q.Peek(10)
+	suite.NoError(err)
+	suite.Equal("job4-0", gangs[0].Tasks[0].Id.GetValue())
+	suite.Equal("job2-1", gangs[1].Tasks[0].Id.GetValue())
+	suite.Equal("job1-0", gangs[2].Tasks[0].Id.GetValue())
+
+	suite.EqualError(suite.q.Remove(nil), "removal of empty list")
+	suite.Error(suite.q.Remove(createGang("job5", 0, 0)))
+}
+
+func (suite *DeadlineQueueTestSuite) TestGangDeadline() {
+	gang := createDeadlineGang("job1", 0, 0, 200)
+	gang.Tasks = append(gang.Tasks, createDeadlineGang("job1", 1, 0, 100).Tasks...)
+	gang.Tasks = append(gang.Tasks, createDeadlineGang("job1", 2, 0, 0).Tasks...)
+	suite.Equal(uint64(100), gangDeadline(gang))
+	suite.Equal(
+		uint64(math.MaxUint64),
+		gangDeadline(createDeadlineGang("job1", 0, 0, 0)))
+}
+
+func (suite *DeadlineQueueTestSuite) TestEnqueueErrors() {
+	q := NewDeadlineQueue(1)
+	suite.EqualError(q.Enqueue(nil), "enqueue of empty list")
+	suite.NoError(q.Enqueue(createGang("job1", 0, 0)))
+	suite.EqualError(
+		q.Enqueue(createGang("job1", 1, 0)),
+		"list size limit reached")
+}
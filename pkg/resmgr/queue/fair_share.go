@@ -0,0 +1,263 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// _maxFairShareWeight caps the number of gangs of a single job which are
+// served in one round, so that a very high priority job can not starve the
+// rest of the jobs in the pool.
+const _maxFairShareWeight = 100
+
+// WeightedFairShareQueue is a queue which round-robins across the jobs
+// which have gangs in the queue. In every round each job is served a number
+// of gangs equal to its weight, which is derived from the job priority.
+// Gangs of the same job are served in the order they came into the queue.
+type WeightedFairShareQueue struct {
+	sync.RWMutex
+
+	// gangs of every job in FIFO order, keyed by job id
+	jobs map[string]*list.List
+	// round-robin ring of the job ids which have gangs in the queue
+	ring *list.List
+	// ring element of every job id, used for removing jobs from the ring
+	ringElements map[string]*list.Element
+	// ring element of the job being served in the current round
+	current *list.Element
+	// number of gangs of the current job served in the current round
+	served uint32
+	// total number of gangs in the queue
+	size int
+	// max number of gangs in the queue, if negative there is no limit
+	limit int64
+}
+
+// NewWeightedFairShareQueue initializes the fair share queue and returns
+// the pointer
+func NewWeightedFairShareQueue(limit int64) *WeightedFairShareQueue {
+	return &WeightedFairShareQueue{
+		jobs:         make(map[string]*list.List),
+		ring:         list.New(),
+		ringElements: make(map[string]*list.Element),
+		limit:        limit,
+	}
+}
+
+// Enqueue queues a gang (task list gang) at the end of the queue of its job
+func (q *WeightedFairShareQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+
+	if q.limit >= 0 && q.limit <= int64(q.size) {
+		return fmt.Errorf("list size limit reached")
+	}
+
+	jobID := gangJobID(gang)
+	gangs, ok := q.jobs[jobID]
+	if !ok {
+		gangs = list.New()
+		q.jobs[jobID] = gangs
+		q.addToRing(jobID)
+	}
+	gangs.PushBack(gang)
+	q.size++
+	return nil
+}
+
+// Dequeue dequeues the next gang (task list gang) of the job being served
+// in the current round
+func (q *WeightedFairShareQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	gangs := q.peek(1)
+	if len(gangs) == 0 {
+		return nil, ErrorQueueEmpty("dequeue failed, queue is empty")
+	}
+	if err := q.remove(gangs[0]); err != nil {
+		return nil, err
+	}
+	return gangs[0], nil
+}
+
+// Peek peeks the limit number of gangs in the order they would be dequeued.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (q *WeightedFairShareQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	q.RLock()
+	defer q.RUnlock()
+
+	items := q.peek(limit)
+	if len(items) == 0 {
+		return items, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return items, nil
+}
+
+// peek simulates the round-robin from the current job without modifying
+// the queue. The caller must hold the lock.
+func (q *WeightedFairShareQueue) peek(limit uint32) []*resmgrsvc.Gang {
+	var items []*resmgrsvc.Gang
+	if q.size == 0 {
+		return items
+	}
+
+	// next gang to be served for every job visited so far
+	cursors := make(map[string]*list.Element)
+	element := q.current
+	served := q.served
+
+	for uint32(len(items)) < limit && len(items) < q.size {
+		jobID := element.Value.(string)
+		cursor, ok := cursors[jobID]
+		if !ok {
+			cursor = q.jobs[jobID].Front()
+		}
+
+		if cursor == nil ||
+			served >= gangWeight(cursor.Value.(*resmgrsvc.Gang)) {
+			// the job has no more gangs or has been served its share
+			// of this round, move on to the next job
+			element = q.next(element)
+			served = 0
+			continue
+		}
+
+		items = append(items, cursor.Value.(*resmgrsvc.Gang))
+		cursors[jobID] = cursor.Next()
+		served++
+	}
+	return items
+}
+
+// Remove removes the item from the queue. Removing the gang at the head of
+// the job being served counts towards the share of the job in this round.
+func (q *WeightedFairShareQueue) Remove(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+	return q.remove(gang)
+}
+
+// remove removes the gang from the queue. The caller must hold the lock.
+func (q *WeightedFairShareQueue) remove(gang *resmgrsvc.Gang) error {
+	jobID := gangJobID(gang)
+	gangs, ok := q.jobs[jobID]
+	if !ok {
+		return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+	}
+
+	var item *list.Element
+	for e := gangs.Front(); e != nil; e = e.Next() {
+		if e.Value == gang {
+			item = e
+			break
+		}
+	}
+	if item == nil {
+		return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+	}
+
+	served := item == gangs.Front() && q.ringElements[jobID] == q.current
+	gangs.Remove(item)
+	q.size--
+
+	if gangs.Len() == 0 {
+		q.removeFromRing(jobID)
+		return nil
+	}
+
+	if served {
+		q.served++
+		if q.served >= gangWeight(gangs.Front().Value.(*resmgrsvc.Gang)) {
+			q.advance()
+		}
+	}
+	return nil
+}
+
+// Size returns the number of elements in the WeightedFairShareQueue
+func (q *WeightedFairShareQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return q.size
+}
+
+// addToRing adds a new job at the end of the current round
+func (q *WeightedFairShareQueue) addToRing(jobID string) {
+	if q.current == nil {
+		q.current = q.ring.PushBack(jobID)
+		q.ringElements[jobID] = q.current
+		return
+	}
+	q.ringElements[jobID] = q.ring.InsertBefore(jobID, q.current)
+}
+
+// removeFromRing removes a job, which has no more gangs, from the ring
+func (q *WeightedFairShareQueue) removeFromRing(jobID string) {
+	element := q.ringElements[jobID]
+	if element == q.current {
+		q.advance()
+		if element == q.current {
+			// this was the only job in the ring
+			q.current = nil
+		}
+	}
+	q.ring.Remove(element)
+	delete(q.ringElements, jobID)
+	delete(q.jobs, jobID)
+}
+
+// advance moves the round-robin to the next job
+func (q *WeightedFairShareQueue) advance() {
+	q.current = q.next(q.current)
+	q.served = 0
+}
+
+// next returns the element following the given one in the ring
+func (q *WeightedFairShareQueue) next(element *list.Element) *list.Element {
+	if n := element.Next(); n != nil {
+		return n
+	}
+	return q.ring.Front()
+}
+
+// gangJobID returns the id of the job the gang belongs to
+func gangJobID(gang *resmgrsvc.Gang) string {
+	return gang.GetTasks()[0].GetJobId().GetValue()
+}
+
+// gangWeight returns the number of gangs of the job of the given gang which
+// are served in one round, which is one more than the job priority.
+func gangWeight(gang *resmgrsvc.Gang) uint32 {
+	priority := gang.GetTasks()[0].GetPriority()
+	if priority >= _maxFairShareWeight {
+		return _maxFairShareWeight
+	}
+	return priority + 1
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FairShareQueueTestSuite struct {
+	suite.Suite
+	q *WeightedFairShareQueue
+}
+
+func TestFairShareQueue(t *testing.T) {
+	suite.Run(t, new(FairShareQueueTestSuite))
+}
+
+func (suite *FairShareQueueTestSuite) SetupTest() {
+	suite.q = NewWeightedFairShareQueue(math.MaxInt64)
+	// job1 has weight 1 and job2 has weight 2
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("job1", i, 0)))
+	}
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("job2", i, 1)))
+	}
+}
+
+func createGang(jobID string, instance int, priority uint32) *resmgrsvc.Gang {
+	return &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			CreateResmgrTask(
+				&peloton.JobID{Value: jobID},
+				&peloton.TaskID{
+					Value: fmt.Sprintf("%s-%d", jobID, instance)},
+				priority),
+		},
+	}
+}
+
+// expected order of the gangs which have been enqueued in SetupTest
+var _fairShareOrder = []string{
+	"job1-0",
+	"job2-0",
+	"job2-1",
+	"job1-1",
+	"job2-2",
+	"job1-2",
+}
+
+func (suite *FairShareQueueTestSuite) TestDequeue() {
+	for _, expected := range _fairShareOrder {
+		gang, err := suite.q.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, gang.Tasks[0].Id.GetValue())
+	}
+	suite.Equal(0, suite.q.Size())
+
+	_, err := suite.q.Dequeue()
+	suite.Error(err)
+	suite.IsType(ErrorQueueEmpty(""), err)
+}
+
+func (suite *FairShareQueueTestSuite) TestPeek() {
+	gangs, err := suite.q.Peek(10)
+	suite.NoError(err)
+	suite.Len(gangs, len(_fairShareOrder))
+	for i, expected := range _fairShareOrder {
+		suite.Equal(expected, gangs[i].Tasks[0].Id.GetValue())
+	}
+
+	gangs, err = suite.q.Peek(2)
+	suite.NoError(err)
+	suite.Len(gangs, 2)
+	suite.Equal(6, suite.q.Size())
+}
+
+func (suite *FairShareQueueTestSuite) TestPeekAndRemove() {
+	for _, expected := range _fairShareOrder {
+		gangs, err := suite.q.Peek(1)
+		suite.NoError(err)
+		suite.Equal(expected, gangs[0].Tasks[0].Id.GetValue())
+		suite.NoError(suite.q.Remove(gangs[0]))
+	}
+
+	_, err := suite.q.Peek(1)
+	suite.Error(err)
+	suite.IsType(ErrorQueueEmpty(""), err)
+}
+
+func (suite *FairShareQueueTestSuite) TestRemoveNotAtHead() {
+	gangs, err := suite.q.Peek(10)
+	suite.NoError(err)
+
+	// removing job1-1 does not count towards the share of job1
+	suite.NoError(suite.q.Remove(gangs[3]))
+	suite.Equal(5, suite.q.Size())
+
+	gangs, err = suite.q.Peek(10)
+	suite.NoError(err)
+	var ids []string
+	for _, gang := range gangs {
+		ids = append(ids, gang.Tasks[0].Id.GetValue())
+	}
+	suite.Equal([]string{"job1-0", "job2-0", "job2-1", "job1-2", "job2-2"}, ids)
+}
+
+func (suite *FairShareQueueTestSuite) TestRemoveErrors() {
+	suite.EqualError(suite.q.Remove(nil), "removal of empty list")
+	suite.Error(suite.q.Remove(createGang("job1", 0, 0)))
+	suite.Error(suite.q.Remove(createGang("job3", 0, 0)))
+}
+
+func (suite *FairShareQueueTestSuite) TestNewJobJoinsEndOfRound() {
+	gang, err := suite.q.Dequeue()
+	suite.NoError(err)
+	suite.Equal("job1-0", gang.Tasks[0].Id.GetValue())
+
+	suite.NoError(suite.q.Enqueue(createGang("job3", 0, 0)))
+
+	var ids []string
+	for suite.q.Size() > 0 {
+		gang, err := suite.q.Dequeue()
+		suite.NoError(err)
+		ids = append(ids, gang.Tasks[0].Id.GetValue())
+	}
+	suite.Equal(
+		[]string{"job2-0", "job2-1", "job1-1", "job3-0", "job2-2", "job1-2"},
+		ids)
+}
+
+func (suite *FairShareQueueTestSuite) TestEnqueueErrors() {
+	q := NewWeightedFairShareQueue(1)
+	suite.EqualError(q.Enqueue(nil), "enqueue of empty list")
+	suite.EqualError(q.Enqueue(&resmgrsvc.Gang{}), "enqueue of empty list")
+	suite.NoError(q.Enqueue(createGang("job1", 0, 0)))
+	suite.EqualError(
+		q.Enqueue(createGang("job1", 1, 0)),
+		"list size limit reached")
+}
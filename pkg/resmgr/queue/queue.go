@@ -45,6 +45,10 @@ func CreateQueue(policy respool.SchedulingPolicy, limit int64) (Queue, error) {
 	switch policy {
 	case respool.SchedulingPolicy_PriorityFIFO:
 		return NewPriorityQueue(limit), nil
+	case respool.SchedulingPolicy_WeightedFairShare:
+		return NewWeightedFairShareQueue(limit), nil
+	case respool.SchedulingPolicy_EarliestDeadlineFirst:
+		return NewDeadlineQueue(limit), nil
 	default:
 		//if type is invalid, return an error
 		return nil, errors.New("invalid queue type")
@@ -35,12 +35,20 @@ func TestQueue(t *testing.T) {
 func (suite *QueueTestSuite) TestCreateQueueSuccess() {
 	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100)
 	suite.NoError(err)
-	suite.NotNil(q)
+	suite.IsType(&PriorityQueue{}, q)
+
+	q, err = CreateQueue(respool.SchedulingPolicy_WeightedFairShare, 100)
+	suite.NoError(err)
+	suite.IsType(&WeightedFairShareQueue{}, q)
+
+	q, err = CreateQueue(respool.SchedulingPolicy_EarliestDeadlineFirst, 100)
+	suite.NoError(err)
+	suite.IsType(&DeadlineQueue{}, q)
 }
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueError() {
-	q, err := CreateQueue(2, 100)
+	q, err := CreateQueue(100, 100)
 	suite.Nil(q)
 	suite.Error(err)
 	suite.EqualError(err, "invalid queue type")
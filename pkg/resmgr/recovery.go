@@ -272,10 +272,10 @@ func (r *RecoveryHandler) requeueTasksInRange(ctx context.Context,
 		WithField("job_id", jobID).
 		Info("Tasks to recover")
 
-	r.addNonRunningTasks(nonRunningTasks, jobConfig)
+	r.addNonRunningTasks(nonRunningTasks, jobConfig, jobRuntime)
 
 	// enqueuing running tasks
-	addedTasks, err := r.addRunningTasks(runningTasks, jobConfig)
+	addedTasks, err := r.addRunningTasks(runningTasks, jobConfig, jobRuntime)
 
 	if err == nil {
 		r.metrics.RecoveryRunningSuccessCount.Inc(int64(addedTasks))
@@ -290,12 +290,13 @@ func (r *RecoveryHandler) requeueTasksInRange(ctx context.Context,
 }
 
 func (r *RecoveryHandler) addNonRunningTasks(notRunningTasks []*task.TaskInfo,
-	jobConfig *job.JobConfig) {
+	jobConfig *job.JobConfig, jobRuntime *job.RuntimeInfo) {
 	if len(notRunningTasks) == 0 {
 		return
 	}
 	request := &resmgrsvc.EnqueueGangsRequest{
-		Gangs:   taskutil.ConvertToResMgrGangs(notRunningTasks, jobConfig),
+		Gangs: taskutil.ConvertToResMgrGangs(
+			notRunningTasks, jobConfig, jobRuntime),
 		ResPool: jobConfig.RespoolID,
 	}
 	log.WithField("request", request).Debug("Adding non running tasks")
@@ -304,7 +305,8 @@ func (r *RecoveryHandler) addNonRunningTasks(notRunningTasks []*task.TaskInfo,
 
 func (r *RecoveryHandler) addRunningTasks(
 	tasks []*task.TaskInfo,
-	config *job.JobConfig) (int, error) {
+	config *job.JobConfig,
+	jobRuntime *job.RuntimeInfo) (int, error) {
 
 	runningTasksAdded := 0
 	if len(tasks) == 0 {
@@ -318,7 +320,7 @@ func (r *RecoveryHandler) addRunningTasks(
 	}
 
 	for _, taskInfo := range tasks {
-		err = r.addTaskToTracker(taskInfo, config, resPool)
+		err = r.addTaskToTracker(taskInfo, config, jobRuntime, resPool)
 		if err != nil {
 			log.WithError(err).WithFields(log.Fields{
 				"job_id":      taskInfo.JobId,
@@ -334,8 +336,9 @@ func (r *RecoveryHandler) addRunningTasks(
 func (r *RecoveryHandler) addTaskToTracker(
 	taskInfo *task.TaskInfo,
 	config *job.JobConfig,
+	jobRuntime *job.RuntimeInfo,
 	respool respool.ResPool) error {
-	rmTask := taskutil.ConvertTaskToResMgrTask(taskInfo, config)
+	rmTask := taskutil.ConvertTaskToResMgrTask(taskInfo, config, jobRuntime)
 	err := r.tracker.AddTask(
 		rmTask,
 		r.handler.GetStreamHandler(),
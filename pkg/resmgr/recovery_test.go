@@ -523,7 +523,7 @@ func (suite *recoveryTestSuite) TestAddRunningTasksError() {
 		taskInfos = append(taskInfos, info)
 	}
 
-	val, err := suite.recovery.addRunningTasks(taskInfos, jobConfig)
+	val, err := suite.recovery.addRunningTasks(taskInfos, jobConfig, nil)
 	suite.Error(err)
 	suite.EqualError(err, "respool respool10 does not exist")
 	suite.Equal(val, 0)
@@ -555,7 +555,7 @@ func (suite *recoveryTestSuite) TestAddRunningTasks() {
 		Return(errors.New("error"))
 	suite.recovery.tracker = tracker
 
-	val, err := suite.recovery.addRunningTasks(taskInfos, jobConfig)
+	val, err := suite.recovery.addRunningTasks(taskInfos, jobConfig, nil)
 	suite.Error(err)
 	suite.EqualError(err, "unable to add running task to tracker: error")
 	suite.Equal(val, 0)
@@ -568,7 +568,7 @@ func (suite *recoveryTestSuite) TestAddRunningTasks() {
 		Return(nil)
 	tracker.EXPECT().AddResources(gomock.Any()).
 		Return(errors.New("error")).Times(1)
-	val, err = suite.recovery.addRunningTasks(taskInfos, jobConfig)
+	val, err = suite.recovery.addRunningTasks(taskInfos, jobConfig, nil)
 	suite.Error(err)
 	suite.EqualError(err, "could not add resources: error")
 	suite.Equal(val, 0)
@@ -601,7 +601,7 @@ func (suite *recoveryTestSuite) TestAddRunningTasks() {
 		Return(nil).AnyTimes()
 	tracker.EXPECT().AddResources(gomock.Any()).Return(nil).AnyTimes()
 	tracker.EXPECT().GetTask(gomock.Any()).Return(rmTask).AnyTimes()
-	val, err = suite.recovery.addRunningTasks(taskInfos, jobConfig)
+	val, err = suite.recovery.addRunningTasks(taskInfos, jobConfig, nil)
 	suite.Error(err)
 	suite.Contains(err.Error(), "transition failed in task state machine")
 	suite.Equal(val, 0)
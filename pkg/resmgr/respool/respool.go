@@ -90,6 +90,11 @@ type ResPool interface {
 	// on the queue type. limit determines the max number of gangs to be
 	// returned.
 	PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang, error)
+	// SetGangPriority sets the priority of the gang of a task in the
+	// queues of the resource pool, and queues the gang again so that it
+	// is ordered by the new priority. It returns false if the task is
+	// not queued.
+	SetGangPriority(id *peloton.TaskID, priority uint32) (bool, error)
 
 	// SetEntitlement sets the entitlement of non-revocable resources
 	// for non-revocable tasks + revocable tasks for this resource pool.
@@ -1154,6 +1159,64 @@ func (n *resPool) PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang,
 	return nil, nil
 }
 
+// SetGangPriority sets the priority of the gang of a task in the queues of
+// the resource pool. The queues are ordered by the priority of the gangs
+// when they are enqueued, so the gang is removed and enqueued again.
+func (n *resPool) SetGangPriority(
+	id *peloton.TaskID,
+	priority uint32) (bool, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, qt := range []QueueType{
+		PendingQueue,
+		NonPreemptibleQueue,
+		ControllerQueue,
+		RevocableQueue} {
+		q := n.queue(qt)
+		if q.Size() == 0 {
+			continue
+		}
+
+		gangs, err := q.Peek(uint32(q.Size()))
+		if err != nil {
+			if _, ok := err.(queue.ErrorQueueEmpty); ok {
+				continue
+			}
+			return false, err
+		}
+
+		for _, gang := range gangs {
+			if !hasTask(gang, id) {
+				continue
+			}
+
+			// the demand is unchanged since the gang stays in the queue
+			if err := q.Remove(gang); err != nil {
+				return false, err
+			}
+			for _, task := range gang.GetTasks() {
+				task.Priority = priority
+			}
+			if err := q.Enqueue(gang); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasTask returns true if the task is a member of the gang
+func hasTask(gang *resmgrsvc.Gang, id *peloton.TaskID) bool {
+	for _, task := range gang.GetTasks() {
+		if task.GetId().GetValue() == id.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
 func (n *resPool) isPreemptionEnabled() bool {
 	return n.preemptionCfg.Enabled
 }
@@ -1163,6 +1163,34 @@ func (s *ResPoolSuite) TestResPoolPeekGangs() {
 	}
 }
 
+func (s *ResPoolSuite) TestResPoolSetGangPriority() {
+	respool := s.createTestResourcePool()
+	for _, t := range s.getTasks() {
+		s.NoError(respool.EnqueueGang(makeTaskGang(t)))
+	}
+	demand := respool.GetDemand()
+
+	// the lowest priority task moves to the head of the queue
+	ok, err := respool.SetGangPriority(&peloton.TaskID{Value: "job1-1"}, 3)
+	s.NoError(err)
+	s.True(ok)
+
+	gangs, err := respool.PeekGangs(PendingQueue, 1)
+	s.NoError(err)
+	s.Equal("job1-1", gangs[0].GetTasks()[0].GetId().GetValue())
+	s.Equal(uint32(3), gangs[0].GetTasks()[0].GetPriority())
+
+	gangs, err = respool.PeekGangs(PendingQueue, 10)
+	s.NoError(err)
+	s.Len(gangs, 4)
+	s.Equal(demand, respool.GetDemand())
+
+	// tasks which are not queued are not found
+	ok, err = respool.SetGangPriority(&peloton.TaskID{Value: "job3-1"}, 3)
+	s.NoError(err)
+	s.False(ok)
+}
+
 func (s *ResPoolSuite) TestResPoolControllerLimit() {
 	rootConfig := &pb_respool.ResourcePoolConfig{
 		Name:      "root",
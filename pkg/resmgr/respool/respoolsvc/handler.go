@@ -21,6 +21,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	res "github.com/uber/peloton/pkg/resmgr/respool"
 	"github.com/uber/peloton/pkg/resmgr/scalar"
@@ -30,6 +31,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 const (
@@ -134,6 +136,13 @@ func (h *ServiceHandler) CreateResourcePool(
 		}, nil
 	}
 
+	// creating a resource pool requires access to its parent
+	if err := h.checkResourcePoolAccess(
+		ctx, h.getResPoolPath(resPoolConfig.GetParent())); err != nil {
+		h.metrics.CreateResourcePoolFail.Inc(1)
+		return nil, err
+	}
+
 	// TODO Handle parent of the new_resource_pool_config
 	// already has tasks added running, drain, distinguish?
 
@@ -292,6 +301,11 @@ func (h *ServiceHandler) DeleteResourcePool(
 		return resp, nil
 	}
 
+	if err := h.checkResourcePoolAccess(ctx, resPool.GetPath()); err != nil {
+		h.metrics.DeleteResourcePoolFail.Inc(1)
+		return nil, err
+	}
+
 	// As if the resource pool is not leaf, Delete method should
 	// not let this operation occur. As delete is only supported for
 	// leaf resource pools
@@ -346,6 +360,38 @@ func (h *ServiceHandler) DeleteResourcePool(
 	}, nil
 }
 
+// checkResourcePoolAccess returns a permission denied error if the user
+// in ctx is not permitted to call the current procedure on the resource
+// pool with the given path
+func (h *ServiceHandler) checkResourcePoolAccess(
+	ctx context.Context,
+	path string) error {
+	var procedure string
+	if call := yarpc.CallFromContext(ctx); call != nil {
+		procedure = call.Procedure()
+	}
+
+	if !auth.IsPermittedOnResource(ctx, procedure, &auth.Resource{
+		ResourcePoolPath: path,
+	}) {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"not permitted to call %s on resource pool %s",
+			procedure, path)
+	}
+	return nil
+}
+
+// getResPoolPath returns the path of the resource pool, or the path
+// of the root resource pool if it is not found
+func (h *ServiceHandler) getResPoolPath(
+	resPoolID *peloton.ResourcePoolID) string {
+	resPool, err := h.resPoolTree.Get(resPoolID)
+	if err != nil {
+		return res.ResourcePoolPathDelimiter
+	}
+	return resPool.GetPath()
+}
+
 // getDeleteResponse returns the empty respool DeleteResponse
 func (h *ServiceHandler) getDeleteResponse() *respool.DeleteResponse {
 	return &respool.DeleteResponse{
@@ -457,6 +503,18 @@ func (h *ServiceHandler) UpdateResourcePool(
 		}, nil
 	}
 
+	// updating a resource pool requires access to it, and to its
+	// new parent if the resource pool is moved
+	for _, path := range []string{
+		existingResPool.GetPath(),
+		h.getResPoolPath(resPoolConfig.GetParent()),
+	} {
+		if err := h.checkResourcePoolAccess(ctx, path); err != nil {
+			h.metrics.UpdateResourcePoolFail.Inc(1)
+			return nil, err
+		}
+	}
+
 	// update persistent store.
 	if err := h.resPoolOps.Update(ctx, resPoolID, resPoolConfig); err != nil {
 		h.metrics.UpdateResourcePoolFail.Inc(1)
@@ -21,6 +21,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
 
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	"github.com/uber/peloton/pkg/common"
 	rc "github.com/uber/peloton/pkg/resmgr/common"
 	res "github.com/uber/peloton/pkg/resmgr/respool"
@@ -36,6 +38,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type resPoolHandlerTestSuite struct {
@@ -351,6 +354,39 @@ func (s *resPoolHandlerTestSuite) TestCreateResourcePool() {
 	s.NotNil(uuid.Parse(createResp.Result.Value))
 }
 
+// TestCreateResourcePoolPermissionDenied tests that a user without a
+// grant on the parent resource pool cannot create a resource pool in it
+func (s *resPoolHandlerTestSuite) TestCreateResourcePoolPermissionDenied() {
+	user := authmocks.NewMockResourceUser(s.mockCtrl)
+	user.EXPECT().
+		IsPermittedOnResource(gomock.Any(), &auth.Resource{
+			ResourcePoolPath: "/respool2/respool22/respool23",
+		}).
+		Return(false)
+
+	createResp, err := s.handler.CreateResourcePool(
+		auth.ContextWithUser(s.context, user),
+		&pb_respool.CreateRequest{
+			Config: &pb_respool.ResourcePoolConfig{
+				Name:   "respool99",
+				Parent: &peloton.ResourcePoolID{Value: "respool23"},
+				Resources: []*pb_respool.ResourceConfig{
+					{
+						Reservation: 1,
+						Limit:       1,
+						Share:       1,
+						Kind:        "cpu",
+						Type:        pb_respool.ReservationType_ELASTIC,
+					},
+				},
+				Policy: pb_respool.SchedulingPolicy_PriorityFIFO,
+			},
+		})
+
+	s.Nil(createResp)
+	s.True(yarpcerrors.IsPermissionDenied(err))
+}
+
 func (s *resPoolHandlerTestSuite) TestCreateStaticResourcePool() {
 	mockResourcePoolName := "respool109"
 	mockResourcePoolConfig := &pb_respool.ResourcePoolConfig{
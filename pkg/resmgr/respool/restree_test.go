@@ -427,7 +427,7 @@ func (s *resTreeTestSuite) TestConvertTask() {
 		},
 	}
 
-	rmtask := taskutil.ConvertTaskToResMgrTask(ti, jobConfig)
+	rmtask := taskutil.ConvertTaskToResMgrTask(ti, jobConfig, nil)
 	s.NotNil(rmtask)
 	s.EqualValues(rmtask.Priority, 12)
 	s.EqualValues(rmtask.Preemptible, true)
@@ -442,7 +442,7 @@ func (s *resTreeTestSuite) TestConvertTask() {
 	}
 	jobConfig = &job.JobConfig{}
 
-	rmtask = taskutil.ConvertTaskToResMgrTask(ti, jobConfig)
+	rmtask = taskutil.ConvertTaskToResMgrTask(ti, jobConfig, nil)
 	s.NotNil(rmtask)
 	s.EqualValues(rmtask.Priority, 0)
 	s.EqualValues(rmtask.Preemptible, false)
@@ -49,6 +49,7 @@ type Server struct {
 	drainer               ServerProcess
 	preemptor             ServerProcess
 	batchScorer           ServerProcess
+	slaChecker            ServerProcess
 	// TODO move these to use ServerProcess
 	getTaskScheduler func() task.Scheduler
 
@@ -67,7 +68,8 @@ func NewServer(
 	reconciler ServerProcess,
 	preemptor ServerProcess,
 	drainer ServerProcess,
-	batchScorer ServerProcess) *Server {
+	batchScorer ServerProcess,
+	slaChecker ServerProcess) *Server {
 	return &Server{
 		ID:                    leader.NewID(httpPort, grpcPort),
 		role:                  common.ResourceManagerRole,
@@ -79,6 +81,7 @@ func NewServer(
 		preemptor:             preemptor,
 		drainer:               drainer,
 		batchScorer:           batchScorer,
+		slaChecker:            slaChecker,
 		metrics:               NewMetrics(parent),
 	}
 }
@@ -158,6 +161,13 @@ func (s *Server) GainedLeadershipCallback() (err error) {
 			Error("Failed to start batch scorer")
 		return err
 	}
+
+	// Start the SLA checker
+	if err = s.slaChecker.Start(); err != nil {
+		log.WithError(err).
+			Error("Failed to start SLA checker")
+		return err
+	}
 	return nil
 }
 
@@ -212,6 +222,11 @@ func (s *Server) LostLeadershipCallback() error {
 		return err
 	}
 
+	if err := s.slaChecker.Stop(); err != nil {
+		log.Errorf("Failed to stop SLA checker")
+		return err
+	}
+
 	return nil
 }
 
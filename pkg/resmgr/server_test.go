@@ -155,6 +155,23 @@ func TestServer_GainedLeadershipCallback(t *testing.T) {
 				preemptor:             &FakeServerProcess{nil},
 				drainer:               &FakeServerProcess{nil},
 				batchScorer:           &FakeServerProcess{nil},
+				slaChecker:            &FakeServerProcess{errFake},
+			},
+			wantErr: errFake,
+		},
+		{
+			s: &Server{
+				role:                  "testResMgr",
+				metrics:               NewMetrics(tally.NoopScope),
+				resTree:               &FakeServerProcess{nil},
+				recoveryHandler:       &FakeServerProcess{nil},
+				entitlementCalculator: &FakeServerProcess{nil},
+				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				reconciler:            &FakeServerProcess{nil},
+				preemptor:             &FakeServerProcess{nil},
+				drainer:               &FakeServerProcess{nil},
+				batchScorer:           &FakeServerProcess{nil},
+				slaChecker:            &FakeServerProcess{nil},
 			},
 			wantErr: nil,
 		},
@@ -281,6 +298,23 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				recoveryHandler:       &FakeServerProcess{nil},
 				resTree:               &FakeServerProcess{nil},
 				batchScorer:           &FakeServerProcess{nil},
+				slaChecker:            &FakeServerProcess{errFake},
+			},
+			wantErr: errFake,
+		},
+		{
+			s: &Server{
+				role:                  "testResMgr",
+				metrics:               NewMetrics(tally.NoopScope),
+				drainer:               &FakeServerProcess{nil},
+				preemptor:             &FakeServerProcess{nil},
+				reconciler:            &FakeServerProcess{nil},
+				entitlementCalculator: &FakeServerProcess{nil},
+				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				recoveryHandler:       &FakeServerProcess{nil},
+				resTree:               &FakeServerProcess{nil},
+				batchScorer:           &FakeServerProcess{nil},
+				slaChecker:            &FakeServerProcess{nil},
 			},
 			wantErr: nil,
 		},
@@ -308,6 +342,7 @@ func TestServer_GetID(t *testing.T) {
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
+		&FakeServerProcess{nil},
 	)
 
 	assert.NotNil(t, s)
@@ -333,6 +368,7 @@ func TestServer_ShutDownCallback(t *testing.T) {
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
+		&FakeServerProcess{nil},
 	)
 
 	assert.NoError(t, s.ShutDownCallback())
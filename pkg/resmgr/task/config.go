@@ -41,6 +41,12 @@ type Config struct {
 	EnablePlacementBackoff bool `yaml:"enable_placement_backoff"`
 	// This flag will enable/disable SLA tracking of tasks
 	EnableSLATracking bool `yaml:"enable_sla_tracking"`
+	// SLA rules of the state transitions of the tasks, which override
+	// the default SLAs for the tasks they match
+	SLARules []*SLARuleConfig `yaml:"sla_rules"`
+	// Period at which the state transitions in progress are checked
+	// for SLA violations
+	SLACheckPeriod time.Duration `yaml:"sla_check_period"`
 	// This flag will enable/disable host reservation of tasks
 	EnableHostReservation bool `yaml:"enable_host_reservation"`
 }
@@ -37,6 +37,8 @@ type Metrics struct {
 	ReconciliationFail    tally.Counter
 
 	OrphanTasks tally.Gauge
+
+	SLAViolations tally.Counter
 }
 
 // NewMetrics returns a new instance of task.Metrics.
@@ -112,5 +114,6 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ReconciliationSuccess: successScope.Counter("run"),
 		ReconciliationFail:    failScope.Counter("run"),
 		OrphanTasks:           scope.Gauge("orphan_tasks"),
+		SLAViolations:         scope.SubScope("sla").Counter("violations"),
 	}
 }
@@ -19,7 +19,9 @@ import (
 	"sync"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/respool"
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -43,6 +46,7 @@ var (
 var (
 	reasonPlacementFailed = "Reached placement failure backoff threshold"
 	reasonPlacementRetry  = "Previous placement failed"
+	reasonSLAViolated     = "State transition SLA violated"
 )
 
 // RunTimeStats is the container for run time stats of the resmgr task
@@ -99,6 +103,8 @@ func CreateRMTask(
 			taskConfig.EnableSLATracking,
 			scope,
 			respool.GetPath(),
+			taskConfig.SLARules,
+			t.GetJobLabels(),
 		),
 	}
 
@@ -480,6 +486,77 @@ func (rmTask *RMTask) RequeueUnPlaced(reason string) error {
 	return rmTask.requeueToReadyQueue(reason)
 }
 
+// RequeueUnPlacedGang requeues the tasks of a gang which couldn't be placed
+// as a single gang. The gang is pushed back to the pending queue if any of
+// its tasks has finished its placement cycle, else to the ready queue. The
+// tasks which are no longer in PLACING, e.g. killed or already requeued, are
+// left out of the gang.
+func RequeueUnPlacedGang(rmTasks []*RMTask, reason string) error {
+	// the tasks are locked together so that none of them is moved by a
+	// timeout or an event while the gang is being requeued
+	var placing []*RMTask
+	for _, rmTask := range rmTasks {
+		rmTask.mu.Lock()
+		defer rmTask.mu.Unlock()
+
+		if rmTask.getCurrentState().State == task.TaskState_PLACING {
+			placing = append(placing, rmTask)
+		}
+	}
+	if len(placing) == 0 {
+		return nil
+	}
+
+	toState, reasonPrefix := task.TaskState_READY, reasonPlacementRetry
+	for _, rmTask := range placing {
+		if rmTask.hasFinishedPlacementCycle() {
+			toState, reasonPrefix = task.TaskState_PENDING, reasonPlacementFailed
+			break
+		}
+	}
+
+	errs := new(multierror.Error)
+	gang := &resmgrsvc.Gang{}
+	for _, rmTask := range placing {
+		if err := rmTask.TransitTo(toState.String(),
+			state.WithReason(strings.Join(
+				[]string{
+					reasonPrefix, reason,
+				}, ":"))); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		rmTask.task.Hostname = ""
+		gang.Tasks = append(gang.Tasks, rmTask.task)
+	}
+	if len(gang.GetTasks()) == 0 {
+		return errs.ErrorOrNil()
+	}
+
+	if toState == task.TaskState_PENDING {
+		// push to pending queue and remove allocation, the tasks of a gang
+		// all belong to the same resource pool
+		pool := placing[0].Respool()
+		if err := pool.EnqueueGang(gang); err != nil {
+			return errors.Wrapf(err, "failed to enqueue gang")
+		}
+		if err := pool.SubtractFromAllocation(
+			scalar.GetGangAllocation(gang)); err != nil {
+			return errors.Wrapf(err, "failed to remove allocation from respool")
+		}
+	} else if err := GetScheduler().EnqueueGang(gang); err != nil {
+		return errors.Wrapf(err, "failed to enqueue gang")
+	}
+
+	log.WithFields(log.Fields{
+		"tasks":      len(gang.GetTasks()),
+		"first_task": gang.GetTasks()[0].GetId().GetValue(),
+		"from_state": task.TaskState_PLACING.String(),
+		"to_state":   toState.String(),
+	}).Info("Gang moved back to queue")
+	return errs.ErrorOrNil()
+}
+
 // requeques a placing task to ready queue
 // NB: Acquire lock on rm task before calling
 func (rmTask *RMTask) requeueToReadyQueue(reason string) error {
@@ -729,6 +806,123 @@ func (rmTask *RMTask) preTimeoutCallback(t *state.Transition) error {
 	return nil
 }
 
+// CheckSLA records the SLA violations of the state transitions in progress
+// of the task, and takes the actions of the violated SLA rules. A task being
+// placed with its gang is requeued together with the gang by the gang
+// requeuer, if any. It returns the violations which were recorded.
+func (rmTask *RMTask) CheckSLA(
+	now time.Time,
+	gangs GangRequeuer,
+) []SLAViolation {
+	violations := rmTask.transitionObserver.CheckSLA(now)
+	for _, violation := range violations {
+		log.WithFields(log.Fields{
+			"task_id":      rmTask.Task().GetId().GetValue(),
+			"from_state":   violation.From.String(),
+			"to_state":     violation.To.String(),
+			"sla_minutes":  violation.SLA.Minutes(),
+			"time_elapsed": violation.Elapsed.Minutes(),
+			"actions":      violation.Actions,
+		}).Info("RMTask SLA breached while in progress")
+
+		for _, action := range violation.Actions {
+			if err := rmTask.takeSLAAction(
+				action,
+				violation,
+				gangs,
+			); err != nil {
+				log.WithError(err).
+					WithField("task_id", rmTask.Task().GetId().GetValue()).
+					WithField("action", action).
+					Warn("Failed to take SLA violation action")
+			}
+		}
+	}
+	return violations
+}
+
+// SLAViolations returns the SLA violations of the task
+func (rmTask *RMTask) SLAViolations() []*resmgr.SLAViolation {
+	var violations []*resmgr.SLAViolation
+	for _, violation := range rmTask.transitionObserver.Violations() {
+		violations = append(violations, rmTask.slaViolationToProto(violation))
+	}
+	return violations
+}
+
+// takeSLAAction takes an action for the violation of the SLA of a
+// transition in progress
+func (rmTask *RMTask) takeSLAAction(
+	action string,
+	violation SLAViolation,
+	gangs GangRequeuer,
+) error {
+	switch action {
+	case SLAActionRequeue:
+		// requeuing a member of a gang alone would leave the placements
+		// held for the rest of its gang behind
+		if gangs != nil &&
+			gangs.RequeueGangOf(rmTask.Task().GetId(), reasonSLAViolated) {
+			return nil
+		}
+
+		rmTask.mu.Lock()
+		defer rmTask.mu.Unlock()
+
+		// only the tasks stuck in placement can be requeued, the
+		// tasks in the queues are already waiting on resources
+		if rmTask.getCurrentState().State != task.TaskState_PLACING {
+			return nil
+		}
+		return rmTask.requeueToReadyQueue(reasonSLAViolated)
+	case SLAActionRaisePriority:
+		rmTask.mu.Lock()
+		defer rmTask.mu.Unlock()
+
+		// the pending queues are ordered by the priority of the gangs when
+		// they are enqueued, so a pending gang is enqueued again at the new
+		// priority, ahead of the gangs of lower priority
+		priority := rmTask.task.GetPriority() + 1
+		if rmTask.getCurrentState().State == task.TaskState_PENDING {
+			if _, err := rmTask.Respool().SetGangPriority(
+				rmTask.task.GetId(),
+				priority); err != nil {
+				return err
+			}
+		}
+		rmTask.task.Priority = priority
+		return nil
+	case SLAActionAlert:
+		if rmTask.statusUpdateHandler == nil {
+			return nil
+		}
+		return rmTask.statusUpdateHandler.AddEvent(&pb_eventstream.Event{
+			Type:         pb_eventstream.Event_SLA_VIOLATION_EVENT,
+			SlaViolation: rmTask.slaViolationToProto(violation),
+		})
+	}
+	return nil
+}
+
+// slaViolationToProto converts an SLA violation of the task to protobuf
+func (rmTask *RMTask) slaViolationToProto(
+	violation SLAViolation,
+) *resmgr.SLAViolation {
+	return &resmgr.SLAViolation{
+		Id:             rmTask.Task().GetId(),
+		TaskId:         &mesos.TaskID{Value: &violation.MesosTaskID},
+		JobId:          rmTask.Task().GetJobId(),
+		RespoolPath:    rmTask.Respool().GetPath(),
+		FromState:      violation.From,
+		ToState:        violation.To,
+		SlaSeconds:     violation.SLA.Seconds(),
+		ElapsedSeconds: violation.Elapsed.Seconds(),
+		InProgress:     violation.InProgress,
+		DetectionTime:  violation.DetectionTime.Format(time.RFC3339),
+		Actions:        violation.Actions,
+	}
+}
+
 // TODO : Commenting it for now to not publish yet, Until we have solution for
 // event race : T936171
 // updateStatus creates and send the task event to event stream
@@ -1115,3 +1115,315 @@ func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedTaskInPlacingToPending() {
 		mockStateMachine)
 	s.NoError(err, "placing to pending requeue should not fail")
 }
+
+func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedGangToPending() {
+	// Tests a gang is requeued to the pending queue as a whole once any of
+	// its tasks has finished its placement cycle.
+	config := &Config{
+		LaunchingTimeout:          2 * time.Second,
+		PlacingTimeout:            2 * time.Second,
+		PlacementRetryCycle:       3,
+		PlacementAttemptsPerCycle: 3,
+		PlacementRetryBackoff:     1 * time.Second,
+		PolicyName:                ExponentialBackOffPolicy,
+		EnablePlacementBackoff:    true,
+	}
+
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").AnyTimes()
+
+	var rmTasks []*RMTask
+	for _, t := range []*resmgr.Task{s.createTask(2), s.createTask0(0, 3)} {
+		rmTask, err := CreateRMTask(tally.NoopScope, t, nil, mockNode, config)
+		s.NoError(err)
+
+		mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+		mockStateMachine.
+			EXPECT().GetCurrentState().
+			Return(statemachine.State(task.TaskState_PLACING.String())).
+			AnyTimes()
+		mockStateMachine.
+			EXPECT().TransitTo(
+			statemachine.State(task.TaskState_PENDING.String()),
+			gomock.Any(),
+		).Return(nil)
+		rmTask.stateMachine = mockStateMachine
+		rmTasks = append(rmTasks, rmTask)
+	}
+
+	mockNode.EXPECT().
+		EnqueueGang(gomock.Any()).
+		Do(func(gang *resmgrsvc.Gang) {
+			s.Len(gang.GetTasks(), 2)
+		}).
+		Return(nil)
+	mockNode.EXPECT().
+		SubtractFromAllocation(gomock.Any()).Return(nil)
+
+	s.NoError(RequeueUnPlacedGang(rmTasks, ""))
+}
+
+func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedGangSkipsNotPlacing() {
+	// Tests the tasks of a gang which are no longer being placed are not
+	// requeued with the gang.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").AnyTimes()
+
+	states := []task.TaskState{task.TaskState_PLACING, task.TaskState_READY}
+	var rmTasks []*RMTask
+	for i, state := range states {
+		rmTask, err := CreateRMTask(
+			tally.NoopScope,
+			s.createTask(i+2),
+			nil,
+			mockNode,
+			&Config{
+				PolicyName: ExponentialBackOffPolicy,
+			},
+		)
+		s.NoError(err)
+
+		mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+		mockStateMachine.
+			EXPECT().GetCurrentState().
+			Return(statemachine.State(state.String())).
+			AnyTimes()
+		rmTask.stateMachine = mockStateMachine
+		rmTasks = append(rmTasks, rmTask)
+	}
+
+	// only the task being placed moves back to the ready queue
+	rmTasks[0].stateMachine.(*sm_mock.MockStateMachine).
+		EXPECT().TransitTo(
+		statemachine.State(task.TaskState_READY.String()),
+		gomock.Any(),
+	).Return(nil)
+
+	s.NoError(RequeueUnPlacedGang(rmTasks, ""))
+}
+
+func (s *RMTaskTestSuite) TestRMTaskCheckSLARequeue() {
+	// Tests a task stuck in PLACING beyond the SLA is moved back to the
+	// ready queue, and its priority is raised for its next enqueue.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").Times(1)
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		s.createTask(1),
+		nil,
+		mockNode,
+		&Config{
+			PolicyName:        ExponentialBackOffPolicy,
+			EnableSLATracking: true,
+			SLARules: []*SLARuleConfig{
+				{
+					From: task.TaskState_PLACING.String(),
+					To:   task.TaskState_PLACED.String(),
+					SLA:  time.Minute,
+					Actions: []string{
+						SLAActionRequeue,
+						SLAActionRaisePriority,
+					},
+				},
+			},
+		},
+	)
+	s.NoError(err)
+
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+	rmTask.stateMachine = mockStateMachine
+
+	// task is in PLACING state
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_PLACING.String()))
+	mockStateMachine.
+		EXPECT().GetReason().
+		Return("testing").AnyTimes()
+	mockStateMachine.
+		EXPECT().GetLastUpdateTime().
+		Return(time.Now()).AnyTimes()
+	// transit to READY
+	mockStateMachine.
+		EXPECT().TransitTo(
+		statemachine.State(task.TaskState_READY.String()),
+		gomock.Any(),
+	).Return(nil)
+	// task is in READY state
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_READY.String())).
+		AnyTimes()
+
+	rmTask.transitionObserver.Observe(
+		rmTask.Task().GetTaskId().GetValue(),
+		task.TaskState_PLACING)
+
+	// the SLA is not violated yet
+	s.Empty(rmTask.CheckSLA(time.Now(), nil))
+
+	violations := rmTask.CheckSLA(time.Now().Add(2*time.Minute), nil)
+	s.Len(violations, 1)
+	s.Equal(task.TaskState_PLACING, violations[0].From)
+	s.Equal(task.TaskState_PLACED, violations[0].To)
+	s.Equal(uint32(1), rmTask.Task().GetPriority())
+}
+
+// fakeGangRequeuer records the tasks of which the gang is requeued
+type fakeGangRequeuer struct{ requeued []string }
+
+func (f *fakeGangRequeuer) RequeueGangOf(
+	taskID *peloton.TaskID,
+	reason string) bool {
+	f.requeued = append(f.requeued, taskID.GetValue())
+	return true
+}
+
+func (s *RMTaskTestSuite) TestRMTaskCheckSLARequeueGang() {
+	// Tests a gang member stuck in PLACING beyond the SLA is requeued with
+	// its gang instead of on its own.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").Times(1)
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		s.createTask(1),
+		nil,
+		mockNode,
+		&Config{
+			PolicyName:        ExponentialBackOffPolicy,
+			EnableSLATracking: true,
+			SLARules: []*SLARuleConfig{
+				{
+					From:    task.TaskState_PLACING.String(),
+					To:      task.TaskState_PLACED.String(),
+					SLA:     time.Minute,
+					Actions: []string{SLAActionRequeue},
+				},
+			},
+		},
+	)
+	s.NoError(err)
+
+	// the task is not moved by itself
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+	rmTask.stateMachine = mockStateMachine
+
+	rmTask.transitionObserver.Observe(
+		rmTask.Task().GetTaskId().GetValue(),
+		task.TaskState_PLACING)
+
+	gangs := &fakeGangRequeuer{}
+	s.Len(rmTask.CheckSLA(time.Now().Add(2*time.Minute), gangs), 1)
+	s.Equal([]string{"job1-1"}, gangs.requeued)
+}
+
+func (s *RMTaskTestSuite) TestRMTaskCheckSLARaisePriorityPending() {
+	// Tests a task stuck in PENDING beyond the SLA is queued again at
+	// the raised priority.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").AnyTimes()
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		s.createTask(1),
+		nil,
+		mockNode,
+		&Config{
+			PolicyName:        ExponentialBackOffPolicy,
+			EnableSLATracking: true,
+			SLARules: []*SLARuleConfig{
+				{
+					From:    task.TaskState_PENDING.String(),
+					To:      task.TaskState_READY.String(),
+					SLA:     time.Minute,
+					Actions: []string{SLAActionRaisePriority},
+				},
+			},
+		},
+	)
+	s.NoError(err)
+
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+	rmTask.stateMachine = mockStateMachine
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_PENDING.String())).
+		AnyTimes()
+
+	mockNode.EXPECT().
+		SetGangPriority(rmTask.Task().GetId(), uint32(1)).
+		Return(true, nil)
+
+	rmTask.transitionObserver.Observe(
+		rmTask.Task().GetTaskId().GetValue(),
+		task.TaskState_PENDING)
+
+	s.Len(rmTask.CheckSLA(time.Now().Add(2*time.Minute), nil), 1)
+	s.Equal(uint32(1), rmTask.Task().GetPriority())
+
+	// the priority is not raised if the gang can't be queued again
+	mockNode.EXPECT().
+		SetGangPriority(rmTask.Task().GetId(), uint32(2)).
+		Return(false, errors.New("test error"))
+	s.Error(rmTask.takeSLAAction(SLAActionRaisePriority, SLAViolation{}, nil))
+	s.Equal(uint32(1), rmTask.Task().GetPriority())
+}
+
+func (s *RMTaskTestSuite) TestRMTaskCheckSLARaisePriority() {
+	// Tests the priority of a task stuck in LAUNCHING beyond the SLA is
+	// raised, and that the violation is reported by the task.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").AnyTimes()
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		s.createTask(1),
+		nil,
+		mockNode,
+		&Config{
+			PolicyName:        ExponentialBackOffPolicy,
+			EnableSLATracking: true,
+			SLARules: []*SLARuleConfig{
+				{
+					From:    task.TaskState_LAUNCHING.String(),
+					To:      task.TaskState_RUNNING.String(),
+					SLA:     time.Minute,
+					Actions: []string{SLAActionRaisePriority},
+				},
+			},
+		},
+	)
+	s.NoError(err)
+
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+	rmTask.stateMachine = mockStateMachine
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_LAUNCHING.String())).
+		AnyTimes()
+	mockStateMachine.
+		EXPECT().GetReason().
+		Return("testing").AnyTimes()
+	mockStateMachine.
+		EXPECT().GetLastUpdateTime().
+		Return(time.Now()).AnyTimes()
+
+	rmTask.transitionObserver.Observe(
+		rmTask.Task().GetTaskId().GetValue(),
+		task.TaskState_LAUNCHING)
+
+	s.Len(rmTask.CheckSLA(time.Now().Add(2*time.Minute), nil), 1)
+	s.Equal(uint32(1), rmTask.Task().GetPriority())
+
+	violations := rmTask.SLAViolations()
+	s.Len(violations, 1)
+	s.Equal("job1-1", violations[0].GetId().GetValue())
+	s.Equal("/mocknode", violations[0].GetRespoolPath())
+	s.Equal(task.TaskState_LAUNCHING, violations[0].GetFromState())
+	s.Equal(task.TaskState_RUNNING, violations[0].GetToState())
+	s.Equal(float64(60), violations[0].GetSlaSeconds())
+	s.True(violations[0].GetInProgress())
+	s.Equal([]string{SLAActionRaisePriority}, violations[0].GetActions())
+}
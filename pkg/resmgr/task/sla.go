@@ -15,8 +15,12 @@
 package task
 
 import (
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
 	log "github.com/sirupsen/logrus"
@@ -84,6 +88,180 @@ var defaultRules = map[task.TaskState]map[task.TaskState]time.Duration{
 	},
 }
 
+// Actions which can be taken when a task breaches the SLA of a transition
+// in progress
+const (
+	// SLAActionRequeue moves a task stuck in PLACING back to the ready
+	// queue, so that it is offered for placement again. A member of a gang
+	// is moved back with its whole gang.
+	SLAActionRequeue = "requeue"
+	// SLAActionRaisePriority raises the priority of a task by one. A
+	// pending task is queued again at the new priority along with its
+	// gang. The ready queue is not ordered by priority, so for the other
+	// tasks it applies the next time the task is enqueued or ranked for
+	// preemption.
+	SLAActionRaisePriority = "raise_priority"
+	// SLAActionAlert publishes the violation on the event stream
+	SLAActionAlert = "alert"
+)
+
+// _maxSLAViolations is the number of SLA violations kept per task
+const _maxSLAViolations = 10
+
+// SLARuleConfig is the SLA of a state transition for the tasks of a
+// resource pool and/or of the jobs with some labels. When several rules
+// match a task, the rules matching on job labels take precedence over the
+// rules matching on resource pool only, and deeper resource pools take
+// precedence over their parents. The default SLAs apply to the
+// transitions without any matching rule.
+type SLARuleConfig struct {
+	// From is the state the transition starts from, e.g. PENDING
+	From string `yaml:"from"`
+	// To is the state the transition is expected to end in, e.g. READY
+	To string `yaml:"to"`
+	// SLA is the maximum duration of the transition
+	SLA time.Duration `yaml:"sla"`
+	// RespoolPath is the path of the resource pool the rule applies to,
+	// along with its children. The rule applies to all resource pools
+	// if not set.
+	RespoolPath string `yaml:"respool_path"`
+	// JobLabels are the labels the job of the task must have for the
+	// rule to apply.
+	JobLabels map[string]string `yaml:"job_labels"`
+	// Actions are the actions to take when a task breaches the SLA,
+	// among requeue, raise_priority and alert
+	Actions []string `yaml:"actions"`
+}
+
+// ValidateSLARules validates the SLA rules
+func ValidateSLARules(rules []*SLARuleConfig) error {
+	for _, rule := range rules {
+		if _, ok := task.TaskState_value[rule.From]; !ok {
+			return fmt.Errorf("invalid SLA rule from state %q", rule.From)
+		}
+		if _, ok := task.TaskState_value[rule.To]; !ok {
+			return fmt.Errorf("invalid SLA rule to state %q", rule.To)
+		}
+		if rule.SLA <= 0 {
+			return fmt.Errorf(
+				"SLA rule from %s to %s must have a positive SLA",
+				rule.From, rule.To)
+		}
+		for _, action := range rule.Actions {
+			switch action {
+			case SLAActionRequeue, SLAActionRaisePriority, SLAActionAlert:
+			default:
+				return fmt.Errorf("invalid SLA rule action %q", action)
+			}
+		}
+	}
+	return nil
+}
+
+// specificity returns how specific the rule is for the task in the
+// resource pool respoolPath and the job with jobLabels, -1 if the rule
+// does not apply to the task
+func (r *SLARuleConfig) specificity(
+	respoolPath string,
+	jobLabels []*peloton.Label,
+) int {
+	specificity := 0
+
+	rulePath := strings.TrimSuffix(r.RespoolPath, "/")
+	if rulePath != "" {
+		if respoolPath != rulePath &&
+			!strings.HasPrefix(respoolPath, rulePath+"/") {
+			return -1
+		}
+		specificity = strings.Count(rulePath, "/")
+	}
+
+	if len(r.JobLabels) > 0 {
+		for key, value := range r.JobLabels {
+			found := false
+			for _, label := range jobLabels {
+				if label.GetKey() == key && label.GetValue() == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return -1
+			}
+		}
+		// labels take precedence over any resource pool depth
+		specificity += 1 << 16
+	}
+	return specificity
+}
+
+// buildSLARules returns the SLAs of the transitions of a task in the
+// resource pool respoolPath and the job with jobLabels, along with the
+// actions to take on their violation
+func buildSLARules(
+	configs []*SLARuleConfig,
+	respoolPath string,
+	jobLabels []*peloton.Label,
+) (map[task.TaskState]map[task.TaskState]time.Duration,
+	map[recordKey][]string) {
+	if len(configs) == 0 {
+		return defaultRules, nil
+	}
+
+	rules := make(map[task.TaskState]map[task.TaskState]time.Duration)
+	for from, endStates := range defaultRules {
+		rules[from] = make(map[task.TaskState]time.Duration)
+		for to, sla := range endStates {
+			rules[from][to] = sla
+		}
+	}
+
+	actions := make(map[recordKey][]string)
+	specificities := make(map[recordKey]int)
+	for _, config := range configs {
+		specificity := config.specificity(respoolPath, jobLabels)
+		if specificity < 0 {
+			continue
+		}
+
+		from := task.TaskState(task.TaskState_value[config.From])
+		to := task.TaskState(task.TaskState_value[config.To])
+		key := getRecorderKey(from, to)
+		if current, ok := specificities[key]; ok && current >= specificity {
+			continue
+		}
+
+		if _, ok := rules[from]; !ok {
+			rules[from] = make(map[task.TaskState]time.Duration)
+		}
+		rules[from][to] = config.SLA
+		actions[key] = config.Actions
+		specificities[key] = specificity
+	}
+	return rules, actions
+}
+
+// SLAViolation is a breach of the SLA of a state transition by a task
+type SLAViolation struct {
+	// The Mesos task ID when the transition started
+	MesosTaskID string
+	// The state the transition started from
+	From task.TaskState
+	// The state the transition is expected to end in
+	To task.TaskState
+	// The SLA of the transition
+	SLA time.Duration
+	// The time the transition took, or had been taking when the
+	// violation was detected if it is still in progress
+	Elapsed time.Duration
+	// InProgress is true until the task reaches the end state
+	InProgress bool
+	// The time the violation was detected
+	DetectionTime time.Time
+	// The actions taken for the violation
+	Actions []string
+}
+
 // Tags for state transition metrics
 const (
 	_respoolPath = "respool_path"
@@ -95,6 +273,13 @@ const (
 // TransitionObserver is the interface for observing a state transition
 type TransitionObserver interface {
 	Observe(taskID string, transitedTo task.TaskState)
+
+	// CheckSLA records the SLA violations of the transitions in progress
+	// at now which were not recorded yet, and returns them.
+	CheckSLA(now time.Time) []SLAViolation
+
+	// Violations returns the SLA violations of the task, latest last
+	Violations() []SLAViolation
 }
 
 // The key of the recorder of the form startState_endState Eg PENDING_READY
@@ -109,6 +294,9 @@ type record struct {
 	startState task.TaskState
 	// The time when the task reached the state
 	startTime time.Time
+	// The violation of the SLA of the transition, if any was recorded
+	// while the transition was in progress
+	violation *SLAViolation
 }
 
 type recorder interface {
@@ -127,10 +315,21 @@ type TransObs struct {
 	// boolean to enable/disable the observer
 	enabled bool
 
+	// protects the in progress transitions and the violations, which
+	// are checked by the SLA checker concurrently with the transitions
+	mu sync.Mutex
+
 	// Represents the transitions to record as map of start state
 	// -> list of end states
 	rules map[task.TaskState]map[task.TaskState]time.Duration
 
+	// The actions to take when the SLA of a transition in progress
+	// is violated
+	actions map[recordKey][]string
+
+	// The SLA violations of the task, latest last
+	violations []*SLAViolation
+
 	// map of in progress transitions which need to be recorded.
 	// Its keyed by the end state which it is waiting on.
 	inProgress map[task.TaskState][]record
@@ -146,11 +345,14 @@ type TransObs struct {
 }
 
 // NewTransitionObserver returns the a new observer for the respool and
-// the task tagged with the relevant tags
+// the task tagged with the relevant tags. The SLA rules matching the
+// respool and the job labels override the default SLAs.
 func NewTransitionObserver(
 	enabled bool,
 	scope tally.Scope,
 	respoolPath string,
+	slaRules []*SLARuleConfig,
+	jobLabels []*peloton.Label,
 ) TransitionObserver {
 	tags := make(map[string]string)
 	if enabled {
@@ -160,7 +362,10 @@ func NewTransitionObserver(
 		}
 	}
 
-	return newTransitionObserver(tags, scope, defaultRules, enabled)
+	rules, actions := buildSLARules(slaRules, respoolPath, jobLabels)
+	obs := newTransitionObserver(tags, scope, rules, enabled)
+	obs.actions = actions
+	return obs
 }
 
 // newTransitionObserver returns a new transition observer based on
@@ -191,6 +396,9 @@ func (obs *TransObs) Observe(mesosTaskID string, currentState task.TaskState) {
 		return
 	}
 
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+
 	// Go through all the rules and see if currentState is the start state
 	// for any of the rules.
 	if endStates, ok := obs.rules[currentState]; ok {
@@ -226,9 +434,25 @@ func (obs *TransObs) Observe(mesosTaskID string, currentState task.TaskState) {
 			sub := time.Now().Sub(inProgressRecord.startTime)
 			recorder.RecordDuration(sub)
 
-			// if time elapsed breached sla lets log it.
+			// if time elapsed breached sla lets record and log it.
 			sla := obs.rules[inProgressRecord.startState][currentState]
+			if inProgressRecord.violation != nil {
+				// the violation was detected while in progress
+				inProgressRecord.violation.Elapsed = sub
+				inProgressRecord.violation.InProgress = false
+			}
 			if sub > sla {
+				if inProgressRecord.violation == nil {
+					obs.addViolation(&SLAViolation{
+						MesosTaskID:   inProgressRecord.mesosTaskID,
+						From:          inProgressRecord.startState,
+						To:            currentState,
+						SLA:           sla,
+						Elapsed:       sub,
+						DetectionTime: time.Now(),
+					})
+				}
+
 				log.WithField("start_mesos_task_id", inProgressRecord.mesosTaskID).
 					WithField("current_mesos_task_id", mesosTaskID).
 					WithField("to_state", currentState.String()).
@@ -242,3 +466,67 @@ func (obs *TransObs) Observe(mesosTaskID string, currentState task.TaskState) {
 		delete(obs.inProgress, currentState)
 	}
 }
+
+// CheckSLA implements TransitionObserver
+func (obs *TransObs) CheckSLA(now time.Time) []SLAViolation {
+	if !obs.enabled {
+		return nil
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+
+	var violations []SLAViolation
+	for endState, inProgressRecords := range obs.inProgress {
+		for i := range inProgressRecords {
+			inProgressRecord := &inProgressRecords[i]
+			if inProgressRecord.violation != nil {
+				continue
+			}
+
+			sla := obs.rules[inProgressRecord.startState][endState]
+			elapsed := now.Sub(inProgressRecord.startTime)
+			if elapsed <= sla {
+				continue
+			}
+
+			key := getRecorderKey(inProgressRecord.startState, endState)
+			violation := &SLAViolation{
+				MesosTaskID:   inProgressRecord.mesosTaskID,
+				From:          inProgressRecord.startState,
+				To:            endState,
+				SLA:           sla,
+				Elapsed:       elapsed,
+				InProgress:    true,
+				DetectionTime: now,
+				Actions:       obs.actions[key],
+			}
+			inProgressRecord.violation = violation
+			obs.addViolation(violation)
+			violations = append(violations, *violation)
+		}
+	}
+	return violations
+}
+
+// Violations implements TransitionObserver
+func (obs *TransObs) Violations() []SLAViolation {
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+
+	violations := make([]SLAViolation, 0, len(obs.violations))
+	for _, violation := range obs.violations {
+		violations = append(violations, *violation)
+	}
+	return violations
+}
+
+// addViolation records a violation, dropping the oldest violation if
+// too many are recorded
+// NB: Acquire lock before calling
+func (obs *TransObs) addViolation(violation *SLAViolation) {
+	obs.violations = append(obs.violations, violation)
+	if len(obs.violations) > _maxSLAViolations {
+		obs.violations = obs.violations[len(obs.violations)-_maxSLAViolations:]
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+// SLAChecker checks the state transitions in progress of the active tasks
+// for SLA violations, and takes the actions of the violated SLA rules.
+// Violations of completed transitions are recorded as the tasks transit.
+type SLAChecker struct {
+	lifeCycle   lifecycle.LifeCycle
+	tracker     activeTasksTracker
+	gangs       GangRequeuer
+	checkPeriod time.Duration
+	metrics     *Metrics
+}
+
+// GangRequeuer requeues the tasks which are being placed as a gang.
+type GangRequeuer interface {
+	// RequeueGangOf returns the gang of a task being placed for placement
+	// again, and drops the placements held for the gang. It returns false
+	// if the task is not being placed as part of a gang.
+	RequeueGangOf(taskID *peloton.TaskID, reason string) bool
+}
+
+// NewSLAChecker returns a new SLA checker
+func NewSLAChecker(
+	tracker activeTasksTracker,
+	gangs GangRequeuer,
+	parent tally.Scope,
+	checkPeriod time.Duration,
+) *SLAChecker {
+	return &SLAChecker{
+		tracker:     tracker,
+		gangs:       gangs,
+		checkPeriod: checkPeriod,
+		metrics:     NewMetrics(parent.SubScope("instance")),
+		lifeCycle:   lifecycle.NewLifeCycle(),
+	}
+}
+
+// Start starts the SLA checker
+func (c *SLAChecker) Start() error {
+	if c.checkPeriod == 0 {
+		log.Info("SLA check period is not set, not starting SLA Checker")
+		return nil
+	}
+
+	if !c.lifeCycle.Start() {
+		log.Warn(
+			"SLA Checker is already running, no action will be performed")
+		return nil
+	}
+
+	go func() {
+		defer c.lifeCycle.StopComplete()
+
+		ticker := time.NewTicker(c.checkPeriod)
+		defer ticker.Stop()
+
+		log.Info("Starting SLA Checker")
+		for {
+			select {
+			case <-c.lifeCycle.StopCh():
+				log.Info("Exiting SLA Checker")
+				return
+			case <-ticker.C:
+			}
+
+			c.run(time.Now())
+		}
+	}()
+	return nil
+}
+
+// run checks the SLAs of all the active tasks at now
+func (c *SLAChecker) run(now time.Time) {
+	violations := 0
+	for _, tasks := range c.tracker.GetActiveTasks("", "", nil) {
+		for _, t := range tasks {
+			violations += len(t.CheckSLA(now, c.gangs))
+		}
+	}
+	c.metrics.SLAViolations.Inc(int64(violations))
+}
+
+// Stop stops the SLA checker
+func (c *SLAChecker) Stop() error {
+	if !c.lifeCycle.Stop() {
+		log.Warn("SLA Checker is already stopped, no action will be performed")
+		return nil
+	}
+
+	// Wait for SLA checker to be stopped
+	c.lifeCycle.Wait()
+	log.Info("SLA Checker Stopped")
+	return nil
+}
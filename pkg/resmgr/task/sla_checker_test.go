@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+func TestSLAChecker_Run(t *testing.T) {
+	obs := NewTransitionObserver(
+		true,
+		tally.NoopScope,
+		"/infra",
+		[]*SLARuleConfig{
+			{From: "PENDING", To: "READY", SLA: time.Minute},
+		},
+		nil,
+	)
+	obs.Observe("mesos-task-1", task.TaskState_PENDING)
+
+	ft := &fakeActiveTasksTracker{
+		tasks: map[string][]*RMTask{task.TaskState_PENDING.String(): {
+			{
+				task: &resmgr.Task{
+					Id: &peloton.TaskID{Value: "job-1-0"},
+				},
+				transitionObserver: obs,
+			},
+		}},
+	}
+
+	scope := tally.NewTestScope("", nil)
+	c := NewSLAChecker(ft, nil, scope, time.Minute)
+
+	// the SLA is not violated yet
+	c.run(time.Now())
+	// the SLA is violated
+	c.run(time.Now().Add(2 * time.Minute))
+	// the violation was already reported
+	c.run(time.Now().Add(3 * time.Minute))
+
+	counter, ok := scope.Snapshot().Counters()["instance.sla.violations+"]
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), counter.Value())
+
+	violations := obs.Violations()
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "mesos-task-1", violations[0].MesosTaskID)
+	assert.Equal(t, task.TaskState_PENDING, violations[0].From)
+	assert.Equal(t, task.TaskState_READY, violations[0].To)
+	assert.True(t, violations[0].InProgress)
+}
+
+func TestSLAChecker_Start(t *testing.T) {
+	c := NewSLAChecker(
+		&fakeActiveTasksTracker{},
+		nil,
+		tally.NoopScope,
+		1*time.Minute,
+	)
+
+	defer func() {
+		c.Stop()
+		// Stopping the SLA checker again. Should be no-op
+		assert.NoError(t, c.Stop())
+	}()
+
+	assert.NoError(t, c.Start())
+	assert.NotNil(t, c.lifeCycle.StopCh())
+
+	// Starting the SLA checker again. Should be no-op
+	assert.NoError(t, c.Start())
+}
+
+func TestSLAChecker_StartWithoutPeriod(t *testing.T) {
+	c := NewSLAChecker(&fakeActiveTasksTracker{}, nil, tally.NoopScope, 0)
+	assert.NoError(t, c.Start())
+	assert.NoError(t, c.Stop())
+}
@@ -18,6 +18,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/pkg/resmgr/respool/mocks"
 
@@ -95,6 +96,221 @@ func TestNewTransitionObserver(t *testing.T) {
 		true,
 		tally.NoopScope,
 		mockResPool.GetPath(),
+		nil,
+		nil,
 	)
 	assert.NotNil(t, dto)
 }
+
+func TestValidateSLARules(t *testing.T) {
+	tt := []struct {
+		msg   string
+		rule  *SLARuleConfig
+		valid bool
+	}{
+		{
+			msg: "valid rule",
+			rule: &SLARuleConfig{
+				From:    "READY",
+				To:      "PLACED",
+				SLA:     time.Minute,
+				Actions: []string{SLAActionRequeue, SLAActionAlert},
+			},
+			valid: true,
+		},
+		{
+			msg:  "invalid from state",
+			rule: &SLARuleConfig{From: "FOO", To: "PLACED", SLA: time.Minute},
+		},
+		{
+			msg:  "invalid to state",
+			rule: &SLARuleConfig{From: "READY", To: "FOO", SLA: time.Minute},
+		},
+		{
+			msg:  "zero SLA",
+			rule: &SLARuleConfig{From: "READY", To: "PLACED"},
+		},
+		{
+			msg: "invalid action",
+			rule: &SLARuleConfig{
+				From:    "READY",
+				To:      "PLACED",
+				SLA:     time.Minute,
+				Actions: []string{"restart"},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		err := ValidateSLARules([]*SLARuleConfig{test.rule})
+		if test.valid {
+			assert.NoError(t, err, test.msg)
+		} else {
+			assert.Error(t, err, test.msg)
+		}
+	}
+}
+
+func TestBuildSLARules(t *testing.T) {
+	configs := []*SLARuleConfig{
+		{
+			From:    "READY",
+			To:      "PLACED",
+			SLA:     10 * time.Minute,
+			Actions: []string{SLAActionAlert},
+		},
+		{
+			From:        "READY",
+			To:          "PLACED",
+			SLA:         5 * time.Minute,
+			RespoolPath: "/infra/",
+			Actions:     []string{SLAActionRequeue},
+		},
+		{
+			From:        "READY",
+			To:          "PLACED",
+			SLA:         time.Minute,
+			RespoolPath: "/infra",
+			JobLabels:   map[string]string{"tier": "critical"},
+			Actions:     []string{SLAActionRaisePriority, SLAActionRequeue},
+		},
+		{
+			From: "PLACING",
+			To:   "LAUNCHING",
+			SLA:  2 * time.Minute,
+		},
+	}
+	key := getRecorderKey(task.TaskState_READY, task.TaskState_PLACED)
+	critical := []*peloton.Label{{Key: "tier", Value: "critical"}}
+
+	tt := []struct {
+		msg         string
+		respoolPath string
+		jobLabels   []*peloton.Label
+		sla         time.Duration
+		actions     []string
+	}{
+		{
+			msg:         "rule without filter",
+			respoolPath: "/batch",
+			jobLabels:   critical,
+			sla:         10 * time.Minute,
+			actions:     []string{SLAActionAlert},
+		},
+		{
+			msg:         "rule of the parent resource pool",
+			respoolPath: "/infra/compute",
+			sla:         5 * time.Minute,
+			actions:     []string{SLAActionRequeue},
+		},
+		{
+			msg:         "rule with job labels",
+			respoolPath: "/infra/compute",
+			jobLabels:   critical,
+			sla:         time.Minute,
+			actions:     []string{SLAActionRaisePriority, SLAActionRequeue},
+		},
+		{
+			msg:         "resource pool sharing a prefix",
+			respoolPath: "/infrastructure",
+			jobLabels:   critical,
+			sla:         10 * time.Minute,
+			actions:     []string{SLAActionAlert},
+		},
+	}
+
+	for _, test := range tt {
+		rules, actions := buildSLARules(configs, test.respoolPath, test.jobLabels)
+		assert.Equal(t, test.sla,
+			rules[task.TaskState_READY][task.TaskState_PLACED], test.msg)
+		assert.Equal(t, test.actions, actions[key], test.msg)
+		// the default SLAs still apply to the other transitions
+		assert.Equal(t, _readyToRunning,
+			rules[task.TaskState_READY][task.TaskState_RUNNING], test.msg)
+		assert.Equal(t, 2*time.Minute,
+			rules[task.TaskState_PLACING][task.TaskState_LAUNCHING], test.msg)
+	}
+
+	rules, actions := buildSLARules(nil, "/infra", critical)
+	assert.Equal(t, defaultRules, rules)
+	assert.Empty(t, actions)
+}
+
+func TestTransObs_CheckSLA(t *testing.T) {
+	obs := NewTransitionObserver(
+		true,
+		tally.NoopScope,
+		"/infra",
+		[]*SLARuleConfig{
+			{
+				From:    "READY",
+				To:      "PLACED",
+				SLA:     time.Millisecond,
+				Actions: []string{SLAActionRequeue},
+			},
+		},
+		nil,
+	)
+
+	obs.Observe("mesos-task-1", task.TaskState_READY)
+	assert.Empty(t, obs.CheckSLA(time.Now()))
+
+	violations := obs.CheckSLA(time.Now().Add(time.Minute))
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "mesos-task-1", violations[0].MesosTaskID)
+	assert.Equal(t, task.TaskState_READY, violations[0].From)
+	assert.Equal(t, task.TaskState_PLACED, violations[0].To)
+	assert.Equal(t, time.Millisecond, violations[0].SLA)
+	assert.True(t, violations[0].InProgress)
+	assert.Equal(t, []string{SLAActionRequeue}, violations[0].Actions)
+
+	// the violation is only reported once
+	assert.Empty(t, obs.CheckSLA(time.Now().Add(2*time.Minute)))
+
+	// the violation is completed when the task reaches the end state
+	obs.Observe("mesos-task-1", task.TaskState_PLACED)
+	violations = obs.Violations()
+	assert.Len(t, violations, 1)
+	assert.False(t, violations[0].InProgress)
+}
+
+func TestTransObs_ViolationsAfterTransition(t *testing.T) {
+	obs := NewTransitionObserver(
+		true,
+		tally.NoopScope,
+		"/infra",
+		[]*SLARuleConfig{
+			{From: "READY", To: "PLACED", SLA: time.Nanosecond},
+		},
+		nil,
+	)
+
+	for i := 0; i < _maxSLAViolations+2; i++ {
+		obs.Observe("mesos-task-1", task.TaskState_READY)
+		time.Sleep(time.Millisecond)
+		obs.Observe("mesos-task-1", task.TaskState_PLACED)
+	}
+
+	violations := obs.Violations()
+	assert.Len(t, violations, _maxSLAViolations)
+	for _, violation := range violations {
+		assert.False(t, violation.InProgress)
+		assert.Empty(t, violation.Actions)
+	}
+}
+
+func TestTransObs_Disabled(t *testing.T) {
+	obs := NewTransitionObserver(
+		false,
+		tally.NoopScope,
+		"/infra",
+		[]*SLARuleConfig{
+			{From: "READY", To: "PLACED", SLA: time.Nanosecond},
+		},
+		nil,
+	)
+
+	obs.Observe("mesos-task-1", task.TaskState_READY)
+	assert.Empty(t, obs.CheckSLA(time.Now().Add(time.Minute)))
+	assert.Empty(t, obs.Violations())
+}
@@ -16,6 +16,7 @@ package config
 
 import (
 	"github.com/uber/peloton/pkg/storage/cassandra"
+	"github.com/uber/peloton/pkg/storage/encryption"
 )
 
 // Config contains the different DB config values for each
@@ -25,4 +26,8 @@ type Config struct {
 	UseCassandra       bool             `yaml:"use_cassandra"`
 	AutoMigrate        bool             `yaml:"auto_migrate"`
 	DbWriteConcurrency int              `yaml:"db_write_concurrency"`
+
+	// SecretEncryption is the config of the encryption of the
+	// secrets at rest
+	SecretEncryption encryption.Config `yaml:"secret_encryption"`
 }
@@ -532,6 +532,28 @@ func (c *cassandraConnector) Update(
 	row []base.Column,
 	keyCols []base.Column,
 ) error {
+	return c.update(ctx, e, row, keyCols, nil)
+}
+
+// UpdateIf updates an existing row in DB if its columns have the values
+// of condCols. Uses CAS write.
+func (c *cassandraConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	condCols []base.Column,
+) error {
+	return c.update(ctx, e, row, keyCols, condCols)
+}
+
+func (c *cassandraConnector) update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	condCols []base.Column,
+) error {
 
 	// split keyCols into a list of names and values to compose query stmt using
 	// names and use values in the session query call, so the order needs to be
@@ -543,11 +565,15 @@ func (c *cassandraConnector) Update(
 	// maintained.
 	colNames, colValues := splitColumnNameValue(row)
 
+	// split condCols into a list of names and values the same way
+	condColNames, condColValues := splitColumnNameValue(condCols)
+
 	// Prepare update statement
 	stmt, err := UpdateStmt(
 		Table(e.Name),
 		Updates(colNames),
 		Conditions(keyColNames),
+		IfConditions(condColNames),
 	)
 
 	if err != nil {
@@ -556,17 +582,35 @@ func (c *cassandraConnector) Update(
 
 	// list of values to be supplied in the query
 	updateVals := append(colValues, keyColValues...)
+	updateVals = append(updateVals, condColValues...)
+
+	operation := update
+	casWrite := len(condCols) > 0
+	if casWrite {
+		operation = cas
+	}
 
 	q := c.Session.Query(
 		stmt, updateVals...).WithContext(ctx)
 
-	if err := q.Exec(); err != nil {
-		sendCounters(c.executeFailScope, e.Name, update, err)
-		return err
+	if casWrite {
+		applied, err := q.MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			sendCounters(c.executeFailScope, e.Name, operation, err)
+			return err
+		}
+		if !applied {
+			return yarpcerrors.AbortedErrorf("item was modified")
+		}
+	} else {
+		if err := q.Exec(); err != nil {
+			sendCounters(c.executeFailScope, e.Name, operation, err)
+			return err
+		}
 	}
 
-	sendLatency(c.scope, e.Name, update, time.Duration(q.Latency()))
-	sendCounters(c.executeSuccessScope, e.Name, update, nil)
+	sendLatency(c.scope, e.Name, operation, time.Duration(q.Latency()))
+	sendCounters(c.executeSuccessScope, e.Name, operation, nil)
 	return nil
 }
 
@@ -485,6 +485,52 @@ func (suite *CassandraConnSuite) TestCreateIfNotExists() {
 	suite.True(yarpcerrors.IsAlreadyExists(err))
 }
 
+// TestUpdateIf tests updating a row only if its columns have given values
+func (suite *CassandraConnSuite) TestUpdateIf() {
+	// Definition stores schema information about an Object
+	obj := &base.Definition{
+		Name: testTableName1,
+		Key: &base.PrimaryKey{
+			PartitionKeys: []string{"id"},
+		},
+		// Column name to data type mapping of the object
+		ColumnToType: map[string]reflect.Type{
+			"id":   reflect.TypeOf(1),
+			"data": reflect.TypeOf("data"),
+			"name": reflect.TypeOf("name"),
+		},
+	}
+	err := connector.Create(context.Background(), obj, testRow)
+	suite.NoError(err)
+
+	row, err := connector.Get(context.Background(), obj, keyRow)
+	suite.NoError(err)
+	name := row["name"].(string)
+
+	testUpdateRow := []base.Column{{Name: "name", Value: "test-update"}}
+
+	// the update is not applied if the name was modified
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		testUpdateRow,
+		keyRow,
+		[]base.Column{{Name: "name", Value: name + "-modified"}})
+	suite.True(yarpcerrors.IsAborted(err))
+
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		testUpdateRow,
+		keyRow,
+		[]base.Column{{Name: "name", Value: name}})
+	suite.NoError(err)
+
+	row, err = connector.Get(context.Background(), obj, keyRow)
+	suite.NoError(err)
+	suite.Equal("test-update", row["name"])
+}
+
 // TestCreateDBFailures tests failures executing DB query
 func (suite *CassandraConnSuite) TestDBFailures() {
 	// Definition stores schema information about an Object
@@ -35,6 +35,9 @@ const (
 	updates = "Updates"
 	// ifNotExist is used to indicate CAS write in the insert query
 	ifNotExist = "IfNotExist"
+	// ifConditions is used to indicate the column conditions of a CAS
+	// write in the update query
+	ifConditions = "IfConditions"
 	// limit is used to indicate the query limit for number of rows.
 	limit = "Limit"
 
@@ -53,7 +56,8 @@ const (
 
 	// updateTemplate is used to construct update query
 	updateTemplate = `UPDATE {{.Table}} SET {{ConditionsFunc .Updates ", "}}` +
-		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}};`
+		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}}` +
+		`{{IfFunc .IfConditions}}{{ConditionsFunc .IfConditions " AND "}};`
 )
 
 var (
@@ -64,6 +68,7 @@ var (
 		"ConditionsFunc": conditionsFunc,
 		"WhereFunc":      whereFunc,
 		"ExistsFunc":     existsFunc,
+		"IfFunc":         ifFunc,
 		"LimitFunc":      limitFunc,
 	}
 
@@ -115,6 +120,14 @@ func existsFunc(exists bool) string {
 	return ""
 }
 
+// ifFunc adds an if clause to the update query
+func ifFunc(conds []string) string {
+	if len(conds) > 0 {
+		return " IF "
+	}
+	return ""
+}
+
 // limitFunc adds a LIMIT clause to the select query.
 func limitFunc(num int) string {
 	if num > 0 {
@@ -175,6 +188,13 @@ func IfNotExist(v interface{}) OptFunc {
 	}
 }
 
+// IfConditions sets the `if` clause to the cql statement
+func IfConditions(v interface{}) OptFunc {
+	return func(opt Option) {
+		opt[ifConditions] = v
+	}
+}
+
 // Limit sets the `limit` to the cql statement.
 func Limit(v interface{}) OptFunc {
 	return func(opt Option) {
@@ -220,7 +240,9 @@ func DeleteStmt(opts ...OptFunc) (string, error) {
 // UpdateStmt creates update statement
 func UpdateStmt(opts ...OptFunc) (string, error) {
 	var bb bytes.Buffer
-	option := Option{}
+	option := Option{
+		ifConditions: []string{},
+	}
 	for _, opt := range opts {
 		opt(option)
 	}
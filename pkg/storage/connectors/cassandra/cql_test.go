@@ -206,3 +206,17 @@ func (suite *CassandraConnSuite) TestUpdateStmt() {
 		suite.Equal(stmt, d.stmt)
 	}
 }
+
+// TestUpdateIfStmt tests constructing the update statement of a CAS write
+func (suite *CassandraConnSuite) TestUpdateIfStmt() {
+	stmt, err := UpdateStmt(
+		Table("table1"),
+		Updates([]string{"c1", "c2"}),
+		Conditions([]string{"c3"}),
+		IfConditions([]string{"c1", "c4"}),
+	)
+	suite.NoError(err)
+	suite.Equal(
+		"UPDATE \"table1\" SET c1=?, c2=? WHERE c3=? IF c1=? AND c4=?;",
+		stmt)
+}
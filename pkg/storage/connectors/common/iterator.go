@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+)
+
+// rowIterator implements interface Iterator for rows already read in memory
+type rowIterator struct {
+	tableDef       *base.Definition
+	colNamesToRead []string
+	rows           []map[string]interface{}
+}
+
+// ensure that implementation (rowIterator) satisfies the interface
+var _ orm.Iterator = (*rowIterator)(nil)
+
+// NewRowIterator returns an iterator over the given rows of normalized
+// values. Every row returned by the iterator has the columns in
+// colNamesToRead, with values of the same types as the ones returned by
+// the Cassandra connector iterator.
+func NewRowIterator(
+	e *base.Definition,
+	colNamesToRead []string,
+	rows []map[string]interface{},
+) orm.Iterator {
+	return &rowIterator{
+		tableDef:       e,
+		colNamesToRead: colNamesToRead,
+		rows:           rows,
+	}
+}
+
+func (iter *rowIterator) Close() {
+	iter.rows = nil
+}
+
+func (iter *rowIterator) Next() ([]base.Column, error) {
+	if len(iter.rows) == 0 {
+		return nil, nil
+	}
+
+	result := iter.rows[0]
+	iter.rows = iter.rows[1:]
+
+	row := make([]base.Column, 0, len(iter.colNamesToRead))
+	for _, columnName := range iter.colNamesToRead {
+		row = append(row, base.Column{
+			Name: columnName,
+			Value: toIteratorValue(
+				iter.tableDef.ColumnToType[columnName],
+				result[columnName]),
+		})
+	}
+	return row, nil
+}
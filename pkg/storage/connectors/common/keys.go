@@ -0,0 +1,72 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// IsPrimaryKey returns true if the column is part of the primary key
+func IsPrimaryKey(e *base.Definition, name string) bool {
+	for _, pk := range e.Key.PartitionKeys {
+		if pk == name {
+			return true
+		}
+	}
+	for _, ck := range e.Key.ClusteringKeys {
+		if ck.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePrimaryKey returns an error if the row does not have all the
+// columns of the primary key
+func ValidatePrimaryKey(e *base.Definition, row []base.Column) error {
+	names := make(map[string]struct{}, len(row))
+	for _, column := range row {
+		names[column.Name] = struct{}{}
+	}
+
+	for _, pk := range e.Key.PartitionKeys {
+		if _, ok := names[pk]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"missing partition key %s for table %s", pk, e.Name)
+		}
+	}
+	for _, ck := range e.Key.ClusteringKeys {
+		if _, ok := names[ck.Name]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"missing clustering key %s for table %s", ck.Name, e.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateUpdate returns an error if the columns to be updated include a
+// primary key column, same as Cassandra which does not allow primary key
+// columns to be updated
+func ValidateUpdate(e *base.Definition, row []base.Column) error {
+	for _, column := range row {
+		if IsPrimaryKey(e, column.Name) {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"PRIMARY KEY part %s found in SET part", column.Name)
+		}
+	}
+	return nil
+}
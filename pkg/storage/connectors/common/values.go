@@ -0,0 +1,220 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+var (
+	_timeType           = reflect.TypeOf(time.Time{})
+	_optionalStringType = reflect.TypeOf(&base.OptionalString{})
+	_optionalUInt64Type = reflect.TypeOf(&base.OptionalUInt64{})
+)
+
+// NormalizeValue converts a column value written by the ORM into the type
+// returned by the Cassandra connector on reads, so that storage objects
+// can be transformed the same way irrespective of the connector used.
+// Integer types of up to 32 bits are returned as uint32 and 64 bit integer
+// types are returned as uint64. Byte slices are copied.
+func NormalizeValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return uint32(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return uint32(v.Uint())
+	case reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Uint64:
+		return v.Uint()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok {
+			return append([]byte(nil), b...)
+		}
+	}
+	return value
+}
+
+// ZeroValue returns the value returned by the Cassandra connector on reads
+// for a column of the given type which has not been set.
+func ZeroValue(typ reflect.Type) interface{} {
+	switch typ {
+	case _timeType:
+		return time.Time{}
+	case _optionalStringType:
+		return ""
+	case _optionalUInt64Type:
+		return uint64(0)
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return ""
+	case reflect.Int32, reflect.Uint32, reflect.Int:
+		return uint32(0)
+	case reflect.Int64, reflect.Uint64:
+		return uint64(0)
+	case reflect.Bool:
+		return false
+	case reflect.Slice:
+		return []byte(nil)
+	}
+	return nil
+}
+
+// EqualValues returns true if the two normalized values are equal
+func EqualValues(v1, v2 interface{}) bool {
+	return CompareValues(v1, v2) == 0
+}
+
+// CompareValues compares two normalized values of the same type and returns
+// 0 if v1 == v2, <0 if v1 < v2 and >0 if v1 > v2. Values of different
+// types are ordered by their type names.
+func CompareValues(v1, v2 interface{}) int {
+	switch a := v1.(type) {
+	case string:
+		if b, ok := v2.(string); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case uint32:
+		if b, ok := v2.(uint32); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case uint64:
+		if b, ok := v2.(uint64); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if b, ok := v2.(bool); ok {
+			switch {
+			case a == b:
+				return 0
+			case !a:
+				return -1
+			}
+			return 1
+		}
+	case []byte:
+		if b, ok := v2.([]byte); ok {
+			return bytes.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := v2.(time.Time); ok {
+			switch {
+			case a.Before(b):
+				return -1
+			case a.After(b):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if v1 == nil || v2 == nil {
+		// values which have not been set are ordered first
+		switch {
+		case v1 != nil:
+			return 1
+		case v2 != nil:
+			return -1
+		}
+		return 0
+	}
+
+	t1, t2 := reflect.TypeOf(v1).String(), reflect.TypeOf(v2).String()
+	switch {
+	case t1 < t2:
+		return -1
+	case t1 > t2:
+		return 1
+	}
+	return 0
+}
+
+// toIteratorValue converts a normalized value into the pointer returned by
+// the Cassandra connector iterator for a column of the given type. Columns
+// which have not been set are returned as nil pointers.
+func toIteratorValue(typ reflect.Type, value interface{}) interface{} {
+	switch {
+	case typ == _timeType:
+		if v, ok := value.(time.Time); ok {
+			return &v
+		}
+		return (*time.Time)(nil)
+	case typ == _optionalStringType, typ.Kind() == reflect.String:
+		if v, ok := value.(string); ok {
+			return &v
+		}
+		return (*string)(nil)
+	case typ == _optionalUInt64Type,
+		typ.Kind() == reflect.Int64,
+		typ.Kind() == reflect.Uint64:
+		if v, ok := value.(uint64); ok {
+			i := int64(v)
+			return &i
+		}
+		return (*int64)(nil)
+	case typ.Kind() == reflect.Int32,
+		typ.Kind() == reflect.Uint32,
+		typ.Kind() == reflect.Int:
+		if v, ok := value.(uint32); ok {
+			i := int(v)
+			return &i
+		}
+		return (*int)(nil)
+	case typ.Kind() == reflect.Bool:
+		if v, ok := value.(bool); ok {
+			return &v
+		}
+		return (*bool)(nil)
+	case typ.Kind() == reflect.Slice:
+		if v, ok := value.([]byte); ok {
+			return &v
+		}
+		return (*[]byte)(nil)
+	}
+	return value
+}
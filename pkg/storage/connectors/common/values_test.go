@@ -0,0 +1,107 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ValuesTestSuite struct {
+	suite.Suite
+}
+
+func TestValuesTestSuite(t *testing.T) {
+	suite.Run(t, new(ValuesTestSuite))
+}
+
+// TestNormalizeValue tests that values are converted to the types
+// returned by the Cassandra connector
+func (suite *ValuesTestSuite) TestNormalizeValue() {
+	suite.Equal(uint32(1), NormalizeValue(1))
+	suite.Equal(uint32(1), NormalizeValue(int32(1)))
+	suite.Equal(uint64(1), NormalizeValue(int64(1)))
+	suite.Equal(uint64(1), NormalizeValue(uint64(1)))
+	suite.Equal("test", NormalizeValue("test"))
+	suite.Equal(true, NormalizeValue(true))
+	suite.Nil(NormalizeValue(nil))
+
+	b := []byte("test")
+	n := NormalizeValue(b).([]byte)
+	b[0] = 'x'
+	suite.Equal([]byte("test"), n)
+}
+
+// TestZeroValue tests the values of columns which have not been set
+func (suite *ValuesTestSuite) TestZeroValue() {
+	suite.Equal(time.Time{}, ZeroValue(reflect.TypeOf(time.Time{})))
+	suite.Equal("", ZeroValue(reflect.TypeOf(&base.OptionalString{})))
+	suite.Equal(uint64(0), ZeroValue(reflect.TypeOf(&base.OptionalUInt64{})))
+	suite.Equal(uint32(0), ZeroValue(reflect.TypeOf(1)))
+	suite.Equal(uint64(0), ZeroValue(reflect.TypeOf(int64(1))))
+	suite.Equal(false, ZeroValue(reflect.TypeOf(true)))
+	suite.Equal([]byte(nil), ZeroValue(reflect.TypeOf([]byte{})))
+}
+
+// TestCompareValues tests the ordering of normalized values
+func (suite *ValuesTestSuite) TestCompareValues() {
+	suite.Equal(-1, CompareValues("a", "b"))
+	suite.Equal(1, CompareValues(uint32(2), uint32(1)))
+	suite.Equal(0, CompareValues(uint64(1), uint64(1)))
+	suite.Equal(-1, CompareValues(false, true))
+	suite.Equal(1, CompareValues([]byte("b"), []byte("a")))
+	suite.Equal(-1, CompareValues(time.Unix(1, 0), time.Unix(2, 0)))
+	suite.Equal(-1, CompareValues(nil, "a"))
+	suite.Equal(1, CompareValues("a", nil))
+	suite.True(EqualValues(nil, nil))
+}
+
+// TestRowIterator tests that the iterator returns the values as pointers
+func (suite *ValuesTestSuite) TestRowIterator() {
+	e := &base.Definition{
+		ColumnToType: map[string]reflect.Type{
+			"id":   reflect.TypeOf(1),
+			"name": reflect.TypeOf(""),
+			"size": reflect.TypeOf(&base.OptionalUInt64{}),
+		},
+	}
+	iter := NewRowIterator(e, []string{"id", "name", "size"},
+		[]map[string]interface{}{
+			{"id": uint32(1), "name": "test", "size": uint64(2)},
+			{"id": uint32(2)},
+		})
+	defer iter.Close()
+
+	row, err := iter.Next()
+	suite.NoError(err)
+	suite.Len(row, 3)
+	suite.Equal(1, *row[0].Value.(*int))
+	suite.Equal("test", *row[1].Value.(*string))
+	suite.Equal(int64(2), *row[2].Value.(*int64))
+
+	row, err = iter.Next()
+	suite.NoError(err)
+	suite.Equal(2, *row[0].Value.(*int))
+	suite.Nil(row[1].Value.(*string))
+
+	row, err = iter.Next()
+	suite.NoError(err)
+	suite.Nil(row)
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/connectors/common"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// table holds the rows of a table keyed by their partition key. The rows of
+// every partition are sorted by the clustering keys of the table.
+type table struct {
+	partitions map[string][]map[string]interface{}
+}
+
+type memoryConnector struct {
+	sync.RWMutex
+	// map of table name to the table
+	tables map[string]*table
+}
+
+// NewMemoryConnector initializes a Connector which keeps all the rows in
+// memory. It is meant for tests and single node deployments which do not
+// need the data to survive a restart.
+func NewMemoryConnector() orm.Connector {
+	return &memoryConnector{
+		tables: make(map[string]*table),
+	}
+}
+
+// ensure that implementation (memoryConnector) satisfies the interface
+var _ orm.Connector = (*memoryConnector)(nil)
+
+// CreateIfNotExists creates a new row in DB if it already doesn't exist.
+func (c *memoryConnector) CreateIfNotExists(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.upsert(ctx, e, row, true)
+}
+
+// Create creates a new row in DB, overwriting the columns of the row if it
+// already exists.
+func (c *memoryConnector) Create(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.upsert(ctx, e, row, false)
+}
+
+// Update updates the columns of a row in DB, creating the row if it does
+// not exist.
+func (c *memoryConnector) Update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+) error {
+	if err := common.ValidateUpdate(e, row); err != nil {
+		return err
+	}
+
+	updateRow := append(append([]base.Column{}, keyCols...), row...)
+	return c.upsert(ctx, e, updateRow, false)
+}
+
+// UpdateIf updates the columns of a row in DB if they have the values of
+// condCols. An Aborted error is returned if the row does not exist or its
+// columns have other values.
+func (c *memoryConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	condCols []base.Column,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := common.ValidateUpdate(e, row); err != nil {
+		return err
+	}
+	if err := common.ValidatePrimaryKey(e, keyCols); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	rows := c.matchingRows(e, keyCols)
+	if len(rows) == 0 || !matchesKeys(rows[0], condCols) {
+		return yarpcerrors.AbortedErrorf("item was modified")
+	}
+	for _, column := range row {
+		rows[0][column.Name] = common.NormalizeValue(column.Value)
+	}
+	return nil
+}
+
+func (c *memoryConnector) upsert(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	ifNotExists bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := common.ValidatePrimaryKey(e, row); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(row))
+	for _, column := range row {
+		values[column.Name] = common.NormalizeValue(column.Value)
+	}
+	partitionKey, err := getPartitionKey(e, values)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	t, ok := c.tables[e.Name]
+	if !ok {
+		t = &table{partitions: make(map[string][]map[string]interface{})}
+		c.tables[e.Name] = t
+	}
+
+	rows := t.partitions[partitionKey]
+	for _, r := range rows {
+		if compareClusteringKeys(e, r, values) != 0 {
+			continue
+		}
+		if ifNotExists {
+			return yarpcerrors.AlreadyExistsErrorf("item already exists")
+		}
+		for name, value := range values {
+			r[name] = value
+		}
+		return nil
+	}
+
+	// insert the new row keeping the partition sorted
+	index := sort.Search(len(rows), func(i int) bool {
+		return compareClusteringKeys(e, rows[i], values) > 0
+	})
+	rows = append(rows, nil)
+	copy(rows[index+1:], rows[index:])
+	rows[index] = values
+	t.partitions[partitionKey] = rows
+	return nil
+}
+
+// Get fetches a record from DB using primary keys
+// returns a map describing a row from DB, key is columnName,
+// value is columnValue.
+func (c *memoryConnector) Get(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+	colNamesToRead ...string,
+) (map[string]interface{}, error) {
+	if len(colNamesToRead) == 0 {
+		colNamesToRead = e.GetColumnsToRead()
+	}
+
+	rows, err := c.selectRows(ctx, e, keyCols, colNamesToRead, 1)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// GetAll fetches all rows from DB using partition keys
+// returns an array of map[string]interface{}
+// the key of the map is the columnName, the value of the map is ColumnValue
+func (c *memoryConnector) GetAll(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([]map[string]interface{}, error) {
+	return c.selectRows(ctx, e, keyCols, e.GetColumnsToRead(), 0)
+}
+
+// GetAllIter gives an iterator to fetch all rows from DB. The rows are
+// read when the iterator is created, so changes made afterwards are not
+// visible to the iterator.
+func (c *memoryConnector) GetAllIter(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) (orm.Iterator, error) {
+	colNamesToRead := e.GetColumnsToRead()
+	rows, err := c.selectRows(ctx, e, keyCols, colNamesToRead, 0)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewRowIterator(e, colNamesToRead, rows), nil
+}
+
+// selectRows returns a copy of the rows which match the key columns in
+// clustering order. If limit is non-zero, at most limit rows are returned.
+func (c *memoryConnector) selectRows(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+	colNamesToRead []string,
+	limit int,
+) ([]map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	result := make([]map[string]interface{}, 0)
+	for _, row := range c.matchingRows(e, keyCols) {
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		r := make(map[string]interface{}, len(colNamesToRead))
+		for _, name := range colNamesToRead {
+			value, ok := row[name]
+			if !ok {
+				value = common.ZeroValue(e.ColumnToType[name])
+			}
+			r[name] = common.NormalizeValue(value)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// Delete deletes the records from DB which match the key columns
+func (c *memoryConnector) Delete(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	t, ok := c.tables[e.Name]
+	if !ok {
+		return nil
+	}
+
+	for partitionKey, rows := range t.partitions {
+		var remaining []map[string]interface{}
+		for _, row := range rows {
+			if !matchesKeys(row, keyCols) {
+				remaining = append(remaining, row)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(t.partitions, partitionKey)
+			continue
+		}
+		t.partitions[partitionKey] = remaining
+	}
+	return nil
+}
+
+// matchingRows returns the rows which match the key columns. If all the
+// partition keys are provided only that partition is scanned, otherwise
+// the partitions are scanned in the order of their keys.
+// The caller must hold the lock.
+func (c *memoryConnector) matchingRows(
+	e *base.Definition,
+	keyCols []base.Column,
+) []map[string]interface{} {
+	t, ok := c.tables[e.Name]
+	if !ok {
+		return nil
+	}
+
+	keys := make(map[string]interface{}, len(keyCols))
+	for _, column := range keyCols {
+		keys[column.Name] = common.NormalizeValue(column.Value)
+	}
+
+	var partitionKeys []string
+	if partitionKey, err := getPartitionKey(e, keys); err == nil {
+		partitionKeys = []string{partitionKey}
+	} else {
+		for partitionKey := range t.partitions {
+			partitionKeys = append(partitionKeys, partitionKey)
+		}
+		sort.Strings(partitionKeys)
+	}
+
+	var rows []map[string]interface{}
+	for _, partitionKey := range partitionKeys {
+		for _, row := range t.partitions[partitionKey] {
+			if matchesKeys(row, keyCols) {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// matchesKeys returns true if the row has the values of all key columns
+func matchesKeys(row map[string]interface{}, keyCols []base.Column) bool {
+	for _, column := range keyCols {
+		value, ok := row[column.Name]
+		if !ok ||
+			!common.EqualValues(value, common.NormalizeValue(column.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// getPartitionKey returns the string representation of the partition key
+// of the given normalized values
+func getPartitionKey(
+	e *base.Definition,
+	values map[string]interface{},
+) (string, error) {
+	parts := make([]string, 0, len(e.Key.PartitionKeys))
+	for _, pk := range e.Key.PartitionKeys {
+		value, ok := values[pk]
+		if !ok {
+			return "", yarpcerrors.InvalidArgumentErrorf(
+				"missing partition key %s for table %s", pk, e.Name)
+		}
+		parts = append(parts, keyString(value))
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// keyString returns an unambiguous string representation of a
+// normalized key value
+func keyString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return hex.EncodeToString(v)
+	case time.Time:
+		return strconv.FormatInt(v.UnixNano(), 10)
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// compareClusteringKeys compares the clustering keys of the two rows in the
+// clustering order of the table
+func compareClusteringKeys(
+	e *base.Definition,
+	row1, row2 map[string]interface{},
+) int {
+	for _, ck := range e.Key.ClusteringKeys {
+		r := common.CompareValues(row1[ck.Name], row2[ck.Name])
+		if ck.Descending {
+			r = -r
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	return 0
+}
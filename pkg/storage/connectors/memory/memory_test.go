@@ -0,0 +1,333 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testTable has primary key "id" and clustering key "ck"
+var testTable = &base.Definition{
+	Name: "test_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{
+				Name:       "ck",
+				Descending: true,
+			},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":   reflect.TypeOf(""),
+		"ck":   reflect.TypeOf(1),
+		"name": reflect.TypeOf(""),
+		"data": reflect.TypeOf([]byte{}),
+		"size": reflect.TypeOf(uint64(1)),
+	},
+}
+
+// testRow returns a row of the test table
+func testRow(id string, ck int, name string) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+		{Name: "name", Value: name},
+		{Name: "data", Value: []byte(name)},
+	}
+}
+
+// testKeys returns the primary key columns of a row of the test table
+func testKeys(id string, ck int) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+	}
+}
+
+type MemoryConnectorSuite struct {
+	suite.Suite
+
+	ctx       context.Context
+	connector orm.Connector
+}
+
+func (suite *MemoryConnectorSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.connector = NewMemoryConnector()
+}
+
+func TestMemoryConnectorSuite(t *testing.T) {
+	suite.Run(t, new(MemoryConnectorSuite))
+}
+
+// TestCreateGetDelete tests creating, reading and deleting a row
+func (suite *MemoryConnectorSuite) TestCreateGetDelete() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal(map[string]interface{}{
+		"id":   "id1",
+		"ck":   uint32(1),
+		"name": "test",
+		"data": []byte("test"),
+		"size": uint64(0),
+	}, row)
+
+	// read the partial row
+	row, err = suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1), "id", "name")
+	suite.NoError(err)
+	suite.Len(row, 2)
+	suite.Equal("test", row["name"])
+
+	suite.NoError(suite.connector.Delete(
+		suite.ctx, testTable, testKeys("id1", 1)))
+
+	row, err = suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Nil(row)
+
+	// deleting a row which does not exist is a noop
+	suite.NoError(suite.connector.Delete(
+		suite.ctx, testTable, testKeys("id1", 1)))
+}
+
+// TestCreateIfNotExists tests that an existing row is not overwritten
+func (suite *MemoryConnectorSuite) TestCreateIfNotExists() {
+	suite.NoError(suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	err := suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow("id1", 1, "test-new"))
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test", row["name"])
+}
+
+// TestCreateMissingKey tests that rows without all the primary key
+// columns are rejected
+func (suite *MemoryConnectorSuite) TestCreateMissingKey() {
+	suite.Error(suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "ck", Value: 1},
+	}))
+	suite.Error(suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	}))
+}
+
+// TestCreateUpdateGet tests that update only changes the given columns
+func (suite *MemoryConnectorSuite) TestCreateUpdateGet() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "name", Value: "test-update"}},
+		testKeys("id1", 1)))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test-update", row["name"])
+	suite.Equal([]byte("test"), row["data"])
+
+	// primary key columns can not be updated
+	err = suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "ck", Value: 2}},
+		testKeys("id1", 1))
+	suite.Error(err)
+
+	// updating a row which does not exist creates it
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "size", Value: uint64(10)}},
+		testKeys("id2", 1)))
+	row, err = suite.connector.Get(suite.ctx, testTable, testKeys("id2", 1))
+	suite.NoError(err)
+	suite.Equal(uint64(10), row["size"])
+	suite.Equal("", row["name"])
+}
+
+// TestUpdateIf tests that a conditional update is only applied to an
+// existing row with the expected values
+func (suite *MemoryConnectorSuite) TestUpdateIf() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+	update := []base.Column{{Name: "name", Value: "test-update"}}
+
+	err := suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id1", 1),
+		[]base.Column{{Name: "name", Value: "other"}})
+	suite.True(yarpcerrors.IsAborted(err))
+
+	// a row which does not exist is not created
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id2", 1),
+		[]base.Column{{Name: "name", Value: "test"}})
+	suite.True(yarpcerrors.IsAborted(err))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id1", 1),
+		[]base.Column{{Name: "name", Value: "test"}}))
+	row, err := suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test-update", row["name"])
+	suite.Equal([]byte("test"), row["data"])
+}
+
+// TestGetAll tests reading all rows of a partition in clustering order
+func (suite *MemoryConnectorSuite) TestGetAll() {
+	for _, ck := range []int{2, 3, 1} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow("id1", ck, "test")))
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id2", 1, "test")))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	// clustering key is in descending order
+	for i, ck := range []uint32{3, 2, 1} {
+		suite.Equal(ck, rows[i]["ck"])
+	}
+
+	// Get returns the first row in clustering order
+	row, err := suite.connector.Get(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	suite.Equal(uint32(3), row["ck"])
+
+	// no partition key reads all the partitions
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, nil)
+	suite.NoError(err)
+	suite.Len(rows, 4)
+
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id3"},
+	})
+	suite.NoError(err)
+	suite.NotNil(rows)
+	suite.Len(rows, 0)
+}
+
+// TestGetAllIter tests reading the rows using an iterator
+func (suite *MemoryConnectorSuite) TestGetAllIter() {
+	for _, ck := range []int{1, 2} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow("id1", ck, "test")))
+	}
+
+	iter, err := suite.connector.GetAllIter(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	defer iter.Close()
+
+	var cks []int
+	for {
+		row, err := iter.Next()
+		suite.NoError(err)
+		if row == nil {
+			break
+		}
+		for _, col := range row {
+			switch col.Name {
+			case "ck":
+				cks = append(cks, *col.Value.(*int))
+			case "name":
+				suite.Equal("test", *col.Value.(*string))
+			case "size":
+				suite.Equal(int64(0), *col.Value.(*int64))
+			}
+		}
+	}
+	suite.Equal([]int{2, 1}, cks)
+}
+
+// TestDeletePartition tests deleting all the rows of a partition
+func (suite *MemoryConnectorSuite) TestDeletePartition() {
+	for _, ck := range []int{1, 2} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow("id1", ck, "test")))
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id2", 1, "test")))
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	}))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, nil)
+	suite.NoError(err)
+	suite.Len(rows, 1)
+	suite.Equal("id2", rows[0]["id"])
+}
+
+// TestReadIsCopy tests that modifying the rows read does not modify the
+// rows stored
+func (suite *MemoryConnectorSuite) TestReadIsCopy() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	row["data"].([]byte)[0] = 'x'
+	row["name"] = "changed"
+
+	row, err = suite.connector.Get(suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test", row["name"])
+	suite.Equal([]byte("test"), row["data"])
+}
+
+// TestCanceledContext tests that operations fail on a canceled context
+func (suite *MemoryConnectorSuite) TestCanceledContext() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+
+	suite.Error(suite.connector.Create(ctx, testTable, testRow("id1", 1, "test")))
+	_, err := suite.connector.GetAll(ctx, testTable, nil)
+	suite.Error(err)
+	suite.Error(suite.connector.Delete(ctx, testTable, testKeys("id1", 1)))
+}
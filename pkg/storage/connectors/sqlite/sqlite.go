@@ -0,0 +1,668 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/connectors/common"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	_ "github.com/mattn/go-sqlite3" // Pull in the SQLite driver for database/sql
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// operation tags for metrics
+	create  = "create"
+	cas     = "cas"
+	get     = "get"
+	getAll  = "get_all"
+	getIter = "get_iter"
+	update  = "update"
+	del     = "delete"
+
+	// default limit for select statements.
+	_defaultQueryLimit = 1
+	_ignoredQueryLimit = 0
+)
+
+var _timeType = reflect.TypeOf(time.Time{})
+
+type sqliteConnector struct {
+	// db is the handle to the SQLite database
+	db *sql.DB
+	// scope is the storage scope for metrics
+	scope tally.Scope
+	// scope is the storage scope for success metrics
+	executeSuccessScope tally.Scope
+	// scope is the storage scope for failure metrics
+	executeFailScope tally.Scope
+
+	sync.Mutex
+	// columns of the tables which have been created in the database
+	tables map[string]map[string]bool
+}
+
+// NewSQLiteConnector initializes a Connector which stores the rows in an
+// embedded SQLite database. Tables are created from the storage object
+// definitions the first time they are used, so no migrations need to be
+// run before using the connector.
+func NewSQLiteConnector(
+	config *Config,
+	scope tally.Scope,
+) (orm.Connector, error) {
+	db, err := sql.Open("sqlite3", config.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open SQLite database")
+	}
+	// SQLite allows a single writer at a time, and an in-memory database
+	// is private to the connection which created it
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to open SQLite database")
+	}
+
+	storeScope := scope.SubScope("sqlite")
+	return &sqliteConnector{
+		db:    db,
+		scope: storeScope,
+		executeSuccessScope: storeScope.Tagged(
+			map[string]string{"result": "success"}),
+		executeFailScope: storeScope.Tagged(
+			map[string]string{"result": "fail"}),
+		tables: make(map[string]map[string]bool),
+	}, nil
+}
+
+// ensure that implementation (sqliteConnector) satisfies the interface
+var _ orm.Connector = (*sqliteConnector)(nil)
+
+// CreateIfNotExists creates a new row in DB if it already doesn't exist.
+func (c *sqliteConnector) CreateIfNotExists(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.upsert(ctx, e, row, cas)
+}
+
+// Create creates a new row in DB, overwriting the columns of the row if it
+// already exists.
+func (c *sqliteConnector) Create(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.upsert(ctx, e, row, create)
+}
+
+// Update updates the columns of a row in DB, creating the row if it does
+// not exist.
+func (c *sqliteConnector) Update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+) error {
+	if err := common.ValidateUpdate(e, row); err != nil {
+		return err
+	}
+
+	updateRow := append(append([]base.Column{}, keyCols...), row...)
+	return c.upsert(ctx, e, updateRow, update)
+}
+
+// UpdateIf updates the columns of a row in DB if they have the values of
+// condCols. An Aborted error is returned if the row does not exist or its
+// columns have other values.
+func (c *sqliteConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	condCols []base.Column,
+) error {
+	if err := common.ValidateUpdate(e, row); err != nil {
+		return err
+	}
+	if err := common.ValidatePrimaryKey(e, keyCols); err != nil {
+		return err
+	}
+
+	if err := c.ensureTable(ctx, e); err != nil {
+		sendCounters(c.executeFailScope, e.Name, cas, err)
+		return err
+	}
+
+	var updates []string
+	var values []interface{}
+	for _, column := range row {
+		updates = append(updates, quote(column.Name)+"=?")
+		values = append(values, toSQLValue(column.Value))
+	}
+	where, whereValues := conditions(
+		append(append([]base.Column{}, keyCols...), condCols...))
+	stmt := fmt.Sprintf(
+		"UPDATE %s SET %s%s",
+		quote(e.Name),
+		strings.Join(updates, ", "),
+		where)
+
+	start := time.Now()
+	result, err := c.db.ExecContext(
+		ctx, stmt, append(values, whereValues...)...)
+	if err != nil {
+		sendCounters(c.executeFailScope, e.Name, cas, err)
+		return err
+	}
+	applied, err := result.RowsAffected()
+	if err != nil {
+		sendCounters(c.executeFailScope, e.Name, cas, err)
+		return err
+	}
+	if applied == 0 {
+		return yarpcerrors.AbortedErrorf("item was modified")
+	}
+
+	sendLatency(c.scope, e.Name, cas, time.Since(start))
+	sendCounters(c.executeSuccessScope, e.Name, cas, nil)
+	return nil
+}
+
+// upsert inserts the row, or updates the columns of the row if it already
+// exists. For the cas operation an existing row is left unchanged and an
+// AlreadyExists error is returned.
+func (c *sqliteConnector) upsert(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	operation string,
+) error {
+	if err := common.ValidatePrimaryKey(e, row); err != nil {
+		return err
+	}
+
+	if err := c.ensureTable(ctx, e); err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return err
+	}
+
+	var colNames, placeholders, updates []string
+	var colValues []interface{}
+	for _, column := range row {
+		colNames = append(colNames, quote(column.Name))
+		placeholders = append(placeholders, "?")
+		colValues = append(colValues, toSQLValue(column.Value))
+		if !common.IsPrimaryKey(e, column.Name) {
+			updates = append(updates, fmt.Sprintf(
+				"%s=excluded.%s", quote(column.Name), quote(column.Name)))
+		}
+	}
+
+	conflict := "DO NOTHING"
+	if operation != cas && len(updates) > 0 {
+		conflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	stmt := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) %s",
+		quote(e.Name),
+		strings.Join(colNames, ", "),
+		strings.Join(placeholders, ", "),
+		strings.Join(primaryKeyColumns(e), ", "),
+		conflict)
+
+	start := time.Now()
+	result, err := c.db.ExecContext(ctx, stmt, colValues...)
+	if err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return err
+	}
+
+	if operation == cas {
+		applied, err := result.RowsAffected()
+		if err != nil {
+			sendCounters(c.executeFailScope, e.Name, operation, err)
+			return err
+		}
+		if applied == 0 {
+			return yarpcerrors.AlreadyExistsErrorf("item already exists")
+		}
+	}
+
+	sendLatency(c.scope, e.Name, operation, time.Since(start))
+	sendCounters(c.executeSuccessScope, e.Name, operation, nil)
+	return nil
+}
+
+// Get fetches a record from DB using primary keys
+// returns a map describing a row from DB, key is columnName,
+// value is columnValue.
+func (c *sqliteConnector) Get(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+	colNamesToRead ...string,
+) (map[string]interface{}, error) {
+	if len(colNamesToRead) == 0 {
+		colNamesToRead = e.GetColumnsToRead()
+	}
+
+	result, err := c.selectRows(
+		ctx, e, keyCols, colNamesToRead, _defaultQueryLimit, get)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return result[0], nil
+}
+
+// GetAll fetches all rows from DB using partition keys
+// returns an array of map[string]interface{}
+// the key of the map is the columnName, the value of the map is ColumnValue
+func (c *sqliteConnector) GetAll(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([]map[string]interface{}, error) {
+	return c.selectRows(
+		ctx, e, keyCols, e.GetColumnsToRead(), _ignoredQueryLimit, getAll)
+}
+
+// GetAllIter gives an iterator to fetch all rows from DB. The rows are
+// read when the iterator is created, since the database connection can
+// not be shared with other queries while the rows are being read.
+func (c *sqliteConnector) GetAllIter(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) (orm.Iterator, error) {
+	colNamesToRead := e.GetColumnsToRead()
+	result, err := c.selectRows(
+		ctx, e, keyCols, colNamesToRead, _ignoredQueryLimit, getIter)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewRowIterator(e, colNamesToRead, result), nil
+}
+
+// selectRows reads the rows which match the key columns in clustering
+// order. If limit is non-zero, at most limit rows are returned.
+func (c *sqliteConnector) selectRows(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+	colNamesToRead []string,
+	limit int,
+	operation string,
+) ([]map[string]interface{}, error) {
+	if err := c.ensureTable(ctx, e); err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(colNamesToRead))
+	for _, name := range colNamesToRead {
+		columns = append(columns, quote(name))
+	}
+
+	var order []string
+	for _, pk := range e.Key.PartitionKeys {
+		order = append(order, quote(pk))
+	}
+	for _, ck := range e.Key.ClusteringKeys {
+		if ck.Descending {
+			order = append(order, quote(ck.Name)+" DESC")
+		} else {
+			order = append(order, quote(ck.Name))
+		}
+	}
+
+	where, whereValues := conditions(keyCols)
+	stmt := fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s",
+		strings.Join(columns, ", "),
+		quote(e.Name),
+		where,
+		strings.Join(order, ", "))
+	if limit > 0 {
+		stmt += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	start := time.Now()
+	rows, err := c.db.QueryContext(ctx, stmt, whereValues...)
+	if err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		dest := make([]interface{}, len(colNamesToRead))
+		for i := range dest {
+			dest[i] = new(interface{})
+		}
+		if err := rows.Scan(dest...); err != nil {
+			sendCounters(c.executeFailScope, e.Name, operation, err)
+			return nil, errors.Wrap(err, "Scan failed")
+		}
+
+		row := make(map[string]interface{}, len(colNamesToRead))
+		for i, name := range colNamesToRead {
+			row[name] = fromSQLValue(
+				e.ColumnToType[name], *(dest[i].(*interface{})))
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return nil, err
+	}
+
+	sendLatency(c.scope, e.Name, operation, time.Since(start))
+	sendCounters(c.executeSuccessScope, e.Name, operation, nil)
+	return result, nil
+}
+
+// Delete deletes the records from DB which match the key columns
+func (c *sqliteConnector) Delete(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) error {
+	if err := c.ensureTable(ctx, e); err != nil {
+		sendCounters(c.executeFailScope, e.Name, del, err)
+		return err
+	}
+
+	where, whereValues := conditions(keyCols)
+	stmt := fmt.Sprintf("DELETE FROM %s%s", quote(e.Name), where)
+
+	start := time.Now()
+	if _, err := c.db.ExecContext(ctx, stmt, whereValues...); err != nil {
+		sendCounters(c.executeFailScope, e.Name, del, err)
+		return err
+	}
+
+	sendLatency(c.scope, e.Name, del, time.Since(start))
+	sendCounters(c.executeSuccessScope, e.Name, del, nil)
+	return nil
+}
+
+// ensureTable creates the table of the storage object if it does not exist,
+// and adds the columns of the object which are missing in the table.
+func (c *sqliteConnector) ensureTable(
+	ctx context.Context,
+	e *base.Definition,
+) error {
+	c.Lock()
+	defer c.Unlock()
+
+	var names []string
+	var missing bool
+	for name := range e.ColumnToType {
+		names = append(names, name)
+		if !c.tables[e.Name][name] {
+			missing = true
+		}
+	}
+	if !missing {
+		return nil
+	}
+	sort.Strings(names)
+
+	var columns []string
+	for _, name := range names {
+		columns = append(columns, fmt.Sprintf(
+			"%s %s", quote(name), columnType(e.ColumnToType[name])))
+	}
+
+	stmt := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s, PRIMARY KEY (%s))",
+		quote(e.Name),
+		strings.Join(columns, ", "),
+		strings.Join(primaryKeyColumns(e), ", "))
+	if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+		return errors.Wrapf(err, "failed to create table %s", e.Name)
+	}
+
+	existing, err := c.tableColumns(ctx, e.Name)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if existing[name] {
+			continue
+		}
+		stmt := fmt.Sprintf(
+			"ALTER TABLE %s ADD COLUMN %s %s",
+			quote(e.Name),
+			quote(name),
+			columnType(e.ColumnToType[name]))
+		if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err,
+				"failed to add column %s to table %s", name, e.Name)
+		}
+		existing[name] = true
+	}
+
+	c.tables[e.Name] = existing
+	return nil
+}
+
+// tableColumns returns the names of the columns of the table
+func (c *sqliteConnector) tableColumns(
+	ctx context.Context,
+	table string,
+) (map[string]bool, error) {
+	rows, err := c.db.QueryContext(
+		ctx, fmt.Sprintf("PRAGMA table_info(%s)", quote(table)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read table %s", table)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var defaultValue interface{}
+		if err := rows.Scan(
+			&cid, &name, &typ, &notNull, &defaultValue, &pk); err != nil {
+			return nil, errors.Wrapf(err, "failed to read table %s", table)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// primaryKeyColumns returns the quoted names of the primary key columns
+func primaryKeyColumns(e *base.Definition) []string {
+	var columns []string
+	for _, pk := range e.Key.PartitionKeys {
+		columns = append(columns, quote(pk))
+	}
+	for _, ck := range e.Key.ClusteringKeys {
+		columns = append(columns, quote(ck.Name))
+	}
+	return columns
+}
+
+// conditions returns the WHERE clause matching the key columns along with
+// the values of the clause
+func conditions(keyCols []base.Column) (string, []interface{}) {
+	if len(keyCols) == 0 {
+		return "", nil
+	}
+
+	var clauses []string
+	var values []interface{}
+	for _, column := range keyCols {
+		clauses = append(clauses, quote(column.Name)+"=?")
+		values = append(values, toSQLValue(column.Value))
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), values
+}
+
+// columnType returns the SQLite type of a column of the given type
+func columnType(typ reflect.Type) string {
+	if typ == _timeType {
+		// stored as nanoseconds since epoch
+		return "INTEGER"
+	}
+
+	switch common.ZeroValue(typ).(type) {
+	case string:
+		return "TEXT"
+	case uint32, bool:
+		return "INTEGER"
+	}
+	// uint64 values are stored as blobs, see toSQLValue
+	return "BLOB"
+}
+
+// toSQLValue converts a column value written by the ORM into the value
+// stored in SQLite. 32 bit unsigned integers are stored as signed 64 bit
+// integers, 64 bit unsigned integers as 8 bytes big endian blobs since
+// SQLite integers are signed and blobs are compared bytewise, and times
+// are stored as nanoseconds since epoch.
+func toSQLValue(value interface{}) interface{} {
+	switch v := common.NormalizeValue(value).(type) {
+	case uint32:
+		return int64(v)
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return b
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.UnixNano()
+	default:
+		return v
+	}
+}
+
+// fromSQLValue converts a value read from SQLite into the type returned by
+// the Cassandra connector for a column of the given type
+func fromSQLValue(typ reflect.Type, value interface{}) interface{} {
+	if typ == _timeType {
+		if n, ok := value.(int64); ok {
+			return time.Unix(0, n).UTC()
+		}
+		return time.Time{}
+	}
+
+	switch common.ZeroValue(typ).(type) {
+	case string:
+		switch v := value.(type) {
+		case string:
+			return v
+		case []byte:
+			return string(v)
+		}
+		return ""
+	case uint32:
+		n, _ := value.(int64)
+		return uint32(n)
+	case uint64:
+		if b, ok := value.([]byte); ok && len(b) == 8 {
+			return binary.BigEndian.Uint64(b)
+		}
+		return uint64(0)
+	case bool:
+		n, _ := value.(int64)
+		return n != 0
+	case []byte:
+		switch v := value.(type) {
+		case []byte:
+			return append([]byte(nil), v...)
+		case string:
+			return []byte(v)
+		}
+		return []byte(nil)
+	}
+	return value
+}
+
+// quote returns the quoted identifier
+func quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// getErrorTag gets a error tag for metrics based on the error
+func getErrorTag(err error) string {
+	if yarpcerrors.IsAlreadyExists(err) {
+		return "already_exists"
+	}
+	if yarpcerrors.IsInvalidArgument(err) {
+		return "invalid_argument"
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return "timeout"
+	}
+	return "unknown"
+}
+
+// helper function to record call latency metric
+func sendLatency(
+	scope tally.Scope,
+	table, operation string,
+	d time.Duration,
+) {
+	s := scope.Tagged(map[string]string{
+		"table":     table,
+		"operation": operation,
+	})
+	s.Timer("execute_latency").Record(d)
+}
+
+// helper function to record query success/failure metrics
+func sendCounters(
+	scope tally.Scope,
+	table, operation string,
+	err error,
+) {
+	errMsg := "none"
+	if err != nil {
+		errMsg = getErrorTag(err)
+	}
+	s := scope.Tagged(map[string]string{
+		"table":     table,
+		"operation": operation,
+		"error":     errMsg,
+	})
+	s.Counter("execute").Inc(1)
+}
@@ -0,0 +1,380 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testTable has primary key "id" and clustering key "ck"
+var testTable = &base.Definition{
+	Name: "test_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{
+				Name:       "ck",
+				Descending: true,
+			},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":         reflect.TypeOf(""),
+		"ck":         reflect.TypeOf(1),
+		"name":       reflect.TypeOf(""),
+		"data":       reflect.TypeOf([]byte{}),
+		"size":       reflect.TypeOf(uint64(1)),
+		"enabled":    reflect.TypeOf(true),
+		"created_at": reflect.TypeOf(time.Time{}),
+	},
+}
+
+// testRow returns a row of the test table
+func testRow(id string, ck int, name string) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+		{Name: "name", Value: name},
+		{Name: "data", Value: []byte(name)},
+	}
+}
+
+// testKeys returns the primary key columns of a row of the test table
+func testKeys(id string, ck int) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+	}
+}
+
+type SQLiteConnectorSuite struct {
+	suite.Suite
+
+	ctx       context.Context
+	connector orm.Connector
+}
+
+func (suite *SQLiteConnectorSuite) SetupTest() {
+	var err error
+	suite.ctx = context.Background()
+	suite.connector, err = NewSQLiteConnector(
+		&Config{Path: ":memory:"}, tally.NoopScope)
+	suite.NoError(err)
+}
+
+func (suite *SQLiteConnectorSuite) TearDownTest() {
+	suite.connector.(*sqliteConnector).db.Close()
+}
+
+func TestSQLiteConnectorSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteConnectorSuite))
+}
+
+// TestCreateGetDelete tests creating, reading and deleting a row
+func (suite *SQLiteConnectorSuite) TestCreateGetDelete() {
+	createdAt := time.Unix(1000, 10).UTC()
+	row := append(testRow("id1", 1, "test"),
+		base.Column{Name: "size", Value: uint64(math.MaxUint64)},
+		base.Column{Name: "enabled", Value: true},
+		base.Column{Name: "created_at", Value: createdAt},
+	)
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, row))
+
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal(map[string]interface{}{
+		"id":         "id1",
+		"ck":         uint32(1),
+		"name":       "test",
+		"data":       []byte("test"),
+		"size":       uint64(math.MaxUint64),
+		"enabled":    true,
+		"created_at": createdAt,
+	}, result)
+
+	// read the partial row
+	result, err = suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1), "id", "name")
+	suite.NoError(err)
+	suite.Len(result, 2)
+	suite.Equal("test", result["name"])
+
+	suite.NoError(suite.connector.Delete(
+		suite.ctx, testTable, testKeys("id1", 1)))
+
+	result, err = suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Nil(result)
+
+	// deleting a row which does not exist is a noop
+	suite.NoError(suite.connector.Delete(
+		suite.ctx, testTable, testKeys("id1", 1)))
+}
+
+// TestGetUnsetColumns tests that columns which have not been written are
+// read as zero values
+func (suite *SQLiteConnectorSuite) TestGetUnsetColumns() {
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testKeys("id1", 1)))
+
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("", result["name"])
+	suite.Equal(uint64(0), result["size"])
+	suite.Equal(false, result["enabled"])
+	suite.Equal(time.Time{}, result["created_at"])
+	suite.Nil(result["data"])
+}
+
+// TestCreateIfNotExists tests that an existing row is not overwritten
+func (suite *SQLiteConnectorSuite) TestCreateIfNotExists() {
+	suite.NoError(suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	err := suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow("id1", 1, "test-new"))
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test", result["name"])
+}
+
+// TestCreateMissingKey tests that rows without all the primary key
+// columns are rejected
+func (suite *SQLiteConnectorSuite) TestCreateMissingKey() {
+	suite.Error(suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "ck", Value: 1},
+	}))
+	suite.Error(suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	}))
+}
+
+// TestCreateUpdateGet tests that update only changes the given columns
+func (suite *SQLiteConnectorSuite) TestCreateUpdateGet() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "name", Value: "test-update"}},
+		testKeys("id1", 1)))
+
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test-update", result["name"])
+	suite.Equal([]byte("test"), result["data"])
+
+	// primary key columns can not be updated
+	suite.Error(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "ck", Value: 2}},
+		testKeys("id1", 1)))
+
+	// updating a row which does not exist creates it
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "size", Value: uint64(10)}},
+		testKeys("id2", 1)))
+	result, err = suite.connector.Get(
+		suite.ctx, testTable, testKeys("id2", 1))
+	suite.NoError(err)
+	suite.Equal(uint64(10), result["size"])
+	suite.Equal("", result["name"])
+}
+
+// TestUpdateIf tests that a conditional update is only applied to an
+// existing row with the expected values
+func (suite *SQLiteConnectorSuite) TestUpdateIf() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+	update := []base.Column{{Name: "name", Value: "test-update"}}
+
+	err := suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id1", 1),
+		[]base.Column{{Name: "name", Value: "other"}})
+	suite.True(yarpcerrors.IsAborted(err))
+
+	// a row which does not exist is not created
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id2", 1),
+		[]base.Column{{Name: "name", Value: "test"}})
+	suite.True(yarpcerrors.IsAborted(err))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		testKeys("id1", 1),
+		[]base.Column{{Name: "name", Value: "test"}}))
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test-update", result["name"])
+	suite.Equal([]byte("test"), result["data"])
+}
+
+// TestGetAll tests reading all rows of a partition in clustering order
+func (suite *SQLiteConnectorSuite) TestGetAll() {
+	for _, ck := range []int{2, 3, 1} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow("id1", ck, "test")))
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id2", 1, "test")))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	// clustering key is in descending order
+	for i, ck := range []uint32{3, 2, 1} {
+		suite.Equal(ck, rows[i]["ck"])
+	}
+
+	// Get returns the first row in clustering order
+	result, err := suite.connector.Get(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	suite.Equal(uint32(3), result["ck"])
+
+	// no partition key reads all the partitions
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, nil)
+	suite.NoError(err)
+	suite.Len(rows, 4)
+
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id3"},
+	})
+	suite.NoError(err)
+	suite.NotNil(rows)
+	suite.Len(rows, 0)
+}
+
+// TestUint64ClusteringOrder tests that 64 bit unsigned clustering keys
+// at or above 2^63 are sorted after the smaller ones
+func (suite *SQLiteConnectorSuite) TestUint64ClusteringOrder() {
+	table := &base.Definition{
+		Name: "uint64_table",
+		Key: &base.PrimaryKey{
+			PartitionKeys:  []string{"id"},
+			ClusteringKeys: []*base.ClusteringKey{{Name: "seq"}},
+		},
+		ColumnToType: map[string]reflect.Type{
+			"id":  reflect.TypeOf(""),
+			"seq": reflect.TypeOf(uint64(1)),
+		},
+	}
+
+	for _, seq := range []uint64{1 << 63, 1, math.MaxUint64, 1<<63 - 1} {
+		suite.NoError(suite.connector.Create(suite.ctx, table, []base.Column{
+			{Name: "id", Value: "id1"},
+			{Name: "seq", Value: seq},
+		}))
+	}
+
+	rows, err := suite.connector.GetAll(suite.ctx, table, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 4)
+	for i, seq := range []uint64{1, 1<<63 - 1, 1 << 63, math.MaxUint64} {
+		suite.Equal(seq, rows[i]["seq"])
+	}
+}
+
+// TestGetAllIter tests reading the rows using an iterator
+func (suite *SQLiteConnectorSuite) TestGetAllIter() {
+	for _, ck := range []int{1, 2} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow("id1", ck, "test")))
+	}
+
+	iter, err := suite.connector.GetAllIter(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: "id1"},
+	})
+	suite.NoError(err)
+	defer iter.Close()
+
+	var cks []int
+	for {
+		row, err := iter.Next()
+		suite.NoError(err)
+		if row == nil {
+			break
+		}
+		for _, col := range row {
+			switch col.Name {
+			case "ck":
+				cks = append(cks, *col.Value.(*int))
+			case "name":
+				suite.Equal("test", *col.Value.(*string))
+			case "size":
+				suite.Equal(int64(0), *col.Value.(*int64))
+			}
+		}
+	}
+	suite.Equal([]int{2, 1}, cks)
+}
+
+// TestAddColumn tests that columns added to a storage object are added to
+// an existing table
+func (suite *SQLiteConnectorSuite) TestAddColumn() {
+	oldTable := &base.Definition{
+		Name: testTable.Name,
+		Key:  testTable.Key,
+		ColumnToType: map[string]reflect.Type{
+			"id": reflect.TypeOf(""),
+			"ck": reflect.TypeOf(1),
+		},
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, oldTable, testKeys("id1", 1)))
+
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow("id1", 1, "test")))
+
+	result, err := suite.connector.Get(
+		suite.ctx, testTable, testKeys("id1", 1))
+	suite.NoError(err)
+	suite.Equal("test", result["name"])
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/uber-go/tally"
+)
+
+// NewSQLiteStore creates a new storage client backed by an embedded SQLite
+// database, meant for single node deployments. It is not part of the
+// objects package so that only the binaries using SQLite link the cgo
+// SQLite driver.
+func NewSQLiteStore(
+	config *Config,
+	scope tally.Scope,
+) (*objects.Store, error) {
+	connector, err := NewSQLiteConnector(config, scope)
+	if err != nil {
+		return nil, err
+	}
+	return objects.NewStore(connector, scope)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// KeyfileProvider wraps the data keys with the keys of a local keyfile
+	KeyfileProvider = "keyfile"
+
+	// KMSProvider wraps the data keys with a key of a key management
+	// service
+	KMSProvider = "kms"
+
+	// _defaultKMSTimeout is the default timeout of the calls to the
+	// key management service
+	_defaultKMSTimeout = 10 * time.Second
+)
+
+// Config is the config of the encryption of the secrets at rest
+type Config struct {
+	// Provider is the key provider which wraps the data keys, one of
+	// keyfile or kms. Secrets are stored in plaintext if not set.
+	Provider string `yaml:"provider"`
+
+	// KeyFile is the YAML file holding the keys of the keyfile provider
+	KeyFile string `yaml:"key_file"`
+
+	// KMS is the config of the kms provider
+	KMS KMSConfig `yaml:"kms"`
+
+	// RotationPeriod is the period at which the secrets which are not
+	// encrypted with the current key are re-encrypted. Secrets are not
+	// re-encrypted in the background if not set.
+	RotationPeriod time.Duration `yaml:"rotation_period"`
+}
+
+// KMSConfig is the config of the key management service
+type KMSConfig struct {
+	// Endpoint is the URL of the key management service
+	Endpoint string `yaml:"endpoint"`
+
+	// KeyName is the name of the key which wraps the data keys
+	KeyName string `yaml:"key_name"`
+
+	// Timeout is the timeout of the calls to the key management service
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// New creates the encrypter configured in cfg. It returns nil if the
+// encryption of the secrets is not enabled.
+func New(cfg *Config) (Encrypter, error) {
+	var provider KeyProvider
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case KeyfileProvider:
+		if cfg.KeyFile == "" {
+			return nil, errors.New("keyfile provider requires a key file")
+		}
+		p, err := NewKeyfileProvider(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	case KMSProvider:
+		if cfg.KMS.Endpoint == "" || cfg.KMS.KeyName == "" {
+			return nil, errors.New("kms provider requires an endpoint and a key name")
+		}
+		timeout := cfg.KMS.Timeout
+		if timeout == 0 {
+			timeout = _defaultKMSTimeout
+		}
+		provider = NewKMSProvider(
+			cfg.KMS.Endpoint,
+			cfg.KMS.KeyName,
+			&http.Client{Timeout: timeout},
+		)
+	default:
+		return nil, errors.New("unknown key provider " + cfg.Provider)
+	}
+
+	// check that the provider is usable before any secret is written
+	if _, err := provider.CurrentKeyID(context.Background()); err != nil {
+		return nil, err
+	}
+	return NewEnvelopeEncrypter(provider), nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+// TestNewDisabled tests that no encrypter is created when no key
+// provider is configured
+func (suite *ConfigTestSuite) TestNewDisabled() {
+	encrypter, err := New(&Config{})
+	suite.NoError(err)
+	suite.Nil(encrypter)
+}
+
+// TestNewKMS tests creating an encrypter using the kms provider
+func (suite *ConfigTestSuite) TestNewKMS() {
+	kms := NewLocalKMS()
+	_, err := kms.RotateKey("secrets")
+	suite.NoError(err)
+	server := httptest.NewServer(kms)
+	defer server.Close()
+
+	encrypter, err := New(&Config{
+		Provider: KMSProvider,
+		KMS:      KMSConfig{Endpoint: server.URL, KeyName: "secrets"},
+	})
+	suite.NoError(err)
+	suite.NotNil(encrypter)
+
+	// the key must exist in the service
+	_, err = New(&Config{
+		Provider: KMSProvider,
+		KMS:      KMSConfig{Endpoint: server.URL, KeyName: "unknown"},
+	})
+	suite.Error(err)
+}
+
+// TestNewInvalid tests that invalid configs are rejected
+func (suite *ConfigTestSuite) TestNewInvalid() {
+	for _, cfg := range []*Config{
+		{Provider: "vault"},
+		{Provider: KeyfileProvider},
+		{Provider: KeyfileProvider, KeyFile: "/does/not/exist"},
+		{Provider: KMSProvider},
+		{Provider: KMSProvider, KMS: KMSConfig{Endpoint: "http://kms"}},
+	} {
+		_, err := New(cfg)
+		suite.Error(err)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// _dataKeySize is the size of the AES-256 data keys
+	_dataKeySize = 32
+
+	// _wrappedKeyLenSize is the size of the length prefix of the wrapped
+	// data key in the envelope
+	_wrappedKeyLenSize = 2
+)
+
+var errMalformedEnvelope = errors.New("malformed encryption envelope")
+
+// Encrypter encrypts and decrypts data at rest
+type Encrypter interface {
+	// CurrentKeyID returns the ID of the key which new data is
+	// encrypted with
+	CurrentKeyID(ctx context.Context) (string, error)
+
+	// Encrypt encrypts plaintext, and returns the ciphertext along with
+	// the ID of the key needed to decrypt it. The ciphertext is bound to
+	// additionalData, which is authenticated but not encrypted.
+	Encrypt(
+		ctx context.Context,
+		plaintext []byte,
+		additionalData []byte,
+	) ([]byte, string, error)
+
+	// Decrypt decrypts ciphertext encrypted with the key keyID. It fails
+	// unless additionalData is the one the ciphertext was encrypted with.
+	Decrypt(
+		ctx context.Context,
+		keyID string,
+		ciphertext []byte,
+		additionalData []byte,
+	) ([]byte, error)
+}
+
+// envelopeEncrypter implements envelope encryption: the data is
+// encrypted with a random data key, which is wrapped by the key provider
+// and stored next to the data.
+type envelopeEncrypter struct {
+	provider KeyProvider
+}
+
+// NewEnvelopeEncrypter returns an Encrypter wrapping the data keys
+// with provider
+func NewEnvelopeEncrypter(provider KeyProvider) Encrypter {
+	return &envelopeEncrypter{provider: provider}
+}
+
+// CurrentKeyID returns the ID of the current key of the provider
+func (e *envelopeEncrypter) CurrentKeyID(ctx context.Context) (string, error) {
+	return e.provider.CurrentKeyID(ctx)
+}
+
+// Encrypt encrypts plaintext with a new data key. The envelope is made of
+// the length of the wrapped data key, the wrapped data key and the
+// sealed plaintext.
+func (e *envelopeEncrypter) Encrypt(
+	ctx context.Context,
+	plaintext []byte,
+	additionalData []byte,
+) ([]byte, string, error) {
+	dataKey := make([]byte, _dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", err
+	}
+
+	keyID, wrappedKey, err := e.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(wrappedKey) > 1<<16-1 {
+		return nil, "", errors.New("wrapped data key is too large")
+	}
+
+	sealed, err := seal(dataKey, plaintext, additionalData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	envelope := make([]byte, _wrappedKeyLenSize, _wrappedKeyLenSize+len(wrappedKey)+len(sealed))
+	binary.BigEndian.PutUint16(envelope, uint16(len(wrappedKey)))
+	envelope = append(envelope, wrappedKey...)
+	envelope = append(envelope, sealed...)
+	return envelope, keyID, nil
+}
+
+// Decrypt unwraps the data key of the envelope with the key keyID,
+// and opens the sealed plaintext with it
+func (e *envelopeEncrypter) Decrypt(
+	ctx context.Context,
+	keyID string,
+	ciphertext []byte,
+	additionalData []byte,
+) ([]byte, error) {
+	if len(ciphertext) < _wrappedKeyLenSize {
+		return nil, errMalformedEnvelope
+	}
+	wrappedKeyLen := int(binary.BigEndian.Uint16(ciphertext))
+	ciphertext = ciphertext[_wrappedKeyLenSize:]
+	if len(ciphertext) < wrappedKeyLen {
+		return nil, errMalformedEnvelope
+	}
+
+	dataKey, err := e.provider.UnwrapKey(ctx, keyID, ciphertext[:wrappedKeyLen])
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, ciphertext[wrappedKeyLen:], additionalData)
+}
+
+// seal encrypts plaintext with AES-GCM, authenticating additionalData
+// along with it, and prepends the random nonce
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the output of seal sealed with the same additionalData
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errMalformedEnvelope
+	}
+	nonceSize := aead.NonceSize()
+	return aead.Open(
+		nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvelopeEncrypterTestSuite struct {
+	suite.Suite
+
+	kms       *LocalKMS
+	server    *httptest.Server
+	encrypter Encrypter
+}
+
+func TestEnvelopeEncrypter(t *testing.T) {
+	suite.Run(t, new(EnvelopeEncrypterTestSuite))
+}
+
+func (suite *EnvelopeEncrypterTestSuite) SetupTest() {
+	suite.kms = NewLocalKMS()
+	_, err := suite.kms.RotateKey("secrets")
+	suite.NoError(err)
+	suite.server = httptest.NewServer(suite.kms)
+	suite.encrypter = NewEnvelopeEncrypter(NewKMSProvider(
+		suite.server.URL, "secrets", suite.server.Client()))
+}
+
+func (suite *EnvelopeEncrypterTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// TestEncryptDecrypt tests encrypting and decrypting data
+func (suite *EnvelopeEncrypterTestSuite) TestEncryptDecrypt() {
+	plaintext := []byte("some secret")
+	ciphertext, keyID, err := suite.encrypter.Encrypt(
+		context.Background(), plaintext, []byte("id"))
+	suite.NoError(err)
+	suite.Equal("secrets/v1", keyID)
+	suite.NotContains(string(ciphertext), string(plaintext))
+
+	// every encryption uses a new data key
+	otherCiphertext, _, err := suite.encrypter.Encrypt(
+		context.Background(), plaintext, []byte("id"))
+	suite.NoError(err)
+	suite.NotEqual(ciphertext, otherCiphertext)
+
+	decrypted, err := suite.encrypter.Decrypt(
+		context.Background(), keyID, ciphertext, []byte("id"))
+	suite.NoError(err)
+	suite.Equal(plaintext, decrypted)
+}
+
+// TestDecryptAfterRotation tests that data encrypted with an old key
+// can be decrypted after the key is rotated
+func (suite *EnvelopeEncrypterTestSuite) TestDecryptAfterRotation() {
+	plaintext := []byte("some secret")
+	ciphertext, keyID, err := suite.encrypter.Encrypt(
+		context.Background(), plaintext, []byte("id"))
+	suite.NoError(err)
+
+	newKeyID, err := suite.kms.RotateKey("secrets")
+	suite.NoError(err)
+	currentKeyID, err := suite.encrypter.CurrentKeyID(context.Background())
+	suite.NoError(err)
+	suite.Equal(newKeyID, currentKeyID)
+
+	decrypted, err := suite.encrypter.Decrypt(
+		context.Background(), keyID, ciphertext, []byte("id"))
+	suite.NoError(err)
+	suite.Equal(plaintext, decrypted)
+}
+
+// TestDecryptMalformed tests decrypting malformed and tampered data
+func (suite *EnvelopeEncrypterTestSuite) TestDecryptMalformed() {
+	_, err := suite.encrypter.Decrypt(
+		context.Background(), "secrets/v1", []byte{0}, nil)
+	suite.Equal(errMalformedEnvelope, err)
+
+	_, err = suite.encrypter.Decrypt(
+		context.Background(), "secrets/v1", []byte{0, 10, 1}, nil)
+	suite.Equal(errMalformedEnvelope, err)
+
+	ciphertext, keyID, err := suite.encrypter.Encrypt(
+		context.Background(), []byte("some secret"), []byte("id"))
+	suite.NoError(err)
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = suite.encrypter.Decrypt(
+		context.Background(), keyID, ciphertext, []byte("id"))
+	suite.Error(err)
+}
+
+// TestDecryptOtherAdditionalData tests that data cannot be decrypted with
+// additional data other than the one it was encrypted with
+func (suite *EnvelopeEncrypterTestSuite) TestDecryptOtherAdditionalData() {
+	ciphertext, keyID, err := suite.encrypter.Encrypt(
+		context.Background(), []byte("some secret"), []byte("id"))
+	suite.NoError(err)
+
+	_, err = suite.encrypter.Decrypt(
+		context.Background(), keyID, ciphertext, []byte("other-id"))
+	suite.Error(err)
+	_, err = suite.encrypter.Decrypt(context.Background(), keyID, ciphertext, nil)
+	suite.Error(err)
+}
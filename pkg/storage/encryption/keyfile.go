@@ -0,0 +1,177 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// KeyProvider wraps the data keys of the envelope encryption with
+// key encryption keys it owns
+type KeyProvider interface {
+	// CurrentKeyID returns the ID of the key which new data keys
+	// are wrapped with
+	CurrentKeyID(ctx context.Context) (string, error)
+
+	// WrapKey wraps dataKey with the current key, and returns the ID
+	// of the key along with the wrapped data key
+	WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error)
+
+	// UnwrapKey unwraps a data key wrapped with the key keyID
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// keyfile is the content of the file of the keyfile provider, e.g.
+//
+//	current_key_id: key-2
+//	keys:
+//	  key-1: <base64 encoded 32 bytes key>
+//	  key-2: <base64 encoded 32 bytes key>
+//
+// Keys are rotated by adding a new key to the file and making it the
+// current key. Old keys must be kept until all the data encrypted with
+// them has been re-encrypted.
+type keyfile struct {
+	CurrentKeyID string            `yaml:"current_key_id"`
+	Keys         map[string]string `yaml:"keys"`
+}
+
+// keyfileProvider wraps the data keys with the AES-256 keys of a local
+// file. The file is reloaded when it is modified.
+type keyfileProvider struct {
+	sync.Mutex
+
+	path string
+
+	// modTime is the modification time of the file when it was loaded
+	modTime      time.Time
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+// NewKeyfileProvider returns a KeyProvider using the keys of the file
+// at path
+func NewKeyfileProvider(path string) (KeyProvider, error) {
+	p := &keyfileProvider{path: path}
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// reload loads the keys of the file if it was modified since the last
+// time it was loaded
+func (p *keyfileProvider) reload() error {
+	p.Lock()
+	defer p.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+	if p.keys != nil && info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	var kf keyfile
+	if err := yaml.Unmarshal(content, &kf); err != nil {
+		return err
+	}
+
+	keys := make(map[string][]byte)
+	for id, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("key %s is not base64 encoded: %v", id, err)
+		}
+		if len(key) != _dataKeySize {
+			return fmt.Errorf("key %s is not %d bytes long", id, _dataKeySize)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[kf.CurrentKeyID]; !ok {
+		return fmt.Errorf("current key %q is not in %s", kf.CurrentKeyID, p.path)
+	}
+
+	p.modTime = info.ModTime()
+	p.currentKeyID = kf.CurrentKeyID
+	p.keys = keys
+	return nil
+}
+
+// currentKey returns the current key and its ID
+func (p *keyfileProvider) currentKey() (string, []byte, error) {
+	if err := p.reload(); err != nil {
+		return "", nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	return p.currentKeyID, p.keys[p.currentKeyID], nil
+}
+
+// CurrentKeyID returns the ID of the current key of the file
+func (p *keyfileProvider) CurrentKeyID(ctx context.Context) (string, error) {
+	keyID, _, err := p.currentKey()
+	return keyID, err
+}
+
+// WrapKey wraps dataKey with the current key of the file
+func (p *keyfileProvider) WrapKey(
+	ctx context.Context,
+	dataKey []byte,
+) (string, []byte, error) {
+	keyID, key, err := p.currentKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	wrappedKey, err := seal(key, dataKey, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, wrappedKey, nil
+}
+
+// UnwrapKey unwraps a data key with the key keyID of the file
+func (p *keyfileProvider) UnwrapKey(
+	ctx context.Context,
+	keyID string,
+	wrappedKey []byte,
+) ([]byte, error) {
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	key, ok := p.keys[keyID]
+	p.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("key %s is not in %s", keyID, p.path)
+	}
+	return open(key, wrappedKey, nil)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyfileProviderTestSuite struct {
+	suite.Suite
+
+	dir  string
+	path string
+}
+
+func TestKeyfileProvider(t *testing.T) {
+	suite.Run(t, new(KeyfileProviderTestSuite))
+}
+
+func (suite *KeyfileProviderTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "keyfile")
+	suite.NoError(err)
+	suite.dir = dir
+	suite.path = filepath.Join(dir, "keys.yaml")
+}
+
+func (suite *KeyfileProviderTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+// newKey returns a base64 encoded random key
+func (suite *KeyfileProviderTestSuite) newKey() string {
+	key := make([]byte, _dataKeySize)
+	_, err := rand.Read(key)
+	suite.NoError(err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// writeKeyfile writes the keys to the keyfile, and moves its
+// modification time forward so that it is reloaded
+func (suite *KeyfileProviderTestSuite) writeKeyfile(
+	currentKeyID string,
+	keys map[string]string,
+) {
+	content := fmt.Sprintf("current_key_id: %s\nkeys:\n", currentKeyID)
+	for id, key := range keys {
+		content += fmt.Sprintf("  %s: %s\n", id, key)
+	}
+	suite.NoError(ioutil.WriteFile(suite.path, []byte(content), 0600))
+
+	modTime := time.Now().Add(time.Duration(len(keys)) * time.Second)
+	suite.NoError(os.Chtimes(suite.path, modTime, modTime))
+}
+
+// TestWrapUnwrapKey tests wrapping and unwrapping a data key
+func (suite *KeyfileProviderTestSuite) TestWrapUnwrapKey() {
+	suite.writeKeyfile("key-1", map[string]string{"key-1": suite.newKey()})
+	provider, err := NewKeyfileProvider(suite.path)
+	suite.NoError(err)
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	keyID, wrappedKey, err := provider.WrapKey(context.Background(), dataKey)
+	suite.NoError(err)
+	suite.Equal("key-1", keyID)
+	suite.NotEqual(dataKey, wrappedKey)
+
+	unwrappedKey, err := provider.UnwrapKey(
+		context.Background(), keyID, wrappedKey)
+	suite.NoError(err)
+	suite.Equal(dataKey, unwrappedKey)
+
+	_, err = provider.UnwrapKey(context.Background(), "key-2", wrappedKey)
+	suite.Error(err)
+}
+
+// TestRotateKey tests that a new current key is picked up when the
+// keyfile is modified, and that old keys can still unwrap data keys
+func (suite *KeyfileProviderTestSuite) TestRotateKey() {
+	key1 := suite.newKey()
+	suite.writeKeyfile("key-1", map[string]string{"key-1": key1})
+	provider, err := NewKeyfileProvider(suite.path)
+	suite.NoError(err)
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	_, wrappedKey, err := provider.WrapKey(context.Background(), dataKey)
+	suite.NoError(err)
+
+	suite.writeKeyfile("key-2", map[string]string{
+		"key-1": key1,
+		"key-2": suite.newKey(),
+	})
+	keyID, err := provider.CurrentKeyID(context.Background())
+	suite.NoError(err)
+	suite.Equal("key-2", keyID)
+
+	unwrappedKey, err := provider.UnwrapKey(
+		context.Background(), "key-1", wrappedKey)
+	suite.NoError(err)
+	suite.Equal(dataKey, unwrappedKey)
+}
+
+// TestInvalidKeyfile tests that invalid keyfiles are rejected
+func (suite *KeyfileProviderTestSuite) TestInvalidKeyfile() {
+	_, err := NewKeyfileProvider(suite.path)
+	suite.Error(err)
+
+	suite.writeKeyfile("key-2", map[string]string{"key-1": suite.newKey()})
+	_, err = NewKeyfileProvider(suite.path)
+	suite.Error(err)
+
+	suite.writeKeyfile("key-1", map[string]string{"key-1": "not base64"})
+	_, err = NewKeyfileProvider(suite.path)
+	suite.Error(err)
+
+	suite.writeKeyfile("key-1", map[string]string{
+		"key-1": base64.StdEncoding.EncodeToString([]byte("short")),
+	})
+	_, err = NewKeyfileProvider(suite.path)
+	suite.Error(err)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// The key management service is called over HTTP with JSON bodies,
+// binary values being base64 encoded:
+//
+//  GET  <endpoint>/v1/keys/<name>
+//       -> {"current_key_id"}
+//  POST <endpoint>/v1/keys/<name>/encrypt {"plaintext"}
+//       -> {"key_id", "ciphertext"}
+//  POST <endpoint>/v1/keys/<name>/decrypt {"key_id", "ciphertext"}
+//       -> {"plaintext"}
+//
+// A key name refers to all the versions of a key, each version having
+// its own key ID. Rotating the key in the service creates a new
+// current version, older versions can still decrypt.
+
+type kmsKeyResponse struct {
+	CurrentKeyID string `json:"current_key_id"`
+}
+
+type kmsEncryptRequest struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+type kmsEncryptResponse struct {
+	KeyID      string `json:"key_id"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type kmsDecryptRequest struct {
+	KeyID      string `json:"key_id"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type kmsDecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+// kmsProvider wraps the data keys with a key of a key management service
+type kmsProvider struct {
+	keyURL string
+	client *http.Client
+}
+
+// NewKMSProvider returns a KeyProvider wrapping the data keys with the
+// key keyName of the key management service at endpoint
+func NewKMSProvider(endpoint, keyName string, client *http.Client) KeyProvider {
+	return &kmsProvider{
+		keyURL: endpoint + "/v1/keys/" + url.PathEscape(keyName),
+		client: client,
+	}
+}
+
+// CurrentKeyID returns the ID of the current version of the key
+func (p *kmsProvider) CurrentKeyID(ctx context.Context) (string, error) {
+	var resp kmsKeyResponse
+	if err := p.call(ctx, "GET", p.keyURL, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.CurrentKeyID, nil
+}
+
+// WrapKey encrypts dataKey with the current version of the key
+func (p *kmsProvider) WrapKey(
+	ctx context.Context,
+	dataKey []byte,
+) (string, []byte, error) {
+	var resp kmsEncryptResponse
+	if err := p.call(
+		ctx,
+		"POST",
+		p.keyURL+"/encrypt",
+		&kmsEncryptRequest{Plaintext: dataKey},
+		&resp,
+	); err != nil {
+		return "", nil, err
+	}
+	return resp.KeyID, resp.Ciphertext, nil
+}
+
+// UnwrapKey decrypts a data key with the version keyID of the key
+func (p *kmsProvider) UnwrapKey(
+	ctx context.Context,
+	keyID string,
+	wrappedKey []byte,
+) ([]byte, error) {
+	var resp kmsDecryptResponse
+	if err := p.call(
+		ctx,
+		"POST",
+		p.keyURL+"/decrypt",
+		&kmsDecryptRequest{KeyID: keyID, Ciphertext: wrappedKey},
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call sends the request body to the key management service, and
+// decodes the response in resp
+func (p *kmsProvider) call(
+	ctx context.Context,
+	method, url string,
+	body interface{},
+	resp interface{},
+) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, &reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key management service call failed: %s", httpResp.Status)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KMSProviderTestSuite struct {
+	suite.Suite
+
+	kms      *LocalKMS
+	server   *httptest.Server
+	provider KeyProvider
+}
+
+func TestKMSProvider(t *testing.T) {
+	suite.Run(t, new(KMSProviderTestSuite))
+}
+
+func (suite *KMSProviderTestSuite) SetupTest() {
+	suite.kms = NewLocalKMS()
+	_, err := suite.kms.RotateKey("secrets")
+	suite.NoError(err)
+	suite.server = httptest.NewServer(suite.kms)
+	suite.provider = NewKMSProvider(
+		suite.server.URL, "secrets", suite.server.Client())
+}
+
+func (suite *KMSProviderTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// TestWrapUnwrapKey tests wrapping and unwrapping a data key
+func (suite *KMSProviderTestSuite) TestWrapUnwrapKey() {
+	keyID, err := suite.provider.CurrentKeyID(context.Background())
+	suite.NoError(err)
+	suite.Equal("secrets/v1", keyID)
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	keyID, wrappedKey, err := suite.provider.WrapKey(
+		context.Background(), dataKey)
+	suite.NoError(err)
+	suite.Equal("secrets/v1", keyID)
+
+	unwrappedKey, err := suite.provider.UnwrapKey(
+		context.Background(), keyID, wrappedKey)
+	suite.NoError(err)
+	suite.Equal(dataKey, unwrappedKey)
+
+	_, err = suite.provider.UnwrapKey(
+		context.Background(), "secrets/v2", wrappedKey)
+	suite.Error(err)
+}
+
+// TestRotateKey tests that the new version of a rotated key wraps the
+// data keys, and that the old version can still unwrap them
+func (suite *KMSProviderTestSuite) TestRotateKey() {
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	oldKeyID, wrappedKey, err := suite.provider.WrapKey(
+		context.Background(), dataKey)
+	suite.NoError(err)
+
+	newKeyID, err := suite.kms.RotateKey("secrets")
+	suite.NoError(err)
+	keyID, err := suite.provider.CurrentKeyID(context.Background())
+	suite.NoError(err)
+	suite.Equal(newKeyID, keyID)
+
+	keyID, _, err = suite.provider.WrapKey(context.Background(), dataKey)
+	suite.NoError(err)
+	suite.Equal(newKeyID, keyID)
+
+	unwrappedKey, err := suite.provider.UnwrapKey(
+		context.Background(), oldKeyID, wrappedKey)
+	suite.NoError(err)
+	suite.Equal(dataKey, unwrappedKey)
+}
+
+// TestUnknownKey tests the calls for a key unknown to the service
+func (suite *KMSProviderTestSuite) TestUnknownKey() {
+	provider := NewKMSProvider(
+		suite.server.URL, "unknown", suite.server.Client())
+	_, err := provider.CurrentKeyID(context.Background())
+	suite.Error(err)
+
+	// a key ID of another key cannot be used
+	_, wrappedKey, err := suite.provider.WrapKey(
+		context.Background(), []byte("data key"))
+	suite.NoError(err)
+	_, err = NewKMSProvider(suite.server.URL, "other", suite.server.Client()).
+		UnwrapKey(context.Background(), "secrets/v1", wrappedKey)
+	suite.Error(err)
+}
+
+// TestServiceFailure tests the calls when the service fails
+func (suite *KMSProviderTestSuite) TestServiceFailure() {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+	defer server.Close()
+
+	provider := NewKMSProvider(server.URL, "secrets", server.Client())
+	_, _, err := provider.WrapKey(context.Background(), []byte("data key"))
+	suite.Error(err)
+}
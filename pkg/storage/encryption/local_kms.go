@@ -0,0 +1,133 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// LocalKMS is a stand-in of the key management service keeping its keys
+// in memory, meant for tests and local development only. It serves
+// the API used by the kms provider.
+type LocalKMS struct {
+	sync.Mutex
+
+	// versions are the IDs of the versions of each key name,
+	// the last one being the current version
+	versions map[string][]string
+	// keys are the keys by key ID
+	keys map[string][]byte
+}
+
+// NewLocalKMS returns a LocalKMS without any key
+func NewLocalKMS() *LocalKMS {
+	return &LocalKMS{
+		versions: make(map[string][]string),
+		keys:     make(map[string][]byte),
+	}
+}
+
+// RotateKey creates a new version of the key name, which becomes its
+// current version. It returns the key ID of the new version.
+func (k *LocalKMS) RotateKey(name string) (string, error) {
+	key := make([]byte, _dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+
+	k.Lock()
+	defer k.Unlock()
+
+	keyID := fmt.Sprintf("%s/v%d", name, len(k.versions[name])+1)
+	k.versions[name] = append(k.versions[name], keyID)
+	k.keys[keyID] = key
+	return keyID, nil
+}
+
+// ServeHTTP serves the key management service API
+func (k *LocalKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/keys/"), "/")
+	name := parts[0]
+
+	k.Lock()
+	defer k.Unlock()
+
+	versions := k.versions[name]
+	if len(versions) == 0 || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	operation := r.Method
+	if len(parts) == 2 {
+		operation += " " + parts[1]
+	}
+
+	var resp interface{}
+	var err error
+	switch operation {
+	case "GET":
+		resp = &kmsKeyResponse{CurrentKeyID: versions[len(versions)-1]}
+	case "POST encrypt":
+		resp, err = k.encrypt(r, versions[len(versions)-1])
+	case "POST decrypt":
+		resp, err = k.decrypt(r, name)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// encrypt encrypts the plaintext of the request with the key keyID
+func (k *LocalKMS) encrypt(r *http.Request, keyID string) (interface{}, error) {
+	var req kmsEncryptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(k.keys[keyID], req.Plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsEncryptResponse{KeyID: keyID, Ciphertext: ciphertext}, nil
+}
+
+// decrypt decrypts the ciphertext of the request with the version of
+// the key name given in the request
+func (k *LocalKMS) decrypt(r *http.Request, name string) (interface{}, error) {
+	var req kmsDecryptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	key, ok := k.keys[req.KeyID]
+	if !ok || !strings.HasPrefix(req.KeyID, name+"/") {
+		return nil, fmt.Errorf("unknown key id %s", req.KeyID)
+	}
+	plaintext, err := open(key, req.Ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsDecryptResponse{Plaintext: plaintext}, nil
+}
@@ -113,6 +113,9 @@ type OrmJobMetrics struct {
 	SecretInfoUpdateFail tally.Counter
 	SecretInfoDelete     tally.Counter
 	SecretInfoDeleteFail tally.Counter
+
+	SecretInfoReencrypt     tally.Counter
+	SecretInfoReencryptFail tally.Counter
 }
 
 // OrmRespoolMetrics tracks counters for resource pools related tables accessed through ORM layer.
@@ -129,6 +132,57 @@ type OrmRespoolMetrics struct {
 	RespoolDeleteFail tally.Counter
 }
 
+// OrmCronJobMetrics tracks counters for cron jobs table accessed through ORM layer.
+type OrmCronJobMetrics struct {
+	CronJobCreate     tally.Counter
+	CronJobCreateFail tally.Counter
+	CronJobGet        tally.Counter
+	CronJobGetFail    tally.Counter
+	CronJobGetAll     tally.Counter
+	CronJobGetAllFail tally.Counter
+	CronJobUpdate     tally.Counter
+	CronJobUpdateFail tally.Counter
+	CronJobDelete     tally.Counter
+	CronJobDeleteFail tally.Counter
+}
+
+// OrmDAGMetrics tracks counters for dags table accessed through ORM layer.
+type OrmDAGMetrics struct {
+	DAGCreate     tally.Counter
+	DAGCreateFail tally.Counter
+	DAGGet        tally.Counter
+	DAGGetFail    tally.Counter
+	DAGGetAll     tally.Counter
+	DAGGetAllFail tally.Counter
+	DAGUpdate     tally.Counter
+	DAGUpdateFail tally.Counter
+	DAGDelete     tally.Counter
+	DAGDeleteFail tally.Counter
+}
+
+// OrmMaintenanceWindowMetrics tracks counters for maintenance_windows table
+// accessed through ORM layer.
+type OrmMaintenanceWindowMetrics struct {
+	MaintenanceWindowCreate     tally.Counter
+	MaintenanceWindowCreateFail tally.Counter
+	MaintenanceWindowGet        tally.Counter
+	MaintenanceWindowGetFail    tally.Counter
+	MaintenanceWindowGetAll     tally.Counter
+	MaintenanceWindowGetAllFail tally.Counter
+	MaintenanceWindowUpdate     tally.Counter
+	MaintenanceWindowUpdateFail tally.Counter
+	MaintenanceWindowDelete     tally.Counter
+	MaintenanceWindowDeleteFail tally.Counter
+}
+
+// OrmAuditEventMetrics tracks counters for audit events table accessed through ORM layer.
+type OrmAuditEventMetrics struct {
+	AuditEventAdd        tally.Counter
+	AuditEventAddFail    tally.Counter
+	AuditEventGetAll     tally.Counter
+	AuditEventGetAllFail tally.Counter
+}
+
 // TaskMetrics is a struct for tracking all the task related counters in the storage layer
 type TaskMetrics struct {
 	TaskCreate     tally.Counter
@@ -358,19 +412,23 @@ type OrmJobUpdateEventsMetrics struct {
 // Metrics is a struct for tracking all the general purpose counters that have relevance to the storage
 // layer, i.e. how many jobs and tasks were created/deleted in the storage layer
 type Metrics struct {
-	JobMetrics                *JobMetrics
-	TaskMetrics               *TaskMetrics
-	UpdateMetrics             *UpdateMetrics
-	ResourcePoolMetrics       *ResourcePoolMetrics
-	FrameworkStoreMetrics     *FrameworkStoreMetrics
-	VolumeMetrics             *VolumeMetrics
-	ErrorMetrics              *ErrorMetrics
-	WorkflowMetrics           *WorkflowMetrics
-	OrmJobMetrics             *OrmJobMetrics
-	OrmRespoolMetrics         *OrmRespoolMetrics
-	OrmTaskMetrics            *OrmTaskMetrics
-	OrmHostInfoMetrics        *OrmHostInfoMetrics
-	OrmJobUpdateEventsMetrics *OrmJobUpdateEventsMetrics
+	JobMetrics                  *JobMetrics
+	TaskMetrics                 *TaskMetrics
+	UpdateMetrics               *UpdateMetrics
+	ResourcePoolMetrics         *ResourcePoolMetrics
+	FrameworkStoreMetrics       *FrameworkStoreMetrics
+	VolumeMetrics               *VolumeMetrics
+	ErrorMetrics                *ErrorMetrics
+	WorkflowMetrics             *WorkflowMetrics
+	OrmJobMetrics               *OrmJobMetrics
+	OrmRespoolMetrics           *OrmRespoolMetrics
+	OrmCronJobMetrics           *OrmCronJobMetrics
+	OrmDAGMetrics               *OrmDAGMetrics
+	OrmAuditEventMetrics        *OrmAuditEventMetrics
+	OrmMaintenanceWindowMetrics *OrmMaintenanceWindowMetrics
+	OrmTaskMetrics              *OrmTaskMetrics
+	OrmHostInfoMetrics          *OrmHostInfoMetrics
+	OrmJobUpdateEventsMetrics   *OrmJobUpdateEventsMetrics
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized and rooted at the given tally.Scope
@@ -647,6 +705,30 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	respoolFailedScope := respoolScope.Tagged(
 		map[string]string{"result": "fail"})
 
+	cronJobScope := ormScope.SubScope("cron_job")
+	cronJobSuccessScope := cronJobScope.Tagged(
+		map[string]string{"result": "success"})
+	cronJobFailScope := cronJobScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	dagScope := ormScope.SubScope("dag")
+	dagSuccessScope := dagScope.Tagged(
+		map[string]string{"result": "success"})
+	dagFailScope := dagScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	maintenanceWindowScope := ormScope.SubScope("maintenance_window")
+	maintenanceWindowSuccessScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "success"})
+	maintenanceWindowFailScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	auditEventScope := ormScope.SubScope("audit_event")
+	auditEventSuccessScope := auditEventScope.Tagged(
+		map[string]string{"result": "success"})
+	auditEventFailScope := auditEventScope.Tagged(
+		map[string]string{"result": "fail"})
+
 	secretInfoScope := ormScope.SubScope("secret_info")
 	secretInfoSuccessScope := secretInfoScope.Tagged(
 		map[string]string{"result": "success"})
@@ -699,6 +781,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		SecretInfoUpdateFail: secretInfoFailScope.Counter("update"),
 		SecretInfoDelete:     secretInfoSuccessScope.Counter("delete"),
 		SecretInfoDeleteFail: secretInfoFailScope.Counter("delete"),
+
+		SecretInfoReencrypt:     secretInfoSuccessScope.Counter("reencrypt"),
+		SecretInfoReencryptFail: secretInfoFailScope.Counter("reencrypt"),
 	}
 
 	ormRespoolMetrics := &OrmRespoolMetrics{
@@ -714,6 +799,52 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		RespoolDeleteFail: respoolFailedScope.Counter("delete"),
 	}
 
+	ormCronJobMetrics := &OrmCronJobMetrics{
+		CronJobCreate:     cronJobSuccessScope.Counter("create"),
+		CronJobCreateFail: cronJobFailScope.Counter("create"),
+		CronJobGet:        cronJobSuccessScope.Counter("get"),
+		CronJobGetFail:    cronJobFailScope.Counter("get"),
+		CronJobGetAll:     cronJobSuccessScope.Counter("getAll"),
+		CronJobGetAllFail: cronJobFailScope.Counter("getAll"),
+		CronJobUpdate:     cronJobSuccessScope.Counter("update"),
+		CronJobUpdateFail: cronJobFailScope.Counter("update"),
+		CronJobDelete:     cronJobSuccessScope.Counter("delete"),
+		CronJobDeleteFail: cronJobFailScope.Counter("delete"),
+	}
+
+	ormDAGMetrics := &OrmDAGMetrics{
+		DAGCreate:     dagSuccessScope.Counter("create"),
+		DAGCreateFail: dagFailScope.Counter("create"),
+		DAGGet:        dagSuccessScope.Counter("get"),
+		DAGGetFail:    dagFailScope.Counter("get"),
+		DAGGetAll:     dagSuccessScope.Counter("getAll"),
+		DAGGetAllFail: dagFailScope.Counter("getAll"),
+		DAGUpdate:     dagSuccessScope.Counter("update"),
+		DAGUpdateFail: dagFailScope.Counter("update"),
+		DAGDelete:     dagSuccessScope.Counter("delete"),
+		DAGDeleteFail: dagFailScope.Counter("delete"),
+	}
+
+	ormMaintenanceWindowMetrics := &OrmMaintenanceWindowMetrics{
+		MaintenanceWindowCreate:     maintenanceWindowSuccessScope.Counter("create"),
+		MaintenanceWindowCreateFail: maintenanceWindowFailScope.Counter("create"),
+		MaintenanceWindowGet:        maintenanceWindowSuccessScope.Counter("get"),
+		MaintenanceWindowGetFail:    maintenanceWindowFailScope.Counter("get"),
+		MaintenanceWindowGetAll:     maintenanceWindowSuccessScope.Counter("getAll"),
+		MaintenanceWindowGetAllFail: maintenanceWindowFailScope.Counter("getAll"),
+		MaintenanceWindowUpdate:     maintenanceWindowSuccessScope.Counter("update"),
+		MaintenanceWindowUpdateFail: maintenanceWindowFailScope.Counter("update"),
+		MaintenanceWindowDelete:     maintenanceWindowSuccessScope.Counter("delete"),
+		MaintenanceWindowDeleteFail: maintenanceWindowFailScope.Counter("delete"),
+	}
+
+	ormAuditEventMetrics := &OrmAuditEventMetrics{
+		AuditEventAdd:        auditEventSuccessScope.Counter("add"),
+		AuditEventAddFail:    auditEventFailScope.Counter("add"),
+		AuditEventGetAll:     auditEventSuccessScope.Counter("getAll"),
+		AuditEventGetAllFail: auditEventFailScope.Counter("getAll"),
+	}
+
 	ormTaskMetrics := &OrmTaskMetrics{
 		PodEventsAdd:     podEventsSuccessScope.Counter("add"),
 		PodEventsAddFail: podEventsFailScope.Counter("add"),
@@ -762,19 +893,23 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	}
 
 	metrics := &Metrics{
-		JobMetrics:                jobMetrics,
-		TaskMetrics:               taskMetrics,
-		UpdateMetrics:             updateMetrics,
-		ResourcePoolMetrics:       resourcePoolMetrics,
-		FrameworkStoreMetrics:     frameworkStoreMetrics,
-		VolumeMetrics:             volumeMetrics,
-		ErrorMetrics:              errorMetrics,
-		WorkflowMetrics:           workflowMetrics,
-		OrmJobMetrics:             ormJobMetrics,
-		OrmRespoolMetrics:         ormRespoolMetrics,
-		OrmTaskMetrics:            ormTaskMetrics,
-		OrmJobUpdateEventsMetrics: ormJobUpdateEventsMetrics,
-		OrmHostInfoMetrics:        ormHostInfoMetrics,
+		JobMetrics:                  jobMetrics,
+		TaskMetrics:                 taskMetrics,
+		UpdateMetrics:               updateMetrics,
+		ResourcePoolMetrics:         resourcePoolMetrics,
+		FrameworkStoreMetrics:       frameworkStoreMetrics,
+		VolumeMetrics:               volumeMetrics,
+		ErrorMetrics:                errorMetrics,
+		WorkflowMetrics:             workflowMetrics,
+		OrmJobMetrics:               ormJobMetrics,
+		OrmRespoolMetrics:           ormRespoolMetrics,
+		OrmCronJobMetrics:           ormCronJobMetrics,
+		OrmDAGMetrics:               ormDAGMetrics,
+		OrmAuditEventMetrics:        ormAuditEventMetrics,
+		OrmMaintenanceWindowMetrics: ormMaintenanceWindowMetrics,
+		OrmTaskMetrics:              ormTaskMetrics,
+		OrmJobUpdateEventsMetrics:   ormJobUpdateEventsMetrics,
+		OrmHostInfoMetrics:          ormHostInfoMetrics,
 	}
 
 	return metrics
@@ -0,0 +1,144 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// _auditEventDayFormat is the format of the day partitioning
+// the audit events
+const _auditEventDayFormat = "2006-01-02"
+
+// AuditEventObject corresponds to a row in audit_events table.
+type AuditEventObject struct {
+	// base.Object DB specific annotations.
+	base.Object `cassandra:"name=audit_events, primaryKey=((day),event_time,event_id)"`
+	// Day of the event in UTC, formatted as 2006-01-02.
+	Day string `column:"name=day"`
+	// Time of the event.
+	EventTime time.Time `column:"name=event_time"`
+	// Unique identifier of the event.
+	EventID string `column:"name=event_id"`
+	// Event is the serialized audit event.
+	Event []byte `column:"name=event"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *AuditEventObject) transform(row map[string]interface{}) {
+	o.Day = row["day"].(string)
+	o.EventTime = row["event_time"].(time.Time)
+	o.EventID = row["event_id"].(string)
+	o.Event = row["event"].([]byte)
+}
+
+// AuditEventOps provides methods for manipulating audit_events table.
+type AuditEventOps interface {
+	// Add records an audit event which happened at eventTime.
+	Add(
+		ctx context.Context,
+		eventTime time.Time,
+		event *audit.AuditEvent,
+	) error
+
+	// GetAll returns the audit events of the day of the given time in UTC.
+	GetAll(ctx context.Context, day time.Time) ([]*audit.AuditEvent, error)
+}
+
+// auditEventOps implements AuditEventOps using a particular Store.
+type auditEventOps struct {
+	store *Store
+}
+
+// init adds an AuditEventObject instance to the global list of storage objects.
+func init() {
+	Objs = append(Objs, &AuditEventObject{})
+}
+
+// Default auditEventOps implementation.
+var _ AuditEventOps = (*auditEventOps)(nil)
+
+// NewAuditEventOps constructs an AuditEventOps object for provided Store.
+func NewAuditEventOps(s *Store) AuditEventOps {
+	return &auditEventOps{store: s}
+}
+
+// Add creates an AuditEventObject in db.
+func (d *auditEventOps) Add(
+	ctx context.Context,
+	eventTime time.Time,
+	event *audit.AuditEvent,
+) error {
+	buffer, err := proto.Marshal(event)
+	if err != nil {
+		d.store.metrics.OrmAuditEventMetrics.AuditEventAddFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal audit event")
+	}
+
+	obj := &AuditEventObject{
+		Day:       eventTime.UTC().Format(_auditEventDayFormat),
+		EventTime: eventTime.UTC(),
+		EventID:   event.GetEventId(),
+		Event:     buffer,
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmAuditEventMetrics.AuditEventAddFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmAuditEventMetrics.AuditEventAdd.Inc(1)
+	return nil
+}
+
+// GetAll returns the audit events of a day from db.
+func (d *auditEventOps) GetAll(
+	ctx context.Context,
+	day time.Time,
+) ([]*audit.AuditEvent, error) {
+	obj := &AuditEventObject{
+		Day: day.UTC().Format(_auditEventDayFormat),
+	}
+
+	rows, err := d.store.oClient.GetAll(ctx, obj)
+	if err != nil {
+		d.store.metrics.OrmAuditEventMetrics.AuditEventGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var events []*audit.AuditEvent
+	for _, row := range rows {
+		obj := &AuditEventObject{}
+		obj.transform(row)
+
+		event := &audit.AuditEvent{}
+		if err := proto.Unmarshal(obj.Event, event); err != nil {
+			d.store.metrics.OrmAuditEventMetrics.AuditEventGetAllFail.Inc(1)
+			return nil, errors.Wrap(err, "Failed to unmarshal audit event")
+		}
+		events = append(events, event)
+	}
+
+	d.store.metrics.OrmAuditEventMetrics.AuditEventGetAll.Inc(1)
+	return events, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditEventObjectTestSuite struct {
+	suite.Suite
+}
+
+func TestAuditEventObjectSuite(t *testing.T) {
+	suite.Run(t, new(AuditEventObjectTestSuite))
+}
+
+func (s *AuditEventObjectTestSuite) SetupTest() {
+	setupTestStore()
+}
+
+// TestAddGetAll tests recording audit events and reading them by day.
+func (s *AuditEventObjectTestSuite) TestAddGetAll() {
+	ops := NewAuditEventOps(testStore)
+	ctx := context.Background()
+
+	day := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	event1 := &audit.AuditEvent{
+		EventId:   uuid.New(),
+		User:      "alice",
+		Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		JobIds:    []string{uuid.New()},
+		Outcome:   audit.Outcome_OUTCOME_SUCCEEDED,
+	}
+	event2 := &audit.AuditEvent{
+		EventId:   uuid.New(),
+		User:      "bob",
+		Procedure: "peloton.api.v0.host.svc.HostService::StartMaintenance",
+		Hostnames: []string{"host1"},
+		Outcome:   audit.Outcome_OUTCOME_FAILED,
+		ErrorCode: "permission-denied",
+	}
+
+	s.NoError(ops.Add(ctx, day.Add(time.Hour), event1))
+	s.NoError(ops.Add(ctx, day.Add(2*time.Hour), event2))
+
+	// the partition may hold events of previous test runs,
+	// so only look at the events added by this test
+	events, err := ops.GetAll(ctx, day)
+	s.NoError(err)
+	var added []*audit.AuditEvent
+	for _, event := range events {
+		if event.GetEventId() == event1.GetEventId() ||
+			event.GetEventId() == event2.GetEventId() {
+			added = append(added, event)
+		}
+	}
+	s.Equal([]*audit.AuditEvent{event2, event1}, added)
+
+	events, err = ops.GetAll(ctx, day.Add(-24*time.Hour))
+	s.NoError(err)
+	for _, event := range events {
+		s.NotEqual(event1.GetEventId(), event.GetEventId())
+	}
+}
+
+// TestStoreErrors tests failures of the underlying client.
+func (s *AuditEventObjectTestSuite) TestStoreErrors() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	ops := NewAuditEventOps(&Store{
+		oClient: mockClient,
+		metrics: testStore.metrics,
+	})
+	ctx := context.Background()
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	s.Error(ops.Add(ctx, time.Now(), &audit.AuditEvent{EventId: uuid.New()}))
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get all failed"))
+	_, err := ops.GetAll(ctx, time.Now())
+	s.Error(err)
+}
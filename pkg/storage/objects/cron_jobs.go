@@ -0,0 +1,310 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+var (
+	_cronJobConfigFields = []string{
+		"Config",
+		"UpdateTime",
+	}
+	_cronJobRuntimeFields = []string{
+		"Runtime",
+		"UpdateTime",
+	}
+)
+
+// CronJobObject corresponds to a row in cron_jobs table.
+type CronJobObject struct {
+	// base.Object DB specific annotations.
+	base.Object `cassandra:"name=cron_jobs, primaryKey=((name))"`
+	// Name of the cron job.
+	Name *base.OptionalString `column:"name=name"`
+	// Config is the serialized cron job configuration.
+	Config []byte `column:"name=config"`
+	// Runtime is the serialized cron job runtime.
+	Runtime []byte `column:"name=runtime"`
+	// Timestamp of the cron job when it's created.
+	CreationTime time.Time `column:"name=creation_time"`
+	// Most recent timestamp when the cron job is updated.
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *CronJobObject) transform(row map[string]interface{}) {
+	o.Name = base.NewOptionalString(row["name"])
+	o.Config = row["config"].([]byte)
+	o.Runtime = row["runtime"].([]byte)
+	o.CreationTime = row["creation_time"].(time.Time)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// toCronJobInfo unmarshals the config and runtime of the cron job.
+func (o *CronJobObject) toCronJobInfo() (*cron.CronJobInfo, error) {
+	config := &cron.CronJobConfig{}
+	if err := proto.Unmarshal(o.Config, config); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal cron job config")
+	}
+
+	runtime := &cron.CronJobRuntime{}
+	if err := proto.Unmarshal(o.Runtime, runtime); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal cron job runtime")
+	}
+
+	return &cron.CronJobInfo{
+		Config:  config,
+		Runtime: runtime,
+	}, nil
+}
+
+// CronJobOps provides methods for manipulating cron_jobs table.
+type CronJobOps interface {
+	// Create inserts a new cron job in the table. It returns an
+	// AlreadyExists error if a cron job with the same name exists.
+	Create(
+		ctx context.Context,
+		config *cron.CronJobConfig,
+		runtime *cron.CronJobRuntime,
+	) error
+
+	// Get retrieves a cron job from the table. It returns a NotFound
+	// error if the cron job does not exist.
+	Get(ctx context.Context, name string) (*cron.CronJobInfo, error)
+
+	// GetAll retrieves all the cron jobs from the table.
+	GetAll(ctx context.Context) ([]*cron.CronJobInfo, error)
+
+	// UpdateConfig replaces the config of an existing cron job.
+	UpdateConfig(ctx context.Context, config *cron.CronJobConfig) error
+
+	// UpdateRuntime replaces the runtime of an existing cron job.
+	UpdateRuntime(
+		ctx context.Context,
+		name string,
+		runtime *cron.CronJobRuntime,
+	) error
+
+	// Delete removes a cron job from the table.
+	Delete(ctx context.Context, name string) error
+}
+
+// cronJobOps implements CronJobOps using a particular Store.
+type cronJobOps struct {
+	store *Store
+}
+
+// init adds a CronJobObject instance to the global list of storage objects.
+func init() {
+	Objs = append(Objs, &CronJobObject{})
+}
+
+// Default cronJobOps implementation.
+var _ CronJobOps = (*cronJobOps)(nil)
+
+// NewCronJobOps constructs a CronJobOps object for provided Store.
+func NewCronJobOps(s *Store) CronJobOps {
+	return &cronJobOps{store: s}
+}
+
+// Create creates a CronJobObject in db.
+func (c *cronJobOps) Create(
+	ctx context.Context,
+	config *cron.CronJobConfig,
+	runtime *cron.CronJobRuntime,
+) error {
+	configBuffer, err := proto.Marshal(config)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job config")
+	}
+
+	runtimeBuffer, err := proto.Marshal(runtime)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job runtime")
+	}
+
+	now := time.Now().UTC()
+	obj := &CronJobObject{
+		Name:         base.NewOptionalString(config.GetName()),
+		Config:       configBuffer,
+		Runtime:      runtimeBuffer,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+
+	if err := c.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobCreateFail.Inc(1)
+		return err
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobCreate.Inc(1)
+	return nil
+}
+
+// Get retrieves a cron job from db.
+func (c *cronJobOps) Get(
+	ctx context.Context,
+	name string,
+) (*cron.CronJobInfo, error) {
+	obj, err := c.getObject(ctx, name)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobGetFail.Inc(1)
+		return nil, err
+	}
+
+	info, err := obj.toCronJobInfo()
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobGetFail.Inc(1)
+		return nil, err
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobGet.Inc(1)
+	return info, nil
+}
+
+// GetAll retrieves all the cron jobs from db.
+func (c *cronJobOps) GetAll(ctx context.Context) ([]*cron.CronJobInfo, error) {
+	rows, err := c.store.oClient.GetAll(ctx, &CronJobObject{})
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var infos []*cron.CronJobInfo
+	for _, row := range rows {
+		obj := &CronJobObject{}
+		obj.transform(row)
+
+		// A runtime update racing with a delete can leave a row
+		// without config behind, skip it.
+		if len(obj.Config) == 0 {
+			continue
+		}
+
+		info, err := obj.toCronJobInfo()
+		if err != nil {
+			c.store.metrics.OrmCronJobMetrics.CronJobGetAllFail.Inc(1)
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobGetAll.Inc(1)
+	return infos, nil
+}
+
+// UpdateConfig replaces the config of a cron job in db.
+func (c *cronJobOps) UpdateConfig(
+	ctx context.Context,
+	config *cron.CronJobConfig,
+) error {
+	obj, err := c.getObject(ctx, config.GetName())
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	obj.Config, err = proto.Marshal(config)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job config")
+	}
+	obj.UpdateTime = time.Now().UTC()
+
+	if err := c.store.oClient.Update(
+		ctx, obj, _cronJobConfigFields...); err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobUpdate.Inc(1)
+	return nil
+}
+
+// UpdateRuntime replaces the runtime of a cron job in db.
+func (c *cronJobOps) UpdateRuntime(
+	ctx context.Context,
+	name string,
+	runtime *cron.CronJobRuntime,
+) error {
+	obj, err := c.getObject(ctx, name)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	obj.Runtime, err = proto.Marshal(runtime)
+	if err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job runtime")
+	}
+	obj.UpdateTime = time.Now().UTC()
+
+	if err := c.store.oClient.Update(
+		ctx, obj, _cronJobRuntimeFields...); err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobUpdate.Inc(1)
+	return nil
+}
+
+// Delete removes a cron job from db.
+func (c *cronJobOps) Delete(ctx context.Context, name string) error {
+	obj := &CronJobObject{
+		Name: base.NewOptionalString(name),
+	}
+	if err := c.store.oClient.Delete(ctx, obj); err != nil {
+		c.store.metrics.OrmCronJobMetrics.CronJobDeleteFail.Inc(1)
+		return err
+	}
+
+	c.store.metrics.OrmCronJobMetrics.CronJobDelete.Inc(1)
+	return nil
+}
+
+// getObject reads the row of a cron job from db.
+func (c *cronJobOps) getObject(
+	ctx context.Context,
+	name string,
+) (*CronJobObject, error) {
+	obj := &CronJobObject{
+		Name: base.NewOptionalString(name),
+	}
+	row, err := c.store.oClient.Get(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"cron job %s not found", name)
+	}
+	obj.transform(row)
+	return obj, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type CronJobObjectTestSuite struct {
+	suite.Suite
+	config *cron.CronJobConfig
+}
+
+func TestCronJobObjectSuite(t *testing.T) {
+	suite.Run(t, new(CronJobObjectTestSuite))
+}
+
+func (s *CronJobObjectTestSuite) SetupTest() {
+	setupTestStore()
+	s.config = &cron.CronJobConfig{
+		Name:              "cron-test",
+		Schedule:          "*/5 * * * *",
+		ConcurrencyPolicy: cron.ConcurrencyPolicy_REPLACE,
+		HistoryLimit:      3,
+		Template: &job.JobConfig{
+			Name:          "cron-test",
+			Type:          job.JobType_BATCH,
+			InstanceCount: 2,
+		},
+	}
+}
+
+// TestCreateGetUpdateDelete tests the lifecycle of a cron job in the store.
+func (s *CronJobObjectTestSuite) TestCreateGetUpdateDelete() {
+	ops := NewCronJobOps(testStore)
+	ctx := context.Background()
+
+	runtime := &cron.CronJobRuntime{
+		NextScheduleTime: "2019-01-01T00:05:00Z",
+	}
+	s.NoError(ops.Create(ctx, s.config, runtime))
+
+	// creating the same cron job again fails
+	err := ops.Create(ctx, s.config, runtime)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	info, err := ops.Get(ctx, s.config.GetName())
+	s.NoError(err)
+	s.Equal(s.config, info.GetConfig())
+	s.Equal(runtime, info.GetRuntime())
+
+	// update the config
+	s.config.Paused = true
+	s.NoError(ops.UpdateConfig(ctx, s.config))
+
+	// update the runtime
+	runtime.ActiveJobs = []*peloton.JobID{{Value: "job1"}}
+	runtime.RunCount = 1
+	s.NoError(ops.UpdateRuntime(ctx, s.config.GetName(), runtime))
+
+	infos, err := ops.GetAll(ctx)
+	s.NoError(err)
+	s.Len(infos, 1)
+	s.Equal(s.config, infos[0].GetConfig())
+	s.Equal(runtime, infos[0].GetRuntime())
+
+	s.NoError(ops.Delete(ctx, s.config.GetName()))
+
+	_, err = ops.Get(ctx, s.config.GetName())
+	s.True(yarpcerrors.IsNotFound(err))
+
+	// updating a deleted cron job fails
+	err = ops.UpdateRuntime(ctx, s.config.GetName(), runtime)
+	s.True(yarpcerrors.IsNotFound(err))
+	err = ops.UpdateConfig(ctx, s.config)
+	s.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestStoreErrors tests failures of the underlying client.
+func (s *CronJobObjectTestSuite) TestStoreErrors() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	ops := NewCronJobOps(&Store{
+		oClient: mockClient,
+		metrics: testStore.metrics,
+	})
+	ctx := context.Background()
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	s.Error(ops.Create(ctx, s.config, &cron.CronJobRuntime{}))
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	_, err := ops.Get(ctx, s.config.GetName())
+	s.Error(err)
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get all failed"))
+	_, err = ops.GetAll(ctx)
+	s.Error(err)
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	s.Error(ops.UpdateConfig(ctx, s.config))
+
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+	s.Error(ops.Delete(ctx, s.config.GetName()))
+}
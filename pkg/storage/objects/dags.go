@@ -0,0 +1,276 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+var _dagRuntimeFields = []string{
+	"Runtime",
+	"UpdateTime",
+}
+
+// DAGObject corresponds to a row in dags table.
+type DAGObject struct {
+	// base.Object DB specific annotations.
+	base.Object `cassandra:"name=dags, primaryKey=((dag_id))"`
+	// DAGID of the DAG.
+	DAGID *base.OptionalString `column:"name=dag_id"`
+	// Config is the serialized DAG configuration.
+	Config []byte `column:"name=config"`
+	// Runtime is the serialized DAG runtime.
+	Runtime []byte `column:"name=runtime"`
+	// Timestamp of the DAG when it's created.
+	CreationTime time.Time `column:"name=creation_time"`
+	// Most recent timestamp when the DAG is updated.
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *DAGObject) transform(row map[string]interface{}) {
+	o.DAGID = base.NewOptionalString(row["dag_id"])
+	o.Config = row["config"].([]byte)
+	o.Runtime = row["runtime"].([]byte)
+	o.CreationTime = row["creation_time"].(time.Time)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// toDAGInfo unmarshals the config and runtime of the DAG.
+func (o *DAGObject) toDAGInfo() (*dag.DAGInfo, error) {
+	config := &dag.DAGConfig{}
+	if err := proto.Unmarshal(o.Config, config); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal DAG config")
+	}
+
+	runtime := &dag.DAGRuntime{}
+	if err := proto.Unmarshal(o.Runtime, runtime); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal DAG runtime")
+	}
+
+	return &dag.DAGInfo{
+		Id:      &dag.DAGID{Value: o.DAGID.String()},
+		Config:  config,
+		Runtime: runtime,
+	}, nil
+}
+
+// DAGOps provides methods for manipulating dags table.
+type DAGOps interface {
+	// Create inserts a new DAG in the table. It returns an
+	// AlreadyExists error if a DAG with the same ID exists.
+	Create(
+		ctx context.Context,
+		id *dag.DAGID,
+		config *dag.DAGConfig,
+		runtime *dag.DAGRuntime,
+	) error
+
+	// Get retrieves a DAG from the table. It returns a NotFound
+	// error if the DAG does not exist.
+	Get(ctx context.Context, id *dag.DAGID) (*dag.DAGInfo, error)
+
+	// GetAll retrieves all the DAGs from the table.
+	GetAll(ctx context.Context) ([]*dag.DAGInfo, error)
+
+	// UpdateRuntime replaces the runtime of an existing DAG.
+	UpdateRuntime(
+		ctx context.Context,
+		id *dag.DAGID,
+		runtime *dag.DAGRuntime,
+	) error
+
+	// Delete removes a DAG from the table.
+	Delete(ctx context.Context, id *dag.DAGID) error
+}
+
+// dagOps implements DAGOps using a particular Store.
+type dagOps struct {
+	store *Store
+}
+
+// init adds a DAGObject instance to the global list of storage objects.
+func init() {
+	Objs = append(Objs, &DAGObject{})
+}
+
+// Default dagOps implementation.
+var _ DAGOps = (*dagOps)(nil)
+
+// NewDAGOps constructs a DAGOps object for provided Store.
+func NewDAGOps(s *Store) DAGOps {
+	return &dagOps{store: s}
+}
+
+// Create creates a DAGObject in db.
+func (d *dagOps) Create(
+	ctx context.Context,
+	id *dag.DAGID,
+	config *dag.DAGConfig,
+	runtime *dag.DAGRuntime,
+) error {
+	configBuffer, err := proto.Marshal(config)
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal DAG config")
+	}
+
+	runtimeBuffer, err := proto.Marshal(runtime)
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal DAG runtime")
+	}
+
+	now := time.Now().UTC()
+	obj := &DAGObject{
+		DAGID:        base.NewOptionalString(id.GetValue()),
+		Config:       configBuffer,
+		Runtime:      runtimeBuffer,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+
+	if err := d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDAGMetrics.DAGCreate.Inc(1)
+	return nil
+}
+
+// Get retrieves a DAG from db.
+func (d *dagOps) Get(
+	ctx context.Context,
+	id *dag.DAGID,
+) (*dag.DAGInfo, error) {
+	obj, err := d.getObject(ctx, id)
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGGetFail.Inc(1)
+		return nil, err
+	}
+
+	info, err := obj.toDAGInfo()
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGGetFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmDAGMetrics.DAGGet.Inc(1)
+	return info, nil
+}
+
+// GetAll retrieves all the DAGs from db.
+func (d *dagOps) GetAll(ctx context.Context) ([]*dag.DAGInfo, error) {
+	rows, err := d.store.oClient.GetAll(ctx, &DAGObject{})
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var infos []*dag.DAGInfo
+	for _, row := range rows {
+		obj := &DAGObject{}
+		obj.transform(row)
+
+		// A runtime update racing with a delete can leave a row
+		// without config behind, skip it.
+		if len(obj.Config) == 0 {
+			continue
+		}
+
+		info, err := obj.toDAGInfo()
+		if err != nil {
+			d.store.metrics.OrmDAGMetrics.DAGGetAllFail.Inc(1)
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	d.store.metrics.OrmDAGMetrics.DAGGetAll.Inc(1)
+	return infos, nil
+}
+
+// UpdateRuntime replaces the runtime of a DAG in db.
+func (d *dagOps) UpdateRuntime(
+	ctx context.Context,
+	id *dag.DAGID,
+	runtime *dag.DAGRuntime,
+) error {
+	obj, err := d.getObject(ctx, id)
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGUpdateFail.Inc(1)
+		return err
+	}
+
+	obj.Runtime, err = proto.Marshal(runtime)
+	if err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal DAG runtime")
+	}
+	obj.UpdateTime = time.Now().UTC()
+
+	if err := d.store.oClient.Update(
+		ctx, obj, _dagRuntimeFields...); err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDAGMetrics.DAGUpdate.Inc(1)
+	return nil
+}
+
+// Delete removes a DAG from db.
+func (d *dagOps) Delete(ctx context.Context, id *dag.DAGID) error {
+	obj := &DAGObject{
+		DAGID: base.NewOptionalString(id.GetValue()),
+	}
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmDAGMetrics.DAGDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDAGMetrics.DAGDelete.Inc(1)
+	return nil
+}
+
+// getObject reads the row of a DAG from db.
+func (d *dagOps) getObject(
+	ctx context.Context,
+	id *dag.DAGID,
+) (*DAGObject, error) {
+	obj := &DAGObject{
+		DAGID: base.NewOptionalString(id.GetValue()),
+	}
+	row, err := d.store.oClient.Get(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"DAG %s not found", id.GetValue())
+	}
+	obj.transform(row)
+	return obj, nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type DAGObjectTestSuite struct {
+	suite.Suite
+	id     *dag.DAGID
+	config *dag.DAGConfig
+}
+
+func TestDAGObjectSuite(t *testing.T) {
+	suite.Run(t, new(DAGObjectTestSuite))
+}
+
+func (s *DAGObjectTestSuite) SetupTest() {
+	setupTestStore()
+	s.id = &dag.DAGID{Value: uuid.New()}
+	s.config = &dag.DAGConfig{
+		Name: "dag-test",
+		Nodes: []*dag.Node{
+			{
+				Name: "extract",
+				Config: &job.JobConfig{
+					Name:          "extract",
+					Type:          job.JobType_BATCH,
+					InstanceCount: 2,
+				},
+			},
+			{
+				Name: "load",
+				Config: &job.JobConfig{
+					Name:          "load",
+					Type:          job.JobType_BATCH,
+					InstanceCount: 1,
+				},
+				Parents: []*dag.Edge{{Parent: "extract"}},
+			},
+		},
+	}
+}
+
+// TestCreateGetUpdateDelete tests the lifecycle of a DAG in the store.
+func (s *DAGObjectTestSuite) TestCreateGetUpdateDelete() {
+	ops := NewDAGOps(testStore)
+	ctx := context.Background()
+
+	runtime := &dag.DAGRuntime{
+		State:        dag.DAGState_DAG_STATE_RUNNING,
+		GoalState:    dag.DAGState_DAG_STATE_RUNNING,
+		CreationTime: "2019-01-01T00:00:00Z",
+	}
+	s.NoError(ops.Create(ctx, s.id, s.config, runtime))
+
+	// creating the same DAG again fails
+	err := ops.Create(ctx, s.id, s.config, runtime)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	info, err := ops.Get(ctx, s.id)
+	s.NoError(err)
+	s.Equal(s.id, info.GetId())
+	s.Equal(s.config, info.GetConfig())
+	s.Equal(runtime, info.GetRuntime())
+
+	// update the runtime
+	runtime.Nodes = []*dag.NodeStatus{
+		{
+			Name:  "extract",
+			State: dag.NodeState_NODE_STATE_RUNNING,
+			JobId: &peloton.JobID{Value: "job1"},
+		},
+	}
+	s.NoError(ops.UpdateRuntime(ctx, s.id, runtime))
+
+	infos, err := ops.GetAll(ctx)
+	s.NoError(err)
+	var found bool
+	for _, info := range infos {
+		if info.GetId().GetValue() == s.id.GetValue() {
+			found = true
+			s.Equal(s.config, info.GetConfig())
+			s.Equal(runtime, info.GetRuntime())
+		}
+	}
+	s.True(found)
+
+	s.NoError(ops.Delete(ctx, s.id))
+
+	_, err = ops.Get(ctx, s.id)
+	s.True(yarpcerrors.IsNotFound(err))
+
+	// updating a deleted DAG fails
+	err = ops.UpdateRuntime(ctx, s.id, runtime)
+	s.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestStoreErrors tests failures of the underlying client.
+func (s *DAGObjectTestSuite) TestStoreErrors() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	ops := NewDAGOps(&Store{
+		oClient: mockClient,
+		metrics: testStore.metrics,
+	})
+	ctx := context.Background()
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	s.Error(ops.Create(ctx, s.id, s.config, &dag.DAGRuntime{}))
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	_, err := ops.Get(ctx, s.id)
+	s.Error(err)
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get all failed"))
+	_, err = ops.GetAll(ctx)
+	s.Error(err)
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	s.Error(ops.UpdateRuntime(ctx, s.id, &dag.DAGRuntime{}))
+
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+	s.Error(ops.Delete(ctx, s.id))
+}
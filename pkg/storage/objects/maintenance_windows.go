@@ -0,0 +1,261 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	hostpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+var _maintenanceWindowUpdateFields = []string{
+	"Window",
+	"UpdateTime",
+}
+
+// MaintenanceWindowObject corresponds to a row in maintenance_windows table.
+type MaintenanceWindowObject struct {
+	// base.Object DB specific annotations.
+	base.Object `cassandra:"name=maintenance_windows, primaryKey=((window_id))"`
+	// WindowID of the maintenance window.
+	WindowID *base.OptionalString `column:"name=window_id"`
+	// Window is the serialized maintenance window.
+	Window []byte `column:"name=window"`
+	// Timestamp of the window when it's created.
+	CreationTime time.Time `column:"name=creation_time"`
+	// Most recent timestamp when the window is updated.
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *MaintenanceWindowObject) transform(row map[string]interface{}) {
+	o.WindowID = base.NewOptionalString(row["window_id"])
+	o.Window = row["window"].([]byte)
+	o.CreationTime = row["creation_time"].(time.Time)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// toMaintenanceWindow unmarshals the maintenance window.
+func (o *MaintenanceWindowObject) toMaintenanceWindow() (
+	*hostpb.MaintenanceWindow, error) {
+	window := &hostpb.MaintenanceWindow{}
+	if err := proto.Unmarshal(o.Window, window); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal maintenance window")
+	}
+	return window, nil
+}
+
+// MaintenanceWindowOps provides methods for manipulating
+// maintenance_windows table.
+type MaintenanceWindowOps interface {
+	// Create inserts a new maintenance window in the table. It returns an
+	// AlreadyExists error if a window with the same ID exists.
+	Create(ctx context.Context, window *hostpb.MaintenanceWindow) error
+
+	// Get retrieves a maintenance window from the table. It returns a
+	// NotFound error if the window does not exist.
+	Get(ctx context.Context, id string) (*hostpb.MaintenanceWindow, error)
+
+	// GetAll retrieves all the maintenance windows from the table.
+	GetAll(ctx context.Context) ([]*hostpb.MaintenanceWindow, error)
+
+	// Update replaces an existing maintenance window.
+	Update(ctx context.Context, window *hostpb.MaintenanceWindow) error
+
+	// Delete removes a maintenance window from the table.
+	Delete(ctx context.Context, id string) error
+}
+
+// maintenanceWindowOps implements MaintenanceWindowOps using a
+// particular Store.
+type maintenanceWindowOps struct {
+	store *Store
+}
+
+// init adds a MaintenanceWindowObject instance to the global list
+// of storage objects.
+func init() {
+	Objs = append(Objs, &MaintenanceWindowObject{})
+}
+
+// Default maintenanceWindowOps implementation.
+var _ MaintenanceWindowOps = (*maintenanceWindowOps)(nil)
+
+// NewMaintenanceWindowOps constructs a MaintenanceWindowOps object
+// for provided Store.
+func NewMaintenanceWindowOps(s *Store) MaintenanceWindowOps {
+	return &maintenanceWindowOps{store: s}
+}
+
+// Create creates a MaintenanceWindowObject in db.
+func (m *maintenanceWindowOps) Create(
+	ctx context.Context,
+	window *hostpb.MaintenanceWindow,
+) error {
+	buffer, err := proto.Marshal(window)
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal maintenance window")
+	}
+
+	now := time.Now().UTC()
+	obj := &MaintenanceWindowObject{
+		WindowID:     base.NewOptionalString(window.GetId()),
+		Window:       buffer,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+
+	if err := m.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowCreateFail.Inc(1)
+		return err
+	}
+
+	m.store.metrics.OrmMaintenanceWindowMetrics.MaintenanceWindowCreate.Inc(1)
+	return nil
+}
+
+// Get retrieves a maintenance window from db.
+func (m *maintenanceWindowOps) Get(
+	ctx context.Context,
+	id string,
+) (*hostpb.MaintenanceWindow, error) {
+	obj, err := m.getObject(ctx, id)
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowGetFail.Inc(1)
+		return nil, err
+	}
+
+	window, err := obj.toMaintenanceWindow()
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowGetFail.Inc(1)
+		return nil, err
+	}
+
+	m.store.metrics.OrmMaintenanceWindowMetrics.MaintenanceWindowGet.Inc(1)
+	return window, nil
+}
+
+// GetAll retrieves all the maintenance windows from db.
+func (m *maintenanceWindowOps) GetAll(
+	ctx context.Context,
+) ([]*hostpb.MaintenanceWindow, error) {
+	rows, err := m.store.oClient.GetAll(ctx, &MaintenanceWindowObject{})
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var windows []*hostpb.MaintenanceWindow
+	for _, row := range rows {
+		obj := &MaintenanceWindowObject{}
+		obj.transform(row)
+
+		// An update racing with a delete can leave a row
+		// without window behind, skip it.
+		if len(obj.Window) == 0 {
+			continue
+		}
+
+		window, err := obj.toMaintenanceWindow()
+		if err != nil {
+			m.store.metrics.OrmMaintenanceWindowMetrics.
+				MaintenanceWindowGetAllFail.Inc(1)
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	m.store.metrics.OrmMaintenanceWindowMetrics.MaintenanceWindowGetAll.Inc(1)
+	return windows, nil
+}
+
+// Update replaces a maintenance window in db.
+func (m *maintenanceWindowOps) Update(
+	ctx context.Context,
+	window *hostpb.MaintenanceWindow,
+) error {
+	obj, err := m.getObject(ctx, window.GetId())
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowUpdateFail.Inc(1)
+		return err
+	}
+
+	obj.Window, err = proto.Marshal(window)
+	if err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal maintenance window")
+	}
+	obj.UpdateTime = time.Now().UTC()
+
+	if err := m.store.oClient.Update(
+		ctx, obj, _maintenanceWindowUpdateFields...); err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowUpdateFail.Inc(1)
+		return err
+	}
+
+	m.store.metrics.OrmMaintenanceWindowMetrics.MaintenanceWindowUpdate.Inc(1)
+	return nil
+}
+
+// Delete removes a maintenance window from db.
+func (m *maintenanceWindowOps) Delete(ctx context.Context, id string) error {
+	obj := &MaintenanceWindowObject{
+		WindowID: base.NewOptionalString(id),
+	}
+	if err := m.store.oClient.Delete(ctx, obj); err != nil {
+		m.store.metrics.OrmMaintenanceWindowMetrics.
+			MaintenanceWindowDeleteFail.Inc(1)
+		return err
+	}
+
+	m.store.metrics.OrmMaintenanceWindowMetrics.MaintenanceWindowDelete.Inc(1)
+	return nil
+}
+
+// getObject reads the row of a maintenance window from db.
+func (m *maintenanceWindowOps) getObject(
+	ctx context.Context,
+	id string,
+) (*MaintenanceWindowObject, error) {
+	obj := &MaintenanceWindowObject{
+		WindowID: base.NewOptionalString(id),
+	}
+	row, err := m.store.oClient.Get(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"maintenance window %s not found", id)
+	}
+	obj.transform(row)
+	return obj, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	hostpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type MaintenanceWindowObjectTestSuite struct {
+	suite.Suite
+	window *hostpb.MaintenanceWindow
+}
+
+func TestMaintenanceWindowObjectSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceWindowObjectTestSuite))
+}
+
+func (s *MaintenanceWindowObjectTestSuite) SetupTest() {
+	setupTestStore()
+	s.window = &hostpb.MaintenanceWindow{
+		Id:              uuid.New(),
+		Hostnames:       []string{"host1", "host2"},
+		StartTime:       "2019-01-01T00:00:00Z",
+		DurationSeconds: 3600,
+		State:           hostpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		CreationTime:    "2018-12-31T00:00:00Z",
+	}
+}
+
+// TestCreateGetUpdateDelete tests the lifecycle of a maintenance
+// window in the store.
+func (s *MaintenanceWindowObjectTestSuite) TestCreateGetUpdateDelete() {
+	ops := NewMaintenanceWindowOps(testStore)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, s.window))
+
+	// creating the same window again fails
+	err := ops.Create(ctx, s.window)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	window, err := ops.Get(ctx, s.window.GetId())
+	s.NoError(err)
+	s.Equal(s.window, window)
+
+	s.window.State = hostpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE
+	s.NoError(ops.Update(ctx, s.window))
+
+	windows, err := ops.GetAll(ctx)
+	s.NoError(err)
+	var found bool
+	for _, w := range windows {
+		if w.GetId() == s.window.GetId() {
+			found = true
+			s.Equal(s.window, w)
+		}
+	}
+	s.True(found)
+
+	s.NoError(ops.Delete(ctx, s.window.GetId()))
+
+	_, err = ops.Get(ctx, s.window.GetId())
+	s.True(yarpcerrors.IsNotFound(err))
+
+	// updating a deleted window fails
+	err = ops.Update(ctx, s.window)
+	s.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestStoreErrors tests failures of the underlying client.
+func (s *MaintenanceWindowObjectTestSuite) TestStoreErrors() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	ops := NewMaintenanceWindowOps(&Store{
+		oClient: mockClient,
+		metrics: testStore.metrics,
+	})
+	ctx := context.Background()
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	s.Error(ops.Create(ctx, s.window))
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	_, err := ops.Get(ctx, s.window.GetId())
+	s.Error(err)
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get all failed"))
+	_, err = ops.GetAll(ctx)
+	s.Error(err)
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	s.Error(ops.Update(ctx, s.window))
+
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+	s.Error(ops.Delete(ctx, s.window.GetId()))
+}
@@ -16,16 +16,22 @@ package objects
 
 import (
 	"context"
+	"encoding/base64"
 	"go.uber.org/yarpc/yarpcerrors"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/pkg/errors"
 )
 
 const (
 	// default secret version that we use
 	secretVersion0 = 0
+	// secret version of the secrets whose data is envelope encrypted
+	// with the key identified by the key_id column
+	secretVersion1 = 1
 	// this flag is used to indicate that the secret is valid, it is more
 	// forward looking in case we end up revoking secrets.
 	secretValid = true
@@ -55,6 +61,8 @@ type SecretInfoObject struct {
 	CreationTime time.Time `column:"name=creation_time"`
 	// Version of this secret
 	Version int64 `column:"name=version"`
+	// ID of the key the secret data is encrypted with
+	KeyID string `column:"name=key_id"`
 	// This flag indicates that the secret is valid or invalid
 	Valid bool `column:"name=valid"`
 }
@@ -68,6 +76,11 @@ func (o *SecretInfoObject) transform(row map[string]interface{}) {
 	o.Data = row["data"].(string)
 	o.CreationTime = row["creation_time"].(time.Time)
 	o.Version = int64(row["version"].(uint64))
+	// key_id is not set for the secrets created before encryption
+	// was introduced
+	if keyID, ok := row["key_id"].(string); ok {
+		o.KeyID = keyID
+	}
 	o.Valid = row["valid"].(bool)
 }
 
@@ -98,6 +111,11 @@ type SecretInfoOps interface {
 		ctx context.Context,
 		secretID string,
 	) error
+
+	// ReencryptSecrets encrypts the secrets which are stored in plaintext
+	// or encrypted with an old key with the current key, and returns the
+	// number of secrets re-encrypted.
+	ReencryptSecrets(ctx context.Context) (int, error)
 }
 
 // secretInfoOps implements SecretInfoOps interface using a particular Store.
@@ -149,6 +167,10 @@ func (s *secretInfoOps) CreateSecret(
 	secretID, secretString, secretPath string,
 ) error {
 	obj := newSecretObject(jobID, now, secretID, secretString, secretPath)
+	if err := s.encrypt(ctx, obj); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
+		return err
+	}
 
 	if err := s.store.oClient.Create(ctx, obj); err != nil {
 		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
@@ -178,6 +200,10 @@ func (s *secretInfoOps) GetSecret(
 			"Secret is not found %s", secretID)
 	}
 	secretInfoObject.transform(row)
+	if err := s.decrypt(ctx, secretInfoObject); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoGetFail.Inc(1)
+		return nil, err
+	}
 	s.store.metrics.OrmJobMetrics.SecretInfoGet.Inc(1)
 	return secretInfoObject, nil
 }
@@ -191,8 +217,13 @@ func (s *secretInfoOps) UpdateSecretData(
 		SecretID: secretID,
 		Valid:    true,
 		Data:     secretString,
+		Version:  secretVersion0,
+	}
+	if err := s.encrypt(ctx, secretInfoObject); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoUpdateFail.Inc(1)
+		return err
 	}
-	fieldToUpdate := []string{"Data"}
+	fieldToUpdate := []string{"Data", "Version", "KeyID"}
 	if err := s.store.oClient.Update(ctx, secretInfoObject, fieldToUpdate...); err != nil {
 		s.store.metrics.OrmJobMetrics.SecretInfoUpdateFail.Inc(1)
 		return err
@@ -217,3 +248,116 @@ func (s *secretInfoOps) DeleteSecret(
 	s.store.metrics.OrmJobMetrics.SecretInfoDelete.Inc(1)
 	return nil
 }
+
+// ReencryptSecrets encrypts the secrets which are stored in plaintext or
+// encrypted with an old key with the current key. A secret is only
+// rewritten if its data did not change since it was read, so the secrets
+// updated or deleted meanwhile are skipped rather than overwritten.
+func (s *secretInfoOps) ReencryptSecrets(ctx context.Context) (int, error) {
+	if s.store.secretEncrypter == nil {
+		return 0, errors.New("secret encryption is not enabled")
+	}
+
+	keyID, err := s.store.secretEncrypter.CurrentKeyID(ctx)
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoReencryptFail.Inc(1)
+		return 0, err
+	}
+
+	rows, err := s.store.oClient.GetAll(ctx, &SecretInfoObject{})
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoReencryptFail.Inc(1)
+		return 0, err
+	}
+
+	reencrypted := 0
+	for _, row := range rows {
+		obj := &SecretInfoObject{}
+		obj.transform(row)
+		if !obj.Valid ||
+			(obj.Version == secretVersion1 && obj.KeyID == keyID) {
+			continue
+		}
+		prev := *obj
+
+		if err := s.decrypt(ctx, obj); err != nil {
+			s.store.metrics.OrmJobMetrics.SecretInfoReencryptFail.Inc(1)
+			return reencrypted, err
+		}
+		if err := s.encrypt(ctx, obj); err != nil {
+			s.store.metrics.OrmJobMetrics.SecretInfoReencryptFail.Inc(1)
+			return reencrypted, err
+		}
+		// the key_id is not part of the condition since it is null for
+		// the secrets written before the column was added, and every
+		// encryption of the data yields a different ciphertext anyway
+		err := s.store.oClient.UpdateIf(
+			ctx,
+			obj,
+			&prev,
+			[]string{"Data", "Version"},
+			"Data", "Version", "KeyID")
+		if yarpcerrors.IsAborted(err) {
+			continue
+		}
+		if err != nil {
+			s.store.metrics.OrmJobMetrics.SecretInfoReencryptFail.Inc(1)
+			return reencrypted, err
+		}
+		reencrypted++
+		s.store.metrics.OrmJobMetrics.SecretInfoReencrypt.Inc(1)
+	}
+	return reencrypted, nil
+}
+
+// encrypt replaces the plaintext data of the secret object with its
+// envelope encrypted data if secret encryption is enabled. The data is
+// bound to the secret ID, so it cannot be decrypted as another secret.
+func (s *secretInfoOps) encrypt(
+	ctx context.Context,
+	obj *SecretInfoObject,
+) error {
+	if s.store.secretEncrypter == nil {
+		return nil
+	}
+
+	ciphertext, keyID, err := s.store.secretEncrypter.Encrypt(
+		ctx, []byte(obj.Data), []byte(obj.SecretID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt secret %s", obj.SecretID)
+	}
+	obj.Data = base64.StdEncoding.EncodeToString(ciphertext)
+	obj.Version = secretVersion1
+	obj.KeyID = keyID
+	return nil
+}
+
+// decrypt replaces the envelope encrypted data of the secret object with
+// its plaintext data. Secrets stored in plaintext are left untouched.
+func (s *secretInfoOps) decrypt(
+	ctx context.Context,
+	obj *SecretInfoObject,
+) error {
+	if obj.Version != secretVersion1 {
+		return nil
+	}
+	if s.store.secretEncrypter == nil {
+		return errors.Errorf(
+			"secret %s is encrypted but secret encryption is not enabled",
+			obj.SecretID)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(obj.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode secret %s", obj.SecretID)
+	}
+	plaintext, err := s.store.secretEncrypter.Decrypt(
+		ctx, obj.KeyID, ciphertext, []byte(obj.SecretID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to decrypt secret %s", obj.SecretID)
+	}
+	obj.Data = string(plaintext)
+	obj.Version = secretVersion0
+	obj.KeyID = ""
+	return nil
+}
@@ -17,9 +17,12 @@ package objects
 import (
 	"context"
 	"encoding/base64"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/uber/peloton/pkg/storage/encryption"
+
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -85,3 +88,143 @@ func (suite *SecretInfoObjectTestSuite) TestSecretInfoOps() {
 	suite.Error(err)
 	suite.True(yarpcerrors.IsNotFound(err))
 }
+
+// newEncryptedTestStore returns a store sharing the ORM client of the test
+// store, which encrypts the secrets with the key of a local KMS.
+func (suite *SecretInfoObjectTestSuite) newEncryptedTestStore() (
+	*Store, *encryption.LocalKMS, *httptest.Server) {
+	kms := encryption.NewLocalKMS()
+	_, err := kms.RotateKey("secrets")
+	suite.NoError(err)
+	server := httptest.NewServer(kms)
+
+	store := &Store{
+		oClient: testStore.oClient,
+		metrics: testStore.metrics,
+	}
+	store.SetSecretEncrypter(encryption.NewEnvelopeEncrypter(
+		encryption.NewKMSProvider(server.URL, "secrets", server.Client())))
+	return store, kms, server
+}
+
+// getRawSecret returns the secret object as stored in the table.
+func (suite *SecretInfoObjectTestSuite) getRawSecret(
+	secretID string) *SecretInfoObject {
+	obj := &SecretInfoObject{SecretID: secretID, Valid: true}
+	row, err := testStore.oClient.Get(context.Background(), obj)
+	suite.NoError(err)
+	obj.transform(row)
+	return obj
+}
+
+// TestSecretInfoOpsEncrypted tests that the secret data is encrypted at
+// rest when secret encryption is enabled.
+func (suite *SecretInfoObjectTestSuite) TestSecretInfoOpsEncrypted() {
+	store, _, server := suite.newEncryptedTestStore()
+	defer server.Close()
+	db := NewSecretInfoOps(store)
+	ctx := context.Background()
+
+	jobID := uuid.New()
+	secretID := uuid.New()
+	secretStr := base64.StdEncoding.EncodeToString([]byte("some secrets"))
+
+	suite.NoError(db.CreateSecret(
+		ctx, jobID, time.Now().UTC(), secretID, secretStr, "some path"))
+
+	raw := suite.getRawSecret(secretID)
+	suite.Equal(int64(secretVersion1), raw.Version)
+	suite.Equal("secrets/v1", raw.KeyID)
+	suite.NotEqual(secretStr, raw.Data)
+
+	secretInfoObj, err := db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+	suite.Equal(secretStr, secretInfoObj.Data)
+
+	updatedSecretStr := base64.StdEncoding.EncodeToString([]byte("new secret"))
+	suite.NoError(db.UpdateSecretData(ctx, secretID, updatedSecretStr))
+	secretInfoObj, err = db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+	suite.Equal(updatedSecretStr, secretInfoObj.Data)
+
+	// encrypted secrets cannot be read without secret encryption
+	_, err = NewSecretInfoOps(testStore).GetSecret(ctx, secretID)
+	suite.Error(err)
+
+	suite.NoError(db.DeleteSecret(ctx, secretID))
+}
+
+// TestSwapEncryptedSecrets tests that the encrypted data of a secret
+// cannot be read as the data of another secret.
+func (suite *SecretInfoObjectTestSuite) TestSwapEncryptedSecrets() {
+	store, _, server := suite.newEncryptedTestStore()
+	defer server.Close()
+	db := NewSecretInfoOps(store)
+	ctx := context.Background()
+
+	secretID := uuid.New()
+	otherSecretID := uuid.New()
+	for _, id := range []string{secretID, otherSecretID} {
+		suite.NoError(db.CreateSecret(
+			ctx, uuid.New(), time.Now().UTC(), id,
+			base64.StdEncoding.EncodeToString([]byte(id)), "path"))
+	}
+
+	// copy the encrypted data of a secret into the other secret
+	raw := suite.getRawSecret(secretID)
+	raw.SecretID = otherSecretID
+	suite.NoError(testStore.oClient.Update(ctx, raw, "Data", "Version", "KeyID"))
+
+	_, err := db.GetSecret(ctx, otherSecretID)
+	suite.Error(err)
+
+	for _, id := range []string{secretID, otherSecretID} {
+		suite.NoError(db.DeleteSecret(ctx, id))
+	}
+}
+
+// TestReencryptSecrets tests that plaintext secrets and secrets encrypted
+// with an old key are re-encrypted with the current key.
+func (suite *SecretInfoObjectTestSuite) TestReencryptSecrets() {
+	store, kms, server := suite.newEncryptedTestStore()
+	defer server.Close()
+	plainDB := NewSecretInfoOps(testStore)
+	db := NewSecretInfoOps(store)
+	ctx := context.Background()
+
+	plainSecretID := uuid.New()
+	encryptedSecretID := uuid.New()
+	secretStr := base64.StdEncoding.EncodeToString([]byte("some secrets"))
+
+	suite.NoError(plainDB.CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), plainSecretID, secretStr, "path"))
+	suite.NoError(db.CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), encryptedSecretID, secretStr, "path"))
+
+	// plaintext secrets are encrypted with the current key
+	n, err := db.ReencryptSecrets(ctx)
+	suite.NoError(err)
+	suite.True(n >= 1)
+	raw := suite.getRawSecret(plainSecretID)
+	suite.Equal(int64(secretVersion1), raw.Version)
+	suite.Equal("secrets/v1", raw.KeyID)
+
+	// all secrets are re-encrypted after the key is rotated
+	keyID, err := kms.RotateKey("secrets")
+	suite.NoError(err)
+	n, err = db.ReencryptSecrets(ctx)
+	suite.NoError(err)
+	suite.True(n >= 2)
+
+	for _, secretID := range []string{plainSecretID, encryptedSecretID} {
+		suite.Equal(keyID, suite.getRawSecret(secretID).KeyID)
+		secretInfoObj, err := db.GetSecret(ctx, secretID)
+		suite.NoError(err)
+		suite.Equal(secretStr, secretInfoObj.Data)
+		suite.NoError(db.DeleteSecret(ctx, secretID))
+	}
+
+	// secrets cannot be re-encrypted without secret encryption
+	_, err = plainDB.ReencryptSecrets(ctx)
+	suite.Error(err)
+}
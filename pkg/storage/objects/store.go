@@ -22,6 +22,8 @@ import (
 
 	pelotonstore "github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/connectors/cassandra"
+	"github.com/uber/peloton/pkg/storage/connectors/memory"
+	"github.com/uber/peloton/pkg/storage/encryption"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 	"github.com/uber/peloton/pkg/storage/orm"
 
@@ -40,6 +42,16 @@ var Objs []base.Object
 type Store struct {
 	oClient orm.Client
 	metrics *pelotonstore.Metrics
+
+	// secretEncrypter encrypts the secret data at rest,
+	// secrets are stored in plaintext if not set
+	secretEncrypter encryption.Encrypter
+}
+
+// SetSecretEncrypter sets the encrypter of the secret data. It must
+// be called before the store is used.
+func (s *Store) SetSecretEncrypter(encrypter encryption.Encrypter) {
+	s.secretEncrypter = encrypter
 }
 
 // NewCassandraStore creates a new Cassandra storage client
@@ -51,6 +63,17 @@ func NewCassandraStore(
 	if err != nil {
 		return nil, err
 	}
+	return NewStore(connector, scope)
+}
+
+// NewMemoryStore creates a new storage client which keeps all the objects
+// in memory. This is meant for tests and local development only.
+func NewMemoryStore(scope tally.Scope) (*Store, error) {
+	return NewStore(memory.NewMemoryConnector(), scope)
+}
+
+// NewStore creates a new storage client using the given connector
+func NewStore(connector orm.Connector, scope tally.Scope) (*Store, error) {
 	// TODO: Load up all objects automatically instead of explicitly adding
 	// them here. Might need to add some Go init() magic to do this.
 	oclient, err := orm.NewClient(connector, Objs...)
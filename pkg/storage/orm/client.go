@@ -45,6 +45,16 @@ type Client interface {
 	// the caller. If not specified, all fields in the object will be updated
 	// to the DB
 	Update(ctx context.Context, e base.Object, fieldsToUpdate ...string) error
+	// UpdateIf updates the storage object in the database like Update,
+	// only if the fields condFields of the stored object still have the
+	// values they have in prev. An Aborted error is returned otherwise.
+	UpdateIf(
+		ctx context.Context,
+		e base.Object,
+		prev base.Object,
+		condFields []string,
+		fieldsToUpdate ...string,
+	) error
 	// Delete deletes the storage object from the database
 	Delete(ctx context.Context, e base.Object) error
 }
@@ -201,6 +211,39 @@ func (c *client) Update(
 	return c.connector.Update(ctx, &table.Definition, row, keyRow)
 }
 
+// UpdateIf updates the storage object in the database if the fields
+// condFields of the stored object have the values they have in prev
+func (c *client) UpdateIf(
+	ctx context.Context,
+	e base.Object,
+	prev base.Object,
+	condFields []string,
+	fieldsToUpdate ...string,
+) error {
+	// lookup if a table exists for this object, return error if not found
+	table, err := c.getTable(e)
+	if err != nil {
+		return err
+	}
+	if len(condFields) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"no condition for the update of %q", table.Name)
+	}
+
+	// translate the storage object into a row (list of column)
+	row := table.GetRowFromObject(e, fieldsToUpdate...)
+
+	// build a primary key row from storage object
+	keyRow := table.GetKeyRowFromObject(e)
+
+	// build the condition row from the previous storage object
+	condRow := table.GetRowFromObject(prev, condFields...)
+
+	// Tell the connector to update the row in the DB if the condition
+	// row matches
+	return c.connector.UpdateIf(ctx, &table.Definition, row, keyRow, condRow)
+}
+
 // Delete deletes the storage object in the database
 func (c *client) Delete(ctx context.Context, e base.Object) error {
 	// lookup if a table exists for this object, return error if not found
@@ -300,6 +300,45 @@ func (suite *ORMTestSuite) TestClientUpdate() {
 	suite.Error(err)
 }
 
+// TestClientUpdateIf tests client conditional update operation on valid
+// and invalid entities
+func (suite *ORMTestSuite) TestClientUpdateIf() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	prev := &ValidObject{
+		ID:   uint64(1),
+		Name: "test",
+		Data: "olddata",
+	}
+
+	conn.EXPECT().UpdateIf(
+		suite.ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition,
+			row []base.Column, keyRow []base.Column, condRow []base.Column) {
+			suite.Equal([]base.Column{{Name: "data", Value: "testdata"}}, row)
+			suite.Equal("id", keyRow[0].Name)
+			suite.Equal(uint64(1), keyRow[0].Value)
+			suite.Equal([]base.Column{{Name: "data", Value: "olddata"}}, condRow)
+		}).Return(nil)
+
+	client, err := orm.NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	// Update Data field in testValidObject if it is still the old one
+	err = client.UpdateIf(
+		suite.ctx, testValidObject, prev, []string{"Data"}, "Data")
+	suite.NoError(err)
+
+	// a conditional update needs a condition
+	err = client.UpdateIf(suite.ctx, testValidObject, prev, nil, "Data")
+	suite.Error(err)
+
+	err = client.UpdateIf(
+		suite.ctx, &InvalidObject1{}, &InvalidObject1{}, []string{"Data"})
+	suite.Error(err)
+}
+
 // TestClientDelete tests client delete operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientDelete() {
 	defer suite.ctrl.Finish()
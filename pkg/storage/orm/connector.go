@@ -63,6 +63,17 @@ type Connector interface {
 		keys []base.Column,
 	) error
 
+	// UpdateIf updates a row in the DB for the base object if the
+	// columns of the row have the values of condCols. An Aborted error is
+	// returned if the row does not exist or its columns have other values.
+	UpdateIf(
+		ctx context.Context,
+		e *base.Definition,
+		values []base.Column,
+		keys []base.Column,
+		condCols []base.Column,
+	) error
+
 	// Delete deletes a row from the DB for the base object
 	Delete(ctx context.Context, e *base.Definition, keys []base.Column) error
 }
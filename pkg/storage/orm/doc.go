@@ -36,6 +36,7 @@ peloton. There are three major components of this layer:
 
   * Connector - is the interface mapping directly to the API exposed by the
              client and should be implemented by different storage connectors.
-             Peloton currently has a cassandra implementation of the connector
-             and we can extend this to other DBs.
+             Peloton currently has a cassandra implementation of the connector,
+             an embedded SQLite implementation for single node deployments
+             and an in-memory implementation for tests.
 */